@@ -5,6 +5,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -38,6 +39,8 @@ const (
 	flagUseRelayer     = "use-relayer"
 	flagSearchTime     = "search-time"
 	flagDetached       = "detached"
+	flagOffersFile     = "offers-file"
+	flagBackupFile     = "file"
 )
 
 var (
@@ -66,6 +69,32 @@ var (
 					swapdPortFlag,
 				},
 			},
+			{
+				Name:   "block-peer",
+				Usage:  "Reject swaps and relay requests from a peer",
+				Action: runBlockPeer,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagPeerID,
+						Usage:    "ID of the peer to block",
+						Required: true,
+					},
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "unblock-peer",
+				Usage:  "Remove a peer from the blocklist",
+				Action: runUnblockPeer,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagPeerID,
+						Usage:    "ID of the peer to unblock",
+						Required: true,
+					},
+					swapdPortFlag,
+				},
+			},
 			{
 				Name:    "balances",
 				Aliases: []string{"b"},
@@ -181,6 +210,19 @@ var (
 					swapdPortFlag,
 				},
 			},
+			{
+				Name:   "import-offers",
+				Usage:  "Make all the offers of an offer book file at once; if any are invalid, none are made",
+				Action: runImportOffers,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagOffersFile,
+						Usage:    "Path to a JSON array of offers, with the same fields as net_makeOffer",
+						Required: true,
+					},
+					swapdPortFlag,
+				},
+			},
 			{
 				Name:    "take",
 				Aliases: []string{"t"},
@@ -278,6 +320,14 @@ var (
 					swapdPortFlag,
 				},
 			},
+			{
+				Name:   "get-offer-liquidity",
+				Usage:  "Get our offers with the XMR reserved by in-flight swaps, and our available balance.",
+				Action: runGetOfferLiquidity,
+				Flags: []cli.Flag{
+					swapdPortFlag,
+				},
+			},
 			{
 				Name:   "get-status",
 				Usage:  "Get the status of a current swap.",
@@ -318,6 +368,25 @@ var (
 					swapdPortFlag,
 				},
 			},
+			{
+				Name:   "sweep-stranded-funds",
+				Usage:  "Sweep XMR left in the addresses of past swaps to swapd's primary Monero address",
+				Action: runSweepStrandedFunds,
+				Flags:  []cli.Flag{swapdPortFlag},
+			},
+			{
+				Name:   "backup-db",
+				Usage:  "Write a consistent snapshot of swapd's database, which swapd's --restore-db flag loads",
+				Action: runBackupDB,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagBackupFile,
+						Usage:    "Path to write the backup to, on the host running swapd",
+						Required: true,
+					},
+					swapdPortFlag,
+				},
+			},
 		},
 	}
 
@@ -383,6 +452,36 @@ func runPeers(ctx *cli.Context) error {
 	return nil
 }
 
+func runBlockPeer(ctx *cli.Context) error {
+	peerID, err := peer.Decode(ctx.String(flagPeerID))
+	if err != nil {
+		return errInvalidFlagValue(flagPeerID, err)
+	}
+
+	c := newRRPClient(ctx)
+	if err = c.BlockPeer(peerID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Blocked peer %s\n", peerID)
+	return nil
+}
+
+func runUnblockPeer(ctx *cli.Context) error {
+	peerID, err := peer.Decode(ctx.String(flagPeerID))
+	if err != nil {
+		return errInvalidFlagValue(flagPeerID, err)
+	}
+
+	c := newRRPClient(ctx)
+	if err = c.UnblockPeer(peerID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Unblocked peer %s\n", peerID)
+	return nil
+}
+
 func runBalances(ctx *cli.Context) error {
 	c := newRRPClient(ctx)
 	balances, err := c.Balances()
@@ -585,6 +684,26 @@ func runMake(ctx *cli.Context) error {
 	return nil
 }
 
+func runImportOffers(ctx *cli.Context) error {
+	data, err := os.ReadFile(ctx.String(flagOffersFile))
+	if err != nil {
+		return err
+	}
+
+	c := newRRPClient(ctx)
+	resp, err := c.ImportOffers(data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Peer ID: %s\n", resp.PeerID)
+	for _, id := range resp.OfferIDs {
+		fmt.Printf("Offer ID: %s\n", id)
+	}
+
+	return nil
+}
+
 func runTake(ctx *cli.Context) error {
 	peerID, err := peer.Decode(ctx.String(flagPeerID))
 	if err != nil {
@@ -672,6 +791,9 @@ func runGetOngoingSwap(ctx *cli.Context) error {
 		fmt.Printf("Receiving: %s %s\n", info.ExpectedAmount.Text('f'), receivedCoin)
 		fmt.Printf("Exchange Rate: %s ETH/XMR\n", info.ExchangeRate)
 		fmt.Printf("Status: %s\n", info.Status)
+		if info.CounterpartyPeerID != "" {
+			fmt.Printf("Counterparty peer ID: %s\n", info.CounterpartyPeerID)
+		}
 		fmt.Printf("Time status was last updated: %s\n", info.LastStatusUpdateTime.Format(common.TimeFmtSecs))
 		if info.Timeout0 != nil && info.Timeout1 != nil {
 			fmt.Printf("First timeout: %s\n", info.Timeout0.Format(common.TimeFmtSecs))
@@ -728,6 +850,9 @@ func runGetPastSwap(ctx *cli.Context) error {
 		fmt.Printf("Received: %s %s\n", info.ExpectedAmount.Text('f'), receivedCoin)
 		fmt.Printf("Exchange Rate: %s ETH/XMR\n", info.ExchangeRate)
 		fmt.Printf("Status: %s\n", info.Status)
+		if info.CounterpartyPeerID != "" {
+			fmt.Printf("Counterparty peer ID: %s\n", info.CounterpartyPeerID)
+		}
 	}
 
 	return nil
@@ -818,6 +943,36 @@ func runGetOffers(ctx *cli.Context) error {
 	return nil
 }
 
+func runGetOfferLiquidity(ctx *cli.Context) error {
+	c := newRRPClient(ctx)
+	resp, err := c.GetOfferLiquidity()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Peer ID (self):", resp.PeerID)
+	fmt.Printf("Unlocked balance: %s XMR\n", resp.UnlockedBalance.Text('f'))
+	fmt.Printf("Reserved by swaps: %s XMR\n", resp.ReservedBalance.Text('f'))
+	fmt.Printf("Available: %s XMR\n", resp.AvailableBalance.Text('f'))
+	fmt.Println("Offers:")
+	for i, o := range resp.Offers {
+		if i > 0 {
+			fmt.Println("  ---")
+		}
+		fmt.Printf("  Offer ID: %s\n", o.OfferID)
+		fmt.Printf("  Exchange Rate: %s %s/%s\n", o.ExchangeRate, o.EthAsset, o.Provides)
+		fmt.Printf("  Min: %s %s\n", o.MinAmount.Text('f'), o.Provides)
+		fmt.Printf("  Max: %s %s\n", o.MaxAmount.Text('f'), o.Provides)
+		fmt.Printf("  Taken: %t\n", o.Taken)
+		fmt.Printf("  Reserved: %s XMR\n", o.ReservedAmount.Text('f'))
+	}
+	if len(resp.Offers) == 0 {
+		fmt.Println("[no offers]")
+	}
+
+	return nil
+}
+
 func runGetStatus(ctx *cli.Context) error {
 	offerID, err := types.HexToHash(ctx.String(flagOfferID))
 	if err != nil {
@@ -862,6 +1017,52 @@ func runGetSwapTimeout(ctx *cli.Context) error {
 	return nil
 }
 
+func runSweepStrandedFunds(ctx *cli.Context) error {
+	c := newRRPClient(ctx)
+	resp, err := c.SweepStrandedFunds()
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Found) == 0 {
+		fmt.Println("No stranded XMR found")
+		return nil
+	}
+
+	for i, funds := range resp.Found {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Swap ID: %s\n", funds.SwapID)
+		fmt.Printf("Address: %s\n", funds.Address)
+		fmt.Printf("Amount: %s XMR\n", funds.Amount.Text('f'))
+		if funds.Error != "" {
+			fmt.Printf("Sweep failed: %s\n", funds.Error)
+			continue
+		}
+		fmt.Printf("Sweep TxIDs: %s\n", strings.Join(funds.TxIDs, ", "))
+	}
+
+	return nil
+}
+
+func runBackupDB(ctx *cli.Context) error {
+	// swapd only accepts absolute paths, so relative ones are resolved from
+	// our working directory
+	backupPath, err := filepath.Abs(ctx.String(flagBackupFile))
+	if err != nil {
+		return err
+	}
+
+	c := newRRPClient(ctx)
+	if err = c.BackupDatabase(backupPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Backed up database to %s\n", backupPath)
+	return nil
+}
+
 func runSuggestedExchangeRate(ctx *cli.Context) error {
 	c := newRRPClient(ctx)
 	resp, err := c.SuggestedExchangeRate()
@@ -7,20 +7,35 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 
+	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	logging "github.com/ipfs/go-log"
+	ma "github.com/multiformats/go-multiaddr"
 	"github.com/urfave/cli/v2"
 
 	"github.com/athanorlabs/atomic-swap/cliutil"
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/daemon"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/ethereum/watcher"
 	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/observer"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	"github.com/athanorlabs/atomic-swap/protocol/txsender"
+	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker"
+	"github.com/athanorlabs/atomic-swap/protocol/xmrtaker"
 	"github.com/athanorlabs/atomic-swap/relayer"
+	"github.com/athanorlabs/atomic-swap/webhook"
 )
 
 const (
@@ -33,6 +48,11 @@ const (
 	defaultRPCPort         = common.DefaultSwapdPort
 	defaultXMRTakerRPCPort = defaultRPCPort
 	defaultXMRMakerRPCPort = defaultXMRTakerRPCPort + 1
+
+	// envWebhookSecret is the environment variable that the secret webhook
+	// requests are signed with is read from, so that it isn't in the process
+	// list.
+	envWebhookSecret = "SWAPD_WEBHOOK_SECRET"
 )
 
 var (
@@ -55,11 +75,19 @@ const (
 	flagBootnodes  = "bootnodes"
 
 	flagEnv                  = "env"
+	flagLibp2pListenAddrs    = "libp2p-listen-addrs"
+	flagLibp2pAnnounceAddrs  = "libp2p-announce-addrs"
 	flagMoneroDaemonHost     = "monerod-host"
 	flagMoneroDaemonPort     = "monerod-port"
 	flagMoneroWalletPath     = "wallet-file"
 	flagMoneroWalletPassword = "wallet-password"
 	flagMoneroWalletPort     = "wallet-port"
+	flagMoneroAuditLog       = "wallet-audit-log"
+	flagMoneroRPCRetries     = "monero-rpc-retries"
+	flagMoneroWalletTimeout  = "monero-wallet-rpc-timeout"
+	flagMoneroRefreshTimeout = "monero-wallet-refresh-timeout"
+	flagMoneroBlockSleep     = "monero-block-poll-interval"
+	flagMoneroDaemonTimeout  = "monero-daemon-rpc-timeout"
 	flagEthereumEndpoint     = "ethereum-endpoint"
 	flagEthereumPrivKey      = "ethereum-privkey"
 	flagContractAddress      = "contract-address"
@@ -67,6 +95,69 @@ const (
 	flagGasLimit             = "gas-limit"
 	flagUseExternalSigner    = "external-signer"
 	flagRelayer              = "relayer"
+	flagBootnodeOnly         = "bootnode-only"
+	flagObserver             = "observer"
+	flagObserverInterval     = "observer-poll-interval"
+	flagRelayerRateLimit     = "relayer-rate-limit"
+	flagRelayerRateBurst     = "relayer-rate-burst"
+	flagRelayerForwarders    = "relayer-forwarders"
+	flagRelayerMaxConcurrent = "relayer-max-concurrent"
+	flagRelayerMaxQueued     = "relayer-max-queued"
+	flagRelayerClaimDetails  = "relayer-claim-details"
+	flagRelayerFees          = "relayer-fees"
+	flagRelayerPayoutAddress = "relayer-payout-address"
+	flagRelayerPayoutMin     = "relayer-payout-min"
+	flagRelayerClaimBuffer   = "relayer-claim-gas-buffer"
+	flagMaxRelayedClaimGas   = "max-relayed-claim-gas"
+	flagMaxDecodeFailures    = "max-decode-failures"
+	flagBlockOnDecodeFailure = "block-on-decode-failures"
+	flagSkipConnectivity     = "skip-connectivity-check"
+	flagXMRLockMargin        = "xmr-lock-margin"
+	flagPerSwapWallet        = "per-swap-wallet"
+	flagPerSwapAccount       = "per-swap-account"
+	flagReclaimOnDBFailure   = "reclaim-on-db-failure"
+	flagCollapseDupOffers    = "collapse-duplicate-offers"
+	flagClaimReceiptRetries  = "claim-receipt-retries"
+	flagRelayClaimRetries    = "relay-claim-retries"
+	flagClaimDeadlineMargin  = "claim-deadline-margin"
+	flagClaimBumpPercent     = "claim-gas-bump-percent"
+	flagClaimBumpInterval    = "claim-gas-bump-interval"
+	flagMaxClaimGasCost      = "max-claim-gas-cost"
+	flagXMRScanRollback      = "monero-start-height-rollback"
+	flagSweepConfirmations   = "monero-sweep-confirmations"
+	flagKeyGenRetries        = "key-gen-retries"
+	flagMaxConcurrentSwaps   = "max-concurrent-swaps"
+	flagDustThresholds       = "dust-thresholds"
+	flagMinExchangeRate      = "min-exchange-rate"
+	flagMaxExchangeRate      = "max-exchange-rate"
+	flagXMRLockTolerance     = "xmr-lock-tolerance"
+	flagMoneroLockPriority   = "monero-lock-priority"
+	flagETHLockConfirmations = "eth-lock-confirmations"
+	flagXMRReservation       = "xmr-reservation-window"
+	flagProofCacheSize       = "dleq-proof-cache-size"
+	flagKeyPoolSize          = "dleq-key-pool-size"
+	flagShutdownTimeout      = "shutdown-timeout"
+	flagRelayerRefresh       = "relayer-refresh-interval"
+	flagOfferBookConcurrency = "offer-book-concurrency"
+	flagOfferBookPeerTimeout = "offer-book-peer-timeout"
+	flagOfferBookTimeout     = "offer-book-timeout"
+	flagSwapResumeWindow     = "swap-resume-window"
+	flagSwapResumeRetries    = "swap-resume-retries"
+	flagMinRelayerSuccess    = "min-relayer-success-rate"
+	flagMetricsAddress       = "metrics-address"
+	flagRecoveryDBPrompt     = "recovery-db-passphrase-prompt"
+	flagWebhookURL           = "webhook-url"
+	flagImportOffers         = "import-offers"
+	flagRestoreDB            = "restore-db"
+	flagWebhookRetries       = "webhook-retries"
+	flagWebhookRetryInterval = "webhook-retry-interval"
+	flagETHPollInterval      = "eth-poll-interval"
+	flagETHSubscribe         = "eth-subscribe"
+	flagResumeConcurrency    = "resume-concurrency"
+	flagMaxOfferFraction     = "max-offer-balance-fraction"
+	flagOfferRevalidation    = "offer-revalidation-interval"
+	flagPeerScoreWeights     = "peer-score-weights"
+	flagAcceptUnsigned       = "accept-unsigned-offers"
 
 	flagDevXMRTaker      = "dev-xmrtaker"
 	flagDevXMRMaker      = "dev-xmrmaker"
@@ -86,6 +177,9 @@ func cliApp() *cli.App {
 		Action:               runDaemon,
 		EnableBashCompletion: true,
 		Suggest:              true,
+		Commands: []*cli.Command{
+			recoverXMRCommand(),
+		},
 		Flags: []cli.Flag{
 			&cli.UintFlag{
 				Name:  flagRPCPort,
@@ -107,6 +201,16 @@ func cliApp() *cli.App {
 				Usage: "libp2p port to listen on",
 				Value: defaultLibp2pPort,
 			},
+			&cli.StringSliceFlag{
+				Name: flagLibp2pListenAddrs,
+				Usage: "libp2p multiaddrs to listen on, like /ip4/10.0.0.2/tcp/9900, instead of the libp2p port." +
+					" They must share an IP and a port",
+			},
+			&cli.StringSliceFlag{
+				Name: flagLibp2pAnnounceAddrs,
+				Usage: "libp2p multiaddrs to announce to peers instead of the addresses listened on," +
+					" like /ip4/203.0.113.5/tcp/9900",
+			},
 			&cli.StringFlag{
 				Name:  flagEnv,
 				Usage: "Environment to use: one of mainnet, stagenet, or dev",
@@ -137,9 +241,50 @@ func cliApp() *cli.App {
 				Usage:  "The port that the internal monero-wallet-rpc instance listens on",
 				Hidden: true, // flag is for integration tests and won't be supported long term
 			},
+			&cli.StringFlag{
+				Name:  flagMoneroAuditLog,
+				Usage: "File to append an audit log of Monero wallet operations to, as JSON lines",
+			},
+			&cli.UintFlag{
+				Name:  flagMoneroRPCRetries,
+				Usage: "Times failed Monero RPC calls are retried while waiting for blocks (0 disables retries)",
+				Value: monero.DefaultRPCRetries,
+			},
+			&cli.DurationFlag{
+				Name:  flagMoneroWalletTimeout,
+				Usage: "Timeout of monero-wallet-rpc calls, other than wallet refreshes",
+				Value: monero.DefaultWalletRPCTimeout,
+			},
+			&cli.DurationFlag{
+				Name:  flagMoneroRefreshTimeout,
+				Usage: "Timeout of monero-wallet-rpc wallet refreshes, which scan the blocks the wallet missed",
+				Value: monero.DefaultWalletRefreshTimeout,
+			},
+			&cli.DurationFlag{
+				Name:  flagMoneroDaemonTimeout,
+				Usage: "Timeout of monerod RPC calls",
+				Value: monero.DefaultDaemonRPCTimeout,
+			},
+			&cli.DurationFlag{
+				Name: flagMoneroBlockSleep,
+				Usage: fmt.Sprintf("How often to check for new Monero blocks while waiting for them,"+
+					" at least %s", monero.MinBlockSleepDuration),
+				Value: monero.DefaultBlockSleepDuration,
+			},
 			&cli.StringFlag{
 				Name:  flagEthereumEndpoint,
-				Usage: "Ethereum client endpoint",
+				Usage: "Ethereum client endpoint: an http://, https://, ws:// or wss:// URL, or the path of an IPC socket",
+			},
+			&cli.DurationFlag{
+				Name: flagETHPollInterval,
+				Usage: "How often swap event watchers poll the ethereum endpoint for new blocks;" +
+					" raise it for rate-limited endpoints",
+				Value: watcher.DefaultPollInterval,
+			},
+			&cli.BoolFlag{
+				Name: flagETHSubscribe,
+				Usage: "Have swap event watchers subscribe to new blocks instead of polling for them;" +
+					" requires a WebSocket or IPC ethereum endpoint",
 			},
 			&cli.StringFlag{
 				Name:  flagEthereumPrivKey,
@@ -201,6 +346,295 @@ func cliApp() *cli.App {
 				),
 				Value: false,
 			},
+			&cli.BoolFlag{
+				Name:  flagBootnodeOnly,
+				Usage: "Only run the p2p host as a bootnode, without monero or ethereum wallets",
+			},
+			&cli.BoolFlag{
+				Name: flagObserver,
+				Usage: "Only observe the network's offers, and its on-chain swaps if --" + flagEthereumEndpoint +
+					" is set, for the observer_ RPC methods, without monero or ethereum wallets",
+			},
+			&cli.DurationFlag{
+				Name:  flagObserverInterval,
+				Usage: "How often an observer queries makers for their offers",
+				Value: observer.DefaultPollInterval,
+			},
+			&cli.Float64Flag{
+				Name:  flagRelayerRateLimit,
+				Usage: "Max sustained relay claim requests per second accepted from a single peer",
+				Value: net.DefaultRelayerRequestsPerSec,
+			},
+			&cli.UintFlag{
+				Name:  flagRelayerRateBurst,
+				Usage: "Max relay claim requests a single peer can send in a burst",
+				Value: net.DefaultRelayerRequestBurst,
+			},
+			&cli.UintFlag{
+				Name:  flagRelayerMaxConcurrent,
+				Usage: "Max relay claim requests validated and submitted at the same time",
+				Value: net.DefaultRelayerMaxConcurrent,
+			},
+			&cli.UintFlag{
+				Name:  flagRelayerMaxQueued,
+				Usage: "Max relay claim requests waiting for validation before new requests are rejected",
+				Value: net.DefaultRelayerMaxQueued,
+			},
+			&cli.StringSliceFlag{
+				Name:  flagRelayerForwarders,
+				Usage: "Trusted forwarder addresses to accept relay claims for, comma separated (default: any verified forwarder)",
+			},
+			&cli.UintFlag{
+				Name:  flagMaxDecodeFailures,
+				Usage: "Malformed messages tolerated from a peer before its streams are closed (0 drops it on the first)",
+				Value: net.DefaultMaxDecodeFailures,
+			},
+			&cli.BoolFlag{
+				Name:  flagBlockOnDecodeFailure,
+				Usage: "Blocklist peers that send more malformed messages than tolerated",
+			},
+			&cli.DurationFlag{
+				Name:  flagRelayerRefresh,
+				Usage: "Interval at which relayers to submit claims to are rediscovered in the background",
+				Value: net.DefaultRelayerRefreshInterval,
+			},
+			&cli.UintFlag{
+				Name:  flagOfferBookConcurrency,
+				Usage: "Number of peers queried at once when fetching the aggregated offer book",
+				Value: net.DefaultOfferBookConcurrency,
+			},
+			&cli.DurationFlag{
+				Name:  flagOfferBookPeerTimeout,
+				Usage: "How long to wait for each peer's offers when fetching the aggregated offer book",
+				Value: net.DefaultOfferBookPeerTimeout,
+			},
+			&cli.DurationFlag{
+				Name:  flagOfferBookTimeout,
+				Usage: "How long fetching the aggregated offer book takes at most",
+				Value: net.DefaultOfferBookTimeout,
+			},
+			&cli.DurationFlag{
+				Name:  flagSwapResumeWindow,
+				Usage: "How long a swap waits for its dropped stream with the peer to be reopened before exiting",
+				Value: net.DefaultSwapResumeWindow,
+			},
+			&cli.UintFlag{
+				Name:  flagSwapResumeRetries,
+				Usage: "How many times we try to reopen a dropped swap stream with the peer",
+				Value: net.DefaultSwapResumeRetries,
+			},
+			&cli.StringSliceFlag{
+				Name: flagPeerScoreWeights,
+				Usage: "Peer score weights in the connection manager, as SIGNAL=WEIGHT pairs where SIGNAL is" +
+					" success, refund, abort, violation or relay",
+			},
+			&cli.BoolFlag{
+				Name:  flagAcceptUnsigned,
+				Usage: "Keep the unsigned offers of makers running older versions, which can't be attributed to them",
+			},
+			&cli.Float64Flag{
+				Name:  flagMinRelayerSuccess,
+				Usage: "Fraction of claims (0-1) a relayer must have gotten mined for us to keep submitting claims to it",
+			},
+			&cli.BoolFlag{
+				Name:  flagRelayerClaimDetails,
+				Usage: "Report the fee charged, and the gas used and block number of relayed claims to the maker",
+			},
+			&cli.StringFlag{
+				Name: flagRelayerFees,
+				Usage: "JSON file of minimum relayer fees per asset, like {\"ETH\": 9000000000000000}, in the asset's" +
+					" smallest unit. Reloaded on SIGHUP. Default: only ETH is relayed",
+			},
+			&cli.StringFlag{
+				Name: flagRelayerPayoutAddress,
+				Usage: "Address that relayer fees are forwarded to after relayed claims, so they don't" +
+					" stay with the signing key (default: fees stay with the signing key)",
+			},
+			&cli.StringFlag{
+				Name: flagRelayerPayoutMin,
+				Usage: "Least ETH forwarded to the relayer payout address in one transfer. Fees, less the gas" +
+					" of their claims, accumulate until they reach it",
+				Value: coins.NewWeiAmount(relayer.DefaultPayoutMinWei).AsEtherString(),
+			},
+			&cli.Uint64Flag{
+				Name:  flagRelayerClaimBuffer,
+				Usage: "Percentage added to the estimated gas of claims that we submit to relayers",
+				Value: relayer.DefaultClaimGasBufferPercent,
+			},
+			&cli.Uint64Flag{
+				Name:  flagMaxRelayedClaimGas,
+				Usage: "Highest gas limit of claims that we submit to relayers, or that we relay",
+				Value: relayer.DefaultMaxClaimGas,
+			},
+			&cli.BoolFlag{
+				Name: flagSkipConnectivity,
+				Usage: "Accept takes of our offers without first checking that the ethereum endpoint" +
+					" and monero wallet are reachable and synced",
+			},
+			&cli.DurationFlag{
+				Name:  flagXMRLockMargin,
+				Usage: "How long before the swap's first timeout a maker's XMR lock must be confirmed by",
+			},
+			&cli.BoolFlag{
+				Name: flagPerSwapWallet,
+				Usage: "Lock and reclaim each swap's XMR using a dedicated wallet file named by the swap ID," +
+					" funded from the primary wallet before the lock",
+			},
+			&cli.BoolFlag{
+				Name:  flagPerSwapAccount,
+				Usage: "Sweep each swap's claimed or reclaimed XMR to a new account of the primary wallet",
+			},
+			&cli.BoolFlag{
+				Name:  flagReclaimOnDBFailure,
+				Usage: "Reclaim refunded XMR even if the counterparty's swap key can't be stored in the database",
+			},
+			&cli.BoolFlag{
+				Name:  flagCollapseDupOffers,
+				Usage: "Reuse an existing offer with the same terms instead of making a duplicate offer",
+			},
+			&cli.UintFlag{
+				Name:  flagClaimReceiptRetries,
+				Usage: "Times to retry fetching the receipt of a relayed claim after an ethereum RPC error",
+				Value: xmrmaker.DefaultClaimReceiptRetries,
+			},
+			&cli.UintFlag{
+				Name:  flagRelayClaimRetries,
+				Usage: "Times a maker resubmits a relayed claim to relayers that failed transiently",
+				Value: xmrmaker.DefaultRelayClaimRetries,
+			},
+			&cli.DurationFlag{
+				Name: flagClaimDeadlineMargin,
+				Usage: fmt.Sprintf("How long before t1 a maker stops waiting on relayers and claims directly"+
+					" (default: %d ethereum block times)", xmrmaker.DefaultClaimDeadlineBlocks),
+			},
+			&cli.Uint64Flag{
+				Name:  flagClaimBumpPercent,
+				Usage: "Percentage that the gas price of a stuck claim sent by a maker is raised by when it's replaced",
+				Value: txsender.DefaultGasBumpPercent,
+			},
+			&cli.DurationFlag{
+				Name:  flagClaimBumpInterval,
+				Usage: "How long a claim sent by a maker can be pending before it's replaced with a higher gas price",
+				Value: txsender.DefaultGasBumpInterval,
+			},
+			&cli.StringFlag{
+				Name: flagMaxClaimGasCost,
+				Usage: "Most ETH that a claim sent by a maker can spend on gas when its gas price is raised" +
+					" (default: no limit, but the gas price is raised at most 3 times)",
+			},
+			&cli.Uint64Flag{
+				Name:  flagXMRScanRollback,
+				Usage: "Blocks below the current monero height to scan from for a swap's XMR lock (0 for private dev chains)",
+				Value: monero.MinSpendConfirmations,
+			},
+			&cli.Uint64Flag{
+				Name: flagSweepConfirmations,
+				Usage: fmt.Sprintf("Confirmations to wait for when sweeping claimed XMR out of a swap wallet,"+
+					" at least %d unless on a dev network (default: %d)",
+					monero.MinSpendConfirmations, monero.SweepToSelfConfirmations),
+			},
+			&cli.Uint64Flag{
+				Name:  flagXMRLockTolerance,
+				Usage: "Piconeros that a maker's XMR lock may fall short of the expected amount, for rounding",
+				Value: xmrtaker.DefaultXMRLockTolerance,
+			},
+			&cli.UintFlag{
+				Name:  flagKeyGenRetries,
+				Usage: "Times a maker retries generating its swap keys and proof when an offer is taken",
+				Value: xmrmaker.DefaultKeyGenRetries,
+			},
+			&cli.StringSliceFlag{
+				Name:  flagDustThresholds,
+				Usage: "Min value of offer minimums per asset, as ASSET=AMOUNT pairs where ASSET is ETH or a token address",
+			},
+			&cli.StringFlag{
+				Name:  flagMinExchangeRate,
+				Usage: "Min plausible ETH per XMR exchange rate of ETH offers (default: 0.001 on mainnet, else none)",
+			},
+			&cli.StringFlag{
+				Name:  flagMaxExchangeRate,
+				Usage: "Max plausible ETH per XMR exchange rate of ETH offers (default: 1 on mainnet, else none)",
+			},
+			&cli.StringFlag{
+				Name:  flagMoneroLockPriority,
+				Usage: "Priority of a maker's XMR lock transfer: default, low, elevated or priority",
+				Value: types.MoneroPriorityDefault.String(),
+			},
+			&cli.Uint64Flag{
+				Name: flagETHLockConfirmations,
+				Usage: fmt.Sprintf("Confirmations a maker waits for on the taker's ETH lock before locking XMR"+
+					" (default: %d, or 0 on a dev network)", xmrmaker.DefaultETHLockConfirmations),
+			},
+			&cli.DurationFlag{
+				Name:  flagXMRReservation,
+				Usage: "How long a maker holds back the XMR of an accepted take from other takes while waiting to lock it",
+				Value: xmrmaker.DefaultXMRReservationWindow,
+			},
+			&cli.UintFlag{
+				Name:  flagProofCacheSize,
+				Usage: "Verified DLEq proofs of takers a maker remembers, to skip verifying repeated ones (default: none)",
+			},
+			&cli.UintFlag{
+				Name:  flagKeyPoolSize,
+				Usage: "Swap keys and DLEq proofs a maker generates ahead of time, for faster swap starts (default: none)",
+			},
+			&cli.Float64Flag{
+				Name:  flagMaxOfferFraction,
+				Usage: "Largest fraction (0-1) of our unlocked XMR balance that an offer's max amount can be (default: unlimited)",
+			},
+			&cli.DurationFlag{
+				Name: flagOfferRevalidation,
+				Usage: "How often offers are checked against --" + flagMaxOfferFraction +
+					", warning about those our balance no longer backs (default: never)",
+			},
+			&cli.UintFlag{
+				Name:  flagResumeConcurrency,
+				Usage: "Ongoing swaps resumed at once on startup, those closest to timing out first",
+				Value: pcommon.DefaultResumeConcurrency,
+			},
+			&cli.UintFlag{
+				Name:  flagMaxConcurrentSwaps,
+				Usage: "Max swaps a maker runs at once, further takes are rejected (default: unlimited)",
+			},
+			&cli.DurationFlag{
+				Name:  flagShutdownTimeout,
+				Usage: "How long to wait for in-flight swap locks, claims and refunds to finish when shutting down",
+				Value: daemon.DefaultShutdownTimeout,
+			},
+			&cli.StringFlag{
+				Name:  flagMetricsAddress,
+				Usage: "BIND_IP:PORT to serve Prometheus metrics on at /metrics (default: disabled)",
+			},
+			&cli.StringFlag{
+				Name: flagImportOffers,
+				Usage: "Path to an offer book, a JSON array of offers with the same fields as net_makeOffer, " +
+					"to make when starting. Offers with the same terms as a current offer aren't made again",
+			},
+			&cli.StringFlag{
+				Name: flagRestoreDB,
+				Usage: "Path to a backup written by personal_backupDatabase, to restore into the db when " +
+					"starting. The db must be empty",
+			},
+			&cli.StringFlag{
+				Name: flagWebhookURL,
+				Usage: "URL to POST swap status changes to as JSON (default: disabled). Requests are signed " +
+					"with HMAC-SHA256 if the " + envWebhookSecret + " environment variable is set",
+			},
+			&cli.UintFlag{
+				Name:  flagWebhookRetries,
+				Usage: "Retries of a failed webhook delivery before it's written to the dead-letter log",
+				Value: webhook.DefaultMaxRetries,
+			},
+			&cli.DurationFlag{
+				Name:  flagWebhookRetryInterval,
+				Usage: "Wait before the first retry of a failed webhook delivery, doubled for each retry",
+				Value: webhook.DefaultRetryInterval,
+			},
+			&cli.BoolFlag{
+				Name: flagRecoveryDBPrompt,
+				Usage: "Read a passphrase to encrypt the swap recovery db with from standard input. " +
+					"It can also be set with the " + envRecoveryDBPassphrase + " environment variable",
+			},
 			&cli.StringFlag{
 				Name:   flagProfile,
 				Usage:  "BIND_IP:PORT to provide profiling information on",
@@ -250,6 +684,7 @@ func setLogLevels(level string) {
 	_ = logging.SetLogLevel("cmd", level)
 	_ = logging.SetLogLevel("extethclient", level)
 	_ = logging.SetLogLevel("ethereum/watcher", level)
+	_ = logging.SetLogLevel("metrics", level)
 	_ = logging.SetLogLevel("monero", level)
 	_ = logging.SetLogLevel("net", level)
 	_ = logging.SetLogLevel("offers", level)
@@ -286,7 +721,28 @@ func runDaemon(c *cli.Context) error {
 		return err
 	}
 
-	mc, err := createMoneroClient(c, envConf)
+	if c.Bool(flagBootnodeOnly) {
+		return runBootnode(c, envConf)
+	}
+
+	if c.Bool(flagObserver) {
+		return runObserver(c, envConf)
+	}
+
+	var auditLog *monero.AuditLog
+	if c.IsSet(flagMoneroAuditLog) {
+		auditLogPath := c.String(flagMoneroAuditLog)
+		if auditLogPath == "" {
+			return errFlagValueEmpty(flagMoneroAuditLog)
+		}
+		auditLog, err = monero.NewAuditLog(auditLogPath)
+		if err != nil {
+			return fmt.Errorf("failed to open wallet audit log: %w", err)
+		}
+		defer func() { _ = auditLog.Close() }()
+	}
+
+	mc, err := createMoneroClient(c, envConf, auditLog)
 	if err != nil {
 		return err
 	}
@@ -311,6 +767,10 @@ func runDaemon(c *cli.Context) error {
 		return err
 	}
 
+	if conf.RelayerFees != nil {
+		go reloadRelayerFeesOnSIGHUP(c.Context, c.String(flagRelayerFees), conf.RelayerFees)
+	}
+
 	err = daemon.RunSwapDaemon(c.Context, conf)
 	if err != nil && !errors.Is(err, context.Canceled) {
 		return err
@@ -319,6 +779,88 @@ func runDaemon(c *cli.Context) error {
 	return nil
 }
 
+// runBootnode runs swapd as a bootnode, which doesn't need monero or ethereum
+// clients.
+func runBootnode(c *cli.Context, envConf *common.Config) error {
+	if c.Bool(flagRelayer) {
+		return errFlagsMutuallyExclusive(flagBootnodeOnly, flagRelayer)
+	}
+
+	libp2pKeyFile, libp2pPort, err := getLibp2pKeyFileAndPort(c, envConf)
+	if err != nil {
+		return err
+	}
+
+	listenAddrs, err := getLibp2pListenAddrs(c)
+	if err != nil {
+		return err
+	}
+
+	announceAddrs, err := getMultiaddrs(c, flagLibp2pAnnounceAddrs)
+	if err != nil {
+		return err
+	}
+
+	err = daemon.RunSwapDaemon(c.Context, &daemon.SwapdConfig{
+		EnvConf:             envConf,
+		Libp2pPort:          libp2pPort,
+		Libp2pKeyfile:       libp2pKeyFile,
+		Libp2pListenAddrs:   listenAddrs,
+		Libp2pAnnounceAddrs: announceAddrs,
+		BootnodeOnly:        true,
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
+
+// runObserver runs swapd as an observer, which doesn't need monero or ethereum
+// wallets. The ethereum endpoint is only used, to observe on-chain swaps, if
+// it's set.
+func runObserver(c *cli.Context, envConf *common.Config) error {
+	if c.Bool(flagBootnodeOnly) {
+		return errFlagsMutuallyExclusive(flagBootnodeOnly, flagObserver)
+	}
+	if c.Bool(flagRelayer) {
+		return errFlagsMutuallyExclusive(flagObserver, flagRelayer)
+	}
+
+	libp2pKeyFile, libp2pPort, err := getLibp2pKeyFileAndPort(c, envConf)
+	if err != nil {
+		return err
+	}
+
+	listenAddrs, err := getLibp2pListenAddrs(c)
+	if err != nil {
+		return err
+	}
+
+	announceAddrs, err := getMultiaddrs(c, flagLibp2pAnnounceAddrs)
+	if err != nil {
+		return err
+	}
+
+	err = daemon.RunSwapDaemon(c.Context, &daemon.SwapdConfig{
+		EnvConf:              envConf,
+		Libp2pPort:           libp2pPort,
+		Libp2pKeyfile:        libp2pKeyFile,
+		Libp2pListenAddrs:    listenAddrs,
+		Libp2pAnnounceAddrs:  announceAddrs,
+		RPCPort:              uint16(c.Uint(flagRPCPort)),
+		ETHPollInterval:      c.Duration(flagETHPollInterval),
+		ObserverOnly:         true,
+		ObserverEthEndpoint:  c.String(flagEthereumEndpoint),
+		ObserverPollInterval: c.Duration(flagObserverInterval),
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
+
 // getEnvConfig returns the environment specific config, adjusting all values changed by
 // command line options.
 func getEnvConfig(c *cli.Context, devXMRMaker bool, devXMRTaker bool) (*common.Config, error) {
@@ -351,6 +893,21 @@ func getEnvConfig(c *cli.Context, devXMRMaker bool, devXMRTaker bool) (*common.C
 		conf.Bootnodes = cliutil.ExpandBootnodes(c.StringSlice(flagBootnodes))
 	}
 
+	if c.IsSet(flagMinExchangeRate) {
+		if conf.MinExchangeRate, err = parseExchangeRateBound(c, flagMinExchangeRate); err != nil {
+			return nil, err
+		}
+	}
+	if c.IsSet(flagMaxExchangeRate) {
+		if conf.MaxExchangeRate, err = parseExchangeRateBound(c, flagMaxExchangeRate); err != nil {
+			return nil, err
+		}
+	}
+	if conf.MinExchangeRate != nil && conf.MaxExchangeRate != nil &&
+		conf.MinExchangeRate.Cmp(conf.MaxExchangeRate) > 0 {
+		return nil, fmt.Errorf("%q can't be greater than %q", flagMinExchangeRate, flagMaxExchangeRate)
+	}
+
 	deploy := c.Bool(flagDeploy)
 	if deploy {
 		if c.IsSet(flagContractAddress) {
@@ -367,7 +924,8 @@ func getEnvConfig(c *cli.Context, devXMRMaker bool, devXMRTaker bool) (*common.C
 			conf.SwapFactoryAddress = ethcommon.HexToAddress(contractAddrStr)
 		}
 
-		if conf.SwapFactoryAddress == (ethcommon.Address{}) {
+		onlyP2P := c.Bool(flagBootnodeOnly) || c.Bool(flagObserver)
+		if conf.SwapFactoryAddress == (ethcommon.Address{}) && !onlyP2P {
 			return nil, fmt.Errorf("flag %q or %q is required for env=%s", flagDeploy, flagContractAddress, env)
 		}
 	}
@@ -375,6 +933,22 @@ func getEnvConfig(c *cli.Context, devXMRMaker bool, devXMRTaker bool) (*common.C
 	return conf, nil
 }
 
+// parseExchangeRateBound returns the exchange rate bound set by the flag. An
+// empty value removes the bound.
+func parseExchangeRateBound(c *cli.Context, flagName string) (*apd.Decimal, error) {
+	boundStr := c.String(flagName)
+	if boundStr == "" {
+		return nil, nil
+	}
+
+	bound, _, err := apd.NewFromString(boundStr)
+	if err != nil || bound.Sign() <= 0 {
+		return nil, fmt.Errorf("%q requires a positive exchange rate", flagName)
+	}
+
+	return bound, nil
+}
+
 // validateOrDeployContracts validates or deploys the swap factory. The SwapFactoryAddress field
 // of envConf should be all zeros if deploying and its value will be replaced by the new deployed
 // contract.
@@ -415,25 +989,13 @@ func validateOrDeployContracts(c *cli.Context, envConf *common.Config, ec exteth
 	return nil
 }
 
-func createMoneroClient(c *cli.Context, envConf *common.Config) (monero.WalletClient, error) {
-	if c.IsSet(flagMoneroDaemonHost) || c.IsSet(flagMoneroDaemonPort) {
-		node := &common.MoneroNode{
-			Host: "127.0.0.1",
-			Port: common.DefaultMoneroPortFromEnv(envConf.Env),
-		}
-		if c.IsSet(flagMoneroDaemonHost) {
-			node.Host = c.String(flagMoneroDaemonHost)
-			if node.Host == "" {
-				return nil, errFlagValueEmpty(flagMoneroDaemonHost)
-			}
-		}
-		if c.IsSet(flagMoneroDaemonPort) {
-			node.Port = c.Uint(flagMoneroDaemonPort)
-			if node.Port == 0 {
-				return nil, errFlagValueZero(flagMoneroDaemonPort)
-			}
-		}
-		envConf.MoneroNodes = []*common.MoneroNode{node}
+func createMoneroClient(
+	c *cli.Context,
+	envConf *common.Config,
+	auditLog *monero.AuditLog,
+) (monero.WalletClient, error) {
+	if err := setMoneroNodes(c, envConf); err != nil {
+		return nil, err
 	}
 
 	walletFilePath := envConf.MoneroWalletPath()
@@ -444,6 +1006,9 @@ func createMoneroClient(c *cli.Context, envConf *common.Config) (monero.WalletCl
 		}
 	}
 
+	// the flag has a default, so zero is an explicit request for no retries
+	rpcRetries := c.Uint(flagMoneroRPCRetries)
+
 	return monero.NewWalletClient(&monero.WalletClientConf{
 		Env:                 envConf.Env,
 		WalletFilePath:      walletFilePath,
@@ -451,9 +1016,41 @@ func createMoneroClient(c *cli.Context, envConf *common.Config) (monero.WalletCl
 		MoneroWalletRPCPath: "", // look for it in "monero-bin/monero-wallet-rpc" and then the user's path
 		WalletPassword:      c.String(flagMoneroWalletPassword),
 		WalletPort:          c.Uint(flagMoneroWalletPort),
+		RPCRetries:          &rpcRetries,
+		WalletRPCTimeout:    c.Duration(flagMoneroWalletTimeout),
+		RefreshTimeout:      c.Duration(flagMoneroRefreshTimeout),
+		DaemonRPCTimeout:    c.Duration(flagMoneroDaemonTimeout),
+		BlockSleepDuration:  c.Duration(flagMoneroBlockSleep),
+		AuditLog:            auditLog,
 	})
 }
 
+// setMoneroNodes overrides the env config's monerod nodes if the user set the
+// monerod host or port flags.
+func setMoneroNodes(c *cli.Context, envConf *common.Config) error {
+	if c.IsSet(flagMoneroDaemonHost) || c.IsSet(flagMoneroDaemonPort) {
+		node := &common.MoneroNode{
+			Host: "127.0.0.1",
+			Port: common.DefaultMoneroPortFromEnv(envConf.Env),
+		}
+		if c.IsSet(flagMoneroDaemonHost) {
+			node.Host = c.String(flagMoneroDaemonHost)
+			if node.Host == "" {
+				return errFlagValueEmpty(flagMoneroDaemonHost)
+			}
+		}
+		if c.IsSet(flagMoneroDaemonPort) {
+			node.Port = c.Uint(flagMoneroDaemonPort)
+			if node.Port == 0 {
+				return errFlagValueZero(flagMoneroDaemonPort)
+			}
+		}
+		envConf.MoneroNodes = []*common.MoneroNode{node}
+	}
+
+	return nil
+}
+
 func createEthClient(c *cli.Context, envConf *common.Config) (extethclient.EthClient, error) {
 	env := envConf.Env
 
@@ -496,6 +1093,12 @@ func createEthClient(c *cli.Context, envConf *common.Config) (extethclient.EthCl
 		return nil, err
 	}
 
+	if c.Bool(flagETHSubscribe) && !extendedEC.SupportsSubscriptions() {
+		extendedEC.Close()
+		return nil, fmt.Errorf("%q is set, but %s: %w", flagETHSubscribe, ethEndpoint,
+			extethclient.ErrSubscriptionsUnsupported)
+	}
+
 	// TODO: add configs for different eth testnets + L2 and set gas limit based on those, if not set (#153)
 	extendedEC.SetGasPrice(uint64(c.Uint(flagGasPrice)))
 	extendedEC.SetGasLimit(uint64(c.Uint(flagGasLimit)))
@@ -503,18 +1106,14 @@ func createEthClient(c *cli.Context, envConf *common.Config) (extethclient.EthCl
 	return extendedEC, nil
 }
 
-func createSwapdConf(
-	c *cli.Context,
-	envConf *common.Config,
-	mc monero.WalletClient,
-	ec extethclient.EthClient,
-) (*daemon.SwapdConfig, error) {
-
+// getLibp2pKeyFileAndPort returns the libp2p key file and port from the flags,
+// with the defaults of dev makers and takers applied.
+func getLibp2pKeyFileAndPort(c *cli.Context, envConf *common.Config) (string, uint16, error) {
 	libp2pKeyFile := envConf.LibP2PKeyFile()
 	if c.IsSet(flagLibp2pKey) {
 		libp2pKeyFile = c.String(flagLibp2pKey)
 		if libp2pKeyFile == "" {
-			return nil, errFlagValueEmpty(flagLibp2pKey)
+			return "", 0, errFlagValueEmpty(flagLibp2pKey)
 		}
 	}
 
@@ -528,6 +1127,58 @@ func createSwapdConf(
 		}
 	}
 
+	return libp2pKeyFile, uint16(libp2pPort), nil
+}
+
+// getLibp2pListenAddrs parses the libp2p listen multiaddrs, if they're set.
+// They're validated further when the p2p host is created.
+func getLibp2pListenAddrs(c *cli.Context) ([]ma.Multiaddr, error) {
+	if !c.IsSet(flagLibp2pListenAddrs) {
+		return nil, nil
+	}
+
+	if c.IsSet(flagLibp2pPort) {
+		return nil, errFlagsMutuallyExclusive(flagLibp2pListenAddrs, flagLibp2pPort)
+	}
+
+	return getMultiaddrs(c, flagLibp2pListenAddrs)
+}
+
+// getMultiaddrs parses the multiaddrs of the passed flag, if it's set.
+func getMultiaddrs(c *cli.Context, flag string) ([]ma.Multiaddr, error) {
+	var addrs []ma.Multiaddr
+	for _, s := range c.StringSlice(flag) {
+		addr, err := ma.NewMultiaddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q: invalid multiaddr %q: %w", flag, s, err)
+		}
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, nil
+}
+
+func createSwapdConf(
+	c *cli.Context,
+	envConf *common.Config,
+	mc monero.WalletClient,
+	ec extethclient.EthClient,
+) (*daemon.SwapdConfig, error) {
+	libp2pKeyFile, libp2pPort, err := getLibp2pKeyFileAndPort(c, envConf)
+	if err != nil {
+		return nil, err
+	}
+
+	listenAddrs, err := getLibp2pListenAddrs(c)
+	if err != nil {
+		return nil, err
+	}
+
+	announceAddrs, err := getMultiaddrs(c, flagLibp2pAnnounceAddrs)
+	if err != nil {
+		return nil, err
+	}
+
 	rpcPort := c.Uint(flagRPCPort)
 	if !c.IsSet(flagRPCPort) {
 		switch {
@@ -538,15 +1189,227 @@ func createSwapdConf(
 		}
 	}
 
+	recoveryDBPassphrase, err := getRecoveryDBPassphrase(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var relayerForwarders []ethcommon.Address
+	for _, addrStr := range c.StringSlice(flagRelayerForwarders) {
+		if !ethcommon.IsHexAddress(addrStr) {
+			return nil, fmt.Errorf("%q requires valid ethereum addresses", flagRelayerForwarders)
+		}
+		relayerForwarders = append(relayerForwarders, ethcommon.HexToAddress(addrStr))
+	}
+
+	var relayerPayoutAddr *ethcommon.Address
+	if c.IsSet(flagRelayerPayoutAddress) {
+		addrStr := c.String(flagRelayerPayoutAddress)
+		if !ethcommon.IsHexAddress(addrStr) {
+			return nil, fmt.Errorf("%q requires a valid ethereum address", flagRelayerPayoutAddress)
+		}
+		addr := ethcommon.HexToAddress(addrStr)
+		if addr == (ethcommon.Address{}) {
+			return nil, fmt.Errorf("%q cannot be the zero address", flagRelayerPayoutAddress)
+		}
+		relayerPayoutAddr = &addr
+	}
+
+	var relayerPayoutMin *big.Int
+	if c.IsSet(flagRelayerPayoutMin) {
+		var minETH *apd.Decimal
+		minETH, _, err = apd.NewFromString(c.String(flagRelayerPayoutMin))
+		if err != nil || minETH.Sign() < 0 {
+			return nil, fmt.Errorf("%q requires a non-negative ETH amount", flagRelayerPayoutMin)
+		}
+		relayerPayoutMin = coins.EtherToWei(minETH).BigInt()
+	}
+
+	relayerClaimGas := &relayer.ClaimGasConfig{
+		BufferPercent: c.Uint64(flagRelayerClaimBuffer),
+		MaxGas:        c.Uint64(flagMaxRelayedClaimGas),
+	}
+	if relayerClaimGas.MaxGas == 0 {
+		return nil, errFlagValueZero(flagMaxRelayedClaimGas)
+	}
+
+	claimGasBump := &txsender.GasBumpConfig{
+		Percent:  c.Uint64(flagClaimBumpPercent),
+		Interval: c.Duration(flagClaimBumpInterval),
+	}
+	if claimGasBump.Percent < txsender.MinGasBumpPercent {
+		return nil, fmt.Errorf("%q must be at least %d", flagClaimBumpPercent, txsender.MinGasBumpPercent)
+	}
+	if claimGasBump.Interval == 0 {
+		return nil, errFlagValueZero(flagClaimBumpInterval)
+	}
+	if c.IsSet(flagMaxClaimGasCost) {
+		var maxCost *apd.Decimal
+		maxCost, _, err = apd.NewFromString(c.String(flagMaxClaimGasCost))
+		if err != nil || maxCost.Sign() <= 0 {
+			return nil, fmt.Errorf("%q requires a positive ETH amount", flagMaxClaimGasCost)
+		}
+		claimGasBump.MaxGasCost = coins.EtherToWei(maxCost).BigInt()
+	}
+
+	dustThresholds := make(types.DustThresholds)
+	for _, pair := range c.StringSlice(flagDustThresholds) {
+		assetStr, amountStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q requires ASSET=AMOUNT pairs", flagDustThresholds)
+		}
+
+		var asset types.EthAsset
+		if err = asset.UnmarshalText([]byte(assetStr)); err != nil {
+			return nil, fmt.Errorf("%q: %w", flagDustThresholds, err)
+		}
+
+		var amount *apd.Decimal
+		amount, _, err = apd.NewFromString(amountStr)
+		if err != nil || amount.Negative {
+			return nil, fmt.Errorf("%q requires non-negative amounts", flagDustThresholds)
+		}
+		dustThresholds[asset] = amount
+	}
+
+	var relayerFees *relayer.FeeTable
+	if c.IsSet(flagRelayerFees) {
+		feesFile := c.String(flagRelayerFees)
+		if feesFile == "" {
+			return nil, errFlagValueEmpty(flagRelayerFees)
+		}
+
+		var fees map[types.EthAsset]*big.Int
+		fees, err = relayer.LoadFees(feesFile)
+		if err != nil {
+			return nil, err
+		}
+		relayerFees = relayer.NewFeeTable(fees)
+	}
+
+	// the flag defaults to monero.MinSpendConfirmations, so zero is always an
+	// explicit request to disable the rollback
+	moneroStartHeightRollback := c.Uint64(flagXMRScanRollback)
+
+	// similarly, zero is an explicit request for no tolerance
+	xmrLockTolerance := c.Uint64(flagXMRLockTolerance)
+
+	// zero drops a peer on its first malformed message
+	maxDecodeFailures := c.Uint(flagMaxDecodeFailures)
+
+	// dev networks only mine blocks with new transactions, so waiting for
+	// confirmations could stall swaps
+	ethLockConfirmations := c.Uint64(flagETHLockConfirmations)
+	if !c.IsSet(flagETHLockConfirmations) && envConf.Env != common.Development {
+		ethLockConfirmations = xmrmaker.DefaultETHLockConfirmations
+	}
+
+	moneroLockPriority, err := types.NewMoneroTxPriority(c.String(flagMoneroLockPriority))
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", flagMoneroLockPriority, err)
+	}
+
+	minRelayerSuccessRate := c.Float64(flagMinRelayerSuccess)
+	if minRelayerSuccessRate < 0 || minRelayerSuccessRate > 1 {
+		return nil, fmt.Errorf("%q must be between 0 and 1", flagMinRelayerSuccess)
+	}
+
+	var peerScoreWeights *net.PeerScoreWeights
+	if c.IsSet(flagPeerScoreWeights) {
+		weights := net.DefaultPeerScoreWeights
+		for _, pair := range c.StringSlice(flagPeerScoreWeights) {
+			signal, weightStr, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("%q requires SIGNAL=WEIGHT pairs", flagPeerScoreWeights)
+			}
+
+			var weight int
+			weight, err = strconv.Atoi(weightStr)
+			if err != nil {
+				return nil, fmt.Errorf("%q requires integer weights: %w", flagPeerScoreWeights, err)
+			}
+
+			if err = weights.Set(signal, weight); err != nil {
+				return nil, fmt.Errorf("%q: %w", flagPeerScoreWeights, err)
+			}
+		}
+		peerScoreWeights = &weights
+	}
+
+	maxOfferBalanceFraction := c.Float64(flagMaxOfferFraction)
+	if maxOfferBalanceFraction < 0 || maxOfferBalanceFraction > 1 {
+		return nil, fmt.Errorf("%q must be between 0 and 1", flagMaxOfferFraction)
+	}
+
 	return &daemon.SwapdConfig{
 		EnvConf:        envConf,
-		Libp2pPort:     uint16(libp2pPort),
+		Libp2pPort:     libp2pPort,
 		Libp2pKeyfile:  libp2pKeyFile,
 		RPCPort:        uint16(rpcPort),
 		IsRelayer:      c.Bool(flagRelayer),
 		NoTransferBack: c.Bool(flagNoTransferBack),
 		MoneroClient:   mc,
 		EthereumClient: ec,
+
+		Libp2pListenAddrs:      listenAddrs,
+		Libp2pAnnounceAddrs:    announceAddrs,
+		RelayerRequestsPerSec:  c.Float64(flagRelayerRateLimit),
+		RelayerRequestBurst:    c.Uint(flagRelayerRateBurst),
+		RelayerMaxConcurrent:   c.Uint(flagRelayerMaxConcurrent),
+		RelayerMaxQueued:       c.Uint(flagRelayerMaxQueued),
+		MaxDecodeFailures:      &maxDecodeFailures,
+		BlockOnDecodeFailures:  c.Bool(flagBlockOnDecodeFailure),
+		RelayerRefreshInterval: c.Duration(flagRelayerRefresh),
+		OfferBookConcurrency:   c.Uint(flagOfferBookConcurrency),
+		OfferBookPeerTimeout:   c.Duration(flagOfferBookPeerTimeout),
+		OfferBookTimeout:       c.Duration(flagOfferBookTimeout),
+		SwapResumeWindow:       c.Duration(flagSwapResumeWindow),
+		SwapResumeRetries:      c.Uint(flagSwapResumeRetries),
+		PeerScoreWeights:       peerScoreWeights,
+		AcceptUnsignedOffers:   c.Bool(flagAcceptUnsigned),
+		MinRelayerSuccessRate:  minRelayerSuccessRate,
+		RelayerForwarders:      relayerForwarders,
+		SkipConnectivityCheck:  c.Bool(flagSkipConnectivity),
+		XMRLockMargin:          c.Duration(flagXMRLockMargin),
+		PerSwapWallet:          c.Bool(flagPerSwapWallet),
+		PerSwapAccount:         c.Bool(flagPerSwapAccount),
+
+		ReclaimOnKeyPersistFailure: c.Bool(flagReclaimOnDBFailure),
+		CollapseDuplicateOffers:    c.Bool(flagCollapseDupOffers),
+		ClaimReceiptRetries:        c.Uint(flagClaimReceiptRetries),
+		RelayClaimRetries:          c.Uint(flagRelayClaimRetries),
+		ClaimDeadlineMargin:        c.Duration(flagClaimDeadlineMargin),
+		ClaimGasBump:               claimGasBump,
+		MoneroStartHeightRollback:  &moneroStartHeightRollback,
+		SweepConfirmations:         c.Uint64(flagSweepConfirmations),
+		ETHPollInterval:            c.Duration(flagETHPollInterval),
+		ETHSubscribe:               c.Bool(flagETHSubscribe),
+		ResumeConcurrency:          c.Uint(flagResumeConcurrency),
+		RelayerIncludeClaimDetails: c.Bool(flagRelayerClaimDetails),
+		RelayerFees:                relayerFees,
+		RelayerPayoutAddress:       relayerPayoutAddr,
+		RelayerPayoutMin:           relayerPayoutMin,
+		RelayerClaimGas:            relayerClaimGas,
+		XMRLockTolerance:           &xmrLockTolerance,
+		KeyGenRetries:              c.Uint(flagKeyGenRetries),
+		MaxConcurrentSwaps:         c.Uint(flagMaxConcurrentSwaps),
+		MoneroLockPriority:         moneroLockPriority,
+		ETHLockConfirmations:       ethLockConfirmations,
+		XMRReservationWindow:       c.Duration(flagXMRReservation),
+		ProofCacheSize:             int(c.Uint(flagProofCacheSize)),
+		KeyPoolSize:                c.Uint(flagKeyPoolSize),
+		MaxOfferBalanceFraction:    maxOfferBalanceFraction,
+		OfferRevalidationInterval:  c.Duration(flagOfferRevalidation),
+		RecoveryDBPassphrase:       recoveryDBPassphrase,
+		DustThresholds:             dustThresholds,
+		ShutdownTimeout:            c.Duration(flagShutdownTimeout),
+		MetricsAddress:             c.String(flagMetricsAddress),
+		ImportOffersFile:           c.String(flagImportOffers),
+		RestoreDBFile:              c.String(flagRestoreDB),
+		WebhookURL:                 c.String(flagWebhookURL),
+		WebhookSecret:              []byte(os.Getenv(envWebhookSecret)),
+		WebhookMaxRetries:          c.Uint(flagWebhookRetries),
+		WebhookRetryInterval:       c.Duration(flagWebhookRetryInterval),
 	}, nil
 }
 
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// envRecoveryDBPassphrase is the environment variable that the recovery db
+// passphrase is read from, if it's not prompted for. It isn't a flag, so that
+// it doesn't show up in the process list.
+const envRecoveryDBPassphrase = "SWAPD_RECOVERY_DB_PASSPHRASE"
+
+// getRecoveryDBPassphrase returns the passphrase that the recovery db is
+// encrypted with. It's read from standard input if the prompt flag is set,
+// and from the environment otherwise. An empty passphrase leaves the recovery
+// db unencrypted.
+func getRecoveryDBPassphrase(c *cli.Context) ([]byte, error) {
+	if !c.Bool(flagRecoveryDBPrompt) {
+		return []byte(os.Getenv(envRecoveryDBPassphrase)), nil
+	}
+
+	return readPassphrase(os.Stdin)
+}
+
+func readPassphrase(r io.Reader) ([]byte, error) {
+	_, _ = fmt.Fprint(os.Stderr, "Enter recovery db passphrase: ")
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return nil, fmt.Errorf("failed to read recovery db passphrase: %w", err)
+	}
+
+	passphrase := strings.TrimRight(line, "\r\n")
+	if passphrase == "" {
+		return nil, errFlagValueEmpty(flagRecoveryDBPrompt)
+	}
+
+	return []byte(passphrase), nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/monero"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+)
+
+const (
+	flagKeysFile       = "keys-file"
+	flagDepositAddress = "deposit-address"
+	flagRestoreHeight  = "restore-height"
+)
+
+// recoveryKeys are the swap keys that recover-xmr reads from its keys file.
+// They aren't flags, so that they don't show up in the process list or the
+// shell history.
+type recoveryKeys struct {
+	OurSpendKey   *mcrypto.PrivateSpendKey `json:"ourSpendKey" validate:"required"`
+	TheirSpendKey *mcrypto.PrivateSpendKey `json:"theirSpendKey" validate:"required"`
+	SharedViewKey *mcrypto.PrivateViewKey  `json:"sharedViewKey" validate:"required"`
+}
+
+// recoverXMRCommand returns the subcommand that sweeps the XMR locked in a
+// swap using only the swap's keys. It doesn't need a running swapd or its
+// database, only a monerod node and the monero-wallet-rpc binary.
+func recoverXMRCommand() *cli.Command {
+	return &cli.Command{
+		Name: "recover-xmr",
+		Usage: "Sweep the XMR locked in a swap to a deposit address, using the swap's private keys. " +
+			"The global --env, --data-dir and monerod flags are honoured.",
+		Action: runRecoverXMR,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: flagKeysFile,
+				Usage: "JSON file with the swap's private keys in hex: ourSpendKey, theirSpendKey and" +
+					" sharedViewKey (the sum of both parties' view keys). Use - to read it from standard input",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     flagDepositAddress,
+				Usage:    "Monero address to sweep the recovered XMR to",
+				Required: true,
+			},
+			&cli.Uint64Flag{
+				Name:  flagRestoreHeight,
+				Usage: "Monero block height to start scanning from, ideally the height the swap started at",
+			},
+		},
+	}
+}
+
+func runRecoverXMR(c *cli.Context) error {
+	if err := setLogLevelsFromContext(c); err != nil {
+		return err
+	}
+
+	env, err := common.NewEnv(c.String(flagEnv))
+	if err != nil {
+		return err
+	}
+	envConf := common.ConfigDefaultsForEnv(env)
+	if c.IsSet(flagDataDir) {
+		envConf.DataDir = c.String(flagDataDir)
+		if envConf.DataDir == "" {
+			return errFlagValueEmpty(flagDataDir)
+		}
+	}
+	walletFilePath := path.Join(envConf.DataDir, "wallet", "swap-wallet-recover")
+	if err = common.MakeDir(path.Dir(walletFilePath)); err != nil {
+		return err
+	}
+	if err = setMoneroNodes(c, envConf); err != nil {
+		return err
+	}
+
+	keys, err := readRecoveryKeysFile(c.String(flagKeysFile))
+	if err != nil {
+		return err
+	}
+
+	depositAddr, err := mcrypto.NewAddress(c.String(flagDepositAddress), env)
+	if err != nil {
+		return fmt.Errorf("invalid %q: %w", flagDepositAddress, err)
+	}
+
+	conf := &monero.WalletClientConf{
+		Env:            env,
+		WalletFilePath: walletFilePath,
+		MonerodNodes:   envConf.MoneroNodes,
+	}
+	if err = conf.Fill(); err != nil {
+		return err
+	}
+
+	return pcommon.RecoverMoneroFromKeys(
+		c.Context,
+		env,
+		conf,
+		c.Uint64(flagRestoreHeight),
+		keys.OurSpendKey,
+		keys.TheirSpendKey,
+		keys.SharedViewKey,
+		depositAddr,
+	)
+}
+
+// readRecoveryKeysFile reads the swap keys from the file at keysFile, or from
+// standard input if it's "-".
+func readRecoveryKeysFile(keysFile string) (*recoveryKeys, error) {
+	if keysFile == "" {
+		return nil, errFlagValueEmpty(flagKeysFile)
+	}
+
+	if keysFile == "-" {
+		return readRecoveryKeys(os.Stdin)
+	}
+
+	f, err := os.Open(filepath.Clean(keysFile))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	return readRecoveryKeys(f)
+}
+
+func readRecoveryKeys(r io.Reader) (*recoveryKeys, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", flagKeysFile, err)
+	}
+
+	keys := new(recoveryKeys)
+	if err = vjson.UnmarshalStruct(data, keys); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", flagKeysFile, err)
+	}
+
+	return keys, nil
+}
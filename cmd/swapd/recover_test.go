@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+)
+
+func TestReadRecoveryKeys(t *testing.T) {
+	ours, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	theirs, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	shared := mcrypto.SumPrivateViewKeys(ours.ViewKey(), theirs.ViewKey())
+
+	jsonData := fmt.Sprintf(`{"ourSpendKey": %q, "theirSpendKey": %q, "sharedViewKey": %q}`,
+		ours.SpendKey().Hex(), theirs.SpendKey().Hex(), shared.Hex())
+	keys, err := readRecoveryKeys(strings.NewReader(jsonData))
+	require.NoError(t, err)
+	require.Equal(t, ours.SpendKey().Hex(), keys.OurSpendKey.Hex())
+	require.Equal(t, theirs.SpendKey().Hex(), keys.TheirSpendKey.Hex())
+	require.Equal(t, shared.Hex(), keys.SharedViewKey.Hex())
+}
+
+func TestReadRecoveryKeys_missingKey(t *testing.T) {
+	ours, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+
+	jsonData := fmt.Sprintf(`{"ourSpendKey": %q}`, ours.SpendKey().Hex())
+	_, err = readRecoveryKeys(strings.NewReader(jsonData))
+	require.ErrorContains(t, err, "theirSpendKey")
+}
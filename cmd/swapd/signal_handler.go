@@ -6,6 +6,8 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
 func signalHandler(ctx context.Context, cancel context.CancelFunc) {
@@ -27,3 +29,27 @@ func signalHandler(ctx context.Context, cancel context.CancelFunc) {
 		log.Info("Protocol complete, shutting down...")
 	}
 }
+
+// reloadRelayerFeesOnSIGHUP replaces the relayer fees in the table with the
+// contents of feesFile each time that we receive a SIGHUP, until ctx is done. If
+// the file can't be loaded, the previous fees are kept.
+func reloadRelayerFeesOnSIGHUP(ctx context.Context, feesFile string, fees *relayer.FeeTable) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+	defer signal.Stop(sigc)
+
+	for {
+		select {
+		case <-sigc:
+			newFees, err := relayer.LoadFees(feesFile)
+			if err != nil {
+				log.Errorf("Failed to reload relayer fees, keeping the previous fees: %s", err)
+				continue
+			}
+			fees.SetFees(newFees)
+			log.Infof("Reloaded relayer fees for %d assets from %s", len(newFees), feesFile)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
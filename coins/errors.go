@@ -7,6 +7,8 @@ import (
 var (
 	errNegativePiconeros = errors.New("negative piconero values are not supported")
 	errNegativeWei       = errors.New("negative wei values are not supported")
+
+	errExchangeRateOutOfBounds = errors.New("exchange rate out of bounds")
 	// ErrInvalidCoin is generated when a ProvidesCoin type has an invalid string
 	ErrInvalidCoin = errors.New("invalid ProvidesCoin")
 )
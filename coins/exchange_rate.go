@@ -1,6 +1,8 @@
 package coins
 
 import (
+	"fmt"
+
 	"github.com/cockroachdb/apd/v3"
 )
 
@@ -9,6 +11,13 @@ import (
 // ie. an ExchangeRate of 0.1 means that the node considers 1 ETH = 10 XMR.
 type ExchangeRate apd.Decimal
 
+// ExchangeRateBounds is the range of exchange rates that are considered
+// plausible. A nil bound is not checked.
+type ExchangeRateBounds struct {
+	Min *apd.Decimal
+	Max *apd.Decimal
+}
+
 // CalcExchangeRate computes and returns an exchange rate using ETH and XRM prices. The
 // price can be relative to USD, bitcoin or something else, but both values should be
 // relative to the same alternate currency.
@@ -48,6 +57,22 @@ func (r *ExchangeRate) MarshalText() ([]byte, error) {
 	return r.Decimal().MarshalText()
 }
 
+// ValidateBounds returns an error if the rate is outside the passed bounds. Rates
+// that far off are almost certainly a mistake, or an attempt to trick automated
+// takers. Nil bounds aren't checked.
+func (r *ExchangeRate) ValidateBounds(bounds *ExchangeRateBounds) error {
+	if bounds == nil {
+		return nil
+	}
+	if bounds.Min != nil && r.Decimal().Cmp(bounds.Min) < 0 {
+		return fmt.Errorf("%w: %s is below the minimum of %s", errExchangeRateOutOfBounds, r, bounds.Min.Text('f'))
+	}
+	if bounds.Max != nil && r.Decimal().Cmp(bounds.Max) > 0 {
+		return fmt.Errorf("%w: %s is above the maximum of %s", errExchangeRateOutOfBounds, r, bounds.Max.Text('f'))
+	}
+	return nil
+}
+
 // ToXMR converts an ether amount to a monero amount with the given exchange rate
 func (r *ExchangeRate) ToXMR(ethAmount *apd.Decimal) (*apd.Decimal, error) {
 	xmrAmt := new(apd.Decimal)
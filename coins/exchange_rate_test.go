@@ -76,3 +76,25 @@ func TestCalcExchangeRate_fail(t *testing.T) {
 	_, err := CalcExchangeRate(xmrPrice, ethPrice)
 	require.ErrorContains(t, err, "division by zero")
 }
+
+func TestExchangeRate_ValidateBounds(t *testing.T) {
+	rate := StrToExchangeRate("0.05")
+	require.NoError(t, rate.ValidateBounds(nil))
+
+	bounds := &ExchangeRateBounds{Min: StrToDecimal("0.001"), Max: StrToDecimal("1")}
+	require.NoError(t, rate.ValidateBounds(bounds))
+	require.NoError(t, StrToExchangeRate("0.001").ValidateBounds(bounds))
+	require.NoError(t, StrToExchangeRate("1").ValidateBounds(bounds))
+
+	err := StrToExchangeRate("0.0001").ValidateBounds(bounds)
+	require.ErrorIs(t, err, errExchangeRateOutOfBounds)
+	require.ErrorContains(t, err, "0.0001 is below the minimum of 0.001")
+
+	err = StrToExchangeRate("50").ValidateBounds(bounds)
+	require.ErrorIs(t, err, errExchangeRateOutOfBounds)
+	require.ErrorContains(t, err, "50 is above the maximum of 1")
+
+	// a nil bound isn't checked
+	bounds.Min = nil
+	require.NoError(t, StrToExchangeRate("0.0001").ValidateBounds(bounds))
+}
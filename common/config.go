@@ -5,6 +5,7 @@ import (
 	"path"
 	"time"
 
+	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 )
 
@@ -36,13 +37,21 @@ type Config struct {
 	SwapFactoryAddress       ethcommon.Address
 	ForwarderContractAddress ethcommon.Address
 	Bootnodes                []string
+
+	// MinExchangeRate and MaxExchangeRate bound the ETH per XMR exchange rate
+	// of ETH offers. Offers outside the bounds are rejected, and a nil bound is
+	// not checked.
+	MinExchangeRate *apd.Decimal
+	MaxExchangeRate *apd.Decimal
 }
 
 // MainnetConfig is the mainnet ethereum and monero configuration
 func MainnetConfig() *Config {
 	return &Config{
-		Env:     Mainnet,
-		DataDir: path.Join(baseDir, "mainnet"),
+		Env:             Mainnet,
+		DataDir:         path.Join(baseDir, "mainnet"),
+		MinExchangeRate: apd.New(1, -3), // 0.001 ETH per XMR
+		MaxExchangeRate: apd.New(1, 0),  // 1 ETH per XMR
 		MoneroNodes: []*MoneroNode{
 			{
 				Host: "node.sethforprivacy.com",
@@ -156,6 +165,21 @@ func SwapTimeoutFromEnv(env Environment) time.Duration {
 	}
 }
 
+// ChainIDFromEnv returns the ethereum chain ID that an environment's nodes must
+// be connected to.
+func ChainIDFromEnv(env Environment) int64 {
+	switch env {
+	case Mainnet:
+		return MainnetChainID
+	case Stagenet:
+		return GoerliChainID
+	case Development:
+		return GanacheChainID
+	default:
+		panic("invalid environment")
+	}
+}
+
 // DefaultMoneroPortFromEnv returns the default Monerod RPC port for an environment
 // Reference: https://monerodocs.org/interacting/monerod-reference/
 func DefaultMoneroPortFromEnv(env Environment) uint {
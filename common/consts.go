@@ -24,6 +24,7 @@ const (
 
 // SwapFactory.sol event signatures
 const (
+	NewEventSignature      = "New(bytes32,bytes32,bytes32,uint256,uint256,address,uint256)"
 	ReadyEventSignature    = "Ready(bytes32)"
 	ClaimedEventSignature  = "Claimed(bytes32,bytes32)"
 	RefundedEventSignature = "Refunded(bytes32,bytes32)"
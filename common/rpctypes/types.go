@@ -3,6 +3,8 @@
 package rpctypes
 
 import (
+	"encoding/json"
+
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -48,27 +50,64 @@ type DiscoverResponse struct {
 type QueryPeerRequest struct {
 	// Peer ID of peer to query
 	PeerID peer.ID `json:"peerID" validate:"required"`
+
+	// Filter optionally selects a page of the peer's offers
+	Filter *types.OfferFilter `json:"filter,omitempty"`
+}
+
+// BlockPeerRequest ...
+type BlockPeerRequest struct {
+	PeerID peer.ID `json:"peerID" validate:"required"`
+}
+
+// UnblockPeerRequest ...
+type UnblockPeerRequest struct {
+	PeerID peer.ID `json:"peerID" validate:"required"`
 }
 
 // QueryPeerResponse ...
 type QueryPeerResponse struct {
-	Offers []*types.Offer `json:"offers" validate:"dive,required"`
+	Offers         []*types.Offer         `json:"offers" validate:"dive,required"`
+	TakeableRanges []*types.TakeableRange `json:"takeableRanges,omitempty" validate:"dive,required"`
+	TotalOffers    uint64                 `json:"totalOffers,omitempty"` // offers matching the filter, if any
 }
 
 // PeerWithOffers ...
 type PeerWithOffers struct {
-	PeerID peer.ID        `json:"peerID" validate:"required"`
-	Offers []*types.Offer `json:"offers" validate:"dive,required"`
+	PeerID         peer.ID                `json:"peerID" validate:"required"`
+	Offers         []*types.Offer         `json:"offers" validate:"dive,required"`
+	TakeableRanges []*types.TakeableRange `json:"takeableRanges,omitempty" validate:"dive,required"`
+	TotalOffers    uint64                 `json:"totalOffers,omitempty"` // offers matching the filter, if any
 }
 
 // QueryAllRequest ...
-type QueryAllRequest = DiscoverRequest
+type QueryAllRequest struct {
+	DiscoverRequest
+
+	// Filter optionally selects a page of each peer's offers
+	Filter *types.OfferFilter `json:"filter,omitempty"`
+}
 
 // QueryAllResponse ...
 type QueryAllResponse struct {
 	PeersWithOffers []*PeerWithOffers `json:"peersWithOffers" validate:"dive,required"`
 }
 
+// OfferBookRequest ...
+type OfferBookRequest struct {
+	// Filter optionally selects the offers, and a page of the merged orderbook
+	Filter *types.OfferFilter `json:"filter,omitempty"`
+
+	// RelayerFee is the fee policy that the effective rates of ETH offers are
+	// computed with. If nil, the standard relayer fee is used.
+	RelayerFee *types.RelayerFeePolicy `json:"relayerFee,omitempty"`
+}
+
+// OfferBookResponse ...
+type OfferBookResponse struct {
+	Offers []*types.OfferWithPeer `json:"offers" validate:"dive,required"`
+}
+
 // TakeOfferRequest ...
 type TakeOfferRequest struct {
 	PeerID         peer.ID      `json:"peerID" validate:"required"`
@@ -76,13 +115,38 @@ type TakeOfferRequest struct {
 	ProvidesAmount *apd.Decimal `json:"providesAmount" validate:"required"` // eth asset amount
 }
 
+// TakeableRangeRequest ...
+type TakeableRangeRequest struct {
+	PeerID  peer.ID    `json:"peerID" validate:"required"`
+	OfferID types.Hash `json:"offerID" validate:"required"`
+}
+
+// TakeableRangeResponse is the range of XMR amounts that the maker would
+// currently accept for the offer.
+type TakeableRangeResponse = types.TakeableRange
+
+// EstimateTakeCostRequest ...
+type EstimateTakeCostRequest = TakeOfferRequest
+
+// EstimateTakeCostResponse holds the total ETH that taking an offer could cost,
+// including the locked value (for ETH offers) and contract gas.
+type EstimateTakeCostResponse struct {
+	WeiCost *coins.WeiAmount `json:"weiCost" validate:"required"`
+}
+
 // MakeOfferRequest ...
 type MakeOfferRequest struct {
-	MinAmount    *apd.Decimal        `json:"minAmount" validate:"required"`
-	MaxAmount    *apd.Decimal        `json:"maxAmount" validate:"required"`
-	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
-	EthAsset     types.EthAsset      `json:"ethAsset,omitempty"`
-	UseRelayer   bool                `json:"useRelayer,omitempty"`
+	MinAmount          *apd.Decimal           `json:"minAmount" validate:"required"`
+	MaxAmount          *apd.Decimal           `json:"maxAmount" validate:"required"`
+	ExchangeRate       *coins.ExchangeRate    `json:"exchangeRate" validate:"required"`
+	EthAsset           types.EthAsset         `json:"ethAsset,omitempty"`
+	UseRelayer         bool                   `json:"useRelayer,omitempty"`
+	MoneroLockPriority types.MoneroTxPriority `json:"moneroLockPriority,omitempty"`
+	Provides           coins.ProvidesCoin     `json:"provides,omitempty"` // defaults to XMR
+	SwapFactory        *ethcommon.Address     `json:"swapFactory,omitempty"`
+	SwapTimeout        uint64                 `json:"swapTimeout,omitempty"` // in seconds
+	Private            bool                   `json:"private,omitempty"`
+	AllowedPeers       []peer.ID              `json:"allowedPeers,omitempty"` // only used by private offers
 }
 
 // MakeOfferResponse ...
@@ -91,6 +155,18 @@ type MakeOfferResponse struct {
 	OfferID types.Hash `json:"offerID" validate:"required"`
 }
 
+// ImportOffersRequest ...
+type ImportOffersRequest struct {
+	// Offers is a JSON array of offers, with the same fields as MakeOfferRequest
+	Offers json.RawMessage `json:"offers" validate:"required"`
+}
+
+// ImportOffersResponse ...
+type ImportOffersResponse struct {
+	PeerID   peer.ID      `json:"peerID" validate:"required"`
+	OfferIDs []types.Hash `json:"offerIDs" validate:"required"`
+}
+
 // SignerRequest initiates the signer_subscribe handler from the front-end
 type SignerRequest struct {
 	OfferID    types.Hash        `json:"offerID" validate:"required"`
@@ -131,3 +207,40 @@ type AddressesResponse struct {
 type PeersResponse struct {
 	Addrs []string `json:"addresses" validate:"dive,required"`
 }
+
+// PeerStatsResponse holds the number of messages from each peer that failed to
+// decode since swapd started.
+type PeerStatsResponse struct {
+	DecodeFailures []*PeerDecodeFailures `json:"decodeFailures" validate:"dive,required"`
+}
+
+// PeerDecodeFailures ...
+type PeerDecodeFailures struct {
+	PeerID peer.ID `json:"peerID" validate:"required"`
+	Count  uint    `json:"count"`
+}
+
+// RelayerClaimStatsResponse holds how the relayers that we submitted claims to
+// performed, with the most reliable relayers first.
+type RelayerClaimStatsResponse struct {
+	Relayers []*types.RelayerClaimStats `json:"relayers" validate:"dive,required"`
+}
+
+// RelayerStatsResponse holds the load on a relayer's claim request worker pool.
+type RelayerStatsResponse struct {
+	Active   int    `json:"active"`
+	Queued   int    `json:"queued"`
+	Rejected uint64 `json:"rejected"`
+}
+
+// ObservedOffersResponse holds the offers that an observer node saw makers
+// advertise, with those that were seen first first.
+type ObservedOffersResponse struct {
+	Offers []*types.ObservedOffer `json:"offers" validate:"dive,required"`
+}
+
+// ObservedSwapsResponse holds the on-chain swaps that an observer node saw,
+// with those that were seen first first.
+type ObservedSwapsResponse struct {
+	Swaps []*types.ObservedSwap `json:"swaps" validate:"dive,required"`
+}
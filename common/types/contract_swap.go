@@ -0,0 +1,88 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// ContractStage is the stage of a swap in the swap factory contract. The
+// values match the indexes of the contract's Stage enum.
+type ContractStage byte
+
+// ContractStage values
+const (
+	// ContractStageInvalid is the stage of a swap that the contract has no
+	// record of.
+	ContractStageInvalid ContractStage = iota
+	// ContractStagePending is the stage after the ETH was locked and before
+	// the taker set the swap to ready.
+	ContractStagePending
+	// ContractStageReady is the stage once the maker can claim the ETH.
+	ContractStageReady
+	// ContractStageCompleted is the stage after the ETH was claimed or
+	// refunded.
+	ContractStageCompleted
+)
+
+// NewContractStage returns a ContractStage from the given string. If there is
+// no match, it returns ContractStageInvalid and false.
+func NewContractStage(str string) (ContractStage, bool) {
+	switch str {
+	case "Invalid":
+		return ContractStageInvalid, true
+	case "Pending":
+		return ContractStagePending, true
+	case "Ready":
+		return ContractStageReady, true
+	case "Completed":
+		return ContractStageCompleted, true
+	default:
+		return ContractStageInvalid, false
+	}
+}
+
+// String returns the stage as a text string.
+func (s ContractStage) String() string {
+	switch s {
+	case ContractStageInvalid:
+		return "Invalid"
+	case ContractStagePending:
+		return "Pending"
+	case ContractStageReady:
+		return "Ready"
+	case ContractStageCompleted:
+		return "Completed"
+	default:
+		return unknownString
+	}
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *ContractStage) UnmarshalText(data []byte) error {
+	stage, ok := NewContractStage(string(data))
+	if !ok {
+		return fmt.Errorf("unknown contract stage %q", string(data))
+	}
+	*s = stage
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s ContractStage) MarshalText() ([]byte, error) {
+	textStr := s.String()
+	if textStr == unknownString {
+		return nil, fmt.Errorf("unknown contract stage %d", s)
+	}
+	return []byte(textStr), nil
+}
+
+// ContractSwapState is the progress of a swap as read from the swap factory
+// contract. Timeout0 and Timeout1 are the swap's deadlines in the contract.
+// Once the swap is completed, exactly one of Claimed or Refunded is set.
+type ContractSwapState struct {
+	Stage    ContractStage `json:"stage"`
+	Timeout0 time.Time     `json:"timeout0"`
+	Timeout1 time.Time     `json:"timeout1"`
+	Claimed  bool          `json:"claimed"`
+	Refunded bool          `json:"refunded"`
+}
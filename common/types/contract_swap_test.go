@@ -0,0 +1,41 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalContractStage(t *testing.T) {
+	type S struct {
+		Stage ContractStage `json:"stage"`
+	}
+
+	const jsonText = `{
+		"stage": "Ready"
+	}`
+
+	s := new(S)
+	err := json.Unmarshal([]byte(jsonText), s)
+	require.NoError(t, err)
+	require.Equal(t, ContractStageReady, s.Stage)
+
+	jsonData, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, jsonText, string(jsonData))
+}
+
+func TestUnmarshalContractStage_fail(t *testing.T) {
+	type S struct {
+		Stage ContractStage `json:"stage"`
+	}
+
+	const jsonText = `{
+		"stage": "Garbage"
+	}`
+
+	s := new(S)
+	err := json.Unmarshal([]byte(jsonText), s)
+	require.ErrorContains(t, err, `unknown contract stage "Garbage"`)
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+// DefaultETHDustThreshold is the default minimum ETH value, at the offer's
+// exchange rate, of an ETH offer's MinAmount (1 gwei).
+var DefaultETHDustThreshold = apd.New(1, -9)
+
+// DustThresholds are the minimum amounts of each asset, in standard units, that
+// an offer's MinAmount must be worth at the offer's exchange rate. Offers below
+// them can't be swapped sensibly, so they are rejected when made or taken.
+type DustThresholds map[EthAsset]*apd.Decimal
+
+// Threshold returns the dust threshold of the asset. ETH defaults to
+// DefaultETHDustThreshold, and other assets without a threshold only require
+// the amount to be non-zero.
+func (t DustThresholds) Threshold(asset EthAsset) *apd.Decimal {
+	if threshold, has := t[asset]; has {
+		return threshold
+	}
+
+	if asset == EthAssetETH {
+		return DefaultETHDustThreshold
+	}
+
+	return new(apd.Decimal)
+}
+
+type errOfferBelowDust struct {
+	minAmount *apd.Decimal
+	value     *apd.Decimal
+	threshold *apd.Decimal
+	asset     EthAsset
+}
+
+func (e errOfferBelowDust) Error() string {
+	return fmt.Sprintf("offer minimum of %s XMR is only worth %s %s, below the dust threshold of %s %s",
+		e.minAmount.Text('f'),
+		e.value.Text('f'),
+		e.asset,
+		e.threshold.Text('f'),
+		e.asset,
+	)
+}
+
+// CheckDust returns an error if the offer's MinAmount, converted at the offer's
+// exchange rate, is zero or below the dust threshold of the offer's asset.
+func (o *Offer) CheckDust(thresholds DustThresholds) error {
+	value, err := o.ExchangeRate.ToETH(o.MinAmount)
+	if err != nil {
+		return err
+	}
+
+	threshold := thresholds.Threshold(o.EthAsset)
+	if value.Sign() <= 0 || value.Cmp(threshold) < 0 {
+		return errOfferBelowDust{
+			minAmount: o.MinAmount,
+			value:     value,
+			threshold: threshold,
+			asset:     o.EthAsset,
+		}
+	}
+
+	return nil
+}
+
+// CheckExchangeRateBounds returns an error if the offer's exchange rate is
+// outside the passed bounds. Token prices vary too widely for fixed bounds, so
+// only ETH offers are checked.
+func (o *Offer) CheckExchangeRateBounds(bounds *coins.ExchangeRateBounds) error {
+	if o.EthAsset != EthAssetETH {
+		return nil
+	}
+
+	return o.ExchangeRate.ValidateBounds(bounds)
+}
+
+// CheckTerms returns an error if the offer's terms are outside what we are
+// configured to accept: a MinAmount below the dust threshold, or an exchange
+// rate outside the configured bounds. Unlike Validate, its result depends on
+// our configuration, so it's checked when offers are made or taken.
+func (o *Offer) CheckTerms(thresholds DustThresholds, bounds *coins.ExchangeRateBounds) error {
+	if err := o.CheckDust(thresholds); err != nil {
+		return err
+	}
+
+	return o.CheckExchangeRateBounds(bounds)
+}
@@ -1,13 +1,15 @@
 package types
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 )
 
-// EthAsset represents an Ethereum asset (ETH or a token address)
+// EthAsset represents an Ethereum asset (ETH or a token address). The zero value
+// is EthAssetETH, ie. native ETH.
 type EthAsset ethcommon.Address
 
 // String implements fmt.Stringer, returning the asset's address in hex, or
@@ -49,3 +51,21 @@ func (asset EthAsset) Address() ethcommon.Address {
 
 // EthAssetETH describes regular ETH (rather than an ERC-20 token)
 var EthAssetETH = EthAsset(ethcommon.Address{})
+
+// maxReservedAsset is the highest address in the range that Ethereum and its L2s
+// reserve for precompiled contracts, none of which can be an ERC20 token.
+var maxReservedAsset = EthAsset(ethcommon.BytesToAddress([]byte{0xff}))
+
+// validate returns an error if the asset is neither ETH nor a possible ERC20
+// token address.
+func (asset EthAsset) validate() error {
+	if asset == EthAssetETH {
+		return nil
+	}
+
+	if bytes.Compare(asset[:], maxReservedAsset[:]) <= 0 {
+		return fmt.Errorf("%w: %s is reserved for precompiled contracts", errInvalidEthAsset, asset)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"time"
+)
+
+// HealthStatus reports whether swapd's ethereum and monero endpoints are
+// reachable and synced. Healthy is only set if both are.
+type HealthStatus struct {
+	Healthy  bool            `json:"healthy"`
+	Ethereum *EthereumHealth `json:"ethereum"`
+	Monero   *MoneroHealth   `json:"monero"`
+}
+
+// EthereumHealth is the state of the ethereum endpoint. SyncLag is how many
+// blocks the node is behind the highest block it knows of, and is zero once
+// it's synced.
+type EthereumHealth struct {
+	Reachable       bool      `json:"reachable"`
+	Error           string    `json:"error,omitempty"`
+	ChainID         uint64    `json:"chainID"`
+	LatestBlock     uint64    `json:"latestBlock"`
+	LatestBlockTime time.Time `json:"latestBlockTime"`
+	Syncing         bool      `json:"syncing"`
+	SyncLag         uint64    `json:"syncLag"`
+}
+
+// MoneroHealth is the state of the monero wallet and of the monerod node it
+// uses. SyncLag is how many blocks the wallet is behind the network height.
+type MoneroHealth struct {
+	Reachable          bool   `json:"reachable"`
+	Error              string `json:"error,omitempty"`
+	DaemonSynchronised bool   `json:"daemonSynchronised"`
+	DaemonHeight       uint64 `json:"daemonHeight"`
+	TargetHeight       uint64 `json:"targetHeight"`
+	WalletHeight       uint64 `json:"walletHeight"`
+	WalletRefreshed    bool   `json:"walletRefreshed"`
+	SyncLag            uint64 `json:"syncLag"`
+}
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+// OfferLiquidity is one of a maker's offers, along with the XMR reserved by the
+// in-flight swap on it, if it's taken. Reservations last until the swap's XMR
+// is locked, after which the locked XMR is no longer part of the balance.
+type OfferLiquidity struct {
+	OfferID      Hash                `json:"offerID" validate:"required"`
+	Provides     coins.ProvidesCoin  `json:"provides" validate:"required"`
+	MinAmount    *apd.Decimal        `json:"minAmount" validate:"required"`
+	MaxAmount    *apd.Decimal        `json:"maxAmount" validate:"required"`
+	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
+	EthAsset     EthAsset            `json:"ethAsset"`
+	// Taken is set if an in-flight swap took the offer, in which case it
+	// isn't advertised until the swap exits.
+	Taken bool `json:"taken"`
+	// ReservedAmount is the XMR reserved by the swap on the offer.
+	ReservedAmount *apd.Decimal `json:"reservedAmount" validate:"required"`
+}
+
+// MakerLiquidity is a maker's offers, along with how much of its unlocked XMR
+// balance is reserved by in-flight swaps, and how much is available to new
+// takes.
+type MakerLiquidity struct {
+	Offers           []*OfferLiquidity `json:"offers" validate:"dive,required"`
+	UnlockedBalance  *apd.Decimal      `json:"unlockedBalance" validate:"required"`
+	ReservedBalance  *apd.Decimal      `json:"reservedBalance" validate:"required"`
+	AvailableBalance *apd.Decimal      `json:"availableBalance" validate:"required"`
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"fmt"
+)
+
+// MoneroTxPriority is the priority of a monero transfer, which determines its
+// fee. Higher priorities pay more to be mined sooner.
+type MoneroTxPriority uint64
+
+// Monero transfer priorities, with their values in the wallet RPC API. The
+// zero value lets the wallet pick the priority.
+const (
+	MoneroPriorityDefault  MoneroTxPriority = 0
+	MoneroPriorityLow      MoneroTxPriority = 1
+	MoneroPriorityElevated MoneroTxPriority = 3
+	MoneroPriorityHigh     MoneroTxPriority = 4
+)
+
+// NewMoneroTxPriority parses one of the priority names: "default", "low",
+// "elevated" or "priority". An empty string is the default priority.
+func NewMoneroTxPriority(name string) (MoneroTxPriority, error) {
+	switch name {
+	case "", "default":
+		return MoneroPriorityDefault, nil
+	case "low":
+		return MoneroPriorityLow, nil
+	case "elevated":
+		return MoneroPriorityElevated, nil
+	case "priority":
+		return MoneroPriorityHigh, nil
+	default:
+		return 0, fmt.Errorf("invalid monero transfer priority %q", name)
+	}
+}
+
+// String returns the name of the priority
+func (p MoneroTxPriority) String() string {
+	switch p {
+	case MoneroPriorityDefault:
+		return "default"
+	case MoneroPriorityLow:
+		return "low"
+	case MoneroPriorityElevated:
+		return "elevated"
+	case MoneroPriorityHigh:
+		return "priority"
+	default:
+		return fmt.Sprintf("MoneroTxPriority(%d)", uint64(p))
+	}
+}
+
+// MarshalText hands off JSON encoding to the priority name
+func (p MoneroTxPriority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText hands off JSON decoding to NewMoneroTxPriority
+func (p *MoneroTxPriority) UnmarshalText(data []byte) error {
+	priority, err := NewMoneroTxPriority(string(data))
+	if err != nil {
+		return err
+	}
+
+	*p = priority
+	return nil
+}
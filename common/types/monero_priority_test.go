@@ -0,0 +1,43 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMoneroTxPriority(t *testing.T) {
+	for _, p := range []MoneroTxPriority{
+		MoneroPriorityDefault,
+		MoneroPriorityLow,
+		MoneroPriorityElevated,
+		MoneroPriorityHigh,
+	} {
+		parsed, err := NewMoneroTxPriority(p.String())
+		require.NoError(t, err)
+		require.Equal(t, p, parsed)
+	}
+
+	p, err := NewMoneroTxPriority("")
+	require.NoError(t, err)
+	require.Equal(t, MoneroPriorityDefault, p)
+
+	_, err = NewMoneroTxPriority("urgent")
+	require.ErrorContains(t, err, "invalid monero transfer priority")
+}
+
+func TestOfferExtra_MoneroLockPriorityJSON(t *testing.T) {
+	extra := &OfferExtra{MoneroLockPriority: MoneroPriorityElevated}
+	data, err := json.Marshal(extra)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"moneroLockPriority":"elevated"}`, string(data))
+
+	decoded := new(OfferExtra)
+	require.NoError(t, json.Unmarshal(data, decoded))
+	require.Equal(t, MoneroPriorityElevated, decoded.MoneroLockPriority)
+
+	data, err = json.Marshal(&OfferExtra{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(data))
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"math/big"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// ObservedOffer is an offer that an observer node saw a maker advertise.
+type ObservedOffer struct {
+	PeerID    peer.ID   `json:"peerID" validate:"required"`
+	Offer     *Offer    `json:"offer" validate:"required"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// ObservedSwap is a swap that an observer node saw on-chain, in the swap
+// factory contract. Stage is the last event seen for the swap, one of New,
+// Ready, Claimed or Refunded. Swaps that started before the observer did
+// have no asset, value or timeouts.
+type ObservedSwap struct {
+	SwapID      Hash           `json:"swapID" validate:"required"`
+	Stage       string         `json:"stage" validate:"required"`
+	Asset       EthAsset       `json:"asset"`
+	Value       *big.Int       `json:"value,omitempty"` // in the asset's smallest unit
+	Timeout0    *time.Time     `json:"timeout0,omitempty"`
+	Timeout1    *time.Time     `json:"timeout1,omitempty"`
+	TxHash      ethcommon.Hash `json:"txHash"` // of the last event
+	BlockNumber uint64         `json:"blockNumber"`
+}
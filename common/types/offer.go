@@ -6,15 +6,25 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"golang.org/x/crypto/sha3"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/vjson"
 )
 
+const (
+	// MinSwapTimeout and MaxSwapTimeout bound the swap timeout that an offer
+	// can set.
+	MinSwapTimeout = 2 * time.Minute
+	MaxSwapTimeout = 24 * time.Hour
+)
+
 var (
 	// CurOfferVersion is the latest supported version of a serialised Offer struct
 	CurOfferVersion, _ = semver.NewVersion("1.0.0")
@@ -23,6 +33,8 @@ var (
 	errOfferIDNotSet       = errors.New(`"offerID" is not set`)
 	errExchangeRateNil     = errors.New(`"exchangeRate" is not set`)
 	errMinGreaterThanMax   = errors.New(`"minAmount" must be less than or equal to "maxAmount"`)
+	errInvalidEthAsset     = errors.New(`"ethAsset" is not ETH or an ERC20 token address`)
+	errSwapTimeoutRange    = fmt.Errorf(`"swapTimeout" must be between %s and %s`, MinSwapTimeout, MaxSwapTimeout)
 )
 
 // Offer represents a swap offer
@@ -33,8 +45,23 @@ type Offer struct {
 	MinAmount    *apd.Decimal        `json:"minAmount" validate:"required"` // Min XMR amount
 	MaxAmount    *apd.Decimal        `json:"maxAmount" validate:"required"` // Max XMR amount
 	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
-	EthAsset     EthAsset            `json:"ethAsset"`
+	EthAsset     EthAsset            `json:"ethAsset"` // zero value (or missing in JSON) means ETH
 	Nonce        uint64              `json:"nonce" validate:"required"`
+
+	// SwapFactory is the SwapFactory contract that swaps on the offer must use.
+	// If nil, swaps use the taker's configured contract.
+	SwapFactory *ethcommon.Address `json:"swapFactory,omitempty"`
+
+	// SwapTimeout is the timeout duration, in seconds, that swaps on the offer
+	// pass to the contract when the ETH is locked. The contract sets t0 to the
+	// lock's block timestamp plus the duration, and t1 to the block timestamp
+	// plus twice the duration. If zero, the ETH holder's configured swap
+	// timeout is used.
+	SwapTimeout uint64 `json:"swapTimeout,omitempty"`
+
+	// Signature is the maker's signature of the offer with its libp2p key. It
+	// is set on offers sent to peers, and isn't part of the offer ID.
+	Signature []byte `json:"signature,omitempty"`
 }
 
 // NewOffer creates and returns an Offer with an initialised ID and Version fields
@@ -76,10 +103,21 @@ func (o *Offer) setID() {
 		panic("offer ID is already set")
 	}
 
-	o.ID = o.hash()
+	o.ID = o.Hash()
 }
 
-func (o *Offer) hash() Hash {
+// Hash returns the hash of the offer's fields, which is its ID. It's the
+// SHA3-256 of the preimage returned by HashPreimage. Offers made by swapd
+// reduce their apd decimals first, so that eg. 0.10 is hashed as 0.1, but the
+// hash of a received offer covers its amounts as they were encoded.
+func (o *Offer) Hash() Hash {
+	return sha3.Sum256([]byte(o.HashPreimage()))
+}
+
+// HashPreimage returns the string that is hashed to compute the offer ID. It
+// is exported, along with the test vectors of OfferHashVectors, so that other
+// implementations can check that they compute the same offer IDs.
+func (o *Offer) HashPreimage() string {
 	b := append([]byte(o.Version.String()), []byte(o.Provides)...)
 	b = append(b, []byte(",")...)
 	b = append(b, []byte(o.MinAmount.Text('f'))...)
@@ -91,7 +129,40 @@ func (o *Offer) hash() Hash {
 	b = append(b, []byte(o.EthAsset.String())...)
 	b = append(b, []byte(",")...)
 	b = append(b, []byte(fmt.Sprintf("%d", o.Nonce))...)
-	return sha3.Sum256(b)
+	// only offers with a SwapFactory hash it, so the IDs of other offers are
+	// unchanged from before the field existed
+	if o.SwapFactory != nil {
+		b = append(b, []byte(",")...)
+		b = append(b, []byte(o.SwapFactory.Hex())...)
+	}
+	// likewise for SwapTimeout, which is prefixed so that it can't be confused
+	// with the SwapFactory
+	if o.SwapTimeout != 0 {
+		b = append(b, []byte(fmt.Sprintf(",timeout=%d", o.SwapTimeout))...)
+	}
+	return string(b)
+}
+
+// SetSwapFactory sets the SwapFactory contract that swaps on the offer must
+// use, and recomputes the offer ID, which covers it. It must be called before
+// the offer is signed or advertised.
+func (o *Offer) SetSwapFactory(addr ethcommon.Address) {
+	o.SwapFactory = &addr
+	o.ID = o.Hash()
+}
+
+// SetSwapTimeout sets the timeout duration that swaps on the offer pass to the
+// contract, and recomputes the offer ID, which covers it. It must be called
+// before the offer is signed or advertised.
+func (o *Offer) SetSwapTimeout(timeout time.Duration) {
+	o.SwapTimeout = uint64(timeout / time.Second)
+	o.ID = o.Hash()
+}
+
+// SwapTimeoutDuration returns the offer's swap timeout, or zero if the ETH
+// holder's configured one should be used.
+func (o *Offer) SwapTimeoutDuration() time.Duration {
+	return time.Duration(o.SwapTimeout) * time.Second
 }
 
 // String ...
@@ -116,6 +187,11 @@ func (o *Offer) IsSet() bool {
 		o.ExchangeRate != nil
 }
 
+// Validate checks that the offer's fields are valid and match its ID.
+func (o *Offer) Validate() error {
+	return o.validate()
+}
+
 func (o *Offer) validate() error {
 	if IsHashZero(o.ID) {
 		return errOfferIDNotSet
@@ -138,7 +214,18 @@ func (o *Offer) validate() error {
 		return errExchangeRateNil
 	}
 
-	if o.ID != o.hash() {
+	if err := o.EthAsset.validate(); err != nil {
+		return err
+	}
+
+	if o.SwapTimeout != 0 {
+		timeout := o.SwapTimeoutDuration()
+		if timeout < MinSwapTimeout || timeout > MaxSwapTimeout {
+			return errSwapTimeoutRange
+		}
+	}
+
+	if o.ID != o.Hash() {
 		return errors.New("hash of offer fields does not match offer ID")
 	}
 
@@ -147,13 +234,70 @@ func (o *Offer) validate() error {
 
 // OfferExtra represents extra data that is passed when an offer is made.
 type OfferExtra struct {
-	StatusCh   chan Status `json:"-"`
-	UseRelayer bool        `json:"useRelayer,omitempty"`
+	StatusCh      chan Status    `json:"-"`
+	UseRelayer    bool           `json:"useRelayer,omitempty"`
+	FiatReference *FiatReference `json:"fiatReference,omitempty"`
+
+	// MoneroLockPriority overrides the maker's configured priority of the XMR
+	// lock transfer for swaps on this offer.
+	MoneroLockPriority MoneroTxPriority `json:"moneroLockPriority,omitempty"`
+
+	// Cancelled is set when the offer is cancelled while a swap on it is in
+	// flight. The swap continues, but the offer is not re-added if it fails.
+	Cancelled bool `json:"cancelled,omitempty"`
+
+	OfferVisibility
+}
+
+// OfferVisibility restricts which peers an offer is served to. Private offers
+// aren't advertised, and are only served to, and can only be taken by, the
+// peers in AllowedPeers.
+type OfferVisibility struct {
+	Private      bool      `json:"private,omitempty"`
+	AllowedPeers []peer.ID `json:"allowedPeers,omitempty"`
+}
+
+// VisibleTo returns whether the offer is served to the passed peer.
+func (v *OfferVisibility) VisibleTo(who peer.ID) bool {
+	if !v.Private {
+		return true
+	}
+
+	for _, allowed := range v.AllowedPeers {
+		if allowed == who {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FiatReference pegs an offer's min and max amounts to a fiat currency. The
+// advertised offer always has concrete XMR amounts and exchange rate, which
+// are computed from a price oracle when the offer is published or repriced.
+type FiatReference struct {
+	Currency  string       `json:"currency"`  // eg. "USD", must match the oracle's prices
+	MinAmount *apd.Decimal `json:"minAmount"` // Min fiat amount
+	MaxAmount *apd.Decimal `json:"maxAmount"` // Max fiat amount
+}
+
+// TakeableRange is the range of XMR amounts of an offer that the maker would
+// accept at the moment it was reported, given its live constraints like its
+// unlocked balance and swap capacity. It is a subrange of the offer's static
+// min and max. If the offer can't be taken right now, MinAmount and MaxAmount
+// are nil and Reason explains why.
+type TakeableRange struct {
+	OfferID   Hash         `json:"offerID" validate:"required"`
+	MinAmount *apd.Decimal `json:"minAmount,omitempty"` // Min XMR amount
+	MaxAmount *apd.Decimal `json:"maxAmount,omitempty"` // Max XMR amount
+	Reason    string       `json:"reason,omitempty"`
 }
 
 // UnmarshalOffer deserializes a JSON offer, checking the version for compatibility before
-// attempting to deserialize the whole blob.
-func UnmarshalOffer(jsonData []byte) (*Offer, error) {
+// attempting to deserialize the whole blob. ETH offers with an exchange rate
+// outside the passed bounds are rejected. Offers we stored ourselves are loaded
+// with nil bounds, so that changing the bounds doesn't make them unreadable.
+func UnmarshalOffer(jsonData []byte, bounds *coins.ExchangeRateBounds) (*Offer, error) {
 	// First unmarshal into a struct that only has the version. Then, if we ever
 	// have to support multiple versions, you can use the version to pick which
 	// offer structure to deserialize the full data into.
@@ -177,6 +321,10 @@ func UnmarshalOffer(jsonData []byte) (*Offer, error) {
 		return nil, err
 	}
 
+	if err := o.CheckExchangeRateBounds(bounds); err != nil {
+		return nil, err
+	}
+
 	return o, nil
 }
 
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+// OfferFilter selects a page of the offers that match all of its set fields.
+// MinAmount and MaxAmount are XMR amounts, and an offer matches them if its own
+// amount range overlaps theirs.
+type OfferFilter struct {
+	Provides  coins.ProvidesCoin `json:"provides,omitempty"`
+	EthAsset  *EthAsset          `json:"ethAsset,omitempty"`
+	MinAmount *apd.Decimal       `json:"minAmount,omitempty"`
+	MaxAmount *apd.Decimal       `json:"maxAmount,omitempty"`
+	Offset    uint64             `json:"offset,omitempty"`
+	Limit     uint64             `json:"limit,omitempty"` // zero is unlimited
+}
+
+// Matches returns whether the offer matches the filter, ignoring its offset
+// and limit.
+func (f *OfferFilter) Matches(o *Offer) bool {
+	if f.Provides != "" && o.Provides != f.Provides {
+		return false
+	}
+
+	if f.EthAsset != nil && o.EthAsset != *f.EthAsset {
+		return false
+	}
+
+	if f.MinAmount != nil && o.MaxAmount.Cmp(f.MinAmount) < 0 {
+		return false
+	}
+
+	if f.MaxAmount != nil && o.MinAmount.Cmp(f.MaxAmount) > 0 {
+		return false
+	}
+
+	return true
+}
+
+// Apply returns the page of the passed offers selected by the filter, along
+// with the total number of matching offers. Matching offers are paged in the
+// order of their IDs, so that the pages of an unchanged set of offers don't
+// overlap or skip offers, whatever order the offers are passed in.
+func (f *OfferFilter) Apply(offers []*Offer) ([]*Offer, uint64) {
+	matching := make([]*Offer, 0, len(offers))
+	for _, o := range offers {
+		if f.Matches(o) {
+			matching = append(matching, o)
+		}
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		return bytes.Compare(matching[i].ID[:], matching[j].ID[:]) < 0
+	})
+
+	total := uint64(len(matching))
+	if f.Offset >= total {
+		return []*Offer{}, total
+	}
+
+	page := matching[f.Offset:]
+	if f.Limit != 0 && f.Limit < uint64(len(page)) {
+		page = page[:f.Limit]
+	}
+
+	return page, total
+}
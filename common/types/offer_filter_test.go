@@ -0,0 +1,52 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestOfferFilter_Apply(t *testing.T) {
+	rate := coins.ToExchangeRate(apd.New(1, -1))
+	token := EthAsset(ethcommon.HexToAddress("0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8"))
+
+	small := NewOffer(coins.ProvidesXMR, apd.New(1, 0), apd.New(2, 0), rate, EthAssetETH)
+	large := NewOffer(coins.ProvidesXMR, apd.New(10, 0), apd.New(20, 0), rate, EthAssetETH)
+	tokenOffer := NewOffer(coins.ProvidesXMR, apd.New(1, 0), apd.New(20, 0), rate, token)
+	offers := []*Offer{small, large, tokenOffer}
+
+	page, total := new(OfferFilter).Apply(offers)
+	require.ElementsMatch(t, offers, page)
+	require.Equal(t, uint64(3), total)
+
+	eth := EthAssetETH
+	page, total = (&OfferFilter{EthAsset: &eth}).Apply(offers)
+	require.ElementsMatch(t, []*Offer{small, large}, page)
+	require.Equal(t, uint64(2), total)
+
+	// offers whose amount range overlaps [3, 15]
+	page, total = (&OfferFilter{MinAmount: apd.New(3, 0), MaxAmount: apd.New(15, 0)}).Apply(offers)
+	require.ElementsMatch(t, []*Offer{large, tokenOffer}, page)
+	require.Equal(t, uint64(2), total)
+
+	page, total = (&OfferFilter{Provides: coins.ProvidesETH}).Apply(offers)
+	require.Empty(t, page)
+	require.Equal(t, uint64(0), total)
+
+	// pages are the same whatever order the offers are in
+	sorted, _ := new(OfferFilter).Apply(offers)
+	reversed := []*Offer{tokenOffer, large, small}
+	for _, in := range [][]*Offer{offers, reversed} {
+		page, total = (&OfferFilter{Offset: 1, Limit: 1}).Apply(in)
+		require.Equal(t, sorted[1:2], page)
+		require.Equal(t, uint64(3), total)
+	}
+
+	page, total = (&OfferFilter{Offset: 3}).Apply(offers)
+	require.Empty(t, page)
+	require.Equal(t, uint64(3), total)
+}
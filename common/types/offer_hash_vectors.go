@@ -0,0 +1,159 @@
+package types
+
+import (
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+// OfferHashVector is a test vector of offer hashing, for checking that other
+// implementations compute the same offer IDs as swapd.
+type OfferHashVector struct {
+	Description string `json:"description"`
+	// Offer is the offer as it's sent to peers, with its ID set to Hash.
+	Offer    *Offer `json:"offer"`
+	Preimage string `json:"preimage"`
+	Hash     Hash   `json:"hash"`
+}
+
+// offerHashVectorSpec holds the fields of a test vector's offer. Amounts are
+// strings, so that unreduced decimals can be expressed.
+type offerHashVectorSpec struct {
+	description  string
+	provides     coins.ProvidesCoin
+	minAmount    string
+	maxAmount    string
+	exchangeRate string
+	ethAsset     EthAsset
+	nonce        uint64
+	swapFactory  *ethcommon.Address
+	swapTimeout  time.Duration
+	// unreduced keeps the decimals as they're written, like in offers
+	// received from peers, instead of reducing them like NewOffer does.
+	unreduced bool
+}
+
+var (
+	vectorTokenAddr   = ethcommon.HexToAddress("0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8")
+	vectorFactoryAddr = ethcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+)
+
+var offerHashVectorSpecs = []offerHashVectorSpec{
+	{
+		description:  "ETH offer",
+		provides:     coins.ProvidesXMR,
+		minAmount:    "0.1",
+		maxAmount:    "2.5",
+		exchangeRate: "0.0625",
+		ethAsset:     EthAssetETH,
+		nonce:        1,
+	},
+	{
+		description:  "decimals are reduced by NewOffer before hashing",
+		provides:     coins.ProvidesXMR,
+		minAmount:    "0.10",
+		maxAmount:    "1.500",
+		exchangeRate: "0.050",
+		ethAsset:     EthAssetETH,
+		nonce:        2,
+	},
+	{
+		description:  "received offers are hashed with their decimals as encoded",
+		provides:     coins.ProvidesXMR,
+		minAmount:    "0.10",
+		maxAmount:    "1.500",
+		exchangeRate: "0.050",
+		ethAsset:     EthAssetETH,
+		nonce:        2,
+		unreduced:    true,
+	},
+	{
+		description:  "ERC20 offer with the maximum nonce",
+		provides:     coins.ProvidesXMR,
+		minAmount:    "1",
+		maxAmount:    "25",
+		exchangeRate: "140.5",
+		ethAsset:     EthAsset(vectorTokenAddr),
+		nonce:        18446744073709551615,
+	},
+	{
+		description:  "offer with a swap factory",
+		provides:     coins.ProvidesXMR,
+		minAmount:    "0.5",
+		maxAmount:    "3",
+		exchangeRate: "0.075",
+		ethAsset:     EthAssetETH,
+		nonce:        3,
+		swapFactory:  &vectorFactoryAddr,
+	},
+	{
+		description:  "offer with a swap timeout",
+		provides:     coins.ProvidesXMR,
+		minAmount:    "0.5",
+		maxAmount:    "3",
+		exchangeRate: "0.075",
+		ethAsset:     EthAssetETH,
+		nonce:        4,
+		swapTimeout:  time.Hour,
+	},
+	{
+		description:  "offer with a swap factory and a swap timeout",
+		provides:     coins.ProvidesXMR,
+		minAmount:    "0.000000000001",
+		maxAmount:    "1000.123456789012",
+		exchangeRate: "0.000001",
+		ethAsset:     EthAsset(vectorTokenAddr),
+		nonce:        5,
+		swapFactory:  &vectorFactoryAddr,
+		swapTimeout:  2 * time.Minute,
+	},
+}
+
+// OfferHashVectors returns a deterministic set of offers, along with the
+// preimage and hash of each, covering the fields and cases that affect the
+// offer ID. They're kept in testdata/offer_hash_vectors.json as well, for
+// implementations in other languages.
+func OfferHashVectors() []*OfferHashVector {
+	vectors := make([]*OfferHashVector, len(offerHashVectorSpecs))
+	for i, spec := range offerHashVectorSpecs {
+		offer := &Offer{
+			Version:      *CurOfferVersion,
+			Provides:     spec.provides,
+			MinAmount:    mustDecimal(spec.minAmount),
+			MaxAmount:    mustDecimal(spec.maxAmount),
+			ExchangeRate: coins.ToExchangeRate(mustDecimal(spec.exchangeRate)),
+			EthAsset:     spec.ethAsset,
+			Nonce:        spec.nonce,
+			SwapFactory:  spec.swapFactory,
+			SwapTimeout:  uint64(spec.swapTimeout / time.Second),
+		}
+
+		if !spec.unreduced {
+			_, _ = offer.MinAmount.Reduce(offer.MinAmount)
+			_, _ = offer.MaxAmount.Reduce(offer.MaxAmount)
+			_, _ = offer.ExchangeRate.Decimal().Reduce(offer.ExchangeRate.Decimal())
+		}
+
+		offer.ID = offer.Hash()
+		vectors[i] = &OfferHashVector{
+			Description: spec.description,
+			Offer:       offer,
+			Preimage:    offer.HashPreimage(),
+			Hash:        offer.ID,
+		}
+	}
+
+	return vectors
+}
+
+// mustDecimal parses the decimals of the test vectors, which are constants.
+func mustDecimal(s string) *apd.Decimal {
+	d, _, err := apd.NewFromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
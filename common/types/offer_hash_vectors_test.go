@@ -0,0 +1,35 @@
+package types
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfferHashVectors(t *testing.T) {
+	data, err := os.ReadFile(path.Join("testdata", "offer_hash_vectors.json"))
+	require.NoError(t, err)
+
+	var expected []*OfferHashVector
+	require.NoError(t, json.Unmarshal(data, &expected))
+
+	vectors := OfferHashVectors()
+	require.Equal(t, len(expected), len(vectors))
+
+	for i, v := range vectors {
+		exp := expected[i]
+		require.Equal(t, exp.Description, v.Description)
+		require.Equal(t, exp.Preimage, v.Preimage, v.Description)
+		require.Equal(t, exp.Hash, v.Hash, v.Description)
+		require.Equal(t, v.Hash, v.Offer.ID, v.Description)
+
+		// the offers decoded from the JSON vectors hash the same way, as the
+		// decimals of received offers aren't reduced
+		require.Equal(t, exp.Preimage, exp.Offer.HashPreimage(), v.Description)
+		require.Equal(t, exp.Hash, exp.Offer.Hash(), v.Description)
+		require.Equal(t, exp.Hash, exp.Offer.ID, v.Description)
+	}
+}
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+var errNoOffers = errors.New("no offers to select from")
+
+// errNoMatchingOffer is returned by SelectBestOffer when none of the offers can
+// be taken for the wanted amount, with the number of offers excluded by each
+// criteria.
+type errNoMatchingOffer struct {
+	wantXMR     *apd.Decimal
+	maxETH      *coins.WeiAmount // may be nil
+	notETH      int
+	outOfRange  int
+	overBudget  int
+	invalidRate int
+}
+
+func (e errNoMatchingOffer) Error() string {
+	budget := "any price"
+	if e.maxETH != nil {
+		budget = fmt.Sprintf("at most %s ETH", e.maxETH.AsEtherString())
+	}
+
+	return fmt.Sprintf("no offer provides %s XMR for %s: %d don't provide XMR for ETH,"+
+		" %d don't allow the amount, %d cost too much, %d have an unusable exchange rate",
+		e.wantXMR.Text('f'),
+		budget,
+		e.notETH,
+		e.outOfRange,
+		e.overBudget,
+		e.invalidRate,
+	)
+}
+
+// SelectBestOffer returns the offer that provides wantXMR for the least ETH,
+// for a taker that pays with ETH. Only offers that provide XMR for ETH, and
+// whose amount range includes wantXMR, are considered. If maxETH is not nil,
+// offers that would cost more than it are excluded as well. Exchange rates are
+// the ETH price of one XMR, so the best offer is the one with the lowest rate.
+// When several offers have the best rate, the first of them is returned.
+func SelectBestOffer(offers []*Offer, wantXMR *apd.Decimal, maxETH *coins.WeiAmount) (*Offer, error) {
+	if len(offers) == 0 {
+		return nil, errNoOffers
+	}
+
+	if err := coins.ValidatePositive("wantXMR", coins.NumMoneroDecimals, wantXMR); err != nil {
+		return nil, err
+	}
+
+	var (
+		best     *Offer
+		bestCost *apd.Decimal
+		noMatch  = errNoMatchingOffer{wantXMR: wantXMR, maxETH: maxETH}
+	)
+
+	for _, o := range offers {
+		if o.Provides != coins.ProvidesXMR || o.EthAsset != EthAssetETH {
+			noMatch.notETH++
+			continue
+		}
+
+		if wantXMR.Cmp(o.MinAmount) < 0 || wantXMR.Cmp(o.MaxAmount) > 0 {
+			noMatch.outOfRange++
+			continue
+		}
+
+		if o.ExchangeRate == nil {
+			noMatch.invalidRate++
+			continue
+		}
+
+		cost, err := o.ExchangeRate.ToETH(wantXMR)
+		if err != nil {
+			noMatch.invalidRate++
+			continue
+		}
+
+		if maxETH != nil && cost.Cmp(maxETH.AsEther()) > 0 {
+			noMatch.overBudget++
+			continue
+		}
+
+		if best == nil || cost.Cmp(bestCost) < 0 {
+			best = o
+			bestCost = cost
+		}
+	}
+
+	if best == nil {
+		return nil, noMatch
+	}
+
+	return best, nil
+}
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestSelectBestOffer(t *testing.T) {
+	token := EthAsset(ethcommon.HexToAddress("0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8"))
+	rate := func(s string) *coins.ExchangeRate {
+		return coins.ToExchangeRate(coins.StrToDecimal(s))
+	}
+
+	cheapSmall := NewOffer(coins.ProvidesXMR, apd.New(1, 0), apd.New(2, 0), rate("0.05"), EthAssetETH)
+	cheapToken := NewOffer(coins.ProvidesXMR, apd.New(1, 0), apd.New(20, 0), rate("0.01"), token)
+	providesETH := NewOffer(coins.ProvidesETH, apd.New(1, 0), apd.New(20, 0), rate("0.01"), EthAssetETH)
+	mid := NewOffer(coins.ProvidesXMR, apd.New(1, 0), apd.New(20, 0), rate("0.07"), EthAssetETH)
+	expensive := NewOffer(coins.ProvidesXMR, apd.New(1, 0), apd.New(20, 0), rate("0.09"), EthAssetETH)
+	offers := []*Offer{expensive, cheapSmall, cheapToken, providesETH, mid}
+
+	// the cheapest offer that allows the amount
+	best, err := SelectBestOffer(offers, apd.New(15, -1), nil)
+	require.NoError(t, err)
+	require.Equal(t, cheapSmall.ID, best.ID)
+
+	// cheapSmall doesn't allow 5 XMR
+	best, err = SelectBestOffer(offers, apd.New(5, 0), nil)
+	require.NoError(t, err)
+	require.Equal(t, mid.ID, best.ID)
+
+	// 5 XMR costs 0.35 ETH with mid
+	budget := coins.EtherToWei(coins.StrToDecimal("0.35"))
+	best, err = SelectBestOffer(offers, apd.New(5, 0), budget)
+	require.NoError(t, err)
+	require.Equal(t, mid.ID, best.ID)
+
+	budget = coins.EtherToWei(coins.StrToDecimal("0.3"))
+	_, err = SelectBestOffer(offers, apd.New(5, 0), budget)
+	var noMatch errNoMatchingOffer
+	require.ErrorAs(t, err, &noMatch)
+	require.Equal(t, 2, noMatch.notETH)
+	require.Equal(t, 1, noMatch.outOfRange)
+	require.Equal(t, 2, noMatch.overBudget)
+	require.ErrorContains(t, err, "2 cost too much")
+
+	_, err = SelectBestOffer(nil, apd.New(5, 0), nil)
+	require.ErrorIs(t, err, errNoOffers)
+}
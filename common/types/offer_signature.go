@@ -0,0 +1,58 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// offerSignaturePrefix is prepended to the offer ID before signing, so that an
+// offer signature can't be passed off as a signature of anything else.
+const offerSignaturePrefix = "atomic-swap offer:"
+
+var (
+	errOfferNotSigned        = errors.New("offer is not signed")
+	errInvalidOfferSignature = errors.New("offer signature does not match peer")
+)
+
+func (o *Offer) signedBytes() []byte {
+	return append([]byte(offerSignaturePrefix), o.ID[:]...)
+}
+
+// Sign sets the offer's signature using the maker's libp2p private key. As the
+// offer ID is the hash of all other fields, signing the ID covers the whole
+// offer.
+func (o *Offer) Sign(key libp2pcrypto.PrivKey) error {
+	sig, err := key.Sign(o.signedBytes())
+	if err != nil {
+		return fmt.Errorf("failed to sign offer %s: %w", o.ID, err)
+	}
+
+	o.Signature = sig
+	return nil
+}
+
+// VerifyOfferSignature returns an error if the offer is not signed, or if its
+// signature wasn't made by the libp2p key of the passed peer.
+func (o *Offer) VerifyOfferSignature(id peer.ID) error {
+	if len(o.Signature) == 0 {
+		return errOfferNotSigned
+	}
+
+	pubKey, err := id.ExtractPublicKey()
+	if err != nil {
+		return fmt.Errorf("failed to get public key of peer %s: %w", id, err)
+	}
+
+	ok, err := pubKey.Verify(o.signedBytes(), o.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to verify signature of offer %s: %w", o.ID, err)
+	}
+	if !ok {
+		return errInvalidOfferSignature
+	}
+
+	return nil
+}
@@ -1,12 +1,16 @@
 package types
 
 import (
+	"crypto/rand"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -41,13 +45,13 @@ func TestOffer_UnmarshalJSON(t *testing.T) {
 	max := apd.New(200, 0)
 	rate := coins.ToExchangeRate(apd.New(15, -1)) // 1.5
 	ethAsset := EthAsset(
-		ethcommon.HexToAddress("0x0000000000000000000000000000000000000001"),
+		ethcommon.HexToAddress("0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8"),
 	)
 	offer := NewOffer(coins.ProvidesXMR, min, max, rate, ethAsset)
 	require.False(t, IsHashZero(offer.ID))
 	v, _ := semver.NewVersion("0.1.0")
 	offer.Version = *v
-	offer.ID = offer.hash()
+	offer.ID = offer.Hash()
 
 	offerJSON := fmt.Sprintf(`{
 		"version": "0.1.0",
@@ -100,6 +104,39 @@ func TestOffer_UnmarshalJSON_DefaultAsset(t *testing.T) {
 	assert.Equal(t, EthAssetETH, res.EthAsset)
 }
 
+func TestOffer_validate_DefaultAsset(t *testing.T) {
+	min := apd.New(100, 0)
+	max := apd.New(200, 0)
+	rate := coins.ToExchangeRate(apd.New(15, -1)) // 1.5
+	offer := NewOffer(coins.ProvidesXMR, min, max, rate, EthAsset{})
+	require.Equal(t, EthAssetETH, offer.EthAsset)
+	require.NoError(t, offer.validate())
+}
+
+func TestOffer_UnmarshalJSON_InvalidAsset(t *testing.T) {
+	min := apd.New(100, 0)
+	max := apd.New(200, 0)
+	rate := coins.ToExchangeRate(apd.New(15, -1)) // 1.5
+	ethAsset := EthAsset(ethcommon.HexToAddress("0x0000000000000000000000000000000000000001"))
+	offer := NewOffer(coins.ProvidesXMR, min, max, rate, ethAsset)
+	require.ErrorIs(t, offer.validate(), errInvalidEthAsset)
+
+	offerJSON := fmt.Sprintf(`{
+		"version": "1.0.0",
+		"offerID": "%s",
+		"provides": "XMR",
+		"minAmount": "100",
+		"maxAmount": "200",
+		"exchangeRate": "1.5",
+		"ethAsset": "%s",
+		"nonce": %d
+	}`, offer.ID, ethAsset, offer.Nonce)
+
+	var res Offer
+	err := vjson.UnmarshalStruct([]byte(offerJSON), &res)
+	require.ErrorIs(t, err, errInvalidEthAsset)
+}
+
 func TestOffer_MarshalJSON_RoundTrip(t *testing.T) {
 	min := apd.New(100, 0)
 	max := apd.New(200, 0)
@@ -115,6 +152,64 @@ func TestOffer_MarshalJSON_RoundTrip(t *testing.T) {
 	assert.EqualValues(t, offer1, &offer2)
 }
 
+func TestOffer_SetSwapFactory(t *testing.T) {
+	min := apd.New(100, 0)
+	max := apd.New(200, 0)
+	rate := coins.ToExchangeRate(apd.New(15, -1)) // 1.5
+	offer1 := NewOffer(coins.ProvidesXMR, min, max, rate, EthAssetETH)
+	origID := offer1.ID
+
+	factory := ethcommon.HexToAddress("0x1234567890123456789012345678901234567890")
+	offer1.SetSwapFactory(factory)
+	require.NotEqual(t, origID, offer1.ID)
+	require.NoError(t, offer1.validate())
+
+	offerJSON, err := vjson.MarshalStruct(offer1)
+	require.NoError(t, err)
+	require.Contains(t, string(offerJSON), `"swapFactory":"0x1234567890123456789012345678901234567890"`)
+
+	var offer2 Offer
+	err = vjson.UnmarshalStruct(offerJSON, &offer2)
+	require.NoError(t, err)
+	require.Equal(t, factory, *offer2.SwapFactory)
+	require.Equal(t, offer1.ID, offer2.ID)
+
+	// the factory is covered by the offer ID
+	offer2.SwapFactory = &ethcommon.Address{0x1}
+	require.ErrorContains(t, offer2.validate(), "hash of offer fields does not match offer ID")
+}
+
+func TestOffer_SetSwapTimeout(t *testing.T) {
+	min := apd.New(100, 0)
+	max := apd.New(200, 0)
+	rate := coins.ToExchangeRate(apd.New(15, -1)) // 1.5
+	offer1 := NewOffer(coins.ProvidesXMR, min, max, rate, EthAssetETH)
+	origID := offer1.ID
+
+	offer1.SetSwapTimeout(3 * time.Hour)
+	require.NotEqual(t, origID, offer1.ID)
+	require.NoError(t, offer1.validate())
+
+	offerJSON, err := vjson.MarshalStruct(offer1)
+	require.NoError(t, err)
+	require.Contains(t, string(offerJSON), `"swapTimeout":10800`)
+
+	var offer2 Offer
+	err = vjson.UnmarshalStruct(offerJSON, &offer2)
+	require.NoError(t, err)
+	require.Equal(t, 3*time.Hour, offer2.SwapTimeoutDuration())
+	require.Equal(t, offer1.ID, offer2.ID)
+
+	// the timeout is covered by the offer ID
+	offer2.SwapTimeout = 3600
+	require.ErrorContains(t, offer2.validate(), "hash of offer fields does not match offer ID")
+
+	offer1.SetSwapTimeout(MaxSwapTimeout + time.Second)
+	require.ErrorIs(t, offer1.validate(), errSwapTimeoutRange)
+	offer1.SetSwapTimeout(MinSwapTimeout - time.Second)
+	require.ErrorIs(t, offer1.validate(), errSwapTimeoutRange)
+}
+
 func TestOffer_UnmarshalJSON_BadID(t *testing.T) {
 	offerJSON := []byte(`{
 		"version": "0.1.0",
@@ -125,7 +220,7 @@ func TestOffer_UnmarshalJSON_BadID(t *testing.T) {
 		"exchangeRate": "1.5",
 		"ethAsset": "ETH"
 	}`)
-	_, err := UnmarshalOffer(offerJSON)
+	_, err := UnmarshalOffer(offerJSON, nil)
 	require.Error(t, err)
 	require.ErrorContains(t, err, "hex string has length 0, want 64")
 }
@@ -140,8 +235,8 @@ func TestOffer_UnmarshalJSON_MissingID(t *testing.T) {
 		"ethAsset": "ETH",
 		"nonce": 1234
 	}`)
-	_, err := UnmarshalOffer(offerJSON)
-	require.ErrorContains(t, err, "Field validation for 'ID' failed on the 'required' tag")
+	_, err := UnmarshalOffer(offerJSON, nil)
+	require.ErrorContains(t, err, `"offerID" failed "required" validation`)
 }
 
 func TestOffer_UnmarshalJSON_BadAmountsOrRate(t *testing.T) {
@@ -162,7 +257,7 @@ func TestOffer_UnmarshalJSON_BadAmountsOrRate(t *testing.T) {
 		// Min amount checks
 		{
 			jsonData:    fmt.Sprintf(offerJSON, `null`, `"1"`, `"0.1"`),
-			errContains: `Field validation for 'MinAmount' failed on the 'required' tag`,
+			errContains: `"minAmount" failed "required" validation`,
 		},
 		{
 			jsonData:    fmt.Sprintf(offerJSON, `"0"`, `"1"`, `"0.1"`),
@@ -175,7 +270,7 @@ func TestOffer_UnmarshalJSON_BadAmountsOrRate(t *testing.T) {
 		// Max Amount checks
 		{
 			jsonData:    fmt.Sprintf(offerJSON, `"1"`, `null`, `"0.1"`),
-			errContains: `Field validation for 'MaxAmount' failed on the 'required' tag`,
+			errContains: `"maxAmount" failed "required" validation`,
 		},
 		{
 			jsonData:    fmt.Sprintf(offerJSON, `"1"`, `"-0"`, `"0.1"`),
@@ -193,7 +288,7 @@ func TestOffer_UnmarshalJSON_BadAmountsOrRate(t *testing.T) {
 		// Exchange rate checks
 		{
 			jsonData:    fmt.Sprintf(offerJSON, `"1"`, `"1"`, `null`),
-			errContains: `Field validation for 'ExchangeRate' failed on the 'required' tag`,
+			errContains: `"exchangeRate" failed "required" validation`,
 		},
 		{
 			jsonData:    fmt.Sprintf(offerJSON, `"1"`, `"1"`, `"0"`),
@@ -225,7 +320,7 @@ func TestOffer_UnmarshalJSON_BadProvides(t *testing.T) {
 }
 
 func TestUnmarshalOffer_MissingVersion(t *testing.T) {
-	_, err := UnmarshalOffer([]byte(`{}`))
+	_, err := UnmarshalOffer([]byte(`{}`), nil)
 	require.ErrorIs(t, err, errOfferVersionMissing)
 }
 
@@ -235,6 +330,110 @@ func TestUnmarshalOffer_VersionTooNew(t *testing.T) {
 		"version": "%s",
 		"some_unsupported_field": ""
 	}`, unsupportedVersion)
-	_, err := UnmarshalOffer([]byte(offerJSON))
+	_, err := UnmarshalOffer([]byte(offerJSON), nil)
 	require.ErrorContains(t, err, fmt.Sprintf("offer version %q not supported", unsupportedVersion))
 }
+
+func TestOffer_CheckDust(t *testing.T) {
+	rate := coins.ToExchangeRate(apd.New(1, -6)) // 0.000001
+
+	// 0.001 XMR is worth 1 gwei at the rate, exactly the default threshold
+	offer := NewOffer(coins.ProvidesXMR, apd.New(1, -3), apd.New(1, 0), rate, EthAssetETH)
+	require.NoError(t, offer.CheckDust(nil))
+
+	offer = NewOffer(coins.ProvidesXMR, apd.New(1, -4), apd.New(1, 0), rate, EthAssetETH)
+	err := offer.CheckDust(nil)
+	require.ErrorAs(t, err, new(errOfferBelowDust))
+	require.ErrorContains(t, err, "0.0001 XMR is only worth 0.0000000001 ETH")
+
+	// the default ETH threshold can be overridden
+	require.NoError(t, offer.CheckDust(DustThresholds{EthAssetETH: apd.New(1, -10)}))
+
+	// the threshold is our configuration, so it doesn't affect decoding
+	require.NoError(t, offer.validate())
+
+	// tokens without a threshold only need a non-zero value
+	token := EthAsset(ethcommon.HexToAddress("0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8"))
+	offer = NewOffer(coins.ProvidesXMR, apd.New(1, -4), apd.New(1, 0), rate, token)
+	require.NoError(t, offer.CheckDust(nil))
+
+	thresholds := DustThresholds{token: apd.New(1, 0)}
+	require.ErrorAs(t, offer.CheckTerms(thresholds, nil), new(errOfferBelowDust))
+}
+
+func TestOffer_CheckExchangeRateBounds(t *testing.T) {
+	bounds := &coins.ExchangeRateBounds{Min: apd.New(1, -3), Max: apd.New(1, 0)}
+
+	one := apd.New(1, 0)
+	offer := NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(apd.New(5, -2)), EthAssetETH)
+	require.NoError(t, offer.CheckTerms(nil, bounds))
+
+	// an exchange rate off by orders of magnitude is rejected
+	offer = NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(apd.New(50, 0)), EthAssetETH)
+	require.ErrorContains(t, offer.CheckExchangeRateBounds(bounds), "50 is above the maximum of 1")
+	require.ErrorContains(t, offer.CheckTerms(nil, bounds), "50 is above the maximum of 1")
+
+	// and fails to decode with the bounds
+	offerJSON, err := vjson.MarshalStruct(offer)
+	require.NoError(t, err)
+	_, err = UnmarshalOffer(offerJSON, bounds)
+	require.ErrorContains(t, err, "50 is above the maximum of 1")
+
+	// but still decodes without them, eg. when loaded from the database
+	_, err = UnmarshalOffer(offerJSON, nil)
+	require.NoError(t, err)
+
+	// token offers aren't bounded
+	token := EthAsset(ethcommon.HexToAddress("0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8"))
+	offer = NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(apd.New(50, 0)), token)
+	require.NoError(t, offer.CheckExchangeRateBounds(bounds))
+	offerJSON, err = vjson.MarshalStruct(offer)
+	require.NoError(t, err)
+	_, err = UnmarshalOffer(offerJSON, bounds)
+	require.NoError(t, err)
+}
+
+func TestOffer_VerifyOfferSignature(t *testing.T) {
+	one := apd.New(1, 0)
+	offer := NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), EthAssetETH)
+
+	key, _, err := libp2pcrypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+	id, err := peer.IDFromPrivateKey(key)
+	require.NoError(t, err)
+
+	otherKey, _, err := libp2pcrypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+	otherID, err := peer.IDFromPrivateKey(otherKey)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, offer.VerifyOfferSignature(id), errOfferNotSigned)
+
+	require.NoError(t, offer.Sign(key))
+	require.NoError(t, offer.VerifyOfferSignature(id))
+	require.ErrorIs(t, offer.VerifyOfferSignature(otherID), errInvalidOfferSignature)
+
+	// the signature survives a JSON round trip, without changing the offer ID
+	data, err := vjson.MarshalStruct(offer)
+	require.NoError(t, err)
+	offer2, err := UnmarshalOffer(data, nil)
+	require.NoError(t, err)
+	require.NoError(t, offer2.VerifyOfferSignature(id))
+}
+
+func TestOfferVisibility_VisibleTo(t *testing.T) {
+	allowed := peer.ID("allowed")
+	other := peer.ID("other")
+
+	public := new(OfferVisibility)
+	assert.True(t, public.VisibleTo(allowed))
+	assert.True(t, public.VisibleTo(""))
+
+	private := &OfferVisibility{Private: true, AllowedPeers: []peer.ID{allowed}}
+	assert.True(t, private.VisibleTo(allowed))
+	assert.False(t, private.VisibleTo(other))
+	assert.False(t, private.VisibleTo(""))
+
+	hidden := &OfferVisibility{Private: true}
+	assert.False(t, hidden.VisibleTo(allowed))
+}
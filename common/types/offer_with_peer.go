@@ -0,0 +1,91 @@
+package types
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+// OfferWithPeer is an offer in an orderbook merged from the offers of several
+// makers, along with the maker that advertised it.
+type OfferWithPeer struct {
+	PeerID        peer.ID        `json:"peerID" validate:"required"`
+	Offer         *Offer         `json:"offer" validate:"required"`
+	TakeableRange *TakeableRange `json:"takeableRange,omitempty"`
+
+	// EffectiveRate is the exchange rate that the taker effectively gets
+	// after the relayer fee, for the largest swap that the offer allows. It's
+	// only set for offers whose effective rate was computed.
+	EffectiveRate *coins.ExchangeRate `json:"effectiveRate,omitempty"`
+
+	// noEffectiveRate is set if the effective rate couldn't be computed, eg.
+	// because the relayer fee is more than the offer's ETH
+	noEffectiveRate bool
+}
+
+// SetEffectiveRate sets the effective rate of the offer after the relayer fee,
+// for the largest swap that the offer allows, which is the maker's takeable
+// range if it reported one. If it can't be computed, the offer is sorted after
+// the offers of its asset that could be.
+func (o *OfferWithPeer) SetEffectiveRate(fees *RelayerFeePolicy) error {
+	xmrAmount := o.Offer.MaxAmount
+	if o.TakeableRange != nil && o.TakeableRange.MaxAmount != nil {
+		xmrAmount = o.TakeableRange.MaxAmount
+	}
+
+	rate, err := o.Offer.EffectiveExchangeRate(xmrAmount, fees)
+	if err != nil {
+		o.noEffectiveRate = true
+		return err
+	}
+
+	o.EffectiveRate = rate
+	return nil
+}
+
+// rate returns the rate that the offer is sorted by, which is its effective
+// rate if it's set.
+func (o *OfferWithPeer) rate() *coins.ExchangeRate {
+	if o.EffectiveRate != nil {
+		return o.EffectiveRate
+	}
+	return o.Offer.ExchangeRate
+}
+
+// SortOrderbook sorts the offers with the best exchange rates for a taker
+// first, using their effective rates where they're set. Offers that provide
+// XMR, which are best with the lowest rate, come before offers that provide
+// ETH, which are best with the highest rate. As rates are only comparable
+// between offers for the same asset, offers are grouped by their asset, with
+// ETH offers first. Offers whose effective rate couldn't be computed come last
+// in their group, as their rate isn't what the taker would get. Offers with the
+// same rate are ordered by ID, so that the order doesn't depend on the order
+// that the offers were received in.
+func SortOrderbook(offers []*OfferWithPeer) {
+	sort.SliceStable(offers, func(i, j int) bool {
+		a, b := offers[i], offers[j]
+		if a.Offer.Provides != b.Offer.Provides {
+			return a.Offer.Provides == coins.ProvidesXMR
+		}
+
+		if a.Offer.EthAsset != b.Offer.EthAsset {
+			return bytes.Compare(a.Offer.EthAsset[:], b.Offer.EthAsset[:]) < 0
+		}
+
+		if a.noEffectiveRate != b.noEffectiveRate {
+			return b.noEffectiveRate
+		}
+
+		cmp := a.rate().Decimal().Cmp(b.rate().Decimal())
+		if cmp == 0 {
+			return bytes.Compare(a.Offer.ID[:], b.Offer.ID[:]) < 0
+		}
+		if a.Offer.Provides == coins.ProvidesETH {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestSortOrderbook(t *testing.T) {
+	newEntry := func(provides coins.ProvidesCoin, rate string) *OfferWithPeer {
+		return &OfferWithPeer{
+			PeerID: "maker",
+			Offer: NewOffer(
+				provides,
+				coins.StrToDecimal("1"),
+				coins.StrToDecimal("2"),
+				coins.ToExchangeRate(coins.StrToDecimal(rate)),
+				EthAssetETH,
+			),
+		}
+	}
+
+	xmrHigh := newEntry(coins.ProvidesXMR, "0.2")
+	xmrLow := newEntry(coins.ProvidesXMR, "0.1")
+	ethHigh := newEntry(coins.ProvidesETH, "0.2")
+	ethLow := newEntry(coins.ProvidesETH, "0.1")
+
+	book := []*OfferWithPeer{ethLow, xmrHigh, ethHigh, xmrLow}
+	SortOrderbook(book)
+	require.Equal(t, []*OfferWithPeer{xmrLow, xmrHigh, ethHigh, ethLow}, book)
+}
+
+func TestSortOrderbook_assets(t *testing.T) {
+	token := EthAsset(ethcommon.HexToAddress("0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8"))
+	newEntry := func(rate string, asset EthAsset) *OfferWithPeer {
+		return &OfferWithPeer{
+			PeerID: "maker",
+			Offer: NewOffer(
+				coins.ProvidesXMR,
+				coins.StrToDecimal("1"),
+				coins.StrToDecimal("2"),
+				coins.ToExchangeRate(coins.StrToDecimal(rate)),
+				asset,
+			),
+		}
+	}
+
+	// token rates aren't comparable with ETH rates
+	ethHigh := newEntry("0.2", EthAssetETH)
+	ethLow := newEntry("0.1", EthAssetETH)
+	tokenHigh := newEntry("300", token)
+	tokenLow := newEntry("0.01", token)
+
+	book := []*OfferWithPeer{tokenHigh, ethHigh, tokenLow, ethLow}
+	SortOrderbook(book)
+	require.Equal(t, []*OfferWithPeer{ethLow, ethHigh, tokenLow, tokenHigh}, book)
+}
+
+func TestSortOrderbook_effectiveRates(t *testing.T) {
+	newEntry := func(rate string, maxAmount string) *OfferWithPeer {
+		return &OfferWithPeer{
+			PeerID: "maker",
+			Offer: NewOffer(
+				coins.ProvidesETH,
+				coins.StrToDecimal("0.1"),
+				coins.StrToDecimal(maxAmount),
+				coins.ToExchangeRate(coins.StrToDecimal(rate)),
+				EthAssetETH,
+			),
+		}
+	}
+
+	// the fixed fee takes a bigger share of the small offer, so its higher
+	// rate is effectively worse
+	small := newEntry("0.11", "0.2")
+	large := newEntry("0.1", "10")
+
+	fees := &RelayerFeePolicy{Fixed: coins.StrToDecimal("0.009")}
+	require.NoError(t, small.SetEffectiveRate(fees))
+	require.NoError(t, large.SetEffectiveRate(fees))
+
+	book := []*OfferWithPeer{small, large}
+	SortOrderbook(book)
+	require.Equal(t, []*OfferWithPeer{large, small}, book)
+}
+
+func TestSortOrderbook_noEffectiveRate(t *testing.T) {
+	newEntry := func(rate string, maxAmount string) *OfferWithPeer {
+		return &OfferWithPeer{
+			PeerID: "maker",
+			Offer: NewOffer(
+				coins.ProvidesETH,
+				coins.StrToDecimal("0.01"),
+				coins.StrToDecimal(maxAmount),
+				coins.ToExchangeRate(coins.StrToDecimal(rate)),
+				EthAssetETH,
+			),
+		}
+	}
+
+	// the fee is more than the ETH of the tiny offer, so its high rate isn't
+	// what a taker would get
+	tiny := newEntry("0.15", "0.05")
+	normal := newEntry("0.1", "10")
+
+	fees := &RelayerFeePolicy{Fixed: coins.StrToDecimal("0.009")}
+	require.Error(t, tiny.SetEffectiveRate(fees))
+	require.NoError(t, normal.SetEffectiveRate(fees))
+
+	book := []*OfferWithPeer{tiny, normal}
+	SortOrderbook(book)
+	require.Equal(t, []*OfferWithPeer{normal, tiny}, book)
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// RelayerClaimStats counts the claims that a relayer submitted for us that
+// were mined, and those that it failed to submit.
+type RelayerClaimStats struct {
+	PeerID    peer.ID `json:"peerID" validate:"required"`
+	Succeeded uint64  `json:"succeeded"`
+	Failed    uint64  `json:"failed"`
+}
+
+// Attempts returns the number of claims submitted to the relayer.
+func (s *RelayerClaimStats) Attempts() uint64 {
+	return s.Succeeded + s.Failed
+}
+
+// SuccessRate returns the fraction of claims submitted to the relayer that were
+// mined. A relayer without any attempts has a success rate of 1.
+func (s *RelayerClaimStats) SuccessRate() float64 {
+	if s.Attempts() == 0 {
+		return 1
+	}
+
+	return float64(s.Succeeded) / float64(s.Attempts())
+}
@@ -0,0 +1,95 @@
+package types
+
+import (
+	"errors"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+var (
+	errSwapAmountNotPositive   = errors.New("swap amount must be positive")
+	errRelayerFeeExceedsAmount = errors.New("relayer fee is more than the claimed amount")
+)
+
+// RelayerFeePolicy is how a relayer computes its fee for relaying a claim: a
+// fixed amount of the swap's asset, in its standard units, plus a percentage of
+// the claimed amount. Either part can be nil.
+type RelayerFeePolicy struct {
+	Fixed   *apd.Decimal `json:"fixed,omitempty"`
+	Percent *apd.Decimal `json:"percent,omitempty"`
+}
+
+// Fee returns the fee charged for relaying the claim of amount.
+func (p *RelayerFeePolicy) Fee(amount *apd.Decimal) (*apd.Decimal, error) {
+	ctx := coins.DecimalCtx()
+	fee := new(apd.Decimal)
+
+	if p.Percent != nil {
+		if _, err := ctx.Mul(fee, amount, p.Percent); err != nil {
+			return nil, err
+		}
+		if _, err := ctx.Quo(fee, fee, apd.New(100, 0)); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Fixed != nil {
+		if _, err := ctx.Add(fee, fee, p.Fixed); err != nil {
+			return nil, err
+		}
+	}
+
+	return fee, nil
+}
+
+// EffectiveExchangeRate returns the exchange rate that the taker of the offer
+// effectively gets for a swap of xmrAmount, once the relayer fee is deducted
+// from the ETH that is claimed through the relayer. The ETH is claimed by the
+// side that provides XMR, so only takers of offers that provide ETH pay the
+// fee. For offers that provide XMR, the fee comes out of the maker's ETH, and
+// the offer's rate is returned. The rate is rounded down, in the taker's
+// disfavour, to the decimals that exchange rates can have.
+func (o *Offer) EffectiveExchangeRate(xmrAmount *apd.Decimal, fees *RelayerFeePolicy) (*coins.ExchangeRate, error) {
+	if o.Provides != coins.ProvidesETH || fees == nil {
+		return o.ExchangeRate, nil
+	}
+
+	if xmrAmount.Sign() <= 0 {
+		return nil, errSwapAmountNotPositive
+	}
+
+	ethAmount, err := o.ExchangeRate.ToETH(xmrAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	fee, err := fees.Fee(ethAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := coins.DecimalCtx()
+	received := new(apd.Decimal)
+	if _, err = ctx.Sub(received, ethAmount, fee); err != nil {
+		return nil, err
+	}
+
+	rate := new(apd.Decimal)
+	if _, err = ctx.Quo(rate, received, xmrAmount); err != nil {
+		return nil, err
+	}
+
+	ctx.Rounding = apd.RoundDown
+	if _, err = ctx.Quantize(rate, rate, -coins.MaxExchangeRateDecimals); err != nil {
+		return nil, err
+	}
+
+	if rate.Sign() <= 0 {
+		return nil, errRelayerFeeExceedsAmount
+	}
+
+	_, _ = rate.Reduce(rate)
+	return coins.ToExchangeRate(rate), nil
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestRelayerFeePolicy_Fee(t *testing.T) {
+	fees := &RelayerFeePolicy{
+		Fixed:   coins.StrToDecimal("0.01"),
+		Percent: coins.StrToDecimal("1"),
+	}
+
+	fee, err := fees.Fee(coins.StrToDecimal("0.2"))
+	require.NoError(t, err)
+	require.Zero(t, fee.Cmp(coins.StrToDecimal("0.012")))
+
+	fee, err = new(RelayerFeePolicy).Fee(coins.StrToDecimal("0.2"))
+	require.NoError(t, err)
+	require.True(t, fee.IsZero())
+}
+
+func TestOffer_EffectiveExchangeRate(t *testing.T) {
+	one := coins.StrToDecimal("1")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	ethOffer := NewOffer(coins.ProvidesETH, one, coins.StrToDecimal("3"), rate, EthAssetETH)
+	xmrOffer := NewOffer(coins.ProvidesXMR, one, coins.StrToDecimal("3"), rate, EthAssetETH)
+
+	fees := &RelayerFeePolicy{
+		Fixed:   coins.StrToDecimal("0.01"),
+		Percent: coins.StrToDecimal("1"),
+	}
+
+	// 0.2 ETH for 2 XMR, minus a 0.012 ETH fee
+	effective, err := ethOffer.EffectiveExchangeRate(coins.StrToDecimal("2"), fees)
+	require.NoError(t, err)
+	require.Equal(t, "0.094", effective.String())
+
+	// rounded down to the decimals of exchange rates
+	effective, err = ethOffer.EffectiveExchangeRate(coins.StrToDecimal("3"), &RelayerFeePolicy{
+		Fixed: coins.StrToDecimal("0.01"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0.096666", effective.String())
+
+	// the maker of XMR offers pays the fee out of the ETH it claims
+	effective, err = xmrOffer.EffectiveExchangeRate(coins.StrToDecimal("2"), fees)
+	require.NoError(t, err)
+	require.Equal(t, "0.1", effective.String())
+
+	_, err = ethOffer.EffectiveExchangeRate(coins.StrToDecimal("0.1"), fees)
+	require.ErrorIs(t, err, errRelayerFeeExceedsAmount)
+
+	_, err = ethOffer.EffectiveExchangeRate(coins.StrToDecimal("0"), fees)
+	require.ErrorIs(t, err, errSwapAmountNotPositive)
+}
@@ -0,0 +1,16 @@
+package types
+
+import (
+	"github.com/cockroachdb/apd/v3"
+)
+
+// StrandedSwapFunds is XMR that was found in the shared address of a past swap,
+// for which we hold both parties' private keys, by a sweep of stranded swap
+// funds. If the sweep of the address failed, Error is set.
+type StrandedSwapFunds struct {
+	SwapID  Hash         `json:"swapID" validate:"required"`
+	Address string       `json:"address" validate:"required"`
+	Amount  *apd.Decimal `json:"amount" validate:"required"` // in XMR
+	TxIDs   []string     `json:"txIDs,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
@@ -29,10 +29,11 @@ func MarshalIndentStruct(v any, prefix, indent string) ([]byte, error) {
 }
 
 // UnmarshalStruct adds additional validation on top of json.Unmarshal. Target
-// object be a struct pointer.
+// object be a struct pointer. If validation fails, the error is a
+// *ValidationError.
 func UnmarshalStruct(jsonData []byte, v any) error {
 	if err := json.Unmarshal(jsonData, v); err != nil {
 		return err
 	}
-	return validate.Struct(v)
+	return newValidationError(jsonValidate.Struct(v))
 }
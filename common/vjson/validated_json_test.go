@@ -49,6 +49,20 @@ func TestUnmarshalStruct(t *testing.T) {
 func TestUnmarshalStruct_notValid(t *testing.T) {
 	var s = new(SomeStruct)
 	err := UnmarshalStruct([]byte(`{"decimal":"0","hex":"xyz"}`), s)
-	errMsg := `Key: 'SomeStruct.Hex' Error:Field validation for 'Hex' failed on the 'hexadecimal' tag`
-	require.ErrorContains(t, err, errMsg)
+	require.EqualError(t, err, `invalid fields: "hex" failed "hexadecimal" validation`)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Equal(t, []*FieldError{{Field: "hex", Tag: "hexadecimal", Value: "xyz"}}, validationErr.Fields)
+}
+
+func TestUnmarshalStruct_nestedFields(t *testing.T) {
+	type outer struct {
+		Inner    *SomeStruct `json:"inner" validate:"required"`
+		Untagged string      `validate:"required"`
+	}
+
+	err := UnmarshalStruct([]byte(`{"inner":{"hex":"xyz"}}`), new(outer))
+	require.EqualError(t, err, `invalid fields: "inner.decimal" failed "required" validation, `+
+		`"inner.hex" failed "hexadecimal" validation, "Untagged" failed "required" validation`)
 }
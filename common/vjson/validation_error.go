@@ -0,0 +1,78 @@
+package vjson
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// jsonValidate validates unmarshalled structs, naming fields by their JSON
+// names in its errors, as those are the names that senders of the JSON know.
+var jsonValidate = newJSONValidator()
+
+func newJSONValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return field.Name
+		default:
+			return name
+		}
+	})
+	return v
+}
+
+// FieldError is a field of unmarshalled JSON that failed validation.
+type FieldError struct {
+	// Field is the JSON path of the field, like "swap.claimer"
+	Field string
+	// Tag is the validation that failed, like "required" or "len"
+	Tag string
+	// Value is the field's value after unmarshalling
+	Value any
+}
+
+// ValidationError is returned by UnmarshalStruct when the unmarshalled struct
+// fails validation, with each field that failed.
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = fmt.Sprintf("%q failed %q validation", f.Field, f.Tag)
+	}
+	return "invalid fields: " + strings.Join(msgs, ", ")
+}
+
+// newValidationError converts the errors of the validator into a
+// ValidationError. Other errors, like those of invalid inputs to the
+// validator, are returned as they are.
+func newValidationError(err error) error {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	fields := make([]*FieldError, len(fieldErrs))
+	for i, fe := range fieldErrs {
+		// the namespace starts with the name of the struct's type, which
+		// isn't part of the JSON
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		fields[i] = &FieldError{
+			Field: field,
+			Tag:   fe.Tag(),
+			Value: fe.Value(),
+		}
+	}
+
+	return &ValidationError{Fields: fields}
+}
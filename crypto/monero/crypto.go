@@ -85,6 +85,13 @@ func (kp *PrivateKeyPair) ViewKey() *PrivateViewKey {
 	return kp.vk
 }
 
+// Zero overwrites both private keys with zeros, for key pairs that are
+// discarded without being used.
+func (kp *PrivateKeyPair) Zero() {
+	kp.sk.key.Set(ed25519.NewScalar())
+	kp.vk.key.Set(ed25519.NewScalar())
+}
+
 // PrivateSpendKey represents a monero private spend key
 type PrivateSpendKey struct {
 	key *ed25519.Scalar
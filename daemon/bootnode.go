@@ -0,0 +1,56 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/net"
+)
+
+// hostListenIP returns the IP that the p2p host listens on. Dev environments
+// only listen locally.
+func hostListenIP(env common.Environment) string {
+	if env == common.Development {
+		return "127.0.0.1"
+	}
+	return "0.0.0.0"
+}
+
+// runBootnode runs the p2p host as a DHT bootnode until ctx is cancelled. No
+// database, wallets, swap handlers or RPC server are used, so the node only
+// helps its peers find each other.
+func runBootnode(ctx context.Context, conf *SwapdConfig) (err error) {
+	chainID := common.ChainIDFromEnv(conf.EnvConf.Env)
+
+	host, err := net.NewHost(&net.Config{
+		Ctx:           ctx,
+		DataDir:       conf.EnvConf.DataDir,
+		Port:          conf.Libp2pPort,
+		KeyFile:       conf.Libp2pKeyfile,
+		Bootnodes:     conf.EnvConf.Bootnodes,
+		ProtocolID:    fmt.Sprintf("%s/%d", net.ProtocolID, chainID),
+		ListenIP:      hostListenIP(conf.EnvConf.Env),
+		ListenAddrs:   conf.Libp2pListenAddrs,
+		AnnounceAddrs: conf.Libp2pAnnounceAddrs,
+		BootnodeOnly:  true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if hostErr := host.Stop(); hostErr != nil {
+			err = multierror.Append(err, fmt.Errorf("error shutting down peer-to-peer services: %w", hostErr))
+		}
+	}()
+
+	if err = host.Start(); err != nil {
+		return err
+	}
+
+	log.Infof("running as a bootnode with peer ID %s", host.PeerID())
+	<-ctx.Done()
+	return ctx.Err()
+}
@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/observer"
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+var errObserverNoSwapFactory = errors.New("observing on-chain swaps requires a swap factory address")
+
+// runObserver runs the p2p host as an observer until ctx is cancelled. Like a
+// bootnode, no database, wallets, keys or swap handlers are used. The offers of
+// the makers that it finds, and the on-chain swaps if an ethereum endpoint is
+// configured, are served by an RPC server with only the observer_ methods.
+func runObserver(ctx context.Context, conf *SwapdConfig) (err error) {
+	chainID := common.ChainIDFromEnv(conf.EnvConf.Env)
+
+	// observers don't make or take swaps, so their host is run like the host
+	// of a bootnode, without handlers
+	host, err := net.NewHost(&net.Config{
+		Ctx:           ctx,
+		DataDir:       conf.EnvConf.DataDir,
+		Port:          conf.Libp2pPort,
+		KeyFile:       conf.Libp2pKeyfile,
+		Bootnodes:     conf.EnvConf.Bootnodes,
+		ProtocolID:    fmt.Sprintf("%s/%d", net.ProtocolID, chainID),
+		ListenIP:      hostListenIP(conf.EnvConf.Env),
+		ListenAddrs:   conf.Libp2pListenAddrs,
+		AnnounceAddrs: conf.Libp2pAnnounceAddrs,
+		BootnodeOnly:  true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if hostErr := host.Stop(); hostErr != nil {
+			err = multierror.Append(err, fmt.Errorf("error shutting down peer-to-peer services: %w", hostErr))
+		}
+	}()
+
+	if err = host.Start(); err != nil {
+		return err
+	}
+
+	obs := observer.NewObserver(&observer.Config{
+		Ctx:          ctx,
+		Network:      host,
+		PollInterval: conf.ObserverPollInterval,
+	})
+	obs.Start()
+
+	if conf.ObserverEthEndpoint != "" {
+		if conf.EnvConf.SwapFactoryAddress == (ethcommon.Address{}) {
+			return errObserverNoSwapFactory
+		}
+
+		var ec *ethclient.Client
+		ec, _, err = extethclient.Dial(ctx, conf.ObserverEthEndpoint)
+		if err != nil {
+			return err
+		}
+		defer ec.Close()
+
+		if err = obs.WatchChain(ec, conf.EnvConf.SwapFactoryAddress, conf.ETHPollInterval); err != nil {
+			return err
+		}
+	}
+
+	rpcServer, err := rpc.NewObserverServer(&rpc.ObserverConfig{
+		Ctx:      ctx,
+		Address:  fmt.Sprintf("127.0.0.1:%d", conf.RPCPort),
+		Observer: obs,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("running as an observer with peer ID %s", host.PeerID())
+	err = rpcServer.Start() // blocks until server is shutdown or context is cancelled
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -6,28 +6,47 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
+	"os"
 	"path"
+	"time"
 
 	"github.com/ChainSafe/chaindb"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/hashicorp/go-multierror"
 	logging "github.com/ipfs/go-log"
+	ma "github.com/multiformats/go-multiaddr"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/db"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/metrics"
 	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
+	"github.com/athanorlabs/atomic-swap/protocol/txsender"
 	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker"
+	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker/offers"
 	"github.com/athanorlabs/atomic-swap/protocol/xmrtaker"
+	"github.com/athanorlabs/atomic-swap/relayer"
 	"github.com/athanorlabs/atomic-swap/rpc"
+	"github.com/athanorlabs/atomic-swap/webhook"
 )
 
 var log = logging.Logger("daemon")
 
+// DefaultShutdownTimeout is how long swapd waits, by default, for in-flight
+// swap steps to reach a recoverable state when shutting down.
+const DefaultShutdownTimeout = 2 * time.Minute
+
+// webhookDeadLetterFile is the file in the data dir that webhook payloads
+// which couldn't be delivered are appended to.
+const webhookDeadLetterFile = "webhook-dead-letters.jsonl"
+
 // SwapdConfig provides startup parameters for swapd.
 type SwapdConfig struct {
 	EnvConf        *common.Config
@@ -38,6 +57,249 @@ type SwapdConfig struct {
 	RPCPort        uint16
 	IsRelayer      bool
 	NoTransferBack bool
+
+	// Libp2pListenAddrs, if set, are the multiaddrs that the p2p host listens
+	// on, instead of Libp2pPort on all interfaces.
+	Libp2pListenAddrs []ma.Multiaddr
+
+	// Libp2pAnnounceAddrs, if set, are the multiaddrs that the p2p host
+	// announces to peers, instead of the addresses it listens on.
+	Libp2pAnnounceAddrs []ma.Multiaddr
+
+	// BootnodeOnly runs only the p2p host, as a DHT bootnode, without making
+	// or taking swaps. Only EnvConf and the libp2p settings are used, so no
+	// wallets need to be configured.
+	BootnodeOnly bool
+
+	// ObserverOnly runs the p2p host as an observer, recording the offers of
+	// makers, and the on-chain swaps of the swap factory if
+	// ObserverEthEndpoint is set, for the RPC server's observer_ methods.
+	// Like BootnodeOnly, no wallets need to be configured.
+	ObserverOnly         bool
+	ObserverEthEndpoint  string
+	ObserverPollInterval time.Duration
+
+	// RelayerRequestsPerSec and RelayerRequestBurst rate limit relay claim
+	// requests per peer. Zero values use the net package defaults.
+	RelayerRequestsPerSec float64
+	RelayerRequestBurst   uint
+
+	// RelayerMaxConcurrent and RelayerMaxQueued bound how many relay claim
+	// requests are handled at once and how many can wait. Zero values use the
+	// net package defaults.
+	RelayerMaxConcurrent uint
+	RelayerMaxQueued     uint
+
+	// MaxDecodeFailures is how many malformed messages are tolerated from a
+	// peer before its streams are closed. Nil uses the net package default.
+	// If BlockOnDecodeFailures is set, such peers are also blocklisted.
+	MaxDecodeFailures     *uint
+	BlockOnDecodeFailures bool
+
+	// RelayerRefreshInterval is how often the relayers that makers submit claims to
+	// are rediscovered. Zero uses the net package default.
+	RelayerRefreshInterval time.Duration
+
+	// OfferBookConcurrency, OfferBookPeerTimeout and OfferBookTimeout bound
+	// the peer queries of aggregated offer book fetches. Zero values use the
+	// net package defaults.
+	OfferBookConcurrency uint
+	OfferBookPeerTimeout time.Duration
+	OfferBookTimeout     time.Duration
+
+	// SwapResumeWindow is how long a swap waits for its dropped peer stream
+	// to be reopened before exiting, and SwapResumeRetries how many times the
+	// side that opened the stream tries to reopen it. Zero values use the net
+	// package defaults.
+	SwapResumeWindow  time.Duration
+	SwapResumeRetries uint
+
+	// PeerScoreWeights are how much swap outcomes, protocol violations and
+	// relayed claims change a peer's score in libp2p's connection manager.
+	// Nil uses the net package defaults.
+	PeerScoreWeights *net.PeerScoreWeights
+
+	// AcceptUnsignedOffers keeps the unsigned offers of makers that predate
+	// offer signing, instead of dropping them.
+	AcceptUnsignedOffers bool
+
+	// MinRelayerSuccessRate is the fraction of claims that a relayer must have
+	// gotten mined for the maker to keep submitting claims to it.
+	MinRelayerSuccessRate float64
+
+	// RelayerForwarders are the trusted forwarders that relayed claims may use.
+	// If empty, any forwarder with verified bytecode is accepted.
+	RelayerForwarders []ethcommon.Address
+
+	// RelayerIncludeClaimDetails has the relayer report the fee charged, and
+	// the gas used and block number of relayed claims to the maker.
+	RelayerIncludeClaimDetails bool
+
+	// RelayerFees are the fees charged for relaying claims, per asset. Nil
+	// only relays ETH claims, for relayer.FeeWei.
+	RelayerFees *relayer.FeeTable
+
+	// RelayerPayoutAddress, if set, is where the fees of relayed claims are
+	// forwarded to, instead of staying with the key that signs them.
+	RelayerPayoutAddress *ethcommon.Address
+
+	// RelayerPayoutMin is the least ETH, in wei, forwarded to the payout
+	// address in one transfer. Nil uses the default.
+	RelayerPayoutMin *big.Int
+
+	// RelayerClaimGas configures the gas limits of relayed claims, both the
+	// buffer added to the estimate of claims that we sign, and the ceiling of
+	// claims that we sign or relay. Nil uses the relayer defaults.
+	RelayerClaimGas *relayer.ClaimGasConfig
+
+	// ClaimGasBump configures how the gas price of claims that the maker sends
+	// itself is raised while they're stuck, and the most that they can spend
+	// on gas. Nil uses the txsender defaults.
+	ClaimGasBump *txsender.GasBumpConfig
+
+	// XMRLockTolerance is how many piconeros the maker's XMR lock may fall
+	// short of the amount the taker expects. Nil uses the xmrtaker default.
+	XMRLockTolerance *uint64
+
+	// SkipConnectivityCheck has the maker accept takes without first checking
+	// that its ethereum endpoint and monero wallet are reachable and synced.
+	SkipConnectivityCheck bool
+
+	// XMRLockMargin is how long before t0 the maker's XMR lock must be
+	// confirmed by. If it isn't, the maker waits for the taker to refund.
+	XMRLockMargin time.Duration
+
+	// PerSwapWallet has the maker lock and reclaim each swap's XMR using a
+	// dedicated wallet named by the swap ID, which is funded with the lock
+	// amount and fee from the primary wallet before the lock.
+	PerSwapWallet bool
+
+	// PerSwapAccount has both swap sides sweep each swap's claimed or
+	// reclaimed XMR to a wallet account created for the swap.
+	PerSwapAccount bool
+
+	// ReclaimOnKeyPersistFailure has the maker reclaim XMR after a refund even
+	// if storing the counterparty's swap key in the db fails.
+	ReclaimOnKeyPersistFailure bool
+
+	// CollapseDuplicateOffers has the maker reuse an existing offer with the
+	// same terms instead of adding a duplicate that only differs by its nonce.
+	CollapseDuplicateOffers bool
+
+	// ClaimReceiptRetries is how many times the maker retries fetching the
+	// receipt of a relayed claim after an RPC error. Zero uses the default.
+	ClaimReceiptRetries uint
+
+	// RelayClaimRetries is how many more times the maker submits a relayed
+	// claim to the relayers that failed transiently. Zero uses the default.
+	RelayClaimRetries uint
+
+	// ClaimDeadlineMargin is how long before t1 the maker stops waiting on
+	// relayers to claim, and claims directly. Zero uses the xmrmaker default.
+	ClaimDeadlineMargin time.Duration
+
+	// MoneroStartHeightRollback is how many blocks below the current monero
+	// height both swap sides start scanning for the XMR lock, to tolerate
+	// reorgs. Nil uses monero.MinSpendConfirmations.
+	MoneroStartHeightRollback *uint64
+
+	// SweepConfirmations is how many confirmations both swap sides wait for
+	// when sweeping claimed XMR out of a swap wallet. Zero uses the default.
+	SweepConfirmations uint64
+
+	// ETHPollInterval is how often swap event watchers poll the ethereum node
+	// for new blocks. Zero uses the default.
+	ETHPollInterval time.Duration
+
+	// ETHSubscribe has swap event watchers subscribe to new blocks, instead
+	// of polling for them. It needs a WebSocket or IPC ethereum endpoint.
+	ETHSubscribe bool
+
+	// ResumeConcurrency is how many ongoing swaps are resumed at once on
+	// startup. Swaps closest to their t1 timeout are resumed first. Zero uses
+	// the default.
+	ResumeConcurrency uint
+
+	// KeyGenRetries is how many times the maker retries generating its swap
+	// keys and DLEq proof when an offer is taken. Zero uses the default.
+	KeyGenRetries uint
+
+	// MaxConcurrentSwaps is the maximum number of swaps the maker runs at
+	// once. Takes are rejected while it's reached. Zero is unlimited.
+	MaxConcurrentSwaps uint
+
+	// MoneroLockPriority is the priority of the maker's XMR lock transfer,
+	// unless an offer overrides it. The default lets the wallet pick.
+	MoneroLockPriority types.MoneroTxPriority
+
+	// ETHLockConfirmations is how many confirmations the maker waits for on
+	// the taker's ETH lock before locking XMR. Zero only waits for it to be
+	// mined.
+	ETHLockConfirmations uint64
+
+	// XMRReservationWindow is how long the maker holds back the XMR of an
+	// accepted take from its balance while waiting to lock it. Zero uses the
+	// xmrmaker default.
+	XMRReservationWindow time.Duration
+
+	// ProofCacheSize is how many verified DLEq proofs of takers the maker
+	// remembers, so repeated takes with the same keys aren't verified again.
+	// Zero verifies every proof.
+	ProofCacheSize int
+
+	// KeyPoolSize is how many swap keys and DLEq proofs the maker generates
+	// ahead of time in the background. Zero generates them as swaps start.
+	KeyPoolSize uint
+
+	// MaxOfferBalanceFraction is the largest fraction (0-1) of the maker's
+	// unlocked XMR balance that an offer's max amount can be. Zero doesn't
+	// limit offers beyond the balance.
+	MaxOfferBalanceFraction float64
+
+	// OfferRevalidationInterval is how often the maker's offers are checked
+	// against MaxOfferBalanceFraction. Zero disables the checks.
+	OfferRevalidationInterval time.Duration
+
+	// OraclePricing, if set, pegs the exchange rates of the maker's offers to
+	// a price oracle, and FiatPricing, if set, lets the maker make offers
+	// whose amounts are pegged to a fiat currency.
+	OraclePricing *offers.OraclePricingConfig
+	FiatPricing   *offers.FiatPricingConfig
+
+	// RecoveryDBPassphrase encrypts the values of the recovery db, which
+	// include swap private keys. If empty, they are stored in plaintext.
+	RecoveryDBPassphrase []byte
+
+	// DustThresholds override the minimum value, in standard units of each
+	// asset, that an offer's MinAmount must be worth at its exchange rate.
+	DustThresholds types.DustThresholds
+
+	// ShutdownTimeout is how long to wait for in-flight swap steps, like locks,
+	// claims and refunds, to finish when shutting down. Zero uses
+	// DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+
+	// MetricsAddress is the address that Prometheus metrics are served on. If
+	// empty, metrics are not served.
+	MetricsAddress string
+
+	// ImportOffersFile is an offer book, a JSON array of offers, that is
+	// imported when swapd starts, if set. Offers that have the same terms as
+	// a current offer aren't made again.
+	ImportOffersFile string
+
+	// RestoreDBFile is a backup written by personal_backupDatabase, that is
+	// restored into the db before it's opened, if set. The db must be empty.
+	RestoreDBFile string
+
+	// WebhookURL is POSTed every swap status change, if set. Requests are
+	// signed with WebhookSecret, if it's set. Failed deliveries are retried
+	// WebhookMaxRetries times, waiting WebhookRetryInterval before the first
+	// retry, before they're written to the dead-letter file in the data dir.
+	WebhookURL           string
+	WebhookSecret        []byte
+	WebhookMaxRetries    uint
+	WebhookRetryInterval time.Duration
 }
 
 // RunSwapDaemon assembles and runs a swapd instance blocking until swapd is
@@ -51,6 +313,14 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		conf.Libp2pKeyfile = path.Join(conf.EnvConf.DataDir, common.DefaultLibp2pKeyFileName)
 	}
 
+	if conf.BootnodeOnly {
+		return runBootnode(ctx, conf)
+	}
+
+	if conf.ObserverOnly {
+		return runObserver(ctx, conf)
+	}
+
 	if conf.EnvConf.SwapFactoryAddress == (ethcommon.Address{}) {
 		panic("swap factory address not specified")
 	}
@@ -60,9 +330,16 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 
 	// Initialize the database first, so the defer statement that closes it
 	// will get executed last.
-	sdb, err := db.NewDatabase(&chaindb.Config{
+	dbConf := &chaindb.Config{
 		DataDir: path.Join(conf.EnvConf.DataDir, "db"),
-	})
+	}
+	if conf.RestoreDBFile != "" {
+		if err = db.Restore(dbConf, conf.RestoreDBFile); err != nil {
+			return fmt.Errorf("failed to restore db: %w", err)
+		}
+	}
+
+	sdb, err := db.NewEncryptedDatabase(dbConf, conf.RecoveryDBPassphrase)
 	if err != nil {
 		return err
 	}
@@ -77,9 +354,23 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		return err
 	}
 
-	hostListenIP := "0.0.0.0"
-	if conf.EnvConf.Env == common.Development {
-		hostListenIP = "127.0.0.1"
+	// the webhook is added before the swap instances are created, so that
+	// status changes of resumed swaps are sent as well
+	if conf.WebhookURL != "" {
+		var dispatcher *webhook.Dispatcher
+		dispatcher, err = webhook.NewDispatcher(&webhook.Config{
+			Ctx:            ctx,
+			URL:            conf.WebhookURL,
+			Secret:         conf.WebhookSecret,
+			MaxRetries:     conf.WebhookMaxRetries,
+			RetryInterval:  conf.WebhookRetryInterval,
+			DeadLetterFile: path.Join(conf.EnvConf.DataDir, webhookDeadLetterFile),
+		})
+		if err != nil {
+			return err
+		}
+		sm.AddStatusListener(dispatcher.OnStatusChange)
+		dispatcher.Start()
 	}
 
 	host, err := net.NewHost(&net.Config{
@@ -89,8 +380,27 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		KeyFile:    conf.Libp2pKeyfile,
 		Bootnodes:  conf.EnvConf.Bootnodes,
 		ProtocolID: fmt.Sprintf("%s/%d", net.ProtocolID, chainID.Int64()),
-		ListenIP:   hostListenIP,
+		ListenIP:   hostListenIP(conf.EnvConf.Env),
 		IsRelayer:  conf.IsRelayer,
+
+		ListenAddrs:           conf.Libp2pListenAddrs,
+		AnnounceAddrs:         conf.Libp2pAnnounceAddrs,
+		RelayerRequestsPerSec: conf.RelayerRequestsPerSec,
+		RelayerRequestBurst:   conf.RelayerRequestBurst,
+		RelayerMaxConcurrent:  conf.RelayerMaxConcurrent,
+		RelayerMaxQueued:      conf.RelayerMaxQueued,
+		Blocklist:             sdb.Blocklist(),
+		MaxDecodeFailures:     conf.MaxDecodeFailures,
+		BlockOnDecodeFailures: conf.BlockOnDecodeFailures,
+
+		RelayerRefreshInterval: conf.RelayerRefreshInterval,
+		OfferBookConcurrency:   conf.OfferBookConcurrency,
+		OfferBookPeerTimeout:   conf.OfferBookPeerTimeout,
+		OfferBookTimeout:       conf.OfferBookTimeout,
+		PeerScoreWeights:       conf.PeerScoreWeights,
+		SwapResumeWindow:       conf.SwapResumeWindow,
+		SwapResumeRetries:      conf.SwapResumeRetries,
+		AcceptUnsignedOffers:   conf.AcceptUnsignedOffers,
 	})
 	if err != nil {
 		return err
@@ -101,8 +411,17 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		}
 	}()
 
+	sm.AddStatusListener(func(change *swap.StatusChange) {
+		host.RecordSwapOutcome(change.PeerID, change.New)
+	})
+
+	// Swaps get their own context, so that in-flight swap steps can reach a
+	// recoverable state after ctx is cancelled, before the swaps are stopped.
+	swapCtx, cancelSwaps := context.WithCancel(context.Background())
+	defer cancelSwaps()
+
 	swapBackend, err := backend.NewBackend(&backend.Config{
-		Ctx:                ctx,
+		Ctx:                swapCtx,
 		MoneroClient:       conf.MoneroClient,
 		EthereumClient:     conf.EthereumClient,
 		Environment:        conf.EnvConf.Env,
@@ -110,11 +429,28 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		SwapManager:        sm,
 		RecoveryDB:         sdb.RecoveryDB(),
 		Net:                host,
+
+		MoneroStartHeightRollback: conf.MoneroStartHeightRollback,
+		SweepConfirmations:        conf.SweepConfirmations,
+		ETHPollInterval:           conf.ETHPollInterval,
+		ETHSubscribe:              conf.ETHSubscribe,
+		ResumeConcurrency:         conf.ResumeConcurrency,
+		PerSwapAccount:            conf.PerSwapAccount,
+		DustThresholds:            conf.DustThresholds,
+		ExchangeRateBounds: &coins.ExchangeRateBounds{
+			Min: conf.EnvConf.MinExchangeRate,
+			Max: conf.EnvConf.MaxExchangeRate,
+		},
 	})
 	if err != nil {
 		return fmt.Errorf("failed to make backend: %w", err)
 	}
 
+	defer func() {
+		shutdownSwaps(sm, conf.ShutdownTimeout)
+		cancelSwaps()
+	}()
+
 	log.Infof("created backend with monero endpoint %s and ethereum endpoint %s",
 		swapBackend.XMRClient().Endpoint(),
 		conf.EthereumClient.Endpoint(),
@@ -124,6 +460,14 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		Backend:        swapBackend,
 		DataDir:        conf.EnvConf.DataDir,
 		NoTransferBack: conf.NoTransferBack,
+
+		RelayerForwarders:          conf.RelayerForwarders,
+		RelayerIncludeClaimDetails: conf.RelayerIncludeClaimDetails,
+		RelayerFees:                conf.RelayerFees,
+		RelayerPayoutAddress:       conf.RelayerPayoutAddress,
+		RelayerPayoutMin:           conf.RelayerPayoutMin,
+		RelayerClaimGas:            conf.RelayerClaimGas,
+		XMRLockTolerance:           conf.XMRLockTolerance,
 	})
 	if err != nil {
 		return err
@@ -134,17 +478,67 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		DataDir:  conf.EnvConf.DataDir,
 		Database: sdb,
 		Network:  host,
+
+		XMRLockMargin:         conf.XMRLockMargin,
+		PerSwapWallet:         conf.PerSwapWallet,
+		SkipConnectivityCheck: conf.SkipConnectivityCheck,
+
+		ReclaimOnKeyPersistFailure: conf.ReclaimOnKeyPersistFailure,
+		CollapseDuplicateOffers:    conf.CollapseDuplicateOffers,
+		ClaimReceiptRetries:        conf.ClaimReceiptRetries,
+		RelayClaimRetries:          conf.RelayClaimRetries,
+		ClaimDeadlineMargin:        conf.ClaimDeadlineMargin,
+		KeyGenRetries:              conf.KeyGenRetries,
+		MaxConcurrentSwaps:         conf.MaxConcurrentSwaps,
+		MoneroLockPriority:         conf.MoneroLockPriority,
+		ETHLockConfirmations:       conf.ETHLockConfirmations,
+		ETHOffers:                  xmrTaker,
+		RelayerStats:               sdb.RelayerStats(),
+		MinRelayerSuccessRate:      conf.MinRelayerSuccessRate,
+		XMRReservationWindow:       conf.XMRReservationWindow,
+		ProofCacheSize:             conf.ProofCacheSize,
+		KeyPoolSize:                conf.KeyPoolSize,
+		MaxOfferBalanceFraction:    conf.MaxOfferBalanceFraction,
+		OfferRevalidationInterval:  conf.OfferRevalidationInterval,
+		OraclePricing:              conf.OraclePricing,
+		FiatPricing:                conf.FiatPricing,
+		ClaimGas:                   conf.RelayerClaimGas,
+		ClaimGasBump:               conf.ClaimGasBump,
 	})
 	if err != nil {
 		return err
 	}
 
+	if conf.MetricsAddress != "" {
+		reg := metrics.NewRegistry(ctx, &metrics.Sources{
+			SwapManager: sm,
+			Offers:      xmrMaker,
+			XMRClient:   conf.MoneroClient,
+			ETHClient:   conf.EthereumClient,
+			RelayQueue:  host,
+		})
+		var metricsServer *metrics.Server
+		metricsServer, err = metrics.NewServer(ctx, conf.MetricsAddress, reg)
+		if err != nil {
+			return err
+		}
+		go func() {
+			_ = metricsServer.Start() // errors are logged by the server
+		}()
+	}
+
 	// connect the maker/taker handlers to the p2p network host
 	host.SetHandlers(xmrMaker, xmrTaker)
 	if err = host.Start(); err != nil {
 		return err
 	}
 
+	if conf.ImportOffersFile != "" {
+		if err = importOffers(xmrMaker, conf.ImportOffersFile); err != nil {
+			return err
+		}
+	}
+
 	rpcServer, err := rpc.NewServer(&rpc.Config{
 		Ctx:             ctx,
 		Address:         fmt.Sprintf("127.0.0.1:%d", conf.RPCPort),
@@ -152,6 +546,7 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		XMRTaker:        xmrTaker,
 		XMRMaker:        xmrMaker,
 		ProtocolBackend: swapBackend,
+		Database:        sdb,
 	})
 
 	log.Infof("starting swapd with data-dir %s", conf.EnvConf.DataDir)
@@ -164,3 +559,36 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 	// return statement below (not nil)
 	return err
 }
+
+// importOffers imports the offer book in the passed file.
+func importOffers(xmrMaker *xmrmaker.Instance, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read offer book: %w", err)
+	}
+
+	ids, err := xmrMaker.ImportOffers(data)
+	if err != nil {
+		return fmt.Errorf("failed to import offer book %s: %w", file, err)
+	}
+
+	log.Infof("imported offer book %s with %d offers", file, len(ids))
+	return nil
+}
+
+// shutdownSwaps stops new swaps and fund locks, and waits up to the passed
+// timeout for in-flight swap steps to finish. Swaps that are still ongoing are
+// resumed or aborted when swapd restarts.
+func shutdownSwaps(sm swap.Manager, timeout time.Duration) {
+	if timeout == 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Infof("waiting up to %s for in-flight swap steps to finish", timeout)
+	if err := sm.Shutdown(ctx); err != nil {
+		log.Warnf("in-flight swap steps did not finish before shutdown: %s", err)
+	}
+}
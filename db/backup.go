@@ -0,0 +1,225 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// BackupSchemaVersion is the version of the db layout that backups are taken
+// from. It's bumped whenever the keys or values stored by swapd change in a
+// way that older or newer versions can't read, so that such backups aren't
+// restored.
+const BackupSchemaVersion = 1
+
+var (
+	errBackupSchemaVersion = errors.New("unsupported backup schema version")
+	errBackupUnknownKey    = errors.New("backup contains a key outside of swapd's tables")
+	errRestoreDBNotEmpty   = errors.New("can only restore a backup into an empty db")
+)
+
+// tablePrefixes are the prefixes of all of the tables in the db.
+var tablePrefixes = []string{
+	offerPrefix,
+	swapPrefix,
+	visibilityPrefix,
+	lockPriorityPrefix,
+	recoveryPrefix,
+	blocklistPrefix,
+	relayerStatsPrefix,
+}
+
+// backup is the file written by Backup. Values are copied as they're stored,
+// so recovery db values stay encrypted, and restoring them needs the same
+// passphrase.
+type backup struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	Entries       []*backupEntry `json:"entries"`
+}
+
+type backupEntry struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Backup writes a snapshot of the whole db to destPath. The snapshot is read
+// in a single transaction, so it's consistent even if swapd is writing to the
+// db at the same time. The file is written next to destPath and then moved
+// into place, so an existing backup at destPath is only replaced by a
+// complete one.
+func (db *Database) Backup(destPath string) error {
+	b := &backup{
+		SchemaVersion: BackupSchemaVersion,
+		CreatedAt:     time.Now(),
+	}
+
+	iter := db.db.NewIterator()
+	for iter.Next() {
+		b.Entries = append(b.Entries, &backupEntry{
+			// the key is only valid until the iterator moves
+			Key:   append([]byte{}, iter.Key()...),
+			Value: iter.Value(),
+		})
+	}
+	iter.Release()
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := destPath + ".tmp"
+	if err = writeFileSynced(tmpPath, data); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	if err = os.Rename(tmpPath, destPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	log.Infof("backed up %d db entries to %s", len(b.Entries), destPath)
+	return nil
+}
+
+// Restore loads a backup written by Backup into the db with the passed
+// config, which must be empty. It's meant to be run before swapd opens the
+// db. The whole backup is validated before anything is written, so an
+// incompatible or corrupt backup leaves the db untouched.
+func Restore(cfg *chaindb.Config, srcPath string) error {
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+
+	b, err := parseBackup(data)
+	if err != nil {
+		return fmt.Errorf("invalid backup %s: %w", srcPath, err)
+	}
+
+	db, err := chaindb.NewBadgerDB(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err = restoreEntries(db, b.Entries); err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	if err = db.Close(); err != nil {
+		return err
+	}
+
+	log.Infof("restored %d db entries from backup %s, taken at %s", len(b.Entries), srcPath, b.CreatedAt)
+	return nil
+}
+
+// parseBackup parses and validates a backup written by Backup.
+func parseBackup(data []byte) (*backup, error) {
+	b := new(backup)
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, err
+	}
+
+	if b.SchemaVersion != BackupSchemaVersion {
+		return nil, fmt.Errorf("%w: %d, expected %d", errBackupSchemaVersion, b.SchemaVersion, BackupSchemaVersion)
+	}
+
+	for i, e := range b.Entries {
+		if e == nil {
+			return nil, fmt.Errorf("entry %d is empty", i)
+		}
+
+		if err := validateBackupEntry(e); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+	}
+
+	return b, nil
+}
+
+// validateBackupEntry checks that the entry is in one of our tables, and that
+// offers and swaps can be decoded. Other values are checked by the code that
+// reads them, when the restored db is opened.
+func validateBackupEntry(e *backupEntry) error {
+	switch {
+	case isTableKey(e.Key, offerPrefix):
+		if _, err := types.UnmarshalOffer(e.Value, nil); err != nil {
+			return fmt.Errorf("invalid offer: %w", err)
+		}
+	case isTableKey(e.Key, swapPrefix):
+		if _, err := swap.UnmarshalInfo(e.Value); err != nil {
+			return fmt.Errorf("invalid swap info: %w", err)
+		}
+	default:
+		for _, prefix := range tablePrefixes {
+			if bytes.HasPrefix(e.Key, []byte(prefix)) {
+				return nil
+			}
+		}
+		return errBackupUnknownKey
+	}
+
+	return nil
+}
+
+// isTableKey returns whether the key is a key of the table with the passed
+// prefix whose keys are IDs, ie. the offer or swap table.
+func isTableKey(key []byte, prefix string) bool {
+	return len(key) == len(prefix)+idLength && bytes.HasPrefix(key, []byte(prefix))
+}
+
+// restoreEntries writes the entries to db, after checking that it's empty.
+func restoreEntries(db chaindb.Database, entries []*backupEntry) error {
+	iter := db.NewIterator()
+	empty := !iter.Next()
+	iter.Release()
+	if !empty {
+		return errRestoreDBNotEmpty
+	}
+
+	batch := db.NewBatch()
+	for _, e := range entries {
+		if err := batch.Put(e.Key, e.Value); err != nil {
+			return err
+		}
+	}
+
+	if err := batch.Flush(); err != nil {
+		return err
+	}
+
+	return db.Flush()
+}
+
+// writeFileSynced writes the data to a new file at path, readable only by us,
+// and syncs it to disk.
+func writeFileSynced(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func TestDatabase_BackupAndRestore(t *testing.T) {
+	passphrase := []byte("backup passphrase")
+	db, err := NewEncryptedDatabase(&chaindb.Config{DataDir: t.TempDir()}, passphrase)
+	require.NoError(t, err)
+
+	one := coins.StrToDecimal("1")
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	require.NoError(t, db.PutOffer(offer))
+	require.NoError(t, db.PutOfferMoneroLockPriority(offer.ID, types.MoneroPriorityHigh))
+
+	info := &swap.Info{
+		Version:              swap.CurInfoVersion,
+		ID:                   types.Hash{0x1},
+		Provides:             coins.ProvidesXMR,
+		ProvidedAmount:       coins.StrToDecimal("0.1"),
+		ExpectedAmount:       coins.StrToDecimal("1"),
+		ExchangeRate:         coins.StrToExchangeRate("0.1"),
+		EthAsset:             types.EthAssetETH,
+		Status:               types.ExpectingKeys,
+		LastStatusUpdateTime: time.Now(),
+		StartTime:            time.Now(),
+	}
+	require.NoError(t, db.PutSwap(info))
+
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	require.NoError(t, db.RecoveryDB().PutSwapPrivateKey(info.ID, kp.SpendKey()))
+
+	backupPath := path.Join(t.TempDir(), "swapd.backup")
+	require.NoError(t, db.Backup(backupPath))
+	require.NoError(t, db.Close())
+
+	restoredCfg := &chaindb.Config{DataDir: t.TempDir()}
+	require.NoError(t, Restore(restoredCfg, backupPath))
+
+	restored, err := NewEncryptedDatabase(restoredCfg, passphrase)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, restored.Close()) }()
+
+	restoredOffer, err := restored.GetOffer(offer.ID)
+	require.NoError(t, err)
+	require.Equal(t, offer.ID, restoredOffer.ID)
+
+	restoredPriority, err := restored.GetOfferMoneroLockPriority(offer.ID)
+	require.NoError(t, err)
+	require.Equal(t, types.MoneroPriorityHigh, restoredPriority)
+
+	restoredInfo, err := restored.GetSwap(info.ID)
+	require.NoError(t, err)
+	require.Equal(t, infoAsJSON(t, info), infoAsJSON(t, restoredInfo))
+
+	restoredKey, err := restored.RecoveryDB().GetSwapPrivateKey(info.ID)
+	require.NoError(t, err)
+	require.Equal(t, kp.SpendKey().Hex(), restoredKey.Hex())
+
+	// restoring into a db that isn't empty fails
+	err = Restore(restoredCfg, backupPath)
+	require.ErrorIs(t, err, errRestoreDBNotEmpty)
+}
+
+func TestRestore_invalidBackups(t *testing.T) {
+	dir := t.TempDir()
+	writeBackup := func(b *backup) string {
+		data, err := json.Marshal(b)
+		require.NoError(t, err)
+		p := path.Join(dir, "swapd.backup")
+		require.NoError(t, os.WriteFile(p, data, 0600))
+		return p
+	}
+
+	cfg := &chaindb.Config{DataDir: t.TempDir()}
+
+	p := writeBackup(&backup{SchemaVersion: BackupSchemaVersion + 1})
+	require.ErrorIs(t, Restore(cfg, p), errBackupSchemaVersion)
+
+	p = writeBackup(&backup{
+		SchemaVersion: BackupSchemaVersion,
+		Entries:       []*backupEntry{{Key: []byte("unknown"), Value: []byte("value")}},
+	})
+	require.ErrorIs(t, Restore(cfg, p), errBackupUnknownKey)
+
+	badOfferKey := append([]byte(offerPrefix), make([]byte, idLength)...)
+	p = writeBackup(&backup{
+		SchemaVersion: BackupSchemaVersion,
+		Entries:       []*backupEntry{{Key: badOfferKey, Value: []byte("{}")}},
+	})
+	require.ErrorContains(t, Restore(cfg, p), "invalid offer")
+
+	// none of the invalid backups were written to the db
+	db, err := chaindb.NewBadgerDB(cfg)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+	iter := db.NewIterator()
+	defer iter.Release()
+	require.False(t, iter.Next())
+}
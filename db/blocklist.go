@@ -0,0 +1,53 @@
+package db
+
+import (
+	"github.com/ChainSafe/chaindb"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	blocklistPrefix = "blocklist"
+)
+
+// Blocklist is the persisted set of peers that we refuse to swap with or relay
+// claims for. The key is the peer ID and the value is unused.
+type Blocklist struct {
+	db chaindb.Database
+}
+
+func newBlocklist(db chaindb.Database) *Blocklist {
+	return &Blocklist{
+		db: db,
+	}
+}
+
+func (b *Blocklist) close() error {
+	return b.db.Close()
+}
+
+// Add adds the peer to the blocklist. Adding a peer that is already blocked is
+// not an error.
+func (b *Blocklist) Add(id peer.ID) error {
+	err := b.db.Put([]byte(id), []byte{1})
+	if err != nil {
+		return err
+	}
+
+	return b.db.Flush()
+}
+
+// Remove removes the peer from the blocklist. Removing a peer that isn't
+// blocked is not an error.
+func (b *Blocklist) Remove(id peer.ID) error {
+	err := b.db.Del([]byte(id))
+	if err != nil {
+		return err
+	}
+
+	return b.db.Flush()
+}
+
+// IsBlocked returns whether the peer is on the blocklist.
+func (b *Blocklist) IsBlocked(id peer.ID) (bool, error) {
+	return b.db.Has([]byte(id))
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocklist(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	bl := db.Blocklist()
+	peerA := peer.ID("peerA")
+	peerB := peer.ID("peerB")
+
+	blocked, err := bl.IsBlocked(peerA)
+	require.NoError(t, err)
+	require.False(t, blocked)
+
+	require.NoError(t, bl.Add(peerA))
+	require.NoError(t, bl.Add(peerA)) // adding twice is fine
+
+	blocked, err = bl.IsBlocked(peerA)
+	require.NoError(t, err)
+	require.True(t, blocked)
+
+	blocked, err = bl.IsBlocked(peerB)
+	require.NoError(t, err)
+	require.False(t, blocked)
+
+	require.NoError(t, bl.Remove(peerA))
+	require.NoError(t, bl.Remove(peerB)) // removing an unblocked peer is fine
+
+	blocked, err = bl.IsBlocked(peerA)
+	require.NoError(t, err)
+	require.False(t, blocked)
+}
@@ -3,6 +3,7 @@ package db
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/ChainSafe/chaindb"
 	logging "github.com/ipfs/go-log"
@@ -13,9 +14,11 @@ import (
 )
 
 const (
-	offerPrefix = "offer"
-	swapPrefix  = "swap"
-	idLength    = len(types.Hash{})
+	offerPrefix        = "offer"
+	swapPrefix         = "swap"
+	visibilityPrefix   = "visibility"
+	lockPriorityPrefix = "lockpriority"
+	idLength           = len(types.Hash{})
 )
 
 var (
@@ -24,6 +27,9 @@ var (
 
 // Database is the persistent datastore used by swapd.
 type Database struct {
+	// db is the underlying database, which holds all of the tables below.
+	db chaindb.Database
+
 	// offerTable is a key-value store where all the keys are prefixed by offerPrefix
 	// in the underlying database.
 	// the key is the 32-byte offer ID and the value is a JSON-marshalled *types.Offer.
@@ -31,6 +37,20 @@ type Database struct {
 	// they are removed when the offer is taken.
 	offerTable chaindb.Database
 
+	// visibilityTable is a key-value store where all the keys are prefixed by
+	// visibilityPrefix in the underlying database.
+	// the key is the 32-byte offer ID and the value is a JSON-marshalled
+	// *types.OfferVisibility. entries are only stored for private offers, and
+	// are removed along with their offer.
+	visibilityTable chaindb.Database
+
+	// lockPriorityTable is a key-value store where all the keys are prefixed
+	// by lockPriorityPrefix in the underlying database.
+	// the key is the 32-byte offer ID and the value is the name of the offer's
+	// types.MoneroTxPriority. entries are only stored for offers that override
+	// the maker's XMR lock priority, and are removed along with their offer.
+	lockPriorityTable chaindb.Database
+
 	// swapTable is a key-value store where all the keys are prefixed by swapPrefix
 	// in the underlying database.
 	// the key is the 32-byte swap ID (which is the same as the ID of the offer taken
@@ -43,21 +63,48 @@ type Database struct {
 	// it contains information about ongoing swaps required to recover funds
 	// in case of a node crash, or any other problem.
 	recoveryDB *RecoveryDB
+
+	// blocklist contains a db table prefixed by blocklistPrefix, holding the
+	// peers that we refuse to swap with or relay claims for.
+	blocklist *Blocklist
+
+	// relayerStats contains a db table prefixed by relayerStatsPrefix, holding
+	// how the relayers we submitted claims to performed.
+	relayerStats *RelayerStats
 }
 
 // NewDatabase returns a new *Database.
 func NewDatabase(cfg *chaindb.Config) (*Database, error) {
+	return NewEncryptedDatabase(cfg, nil)
+}
+
+// NewEncryptedDatabase returns a new *Database whose recovery db values, which
+// include swap private keys, are encrypted with a key derived from the passed
+// passphrase. Values of an unencrypted recovery db are encrypted when it's
+// first opened with a passphrase. If the passphrase is empty, the recovery db
+// is not encrypted, and opening one that is fails.
+func NewEncryptedDatabase(cfg *chaindb.Config, passphrase []byte) (*Database, error) {
 	db, err := chaindb.NewBadgerDB(cfg)
 	if err != nil {
 		return nil, err
 	}
 
 	recoveryDB := newRecoveryDB(chaindb.NewTable(db, recoveryPrefix))
+	if err = recoveryDB.setupEncryption(passphrase); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open recovery db: %w", err)
+	}
 
 	return &Database{
+		db:         db,
 		offerTable: chaindb.NewTable(db, offerPrefix),
 		swapTable:  chaindb.NewTable(db, swapPrefix),
 		recoveryDB: recoveryDB,
+		blocklist:  newBlocklist(chaindb.NewTable(db, blocklistPrefix)),
+
+		visibilityTable:   chaindb.NewTable(db, visibilityPrefix),
+		lockPriorityTable: chaindb.NewTable(db, lockPriorityPrefix),
+		relayerStats:      newRelayerStats(chaindb.NewTable(db, relayerStatsPrefix)),
 	}, nil
 }
 
@@ -73,7 +120,27 @@ func (db *Database) Close() error {
 		return err
 	}
 
-	return db.recoveryDB.close()
+	err = db.visibilityTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.lockPriorityTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.recoveryDB.close()
+	if err != nil {
+		return err
+	}
+
+	err = db.blocklist.close()
+	if err != nil {
+		return err
+	}
+
+	return db.relayerStats.close()
 }
 
 // RecoveryDB ...
@@ -81,6 +148,16 @@ func (db *Database) RecoveryDB() *RecoveryDB {
 	return db.recoveryDB
 }
 
+// Blocklist returns the persisted peer blocklist.
+func (db *Database) Blocklist() *Blocklist {
+	return db.blocklist
+}
+
+// RelayerStats returns the persisted record of how relayers performed.
+func (db *Database) RelayerStats() *RelayerStats {
+	return db.relayerStats
+}
+
 // PutOffer puts an offer in the database.
 func (db *Database) PutOffer(offer *types.Offer) error {
 	val, err := vjson.MarshalStruct(offer)
@@ -97,11 +174,95 @@ func (db *Database) PutOffer(offer *types.Offer) error {
 	return db.offerTable.Flush()
 }
 
-// DeleteOffer deletes an offer from the database.
+// DeleteOffer deletes an offer, and its visibility and XMR lock priority, from
+// the database.
 func (db *Database) DeleteOffer(id types.Hash) error {
+	for _, table := range []chaindb.Database{db.visibilityTable, db.lockPriorityTable} {
+		if err := table.Del(id[:]); err != nil && !errors.Is(err, chaindb.ErrKeyNotFound) {
+			return err
+		}
+	}
+
 	return db.offerTable.Del(id[:])
 }
 
+// PutOfferVisibility puts the visibility of a private offer in the database.
+func (db *Database) PutOfferVisibility(id types.Hash, v *types.OfferVisibility) error {
+	val, err := vjson.MarshalStruct(v)
+	if err != nil {
+		return err
+	}
+
+	err = db.visibilityTable.Put(id[:], val)
+	if err != nil {
+		return err
+	}
+
+	return db.visibilityTable.Flush()
+}
+
+// GetOfferVisibility returns the visibility of the offer with the passed ID.
+// Offers without a stored visibility are public.
+func (db *Database) GetOfferVisibility(id types.Hash) (*types.OfferVisibility, error) {
+	val, err := db.visibilityTable.Get(id[:])
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return new(types.OfferVisibility), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v := new(types.OfferVisibility)
+	if err = vjson.UnmarshalStruct(val, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// PutOfferMoneroLockPriority puts the XMR lock priority of an offer in the
+// database. Setting the default priority removes the stored one.
+func (db *Database) PutOfferMoneroLockPriority(id types.Hash, priority types.MoneroTxPriority) error {
+	if priority == types.MoneroPriorityDefault {
+		err := db.lockPriorityTable.Del(id[:])
+		if err != nil && !errors.Is(err, chaindb.ErrKeyNotFound) {
+			return err
+		}
+		return nil
+	}
+
+	val, err := priority.MarshalText()
+	if err != nil {
+		return err
+	}
+
+	err = db.lockPriorityTable.Put(id[:], val)
+	if err != nil {
+		return err
+	}
+
+	return db.lockPriorityTable.Flush()
+}
+
+// GetOfferMoneroLockPriority returns the XMR lock priority of the offer with
+// the passed ID. Offers without a stored priority use the default priority.
+func (db *Database) GetOfferMoneroLockPriority(id types.Hash) (types.MoneroTxPriority, error) {
+	val, err := db.lockPriorityTable.Get(id[:])
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return types.MoneroPriorityDefault, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var priority types.MoneroTxPriority
+	if err = priority.UnmarshalText(val); err != nil {
+		return 0, err
+	}
+
+	return priority, nil
+}
+
 // GetOffer returns the given offer from the db, if it exists. Returns
 // the error chaindb.ErrKeyNotFound if the entry does not exist.
 func (db *Database) GetOffer(id types.Hash) (*types.Offer, error) {
@@ -110,7 +271,7 @@ func (db *Database) GetOffer(id types.Hash) (*types.Offer, error) {
 		return nil, err
 	}
 
-	return types.UnmarshalOffer(val)
+	return types.UnmarshalOffer(val, nil)
 }
 
 // purgeInvalidOffer purges an offer after its JSON entry failed to decode when GetAllOffers
@@ -151,7 +312,7 @@ func (db *Database) GetAllOffers() ([]*types.Offer, error) {
 		}
 
 		encodedOffer := iter.Value()
-		offer, err := types.UnmarshalOffer(encodedOffer)
+		offer, err := types.UnmarshalOffer(encodedOffer, nil)
 		if err != nil {
 			// Assuming logging and purging succeeds, don't propagate the error up,
 			// so swapd can continue running.
@@ -167,14 +328,25 @@ func (db *Database) GetAllOffers() ([]*types.Offer, error) {
 	return offers, nil
 }
 
-// ClearAllOffers clears all offers from the database.
+// ClearAllOffers clears all offers, and their visibilities and XMR lock
+// priorities, from the database.
 func (db *Database) ClearAllOffers() error {
-	iter := db.offerTable.NewIterator()
+	for _, table := range []chaindb.Database{db.offerTable, db.visibilityTable, db.lockPriorityTable} {
+		if err := clearTable(table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clearTable deletes all the entries of the table.
+func clearTable(table chaindb.Database) error {
+	iter := table.NewIterator()
 	defer iter.Release()
 
 	for iter.Valid() {
-		offerID := iter.Key()
-		err := db.offerTable.Del(offerID)
+		err := table.Del(iter.Key())
 		if err != nil {
 			return err
 		}
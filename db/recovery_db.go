@@ -15,12 +15,15 @@ const (
 	counterpartySwapPrivateKeyPrefix = "cspriv"
 	relayerInfoPrefix                = "relayer"
 	counterpartySwapKeysPrefix       = "cskeys"
+	xmrLockStartedPrefix             = "xmrlock"
 )
 
 // RecoveryDB contains information about ongoing swaps required for recovery
-// in case of shutdown.
+// in case of shutdown. If it was opened with a passphrase, its values are
+// encrypted at rest.
 type RecoveryDB struct {
-	db chaindb.Database
+	db     chaindb.Database
+	cipher *recoveryCipher // nil if the db isn't encrypted
 }
 
 func newRecoveryDB(db chaindb.Database) *RecoveryDB {
@@ -45,7 +48,7 @@ func (db *RecoveryDB) PutSwapRelayerInfo(id types.Hash, info *types.OfferExtra)
 	}
 
 	key := getRecoveryDBKey(id, relayerInfoPrefix)
-	err = db.db.Put(key, val)
+	err = db.put(key, val)
 	if err != nil {
 		return err
 	}
@@ -56,7 +59,7 @@ func (db *RecoveryDB) PutSwapRelayerInfo(id types.Hash, info *types.OfferExtra)
 // GetSwapRelayerInfo ...
 func (db *RecoveryDB) GetSwapRelayerInfo(id types.Hash) (*types.OfferExtra, error) {
 	key := getRecoveryDBKey(id, relayerInfoPrefix)
-	value, err := db.db.Get(key)
+	value, err := db.get(key)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +83,7 @@ func (db *RecoveryDB) PutContractSwapInfo(id types.Hash, info *EthereumSwapInfo)
 	}
 
 	key := getRecoveryDBKey(id, contractSwapInfoPrefix)
-	err = db.db.Put(key, val)
+	err = db.put(key, val)
 	if err != nil {
 		return err
 	}
@@ -92,7 +95,7 @@ func (db *RecoveryDB) PutContractSwapInfo(id types.Hash, info *EthereumSwapInfo)
 // and contract swap structure for the given swap ID.
 func (db *RecoveryDB) GetContractSwapInfo(id types.Hash) (*EthereumSwapInfo, error) {
 	key := getRecoveryDBKey(id, contractSwapInfoPrefix)
-	value, err := db.db.Get(key)
+	value, err := db.get(key)
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +117,7 @@ func (db *RecoveryDB) PutSwapPrivateKey(id types.Hash, sk *mcrypto.PrivateSpendK
 	}
 
 	key := getRecoveryDBKey(id, swapPrivateKeyPrefix)
-	err = db.db.Put(key, val)
+	err = db.put(key, val)
 	if err != nil {
 		return err
 	}
@@ -125,7 +128,7 @@ func (db *RecoveryDB) PutSwapPrivateKey(id types.Hash, sk *mcrypto.PrivateSpendK
 // GetSwapPrivateKey returns the swap private key share, if it exists.
 func (db *RecoveryDB) GetSwapPrivateKey(id types.Hash) (*mcrypto.PrivateSpendKey, error) {
 	key := getRecoveryDBKey(id, swapPrivateKeyPrefix)
-	value, err := db.db.Get(key[:])
+	value, err := db.get(key)
 	if err != nil {
 		return nil, err
 	}
@@ -147,7 +150,7 @@ func (db *RecoveryDB) PutCounterpartySwapPrivateKey(id types.Hash, kp *mcrypto.P
 	}
 
 	key := getRecoveryDBKey(id, counterpartySwapPrivateKeyPrefix)
-	err = db.db.Put(key, val)
+	err = db.put(key, val)
 	if err != nil {
 		return err
 	}
@@ -158,7 +161,7 @@ func (db *RecoveryDB) PutCounterpartySwapPrivateKey(id types.Hash, kp *mcrypto.P
 // GetCounterpartySwapPrivateKey returns the counterparty's swap private key, if it exists.
 func (db *RecoveryDB) GetCounterpartySwapPrivateKey(id types.Hash) (*mcrypto.PrivateSpendKey, error) {
 	key := getRecoveryDBKey(id, counterpartySwapPrivateKeyPrefix)
-	value, err := db.db.Get(key[:])
+	value, err := db.get(key)
 	if err != nil {
 		return nil, err
 	}
@@ -189,7 +192,7 @@ func (db *RecoveryDB) PutCounterpartySwapKeys(id types.Hash, sk *mcrypto.PublicK
 
 	key := getRecoveryDBKey(id, counterpartySwapKeysPrefix)
 	log.Debugf("PutCounterpartySwapKeys %s", key)
-	err = db.db.Put(key, val)
+	err = db.put(key, val)
 	if err != nil {
 		return err
 	}
@@ -201,7 +204,7 @@ func (db *RecoveryDB) PutCounterpartySwapKeys(id types.Hash, sk *mcrypto.PublicK
 // GetCounterpartySwapKeys is called during recovery to retrieve the counterparty's swap keys.
 func (db *RecoveryDB) GetCounterpartySwapKeys(id types.Hash) (*mcrypto.PublicKey, *mcrypto.PrivateViewKey, error) {
 	key := getRecoveryDBKey(id, counterpartySwapKeysPrefix)
-	value, err := db.db.Get(key)
+	value, err := db.get(key)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -215,6 +218,25 @@ func (db *RecoveryDB) GetCounterpartySwapKeys(id types.Hash) (*mcrypto.PublicKey
 	return info.PublicSpendKey, info.PrivateViewKey, nil
 }
 
+// PutXMRLockStarted records that we are about to transfer XMR into the swap's
+// shared wallet. It is written before the transfer is sent, so if it exists on
+// recovery, the XMR may or may not have been locked.
+func (db *RecoveryDB) PutXMRLockStarted(id types.Hash) error {
+	key := getRecoveryDBKey(id, xmrLockStartedPrefix)
+	err := db.put(key, []byte{1})
+	if err != nil {
+		return err
+	}
+
+	return db.db.Flush()
+}
+
+// HasXMRLockStarted returns whether PutXMRLockStarted was called for the given swap ID.
+func (db *RecoveryDB) HasXMRLockStarted(id types.Hash) (bool, error) {
+	key := getRecoveryDBKey(id, xmrLockStartedPrefix)
+	return db.db.Has(key)
+}
+
 // DeleteSwap deletes all recovery info from the db for the given swap.
 // TODO: this is currently unimplemented
 func (db *RecoveryDB) DeleteSwap(id types.Hash) error {
@@ -229,6 +251,7 @@ func (db *RecoveryDB) deleteSwap(id types.Hash) error {
 		getRecoveryDBKey(id, swapPrivateKeyPrefix),
 		getRecoveryDBKey(id, counterpartySwapPrivateKeyPrefix),
 		getRecoveryDBKey(id, counterpartySwapKeysPrefix),
+		getRecoveryDBKey(id, xmrLockStartedPrefix),
 	}
 
 	for _, key := range keys {
@@ -138,6 +138,22 @@ func TestRecoveryDB_CounterpartySwapKeys(t *testing.T) {
 	require.Equal(t, kp.ViewKey().String(), resVk.String())
 }
 
+func TestRecoveryDB_XMRLockStarted(t *testing.T) {
+	rdb := newTestRecoveryDB(t)
+	offerID := types.Hash{5, 6, 7, 8}
+
+	started, err := rdb.HasXMRLockStarted(offerID)
+	require.NoError(t, err)
+	require.False(t, started)
+
+	err = rdb.PutXMRLockStarted(offerID)
+	require.NoError(t, err)
+
+	started, err = rdb.HasXMRLockStarted(offerID)
+	require.NoError(t, err)
+	require.True(t, started)
+}
+
 func TestRecoveryDB_DeleteSwap(t *testing.T) {
 	rdb := newTestRecoveryDB(t)
 	offerID := types.Hash{5, 6, 7, 8}
@@ -0,0 +1,252 @@
+package db
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+const (
+	// encryptionInfoKey is where the salt and passphrase check of an encrypted
+	// recovery db are stored. It can't collide with the keys of swap values,
+	// which start with the 32-byte swap ID.
+	encryptionInfoKey = "encryption"
+
+	// scrypt parameters recommended for interactive logins
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = chacha20poly1305.KeySize
+	saltLen      = 32
+)
+
+var (
+	// encryptedValueMagic prefixes every encrypted value, so that values can be
+	// told apart from the plaintext JSON values of unencrypted dbs.
+	encryptedValueMagic = []byte("\xffenc1")
+
+	// passphraseCheck is encrypted when encryption is enabled, so that a wrong
+	// passphrase is detected when the db is opened, instead of on first read.
+	passphraseCheck = []byte("atomic-swap recovery db")
+
+	errRecoveryDBEncrypted = errors.New("recovery db is encrypted, a passphrase is required to open it")
+	errWrongPassphrase     = errors.New("wrong recovery db passphrase")
+	errValueNotEncrypted   = errors.New("recovery db value is not encrypted")
+	errValueEncrypted      = errors.New("recovery db value is encrypted")
+	errValueTooShort       = errors.New("encrypted recovery db value is too short")
+)
+
+type encryptionInfo struct {
+	Salt  []byte `json:"salt" validate:"required"`
+	Check []byte `json:"check" validate:"required"`
+}
+
+// recoveryCipher encrypts and decrypts recovery db values with XChaCha20-Poly1305,
+// using a key derived from the user's passphrase with scrypt. The db key of each
+// value is authenticated as well, so encrypted values can't be swapped around.
+type recoveryCipher struct {
+	aead cipher.AEAD
+}
+
+func newRecoveryCipher(passphrase []byte, salt []byte) (*recoveryCipher, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recoveryCipher{aead: aead}, nil
+}
+
+func (c *recoveryCipher) seal(key []byte, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, encryptedValueMagic...)
+	out = append(out, nonce...)
+	return c.aead.Seal(out, nonce, plaintext, key), nil
+}
+
+func (c *recoveryCipher) open(key []byte, value []byte) ([]byte, error) {
+	if !isEncryptedValue(value) {
+		return nil, errValueNotEncrypted
+	}
+
+	value = value[len(encryptedValueMagic):]
+	if len(value) < c.aead.NonceSize()+c.aead.Overhead() {
+		return nil, errValueTooShort
+	}
+
+	nonce, ciphertext := value[:c.aead.NonceSize()], value[c.aead.NonceSize():]
+	return c.aead.Open(nil, nonce, ciphertext, key)
+}
+
+func isEncryptedValue(value []byte) bool {
+	return bytes.HasPrefix(value, encryptedValueMagic)
+}
+
+// setupEncryption is called when the recovery db is opened. With a passphrase,
+// it enables encryption if the db isn't encrypted yet, checks the passphrase,
+// and encrypts any values that are still in plaintext. Without one, it fails
+// if the db is encrypted.
+func (db *RecoveryDB) setupEncryption(passphrase []byte) error {
+	infoKey := []byte(encryptionInfoKey)
+	has, err := db.db.Has(infoKey)
+	if err != nil {
+		return err
+	}
+
+	if len(passphrase) == 0 {
+		if has {
+			return errRecoveryDBEncrypted
+		}
+		return nil
+	}
+
+	var info encryptionInfo
+	if has {
+		var value []byte
+		if value, err = db.db.Get(infoKey); err != nil {
+			return err
+		}
+		if err = vjson.UnmarshalStruct(value, &info); err != nil {
+			return err
+		}
+	} else {
+		info.Salt = make([]byte, saltLen)
+		if _, err = rand.Read(info.Salt); err != nil {
+			return err
+		}
+	}
+
+	c, err := newRecoveryCipher(passphrase, info.Salt)
+	if err != nil {
+		return err
+	}
+
+	if has {
+		if _, err = c.open(infoKey, info.Check); err != nil {
+			return errWrongPassphrase
+		}
+	} else {
+		log.Infof("enabling recovery db encryption")
+		info.Check, err = c.seal(infoKey, passphraseCheck)
+		if err != nil {
+			return err
+		}
+
+		var value []byte
+		if value, err = vjson.MarshalStruct(&info); err != nil {
+			return err
+		}
+		if err = db.db.Put(infoKey, value); err != nil {
+			return err
+		}
+	}
+
+	db.cipher = c
+
+	// The encryption info is written first, so if we exit while migrating, the
+	// remaining plaintext values are encrypted the next time the db is opened.
+	return db.encryptPlaintextValues()
+}
+
+// encryptPlaintextValues encrypts the values of unencrypted dbs in place.
+func (db *RecoveryDB) encryptPlaintextValues() error {
+	iter := db.db.NewIterator()
+	defer iter.Release()
+
+	type entry struct {
+		key   []byte
+		value []byte
+	}
+	var plaintext []entry
+
+	for iter.Valid() {
+		key := iter.Key()
+		if isRecoveryDBKey(key) && !isEncryptedValue(iter.Value()) {
+			plaintext = append(plaintext, entry{
+				key:   append([]byte{}, key...),
+				value: append([]byte{}, iter.Value()...),
+			})
+		}
+		iter.Next()
+	}
+
+	if len(plaintext) == 0 {
+		return nil
+	}
+
+	for _, e := range plaintext {
+		if err := db.put(e.key, e.value); err != nil {
+			return fmt.Errorf("failed to encrypt recovery db value: %w", err)
+		}
+	}
+
+	log.Infof("encrypted %d existing recovery db values", len(plaintext))
+	return db.db.Flush()
+}
+
+// isRecoveryDBKey returns whether the key is one of the per-swap keys created
+// by getRecoveryDBKey.
+func isRecoveryDBKey(key []byte) bool {
+	if len(key) <= idLength {
+		return false
+	}
+
+	switch string(key[idLength:]) {
+	case relayerInfoPrefix,
+		contractSwapInfoPrefix,
+		swapPrivateKeyPrefix,
+		counterpartySwapPrivateKeyPrefix,
+		counterpartySwapKeysPrefix,
+		xmrLockStartedPrefix:
+		return true
+	default:
+		return false
+	}
+}
+
+// put stores the value under the key, encrypting it if encryption is enabled.
+func (db *RecoveryDB) put(key []byte, value []byte) error {
+	if db.cipher != nil {
+		var err error
+		value, err = db.cipher.seal(key, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.db.Put(key, value)
+}
+
+// get returns the value stored under the key, decrypting it if encryption is
+// enabled.
+func (db *RecoveryDB) get(key []byte) ([]byte, error) {
+	value, err := db.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if db.cipher == nil {
+		if isEncryptedValue(value) {
+			return nil, errValueEncrypted
+		}
+		return value, nil
+	}
+
+	return db.cipher.open(key, value)
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+)
+
+func TestRecoveryDB_encryption(t *testing.T) {
+	cfg := &chaindb.Config{DataDir: t.TempDir()}
+	passphrase := []byte("correct horse battery staple")
+	offerID := types.Hash{5, 6, 7, 8}
+
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+
+	// store a key in an unencrypted db
+	sdb, err := NewDatabase(cfg)
+	require.NoError(t, err)
+	require.NoError(t, sdb.RecoveryDB().PutCounterpartySwapPrivateKey(offerID, kp.SpendKey()))
+	require.NoError(t, sdb.Close())
+
+	// opening it with a passphrase encrypts the existing value
+	sdb, err = NewEncryptedDatabase(cfg, passphrase)
+	require.NoError(t, err)
+	rdb := sdb.RecoveryDB()
+	raw, err := rdb.db.Get(getRecoveryDBKey(offerID, counterpartySwapPrivateKeyPrefix))
+	require.NoError(t, err)
+	require.True(t, isEncryptedValue(raw))
+	require.NotContains(t, string(raw), kp.SpendKey().Hex())
+
+	res, err := rdb.GetCounterpartySwapPrivateKey(offerID)
+	require.NoError(t, err)
+	require.Equal(t, kp.SpendKey().String(), res.String())
+
+	require.NoError(t, rdb.PutXMRLockStarted(offerID))
+	require.NoError(t, sdb.Close())
+
+	// the db can't be opened without the right passphrase
+	_, err = NewDatabase(cfg)
+	require.ErrorIs(t, err, errRecoveryDBEncrypted)
+	_, err = NewEncryptedDatabase(cfg, []byte("wrong"))
+	require.ErrorIs(t, err, errWrongPassphrase)
+
+	sdb, err = NewEncryptedDatabase(cfg, passphrase)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sdb.Close()) }()
+
+	res, err = sdb.RecoveryDB().GetCounterpartySwapPrivateKey(offerID)
+	require.NoError(t, err)
+	require.Equal(t, kp.SpendKey().String(), res.String())
+
+	started, err := sdb.RecoveryDB().HasXMRLockStarted(offerID)
+	require.NoError(t, err)
+	require.True(t, started)
+}
+
+func TestRecoveryCipher_keyIsAuthenticated(t *testing.T) {
+	c, err := newRecoveryCipher([]byte("passphrase"), make([]byte, saltLen))
+	require.NoError(t, err)
+
+	sealed, err := c.seal([]byte("key1"), []byte("value"))
+	require.NoError(t, err)
+
+	value, err := c.open([]byte("key1"), sealed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), value)
+
+	_, err = c.open([]byte("key2"), sealed)
+	require.Error(t, err)
+
+	_, err = c.open([]byte("key1"), []byte(`{"plain":"json"}`))
+	require.ErrorIs(t, err, errValueNotEncrypted)
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+const (
+	relayerStatsPrefix = "relayerstats"
+)
+
+// RelayerStats is the persisted record of how the relayers we submitted claims
+// to performed. The key is the peer ID and the value is a JSON-marshalled
+// *types.RelayerClaimStats.
+type RelayerStats struct {
+	db chaindb.Database
+}
+
+func newRelayerStats(db chaindb.Database) *RelayerStats {
+	return &RelayerStats{
+		db: db,
+	}
+}
+
+func (s *RelayerStats) close() error {
+	return s.db.Close()
+}
+
+// RecordSuccess counts a claim that the relayer submitted and was mined.
+func (s *RelayerStats) RecordSuccess(id peer.ID) error {
+	return s.update(id, func(stats *types.RelayerClaimStats) {
+		stats.Succeeded++
+	})
+}
+
+// RecordFailure counts a claim that the relayer failed to submit.
+func (s *RelayerStats) RecordFailure(id peer.ID) error {
+	return s.update(id, func(stats *types.RelayerClaimStats) {
+		stats.Failed++
+	})
+}
+
+func (s *RelayerStats) update(id peer.ID, updateFn func(*types.RelayerClaimStats)) error {
+	stats, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	updateFn(stats)
+
+	val, err := vjson.MarshalStruct(stats)
+	if err != nil {
+		return err
+	}
+
+	if err = s.db.Put([]byte(id), val); err != nil {
+		return err
+	}
+
+	return s.db.Flush()
+}
+
+// Get returns the relayer's stats, which are zero if we never submitted a
+// claim to it.
+func (s *RelayerStats) Get(id peer.ID) (*types.RelayerClaimStats, error) {
+	val, err := s.db.Get([]byte(id))
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return &types.RelayerClaimStats{PeerID: id}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stats := new(types.RelayerClaimStats)
+	if err = vjson.UnmarshalStruct(val, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetAll returns the stats of every relayer we submitted a claim to.
+func (s *RelayerStats) GetAll() ([]*types.RelayerClaimStats, error) {
+	iter := s.db.NewIterator()
+	defer iter.Release()
+
+	var all []*types.RelayerClaimStats
+	for iter.Valid() {
+		// if the entry isn't keyed by its peer ID, we're not iterating over
+		// relayer stats
+		stats := new(types.RelayerClaimStats)
+		err := vjson.UnmarshalStruct(iter.Value(), stats)
+		if err != nil || stats.PeerID != peer.ID(iter.Key()) {
+			break
+		}
+
+		all = append(all, stats)
+		iter.Next()
+	}
+
+	return all, nil
+}
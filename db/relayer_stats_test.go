@@ -0,0 +1,45 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestRelayerStats(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	rs := db.RelayerStats()
+	relayerA := peer.ID("relayerA")
+	relayerB := peer.ID("relayerB")
+
+	stats, err := rs.Get(relayerA)
+	require.NoError(t, err)
+	require.Equal(t, &types.RelayerClaimStats{PeerID: relayerA}, stats)
+
+	require.NoError(t, rs.RecordSuccess(relayerA))
+	require.NoError(t, rs.RecordSuccess(relayerA))
+	require.NoError(t, rs.RecordFailure(relayerA))
+	require.NoError(t, rs.RecordFailure(relayerB))
+
+	stats, err = rs.Get(relayerA)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), stats.Succeeded)
+	require.Equal(t, uint64(1), stats.Failed)
+
+	all, err := rs.GetAll()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*types.RelayerClaimStats{
+		{PeerID: relayerA, Succeeded: 2, Failed: 1},
+		{PeerID: relayerB, Failed: 1},
+	}, all)
+}
@@ -19,6 +19,7 @@ type Interface interface {
 
 // Proof represents a DLEq proof
 type Proof struct {
+	scheme ProofScheme
 	secret [32]byte
 	proof  []byte
 }
@@ -37,6 +38,17 @@ func (p *Proof) Secret() [32]byte {
 	return s
 }
 
+// ZeroSecret overwrites the proof's secret with zeros, for proofs that are
+// discarded without being used.
+func (p *Proof) ZeroSecret() {
+	p.secret = [32]byte{}
+}
+
+// Scheme returns the scheme the proof was generated with
+func (p *Proof) Scheme() ProofScheme {
+	return p.scheme
+}
+
 // Proof returns the encoded DLEq proof
 func (p *Proof) Proof() []byte {
 	return p.proof
@@ -42,6 +42,7 @@ func (d *GoDLEq) Prove() (*Proof, error) {
 	}
 
 	return &Proof{
+		scheme: ProofSchemeSecp256k1Ed25519,
 		proof:  proof.Serialize(),
 		secret: x,
 	}, nil
@@ -0,0 +1,75 @@
+package dleq
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProofScheme identifies the curves and construction of a DLEq proof, so that
+// the verifier of a proof knows how to verify it.
+type ProofScheme uint8
+
+// Supported DLEq proof schemes. The zero value is the scheme that swaps have
+// always used, so peers that don't send a scheme are treated as using it.
+const (
+	// ProofSchemeSecp256k1Ed25519 is a go-dleq proof between the secp256k1
+	// and ed25519 curves.
+	ProofSchemeSecp256k1Ed25519 ProofScheme = 0
+
+	// DefaultProofScheme is the scheme of the proofs we generate.
+	DefaultProofScheme = ProofSchemeSecp256k1Ed25519
+)
+
+var errUnknownProofScheme = errors.New("unknown DLEq proof scheme")
+
+// NewProofScheme parses the name of a proof scheme. An empty string is the
+// default scheme.
+func NewProofScheme(name string) (ProofScheme, error) {
+	switch name {
+	case "", "secp256k1-ed25519":
+		return ProofSchemeSecp256k1Ed25519, nil
+	default:
+		return 0, fmt.Errorf("%w %q", errUnknownProofScheme, name)
+	}
+}
+
+// New returns the prover and verifier of the passed scheme, or an error if the
+// scheme isn't supported.
+func New(scheme ProofScheme) (Interface, error) {
+	switch scheme {
+	case ProofSchemeSecp256k1Ed25519:
+		return &GoDLEq{}, nil
+	default:
+		return nil, fmt.Errorf("%w %s", errUnknownProofScheme, scheme)
+	}
+}
+
+// String returns the name of the scheme
+func (s ProofScheme) String() string {
+	switch s {
+	case ProofSchemeSecp256k1Ed25519:
+		return "secp256k1-ed25519"
+	default:
+		return fmt.Sprintf("ProofScheme(%d)", uint8(s))
+	}
+}
+
+// MarshalText hands off JSON encoding to the scheme name
+func (s ProofScheme) MarshalText() ([]byte, error) {
+	if _, err := New(s); err != nil {
+		return nil, err
+	}
+
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText hands off JSON decoding to NewProofScheme
+func (s *ProofScheme) UnmarshalText(data []byte) error {
+	scheme, err := NewProofScheme(string(data))
+	if err != nil {
+		return err
+	}
+
+	*s = scheme
+	return nil
+}
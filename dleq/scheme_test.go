@@ -0,0 +1,43 @@
+package dleq
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProofScheme_JSON(t *testing.T) {
+	type msg struct {
+		Scheme ProofScheme `json:"scheme,omitempty"`
+	}
+
+	data, err := json.Marshal(&msg{Scheme: DefaultProofScheme})
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(data))
+
+	decoded := new(msg)
+	require.NoError(t, json.Unmarshal([]byte(`{"scheme":"secp256k1-ed25519"}`), decoded))
+	require.Equal(t, ProofSchemeSecp256k1Ed25519, decoded.Scheme)
+
+	err = json.Unmarshal([]byte(`{"scheme":"bls12-381-ed25519"}`), decoded)
+	require.ErrorIs(t, err, errUnknownProofScheme)
+
+	_, err = json.Marshal(&msg{Scheme: ProofScheme(7)})
+	require.ErrorIs(t, err, errUnknownProofScheme)
+}
+
+func TestNew(t *testing.T) {
+	d, err := New(DefaultProofScheme)
+	require.NoError(t, err)
+
+	proof, err := d.Prove()
+	require.NoError(t, err)
+	require.Equal(t, DefaultProofScheme, proof.Scheme())
+
+	_, err = d.Verify(NewProofWithoutSecret(proof.Proof()))
+	require.NoError(t, err)
+
+	_, err = New(ProofScheme(7))
+	require.ErrorIs(t, err, errUnknownProofScheme)
+}
@@ -0,0 +1,128 @@
+package block
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+var (
+	confirmationsSleepDuration = time.Second * 2
+
+	// ErrTxReorged is returned when a mined transaction is no longer in the
+	// canonical chain after a reorg.
+	ErrTxReorged = errors.New("transaction was removed from the chain by a reorg")
+)
+
+// WaitForConfirmations waits until the transaction has been mined with the
+// passed number of confirmations, counting the block that includes it, and
+// returns its receipt. If a reorg moves the transaction into another block, the
+// confirmations are counted from the new block. If a reorg removes the
+// transaction from the chain, ErrTxReorged is returned. Zero or one
+// confirmations return as soon as the transaction is mined.
+func WaitForConfirmations(
+	ctx context.Context,
+	ec *ethclient.Client,
+	txHash ethcommon.Hash,
+	confirmations uint64,
+) (*ethtypes.Receipt, error) {
+	receipt, err := WaitForReceipt(ctx, ec, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmations <= 1 {
+		return receipt, nil
+	}
+
+	// the receipt's block is the first confirmation
+	depth := new(big.Int).SetUint64(confirmations - 1)
+	for {
+		head, err := ec.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if head.Number.Cmp(new(big.Int).Add(receipt.BlockNumber, depth)) < 0 {
+			log.Debugf("waiting for transaction %s to have %d confirmations, head is at block %d",
+				txHash, confirmations, head.Number)
+			if err = common.SleepWithContext(ctx, confirmationsSleepDuration); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		latest, err := canonicalReceipt(ctx, ec, txHash)
+		if err != nil {
+			return nil, err
+		}
+
+		if latest.BlockHash == receipt.BlockHash {
+			log.Infof("transaction %s has %d confirmations", txHash, confirmations)
+			return latest, nil
+		}
+
+		log.Warnf("transaction %s was moved to block %d by a reorg", txHash, latest.BlockNumber)
+		receipt = latest
+	}
+}
+
+// WaitForDepth waits until the chain's head has the passed number of
+// confirmations on top of the passed block, counting the block itself. It's
+// used when only the block of a transaction is known, so it can't tell whether
+// the transaction is still in the chain; callers should check its effects.
+func WaitForDepth(ctx context.Context, ec *ethclient.Client, blockNum *big.Int, confirmations uint64) error {
+	if confirmations <= 1 {
+		return nil
+	}
+
+	target := new(big.Int).Add(blockNum, new(big.Int).SetUint64(confirmations-1))
+	for {
+		head, err := ec.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		if head.Number.Cmp(target) >= 0 {
+			return nil
+		}
+
+		log.Debugf("waiting for block %s to have %d confirmations, head is at block %d",
+			blockNum, confirmations, head.Number)
+		if err = common.SleepWithContext(ctx, confirmationsSleepDuration); err != nil {
+			return err
+		}
+	}
+}
+
+// canonicalReceipt returns the receipt of the transaction, checking that the
+// block it references is still part of the canonical chain.
+func canonicalReceipt(ctx context.Context, ec *ethclient.Client, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
+	receipt, err := ec.TransactionReceipt(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, fmt.Errorf("%w: tx=%s", ErrTxReorged, txHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := ec.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Hash() != receipt.BlockHash {
+		return nil, fmt.Errorf("%w: tx=%s", ErrTxReorged, txHash)
+	}
+
+	return receipt, nil
+}
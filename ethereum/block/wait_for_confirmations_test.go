@@ -0,0 +1,41 @@
+package block
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForConfirmations(t *testing.T) {
+	checker := createStampChecker(t)
+	auth, err := bind.NewKeyedTransactorWithChainID(checker.fromKey, checker.chainID)
+	require.NoError(t, err)
+
+	futureTime := time.Now().Add(time.Hour).Unix()
+	tx, err := checker.contract.CheckStamp(auth, big.NewInt(futureTime))
+	require.NoError(t, err)
+
+	const confirmations = 3
+	receipt, err := WaitForConfirmations(checker.ctx, checker.ec, tx.Hash(), confirmations)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t,
+		checker.curBlockHeader().Number.Uint64(),
+		receipt.BlockNumber.Uint64()+confirmations-1,
+	)
+}
+
+func TestWaitForDepth(t *testing.T) {
+	checker := createStampChecker(t)
+	start := checker.curBlockHeader().Number
+
+	const confirmations = 2
+	err := WaitForDepth(checker.ctx, checker.ec, start, confirmations)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t,
+		checker.curBlockHeader().Number.Uint64(),
+		start.Uint64()+confirmations-1,
+	)
+}
@@ -32,6 +32,13 @@ var (
 	errInvalidForwarderContract = errors.New("given contract address does not contain correct Forwarder code")
 )
 
+// IsInvalidContractCode returns whether the error of CheckSwapFactoryContractCode
+// or CheckForwarderContractCode is due to the contract's bytecode not matching
+// ours, rather than a failure to read it.
+func IsInvalidContractCode(err error) bool {
+	return errors.Is(err, errInvalidSwapContract) || errors.Is(err, errInvalidForwarderContract)
+}
+
 // CheckSwapFactoryContractCode checks that the bytecode at the given address matches the
 // SwapFactory.sol contract. The trusted forwarder address that the contract was deployed
 // with is parsed out from the byte code and returned.
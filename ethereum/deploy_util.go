@@ -52,6 +52,43 @@ func DeploySwapFactoryWithKey(
 	return address, sf, nil
 }
 
+// DeployAndVerifySwapFactory deploys the SwapFactory contract using auth to pay for
+// the gas, waits for the deployment to be mined, and then checks that the deployed
+// bytecode is the expected SwapFactory code trusting forwarderAddr. This catches
+// deployments to chains whose EVM doesn't behave as expected. The address of the
+// contract is only returned if the check passes.
+func DeployAndVerifySwapFactory(
+	ctx context.Context,
+	ec *ethclient.Client,
+	auth *bind.TransactOpts,
+	forwarderAddr ethcommon.Address,
+) (ethcommon.Address, error) {
+	address, tx, _, err := DeploySwapFactory(auth, ec, forwarderAddr)
+	if err != nil {
+		return ethcommon.Address{}, fmt.Errorf("failed to deploy swap factory: %w", err)
+	}
+
+	_, err = block.WaitForReceipt(ctx, ec, tx.Hash())
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	deployedForwarder, err := CheckSwapFactoryContractCode(ctx, ec, address)
+	if err != nil {
+		return ethcommon.Address{}, fmt.Errorf("swap factory deployed at %s (tx %s) failed verification: %w",
+			address, tx.Hash(), err)
+	}
+
+	if deployedForwarder != forwarderAddr {
+		return ethcommon.Address{}, fmt.Errorf("swap factory deployed at %s trusts forwarder %s, expected %s",
+			address, deployedForwarder, forwarderAddr)
+	}
+
+	log.Infof("deployed and verified SwapFactory.sol: address=%s tx hash=%s", address, tx.Hash())
+
+	return address, nil
+}
+
 // DeployGSNForwarderWithKey deploys and registers the GSN forwarder using the passed
 // private key to pay the gas fees.
 func DeployGSNForwarderWithKey(
@@ -33,3 +33,20 @@ func Test_registerDomainSeparatorIfNeeded(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, isRegistered)
 }
+
+func TestDeployAndVerifySwapFactory(t *testing.T) {
+	ec, _ := tests.NewEthClient(t)
+	ctx := context.Background()
+	privKey := tests.GetMakerTestKey(t)
+	forwarderAddr := deployForwarder(t, ec, privKey)
+
+	txOpts, err := newTXOpts(ctx, ec, privKey)
+	require.NoError(t, err)
+
+	sfAddr, err := DeployAndVerifySwapFactory(ctx, ec, txOpts, forwarderAddr)
+	require.NoError(t, err)
+
+	parsedForwarderAddr, err := CheckSwapFactoryContractCode(ctx, ec, sfAddr)
+	require.NoError(t, err)
+	require.Equal(t, forwarderAddr, parsedForwarderAddr)
+}
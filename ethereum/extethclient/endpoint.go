@@ -0,0 +1,88 @@
+package extethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Transport is how we connect to an ethereum endpoint, which is picked from the
+// endpoint's URL.
+type Transport string
+
+// The transports of ethereum endpoints. HTTP endpoints can't be used for
+// subscriptions, as those need a persistent connection.
+const (
+	TransportHTTP      Transport = "http"
+	TransportWebSocket Transport = "websocket"
+	TransportIPC       Transport = "ipc"
+)
+
+// ErrSubscriptionsUnsupported is returned when subscriptions are required, but
+// the ethereum endpoint is an HTTP endpoint.
+var ErrSubscriptionsUnsupported = errors.New(
+	"ethereum endpoint doesn't support subscriptions, use a ws://, wss:// or IPC endpoint",
+)
+
+var errUnsupportedEndpoint = errors.New("unsupported ethereum endpoint")
+
+// SupportsSubscriptions returns whether subscriptions, like eth_subscribe, can
+// be made over the transport.
+func (t Transport) SupportsSubscriptions() bool {
+	return t == TransportWebSocket || t == TransportIPC
+}
+
+// ParseEndpoint returns the transport of the ethereum endpoint. Endpoints with
+// an http:// or https:// scheme use HTTP, and those with a ws:// or wss://
+// scheme use WebSockets. Endpoints without a scheme are paths to the IPC
+// socket of a local node.
+func ParseEndpoint(endpoint string) (Transport, error) {
+	if endpoint == "" {
+		return "", fmt.Errorf("%w: endpoint is empty", errUnsupportedEndpoint)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		// eg. Windows named pipes, which aren't valid URLs
+		return TransportIPC, nil
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return TransportHTTP, nil
+	case "ws", "wss":
+		return TransportWebSocket, nil
+	case "":
+		return TransportIPC, nil
+	default:
+		return "", fmt.Errorf("%w: unknown scheme %q", errUnsupportedEndpoint, u.Scheme)
+	}
+}
+
+// Dial connects to the ethereum endpoint with the transport that ParseEndpoint
+// picks for it.
+func Dial(ctx context.Context, endpoint string) (*ethclient.Client, Transport, error) {
+	transport, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var c *rpc.Client
+	switch transport {
+	case TransportHTTP:
+		c, err = rpc.DialHTTP(endpoint)
+	case TransportWebSocket:
+		c, err = rpc.DialWebsocket(ctx, endpoint, "")
+	case TransportIPC:
+		c, err = rpc.DialIPC(ctx, endpoint)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to connect to %s ethereum endpoint: %w", transport, err)
+	}
+
+	return ethclient.NewClient(c), transport, nil
+}
@@ -0,0 +1,37 @@
+package extethclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint  string
+		transport Transport
+	}{
+		{"http://127.0.0.1:8545", TransportHTTP},
+		{"https://mainnet.example.org/v3/key", TransportHTTP},
+		{"ws://127.0.0.1:8546", TransportWebSocket},
+		{"wss://mainnet.example.org/ws/v3/key", TransportWebSocket},
+		{"/home/eth/.ethereum/geth.ipc", TransportIPC},
+		{"geth.ipc", TransportIPC},
+	} {
+		transport, err := ParseEndpoint(tc.endpoint)
+		require.NoError(t, err, tc.endpoint)
+		require.Equal(t, tc.transport, transport, tc.endpoint)
+	}
+
+	_, err := ParseEndpoint("")
+	require.ErrorIs(t, err, errUnsupportedEndpoint)
+
+	_, err = ParseEndpoint("ftp://127.0.0.1")
+	require.ErrorIs(t, err, errUnsupportedEndpoint)
+}
+
+func TestTransport_SupportsSubscriptions(t *testing.T) {
+	require.False(t, TransportHTTP.SupportsSubscriptions())
+	require.True(t, TransportWebSocket.SupportsSubscriptions())
+	require.True(t, TransportIPC.SupportsSubscriptions())
+}
@@ -5,6 +5,7 @@ package extethclient
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
@@ -23,6 +24,8 @@ import (
 
 var log = logging.Logger("extethclient")
 
+var errNoPrivateKey = errors.New("transactions can't be sent without a private key")
+
 // EthClient provides management of a private key and other convenience functions layered
 // on top of the go-ethereum client. You can still access the raw go-ethereum client via
 // the Raw() method.
@@ -30,16 +33,26 @@ type EthClient interface {
 	Address() ethcommon.Address
 	SetAddress(addr ethcommon.Address)
 	PrivateKey() *ecdsa.PrivateKey
+	Signer() Signer
 	HasPrivateKey() bool
 	Endpoint() string
+	SupportsSubscriptions() bool
 
 	Balance(ctx context.Context) (*big.Int, error)
 	ERC20Balance(ctx context.Context, token ethcommon.Address) (*big.Int, error)
 
 	ERC20Info(ctx context.Context, token ethcommon.Address) (name string, symbol string, decimals uint8, err error)
+	ERC20Allowance(ctx context.Context, token ethcommon.Address, spender ethcommon.Address) (*big.Int, error)
+	EnsureERC20Approval(
+		ctx context.Context,
+		token ethcommon.Address,
+		spender ethcommon.Address,
+		amount *big.Int,
+	) (*ethcommon.Hash, error)
 
 	SetGasPrice(uint64)
 	SetGasLimit(uint64)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 	CallOpts(ctx context.Context) *bind.CallOpts
 	TxOpts(ctx context.Context) (*bind.TransactOpts, error)
 	ChainID() *big.Int
@@ -56,8 +69,10 @@ type EthClient interface {
 
 type ethClient struct {
 	endpoint   string
+	transport  Transport
 	ec         *ethclient.Client
 	ethPrivKey *ecdsa.PrivateKey
+	signer     Signer
 	ethAddress ethcommon.Address
 	gasPrice   *big.Int
 	gasLimit   uint64
@@ -67,37 +82,66 @@ type ethClient struct {
 
 // NewEthClient creates and returns our extended ethereum client/wallet. The passed context
 // is only used for creation. The privKey can be nil if you are using an external signer.
+// The endpoint can be an HTTP, WebSocket or IPC endpoint, as described by ParseEndpoint.
 func NewEthClient(
 	ctx context.Context,
 	env common.Environment,
 	endpoint string,
 	privKey *ecdsa.PrivateKey,
 ) (EthClient, error) {
-	ec, err := ethclient.Dial(endpoint)
+	c, err := newEthClient(ctx, env, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	chainID, err := ec.ChainID(ctx)
+	if privKey != nil {
+		c.ethPrivKey = privKey
+		c.signer = NewPrivateKeySigner(privKey, c.chainID)
+		c.ethAddress = c.signer.Address()
+	}
+
+	return c, nil
+}
+
+// NewEthClientWithSigner creates and returns our extended ethereum client/wallet
+// that signs with the passed Signer instead of an in-memory private key.
+func NewEthClientWithSigner(
+	ctx context.Context,
+	env common.Environment,
+	endpoint string,
+	signer Signer,
+) (EthClient, error) {
+	c, err := newEthClient(ctx, env, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = validateChainID(env, chainID); err != nil {
+	c.signer = signer
+	c.ethAddress = signer.Address()
+
+	return c, nil
+}
+
+func newEthClient(ctx context.Context, env common.Environment, endpoint string) (*ethClient, error) {
+	ec, transport, err := Dial(ctx, endpoint)
+	if err != nil {
 		return nil, err
 	}
 
-	var addr ethcommon.Address
-	if privKey != nil {
-		addr = common.EthereumPrivateKeyToAddress(privKey)
+	chainID, err := ec.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateChainID(env, chainID); err != nil {
+		return nil, err
 	}
 
 	return &ethClient{
-		endpoint:   endpoint,
-		ec:         ec,
-		ethPrivKey: privKey,
-		ethAddress: addr,
-		chainID:    chainID,
+		endpoint:  endpoint,
+		transport: transport,
+		ec:        ec,
+		chainID:   chainID,
 	}, nil
 }
 
@@ -112,12 +156,22 @@ func (c *ethClient) SetAddress(addr ethcommon.Address) {
 	c.ethAddress = addr
 }
 
+// PrivateKey returns our in-memory private key, which is nil if we sign with a
+// Signer that holds the key elsewhere or with an external signer.
 func (c *ethClient) PrivateKey() *ecdsa.PrivateKey {
 	return c.ethPrivKey
 }
 
+// Signer returns the Signer of our transactions and messages, which is nil if
+// we are using an external signer.
+func (c *ethClient) Signer() Signer {
+	return c.signer
+}
+
+// HasPrivateKey returns whether we sign our transactions ourselves, with a
+// private key or another Signer, instead of with an external signer.
 func (c *ethClient) HasPrivateKey() bool {
-	return c.ethPrivKey != nil
+	return c.signer != nil
 }
 
 // Endpoint returns the endpoint URL that we are connected to
@@ -125,6 +179,12 @@ func (c *ethClient) Endpoint() string {
 	return c.endpoint
 }
 
+// SupportsSubscriptions returns whether the endpoint that we are connected to
+// supports subscriptions, which HTTP endpoints don't.
+func (c *ethClient) SupportsSubscriptions() bool {
+	return c.transport.SupportsSubscriptions()
+}
+
 func (c *ethClient) Balance(ctx context.Context) (*big.Int, error) {
 	addr := c.Address()
 	bal, err := c.ec.BalanceAt(ctx, addr, nil)
@@ -167,6 +227,70 @@ func (c *ethClient) ERC20Info(ctx context.Context, token ethcommon.Address) (
 	return name, symbol, decimals, nil
 }
 
+// ERC20Allowance returns how many of our tokens the spender is approved to
+// transfer.
+func (c *ethClient) ERC20Allowance(
+	ctx context.Context,
+	token ethcommon.Address,
+	spender ethcommon.Address,
+) (*big.Int, error) {
+	tokenContract, err := contracts.NewIERC20(token, c.ec)
+	if err != nil {
+		return nil, err
+	}
+	return tokenContract.Allowance(c.CallOpts(ctx), c.Address(), spender)
+}
+
+// EnsureERC20Approval approves the spender to transfer amount of our tokens,
+// unless it is already approved for at least that amount. The hash of the
+// approval transaction is returned if one was sent, otherwise nil.
+func (c *ethClient) EnsureERC20Approval(
+	ctx context.Context,
+	token ethcommon.Address,
+	spender ethcommon.Address,
+	amount *big.Int,
+) (*ethcommon.Hash, error) {
+	if !c.HasPrivateKey() {
+		return nil, errNoPrivateKey
+	}
+
+	tokenContract, err := contracts.NewIERC20(token, c.ec)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	allowance, err := tokenContract.Allowance(c.CallOpts(ctx), c.Address(), spender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token allowance: %w", err)
+	}
+
+	if allowance.Cmp(amount) >= 0 {
+		log.Debugf("%s is already approved to spend %s of token %s", spender, allowance, token)
+		return nil, nil
+	}
+
+	txOpts, err := c.TxOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := tokenContract.Approve(txOpts, spender, amount)
+	if err != nil {
+		return nil, fmt.Errorf("approve tx creation failed, %w", err)
+	}
+
+	txHash := tx.Hash()
+	if _, err = block.WaitForReceipt(ctx, c.ec, txHash); err != nil {
+		return nil, fmt.Errorf("approve failed, %w", err)
+	}
+
+	log.Infof("approved %s to spend %s of token %s, tx=%s", spender, amount, token, txHash)
+	return &txHash, nil
+}
+
 // SetGasPrice sets the ethereum gas price (in wei) for use in transactions. In most
 // cases, you should not use this function and let the ethereum client determine the
 // suggested gas price at the current time. Setting a value of zero reverts to using
@@ -186,6 +310,16 @@ func (c *ethClient) SetGasLimit(gasLimit uint64) {
 	c.gasLimit = gasLimit
 }
 
+// SuggestGasPrice returns the gas price (in wei) that transactions will use. This is
+// the price set with SetGasPrice or, if none was set, the raw ethereum client's
+// suggested price at the current time.
+func (c *ethClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	if c.gasPrice != nil {
+		return new(big.Int).Set(c.gasPrice), nil
+	}
+	return c.ec.SuggestGasPrice(ctx)
+}
+
 func (c *ethClient) CallOpts(ctx context.Context) *bind.CallOpts {
 	return &bind.CallOpts{
 		Pending:     false,
@@ -200,17 +334,14 @@ func (c *ethClient) TxOpts(ctx context.Context) (*bind.TransactOpts, error) {
 		panic("TxOpts() should not have been invoked when using an external signer")
 	}
 
-	txOpts, err := bind.NewKeyedTransactorWithChainID(c.ethPrivKey, c.chainID)
-	if err != nil {
-		return nil, err
-	}
-	txOpts.Context = ctx
-
 	// TODO: set gas limit + price based on network (#153)
-	txOpts.GasPrice = c.gasPrice
-	txOpts.GasLimit = c.gasLimit
-
-	return txOpts, nil
+	return &bind.TransactOpts{
+		From:     c.signer.Address(),
+		Signer:   c.signTx,
+		Context:  ctx,
+		GasPrice: c.gasPrice,
+		GasLimit: c.gasLimit,
+	}, nil
 }
 
 func (c *ethClient) ChainID() *big.Int {
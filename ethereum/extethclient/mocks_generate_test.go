@@ -0,0 +1,4 @@
+package extethclient
+
+//nolint:lll
+//go:generate mockgen -destination=mocks.go -package $GOPACKAGE github.com/athanorlabs/atomic-swap/ethereum/extethclient EthClient
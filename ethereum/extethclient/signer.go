@@ -0,0 +1,87 @@
+package extethclient
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+var errWrongSigner = errors.New("transaction was signed by the wrong address")
+
+// Signer signs everything that an EthClient sends on our behalf: transactions,
+// and the digests of messages that a contract verifies, like the forward
+// requests of relayed claims. The default Signer holds the ethereum key in
+// memory, but other implementations can keep it out of swapd, eg. in an HSM or
+// a remote signer service.
+type Signer interface {
+	// Address returns the address that everything is signed by.
+	Address() ethcommon.Address
+
+	// SignTx returns the transaction signed for the chain that we're on.
+	SignTx(tx *ethtypes.Transaction) (*ethtypes.Transaction, error)
+
+	// SignHash signs a 32-byte digest, eg. the EIP-712 hash of typed data. The
+	// signature is in the [R || S || V] format, with V being 0 or 1.
+	SignHash(digest [32]byte) ([]byte, error)
+}
+
+type privateKeySigner struct {
+	key     *ecdsa.PrivateKey
+	address ethcommon.Address
+	signer  ethtypes.Signer
+}
+
+// NewPrivateKeySigner returns a Signer that signs transactions for the chain
+// with the passed ID with an in-memory private key.
+func NewPrivateKeySigner(key *ecdsa.PrivateKey, chainID *big.Int) Signer {
+	return &privateKeySigner{
+		key:     key,
+		address: common.EthereumPrivateKeyToAddress(key),
+		signer:  ethtypes.LatestSignerForChainID(chainID),
+	}
+}
+
+func (s *privateKeySigner) Address() ethcommon.Address {
+	return s.address
+}
+
+func (s *privateKeySigner) SignTx(tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+	return ethtypes.SignTx(tx, s.signer, s.key)
+}
+
+func (s *privateKeySigner) SignHash(digest [32]byte) ([]byte, error) {
+	return ethcrypto.Sign(digest[:], s.key)
+}
+
+// signTx signs the transaction with our Signer. As a remote signer could sign
+// with a different key than the one we expect, the transaction is only returned
+// if it was signed by `from`.
+func (c *ethClient) signTx(from ethcommon.Address, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+	if from != c.signer.Address() {
+		return nil, bind.ErrNotAuthorized
+	}
+
+	signed, err := c.signer.SignTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	signedBy, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(c.chainID), signed)
+	if err != nil {
+		return nil, err
+	}
+
+	if signedBy != from {
+		return nil, fmt.Errorf("%w: %s instead of %s", errWrongSigner, signedBy, from)
+	}
+
+	return signed, nil
+}
@@ -0,0 +1,71 @@
+package extethclient
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+// otherAddressSigner claims an address other than the one of the key that it
+// signs with
+type otherAddressSigner struct {
+	Signer
+	address ethcommon.Address
+}
+
+func (s *otherAddressSigner) Address() ethcommon.Address {
+	return s.address
+}
+
+func TestEthClient_signTx(t *testing.T) {
+	chainID := big.NewInt(common.GanacheChainID)
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewPrivateKeySigner(key, chainID)
+	require.Equal(t, ethcrypto.PubkeyToAddress(key.PublicKey), signer.Address())
+
+	c := &ethClient{chainID: chainID, signer: signer, ethAddress: signer.Address()}
+	tx := ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       21000,
+	})
+
+	signed, err := c.signTx(signer.Address(), tx)
+	require.NoError(t, err)
+	from, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(chainID), signed)
+	require.NoError(t, err)
+	require.Equal(t, signer.Address(), from)
+
+	other := ethcommon.Address{0x1}
+	_, err = c.signTx(other, tx)
+	require.ErrorIs(t, err, bind.ErrNotAuthorized)
+
+	c.signer = &otherAddressSigner{Signer: signer, address: other}
+	_, err = c.signTx(other, tx)
+	require.ErrorIs(t, err, errWrongSigner)
+}
+
+func TestPrivateKeySigner_SignHash(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewPrivateKeySigner(key, big.NewInt(common.GanacheChainID))
+
+	digest := ethcrypto.Keccak256Hash([]byte("forward request"))
+	sig, err := signer.SignHash(digest)
+	require.NoError(t, err)
+	require.Len(t, sig, 65)
+
+	pubKey, err := ethcrypto.SigToPub(digest[:], sig)
+	require.NoError(t, err)
+	require.Equal(t, signer.Address(), ethcrypto.PubkeyToAddress(*pubKey))
+}
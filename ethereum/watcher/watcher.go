@@ -13,13 +13,22 @@ import (
 	logging "github.com/ipfs/go-log"
 )
 
-var (
-	log                   = logging.Logger("ethereum/watcher")
-	checkForBlocksTimeout = time.Second
-)
+// DefaultPollInterval is how often, by default, an EventFilter checks the
+// chain for new blocks. Filters poll with eth_getLogs instead of using
+// eth_subscribe, so they work with endpoints that don't support subscriptions,
+// like most public HTTP endpoints.
+const DefaultPollInterval = time.Second
+
+var log = logging.Logger("ethereum/watcher")
 
 // EventFilter filters the chain for specific events (logs).
 // When it finds a desired log, it puts it into its outbound channel.
+//
+// If reorg detection is enabled with SetReorgDetection, the block hash of each
+// found log is re-validated once its block has the configured number of
+// confirmations. Logs whose block was reorged out are sent, with Removed set,
+// to the reverted channel, and the filter rescans from their block so that a
+// log included again in the new chain is found again.
 type EventFilter struct {
 	ctx         context.Context
 	cancel      context.CancelFunc
@@ -27,6 +36,20 @@ type EventFilter struct {
 	topic       ethcommon.Hash
 	filterQuery eth.FilterQuery
 	logCh       chan<- ethtypes.Log
+
+	pollInterval time.Duration
+
+	// subscribeNewHeads has the filter check the chain when the node notifies
+	// it of a new head, instead of every pollInterval
+	subscribeNewHeads bool
+
+	confirmations uint64
+	revertedCh    chan<- ethtypes.Log
+	unconfirmed   []ethtypes.Log
+
+	// sent holds the logs that were sent to logCh, so that they aren't sent
+	// again when their blocks are filtered again
+	sent map[logKey]struct{}
 }
 
 // NewEventFilter returns a new *EventFilter.
@@ -41,68 +64,227 @@ func NewEventFilter(
 	filterQuery := eth.FilterQuery{
 		FromBlock: fromBlock,
 		Addresses: []ethcommon.Address{contract},
+		Topics:    [][]ethcommon.Hash{{topic}},
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	return &EventFilter{
-		ctx:         ctx,
-		cancel:      cancel,
-		ec:          ec,
-		topic:       topic,
-		filterQuery: filterQuery,
-		logCh:       logCh,
+		ctx:          ctx,
+		cancel:       cancel,
+		ec:           ec,
+		topic:        topic,
+		filterQuery:  filterQuery,
+		logCh:        logCh,
+		pollInterval: DefaultPollInterval,
+		sent:         make(map[logKey]struct{}),
+	}
+}
+
+// SetPollInterval sets how often the filter checks the chain for new blocks.
+// Longer intervals reduce the load on rate-limited endpoints, at the cost of
+// noticing events later. Zero uses DefaultPollInterval. It must be called
+// before Start.
+func (f *EventFilter) SetPollInterval(interval time.Duration) {
+	if interval == 0 {
+		interval = DefaultPollInterval
 	}
+	f.pollInterval = interval
+}
+
+// SetSubscribeNewHeads has the filter check the chain for logs whenever the
+// node notifies it of a new head with eth_subscribe, so that events are noticed
+// as soon as they're mined, instead of polling every poll interval. The
+// ethereum client must be connected to an endpoint that supports
+// subscriptions. If the subscription can't be set up, or fails later, the
+// filter falls back to polling. It must be called before Start.
+func (f *EventFilter) SetSubscribeNewHeads(subscribe bool) {
+	f.subscribeNewHeads = subscribe
+}
+
+// SetReorgDetection enables re-validating found logs once their block has the
+// passed number of confirmations, counting the block itself. Logs reverted by a
+// reorg are sent to revertedCh. It must be called before Start.
+func (f *EventFilter) SetReorgDetection(confirmations uint64, revertedCh chan<- ethtypes.Log) {
+	f.confirmations = confirmations
+	f.revertedCh = revertedCh
 }
 
-// Start starts the EventFilter. It watches the chain for logs.
+// Start starts the EventFilter. It first backfills the logs emitted since the
+// filter's start block, eg. while swapd was down, and then watches new blocks
+// for logs. If the filter subscribes to new heads, but the subscription can't
+// be set up, it polls instead.
 func (f *EventFilter) Start() error {
+	var (
+		heads chan *ethtypes.Header
+		sub   eth.Subscription
+	)
+	if f.subscribeNewHeads {
+		heads = make(chan *ethtypes.Header, 16)
+		var err error
+		sub, err = f.ec.SubscribeNewHead(f.ctx, heads)
+		if err != nil {
+			log.Warnf("failed to subscribe watcher for topic %s to new heads, polling instead: %s", f.topic, err)
+			sub = nil
+		}
+	}
+
 	go func() {
+		defer func() {
+			if sub != nil {
+				sub.Unsubscribe()
+			}
+		}()
+
+		log.Debugf("watcher for topic %s backfilling logs from block %s", f.topic, f.filterQuery.FromBlock)
 		for {
+			f.poll()
+
+			// without a subscription, we poll, and with one, we wait for the
+			// next head, or for the subscription to fail
+			var (
+				tick   <-chan time.Time
+				subErr <-chan error
+			)
+			if sub == nil {
+				tick = time.After(f.pollInterval)
+			} else {
+				subErr = sub.Err()
+			}
+
 			select {
 			case <-f.ctx.Done():
 				return
-			case <-time.After(checkForBlocksTimeout):
+			case <-tick:
+			case <-heads:
+			case err := <-subErr:
+				log.Warnf("new head subscription of watcher for topic %s failed, polling instead: %s", f.topic, err)
+				sub.Unsubscribe()
+				sub = nil
 			}
+		}
+	}()
 
-			currHeader, err := f.ec.HeaderByNumber(f.ctx, nil)
-			if err != nil {
-				log.Errorf("failed to get header in event watcher: %s", err)
-				continue
-			}
+	return nil
+}
 
-			if currHeader.Number.Cmp(f.filterQuery.FromBlock) <= 0 {
-				// no new blocks, don't do anything
-				continue
-			}
+// poll sends the logs in the blocks from the filter's start block to the
+// current head, and moves the start block past the head.
+func (f *EventFilter) poll() {
+	currHeader, err := f.ec.HeaderByNumber(f.ctx, nil)
+	if err != nil {
+		log.Errorf("failed to get header in event watcher: %s", err)
+		return
+	}
 
-			// let's see if we have logs
-			logs, err := f.ec.FilterLogs(f.ctx, f.filterQuery)
-			if err != nil {
-				log.Errorf("failed to filter logs for topic %s: %s", f.topic, err)
-				continue
-			}
+	if f.revertedCh != nil {
+		f.checkUnconfirmed(currHeader.Number)
+	}
 
-			log.Debugf("filtered for logs from block %s to block %s", f.filterQuery.FromBlock, currHeader.Number)
+	if currHeader.Number.Cmp(f.filterQuery.FromBlock) < 0 {
+		// no new blocks, don't do anything
+		return
+	}
 
-			for _, l := range logs {
-				if l.Topics[0] != f.topic {
-					continue
-				}
+	// the range is bounded by the head, so that logs of blocks mined while we
+	// filter are found by the next poll, instead of by both
+	query := f.filterQuery
+	query.ToBlock = currHeader.Number
+	logs, err := f.ec.FilterLogs(f.ctx, query)
+	if err != nil {
+		log.Errorf("failed to filter logs for topic %s: %s", f.topic, err)
+		return
+	}
 
-				if l.Removed {
-					log.Debugf("found removed log: tx hash %s", l.TxHash)
-					continue
-				}
+	log.Debugf("filtered for logs from block %s to block %s", query.FromBlock, query.ToBlock)
 
-				log.Debugf("watcher for topic %s found log in block %d", f.topic, l.BlockNumber)
-				f.logCh <- l
-			}
+	for _, l := range f.newLogs(logs) {
+		log.Debugf("watcher for topic %s found log in block %d", f.topic, l.BlockNumber)
+		select {
+		case f.logCh <- l:
+		case <-f.ctx.Done():
+			return
+		}
+		if f.revertedCh != nil {
+			f.unconfirmed = append(f.unconfirmed, l)
+		}
+	}
+
+	f.filterQuery.FromBlock = new(big.Int).Add(currHeader.Number, big.NewInt(1))
+}
 
-			f.filterQuery.FromBlock = currHeader.Number
+// logKey identifies a log in a specific block, so a log that is included again
+// in a different block after a reorg is sent again.
+type logKey struct {
+	blockHash ethcommon.Hash
+	txHash    ethcommon.Hash
+	index     uint
+}
+
+// newLogs returns the logs with the filter's topic that it hasn't sent yet,
+// and marks them as sent. Logs can be found again when the filter rescans
+// blocks after a reorg.
+func (f *EventFilter) newLogs(logs []ethtypes.Log) []ethtypes.Log {
+	var found []ethtypes.Log
+	for _, l := range logs {
+		if len(l.Topics) == 0 || l.Topics[0] != f.topic {
+			continue
 		}
-	}()
 
-	return nil
+		if l.Removed {
+			log.Debugf("found removed log: tx hash %s", l.TxHash)
+			continue
+		}
+
+		key := logKey{blockHash: l.BlockHash, txHash: l.TxHash, index: l.Index}
+		if _, has := f.sent[key]; has {
+			continue
+		}
+
+		f.sent[key] = struct{}{}
+		found = append(found, l)
+	}
+
+	return found
+}
+
+// checkUnconfirmed re-validates the found logs whose block has the configured
+// number of confirmations as of the passed head, and stops tracking them.
+func (f *EventFilter) checkUnconfirmed(head *big.Int) {
+	var remaining []ethtypes.Log
+	for _, l := range f.unconfirmed {
+		if l.BlockNumber+f.confirmations > head.Uint64()+1 {
+			remaining = append(remaining, l)
+			continue
+		}
+
+		header, err := f.ec.HeaderByNumber(f.ctx, new(big.Int).SetUint64(l.BlockNumber))
+		if err != nil {
+			log.Errorf("failed to get header of block %d in event watcher: %s", l.BlockNumber, err)
+			remaining = append(remaining, l)
+			continue
+		}
+
+		if header.Hash() == l.BlockHash {
+			log.Debugf("watcher for topic %s confirmed log in block %d", f.topic, l.BlockNumber)
+			continue
+		}
+
+		log.Warnf("watcher for topic %s found log in block %d was reverted by a reorg: tx hash %s",
+			f.topic, l.BlockNumber, l.TxHash)
+		l.Removed = true
+		select {
+		case f.revertedCh <- l:
+		case <-f.ctx.Done():
+			return
+		}
+
+		// rescan from the reverted block for the log's inclusion in the new chain
+		if from := new(big.Int).SetUint64(l.BlockNumber); from.Cmp(f.filterQuery.FromBlock) < 0 {
+			f.filterQuery.FromBlock = from
+		}
+	}
+
+	f.unconfirmed = remaining
 }
 
 // Stop stops the EventFilter.
@@ -0,0 +1,69 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFilter_newLogs(t *testing.T) {
+	topic := ethcommon.Hash{0x1}
+	f := NewEventFilter(context.Background(), nil, ethcommon.Address{}, big.NewInt(0), topic, nil)
+
+	backfilled := ethtypes.Log{
+		Topics:      []ethcommon.Hash{topic},
+		BlockNumber: 10,
+		BlockHash:   ethcommon.Hash{0xa},
+		TxHash:      ethcommon.Hash{0xb},
+	}
+	otherTopic := ethtypes.Log{
+		Topics:    []ethcommon.Hash{{0x2}},
+		BlockHash: ethcommon.Hash{0xa},
+		TxHash:    ethcommon.Hash{0xc},
+	}
+	removed := ethtypes.Log{
+		Topics:    []ethcommon.Hash{topic},
+		BlockHash: ethcommon.Hash{0xa},
+		TxHash:    ethcommon.Hash{0xd},
+		Removed:   true,
+	}
+
+	logs := f.newLogs([]ethtypes.Log{backfilled, otherTopic, removed})
+	require.Equal(t, []ethtypes.Log{backfilled}, logs)
+
+	// a log found again, eg. when blocks are rescanned after a reorg, isn't
+	// sent twice
+	live := ethtypes.Log{
+		Topics:      []ethcommon.Hash{topic},
+		BlockNumber: 11,
+		BlockHash:   ethcommon.Hash{0xe},
+		TxHash:      ethcommon.Hash{0xf},
+	}
+	logs = f.newLogs([]ethtypes.Log{backfilled, live})
+	require.Equal(t, []ethtypes.Log{live}, logs)
+
+	// unless it was included again in a different block
+	reincluded := backfilled
+	reincluded.BlockNumber = 11
+	reincluded.BlockHash = live.BlockHash
+	logs = f.newLogs([]ethtypes.Log{reincluded})
+	require.Equal(t, []ethtypes.Log{reincluded}, logs)
+}
+
+func TestEventFilter_Start_subscriptionUnsupported(t *testing.T) {
+	// HTTP endpoints don't support subscriptions, and the client fails to
+	// subscribe without connecting to the endpoint
+	ec, err := ethclient.Dial("http://127.0.0.1:1")
+	require.NoError(t, err)
+	t.Cleanup(ec.Close)
+
+	f := NewEventFilter(context.Background(), ec, ethcommon.Address{}, big.NewInt(0), ethcommon.Hash{0x1}, nil)
+	f.SetSubscribeNewHeads(true)
+	require.NoError(t, f.Start())
+	f.Stop()
+}
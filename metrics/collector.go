@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// OfferLister is implemented by the xmrmaker instance, which holds our offers.
+type OfferLister interface {
+	GetOffers() []*types.Offer
+}
+
+// RelayQueue is implemented by the p2p host, which queues the relay claim
+// requests that it receives.
+type RelayQueue interface {
+	RelayQueueStats() net.RelayQueueStats
+}
+
+// Sources are what the gauges are read from when metrics are scraped.
+type Sources struct {
+	SwapManager swap.Manager
+	Offers      OfferLister
+	XMRClient   monero.WalletClient
+	ETHClient   extethclient.EthClient
+	RelayQueue  RelayQueue
+}
+
+// sourceCollector reports gauges read from its sources at scrape time, so they
+// are never stale.
+type sourceCollector struct {
+	ctx context.Context
+	src *Sources
+
+	ongoingSwaps       *prometheus.Desc
+	offers             *prometheus.Desc
+	xmrBalance         *prometheus.Desc
+	xmrUnlockedBalance *prometheus.Desc
+	ethBalance         *prometheus.Desc
+	relayActive        *prometheus.Desc
+	relayQueued        *prometheus.Desc
+	relayRejected      *prometheus.Desc
+}
+
+func newSourceCollector(ctx context.Context, src *Sources) *sourceCollector {
+	return &sourceCollector{
+		ctx: ctx,
+		src: src,
+		ongoingSwaps: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "ongoing_swaps"),
+			"Swaps currently in progress, by status.",
+			[]string{"status"}, nil,
+		),
+		offers: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "offers"),
+			"Offers that we are currently advertising.",
+			nil, nil,
+		),
+		xmrBalance: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "xmr_balance"),
+			"Balance of the primary Monero wallet, in XMR.",
+			nil, nil,
+		),
+		xmrUnlockedBalance: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "xmr_unlocked_balance"),
+			"Unlocked balance of the primary Monero wallet, in XMR.",
+			nil, nil,
+		),
+		ethBalance: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "eth_balance"),
+			"Balance of the Ethereum account, in ETH.",
+			nil, nil,
+		),
+		relayActive: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "relay_requests_active"),
+			"Relay claim requests that we are currently handling.",
+			nil, nil,
+		),
+		relayQueued: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "relay_requests_queued"),
+			"Relay claim requests waiting for a free worker.",
+			nil, nil,
+		),
+		relayRejected: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "relay_requests_rejected_total"),
+			"Relay claim requests rejected because the queue was full or they waited too long.",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *sourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.ongoingSwaps
+	ch <- c.offers
+	ch <- c.xmrBalance
+	ch <- c.xmrUnlockedBalance
+	ch <- c.ethBalance
+	ch <- c.relayActive
+	ch <- c.relayQueued
+	ch <- c.relayRejected
+}
+
+// Collect implements prometheus.Collector. A source that fails to be read is
+// logged and its gauges are left out of the scrape.
+func (c *sourceCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.src.SwapManager != nil {
+		swaps, err := c.src.SwapManager.GetOngoingSwaps()
+		if err != nil {
+			log.Warnf("failed to get ongoing swaps for metrics: %s", err)
+		} else {
+			counts := make(map[string]int)
+			for _, info := range swaps {
+				counts[info.Status.String()]++
+			}
+			for status, count := range counts {
+				ch <- prometheus.MustNewConstMetric(c.ongoingSwaps, prometheus.GaugeValue, float64(count), status)
+			}
+		}
+	}
+
+	if c.src.Offers != nil {
+		ch <- prometheus.MustNewConstMetric(c.offers, prometheus.GaugeValue, float64(len(c.src.Offers.GetOffers())))
+	}
+
+	if c.src.XMRClient != nil {
+		bal, err := c.src.XMRClient.GetBalance(0)
+		if err != nil {
+			log.Warnf("failed to get XMR balance for metrics: %s", err)
+		} else {
+			ch <- prometheus.MustNewConstMetric(c.xmrBalance, prometheus.GaugeValue,
+				decimalToFloat(coins.NewPiconeroAmount(bal.Balance).AsMonero()))
+			ch <- prometheus.MustNewConstMetric(c.xmrUnlockedBalance, prometheus.GaugeValue,
+				decimalToFloat(coins.NewPiconeroAmount(bal.UnlockedBalance).AsMonero()))
+		}
+	}
+
+	if c.src.ETHClient != nil {
+		bal, err := c.src.ETHClient.Balance(c.ctx)
+		if err != nil {
+			log.Warnf("failed to get ETH balance for metrics: %s", err)
+		} else {
+			ch <- prometheus.MustNewConstMetric(c.ethBalance, prometheus.GaugeValue,
+				decimalToFloat(coins.NewWeiAmount(bal).AsEther()))
+		}
+	}
+
+	if c.src.RelayQueue != nil {
+		stats := c.src.RelayQueue.RelayQueueStats()
+		ch <- prometheus.MustNewConstMetric(c.relayActive, prometheus.GaugeValue, float64(stats.Active))
+		ch <- prometheus.MustNewConstMetric(c.relayQueued, prometheus.GaugeValue, float64(stats.Queued))
+		ch <- prometheus.MustNewConstMetric(c.relayRejected, prometheus.CounterValue, float64(stats.Rejected))
+	}
+}
+
+// NewRegistry returns a registry holding all of swapd's metrics, with the
+// gauges read from src.
+func NewRegistry(ctx context.Context, src *Sources) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		swapsExited,
+		swapDuration,
+		relayerClaims,
+		newSourceCollector(ctx, src),
+	)
+	return reg
+}
@@ -0,0 +1,57 @@
+// Package metrics provides swapd's Prometheus metrics, and the HTTP server
+// that exposes them for scraping.
+package metrics
+
+import (
+	"time"
+
+	logging "github.com/ipfs/go-log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+const namespace = "swapd"
+
+var (
+	log = logging.Logger("metrics")
+
+	swapsExited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "swaps_exited_total",
+		Help:      "Swaps that have exited, by the coin we provided and their final status.",
+	}, []string{"provides", "status"})
+
+	swapDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "swap_duration_seconds",
+		Help:      "Time from a swap starting until it exits, by final status.",
+		// 1 minute up to ~17 hours, which covers both swap timeouts at their defaults
+		Buckets: prometheus.ExponentialBuckets(60, 2, 11),
+	}, []string{"status"})
+
+	relayerClaims = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "relayer_claims_total",
+		Help:      "Claims that we submitted to relayers, by whether the claim was mined.",
+	}, []string{"outcome"})
+)
+
+// SwapExited records a swap exiting with the passed final status. startTime is
+// when the swap started, and is not observed if zero.
+func SwapExited(provides coins.ProvidesCoin, status types.Status, startTime time.Time) {
+	swapsExited.WithLabelValues(string(provides), status.String()).Inc()
+	if !startTime.IsZero() {
+		swapDuration.WithLabelValues(status.String()).Observe(time.Since(startTime).Seconds())
+	}
+}
+
+// RelayerClaimSubmitted records the outcome of submitting a claim to a relayer.
+func RelayerClaimSubmitted(succeeded bool) {
+	outcome := "failed"
+	if succeeded {
+		outcome = "succeeded"
+	}
+	relayerClaims.WithLabelValues(outcome).Inc()
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net"
+)
+
+type mockOfferLister struct {
+	offers []*types.Offer
+}
+
+func (m *mockOfferLister) GetOffers() []*types.Offer {
+	return m.offers
+}
+
+func TestSwapExited(t *testing.T) {
+	counter := swapsExited.WithLabelValues(string(coins.ProvidesXMR), types.CompletedSuccess.String())
+	before := testutil.ToFloat64(counter)
+
+	SwapExited(coins.ProvidesXMR, types.CompletedSuccess, time.Now().Add(-time.Minute))
+	require.Equal(t, before+1, testutil.ToFloat64(counter))
+}
+
+func TestRelayerClaimSubmitted(t *testing.T) {
+	succeeded := relayerClaims.WithLabelValues("succeeded")
+	failed := relayerClaims.WithLabelValues("failed")
+	beforeSucceeded := testutil.ToFloat64(succeeded)
+	beforeFailed := testutil.ToFloat64(failed)
+
+	RelayerClaimSubmitted(true)
+	RelayerClaimSubmitted(false)
+	RelayerClaimSubmitted(false)
+	require.Equal(t, beforeSucceeded+1, testutil.ToFloat64(succeeded))
+	require.Equal(t, beforeFailed+2, testutil.ToFloat64(failed))
+}
+
+func TestNewRegistry_offers(t *testing.T) {
+	src := &Sources{
+		Offers: &mockOfferLister{offers: []*types.Offer{{}, {}}},
+	}
+	reg := NewRegistry(context.Background(), src)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	var found bool
+	for _, family := range families {
+		if family.GetName() == "swapd_offers" {
+			found = true
+			require.Len(t, family.GetMetric(), 1)
+			require.Equal(t, float64(2), family.GetMetric()[0].GetGauge().GetValue())
+		}
+	}
+	require.True(t, found)
+}
+
+type mockRelayQueue struct {
+	stats net.RelayQueueStats
+}
+
+func (m *mockRelayQueue) RelayQueueStats() net.RelayQueueStats {
+	return m.stats
+}
+
+func TestNewRegistry_relayQueue(t *testing.T) {
+	src := &Sources{
+		RelayQueue: &mockRelayQueue{stats: net.RelayQueueStats{Active: 4, Queued: 2, Rejected: 7}},
+	}
+	reg := NewRegistry(context.Background(), src)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), "swapd_relay_requests_") {
+			continue
+		}
+		require.Len(t, family.GetMetric(), 1)
+		m := family.GetMetric()[0]
+		if m.GetCounter() != nil {
+			values[family.GetName()] = m.GetCounter().GetValue()
+		} else {
+			values[family.GetName()] = m.GetGauge().GetValue()
+		}
+	}
+	require.Equal(t, float64(4), values["swapd_relay_requests_active"])
+	require.Equal(t, float64(2), values["swapd_relay_requests_queued"])
+	require.Equal(t, float64(7), values["swapd_relay_requests_rejected_total"])
+}
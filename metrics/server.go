@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server serves metrics in the Prometheus text format on /metrics.
+type Server struct {
+	ctx        context.Context
+	listener   net.Listener
+	httpServer *http.Server
+}
+
+// NewServer listens on address and returns a server for the registry's
+// metrics. Start must be called to serve requests.
+func NewServer(ctx context.Context, address string, reg *prometheus.Registry) (*Server, error) {
+	lc := net.ListenConfig{}
+	ln, err := lc.Listen(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	return &Server{
+		ctx:      ctx,
+		listener: ln,
+		httpServer: &http.Server{
+			Addr:              ln.Addr().String(),
+			ReadHeaderTimeout: time.Second,
+			Handler:           mux,
+			BaseContext: func(net.Listener) context.Context {
+				return ctx
+			},
+		},
+	}, nil
+}
+
+// Addr returns the address that the server is listening on.
+func (s *Server) Addr() string {
+	return s.httpServer.Addr
+}
+
+// Start serves metrics until the server's context is cancelled.
+func (s *Server) Start() error {
+	log.Infof("Starting metrics server on http://%s/metrics", s.Addr())
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- s.httpServer.Serve(s.listener)
+	}()
+
+	select {
+	case <-s.ctx.Done():
+		if err := s.httpServer.Shutdown(s.ctx); err != nil {
+			log.Debugf("metrics server shutdown errored: %s", err)
+		}
+		return s.ctx.Err()
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("metrics server failed: %s", err)
+		}
+		return err
+	}
+}
+
+func decimalToFloat(d *apd.Decimal) float64 {
+	f, err := d.Float64()
+	if err != nil {
+		return math.NaN()
+	}
+	return f
+}
@@ -0,0 +1,242 @@
+package monero
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/MarinX/monerorpc/wallet"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+)
+
+// AuditEntry is a single record of the wallet audit log. Entries only hold
+// addresses, amounts and transaction IDs; wallet passwords, keys and the wallet
+// RPC endpoint are never recorded. Amounts are in XMR.
+type AuditEntry struct {
+	Time            time.Time `json:"time"`
+	Wallet          string    `json:"wallet"`
+	Method          string    `json:"method"`
+	Account         *uint64   `json:"account,omitempty"`
+	Address         string    `json:"address,omitempty"`
+	To              string    `json:"to,omitempty"`
+	Amount          string    `json:"amount,omitempty"`
+	Fee             string    `json:"fee,omitempty"`
+	Balance         string    `json:"balance,omitempty"`
+	UnlockedBalance string    `json:"unlockedBalance,omitempty"`
+	TxIDs           []string  `json:"txIDs,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// AuditLog writes AuditEntry records as JSON lines. It is safe for concurrent
+// use by multiple audited wallet clients.
+type AuditLog struct {
+	mu  sync.Mutex
+	w   io.WriteCloser
+	enc *json.Encoder
+	now func() time.Time // overridden in tests
+}
+
+// NewAuditLog opens the audit log at `filePath` for appending, creating it if
+// it does not exist.
+func NewAuditLog(filePath string) (*AuditLog, error) {
+	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return newAuditLog(f), nil
+}
+
+func newAuditLog(w io.WriteCloser) *AuditLog {
+	return &AuditLog{
+		w:   w,
+		enc: json.NewEncoder(w),
+		now: time.Now,
+	}
+}
+
+func (l *AuditLog) write(entry *AuditEntry, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Time = l.now()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if encErr := l.enc.Encode(entry); encErr != nil {
+		log.Warnf("failed to write wallet audit log entry for %s: %s", entry.Method, encErr)
+	}
+}
+
+// Close closes the underlying audit log file.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Close()
+}
+
+// auditWalletClient is a WalletClient that records each wallet operation, and
+// its result, to an AuditLog before returning it to the caller.
+type auditWalletClient struct {
+	WalletClient
+	audit *AuditLog
+}
+
+// NewAuditWalletClient wraps `client` so that balance queries, account
+// creation, transfers, sweeps, fee estimates and wallet removals are recorded
+// to `audit`.
+func NewAuditWalletClient(client WalletClient, audit *AuditLog) WalletClient {
+	return &auditWalletClient{
+		WalletClient: client,
+		audit:        audit,
+	}
+}
+
+func (c *auditWalletClient) entry(method string, accountIdx *uint64) *AuditEntry {
+	return &AuditEntry{
+		Wallet:  c.WalletName(),
+		Method:  method,
+		Account: accountIdx,
+	}
+}
+
+func (c *auditWalletClient) GetAccounts() (*wallet.GetAccountsResponse, error) {
+	resp, err := c.WalletClient.GetAccounts()
+	entry := c.entry("get_accounts", nil)
+	if err == nil {
+		entry.Balance = coins.FmtPiconeroAsXMR(resp.TotalBalance)
+		entry.UnlockedBalance = coins.FmtPiconeroAsXMR(resp.TotalUnlockedBalance)
+	}
+	c.audit.write(entry, err)
+	return resp, err
+}
+
+func (c *auditWalletClient) GetAddress(idx uint64) (*wallet.GetAddressResponse, error) {
+	resp, err := c.WalletClient.GetAddress(idx)
+	entry := c.entry("get_address", &idx)
+	if err == nil {
+		entry.Address = resp.Address
+	}
+	c.audit.write(entry, err)
+	return resp, err
+}
+
+func (c *auditWalletClient) CreateAccount(label string) (uint64, *mcrypto.Address, error) {
+	idx, addr, err := c.WalletClient.CreateAccount(label)
+	entry := c.entry("create_account", nil)
+	if err == nil {
+		entry.Account = &idx
+		entry.Address = addr.String()
+	}
+	c.audit.write(entry, err)
+	return idx, addr, err
+}
+
+func (c *auditWalletClient) GetBalance(idx uint64) (*wallet.GetBalanceResponse, error) {
+	resp, err := c.WalletClient.GetBalance(idx)
+	entry := c.entry("get_balance", &idx)
+	if err == nil {
+		entry.Balance = coins.FmtPiconeroAsXMR(resp.Balance)
+		entry.UnlockedBalance = coins.FmtPiconeroAsXMR(resp.UnlockedBalance)
+	}
+	c.audit.write(entry, err)
+	return resp, err
+}
+
+func (c *auditWalletClient) Transfer(
+	ctx context.Context,
+	to *mcrypto.Address,
+	accountIdx uint64,
+	amount *coins.PiconeroAmount,
+	numConfirmations uint64,
+	priority types.MoneroTxPriority,
+) (*wallet.Transfer, error) {
+	transfer, err := c.WalletClient.Transfer(ctx, to, accountIdx, amount, numConfirmations, priority)
+	entry := c.entry("transfer", &accountIdx)
+	entry.To = to.String()
+	entry.Amount = amount.AsMoneroString()
+	if err == nil {
+		entry.Fee = coins.FmtPiconeroAsXMR(transfer.Fee)
+		entry.TxIDs = []string{transfer.TxID}
+	}
+	c.audit.write(entry, err)
+	return transfer, err
+}
+
+func (c *auditWalletClient) SweepAll(
+	ctx context.Context,
+	to *mcrypto.Address,
+	accountIdx uint64,
+	numConfirmations uint64,
+) ([]*wallet.Transfer, error) {
+	transfers, err := c.WalletClient.SweepAll(ctx, to, accountIdx, numConfirmations)
+	entry := c.entry("sweep_all", &accountIdx)
+	entry.To = to.String()
+	if err == nil {
+		var amount, fee uint64
+		for _, transfer := range transfers {
+			amount += transfer.Amount
+			fee += transfer.Fee
+			entry.TxIDs = append(entry.TxIDs, transfer.TxID)
+		}
+		entry.Amount = coins.FmtPiconeroAsXMR(amount)
+		entry.Fee = coins.FmtPiconeroAsXMR(fee)
+	}
+	c.audit.write(entry, err)
+	return transfers, err
+}
+
+func (c *auditWalletClient) EstimateTransferFee(
+	to *mcrypto.Address,
+	accountIdx uint64,
+	amount *coins.PiconeroAmount,
+	priority types.MoneroTxPriority,
+) (*coins.PiconeroAmount, error) {
+	fee, err := c.WalletClient.EstimateTransferFee(to, accountIdx, amount, priority)
+	entry := c.entry("estimate_transfer_fee", &accountIdx)
+	entry.To = to.String()
+	entry.Amount = amount.AsMoneroString()
+	if err == nil {
+		entry.Fee = fee.AsMoneroString()
+	}
+	c.audit.write(entry, err)
+	return fee, err
+}
+
+func (c *auditWalletClient) CloseAndRemoveWallet() {
+	entry := c.entry("close_and_remove_wallet", nil)
+	c.WalletClient.CloseAndRemoveWallet()
+	c.audit.write(entry, nil)
+}
+
+// withAuditLog wraps `client` with NewAuditWalletClient if `audit` is set, so
+// that every wallet client created from a config with an audit log, including
+// the swap wallets created from our primary wallet's config, is audited.
+func withAuditLog(client WalletClient, audit *AuditLog) WalletClient {
+	if audit == nil {
+		return client
+	}
+	return NewAuditWalletClient(client, audit)
+}
+
+// unwrapWalletClient returns the walletClient underneath any wrappers, for the
+// package helpers that need direct access to the wallet RPC. Other
+// implementations, like mocks, don't have one, so an error is returned.
+func unwrapWalletClient(client WalletClient) (*walletClient, error) {
+	if ac, ok := client.(*auditWalletClient); ok {
+		return unwrapWalletClient(ac.WalletClient)
+	}
+
+	c, ok := client.(*walletClient)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", errNoWalletRPC, client)
+	}
+
+	return c, nil
+}
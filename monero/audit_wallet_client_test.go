@@ -0,0 +1,119 @@
+package monero
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MarinX/monerorpc/wallet"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// stubWalletClient answers the audited calls without a monero-wallet-rpc
+// process; any other call panics on the nil embedded interface.
+type stubWalletClient struct {
+	WalletClient
+	transferErr error
+}
+
+func (*stubWalletClient) WalletName() string { return "stub-wallet" }
+
+func (*stubWalletClient) GetBalance(_ uint64) (*wallet.GetBalanceResponse, error) {
+	return &wallet.GetBalanceResponse{Balance: 2e12, UnlockedBalance: 1e12}, nil
+}
+
+func (s *stubWalletClient) Transfer(
+	_ context.Context,
+	_ *mcrypto.Address,
+	_ uint64,
+	_ *coins.PiconeroAmount,
+	_ uint64,
+	_ types.MoneroTxPriority,
+) (*wallet.Transfer, error) {
+	if s.transferErr != nil {
+		return nil, s.transferErr
+	}
+	return &wallet.Transfer{TxID: "abcd", Fee: 1e9}, nil
+}
+
+func TestAuditWalletClient(t *testing.T) {
+	buf := new(bytes.Buffer)
+	audit := newAuditLog(nopCloser{buf})
+	now := time.Unix(1700000000, 0).UTC()
+	audit.now = func() time.Time { return now }
+
+	stub := &stubWalletClient{}
+	c := NewAuditWalletClient(stub, audit)
+
+	_, err := c.GetBalance(0)
+	require.NoError(t, err)
+
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	to := kp.PublicKeyPair().Address(common.Development)
+	amount := coins.MoneroToPiconero(coins.StrToDecimal("0.5"))
+
+	_, err = c.Transfer(context.Background(), to, 0, amount, 1, types.MoneroPriorityDefault)
+	require.NoError(t, err)
+
+	stub.transferErr = errors.New("not enough money")
+	_, err = c.Transfer(context.Background(), to, 0, amount, 1, types.MoneroPriorityDefault)
+	require.ErrorIs(t, err, stub.transferErr)
+
+	dec := json.NewDecoder(buf)
+	var entries []*AuditEntry
+	for dec.More() {
+		entry := new(AuditEntry)
+		require.NoError(t, dec.Decode(entry))
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, 3)
+
+	require.Equal(t, "get_balance", entries[0].Method)
+	require.Equal(t, "stub-wallet", entries[0].Wallet)
+	require.Equal(t, "2", entries[0].Balance)
+	require.Equal(t, "1", entries[0].UnlockedBalance)
+	require.True(t, now.Equal(entries[0].Time))
+
+	require.Equal(t, "transfer", entries[1].Method)
+	require.Equal(t, to.String(), entries[1].To)
+	require.Equal(t, "0.5", entries[1].Amount)
+	require.Equal(t, "0.001", entries[1].Fee)
+	require.Equal(t, []string{"abcd"}, entries[1].TxIDs)
+	require.Empty(t, entries[1].Error)
+
+	require.Equal(t, "transfer", entries[2].Method)
+	require.Equal(t, "not enough money", entries[2].Error)
+	require.Empty(t, entries[2].TxIDs)
+}
+
+func TestWithAuditLog(t *testing.T) {
+	stub := &stubWalletClient{}
+	require.Equal(t, WalletClient(stub), withAuditLog(stub, nil))
+
+	audit := newAuditLog(nopCloser{new(bytes.Buffer)})
+	audited := withAuditLog(stub, audit)
+	require.IsType(t, &auditWalletClient{}, audited)
+
+	// wallets created from our wallet's config are audited to the same log
+	c := &walletClient{conf: &WalletClientConf{
+		Env:            common.Development,
+		WalletFilePath: "/wallets/primary",
+		AuditLog:       audit,
+	}}
+	require.Same(t, audit, c.WalletConf("swap-wallet").AuditLog)
+}
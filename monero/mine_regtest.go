@@ -31,7 +31,7 @@ func BackgroundMineBlocks(ctx context.Context, blockRewardAddress *mcrypto.Addre
 	defer wg.Wait()
 
 	// Lower the sleep duration used by WaitForBlock
-	blockSleepDuration = backgroundMineInterval / 3
+	devBlockSleepDuration = backgroundMineInterval / 3
 	go func() {
 		defer wg.Done()
 		if !mineMu.TryLock() {
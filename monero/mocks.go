@@ -0,0 +1,254 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/athanorlabs/atomic-swap/monero (interfaces: WalletClient)
+
+// Package monero is a generated GoMock package.
+package monero
+
+import (
+	context "context"
+	reflect "reflect"
+
+	wallet "github.com/MarinX/monerorpc/wallet"
+	coins "github.com/athanorlabs/atomic-swap/coins"
+	types "github.com/athanorlabs/atomic-swap/common/types"
+	monero "github.com/athanorlabs/atomic-swap/crypto/monero"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWalletClient is a mock of WalletClient interface.
+type MockWalletClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletClientMockRecorder
+}
+
+// MockWalletClientMockRecorder is the mock recorder for MockWalletClient.
+type MockWalletClientMockRecorder struct {
+	mock *MockWalletClient
+}
+
+// NewMockWalletClient creates a new mock instance.
+func NewMockWalletClient(ctrl *gomock.Controller) *MockWalletClient {
+	mock := &MockWalletClient{ctrl: ctrl}
+	mock.recorder = &MockWalletClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWalletClient) EXPECT() *MockWalletClientMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockWalletClient) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockWalletClientMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockWalletClient)(nil).Close))
+}
+
+// CloseAndRemoveWallet mocks base method.
+func (m *MockWalletClient) CloseAndRemoveWallet() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CloseAndRemoveWallet")
+}
+
+// CloseAndRemoveWallet indicates an expected call of CloseAndRemoveWallet.
+func (mr *MockWalletClientMockRecorder) CloseAndRemoveWallet() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAndRemoveWallet", reflect.TypeOf((*MockWalletClient)(nil).CloseAndRemoveWallet))
+}
+
+// CreateAccount mocks base method.
+func (m *MockWalletClient) CreateAccount(arg0 string) (uint64, *monero.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(*monero.Address)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockWalletClientMockRecorder) CreateAccount(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockWalletClient)(nil).CreateAccount), arg0)
+}
+
+// CreateWalletConf mocks base method.
+func (m *MockWalletClient) CreateWalletConf(arg0 string) *WalletClientConf {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWalletConf", arg0)
+	ret0, _ := ret[0].(*WalletClientConf)
+	return ret0
+}
+
+// CreateWalletConf indicates an expected call of CreateWalletConf.
+func (mr *MockWalletClientMockRecorder) CreateWalletConf(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWalletConf", reflect.TypeOf((*MockWalletClient)(nil).CreateWalletConf), arg0)
+}
+
+// Endpoint mocks base method.
+func (m *MockWalletClient) Endpoint() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Endpoint")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Endpoint indicates an expected call of Endpoint.
+func (mr *MockWalletClientMockRecorder) Endpoint() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Endpoint", reflect.TypeOf((*MockWalletClient)(nil).Endpoint))
+}
+
+// EstimateTransferFee mocks base method.
+func (m *MockWalletClient) EstimateTransferFee(arg0 *monero.Address, arg1 uint64, arg2 *coins.PiconeroAmount, arg3 types.MoneroTxPriority) (*coins.PiconeroAmount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EstimateTransferFee", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*coins.PiconeroAmount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EstimateTransferFee indicates an expected call of EstimateTransferFee.
+func (mr *MockWalletClientMockRecorder) EstimateTransferFee(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateTransferFee", reflect.TypeOf((*MockWalletClient)(nil).EstimateTransferFee), arg0, arg1, arg2, arg3)
+}
+
+// GetAccounts mocks base method.
+func (m *MockWalletClient) GetAccounts() (*wallet.GetAccountsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccounts")
+	ret0, _ := ret[0].(*wallet.GetAccountsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccounts indicates an expected call of GetAccounts.
+func (mr *MockWalletClientMockRecorder) GetAccounts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockWalletClient)(nil).GetAccounts))
+}
+
+// GetAddress mocks base method.
+func (m *MockWalletClient) GetAddress(arg0 uint64) (*wallet.GetAddressResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddress", arg0)
+	ret0, _ := ret[0].(*wallet.GetAddressResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddress indicates an expected call of GetAddress.
+func (mr *MockWalletClientMockRecorder) GetAddress(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddress", reflect.TypeOf((*MockWalletClient)(nil).GetAddress), arg0)
+}
+
+// GetBalance mocks base method.
+func (m *MockWalletClient) GetBalance(arg0 uint64) (*wallet.GetBalanceResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", arg0)
+	ret0, _ := ret[0].(*wallet.GetBalanceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockWalletClientMockRecorder) GetBalance(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWalletClient)(nil).GetBalance), arg0)
+}
+
+// GetHeight mocks base method.
+func (m *MockWalletClient) GetHeight() (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeight")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeight indicates an expected call of GetHeight.
+func (mr *MockWalletClientMockRecorder) GetHeight() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeight", reflect.TypeOf((*MockWalletClient)(nil).GetHeight))
+}
+
+// PrimaryAddress mocks base method.
+func (m *MockWalletClient) PrimaryAddress() *monero.Address {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrimaryAddress")
+	ret0, _ := ret[0].(*monero.Address)
+	return ret0
+}
+
+// PrimaryAddress indicates an expected call of PrimaryAddress.
+func (mr *MockWalletClientMockRecorder) PrimaryAddress() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrimaryAddress", reflect.TypeOf((*MockWalletClient)(nil).PrimaryAddress))
+}
+
+// SweepAll mocks base method.
+func (m *MockWalletClient) SweepAll(arg0 context.Context, arg1 *monero.Address, arg2, arg3 uint64) ([]*wallet.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SweepAll", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*wallet.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SweepAll indicates an expected call of SweepAll.
+func (mr *MockWalletClientMockRecorder) SweepAll(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SweepAll", reflect.TypeOf((*MockWalletClient)(nil).SweepAll), arg0, arg1, arg2, arg3)
+}
+
+// Transfer mocks base method.
+func (m *MockWalletClient) Transfer(arg0 context.Context, arg1 *monero.Address, arg2 uint64, arg3 *coins.PiconeroAmount, arg4 uint64, arg5 types.MoneroTxPriority) (*wallet.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*wallet.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockWalletClientMockRecorder) Transfer(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockWalletClient)(nil).Transfer), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// WalletConf mocks base method.
+func (m *MockWalletClient) WalletConf(arg0 string) *WalletClientConf {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletConf", arg0)
+	ret0, _ := ret[0].(*WalletClientConf)
+	return ret0
+}
+
+// WalletConf indicates an expected call of WalletConf.
+func (mr *MockWalletClientMockRecorder) WalletConf(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletConf", reflect.TypeOf((*MockWalletClient)(nil).WalletConf), arg0)
+}
+
+// WalletName mocks base method.
+func (m *MockWalletClient) WalletName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// WalletName indicates an expected call of WalletName.
+func (mr *MockWalletClientMockRecorder) WalletName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletName", reflect.TypeOf((*MockWalletClient)(nil).WalletName))
+}
@@ -0,0 +1,3 @@
+package monero
+
+//go:generate mockgen -destination=mocks.go -package $GOPACKAGE github.com/athanorlabs/atomic-swap/monero WalletClient
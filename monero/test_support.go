@@ -83,7 +83,7 @@ func TestBackgroundMineBlocks(t *testing.T) {
 		wg.Wait()
 	})
 	// Lower the sleep duration used by WaitForBlock
-	blockSleepDuration = backgroundMineInterval / 3
+	devBlockSleepDuration = backgroundMineInterval / 3
 	go func() {
 		defer wg.Done()
 		if !mineMu.TryLock() {
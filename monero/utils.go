@@ -2,6 +2,7 @@ package monero
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,33 +12,107 @@ import (
 )
 
 var (
-	// blockSleepDuration is the duration that we sleep between checks for new blocks. We
-	// lower it in dev environments if fast background mining is started.
-	blockSleepDuration = time.Second * 10
+	// devBlockSleepDuration, if set, overrides the block sleep duration of
+	// every client. We set it in dev environments if fast background mining is
+	// started, as it's below MinBlockSleepDuration.
+	devBlockSleepDuration time.Duration
+
+	// rpcRetryDelay is the delay before the first retry of a failed RPC call
+	// while waiting for blocks. It doubles with each later retry.
+	rpcRetryDelay = time.Second
 
 	log = logging.Logger("monero")
+
+	// ErrBlocksDeadlineReached is returned by WaitForBlocksOrDeadline when the
+	// deadline passes before the requested number of blocks arrive.
+	ErrBlocksDeadlineReached = errors.New("deadline reached before blocks arrived")
+
+	errNoWalletRPC = errors.New("wallet client has no direct wallet RPC access")
 )
 
 // WaitForBlocks waits for `count` new blocks to arrive.
-// It returns the height of the chain.
+// It returns the height of the chain. Failed RPC calls are retried up to the
+// RPCRetries of the client's config before giving up.
 func WaitForBlocks(ctx context.Context, client WalletClient, count int) (uint64, error) {
-	c := client.(*walletClient)
-	startHeight, err := c.getChainHeight()
+	c, err := unwrapWalletClient(client)
+	if err != nil {
+		return 0, err
+	}
+
+	height, err := waitForBlocks(ctx, c, count)
+	if err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+// WaitForBlocksOrDeadline is the same as WaitForBlocks, but if the deadline passes
+// before `count` new blocks arrive, it returns the last seen height of the chain
+// along with ErrBlocksDeadlineReached.
+func WaitForBlocksOrDeadline(
+	ctx context.Context,
+	client WalletClient,
+	count int,
+	deadline time.Time,
+) (uint64, error) {
+	c, err := unwrapWalletClient(client)
+	if err != nil {
+		return 0, err
+	}
+
+	deadlineCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	height, err := waitForBlocks(deadlineCtx, c, count)
+	if err != nil {
+		// only our own deadline is reported as partial progress
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return height, ErrBlocksDeadlineReached
+		}
+		return 0, err
+	}
+
+	return height, nil
+}
+
+// waitForBlocks waits for `count` new blocks to arrive, returning the height of the
+// chain. If the context is done first, the last seen height is returned with the
+// context's error.
+func waitForBlocks(ctx context.Context, c *walletClient, count int) (uint64, error) {
+	var startHeight uint64
+	err := c.retryRPC(ctx, "getting chain height", func() (err error) {
+		startHeight, err = c.getChainHeight()
+		return err
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to get height: %w", err)
 	}
 	prevHeight := startHeight - 1 // prevHeight is only for logging
 	endHeight := startHeight + uint64(count)
+	height := startHeight
 
 	for {
-		height, err := c.getChainHeight()
+		err = c.retryRPC(ctx, "getting chain height", func() error {
+			newHeight, heightErr := c.getChainHeight()
+			if heightErr != nil {
+				return heightErr
+			}
+			height = newHeight
+			return nil
+		})
 		if err != nil {
+			if ctx.Err() != nil {
+				return height, err
+			}
 			return 0, err
 		}
 
 		if height >= endHeight {
 			// ensure wallet height is refreshed to the chain height
-			if err = c.refresh(); err != nil {
+			if err = c.retryRPC(ctx, "refreshing wallet", c.refresh); err != nil {
+				if ctx.Err() != nil {
+					return height, err
+				}
 				return 0, err
 			}
 			return height, nil
@@ -48,8 +123,94 @@ func WaitForBlocks(ctx context.Context, client WalletClient, count int) (uint64,
 			prevHeight = height
 		}
 
-		if err = common.SleepWithContext(ctx, blockSleepDuration); err != nil {
-			return 0, err
+		if err = common.SleepWithContext(ctx, c.blockSleepDuration()); err != nil {
+			return height, err
+		}
+	}
+}
+
+// blockSleepDuration returns the duration that we sleep between checks for new
+// blocks, which is the BlockSleepDuration of the client's config.
+func (c *walletClient) blockSleepDuration() time.Duration {
+	if devBlockSleepDuration != 0 {
+		return devBlockSleepDuration
+	}
+
+	if c.conf == nil || c.conf.BlockSleepDuration == 0 {
+		return DefaultBlockSleepDuration
+	}
+
+	return c.conf.BlockSleepDuration
+}
+
+// rpcRetries returns the number of times that failed RPC calls are retried,
+// which is the RPCRetries of the client's config. Zero disables the retries.
+func (c *walletClient) rpcRetries() uint {
+	if c.conf == nil || c.conf.RPCRetries == nil {
+		return DefaultRPCRetries
+	}
+
+	return *c.conf.RPCRetries
+}
+
+// retryRPC calls fn, retrying failures up to RPCRetries times from the client's
+// config with an exponential backoff, so that brief wallet or daemon RPC outages
+// don't abort a swap. The error of the last attempt, or the context's error, is
+// returned once the retries are used up.
+func (c *walletClient) retryRPC(ctx context.Context, desc string, fn func() error) error {
+	delay := rpcRetryDelay
+	retries := c.rpcRetries()
+	for attempt := uint(1); ; attempt++ {
+		err := fn()
+		if err == nil || attempt > retries {
+			return err
 		}
+
+		log.Warnf("%s failed, retry %d/%d in %s: %s", desc, attempt, retries, delay, err)
+		if err = common.SleepWithContext(ctx, delay); err != nil {
+			return err
+		}
+		delay *= 2
 	}
 }
+
+// SyncStatus is the synchronisation state of the monerod node used by a wallet
+// client, and of the wallet itself.
+type SyncStatus struct {
+	DaemonSynchronised bool
+	DaemonHeight       uint64
+	TargetHeight       uint64 // height of the network, as known by monerod
+	WalletHeight       uint64
+}
+
+// GetSyncStatus refreshes the wallet and returns the synchronisation state of
+// the wallet and of the monerod node it uses.
+func GetSyncStatus(client WalletClient) (*SyncStatus, error) {
+	c, err := unwrapWalletClient(client)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := c.dRPC.GetInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monerod info: %w", err)
+	}
+
+	walletHeight, err := c.GetHeight()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh wallet: %w", err)
+	}
+
+	// monerod reports a zero target height once it's synchronised
+	targetHeight := info.TargetHeight
+	if targetHeight < info.Height {
+		targetHeight = info.Height
+	}
+
+	return &SyncStatus{
+		DaemonSynchronised: info.Synchronized,
+		DaemonHeight:       info.Height,
+		TargetHeight:       targetHeight,
+		WalletHeight:       walletHeight,
+	}, nil
+}
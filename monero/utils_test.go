@@ -2,8 +2,11 @@ package monero
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,3 +20,122 @@ func TestWaitForBlocks(t *testing.T) {
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, heightAfter-heightBefore, uint64(2))
 }
+
+func TestWaitForBlocksOrDeadline(t *testing.T) {
+	c := CreateWalletClient(t)
+
+	heightBefore, err := c.GetHeight()
+	require.NoError(t, err)
+
+	heightAfter, err := WaitForBlocksOrDeadline(context.Background(), c, 1, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, heightAfter-heightBefore, uint64(1))
+}
+
+func TestWaitForBlocksOrDeadline_deadlineReached(t *testing.T) {
+	c := CreateWalletClient(t)
+
+	heightBefore, err := c.GetHeight()
+	require.NoError(t, err)
+
+	// far more blocks than can arrive before the deadline
+	height, err := WaitForBlocksOrDeadline(context.Background(), c, 1000, time.Now().Add(time.Second))
+	require.ErrorIs(t, err, ErrBlocksDeadlineReached)
+	require.GreaterOrEqual(t, height, heightBefore)
+
+	// the caller's own context errors are returned as is
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = WaitForBlocksOrDeadline(ctx, c, 1000, time.Now().Add(time.Minute))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWaitForBlocks_noWalletRPC(t *testing.T) {
+	c := NewMockWalletClient(gomock.NewController(t))
+
+	_, err := WaitForBlocks(context.Background(), c, 1)
+	require.ErrorIs(t, err, errNoWalletRPC)
+
+	_, err = WaitForBlocksOrDeadline(context.Background(), c, 1, time.Now().Add(time.Minute))
+	require.ErrorIs(t, err, errNoWalletRPC)
+
+	_, err = GetSyncStatus(NewAuditWalletClient(c, nil))
+	require.ErrorIs(t, err, errNoWalletRPC)
+}
+
+func TestWalletClient_retryRPC(t *testing.T) {
+	origDelay := rpcRetryDelay
+	rpcRetryDelay = time.Millisecond
+	t.Cleanup(func() { rpcRetryDelay = origDelay })
+
+	retries := uint(2)
+	c := &walletClient{conf: &WalletClientConf{RPCRetries: &retries}}
+	errRPC := errors.New("connection refused")
+
+	// succeeds on the last retry
+	calls := 0
+	err := c.retryRPC(context.Background(), "test", func() error {
+		calls++
+		if calls < 3 {
+			return errRPC
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+
+	// the error is returned once the retries are used up
+	calls = 0
+	err = c.retryRPC(context.Background(), "test", func() error {
+		calls++
+		return errRPC
+	})
+	require.ErrorIs(t, err, errRPC)
+	require.Equal(t, 3, calls)
+
+	// retries stop when the context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	err = c.retryRPC(ctx, "test", func() error {
+		calls++
+		return errRPC
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, calls)
+
+	// zero disables the retries
+	retries = 0
+	calls = 0
+	err = c.retryRPC(context.Background(), "test", func() error {
+		calls++
+		return errRPC
+	})
+	require.ErrorIs(t, err, errRPC)
+	require.Equal(t, 1, calls)
+}
+
+func TestWalletClient_rpcRetries(t *testing.T) {
+	c := &walletClient{conf: &WalletClientConf{}}
+	require.Equal(t, uint(DefaultRPCRetries), c.rpcRetries())
+
+	retries := uint(0)
+	c.conf.RPCRetries = &retries
+	require.Zero(t, c.rpcRetries())
+}
+
+func TestWalletClient_blockSleepDuration(t *testing.T) {
+	origDev := devBlockSleepDuration
+	devBlockSleepDuration = 0
+	t.Cleanup(func() { devBlockSleepDuration = origDev })
+
+	c := &walletClient{conf: &WalletClientConf{}}
+	require.Equal(t, DefaultBlockSleepDuration, c.blockSleepDuration())
+
+	c.conf.BlockSleepDuration = 2 * time.Second
+	require.Equal(t, 2*time.Second, c.blockSleepDuration())
+
+	// fast background mining in dev environments overrides the config
+	devBlockSleepDuration = 100 * time.Millisecond
+	require.Equal(t, 100*time.Millisecond, c.blockSleepDuration())
+}
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path"
@@ -22,6 +24,7 @@ import (
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 )
 
@@ -35,12 +38,49 @@ const (
 	// SweepToSelfConfirmations is the number of confirmations that we wait for when
 	// sweeping funds from an A+B wallet to our primary wallet.
 	SweepToSelfConfirmations = 2
+
+	// DefaultRPCRetries is the default number of times that failed RPC calls are
+	// retried while waiting for blocks.
+	DefaultRPCRetries = 5
+
+	// DefaultWalletRPCTimeout is the default timeout of monero-wallet-rpc calls,
+	// other than refreshing the wallet.
+	DefaultWalletRPCTimeout = time.Minute
+
+	// DefaultWalletRefreshTimeout is the default timeout of refreshing the
+	// wallet. It's much longer than that of other wallet calls, as a wallet
+	// that is far behind has to scan every block that it missed.
+	DefaultWalletRefreshTimeout = 30 * time.Minute
+
+	// DefaultDaemonRPCTimeout is the default timeout of monerod RPC calls.
+	DefaultDaemonRPCTimeout = 30 * time.Second
+
+	// DefaultBlockSleepDuration is the default duration that we sleep between
+	// checks for new blocks while waiting for them.
+	DefaultBlockSleepDuration = 10 * time.Second
+
+	// MinBlockSleepDuration is the shortest configurable duration between
+	// checks for new blocks, so that we don't hammer the daemon.
+	MinBlockSleepDuration = time.Second
 )
 
+// ErrRPCTimeout is wrapped by the errors of wallet and daemon RPC calls that
+// timed out, so that a hung monerod or monero-wallet-rpc can be told apart from
+// calls that failed.
+var ErrRPCTimeout = errors.New("monero RPC call timed out")
+
+// ErrInsufficientFunds is wrapped by the error of a transfer that the wallet
+// rejected because its unlocked balance doesn't cover the amount and fee. No
+// transaction was sent when it's returned.
+var ErrInsufficientFunds = errors.New("not enough unlocked XMR for transfer")
+
+var errBlockSleepDurationTooLow = fmt.Errorf("block sleep duration must be at least %s", MinBlockSleepDuration)
+
 // WalletClient represents a monero-wallet-rpc client.
 type WalletClient interface {
 	GetAccounts() (*wallet.GetAccountsResponse, error)
 	GetAddress(idx uint64) (*wallet.GetAddressResponse, error)
+	CreateAccount(label string) (uint64, *mcrypto.Address, error)
 	PrimaryAddress() *mcrypto.Address
 	GetBalance(idx uint64) (*wallet.GetBalanceResponse, error)
 	Transfer(
@@ -49,6 +89,7 @@ type WalletClient interface {
 		accountIdx uint64,
 		amount *coins.PiconeroAmount,
 		numConfirmations uint64,
+		priority types.MoneroTxPriority,
 	) (*wallet.Transfer, error)
 	SweepAll(
 		ctx context.Context,
@@ -56,7 +97,14 @@ type WalletClient interface {
 		accountIdx uint64,
 		numConfirmations uint64,
 	) ([]*wallet.Transfer, error)
+	EstimateTransferFee(
+		to *mcrypto.Address,
+		accountIdx uint64,
+		amount *coins.PiconeroAmount,
+		priority types.MoneroTxPriority,
+	) (*coins.PiconeroAmount, error)
 	CreateWalletConf(walletNamePrefix string) *WalletClientConf
+	WalletConf(walletName string) *WalletClientConf
 	WalletName() string
 	GetHeight() (uint64, error)
 	Endpoint() string // URL on which the wallet is accepting RPC requests
@@ -73,10 +121,16 @@ type WalletClientConf struct {
 	MonerodNodes        []*common.MoneroNode // Optional, defaulted from environment if nil
 	MoneroWalletRPCPath string               // optional, path to monero-rpc-binary
 	LogPath             string               // optional, default is dir(WalletFilePath)/../monero-wallet-rpc.log
+	RPCRetries          *uint                // optional, default is DefaultRPCRetries, see WaitForBlocks
+	WalletRPCTimeout    time.Duration        // optional, default is DefaultWalletRPCTimeout
+	RefreshTimeout      time.Duration        // optional, default is DefaultWalletRefreshTimeout
+	DaemonRPCTimeout    time.Duration        // optional, default is DefaultDaemonRPCTimeout
+	BlockSleepDuration  time.Duration        // optional, default is DefaultBlockSleepDuration, see WaitForBlocks
+	AuditLog            *AuditLog            // optional, wallet operations are recorded to it if set
 }
 
 // Fill fills in the optional configuration values (Port, MonerodNodes, MoneroWalletRPCPath,
-// and LogPath) if they are not set.
+// LogPath, the RPC timeouts and BlockSleepDuration) if they are not set.
 // Note: MonerodNodes is set to the first validated node.
 func (conf *WalletClientConf) Fill() error {
 	if conf.WalletFilePath == "" {
@@ -113,6 +167,24 @@ func (conf *WalletClientConf) Fill() error {
 		}
 	}
 
+	if conf.WalletRPCTimeout == 0 {
+		conf.WalletRPCTimeout = DefaultWalletRPCTimeout
+	}
+
+	if conf.RefreshTimeout == 0 {
+		conf.RefreshTimeout = DefaultWalletRefreshTimeout
+	}
+
+	if conf.DaemonRPCTimeout == 0 {
+		conf.DaemonRPCTimeout = DefaultDaemonRPCTimeout
+	}
+
+	if conf.BlockSleepDuration == 0 {
+		conf.BlockSleepDuration = DefaultBlockSleepDuration
+	} else if conf.BlockSleepDuration < MinBlockSleepDuration {
+		return fmt.Errorf("%w: %s", errBlockSleepDurationTooLow, conf.BlockSleepDuration)
+	}
+
 	return nil
 }
 
@@ -126,6 +198,7 @@ type waitForReceiptRequest struct {
 
 type walletClient struct {
 	wRPC       wallet.Wallet       // full monero-wallet-rpc API (larger than the WalletClient interface)
+	wRefresh   wallet.Wallet       // monero-wallet-rpc API with the longer timeout of refreshes
 	dRPC       monerodaemon.Daemon // full monerod RPC API
 	endpoint   string
 	walletAddr *mcrypto.Address
@@ -163,7 +236,8 @@ func NewWalletClient(conf *WalletClientConf) (WalletClient, error) {
 		return nil, err
 	}
 
-	c := NewThinWalletClient(validatedNode.Host, validatedNode.Port, conf.WalletPort).(*walletClient)
+	c := newThinWalletClient(validatedNode.Host, validatedNode.Port, conf.WalletPort,
+		conf.WalletRPCTimeout, conf.RefreshTimeout, conf.DaemonRPCTimeout)
 	c.rpcProcess = proc
 
 	walletName := path.Base(conf.WalletFilePath)
@@ -196,20 +270,68 @@ func NewWalletClient(conf *WalletClientConf) (WalletClient, error) {
 	}
 
 	c.conf = conf
-	return c, nil
+	return withAuditLog(c, conf.AuditLog), nil
 }
 
 // NewThinWalletClient returns a WalletClient for an existing monero-wallet-rpc process.
+// RPC calls use the default timeouts.
 func NewThinWalletClient(monerodHost string, monerodPort uint, walletPort uint) WalletClient {
+	return newThinWalletClient(monerodHost, monerodPort, walletPort,
+		DefaultWalletRPCTimeout, DefaultWalletRefreshTimeout, DefaultDaemonRPCTimeout)
+}
+
+// newThinWalletClient returns a walletClient whose wallet refreshes time out
+// after refreshTimeout, and whose other wallet and daemon calls time out after
+// walletTimeout and daemonTimeout.
+func newThinWalletClient(
+	monerodHost string,
+	monerodPort uint,
+	walletPort uint,
+	walletTimeout time.Duration,
+	refreshTimeout time.Duration,
+	daemonTimeout time.Duration,
+) *walletClient {
 	monerodEndpoint := fmt.Sprintf("http://%s:%d/json_rpc", monerodHost, monerodPort)
 	walletEndpoint := fmt.Sprintf("http://127.0.0.1:%d/json_rpc", walletPort)
 	return &walletClient{
-		dRPC:     monerorpc.New(monerodEndpoint, nil).Daemon,
-		wRPC:     monerorpc.New(walletEndpoint, nil).Wallet,
+		dRPC:     monerorpc.New(monerodEndpoint, &http.Client{Timeout: daemonTimeout}).Daemon,
+		wRPC:     monerorpc.New(walletEndpoint, &http.Client{Timeout: walletTimeout}).Wallet,
+		wRefresh: monerorpc.New(walletEndpoint, &http.Client{Timeout: refreshTimeout}).Wallet,
 		endpoint: walletEndpoint,
 	}
 }
 
+// wrapTimeout wraps the error of an RPC call with ErrRPCTimeout if the call
+// timed out.
+func wrapTimeout(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var netErr net.Error
+	if (errors.As(err, &netErr) && netErr.Timeout()) || strings.Contains(err.Error(), "Client.Timeout exceeded") {
+		return fmt.Errorf("%w: %s", ErrRPCTimeout, err)
+	}
+
+	return err
+}
+
+// wrapInsufficientFunds wraps the error of a transfer with ErrInsufficientFunds
+// if the wallet rejected it for a lack of (unlocked) funds. monero-wallet-rpc
+// only tells us this in the error message.
+func wrapInsufficientFunds(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	if strings.Contains(msg, "not enough money") || strings.Contains(msg, "not enough unlocked money") {
+		return fmt.Errorf("%w: %s", ErrInsufficientFunds, err)
+	}
+
+	return err
+}
+
 func (c *walletClient) WalletName() string {
 	return path.Base(c.conf.WalletFilePath)
 }
@@ -222,9 +344,10 @@ func (c *walletClient) GetBalance(idx uint64) (*wallet.GetBalanceResponse, error
 	if err := c.refresh(); err != nil {
 		return nil, err
 	}
-	return c.wRPC.GetBalance(&wallet.GetBalanceRequest{
+	resp, err := c.wRPC.GetBalance(&wallet.GetBalanceRequest{
 		AccountIndex: idx,
 	})
+	return resp, wrapTimeout(err)
 }
 
 // waitForReceipt waits for the passed monero transaction ID to receive numConfirmations
@@ -247,7 +370,7 @@ func (c *walletClient) waitForReceipt(req *waitForReceiptRequest) (*wallet.Trans
 			AccountIndex: req.AccountIdx,
 		})
 		if err != nil {
-			return nil, err
+			return nil, wrapTimeout(err)
 		}
 
 		transfer = &transferResp.Transfer
@@ -276,23 +399,25 @@ func (c *walletClient) Transfer(
 	accountIdx uint64,
 	amount *coins.PiconeroAmount,
 	numConfirmations uint64,
+	priority types.MoneroTxPriority,
 ) (*wallet.Transfer, error) {
 	amt, err := amount.Uint64()
 	if err != nil {
 		return nil, err
 	}
 	amountStr := amount.AsMoneroString()
-	log.Infof("Transferring %s XMR to %s", amountStr, to)
+	log.Infof("Transferring %s XMR to %s with %s priority", amountStr, to, priority)
 	reqResp, err := c.wRPC.Transfer(&wallet.TransferRequest{
 		Destinations: []wallet.Destination{{
 			Amount:  amt,
 			Address: to.String(),
 		}},
 		AccountIndex: accountIdx,
+		Priority:     uint64(priority),
 	})
 	if err != nil {
 		log.Warnf("Transfer of %s XMR failed: %s", amountStr, err)
-		return nil, fmt.Errorf("transfer failed: %w", err)
+		return nil, fmt.Errorf("transfer failed: %w", wrapInsufficientFunds(wrapTimeout(err)))
 	}
 	log.Infof("Transfer of %s XMR initiated, TXID=%s", amountStr, reqResp.TxHash)
 	transfer, err := c.waitForReceipt(&waitForReceiptRequest{
@@ -312,6 +437,39 @@ func (c *walletClient) Transfer(
 	return transfer, nil
 }
 
+// EstimateTransferFee returns the network fee that a transfer of the given amount would
+// currently pay with the given priority. The transfer is constructed by the wallet, but
+// never relayed.
+func (c *walletClient) EstimateTransferFee(
+	to *mcrypto.Address,
+	accountIdx uint64,
+	amount *coins.PiconeroAmount,
+	priority types.MoneroTxPriority,
+) (*coins.PiconeroAmount, error) {
+	amt, err := amount.Uint64()
+	if err != nil {
+		return nil, err
+	}
+
+	// Without the tx metadata or blob, the created transaction can't be relayed later
+	// either, so there's no way for this call to spend our funds.
+	reqResp, err := c.wRPC.Transfer(&wallet.TransferRequest{
+		Destinations: []wallet.Destination{{
+			Amount:  amt,
+			Address: to.String(),
+		}},
+		AccountIndex: accountIdx,
+		Priority:     uint64(priority),
+		DoNotRelay:   true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate fee of %s XMR transfer: %w",
+			amount.AsMoneroString(), wrapInsufficientFunds(err))
+	}
+
+	return coins.NewPiconeroAmount(reqResp.Fee), nil
+}
+
 func (c *walletClient) SweepAll(
 	ctx context.Context,
 	to *mcrypto.Address,
@@ -344,7 +502,7 @@ func (c *walletClient) SweepAll(
 		Address:      to.String(),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("sweep_all from %s failed: %w", from, err)
+		return nil, fmt.Errorf("sweep_all from %s failed: %w", from, wrapTimeout(err))
 	}
 	log.Infof("Sweep transaction started, TX IDs: %s", strings.Join(reqResp.TxHashList, ", "))
 
@@ -373,6 +531,13 @@ func (c *walletClient) SweepAll(
 
 func (c *walletClient) CreateWalletConf(walletNamePrefix string) *WalletClientConf {
 	walletName := fmt.Sprintf("%s-%s", walletNamePrefix, time.Now().Format(common.TimeFmtNSecs))
+	return c.WalletConf(walletName)
+}
+
+// WalletConf returns the configuration for a wallet with the given name, located in
+// the same directory as our primary wallet. Passing the returned config to
+// NewWalletClient opens the wallet, creating it first if it does not exist.
+func (c *walletClient) WalletConf(walletName string) *WalletClientConf {
 	walletPath := path.Join(path.Dir(c.conf.WalletFilePath), walletName)
 	conf := &WalletClientConf{
 		Env:                 c.conf.Env,
@@ -382,6 +547,12 @@ func (c *walletClient) CreateWalletConf(walletNamePrefix string) *WalletClientCo
 		MonerodNodes:        c.conf.MonerodNodes,
 		MoneroWalletRPCPath: c.conf.MoneroWalletRPCPath,
 		LogPath:             c.conf.LogPath,
+		RPCRetries:          c.conf.RPCRetries,
+		WalletRPCTimeout:    c.conf.WalletRPCTimeout,
+		RefreshTimeout:      c.conf.RefreshTimeout,
+		DaemonRPCTimeout:    c.conf.DaemonRPCTimeout,
+		BlockSleepDuration:  c.conf.BlockSleepDuration,
+		AuditLog:            c.conf.AuditLog,
 	}
 	return conf
 }
@@ -415,7 +586,8 @@ func createWalletFromKeys(
 		return nil, err
 	}
 
-	c := NewThinWalletClient(monerodNode.Host, monerodNode.Port, conf.WalletPort).(*walletClient)
+	c := newThinWalletClient(monerodNode.Host, monerodNode.Port, conf.WalletPort,
+		conf.WalletRPCTimeout, conf.RefreshTimeout, conf.DaemonRPCTimeout)
 	c.rpcProcess = proc
 	c.conf = conf
 	err = c.generateFromKeys(
@@ -461,7 +633,7 @@ func createWalletFromKeys(
 		bal.BlocksToUnlock,
 		c.PrimaryAddress(),
 	)
-	return c, nil
+	return withAuditLog(c, conf.AuditLog), nil
 }
 
 // CreateSpendWalletFromKeys creates a new monero-wallet-rpc process, wallet client and
@@ -535,9 +707,27 @@ func (c *walletClient) GetAddress(idx uint64) (*wallet.GetAddressResponse, error
 	})
 }
 
+// CreateAccount creates a new account in the wallet with the given label,
+// returning the account's index and primary address.
+func (c *walletClient) CreateAccount(label string) (uint64, *mcrypto.Address, error) {
+	resp, err := c.wRPC.CreateAccount(&wallet.CreateAccountRequest{
+		Label: label,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	addr, err := mcrypto.NewAddress(resp.Address, c.conf.Env)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return resp.AccountIndex, addr, nil
+}
+
 func (c *walletClient) refresh() error {
-	_, err := c.wRPC.Refresh(&wallet.RefreshRequest{})
-	return err
+	_, err := c.wRefresh.Refresh(&wallet.RefreshRequest{})
+	return wrapTimeout(err)
 }
 
 func (c *walletClient) CreateWallet(filename, password string) error {
@@ -563,7 +753,7 @@ func (c *walletClient) GetHeight() (uint64, error) {
 
 	res, err := c.wRPC.GetHeight()
 	if err != nil {
-		return 0, err
+		return 0, wrapTimeout(err)
 	}
 
 	return res.Height, nil
@@ -574,7 +764,7 @@ func (c *walletClient) GetHeight() (uint64, error) {
 func (c *walletClient) getChainHeight() (uint64, error) {
 	res, err := c.dRPC.GetBlockCount()
 	if err != nil {
-		return 0, err
+		return 0, wrapTimeout(err)
 	}
 
 	return res.Count, nil
@@ -2,8 +2,13 @@ package monero
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"testing"
 	"time"
 
@@ -13,6 +18,7 @@ import (
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 )
 
@@ -51,7 +57,7 @@ func TestClient_Transfer(t *testing.T) {
 	vkABPriv := mcrypto.SumPrivateViewKeys(kpA.ViewKey(), kpB.ViewKey())
 
 	// Transfer from Bob's account to the Alice+Bob swap account
-	transfer, err := cXMRMaker.Transfer(ctx, abAddress, 0, transferAmt, MinSpendConfirmations)
+	transfer, err := cXMRMaker.Transfer(ctx, abAddress, 0, transferAmt, MinSpendConfirmations, types.MoneroPriorityDefault)
 	require.NoError(t, err)
 	t.Logf("Bob sent %s (+fee %s) XMR to A+B address with TX ID %s",
 		coins.FmtPiconeroAsXMR(transfer.Amount),
@@ -133,6 +139,31 @@ func TestClient_Transfer(t *testing.T) {
 	require.Equal(t, balanceAlice.Balance, sweepAmount)
 }
 
+func TestClient_EstimateTransferFee(t *testing.T) {
+	amount := coins.MoneroToPiconero(coins.StrToDecimal("1"))
+	c := CreateWalletClient(t)
+	MineMinXMRBalance(t, c, coins.MoneroToPiconero(coins.StrToDecimal("1.01")))
+	balanceBefore := GetBalance(t, c)
+
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	dest := kp.PublicKeyPair().Address(common.Development)
+
+	fee, err := c.EstimateTransferFee(dest, 0, amount, types.MoneroPriorityDefault)
+	require.NoError(t, err)
+	require.Positive(t, fee.CmpU64(0))
+
+	// the fee is estimated for the passed priority
+	highFee, err := c.EstimateTransferFee(dest, 0, amount, types.MoneroPriorityHigh)
+	require.NoError(t, err)
+	require.Positive(t, highFee.Cmp(fee))
+
+	// the estimate must not have spent anything
+	balanceAfter := GetBalance(t, c)
+	require.Equal(t, balanceBefore.Balance, balanceAfter.Balance)
+	require.Equal(t, balanceBefore.UnlockedBalance, balanceAfter.UnlockedBalance)
+}
+
 func Test_walletClient_SweepAll_nothingToSweepReturnsError(t *testing.T) {
 	emptyWallet := CreateWalletClient(t)
 	takerWallet := CreateWalletClient(t)
@@ -165,6 +196,23 @@ func TestClient_CloseAndRemoveWallet(t *testing.T) {
 	require.ErrorIs(t, err, os.ErrNotExist)
 }
 
+func TestClient_WalletConf_reopensWallet(t *testing.T) {
+	c := CreateWalletClient(t)
+
+	conf := c.WalletConf("test-swap-wallet")
+	require.Equal(t, path.Dir(conf.WalletFilePath), path.Dir(c.(*walletClient).conf.WalletFilePath))
+	swapCli, err := NewWalletClient(conf)
+	require.NoError(t, err)
+	addr := swapCli.PrimaryAddress()
+	swapCli.Close()
+
+	// opening the same named wallet again gives us the same wallet
+	swapCli, err = NewWalletClient(c.WalletConf("test-swap-wallet"))
+	require.NoError(t, err)
+	defer swapCli.CloseAndRemoveWallet()
+	require.Equal(t, addr, swapCli.PrimaryAddress())
+}
+
 func TestClient_GetAccounts(t *testing.T) {
 	c, err := NewWalletClient(&WalletClientConf{
 		Env:                 common.Development,
@@ -338,7 +386,14 @@ func Test_walletClient_waitForConfirmations_contextCancelled(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err = c.Transfer(ctx, destAddr, 0, coins.NewPiconeroAmount(amount), numConfirmations)
+	_, err = c.Transfer(
+		ctx,
+		destAddr,
+		0,
+		coins.NewPiconeroAmount(amount),
+		numConfirmations,
+		types.MoneroPriorityDefault,
+	)
 	require.ErrorIs(t, err, context.DeadlineExceeded)
 }
 
@@ -364,3 +419,50 @@ func TestCreateWalletFromKeys(t *testing.T) {
 	defer abCli.CloseAndRemoveWallet()
 	require.Equal(t, kp.PublicKeyPair().Address(common.Development).String(), abCli.PrimaryAddress().String())
 }
+
+func TestWalletClient_rpcTimeout(t *testing.T) {
+	// the server answers every call after a delay that is longer than the
+	// timeout of normal calls, but shorter than that of refreshes
+	const delay = 300 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":{}}`))
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.ParseUint(serverURL.Port(), 10, 16)
+	require.NoError(t, err)
+
+	c := newThinWalletClient("127.0.0.1", uint(port), uint(port),
+		100*time.Millisecond, 10*delay, 100*time.Millisecond)
+	require.NoError(t, c.refresh())
+	// GetHeight's refresh succeeds, but the height call times out
+	_, err = c.GetHeight()
+	require.ErrorIs(t, err, ErrRPCTimeout)
+	_, err = c.getChainHeight()
+	require.ErrorIs(t, err, ErrRPCTimeout)
+
+	c = newThinWalletClient("127.0.0.1", uint(port), uint(port),
+		100*time.Millisecond, 100*time.Millisecond, 100*time.Millisecond)
+	require.ErrorIs(t, c.refresh(), ErrRPCTimeout)
+}
+
+func TestWrapTimeout(t *testing.T) {
+	require.NoError(t, wrapTimeout(nil))
+	err := errors.New("some failure")
+	require.Equal(t, err, wrapTimeout(err))
+	require.ErrorIs(t, wrapTimeout(context.DeadlineExceeded), ErrRPCTimeout)
+}
+
+func TestWrapInsufficientFunds(t *testing.T) {
+	require.NoError(t, wrapInsufficientFunds(nil))
+	err := errors.New("some failure")
+	require.Equal(t, err, wrapInsufficientFunds(err))
+	require.ErrorIs(t, wrapInsufficientFunds(errors.New("not enough money")), ErrInsufficientFunds)
+	require.ErrorIs(t, wrapInsufficientFunds(errors.New("not enough unlocked money")), ErrInsufficientFunds)
+}
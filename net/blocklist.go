@@ -0,0 +1,48 @@
+package net
+
+import (
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// Blocklist is a persisted set of peers that we refuse to swap with or relay
+// claims for. It is implemented by *db.Blocklist.
+type Blocklist interface {
+	Add(id peer.ID) error
+	Remove(id peer.ID) error
+	IsBlocked(id peer.ID) (bool, error)
+}
+
+// BlockPeer adds the peer to the blocklist. Swap and relay streams from the peer
+// are closed as soon as they are opened.
+func (h *Host) BlockPeer(id peer.ID) error {
+	if h.blocklist == nil {
+		return errNoBlocklist
+	}
+
+	return h.blocklist.Add(id)
+}
+
+// UnblockPeer removes the peer from the blocklist.
+func (h *Host) UnblockPeer(id peer.ID) error {
+	if h.blocklist == nil {
+		return errNoBlocklist
+	}
+
+	return h.blocklist.Remove(id)
+}
+
+// isBlocked returns whether the peer is on the blocklist. If the blocklist
+// can't be read, the peer is treated as not blocked.
+func (h *Host) isBlocked(id peer.ID) bool {
+	if h.blocklist == nil {
+		return false
+	}
+
+	blocked, err := h.blocklist.IsBlocked(id)
+	if err != nil {
+		log.Warnf("failed to check blocklist for peer %s: %s", id, err)
+		return false
+	}
+
+	return blocked
+}
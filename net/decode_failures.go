@@ -0,0 +1,78 @@
+package net
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// DefaultMaxDecodeFailures is the default number of malformed messages that we
+// tolerate from a single peer before dropping its streams.
+const DefaultMaxDecodeFailures = 3
+
+// decodeFailureTracker counts, per peer, the messages that failed to decode
+// since startup. A peer is tolerated, ie. we log the failure and keep reading
+// from the stream, until its count exceeds maxFailures.
+type decodeFailureTracker struct {
+	mu          sync.Mutex
+	maxFailures uint
+	counts      map[peer.ID]uint
+}
+
+func newDecodeFailureTracker(maxFailures uint) *decodeFailureTracker {
+	return &decodeFailureTracker{
+		maxFailures: maxFailures,
+		counts:      make(map[peer.ID]uint),
+	}
+}
+
+// record counts a decode failure from the peer and returns whether the peer is
+// still tolerated.
+func (t *decodeFailureTracker) record(id peer.ID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.counts[id]++
+	return t.counts[id] <= t.maxFailures
+}
+
+// snapshot returns a copy of the per-peer decode failure counts.
+func (t *decodeFailureTracker) snapshot() map[peer.ID]uint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	counts := make(map[peer.ID]uint, len(t.counts))
+	for id, n := range t.counts {
+		counts[id] = n
+	}
+
+	return counts
+}
+
+// DecodeFailures returns the number of messages from each peer that failed to
+// decode since startup. Peers whose messages always decoded are not included.
+func (h *Host) DecodeFailures() map[peer.ID]uint {
+	return h.decodeFailures.snapshot()
+}
+
+// handleDecodeFailure records that a message from the peer failed to decode
+// and returns whether we should keep reading from the peer's stream. Once the
+// peer is no longer tolerated, it is also blocklisted if so configured.
+func (h *Host) handleDecodeFailure(id peer.ID, err error) bool {
+	h.penalizeProtocolViolation(id)
+
+	if h.decodeFailures.record(id) {
+		log.Warnf("ignoring malformed message from peer %s: %s", id, err)
+		return true
+	}
+
+	log.Warnf("dropping peer %s after too many malformed messages: %s", id, err)
+
+	if h.blockOnDecodeFailures {
+		if err = h.BlockPeer(id); err != nil {
+			log.Warnf("failed to block peer %s: %s", id, err)
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,47 @@
+package net
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHost_handleDecodeFailure(t *testing.T) {
+	bl := newMockBlocklist()
+	h := &Host{
+		blocklist:             bl,
+		decodeFailures:        newDecodeFailureTracker(2),
+		blockOnDecodeFailures: true,
+	}
+
+	malicious := peer.ID("malicious")
+	buggy := peer.ID("buggy")
+	decodeErr := errors.New("bad message")
+
+	// the first failures are tolerated
+	require.True(t, h.handleDecodeFailure(malicious, decodeErr))
+	require.True(t, h.handleDecodeFailure(malicious, decodeErr))
+	require.True(t, h.handleDecodeFailure(buggy, decodeErr))
+	require.False(t, h.isBlocked(malicious))
+
+	// one too many drops and blocks the peer
+	require.False(t, h.handleDecodeFailure(malicious, decodeErr))
+	require.True(t, h.isBlocked(malicious))
+	require.False(t, h.isBlocked(buggy))
+
+	require.Equal(t, map[peer.ID]uint{malicious: 3, buggy: 1}, h.DecodeFailures())
+}
+
+func TestHost_handleDecodeFailure_noBlock(t *testing.T) {
+	bl := newMockBlocklist()
+	h := &Host{
+		blocklist:      bl,
+		decodeFailures: newDecodeFailureTracker(0),
+	}
+
+	id := peer.ID("peer")
+	require.False(t, h.handleDecodeFailure(id, errors.New("bad message")))
+	require.False(t, h.isBlocked(id))
+}
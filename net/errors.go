@@ -4,8 +4,29 @@ import (
 	"errors"
 )
 
+// ErrRelayerRejectedClaim is wrapped by the errors of SubmitClaimToRelayer when
+// the relayer refused to relay the claim, eg. because the request failed its
+// validation. Unlike rate limited or busy relayers, or relayers that failed to
+// relay a valid claim, retrying won't help.
+var ErrRelayerRejectedClaim = errors.New("relayer rejected claim request")
+
 var (
 	errNilHandler            = errors.New("handler is nil")
+	errBootnodeRelayer       = errors.New("bootnode-only hosts can't be relayers")
 	errNoOngoingSwap         = errors.New("no swap currently happening")
 	errSwapAlreadyInProgress = errors.New("already have ongoing swap")
+	errRelayRateLimited      = errors.New("relay request rate limit exceeded, try again later")
+	errRelayQueueFull        = errors.New("relayer is busy, try again later")
+	errNoBlocklist           = errors.New("peer blocklist is not configured")
+	errMessageDecode         = errors.New("failed to decode message")
+	errIncompatibleVersion   = errors.New("incompatible swap protocol version")
+	errVersionFeature        = errors.New("swap message uses a feature of a later swap protocol version")
+	errResumeNotSupported    = errors.New("swap protocol version does not support reopening swap streams")
+	errResumeMessageCount    = errors.New("peer's count of received swap messages does not match ours")
+	errUnknownScoreSignal    = errors.New("unknown peer score signal")
+	errResumeWrongPeer       = errors.New("swap is with a different peer")
+	errInvalidListenAddr     = errors.New("invalid listen address")
+	errNoConnManager         = errors.New("peer score weights are set, but the p2p host has no connection manager")
+	errInvalidAnnounceAddr   = errors.New("invalid announce address")
+	errNoAddrsFactory        = errors.New("announce addresses are set, but the p2p host can't override its addresses")
 )
@@ -4,11 +4,15 @@ package net
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	p2pnet "github.com/athanorlabs/go-p2p-net"
 	logging "github.com/ipfs/go-log"
+	"github.com/libp2p/go-libp2p/core/connmgr"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
@@ -56,9 +60,60 @@ type Host struct {
 	h         P2pHost
 	isRelayer bool
 
+	// bootnodeOnly hosts only take part in the DHT, so they have no maker or
+	// taker handlers
+	bootnodeOnly bool
+
+	// relayLimiter rate limits relay claim requests per peer
+	relayLimiter *peerRateLimiter
+	// relayPool limits the number of relay claim requests handled at once
+	relayPool *relayWorkerPool
+
+	// blocklist holds the peers whose swap and relay streams we close
+	blocklist Blocklist
+
+	// decodeFailures counts malformed messages per peer, and decides when
+	// to stop tolerating them
+	decodeFailures        *decodeFailureTracker
+	blockOnDecodeFailures bool
+
+	// connManager is the libp2p connection manager that peer scores are fed
+	// into, or nil if the P2pHost doesn't expose one
+	connManager  connmgr.ConnManager
+	scoreWeights PeerScoreWeights
+
+	// relayers caches discovered relayers, which are rediscovered every
+	// relayerRefreshInterval
+	relayers               *relayerCache
+	relayerRefreshInterval time.Duration
+
+	// offerSigningKey signs the offers we send to peers. Bootnode-only hosts,
+	// which send no offers, run without it if it couldn't be loaded.
+	offerSigningKey libp2pcrypto.PrivKey
+
+	// acceptUnsignedOffers keeps the unsigned offers that peers reply with,
+	// instead of dropping them
+	acceptUnsignedOffers bool
+
 	makerHandler MakerHandler
 	takerHandler TakerHandler
 
+	// swapVersions are the swap protocol versions we advertise in the version
+	// handshake of swap streams
+	swapVersions []uint16
+
+	// offerBookConcurrency, offerBookPeerTimeout and offerBookTimeout bound
+	// the peer queries of FetchAggregatedOffers
+	offerBookConcurrency int
+	offerBookPeerTimeout time.Duration
+	offerBookTimeout     time.Duration
+
+	// swapResumeWindow is how long we wait for a dropped swap stream to be
+	// reopened, and swapResumeRetries how many times we try to reopen the
+	// swap streams that we opened
+	swapResumeWindow  time.Duration
+	swapResumeRetries uint
+
 	// swap instance info
 	swapMu sync.Mutex
 	swaps  map[types.Hash]*swap
@@ -74,34 +129,229 @@ type Config struct {
 	ProtocolID string
 	ListenIP   string
 	IsRelayer  bool
+
+	// ListenAddrs, if set, are the /ip4/<ip>/tcp/<port> multiaddrs that the
+	// host listens on, instead of ListenIP and Port. They must share an IP and
+	// a port.
+	ListenAddrs []ma.Multiaddr
+
+	// AnnounceAddrs, if set, are the multiaddrs that the host announces to
+	// peers instead of the addresses it listens on, eg. its public address
+	// behind NAT or a port forward. They need a p2p host whose addresses can
+	// be overridden, otherwise NewHost fails.
+	AnnounceAddrs []ma.Multiaddr
+
+	// BootnodeOnly runs the host only as a DHT bootnode, helping peers find
+	// each other and relayers, without making or taking swaps. SetHandlers
+	// doesn't need to be called, and the host can't be a relayer.
+	BootnodeOnly bool
+
+	// RelayerRequestsPerSec and RelayerRequestBurst configure the per-peer token
+	// bucket limiting relay claim requests. Zero values use the defaults.
+	RelayerRequestsPerSec float64
+	RelayerRequestBurst   uint
+
+	// RelayerMaxConcurrent and RelayerMaxQueued limit how many relay claim
+	// requests are handled at once, and how many more can wait for a free
+	// worker. Zero values use the defaults.
+	RelayerMaxConcurrent uint
+	RelayerMaxQueued     uint
+
+	// Blocklist is optional; if set, swaps and relay requests from blocked
+	// peers are rejected.
+	Blocklist Blocklist
+
+	// MaxDecodeFailures is how many malformed messages we tolerate from a
+	// peer, by logging them and reading the peer's next message, before we
+	// close its streams. Nil uses the default, and zero drops a peer on its
+	// first malformed message. If BlockOnDecodeFailures is set, peers that
+	// exceed it are also added to the blocklist.
+	MaxDecodeFailures     *uint
+	BlockOnDecodeFailures bool
+
+	// RelayerRefreshInterval is how often discovered relayers are refreshed in
+	// the background. Zero uses the default.
+	RelayerRefreshInterval time.Duration
+
+	// OfferBookConcurrency is how many peers FetchAggregatedOffers queries at
+	// once, OfferBookPeerTimeout how long it waits for each peer's reply, and
+	// OfferBookTimeout how long it takes at most. Zero values use the defaults.
+	OfferBookConcurrency uint
+	OfferBookPeerTimeout time.Duration
+	OfferBookTimeout     time.Duration
+
+	// PeerScoreWeights are how much swap outcomes, protocol violations and
+	// relayed claims change a peer's score in the connection manager. Nil
+	// uses DefaultPeerScoreWeights, or disables peer scores with a warning if
+	// the p2p host has no connection manager. If set, NewHost fails without
+	// one.
+	PeerScoreWeights *PeerScoreWeights
+
+	// SwapResumeWindow is how long an ongoing swap waits for its stream to be
+	// reopened if it drops, before the swap exits. SwapResumeRetries is how
+	// many times the side that opened the stream tries to reopen it within the
+	// window. Zero values use the defaults.
+	SwapResumeWindow  time.Duration
+	SwapResumeRetries uint
+
+	// AcceptUnsignedOffers keeps the unsigned offers of makers that predate
+	// offer signing. Unsigned offers can't be attributed to the peer that
+	// sent them, so by default they're dropped.
+	AcceptUnsignedOffers bool
 }
 
 // NewHost returns a new Host.
 // The host implemented in this package is swap-specific; ie. it supports swap-specific
 // messages (initiate and query).
 func NewHost(cfg *Config) (*Host, error) {
+	if cfg.BootnodeOnly && cfg.IsRelayer {
+		return nil, errBootnodeRelayer
+	}
+
+	listenIP, port := cfg.ListenIP, cfg.Port
+	if len(cfg.ListenAddrs) > 0 {
+		var err error
+		listenIP, port, err = listenIPAndPort(cfg.ListenAddrs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkAnnounceAddrs(cfg.AnnounceAddrs); err != nil {
+		return nil, err
+	}
+
+	relayRate := cfg.RelayerRequestsPerSec
+	if relayRate == 0 {
+		relayRate = DefaultRelayerRequestsPerSec
+	}
+
+	relayBurst := cfg.RelayerRequestBurst
+	if relayBurst == 0 {
+		relayBurst = DefaultRelayerRequestBurst
+	}
+
+	relayMaxConcurrent := cfg.RelayerMaxConcurrent
+	if relayMaxConcurrent == 0 {
+		relayMaxConcurrent = DefaultRelayerMaxConcurrent
+	}
+
+	relayMaxQueued := cfg.RelayerMaxQueued
+	if relayMaxQueued == 0 {
+		relayMaxQueued = DefaultRelayerMaxQueued
+	}
+
+	maxDecodeFailures := uint(DefaultMaxDecodeFailures)
+	if cfg.MaxDecodeFailures != nil {
+		maxDecodeFailures = *cfg.MaxDecodeFailures
+	}
+
+	relayerRefreshInterval := cfg.RelayerRefreshInterval
+	if relayerRefreshInterval == 0 {
+		relayerRefreshInterval = DefaultRelayerRefreshInterval
+	}
+
+	offerBookConcurrency := cfg.OfferBookConcurrency
+	if offerBookConcurrency == 0 {
+		offerBookConcurrency = DefaultOfferBookConcurrency
+	}
+
+	offerBookPeerTimeout := cfg.OfferBookPeerTimeout
+	if offerBookPeerTimeout == 0 {
+		offerBookPeerTimeout = DefaultOfferBookPeerTimeout
+	}
+
+	offerBookTimeout := cfg.OfferBookTimeout
+	if offerBookTimeout == 0 {
+		offerBookTimeout = DefaultOfferBookTimeout
+	}
+
+	swapResumeWindow := cfg.SwapResumeWindow
+	if swapResumeWindow == 0 {
+		swapResumeWindow = DefaultSwapResumeWindow
+	}
+
+	swapResumeRetries := cfg.SwapResumeRetries
+	if swapResumeRetries == 0 {
+		swapResumeRetries = DefaultSwapResumeRetries
+	}
+
+	scoreWeights := DefaultPeerScoreWeights
+	if cfg.PeerScoreWeights != nil {
+		scoreWeights = *cfg.PeerScoreWeights
+	}
+
 	h := &Host{
-		ctx:       cfg.Ctx,
-		h:         nil, // set below
-		isRelayer: cfg.IsRelayer,
-		swaps:     make(map[types.Hash]*swap),
+		ctx:          cfg.Ctx,
+		h:            nil, // set below
+		isRelayer:    cfg.IsRelayer,
+		bootnodeOnly: cfg.BootnodeOnly,
+		relayLimiter: newPeerRateLimiter(relayRate, relayBurst),
+		relayPool:    newRelayWorkerPool(relayMaxConcurrent, relayMaxQueued),
+		blocklist:    cfg.Blocklist,
+		swaps:        make(map[types.Hash]*swap),
+
+		decodeFailures:        newDecodeFailureTracker(maxDecodeFailures),
+		blockOnDecodeFailures: cfg.BlockOnDecodeFailures,
+		scoreWeights:          scoreWeights,
+
+		relayers:               newRelayerCache(relayerCacheTTLRefreshes * relayerRefreshInterval),
+		relayerRefreshInterval: relayerRefreshInterval,
+		swapVersions:           message.SupportedSwapProtocolVersions,
+
+		offerBookConcurrency: int(offerBookConcurrency),
+		offerBookPeerTimeout: offerBookPeerTimeout,
+		offerBookTimeout:     offerBookTimeout,
+
+		swapResumeWindow:     swapResumeWindow,
+		swapResumeRetries:    swapResumeRetries,
+		acceptUnsignedOffers: cfg.AcceptUnsignedOffers,
 	}
 
 	var err error
 	h.h, err = p2pnet.NewHost(&p2pnet.Config{
 		Ctx:                      cfg.Ctx,
 		DataDir:                  cfg.DataDir,
-		Port:                     cfg.Port,
+		Port:                     port,
 		KeyFile:                  cfg.KeyFile,
 		Bootnodes:                cfg.Bootnodes,
 		ProtocolID:               cfg.ProtocolID,
-		ListenIP:                 cfg.ListenIP,
+		ListenIP:                 listenIP,
 		AdvertisedNamespacesFunc: h.advertisedNamespaces,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	cmh, ok := h.h.(connManagerHost)
+	switch {
+	case ok:
+		h.connManager = cmh.ConnManager()
+	case cfg.PeerScoreWeights != nil:
+		// peer scores were configured, so don't run without them
+		return nil, errNoConnManager
+	default:
+		log.Warnf("peer scores are disabled, the p2p host has no connection manager")
+	}
+
+	if len(cfg.AnnounceAddrs) > 0 {
+		afh, ok := h.h.(addrsFactoryHost)
+		if !ok {
+			return nil, errNoAddrsFactory
+		}
+		afh.SetAddrsFactory(announceAddrsFactory(cfg.AnnounceAddrs))
+	}
+
+	h.offerSigningKey, err = loadOfferSigningKey(cfg.KeyFile, h.h.PeerID())
+	switch {
+	case err == nil:
+	case cfg.BootnodeOnly:
+		log.Warnf("failed to load offer signing key: %s", err)
+	default:
+		// peers drop unsigned offers, so we can't run without the key
+		return nil, fmt.Errorf("failed to load offer signing key: %w", err)
+	}
+
 	return h, nil
 }
 
@@ -112,11 +362,30 @@ func (h *Host) P2pHost() P2pHost {
 
 func (h *Host) advertisedNamespaces() []string {
 	provides := []string{""}
+	if h.makerHandler == nil {
+		// bootnodes only advertise that they are part of the network
+		return provides
+	}
+
+	// private offers aren't advertised
+	var providesXMR, providesETH bool
+	for _, o := range h.makerHandler.GetOffersForPeer("") {
+		switch o.Provides {
+		case coins.ProvidesXMR:
+			providesXMR = true
+		case coins.ProvidesETH:
+			providesETH = true
+		}
+	}
 
-	if len(h.makerHandler.GetOffers()) > 0 {
+	if providesXMR {
 		provides = append(provides, string(coins.ProvidesXMR))
 	}
 
+	if providesETH {
+		provides = append(provides, string(coins.ProvidesETH))
+	}
+
 	if h.isRelayer {
 		provides = append(provides, RelayerProvidesStr)
 	}
@@ -131,15 +400,20 @@ func (h *Host) SetHandlers(makerHandler MakerHandler, takerHandler TakerHandler)
 	h.takerHandler = takerHandler
 
 	h.h.SetStreamHandler(queryProtocolID, h.handleQueryStream)
+	h.h.SetStreamHandler(queryFilteredProtocolID, h.handleQueryFilteredStream)
 	if h.isRelayer {
 		h.h.SetStreamHandler(relayProtocolID, h.handleRelayStream)
+		// claim requests on the legacy protocol don't set their gas limit, so
+		// they're relayed with the fixed limit that they were signed with
+		h.h.SetStreamHandler(legacyRelayProtocolID, h.handleRelayStream)
 	}
 	h.h.SetStreamHandler(swapID, h.handleProtocolStream)
+	h.h.SetStreamHandler(swapResumeID, h.handleResumeStream)
 }
 
 // Start starts the bootstrap and discovery process.
 func (h *Host) Start() error {
-	if h.makerHandler == nil || h.takerHandler == nil {
+	if !h.bootnodeOnly && (h.makerHandler == nil || h.takerHandler == nil) {
 		return errNilHandler
 	}
 
@@ -148,6 +422,11 @@ func (h *Host) Start() error {
 		return err
 	}
 
+	if h.bootnodeOnly {
+		return nil
+	}
+
+	go h.refreshRelayers()
 	return nil
 }
 
@@ -157,25 +436,55 @@ func (h *Host) Stop() error {
 }
 
 // SendSwapMessage sends a message to the peer who we're currently doing a swap with.
+// If the swap's stream dropped, it first waits for the stream to be reopened. With peers
+// that can reopen dropped streams, messages that fail to be written are resent once the
+// stream is reopened, and the swap exits if it isn't.
 func (h *Host) SendSwapMessage(msg Message, id types.Hash) error {
 	h.swapMu.Lock()
-	defer h.swapMu.Unlock()
-
 	swap, has := h.swaps[id]
 	if !has {
+		h.swapMu.Unlock()
 		return errNoOngoingSwap
 	}
+	resumed := swap.resumed
+	h.swapMu.Unlock()
 
-	return p2pnet.WriteStreamMessage(swap.stream, msg, swap.stream.Conn().RemotePeer())
+	if resumed != nil {
+		<-resumed
+	}
+
+	if err := checkMessageVersion(msg, swap.version); err != nil {
+		return err
+	}
+
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+
+	// kept until the peer confirms receiving it when the stream is reopened,
+	// in case the stream drops before it gets there
+	swap.sent = append(swap.sent, msg)
+	err := p2pnet.WriteStreamMessage(swap.stream, msg, swap.stream.Conn().RemotePeer())
+	if err != nil && !swap.closed && swap.version >= message.MinSwapResumeVersion {
+		log.Debugf("failed to send %s, resending it once the swap stream is reopened: %s",
+			message.TypeToString(msg.Type()), err)
+		return nil
+	}
+
+	return err
 }
 
 // CloseProtocolStream closes the current swap protocol stream.
 func (h *Host) CloseProtocolStream(id types.Hash) {
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+
 	swap, has := h.swaps[id]
 	if !has {
 		return
 	}
 
+	swap.closed = true
+
 	log.Debugf("closing stream: peer=%s protocol=%s",
 		swap.stream.Conn().RemotePeer(), swap.stream.Protocol(),
 	)
@@ -220,5 +529,22 @@ func readStreamMessage(stream libp2pnetwork.Stream, maxMessageSize uint32) (comm
 		return nil, err
 	}
 
-	return message.DecodeMessage(msgBytes)
+	msg, err := message.DecodeMessage(msgBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errMessageDecode, err)
+	}
+
+	return msg, nil
+}
+
+// readPeerMessage reads the next message from a stream, skipping over messages
+// that fail to decode while the remote peer is still tolerated. Messages are
+// length-prefixed, so the stream stays usable after a malformed one.
+func (h *Host) readPeerMessage(stream libp2pnetwork.Stream, maxMessageSize uint32) (common.Message, error) {
+	for {
+		msg, err := readStreamMessage(stream, maxMessageSize)
+		if !errors.Is(err, errMessageDecode) || !h.handleDecodeFailure(stream.Conn().RemotePeer(), err) {
+			return msg, err
+		}
+	}
 }
@@ -2,11 +2,14 @@ package net
 
 import (
 	"context"
+	"math/big"
 	"path"
+	"sync"
 	"testing"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	logging "github.com/ipfs/go-log"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/require"
 
 	"github.com/athanorlabs/atomic-swap/common/types"
@@ -28,11 +31,18 @@ type mockMakerHandler struct {
 	id types.Hash
 }
 
-func (h *mockMakerHandler) GetOffers() []*types.Offer {
+func (h *mockMakerHandler) GetOffersForPeer(_ peer.ID) []*types.Offer {
 	return []*types.Offer{}
 }
 
-func (h *mockMakerHandler) HandleInitiateMessage(msg *message.SendKeysMessage) (s SwapState, resp Message, err error) {
+func (h *mockMakerHandler) TakeableRanges(_ []*types.Offer) []*types.TakeableRange {
+	return nil
+}
+
+func (h *mockMakerHandler) HandleInitiateMessage(
+	_ peer.ID,
+	msg *message.SendKeysMessage,
+) (s SwapState, resp Message, err error) {
 	if (h.id != types.Hash{}) {
 		return &mockSwapState{h.id}, createSendKeysMessage(h.t), nil
 	}
@@ -40,15 +50,24 @@ func (h *mockMakerHandler) HandleInitiateMessage(msg *message.SendKeysMessage) (
 }
 
 type mockTakerHandler struct {
-	t *testing.T
+	t   *testing.T
+	err error // if set, relay claim requests are rejected with it
 }
 
 func (h *mockTakerHandler) HandleRelayClaimRequest(_ *RelayClaimRequest) (*RelayClaimResponse, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
 	return &RelayClaimResponse{
 		TxHash: mockEthTXHash,
 	}, nil
 }
 
+func (h *mockTakerHandler) RelayerFees() map[types.EthAsset]*big.Int {
+	return map[types.EthAsset]*big.Int{types.EthAssetETH: big.NewInt(9e15)}
+}
+
 type mockSwapState struct {
 	id types.Hash
 }
@@ -69,6 +88,36 @@ func (s *mockSwapState) Exit() error {
 	return nil
 }
 
+type mockBlocklist struct {
+	mu    sync.Mutex
+	peers map[peer.ID]struct{}
+}
+
+func newMockBlocklist() *mockBlocklist {
+	return &mockBlocklist{peers: make(map[peer.ID]struct{})}
+}
+
+func (b *mockBlocklist) Add(id peer.ID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.peers[id] = struct{}{}
+	return nil
+}
+
+func (b *mockBlocklist) Remove(id peer.ID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.peers, id)
+	return nil
+}
+
+func (b *mockBlocklist) IsBlocked(id peer.ID) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.peers[id]
+	return ok, nil
+}
+
 func basicTestConfig(t *testing.T) *Config {
 	// t.TempDir() is unique on every call. Don't reuse this config with multiple hosts.
 	tmpDir := t.TempDir()
@@ -94,3 +143,25 @@ func newHost(t *testing.T, cfg *Config) *Host {
 	})
 	return h
 }
+
+func TestHost_bootnodeOnly(t *testing.T) {
+	cfg := basicTestConfig(t)
+	cfg.BootnodeOnly = true
+	h, err := NewHost(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, h.Stop())
+	})
+
+	// bootnodes start without handlers, and advertise no offers
+	require.NoError(t, h.Start())
+	require.Equal(t, []string{""}, h.advertisedNamespaces())
+}
+
+func TestHost_bootnodeOnly_relayer(t *testing.T) {
+	cfg := basicTestConfig(t)
+	cfg.BootnodeOnly = true
+	cfg.IsRelayer = true
+	_, err := NewHost(cfg)
+	require.ErrorIs(t, err, errBootnodeRelayer)
+}
@@ -52,6 +52,19 @@ func (h *Host) Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s com
 		"opened protocol stream, peer=", who.ID,
 	)
 
+	version, err := h.exchangeVersions(stream)
+	if err != nil {
+		_ = stream.Close()
+		return err
+	}
+
+	log.Debugf("using swap protocol version %d with peer %s", version, who.ID)
+
+	if err = checkMessageVersion(sendKeysMessage, version); err != nil {
+		_ = stream.Close()
+		return err
+	}
+
 	if err := p2pnet.WriteStreamMessage(stream, sendKeysMessage, who.ID); err != nil {
 		log.Warnf("failed to send initial SendKeysMessage to peer: err=%s", err)
 		return err
@@ -60,9 +73,13 @@ func (h *Host) Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s com
 	h.swaps[id] = &swap{
 		swapState: s,
 		stream:    stream,
+		peer:      who,
+		initiator: true,
+		version:   version,
+		sent:      []Message{sendKeysMessage},
 	}
 
-	go h.handleProtocolStreamInner(stream, s)
+	go h.handleProtocolStreamInner(stream, s, version)
 	return nil
 }
 
@@ -73,7 +90,34 @@ func (h *Host) handleProtocolStream(stream libp2pnetwork.Stream) {
 		return
 	}
 
-	msg, err := readStreamMessage(stream, maxMessageSize)
+	if h.isBlocked(stream.Conn().RemotePeer()) {
+		log.Debugf("closing swap stream from blocked peer %s", stream.Conn().RemotePeer())
+		_ = stream.Close()
+		return
+	}
+
+	msg, err := h.readPeerMessage(stream, maxMessageSize)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Debugf("Peer closed stream-id=%s, protocol exited", stream.ID())
+		} else {
+			log.Debugf("Failed to read message from peer, stream-id=%s: %s", stream.ID(), err)
+		}
+		_ = stream.Close()
+		return
+	}
+
+	// the swap messages can only be decoded once we agree on a version
+	version, err := h.handleVersionHandshake(stream, msg)
+	if err != nil {
+		log.Warnf("rejecting swap stream from peer %s: %s", stream.Conn().RemotePeer(), err)
+		_ = stream.Close()
+		return
+	}
+
+	log.Debugf("using swap protocol version %d with peer %s", version, stream.Conn().RemotePeer())
+
+	msg, err = h.readPeerMessage(stream, maxMessageSize)
 	if err != nil {
 		if errors.Is(err, io.EOF) {
 			log.Debugf("Peer closed stream-id=%s, protocol exited", stream.ID())
@@ -94,19 +138,30 @@ func (h *Host) handleProtocolStream(stream libp2pnetwork.Stream) {
 	im, ok := msg.(*SendKeysMessage)
 	if !ok {
 		log.Warnf("failed to handle protocol message: message was not SendKeysMessage")
+		h.penalizeProtocolViolation(stream.Conn().RemotePeer())
+		_ = stream.Close()
+		return
+	}
+
+	if err = checkMessageVersion(im, version); err != nil {
+		log.Warnf("failed to handle protocol message: %s", err)
+		h.penalizeProtocolViolation(stream.Conn().RemotePeer())
 		_ = stream.Close()
 		return
 	}
 
 	var s SwapState
-	s, resp, err := h.makerHandler.HandleInitiateMessage(im)
+	s, resp, err := h.makerHandler.HandleInitiateMessage(stream.Conn().RemotePeer(), im)
 	if err != nil {
 		log.Warnf("failed to handle protocol message: err=%s", err)
 		_ = stream.Close()
 		return
 	}
 
-	if err := p2pnet.WriteStreamMessage(stream, resp, stream.Conn().RemotePeer()); err != nil {
+	if err = checkMessageVersion(resp, version); err == nil {
+		err = p2pnet.WriteStreamMessage(stream, resp, stream.Conn().RemotePeer())
+	}
+	if err != nil {
 		log.Warnf("failed to send response to peer: %s", err)
 		if err = s.Exit(); err != nil {
 			log.Warnf("Swap exit failure: %s", err)
@@ -119,14 +174,20 @@ func (h *Host) handleProtocolStream(stream libp2pnetwork.Stream) {
 	h.swaps[s.ID()] = &swap{
 		swapState: s,
 		stream:    stream,
+		peer:      peer.AddrInfo{ID: stream.Conn().RemotePeer()},
+		version:   version,
+		received:  1, // the SendKeysMessage
+		sent:      []Message{resp},
 	}
 	h.swapMu.Unlock()
 
-	h.handleProtocolStreamInner(stream, s)
+	h.handleProtocolStreamInner(stream, s, version)
 }
 
 // handleProtocolStreamInner is called to handle a protocol stream, in both ingoing and outgoing cases.
-func (h *Host) handleProtocolStreamInner(stream libp2pnetwork.Stream, s SwapState) {
+// If the stream drops, it carries on with the stream that replaces it, if it's reopened in time.
+// Messages using features that the negotiated swap protocol version doesn't have end the swap.
+func (h *Host) handleProtocolStreamInner(stream libp2pnetwork.Stream, s SwapState, version uint16) {
 	defer func() {
 		log.Debugf("closing stream: peer=%s protocol=%s", stream.Conn().RemotePeer(), stream.Protocol())
 		_ = stream.Close()
@@ -141,8 +202,13 @@ func (h *Host) handleProtocolStreamInner(stream libp2pnetwork.Stream, s SwapStat
 	}()
 
 	for {
-		msg, err := readStreamMessage(stream, maxMessageSize)
+		msg, err := h.readPeerMessage(stream, maxMessageSize)
 		if err != nil {
+			if resumed := h.resumeSwapStream(s.ID(), stream, err); resumed != nil {
+				stream = resumed
+				continue
+			}
+
 			if errors.Is(err, io.EOF) {
 				log.Debug("Peer closed stream with us, protocol exited")
 			} else {
@@ -154,6 +220,13 @@ func (h *Host) handleProtocolStreamInner(stream libp2pnetwork.Stream, s SwapStat
 
 		log.Debugf("received protocol=%s message from peer=%s type=%s",
 			stream.Protocol(), stream.Conn().RemotePeer(), message.TypeToString(msg.Type()))
+		h.countReceived(s.ID())
+
+		if err = checkMessageVersion(msg, version); err != nil {
+			log.Warnf("failed to handle protocol message: err=%s", err)
+			h.penalizeProtocolViolation(stream.Conn().RemotePeer())
+			return
+		}
 
 		err = s.HandleProtocolMessage(msg)
 		if err != nil {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/dleq"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 	"github.com/athanorlabs/atomic-swap/tests"
@@ -16,7 +17,7 @@ import (
 )
 
 func createSendKeysMessage(t *testing.T) *message.SendKeysMessage {
-	keysAndProof, err := pcommon.GenerateKeysAndProof()
+	keysAndProof, err := pcommon.GenerateKeysAndProof(dleq.DefaultProofScheme)
 	require.NoError(t, err)
 
 	return &message.SendKeysMessage{
@@ -96,3 +97,49 @@ func TestHost_ConcurrentSwaps(t *testing.T) {
 	require.NotNil(t, hb.swaps[testID])
 	hb.swapMu.Unlock()
 }
+
+func TestHost_Initiate_incompatibleVersion(t *testing.T) {
+	ha := newHost(t, basicTestConfig(t))
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, basicTestConfig(t))
+	hb.swapVersions = []uint16{message.SwapProtocolVersion + 1}
+	err = hb.Start()
+	require.NoError(t, err)
+
+	err = ha.h.Connect(ha.ctx, hb.h.AddrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.h.AddrInfo(), createSendKeysMessage(t), new(mockSwapState))
+	require.ErrorIs(t, err, errIncompatibleVersion)
+
+	ha.swapMu.Lock()
+	require.Nil(t, ha.swaps[testID])
+	ha.swapMu.Unlock()
+
+	hb.swapMu.Lock()
+	require.Nil(t, hb.swaps[testID])
+	hb.swapMu.Unlock()
+}
+
+func TestHost_agreeVersion(t *testing.T) {
+	h := &Host{swapVersions: []uint16{1, 2, 3}}
+
+	version, err := h.agreeVersion(&VersionHandshake{Versions: []uint16{4, 2, 1}})
+	require.NoError(t, err)
+	require.Equal(t, uint16(2), version)
+
+	_, err = h.agreeVersion(&VersionHandshake{Versions: []uint16{4}})
+	require.ErrorIs(t, err, errIncompatibleVersion)
+}
+
+func TestCheckMessageVersion(t *testing.T) {
+	msg := createSendKeysMessage(t)
+	require.NoError(t, checkMessageVersion(msg, 1))
+
+	// other proof schemes can't be used with peers that predate them
+	msg.DLEqProofScheme = dleq.ProofScheme(1)
+	err := checkMessageVersion(msg, message.MinProofSchemeVersion-1)
+	require.ErrorIs(t, err, errVersionFeature)
+	require.NoError(t, checkMessageVersion(msg, message.MinProofSchemeVersion))
+}
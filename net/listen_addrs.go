@@ -0,0 +1,88 @@
+package net
+
+import (
+	"fmt"
+	"strconv"
+
+	basichost "github.com/libp2p/go-libp2p/p2p/host/basic"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// addrsFactoryHost is implemented by P2pHosts that let the addresses they
+// announce to peers be overridden.
+type addrsFactoryHost interface {
+	SetAddrsFactory(factory basichost.AddrsFactory)
+}
+
+// listenIPAndPort validates the listen addresses of the host, and returns the
+// IP and port to listen on for them. The p2p host binds a single IPv4 TCP
+// address, so each address must be of the form /ip4/<ip>/tcp/<port>, and they
+// must all share the same IP and port. Listening on all interfaces has to be
+// asked for explicitly, with /ip4/0.0.0.0.
+func listenIPAndPort(addrs []ma.Multiaddr) (string, uint16, error) {
+	var ip, port string
+
+	for _, addr := range addrs {
+		addrIP, addrPort, err := splitListenAddr(addr)
+		if err != nil {
+			return "", 0, err
+		}
+
+		if ip != "" && addrIP != ip {
+			return "", 0, fmt.Errorf("%w: %s uses IP %s, other addresses use %s",
+				errInvalidListenAddr, addr, addrIP, ip)
+		}
+
+		if port != "" && addrPort != port {
+			return "", 0, fmt.Errorf("%w: %s uses port %s, other addresses use %s",
+				errInvalidListenAddr, addr, addrPort, port)
+		}
+
+		ip, port = addrIP, addrPort
+	}
+
+	if ip == "" {
+		return "", 0, fmt.Errorf("%w: no addresses", errInvalidListenAddr)
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || p == 0 {
+		return "", 0, fmt.Errorf("%w: invalid port %s", errInvalidListenAddr, port)
+	}
+
+	return ip, uint16(p), nil
+}
+
+// splitListenAddr returns the IP and port of an /ip4/<ip>/tcp/<port> address.
+func splitListenAddr(addr ma.Multiaddr) (string, string, error) {
+	components := ma.Split(addr)
+	if len(components) != 2 ||
+		components[0].Protocol().Code != ma.P_IP4 ||
+		components[1].Protocol().Code != ma.P_TCP {
+		return "", "", fmt.Errorf("%w: %s is not of the form /ip4/<ip>/tcp/<port>", errInvalidListenAddr, addr)
+	}
+
+	// the IP was already validated when the multiaddr was parsed
+	return components[0].Value(), components[1].Value(), nil
+}
+
+// checkAnnounceAddrs checks that the announce addresses can reach the host,
+// which only listens on TCP.
+func checkAnnounceAddrs(addrs []ma.Multiaddr) error {
+	for _, addr := range addrs {
+		if _, err := addr.ValueForProtocol(ma.P_TCP); err != nil {
+			return fmt.Errorf("%w: %s has no TCP port", errInvalidAnnounceAddr, addr)
+		}
+	}
+
+	return nil
+}
+
+// announceAddrsFactory returns an address factory that has the host announce
+// the passed addresses to peers, instead of the addresses it listens on.
+func announceAddrsFactory(addrs []ma.Multiaddr) basichost.AddrsFactory {
+	announce := append([]ma.Multiaddr{}, addrs...)
+	return func([]ma.Multiaddr) []ma.Multiaddr {
+		return append([]ma.Multiaddr{}, announce...)
+	}
+}
@@ -0,0 +1,62 @@
+package net
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func multiaddrs(t *testing.T, strs ...string) []ma.Multiaddr {
+	var res []ma.Multiaddr
+	for _, s := range strs {
+		addr, err := ma.NewMultiaddr(s)
+		require.NoError(t, err)
+		res = append(res, addr)
+	}
+	return res
+}
+
+func TestListenIPAndPort(t *testing.T) {
+	addrs := func(strs ...string) []ma.Multiaddr {
+		return multiaddrs(t, strs...)
+	}
+
+	ip, port, err := listenIPAndPort(addrs("/ip4/10.0.0.2/tcp/9900"))
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.2", ip)
+	require.Equal(t, uint16(9900), port)
+
+	// the host binds a single IP, so all interfaces must be asked for
+	_, _, err = listenIPAndPort(addrs("/ip4/10.0.0.2/tcp/9900", "/ip4/192.168.1.2/tcp/9900"))
+	require.ErrorIs(t, err, errInvalidListenAddr)
+
+	ip, port, err = listenIPAndPort(addrs("/ip4/0.0.0.0/tcp/9900"))
+	require.NoError(t, err)
+	require.Equal(t, "0.0.0.0", ip)
+	require.Equal(t, uint16(9900), port)
+
+	_, _, err = listenIPAndPort(addrs("/ip4/10.0.0.2/tcp/9900", "/ip4/192.168.1.2/tcp/9901"))
+	require.ErrorIs(t, err, errInvalidListenAddr)
+
+	_, _, err = listenIPAndPort(addrs("/ip6/::1/tcp/9900"))
+	require.ErrorIs(t, err, errInvalidListenAddr)
+
+	_, _, err = listenIPAndPort(addrs("/ip4/10.0.0.2/udp/9900/quic"))
+	require.ErrorIs(t, err, errInvalidListenAddr)
+
+	_, _, err = listenIPAndPort(addrs("/ip4/10.0.0.2/tcp/0"))
+	require.ErrorIs(t, err, errInvalidListenAddr)
+}
+
+func TestAnnounceAddrs(t *testing.T) {
+	announce := multiaddrs(t, "/ip4/203.0.113.5/tcp/9900", "/dns4/swapd.example.com/tcp/9900")
+	require.NoError(t, checkAnnounceAddrs(announce))
+
+	err := checkAnnounceAddrs(multiaddrs(t, "/ip4/203.0.113.5/udp/9900/quic"))
+	require.ErrorIs(t, err, errInvalidAnnounceAddr)
+
+	// the listen addresses are replaced by the announce addresses
+	factory := announceAddrsFactory(announce)
+	require.Equal(t, announce, factory(multiaddrs(t, "/ip4/10.0.0.2/tcp/9900")))
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common/vjson"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/crypto/secp256k1"
+	"github.com/athanorlabs/atomic-swap/dleq"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 )
 
@@ -25,27 +26,65 @@ const (
 	RelayClaimResponseType
 	SendKeysType
 	NotifyETHLockedType
+	NotifyXMRLockedType
+	QueryRequestType
+	VersionHandshakeType
+	ResumeSwapType
+	RelayerTermsType
 )
 
+// SwapProtocolVersion is the version of the swap protocol messages that we
+// speak. It is bumped when the swap messages, or how swap streams are handled,
+// change in a way that peers running an older version can't handle.
+const SwapProtocolVersion uint16 = 3
+
+// The first swap protocol versions with each feature that older peers can't
+// handle. Features are only used with peers whose negotiated version has them.
+const (
+	// MinProofSchemeVersion is the first version whose SendKeysMessage can
+	// have a DLEq proof scheme other than the default one.
+	MinProofSchemeVersion uint16 = 2
+
+	// MinSwapResumeVersion is the first version that reopens dropped swap
+	// streams, resending the messages that the peer didn't receive.
+	MinSwapResumeVersion uint16 = 3
+)
+
+// SupportedSwapProtocolVersions are the swap protocol versions that we can run
+// swaps with, which we advertise in our VersionHandshake. Version 1 added the
+// version handshake.
+var SupportedSwapProtocolVersions = []uint16{1, MinProofSchemeVersion, SwapProtocolVersion}
+
 // TypeToString converts a message type into a string.
 func TypeToString(t byte) string {
 	switch t {
+	case QueryRequestType:
+		return "QueryRequest"
 	case QueryResponseType:
 		return "QueryResponse"
 	case SendKeysType:
 		return "SendKeysMessage"
 	case NotifyETHLockedType:
 		return "NotifyETHLocked"
+	case NotifyXMRLockedType:
+		return "NotifyXMRLocked"
 	case RelayClaimRequestType:
 		return "RelayClaimRequestType"
 	case RelayClaimResponseType:
 		return "RelayClaimResponse"
+	case VersionHandshakeType:
+		return "VersionHandshake"
+	case ResumeSwapType:
+		return "ResumeSwap"
+	case RelayerTermsType:
+		return "RelayerTerms"
 	default:
 		return fmt.Sprintf("Unknown(%d)", t)
 	}
 }
 
-// DecodeMessage decodes the given bytes into a Message
+// DecodeMessage decodes the given bytes into a Message. If the message fails
+// validation, the error wraps a *vjson.ValidationError with the invalid fields.
 func DecodeMessage(b []byte) (common.Message, error) {
 	// 1-byte type followed by at least 2-bytes of JSON (`{}`)
 	if len(b) < 3 {
@@ -57,6 +96,8 @@ func DecodeMessage(b []byte) (common.Message, error) {
 	var msg common.Message
 
 	switch msgType {
+	case QueryRequestType:
+		msg = new(QueryRequest)
 	case QueryResponseType:
 		msg = new(QueryResponse)
 	case RelayClaimRequestType:
@@ -67,6 +108,14 @@ func DecodeMessage(b []byte) (common.Message, error) {
 		msg = new(SendKeysMessage)
 	case NotifyETHLockedType:
 		msg = new(NotifyETHLocked)
+	case NotifyXMRLockedType:
+		msg = new(NotifyXMRLocked)
+	case VersionHandshakeType:
+		msg = new(VersionHandshake)
+	case ResumeSwapType:
+		msg = new(ResumeSwap)
+	case RelayerTermsType:
+		msg = new(RelayerTermsMessage)
 	default:
 		return nil, fmt.Errorf("invalid message type=%d", msgType)
 	}
@@ -78,9 +127,49 @@ func DecodeMessage(b []byte) (common.Message, error) {
 	return msg, nil
 }
 
+// QueryRequest is sent on the filtered query protocol to request the page of
+// the maker's offers selected by the filter.
+type QueryRequest struct {
+	types.OfferFilter
+}
+
+// String ...
+func (m *QueryRequest) String() string {
+	return fmt.Sprintf("QueryRequest Provides=%s EthAsset=%v MinAmount=%v MaxAmount=%v Offset=%d Limit=%d",
+		m.Provides,
+		m.EthAsset,
+		m.MinAmount,
+		m.MaxAmount,
+		m.Offset,
+		m.Limit,
+	)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *QueryRequest) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{QueryRequestType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *QueryRequest) Type() byte {
+	return QueryRequestType
+}
+
 // QueryResponse ...
+// TakeableRanges holds the currently takeable range of each offer. It is not
+// set by makers that predate it, or that couldn't compute the ranges.
+// TotalOffers is the number of offers matching a QueryRequest's filter, before
+// its offset and limit are applied, and is only set in replies to one.
 type QueryResponse struct {
-	Offers []*types.Offer `json:"offers" validate:"dive,required"`
+	Offers         []*types.Offer         `json:"offers" validate:"dive,required"`
+	TakeableRanges []*types.TakeableRange `json:"takeableRanges,omitempty" validate:"dive,required"`
+	TotalOffers    uint64                 `json:"totalOffers,omitempty"`
 }
 
 // String ...
@@ -106,6 +195,82 @@ func (m *QueryResponse) Type() byte {
 	return QueryResponseType
 }
 
+// VersionHandshake is sent by both sides right after a swap stream is opened,
+// before SendKeysMessage. Each side lists the swap protocol versions that it
+// supports, and the swap runs with the highest version that both support.
+type VersionHandshake struct {
+	Versions []uint16 `json:"versions" validate:"required,min=1"`
+}
+
+// String ...
+func (m *VersionHandshake) String() string {
+	return fmt.Sprintf("VersionHandshake Versions=%v", m.Versions)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *VersionHandshake) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{VersionHandshakeType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *VersionHandshake) Type() byte {
+	return VersionHandshakeType
+}
+
+// CommonVersion returns the highest swap protocol version in both `ours` and
+// `theirs`, and false if there is none.
+func CommonVersion(ours, theirs []uint16) (uint16, bool) {
+	var best uint16
+	found := false
+	for _, v := range ours {
+		for _, w := range theirs {
+			if v == w && (!found || v > best) {
+				best = v
+				found = true
+			}
+		}
+	}
+
+	return best, found
+}
+
+// ResumeSwap is sent on a new stream to replace the dropped stream of an ongoing
+// swap. It's sent by the side that opened the dropped stream, and answered by
+// the other side once it accepted the new stream. Both sides say how many swap
+// messages they received, so that each side can resend the messages that the
+// other side missed when the stream dropped.
+type ResumeSwap struct {
+	OfferID  types.Hash `json:"offerID" validate:"required"`
+	Received uint64     `json:"received"`
+}
+
+// String ...
+func (m *ResumeSwap) String() string {
+	return fmt.Sprintf("ResumeSwap OfferID=%s Received=%d", m.OfferID, m.Received)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *ResumeSwap) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{ResumeSwapType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *ResumeSwap) Type() byte {
+	return ResumeSwapType
+}
+
 // The below messages are swap protocol messages, exchanged after the swap has been agreed
 // upon by both sides.
 
@@ -116,18 +281,20 @@ type SendKeysMessage struct {
 	PublicSpendKey     *mcrypto.PublicKey      `json:"publicSpendKey" validate:"required"`
 	PrivateViewKey     *mcrypto.PrivateViewKey `json:"privateViewKey" validate:"required"`
 	DLEqProof          []byte                  `json:"dleqProof" validate:"required"`
+	DLEqProofScheme    dleq.ProofScheme        `json:"dleqProofScheme,omitempty"` // omitted for the default scheme
 	Secp256k1PublicKey *secp256k1.PublicKey    `json:"secp256k1PublicKey" validate:"required"`
 	EthAddress         ethcommon.Address       `json:"ethAddress"` // not set by XMR Taker
 }
 
 // String ...
 func (m *SendKeysMessage) String() string {
-	return fmt.Sprintf("SendKeysMessage OfferID=%s ProvidedAmount=%v PublicSpendKey=%s PrivateViewKey=%s DLEqProof=%s Secp256k1PublicKey=%s EthAddress=%s", //nolint:lll
+	return fmt.Sprintf("SendKeysMessage OfferID=%s ProvidedAmount=%v PublicSpendKey=%s PrivateViewKey=%s DLEqProof=%s DLEqProofScheme=%s Secp256k1PublicKey=%s EthAddress=%s", //nolint:lll
 		m.OfferID,
 		m.ProvidedAmount,
 		m.PublicSpendKey,
 		m.PrivateViewKey,
 		m.DLEqProof,
+		m.DLEqProofScheme,
 		m.Secp256k1PublicKey,
 		m.EthAddress,
 	)
@@ -183,3 +350,37 @@ func (m *NotifyETHLocked) Encode() ([]byte, error) {
 func (m *NotifyETHLocked) Type() byte {
 	return NotifyETHLockedType
 }
+
+// NotifyXMRLocked is sent by XMRMaker to XMRTaker after locking his XMR, so
+// that she can check for the lock right away. It is only a hint: XMRTaker
+// still verifies the lock with her view-only wallet before proceeding.
+type NotifyXMRLocked struct {
+	TxID    string           `json:"txID" validate:"required"`
+	Address *mcrypto.Address `json:"address" validate:"required"`
+	Height  uint64           `json:"height"`
+}
+
+// String ...
+func (m *NotifyXMRLocked) String() string {
+	return fmt.Sprintf("NotifyXMRLocked TxID=%s Address=%s Height=%d",
+		m.TxID,
+		m.Address,
+		m.Height,
+	)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *NotifyXMRLocked) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{NotifyXMRLockedType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *NotifyXMRLocked) Type() byte {
+	return NotifyXMRLockedType
+}
@@ -1,25 +1,89 @@
 package message
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/common/vjson"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 )
 
+// ErrInvalidRelayClaim is matched by the errors of relayers handling claim
+// requests that are invalid, or whose claim reverts, which retrying won't fix.
+// Any other failure, like an RPC error or a transaction that wasn't included
+// in time, is reported to the claimer as retryable.
+var ErrInvalidRelayClaim = errors.New("invalid relay claim request")
+
 // RelayClaimRequest implements common.Message for our p2p relay claim requests
 type RelayClaimRequest struct {
 	SwapFactoryAddress ethcommon.Address          `json:"swapFactoryAddress" validate:"required"`
 	Swap               *contracts.SwapFactorySwap `json:"swap" validate:"required"`
 	Secret             []byte                     `json:"secret" validate:"required,len=32"`
 	Signature          []byte                     `json:"signature" validate:"required,len=65"`
+
+	// Gas is the gas limit of the forwarded claim that was signed. It's zero
+	// in requests from claimers that predate it, and in requests to relayers
+	// on the legacy relay protocol, which were signed with a fixed limit.
+	Gas uint64 `json:"gas,omitempty"`
+
+	// Fee is the relayer fee that the claim was signed to pay, in the smallest
+	// unit of the swap's asset. It's nil in requests from claimers that predate
+	// it, and in requests to relayers on the legacy relay protocol, which were
+	// signed with the relayer's fee.
+	Fee *big.Int `json:"fee,omitempty"`
 }
 
-// RelayClaimResponse implements common.Message for our p2p relay claim responses
+// RelayerTermsMessage is sent by relayers on the current relay protocol when a
+// claimer opens a relay stream, before the claim request, with the minimum fee
+// that the relayer charges for relaying the claim of a swap, per asset of the
+// swap, in the asset's smallest unit.
+type RelayerTermsMessage struct {
+	Fees map[types.EthAsset]*big.Int `json:"fees" validate:"required"`
+}
+
+// RelayerTerms are the terms that a relayer relays claims on, which the claim
+// request sent to it must be signed for.
+type RelayerTerms struct {
+	// Legacy is set for relayers on the legacy relay protocol, which relay
+	// every claim with the same fixed gas limit, for their fixed fee.
+	Legacy bool
+
+	// Fees are the minimum fees that the relayer advertised, per asset. They
+	// are nil for relayers on the legacy relay protocol.
+	Fees map[types.EthAsset]*big.Int
+}
+
+// RelayClaimRequestFunc returns the claim request to send to a relayer that
+// relays claims on the passed terms.
+type RelayClaimRequestFunc func(terms *RelayerTerms) (*RelayClaimRequest, error)
+
+// RelayClaimDetailsVersion is the version of the optional claim details that
+// relayers can include in a RelayClaimResponse.
+const RelayClaimDetailsVersion = 1
+
+// RelayClaimResponse implements common.Message for our p2p relay claim responses.
+// Error is set instead of TxHash when the relayer rejected the request. If
+// Retryable is set too, the relayer failed to relay a valid claim, and the
+// request can be sent again. Relayers that predate Retryable never set it.
+//
+// DetailsVersion is zero when the relayer did not include the details of the
+// included claim transaction, either because it predates them or because it is
+// configured not to share them. Receivers must ignore the details of versions
+// they don't know.
 type RelayClaimResponse struct {
-	TxHash ethcommon.Hash `json:"transactionHash" validate:"required"`
+	TxHash    ethcommon.Hash `json:"transactionHash" validate:"required_without=Error"`
+	Error     string         `json:"error,omitempty"`
+	Retryable bool           `json:"retryable,omitempty"`
+
+	DetailsVersion uint8            `json:"detailsVersion,omitempty"`
+	FeeWei         *coins.WeiAmount `json:"feeWei,omitempty"`
+	GasUsed        uint64           `json:"gasUsed,omitempty"`
+	BlockNumber    uint64           `json:"blockNumber,omitempty"`
 }
 
 // String converts the RelayClaimRequest to a string usable for debugging purposes
@@ -63,3 +127,24 @@ func (m *RelayClaimResponse) Encode() ([]byte, error) {
 func (m *RelayClaimResponse) Type() byte {
 	return RelayClaimResponseType
 }
+
+// String converts the RelayerTermsMessage to a string usable for debugging purposes
+func (m *RelayerTermsMessage) String() string {
+	return fmt.Sprintf("RelayerTermsMessage=%#v", m)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *RelayerTermsMessage) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{RelayerTermsType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *RelayerTermsMessage) Type() byte {
+	return RelayerTermsType
+}
@@ -0,0 +1,179 @@
+package net
+
+import (
+	"context"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+const (
+	// DefaultOfferBookConcurrency is the default number of peers that
+	// FetchAggregatedOffers queries at once.
+	DefaultOfferBookConcurrency = 16
+
+	// DefaultOfferBookPeerTimeout is the default time FetchAggregatedOffers
+	// waits for the reply of a single peer.
+	DefaultOfferBookPeerTimeout = 5 * time.Second
+
+	// DefaultOfferBookTimeout is the default time FetchAggregatedOffers takes
+	// at most, including the time peers wait for a free worker.
+	DefaultOfferBookTimeout = 30 * time.Second
+)
+
+// FetchAggregatedOffers queries all of our connected peers for their offers,
+// offerBookConcurrency peers at a time, and merges the replies into an
+// orderbook sorted with the best exchange rates first. Peers that fail to
+// reply within offerBookPeerTimeout are left out, as are those that weren't
+// queried or didn't reply before the context is done or offerBookTimeout
+// passes. The filter's offset and limit select a page of the merged orderbook,
+// instead of each peer's offers. If relayerFee is set, the effective rates of
+// ETH offers after the relayer fee are set, and the orderbook is sorted by them.
+func (h *Host) FetchAggregatedOffers(
+	ctx context.Context,
+	filter *types.OfferFilter,
+	relayerFee *types.RelayerFeePolicy,
+) ([]*types.OfferWithPeer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.offerBookTimeout)
+	defer cancel()
+
+	var peerFilter *types.OfferFilter
+	if filter != nil {
+		peerFilter = new(types.OfferFilter)
+		*peerFilter = *filter
+		peerFilter.Offset = 0
+		peerFilter.Limit = 0
+	}
+
+	type peerReply struct {
+		who  peer.ID
+		resp *QueryResponse
+	}
+
+	peerIDs := h.connectedPeerIDs()
+	replies := make(chan *peerReply, len(peerIDs)) // buffered, so late replies don't block
+	queue := make(chan peer.ID)
+
+	workers := h.offerBookConcurrency
+	if workers > len(peerIDs) {
+		workers = len(peerIDs)
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for who := range queue {
+				peerCtx, peerCancel := context.WithTimeout(ctx, h.offerBookPeerTimeout)
+				resp, err := h.queryPeer(peerCtx, who, peerFilter)
+				peerCancel()
+				if err != nil {
+					log.Debugf("failed to query peer %s for the orderbook: %s", who, err)
+				}
+				replies <- &peerReply{who: who, resp: resp}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(queue)
+		for _, who := range peerIDs {
+			select {
+			case queue <- who:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	book := make([]*types.OfferWithPeer, 0)
+	seen := make(map[types.Hash]struct{})
+	for range peerIDs {
+		var reply *peerReply
+		select {
+		case <-ctx.Done():
+			log.Debugf("orderbook query timed out, returning the offers of peers that replied")
+			return pageOrderbook(book, filter), nil
+		case reply = <-replies:
+		}
+
+		if reply.resp == nil {
+			continue
+		}
+
+		ranges := make(map[types.Hash]*types.TakeableRange, len(reply.resp.TakeableRanges))
+		for _, r := range reply.resp.TakeableRanges {
+			ranges[r.OfferID] = r
+		}
+
+		for _, o := range reply.resp.Offers {
+			if _, has := seen[o.ID]; has {
+				continue
+			}
+			seen[o.ID] = struct{}{}
+
+			entry := &types.OfferWithPeer{
+				PeerID:        reply.who,
+				Offer:         o,
+				TakeableRange: ranges[o.ID],
+			}
+
+			// relayer fees are in the swap's asset, so the fee only applies
+			// to ETH offers
+			if relayerFee != nil && o.EthAsset == types.EthAssetETH {
+				if err := entry.SetEffectiveRate(relayerFee); err != nil {
+					log.Debugf("failed to compute effective rate of offer %s: %s", o.ID, err)
+				}
+			}
+
+			book = append(book, entry)
+		}
+	}
+
+	return pageOrderbook(book, filter), nil
+}
+
+// pageOrderbook sorts the orderbook and returns the page of it selected by the
+// filter's offset and limit.
+func pageOrderbook(book []*types.OfferWithPeer, filter *types.OfferFilter) []*types.OfferWithPeer {
+	types.SortOrderbook(book)
+	if filter == nil {
+		return book
+	}
+
+	if filter.Offset >= uint64(len(book)) {
+		return []*types.OfferWithPeer{}
+	}
+
+	book = book[filter.Offset:]
+	if filter.Limit != 0 && filter.Limit < uint64(len(book)) {
+		book = book[:filter.Limit]
+	}
+
+	return book
+}
+
+// connectedPeerIDs returns the IDs of the peers we're connected to.
+func (h *Host) connectedPeerIDs() []peer.ID {
+	addrs := h.h.ConnectedPeers()
+	ids := make([]peer.ID, 0, len(addrs))
+	seen := make(map[peer.ID]struct{}, len(addrs))
+	for _, addr := range addrs {
+		info, err := peer.AddrInfoFromString(addr)
+		if err != nil {
+			log.Debugf("failed to parse connected peer address %s: %s", addr, err)
+			continue
+		}
+
+		if _, has := seen[info.ID]; has {
+			continue
+		}
+		seen[info.ID] = struct{}{}
+		ids = append(ids, info.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,61 @@
+package net
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+type offersMakerHandler struct {
+	mockMakerHandler
+	offers []*types.Offer
+}
+
+func (h *offersMakerHandler) GetOffersForPeer(_ peer.ID) []*types.Offer {
+	return h.offers
+}
+
+func TestHost_FetchAggregatedOffers_unresponsivePeer(t *testing.T) {
+	cfg := basicTestConfig(t)
+	cfg.OfferBookConcurrency = 1
+	cfg.OfferBookPeerTimeout = 500 * time.Millisecond
+	ha := newHost(t, cfg)
+	require.NoError(t, ha.Start())
+
+	one := apd.New(1, 0)
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	hb := newHost(t, basicTestConfig(t))
+	hb.SetHandlers(&offersMakerHandler{mockMakerHandler{t: t}, []*types.Offer{offer}}, &mockTakerHandler{t: t})
+	require.NoError(t, hb.Start())
+
+	// hc accepts query streams, but never replies
+	hc := newHost(t, basicTestConfig(t))
+	require.NoError(t, hc.Start())
+	unblock := make(chan struct{})
+	t.Cleanup(func() { close(unblock) })
+	hang := func(stream libp2pnetwork.Stream) {
+		<-unblock
+		_ = stream.Close()
+	}
+	hc.h.SetStreamHandler(queryProtocolID, hang)
+	hc.h.SetStreamHandler(queryFilteredProtocolID, hang)
+
+	require.NoError(t, ha.h.Connect(ha.ctx, hb.h.AddrInfo()))
+	require.NoError(t, ha.h.Connect(ha.ctx, hc.h.AddrInfo()))
+
+	start := time.Now()
+	book, err := ha.FetchAggregatedOffers(context.Background(), nil, nil)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), DefaultOfferBookTimeout)
+
+	require.Len(t, book, 1)
+	require.Equal(t, hb.h.PeerID(), book[0].PeerID)
+	require.Equal(t, offer.ID, book[0].Offer.ID)
+}
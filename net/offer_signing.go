@@ -0,0 +1,81 @@
+package net
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// loadOfferSigningKey loads the libp2p private key of the host from its key
+// file, which holds the hex encoded ed25519 key. It returns an error if the
+// key doesn't belong to the passed peer ID.
+func loadOfferSigningKey(keyFile string, id peer.ID) (libp2pcrypto.PrivKey, error) {
+	data, err := os.ReadFile(filepath.Clean(keyFile))
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := libp2pcrypto.UnmarshalEd25519PrivateKey(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if !id.MatchesPrivateKey(key) {
+		return nil, fmt.Errorf("key in %s does not match peer ID %s", keyFile, id)
+	}
+
+	return key, nil
+}
+
+// signOffers returns signed copies of the passed offers, so that takers can
+// check they were made by us. The offers are returned unsigned if we have no
+// signing key, or if signing one fails, in which case takers that don't accept
+// unsigned offers drop them.
+func (h *Host) signOffers(offers []*types.Offer) []*types.Offer {
+	if h.offerSigningKey == nil {
+		return offers
+	}
+
+	signed := make([]*types.Offer, 0, len(offers))
+	for _, o := range offers {
+		cp := *o
+		if err := cp.Sign(h.offerSigningKey); err != nil {
+			log.Warnf("%s", err)
+			return offers
+		}
+		signed = append(signed, &cp)
+	}
+
+	return signed
+}
+
+// dropMisattributedOffers removes the offers whose signature wasn't made by
+// the responding peer, which may be presenting someone else's offers as its
+// own. Unsigned offers, from makers that predate offer signing, are only kept
+// if acceptUnsigned is set.
+func dropMisattributedOffers(who peer.ID, offers []*types.Offer, acceptUnsigned bool) []*types.Offer {
+	kept := make([]*types.Offer, 0, len(offers))
+	for _, o := range offers {
+		if len(o.Signature) != 0 || !acceptUnsigned {
+			if err := o.VerifyOfferSignature(who); err != nil {
+				log.Warnf("dropping offer %s from peer %s: %s", o.ID, who, err)
+				continue
+			}
+		}
+		kept = append(kept, o)
+	}
+
+	return kept
+}
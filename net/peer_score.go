@@ -0,0 +1,110 @@
+package net
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/connmgr"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+const (
+	// peerScoreTag is the connection manager tag holding the score of a peer,
+	// which the connection manager uses to decide which peers to prune.
+	peerScoreTag = "atomic-swap-score"
+
+	// maxPeerScore bounds the score of a peer in both directions, so that a
+	// long history doesn't make a peer impossible to prune, or to recover.
+	maxPeerScore = 1000
+)
+
+// PeerScoreWeights are how much each swap-level signal adds to the score of a
+// peer in the connection manager. Peers with higher scores are kept connected
+// when the connection manager trims connections.
+type PeerScoreWeights struct {
+	SwapSuccess       int
+	SwapRefund        int
+	SwapAbort         int
+	ProtocolViolation int
+	RelaySuccess      int
+}
+
+// DefaultPeerScoreWeights are the peer score weights used when none are
+// configured.
+var DefaultPeerScoreWeights = PeerScoreWeights{
+	SwapSuccess:       10,
+	SwapRefund:        -5,
+	SwapAbort:         -2,
+	ProtocolViolation: -20,
+	RelaySuccess:      2,
+}
+
+// Set sets the weight of the named signal, which is one of success, refund,
+// abort, violation or relay.
+func (w *PeerScoreWeights) Set(signal string, weight int) error {
+	switch signal {
+	case "success":
+		w.SwapSuccess = weight
+	case "refund":
+		w.SwapRefund = weight
+	case "abort":
+		w.SwapAbort = weight
+	case "violation":
+		w.ProtocolViolation = weight
+	case "relay":
+		w.RelaySuccess = weight
+	default:
+		return fmt.Errorf("%w: %q", errUnknownScoreSignal, signal)
+	}
+
+	return nil
+}
+
+// connManagerHost is implemented by P2pHosts that expose their libp2p
+// connection manager.
+type connManagerHost interface {
+	ConnManager() connmgr.ConnManager
+}
+
+// adjustPeerScore adds delta to the score of the peer in the connection
+// manager, if we have one.
+func (h *Host) adjustPeerScore(id peer.ID, delta int) {
+	if h.connManager == nil || delta == 0 {
+		return
+	}
+
+	h.connManager.UpsertTag(id, peerScoreTag, func(score int) int {
+		score += delta
+		if score > maxPeerScore {
+			return maxPeerScore
+		}
+		if score < -maxPeerScore {
+			return -maxPeerScore
+		}
+		return score
+	})
+}
+
+// RecordSwapOutcome adjusts the score of a swap's counterparty by the weight
+// of the swap's status. Statuses of ongoing swaps are ignored.
+func (h *Host) RecordSwapOutcome(id peer.ID, status types.Status) {
+	if id == "" {
+		return
+	}
+
+	switch status {
+	case types.CompletedSuccess:
+		h.adjustPeerScore(id, h.scoreWeights.SwapSuccess)
+	case types.CompletedRefund:
+		h.adjustPeerScore(id, h.scoreWeights.SwapRefund)
+	case types.CompletedAbort:
+		h.adjustPeerScore(id, h.scoreWeights.SwapAbort)
+	}
+}
+
+// penalizeProtocolViolation lowers the score of a peer that sent us something
+// that the protocol doesn't allow.
+func (h *Host) penalizeProtocolViolation(id peer.ID) {
+	h.adjustPeerScore(id, h.scoreWeights.ProtocolViolation)
+}
@@ -0,0 +1,56 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/net/connmgr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestHost_RecordSwapOutcome(t *testing.T) {
+	cm, err := connmgr.NewConnManager(1, 2)
+	require.NoError(t, err)
+
+	h := &Host{
+		connManager:    cm,
+		scoreWeights:   DefaultPeerScoreWeights,
+		decodeFailures: newDecodeFailureTracker(DefaultMaxDecodeFailures),
+	}
+
+	score := func(id peer.ID) int {
+		info := cm.GetTagInfo(id)
+		require.NotNil(t, info)
+		return info.Tags[peerScoreTag]
+	}
+
+	good := peer.ID("good")
+	bad := peer.ID("bad")
+
+	h.RecordSwapOutcome(good, types.CompletedSuccess)
+	h.RecordSwapOutcome(good, types.XMRLocked) // ongoing swaps don't count
+	h.RecordSwapOutcome(bad, types.CompletedRefund)
+	require.True(t, h.handleDecodeFailure(bad, errMessageDecode))
+
+	require.Equal(t, DefaultPeerScoreWeights.SwapSuccess, score(good))
+	require.Equal(t, DefaultPeerScoreWeights.SwapRefund+DefaultPeerScoreWeights.ProtocolViolation, score(bad))
+
+	// scores are bounded
+	for i := 0; i < maxPeerScore; i++ {
+		h.penalizeProtocolViolation(bad)
+	}
+	require.Equal(t, -maxPeerScore, score(bad))
+}
+
+func TestPeerScoreWeights_Set(t *testing.T) {
+	weights := DefaultPeerScoreWeights
+	require.NoError(t, weights.Set("violation", -50))
+	require.NoError(t, weights.Set("relay", 0))
+	require.Equal(t, -50, weights.ProtocolViolation)
+	require.Zero(t, weights.RelaySuccess)
+	require.Equal(t, DefaultPeerScoreWeights.SwapSuccess, weights.SwapSuccess)
+
+	require.ErrorIs(t, weights.Set("unknown", 1), errUnknownScoreSignal)
+}
@@ -9,19 +9,53 @@ import (
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
 const (
-	queryProtocolID = "/query/0"
-	queryTimeout    = time.Second * 5
+	queryProtocolID         = "/query/0"
+	queryFilteredProtocolID = "/query/filtered/0"
+	queryTimeout            = time.Second * 5
+	maxQueryRequestSize     = 2048
 )
 
 func (h *Host) handleQueryStream(stream libp2pnetwork.Stream) {
 	defer func() { _ = stream.Close() }()
 
+	offers := h.makerHandler.GetOffersForPeer(stream.Conn().RemotePeer())
 	resp := &QueryResponse{
-		Offers: h.makerHandler.GetOffers(),
+		Offers:         h.signOffers(offers),
+		TakeableRanges: h.makerHandler.TakeableRanges(offers),
+	}
+
+	if err := p2pnet.WriteStreamMessage(stream, resp, stream.Conn().RemotePeer()); err != nil {
+		log.Warnf("failed to send QueryResponse message to peer: err=%s", err)
+	}
+}
+
+// handleQueryFilteredStream replies to a QueryRequest with the page of our
+// offers selected by its filter, so that only those offers are encoded.
+func (h *Host) handleQueryFilteredStream(stream libp2pnetwork.Stream) {
+	defer func() { _ = stream.Close() }()
+
+	msg, err := h.readPeerMessage(stream, maxQueryRequestSize)
+	if err != nil {
+		log.Debugf("error reading QueryRequest: %s", err)
+		return
+	}
+
+	req, ok := msg.(*QueryRequest)
+	if !ok {
+		log.Debugf("ignoring wrong message type=%s sent to query stream", message.TypeToString(msg.Type()))
+		return
+	}
+
+	offers, total := req.Apply(h.makerHandler.GetOffersForPeer(stream.Conn().RemotePeer()))
+	resp := &QueryResponse{
+		Offers:         h.signOffers(offers),
+		TakeableRanges: h.makerHandler.TakeableRanges(offers),
+		TotalOffers:    total,
 	}
 
 	if err := p2pnet.WriteStreamMessage(stream, resp, stream.Conn().RemotePeer()); err != nil {
@@ -31,13 +65,35 @@ func (h *Host) handleQueryStream(stream libp2pnetwork.Stream) {
 
 // Query queries the given peer for its offers.
 func (h *Host) Query(who peer.ID) (*QueryResponse, error) {
+	return h.QueryFiltered(who, nil)
+}
+
+// QueryFiltered queries the given peer for the page of its offers selected by
+// the filter, which the peer applies before replying. Peers that predate the
+// filtered query protocol are queried for all their offers, and the filter is
+// applied to their reply instead. A nil filter queries for all offers.
+func (h *Host) QueryFiltered(who peer.ID, filter *types.OfferFilter) (*QueryResponse, error) {
 	ctx, cancel := context.WithTimeout(h.ctx, queryTimeout)
 	defer cancel()
 
+	return h.queryPeer(ctx, who, filter)
+}
+
+// queryPeer is QueryFiltered, giving up on the peer when the context is done.
+func (h *Host) queryPeer(ctx context.Context, who peer.ID, filter *types.OfferFilter) (*QueryResponse, error) {
 	if err := h.h.Connect(ctx, peer.AddrInfo{ID: who}); err != nil {
 		return nil, err
 	}
 
+	if filter != nil {
+		resp, err := h.queryFiltered(ctx, who, filter)
+		if err == nil {
+			return resp, nil
+		}
+
+		log.Debugf("filtered query of peer %s failed, querying all offers: %s", who, err)
+	}
+
 	stream, err := h.h.NewStream(ctx, who, queryProtocolID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
@@ -48,8 +104,72 @@ func (h *Host) Query(who peer.ID) (*QueryResponse, error) {
 	defer func() {
 		_ = stream.Close()
 	}()
+	setStreamDeadline(ctx, stream)
+
+	resp, err := receiveQueryResponse(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Offers = dropMisattributedOffers(who, resp.Offers, h.acceptUnsignedOffers)
+	if filter != nil {
+		applyQueryFilter(resp, filter)
+	}
+
+	return resp, nil
+}
+
+func (h *Host) queryFiltered(ctx context.Context, who peer.ID, filter *types.OfferFilter) (*QueryResponse, error) {
+	stream, err := h.h.NewStream(ctx, who, queryFilteredProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
+	}
+
+	log.Debugf("opened filtered query stream: %s", stream.Conn())
+
+	defer func() {
+		_ = stream.Close()
+	}()
+	setStreamDeadline(ctx, stream)
+
+	if err = p2pnet.WriteStreamMessage(stream, &QueryRequest{OfferFilter: *filter}, who); err != nil {
+		return nil, err
+	}
 
-	return receiveQueryResponse(stream)
+	resp, err := receiveQueryResponse(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Offers = dropMisattributedOffers(who, resp.Offers, h.acceptUnsignedOffers)
+	return resp, nil
+}
+
+// applyQueryFilter filters the reply of a peer that didn't filter its offers,
+// keeping the takeable ranges of the remaining offers only.
+func applyQueryFilter(resp *QueryResponse, filter *types.OfferFilter) {
+	resp.Offers, resp.TotalOffers = filter.Apply(resp.Offers)
+
+	kept := make(map[types.Hash]struct{}, len(resp.Offers))
+	for _, o := range resp.Offers {
+		kept[o.ID] = struct{}{}
+	}
+
+	var ranges []*types.TakeableRange
+	for _, r := range resp.TakeableRanges {
+		if _, has := kept[r.OfferID]; has {
+			ranges = append(ranges, r)
+		}
+	}
+	resp.TakeableRanges = ranges
+}
+
+// setStreamDeadline makes reads and writes on the stream fail once the
+// context's deadline passes, as they don't otherwise honour the context.
+func setStreamDeadline(ctx context.Context, stream libp2pnetwork.Stream) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
 }
 
 func receiveQueryResponse(stream libp2pnetwork.Stream) (*QueryResponse, error) {
@@ -3,8 +3,10 @@ package net
 import (
 	"testing"
 
+	"github.com/cockroachdb/apd/v3"
 	"github.com/stretchr/testify/require"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 )
 
@@ -24,3 +26,66 @@ func TestHost_Query(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []*types.Offer{}, resp.Offers)
 }
+
+func TestHost_signOffers(t *testing.T) {
+	ha := newHost(t, basicTestConfig(t))
+	hb := newHost(t, basicTestConfig(t))
+	require.NotNil(t, ha.offerSigningKey)
+
+	one := apd.New(1, 0)
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	unsigned := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+
+	signed := ha.signOffers([]*types.Offer{offer})
+	require.Len(t, signed, 1)
+	require.Empty(t, offer.Signature) // the passed offers are not modified
+	require.NotEmpty(t, signed[0].Signature)
+
+	offers := []*types.Offer{signed[0], unsigned}
+	require.Equal(t, []*types.Offer{signed[0]}, dropMisattributedOffers(ha.h.PeerID(), offers, false))
+	require.Equal(t, offers, dropMisattributedOffers(ha.h.PeerID(), offers, true))
+
+	// hb presenting ha's offer as its own
+	require.Empty(t, dropMisattributedOffers(hb.h.PeerID(), offers, false))
+	require.Equal(t, []*types.Offer{unsigned}, dropMisattributedOffers(hb.h.PeerID(), offers, true))
+}
+
+func TestHost_QueryFiltered(t *testing.T) {
+	ha := newHost(t, basicTestConfig(t))
+	err := ha.Start()
+	require.NoError(t, err)
+
+	hb := newHost(t, basicTestConfig(t))
+	err = hb.Start()
+	require.NoError(t, err)
+
+	err = ha.h.Connect(ha.ctx, hb.h.AddrInfo())
+	require.NoError(t, err)
+
+	resp, err := ha.QueryFiltered(hb.h.PeerID(), &types.OfferFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []*types.Offer{}, resp.Offers)
+	require.Zero(t, resp.TotalOffers)
+}
+
+func TestApplyQueryFilter(t *testing.T) {
+	one := apd.New(1, 0)
+	ten := apd.New(10, 0)
+	rate := coins.ToExchangeRate(one)
+	small := types.NewOffer(coins.ProvidesXMR, one, one, rate, types.EthAssetETH)
+	large := types.NewOffer(coins.ProvidesXMR, ten, ten, rate, types.EthAssetETH)
+
+	resp := &QueryResponse{
+		Offers: []*types.Offer{small, large},
+		TakeableRanges: []*types.TakeableRange{
+			{OfferID: small.ID, MinAmount: one, MaxAmount: one},
+			{OfferID: large.ID, MinAmount: ten, MaxAmount: ten},
+		},
+	}
+
+	applyQueryFilter(resp, &types.OfferFilter{MinAmount: apd.New(5, 0)})
+	require.Equal(t, []*types.Offer{large}, resp.Offers)
+	require.Len(t, resp.TakeableRanges, 1)
+	require.Equal(t, large.ID, resp.TakeableRanges[0].OfferID)
+	require.Equal(t, uint64(1), resp.TotalOffers)
+}
@@ -0,0 +1,87 @@
+package net
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// DefaultRelayerRequestsPerSec is the default sustained rate of relay claim
+	// requests that we accept from a single peer (one request every 10 seconds).
+	DefaultRelayerRequestsPerSec = 0.1
+
+	// DefaultRelayerRequestBurst is the default number of relay claim requests
+	// that a single peer can send back-to-back before being rate limited.
+	DefaultRelayerRequestBurst = 3
+)
+
+// peerRateLimiter is a token-bucket rate limiter keyed by peer ID. Each peer's
+// bucket holds up to `burst` tokens and is refilled at `rate` tokens per second.
+type peerRateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[peer.ID]*tokenBucket
+	now     func() time.Time // overridden in tests
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+func newPeerRateLimiter(rate float64, burst uint) *peerRateLimiter {
+	return &peerRateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[peer.ID]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow consumes a token from the peer's bucket and returns true if one was
+// available, or returns false if the peer is over its limit.
+func (l *peerRateLimiter) allow(id peer.ID) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.pruneFullBuckets(now)
+
+	b, has := l.buckets[id]
+	if !has {
+		b = &tokenBucket{tokens: l.burst, lastRefill: now}
+		l.buckets[id] = b
+	}
+
+	b.tokens += now.Sub(b.lastRefill).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// pruneFullBuckets removes the buckets of peers that would have refilled back to
+// the burst size, since they are indistinguishable from a new bucket. This keeps
+// the map from growing without bound.
+func (l *peerRateLimiter) pruneFullBuckets(now time.Time) {
+	if l.rate <= 0 {
+		return
+	}
+
+	refillTime := time.Duration(l.burst / l.rate * float64(time.Second))
+	for id, b := range l.buckets {
+		if now.Sub(b.lastRefill) >= refillTime {
+			delete(l.buckets, id)
+		}
+	}
+}
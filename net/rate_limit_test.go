@@ -0,0 +1,57 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerRateLimiter(t *testing.T) {
+	const burst = 3
+	l := newPeerRateLimiter(1, burst) // 1 token per second
+
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	peerA := peer.ID("a")
+	peerB := peer.ID("b")
+
+	for i := 0; i < burst; i++ {
+		require.True(t, l.allow(peerA))
+	}
+	require.False(t, l.allow(peerA))
+
+	// other peers have their own bucket
+	require.True(t, l.allow(peerB))
+
+	// half a second is not enough to refill a token
+	now = now.Add(500 * time.Millisecond)
+	require.False(t, l.allow(peerA))
+
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, l.allow(peerA))
+	require.False(t, l.allow(peerA))
+
+	// buckets never refill beyond the burst size, and are pruned once full
+	now = now.Add(time.Hour)
+	l.pruneFullBuckets(now)
+	require.Len(t, l.buckets, 0)
+	for i := 0; i < burst; i++ {
+		require.True(t, l.allow(peerA))
+	}
+	require.False(t, l.allow(peerA))
+}
+
+func TestHost_SubmitClaimToRelayer_rateLimited(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+	hb.relayLimiter = newPeerRateLimiter(0.001, 1)
+
+	_, err := ha.SubmitClaimToRelayer(hb.PeerID(), claimRequestFunc(createTestClaimRequest()))
+	require.NoError(t, err)
+
+	_, err = ha.SubmitClaimToRelayer(hb.PeerID(), claimRequestFunc(createTestClaimRequest()))
+	require.ErrorContains(t, err, errRelayRateLimited.Error())
+	require.NotErrorIs(t, err, ErrRelayerRejectedClaim)
+}
@@ -2,18 +2,28 @@ package net
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"time"
 
 	p2pnet "github.com/athanorlabs/go-p2p-net"
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
 const (
-	relayProtocolID   = "/relay/0"
+	// relayProtocolID is the relay protocol of relayers that relay claims
+	// with the gas limit that the claim request was signed with.
+	relayProtocolID = "/relay/1"
+
+	// legacyRelayProtocolID is the relay protocol of relayers that relay every
+	// claim with the same fixed gas limit.
+	legacyRelayProtocolID = "/relay/0"
+
 	relayClaimTimeout = time.Second * 30 // TODO: Vet this value
 
 	// RelayerProvidesStr is the DHT namespace advertised by nodes willing to relay
@@ -22,10 +32,37 @@ const (
 )
 
 // DiscoverRelayers returns the peer IDs of hosts that advertised their willingness to
-// relay claim transactions.
+// relay claim transactions. Cached relayers, found by a recent discovery and that haven't
+// failed us since, are returned if there are any, with rediscovered relayers that recently
+// failed us ordered last.
 func (h *Host) DiscoverRelayers() ([]peer.ID, error) {
+	if relayers := h.relayers.get(); relayers != nil {
+		return relayers, nil
+	}
+
+	if _, err := h.discoverRelayers(); err != nil {
+		return nil, err
+	}
+
+	return h.relayers.get(), nil
+}
+
+// MarkRelayerFailed deprioritizes a relayer that failed to relay our claim, so
+// that other relayers are tried first for a while.
+func (h *Host) MarkRelayerFailed(relayerID peer.ID) {
+	h.relayers.markFailed(relayerID)
+}
+
+// discoverRelayers searches the DHT for relayers and caches the result.
+func (h *Host) discoverRelayers() ([]peer.ID, error) {
 	const defaultDiscoverTime = time.Second * 3
-	return h.Discover(RelayerProvidesStr, defaultDiscoverTime)
+	relayers, err := h.Discover(RelayerProvidesStr, defaultDiscoverTime)
+	if err != nil {
+		return nil, err
+	}
+
+	h.relayers.set(relayers)
+	return relayers, nil
 }
 
 func (h *Host) handleRelayStream(stream libp2pnetwork.Stream) {
@@ -37,7 +74,24 @@ func (h *Host) handleRelayStream(stream libp2pnetwork.Stream) {
 		return
 	}
 
-	msg, err := readStreamMessage(stream, maxRelayMessageSize)
+	remotePeer := stream.Conn().RemotePeer()
+
+	if h.isBlocked(remotePeer) {
+		log.Debugf("closing relay stream from blocked peer %s", remotePeer)
+		return
+	}
+
+	// claims on the current relay protocol are signed for the fees that we
+	// advertise, while legacy claimers sign for our fee without being told
+	if stream.Protocol() == relayProtocolID {
+		terms := &RelayerTermsMessage{Fees: h.takerHandler.RelayerFees()}
+		if err := p2pnet.WriteStreamMessage(stream, terms, remotePeer); err != nil {
+			log.Debugf("failed to send RelayerTerms message to peer: %s", err)
+			return
+		}
+	}
+
+	msg, err := h.readPeerMessage(stream, maxRelayMessageSize)
 	if err != nil {
 		log.Debugf("error reading RelayClaimRequest: %s", err)
 		return
@@ -46,25 +100,72 @@ func (h *Host) handleRelayStream(stream libp2pnetwork.Stream) {
 	req, ok := msg.(*RelayClaimRequest)
 	if !ok {
 		log.Debugf("ignoring wrong message type=%s sent to relay stream", message.TypeToString(msg.Type()))
+		h.penalizeProtocolViolation(remotePeer)
+		return
+	}
+
+	// check the rate limit before doing any validation work, which requires
+	// ethereum RPC calls
+	if !h.relayLimiter.allow(remotePeer) {
+		log.Debugf("rejecting relay request from rate limited peer %s", remotePeer)
+		resp := &RelayClaimResponse{Error: errRelayRateLimited.Error(), Retryable: true}
+		if err := p2pnet.WriteStreamMessage(stream, resp, remotePeer); err != nil {
+			log.Warnf("failed to send RelayClaimResponse message to peer: %s", err)
+		}
+		return
+	}
+
+	if err = h.relayPool.acquire(h.ctx); err != nil {
+		log.Debugf("rejecting relay request from peer %s: %s", remotePeer, err)
+		resp := &RelayClaimResponse{Error: err.Error(), Retryable: true}
+		if err := p2pnet.WriteStreamMessage(stream, resp, remotePeer); err != nil {
+			log.Warnf("failed to send RelayClaimResponse message to peer: %s", err)
+		}
 		return
 	}
 
 	resp, err := h.takerHandler.HandleRelayClaimRequest(req)
+	h.relayPool.release()
 	if err != nil {
 		log.Debugf("Did not handle relay request: %s", err)
+		// only invalid requests are the peer's fault, not our RPC errors
+		invalid := errors.Is(err, message.ErrInvalidRelayClaim)
+		if invalid {
+			h.penalizeProtocolViolation(remotePeer)
+		}
+		// let the requester know whether retrying with us is pointless
+		resp = &RelayClaimResponse{
+			Error:     err.Error(),
+			Retryable: !invalid,
+		}
+		if err := p2pnet.WriteStreamMessage(stream, resp, remotePeer); err != nil {
+			log.Warnf("failed to send RelayClaimResponse message to peer: %s", err)
+		}
 		return
 	}
 
 	log.Debugf("Relayed claim for %s with tx=%s", req.Swap.Claimer, resp.TxHash)
+	h.adjustPeerScore(remotePeer, h.scoreWeights.RelaySuccess)
 
-	if err := p2pnet.WriteStreamMessage(stream, resp, stream.Conn().RemotePeer()); err != nil {
+	if err := p2pnet.WriteStreamMessage(stream, resp, remotePeer); err != nil {
 		log.Warnf("failed to send RelayClaimResponse message to peer: %s", err)
 		return
 	}
 }
 
-// SubmitClaimToRelayer sends a request to relay a swap claim to a peer.
-func (h *Host) SubmitClaimToRelayer(relayerID peer.ID, request *RelayClaimRequest) (*RelayClaimResponse, error) {
+// RelayQueueStats returns the current load on the worker pool that handles relay
+// claim requests.
+func (h *Host) RelayQueueStats() RelayQueueStats {
+	return h.relayPool.stats()
+}
+
+// SubmitClaimToRelayer sends a request to relay a swap claim to a peer. The
+// request is created by createRequest for the terms of the relay protocol that
+// the peer supports, including the fees that the peer advertised.
+func (h *Host) SubmitClaimToRelayer(
+	relayerID peer.ID,
+	createRequest message.RelayClaimRequestFunc,
+) (*RelayClaimResponse, error) {
 	ctx, cancel := context.WithTimeout(h.ctx, relayClaimTimeout)
 	defer cancel()
 
@@ -72,14 +173,27 @@ func (h *Host) SubmitClaimToRelayer(relayerID peer.ID, request *RelayClaimReques
 		return nil, err
 	}
 
-	stream, err := h.h.NewStream(ctx, relayerID, relayProtocolID)
+	stream, legacy, err := h.openRelayStream(ctx, relayerID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
+		return nil, err
 	}
 
 	defer func() { _ = stream.Close() }()
 	log.Debugf("opened relay stream: %s", stream.Conn())
 
+	terms := &message.RelayerTerms{Legacy: legacy}
+	if !legacy {
+		terms.Fees, err = receiveRelayerFees(stream)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	request, err := createRequest(terms)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := p2pnet.WriteStreamMessage(stream, request, relayerID); err != nil {
 		log.Warnf("failed to send RelayClaimRequest to peer: err=%s", err)
 		return nil, err
@@ -88,6 +202,42 @@ func (h *Host) SubmitClaimToRelayer(relayerID peer.ID, request *RelayClaimReques
 	return receiveRelayClaimResponse(stream)
 }
 
+// openRelayStream opens a relay stream with the peer, falling back to the
+// legacy relay protocol if the peer doesn't support the current one, in which
+// case legacy is true.
+func (h *Host) openRelayStream(ctx context.Context, relayerID peer.ID) (libp2pnetwork.Stream, bool, error) {
+	stream, err := h.h.NewStream(ctx, relayerID, relayProtocolID)
+	if err == nil {
+		return stream, false, nil
+	}
+
+	log.Debugf("failed to open %s stream with peer %s, trying %s: %s",
+		relayProtocolID, relayerID, legacyRelayProtocolID, err)
+
+	stream, err = h.h.NewStream(ctx, relayerID, legacyRelayProtocolID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open stream with peer: err=%w", err)
+	}
+
+	return stream, true, nil
+}
+
+func receiveRelayerFees(stream libp2pnetwork.Stream) (map[types.EthAsset]*big.Int, error) {
+	msg, err := readStreamMessage(stream, maxRelayMessageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RelayerTerms: %w", err)
+	}
+
+	terms, ok := msg.(*RelayerTermsMessage)
+	if !ok {
+		return nil, fmt.Errorf("expected %s message but received %s",
+			message.TypeToString(message.RelayerTermsType),
+			message.TypeToString(msg.Type()))
+	}
+
+	return terms.Fees, nil
+}
+
 func receiveRelayClaimResponse(stream libp2pnetwork.Stream) (*RelayClaimResponse, error) {
 	msg, err := readStreamMessage(stream, maxRelayMessageSize)
 	if err != nil {
@@ -101,5 +251,17 @@ func receiveRelayClaimResponse(stream libp2pnetwork.Stream) (*RelayClaimResponse
 			message.TypeToString(msg.Type()))
 	}
 
+	// relayers that predate Retryable only report their rate limit and queue
+	// as retryable, by their errors
+	switch {
+	case resp.Error == "":
+	case resp.Error == errRelayRateLimited.Error(), resp.Error == errRelayQueueFull.Error():
+		return nil, fmt.Errorf("relayer is unavailable: %s", resp.Error)
+	case resp.Retryable:
+		return nil, fmt.Errorf("relayer failed to relay claim: %s", resp.Error)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrRelayerRejectedClaim, resp.Error)
+	}
+
 	return resp, nil
 }
@@ -0,0 +1,97 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultRelayerMaxConcurrent is the default number of relay claim requests
+	// that we validate and submit at the same time.
+	DefaultRelayerMaxConcurrent = 4
+
+	// DefaultRelayerMaxQueued is the default number of relay claim requests that
+	// can wait for a free worker before further requests are rejected.
+	DefaultRelayerMaxQueued = 16
+
+	// relayQueueTimeout is how long a relay claim request can wait for a free
+	// worker. The requester stops waiting for our response after
+	// relayClaimTimeout, which also covers submitting the claim, so it's
+	// rejected rather than submitted once the requester may have moved on to
+	// another relayer.
+	relayQueueTimeout = relayClaimTimeout / 3
+)
+
+// RelayQueueStats are counters describing the load on the relay worker pool.
+type RelayQueueStats struct {
+	Active   int    // requests currently being handled
+	Queued   int    // requests waiting for a free worker
+	Rejected uint64 // requests rejected because the queue was full or they waited too long, since startup
+}
+
+// relayWorkerPool limits the number of relay claim requests handled at the same
+// time, as each one makes several ethereum RPC calls. Requests beyond the limit
+// wait in a bounded queue.
+type relayWorkerPool struct {
+	slots     chan struct{}
+	maxQueued int32
+	queued    atomic.Int32
+	rejected  atomic.Uint64
+}
+
+func newRelayWorkerPool(maxConcurrent, maxQueued uint) *relayWorkerPool {
+	return &relayWorkerPool{
+		slots:     make(chan struct{}, maxConcurrent),
+		maxQueued: int32(maxQueued),
+	}
+}
+
+// acquire blocks until a worker is free, returning errRelayQueueFull without
+// blocking if the queue is already full, or after waiting for relayQueueTimeout.
+// If nil is returned, the caller must call release when done.
+func (p *relayWorkerPool) acquire(ctx context.Context) error {
+	return p.acquireWithin(ctx, relayQueueTimeout)
+}
+
+func (p *relayWorkerPool) acquireWithin(ctx context.Context, timeout time.Duration) error {
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if p.queued.Add(1) > p.maxQueued {
+		p.queued.Add(-1)
+		p.rejected.Add(1)
+		return errRelayQueueFull
+	}
+	defer p.queued.Add(-1)
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-waitCtx.Done():
+		if errors.Is(waitCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			p.rejected.Add(1)
+			return errRelayQueueFull
+		}
+		return ctx.Err()
+	}
+}
+
+func (p *relayWorkerPool) release() {
+	<-p.slots
+}
+
+func (p *relayWorkerPool) stats() RelayQueueStats {
+	return RelayQueueStats{
+		Active:   len(p.slots),
+		Queued:   int(p.queued.Load()),
+		Rejected: p.rejected.Load(),
+	}
+}
@@ -0,0 +1,54 @@
+package net
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayWorkerPool(t *testing.T) {
+	ctx := context.Background()
+	p := newRelayWorkerPool(1, 1)
+
+	require.NoError(t, p.acquire(ctx))
+
+	// the second request waits in the queue until the first is released
+	acquired := make(chan error)
+	go func() {
+		acquired <- p.acquire(ctx)
+	}()
+	require.Eventually(t, func() bool { return p.stats().Queued == 1 }, time.Second, 10*time.Millisecond)
+
+	// the queue is full, so the third request is rejected
+	require.ErrorIs(t, p.acquire(ctx), errRelayQueueFull)
+	require.Equal(t, RelayQueueStats{Active: 1, Queued: 1, Rejected: 1}, p.stats())
+
+	p.release()
+	require.NoError(t, <-acquired)
+	require.Equal(t, RelayQueueStats{Active: 1, Queued: 0, Rejected: 1}, p.stats())
+	p.release()
+}
+
+func TestRelayWorkerPool_contextCancelled(t *testing.T) {
+	p := newRelayWorkerPool(1, 1)
+	require.NoError(t, p.acquire(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, p.acquire(ctx), context.Canceled)
+	require.Zero(t, p.stats().Queued)
+}
+
+func TestRelayWorkerPool_queueTimeout(t *testing.T) {
+	p := newRelayWorkerPool(1, 1)
+	require.NoError(t, p.acquire(context.Background()))
+
+	// the queued request gives up waiting, and is rejected as if the relayer
+	// was busy, so that the requester tries another relayer
+	err := p.acquireWithin(context.Background(), 10*time.Millisecond)
+	require.ErrorIs(t, err, errRelayQueueFull)
+	require.Equal(t, RelayQueueStats{Active: 1, Queued: 0, Rejected: 1}, p.stats())
+	p.release()
+}
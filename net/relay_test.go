@@ -1,11 +1,14 @@
 package net
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 	"testing"
 	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/p2p/net/connmgr"
 	"github.com/stretchr/testify/require"
 
 	"github.com/athanorlabs/atomic-swap/common/types"
@@ -74,12 +77,39 @@ func createTestClaimRequest() *message.RelayClaimRequest {
 	return req
 }
 
+// claimRequestFunc returns a RelayClaimRequestFunc that returns req.
+func claimRequestFunc(req *message.RelayClaimRequest) message.RelayClaimRequestFunc {
+	return func(_ *message.RelayerTerms) (*message.RelayClaimRequest, error) {
+		return req, nil
+	}
+}
+
 func TestHost_SubmitClaimToRelayer(t *testing.T) {
 	ha, hb := twoHostRelayerSetup(t)
 
-	resp, err := ha.SubmitClaimToRelayer(hb.PeerID(), createTestClaimRequest())
+	var terms *message.RelayerTerms
+	createRequest := func(t *message.RelayerTerms) (*message.RelayClaimRequest, error) {
+		terms = t
+		return createTestClaimRequest(), nil
+	}
+
+	resp, err := ha.SubmitClaimToRelayer(hb.PeerID(), createRequest)
 	require.NoError(t, err)
 	require.Equal(t, mockEthTXHash.Hex(), resp.TxHash.Hex())
+	require.NotNil(t, terms)
+	require.False(t, terms.Legacy)
+	require.Equal(t, big.NewInt(9e15), terms.Fees[types.EthAssetETH])
+}
+
+func TestHost_SubmitClaimToRelayer_requestError(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+
+	createRequest := func(_ *message.RelayerTerms) (*message.RelayClaimRequest, error) {
+		return nil, errors.New("failed to sign")
+	}
+
+	_, err := ha.SubmitClaimToRelayer(hb.PeerID(), createRequest)
+	require.ErrorContains(t, err, "failed to sign")
 }
 
 func TestHost_SubmitClaimToRelayer_fail(t *testing.T) {
@@ -87,11 +117,56 @@ func TestHost_SubmitClaimToRelayer_fail(t *testing.T) {
 
 	req := createTestClaimRequest()
 	req.Secret = []byte{0x1} // wrong size
-	_, err := ha.SubmitClaimToRelayer(hb.PeerID(), req)
+	_, err := ha.SubmitClaimToRelayer(hb.PeerID(), claimRequestFunc(req))
 	require.ErrorContains(t, err, "Field validation for 'Secret' failed on the 'len' tag")
 
 	req = createTestClaimRequest()
 	req.Signature = []byte{0x1, 0x2} // wrong size
-	_, err = ha.SubmitClaimToRelayer(hb.PeerID(), req)
+	_, err = ha.SubmitClaimToRelayer(hb.PeerID(), claimRequestFunc(req))
 	require.ErrorContains(t, err, "Field validation for 'Signature' failed on the 'len' tag")
 }
+
+func TestHost_SubmitClaimToRelayer_rejected(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+	hb.takerHandler = &mockTakerHandler{t: t, err: fmt.Errorf("%w: invalid signature", message.ErrInvalidRelayClaim)}
+	cm, err := connmgr.NewConnManager(1, 2)
+	require.NoError(t, err)
+	hb.connManager = cm
+
+	_, err = ha.SubmitClaimToRelayer(hb.PeerID(), claimRequestFunc(createTestClaimRequest()))
+	require.ErrorIs(t, err, ErrRelayerRejectedClaim)
+	require.ErrorContains(t, err, "invalid signature")
+	require.Equal(t, DefaultPeerScoreWeights.ProtocolViolation, cm.GetTagInfo(ha.PeerID()).Tags[peerScoreTag])
+}
+
+func TestHost_SubmitClaimToRelayer_transientFailure(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+	hb.takerHandler = &mockTakerHandler{t: t, err: errors.New("nonce too low")}
+	cm, err := connmgr.NewConnManager(1, 2)
+	require.NoError(t, err)
+	hb.connManager = cm
+
+	_, err = ha.SubmitClaimToRelayer(hb.PeerID(), claimRequestFunc(createTestClaimRequest()))
+	require.ErrorContains(t, err, "nonce too low")
+	require.NotErrorIs(t, err, ErrRelayerRejectedClaim)
+
+	// our own failures to relay don't count against the peer
+	require.Nil(t, cm.GetTagInfo(ha.PeerID()))
+}
+
+func TestHost_SubmitClaimToRelayer_blockedPeer(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+	hb.blocklist = newMockBlocklist()
+
+	err := hb.BlockPeer(ha.PeerID())
+	require.NoError(t, err)
+
+	_, err = ha.SubmitClaimToRelayer(hb.PeerID(), claimRequestFunc(createTestClaimRequest()))
+	require.Error(t, err)
+
+	err = hb.UnblockPeer(ha.PeerID())
+	require.NoError(t, err)
+
+	_, err = ha.SubmitClaimToRelayer(hb.PeerID(), claimRequestFunc(createTestClaimRequest()))
+	require.NoError(t, err)
+}
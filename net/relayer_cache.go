@@ -0,0 +1,126 @@
+package net
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const (
+	// DefaultRelayerRefreshInterval is the default interval at which we
+	// rediscover relayers in the background, so the list is warm when we need
+	// to claim.
+	DefaultRelayerRefreshInterval = 5 * time.Minute
+
+	// relayerFailurePenalty is how long a relayer that failed to relay our
+	// claim is tried after the other relayers, once it's rediscovered.
+	relayerFailurePenalty = 10 * time.Minute
+
+	// relayerCacheTTLRefreshes is how many refresh intervals a relayer stays
+	// cached without being rediscovered, eg. while refreshes fail.
+	relayerCacheTTLRefreshes = 3
+)
+
+// relayerCache holds the peer IDs of discovered relayers, along with when each
+// one was last discovered, and when each one last failed to relay a claim for
+// us. Relayers expire from the cache once they haven't been rediscovered for
+// the cache's TTL, or once they fail us.
+type relayerCache struct {
+	mu       sync.Mutex
+	relayers []peer.ID
+	seen     map[peer.ID]time.Time
+	failed   map[peer.ID]time.Time
+	ttl      time.Duration
+	now      func() time.Time // overridden in tests
+}
+
+func newRelayerCache(ttl time.Duration) *relayerCache {
+	return &relayerCache{
+		seen:   make(map[peer.ID]time.Time),
+		failed: make(map[peer.ID]time.Time),
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// set replaces the cached relayers with newly discovered ones.
+func (c *relayerCache) set(relayers []peer.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.relayers = relayers
+	c.seen = make(map[peer.ID]time.Time, len(relayers))
+	for _, id := range relayers {
+		c.seen[id] = now
+	}
+}
+
+// get returns the unexpired cached relayers, with those that recently failed
+// after the others, or nil if there are none.
+func (c *relayerCache) get() []peer.ID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	preferred := make([]peer.ID, 0, len(c.relayers))
+	var deprioritized []peer.ID
+	for _, id := range c.relayers {
+		if now.Sub(c.seen[id]) >= c.ttl {
+			continue
+		}
+
+		failedAt, has := c.failed[id]
+		switch {
+		case !has:
+			preferred = append(preferred, id)
+		case now.Sub(failedAt) >= relayerFailurePenalty:
+			delete(c.failed, id)
+			preferred = append(preferred, id)
+		default:
+			deprioritized = append(deprioritized, id)
+		}
+	}
+
+	if len(preferred)+len(deprioritized) == 0 {
+		return nil
+	}
+
+	return append(preferred, deprioritized...)
+}
+
+// markFailed drops the relayer from the cache until it's discovered again, and
+// deprioritizes it for relayerFailurePenalty once it is.
+func (c *relayerCache) markFailed(id peer.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failed[id] = c.now()
+	delete(c.seen, id)
+	for i, r := range c.relayers {
+		if r == id {
+			c.relayers = append(c.relayers[:i:i], c.relayers[i+1:]...)
+			return
+		}
+	}
+}
+
+// refreshRelayers rediscovers relayers at the refresh interval until the
+// host's context is cancelled.
+func (h *Host) refreshRelayers() {
+	ticker := time.NewTicker(h.relayerRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := h.discoverRelayers(); err != nil {
+			log.Debugf("failed to refresh relayers: %s", err)
+		}
+
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
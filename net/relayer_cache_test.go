@@ -0,0 +1,41 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayerCache(t *testing.T) {
+	ttl := 3 * time.Minute
+	c := newRelayerCache(ttl)
+	require.Nil(t, c.get())
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	relayerA := peer.ID("a")
+	relayerB := peer.ID("b")
+	relayerC := peer.ID("c")
+	c.set([]peer.ID{relayerA, relayerB, relayerC})
+	require.Equal(t, []peer.ID{relayerA, relayerB, relayerC}, c.get())
+
+	// relayers that fail are dropped until rediscovered
+	c.markFailed(relayerA)
+	require.Equal(t, []peer.ID{relayerB, relayerC}, c.get())
+
+	// and are then tried last, until the failure penalty is over
+	c.set([]peer.ID{relayerA, relayerB, relayerC})
+	require.Equal(t, []peer.ID{relayerB, relayerC, relayerA}, c.get())
+
+	now = now.Add(relayerFailurePenalty)
+	c.set([]peer.ID{relayerA, relayerB, relayerC})
+	require.Equal(t, []peer.ID{relayerA, relayerB, relayerC}, c.get())
+	require.Len(t, c.failed, 0)
+
+	// relayers that weren't rediscovered within the TTL expire
+	now = now.Add(ttl)
+	require.Nil(t, c.get())
+}
@@ -0,0 +1,336 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	p2pnet "github.com/athanorlabs/go-p2p-net"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	swapResumeID = "/swap/resume/1"
+
+	// DefaultSwapResumeWindow is how long we wait by default for the dropped
+	// stream of an ongoing swap to be reopened, before exiting the swap.
+	DefaultSwapResumeWindow = 30 * time.Second
+
+	// DefaultSwapResumeRetries is how many times we try to reopen a dropped
+	// swap stream by default, within the resume window.
+	DefaultSwapResumeRetries = 5
+
+	maxResumeSwapSize    = 256
+	initialResumeBackoff = time.Second
+	maxResumeBackoff     = 8 * time.Second
+)
+
+// resumeSwapStream is called when reading from the stream of a swap fails. If
+// the stream dropped, rather than being closed by either side, it waits up to
+// the resume window for the stream to be reopened, by us if we opened the
+// dropped stream, or else by the peer. It returns the stream that replaced the
+// dropped one, or nil if the swap should exit.
+func (h *Host) resumeSwapStream(id types.Hash, dropped libp2pnetwork.Stream, readErr error) libp2pnetwork.Stream {
+	h.swapMu.Lock()
+	sw, has := h.swaps[id]
+	if !has || sw.closed {
+		h.swapMu.Unlock()
+		return nil
+	}
+
+	// the peer may have reopened the stream before we noticed that it dropped
+	if sw.stream != dropped {
+		stream := sw.stream
+		h.swapMu.Unlock()
+		return stream
+	}
+
+	// streams that the peer closed, or whose messages we stopped tolerating,
+	// aren't reopened, nor are the streams of peers that can't reopen them
+	if errors.Is(readErr, io.EOF) || errors.Is(readErr, errMessageDecode) ||
+		sw.version < message.MinSwapResumeVersion {
+		h.swapMu.Unlock()
+		return nil
+	}
+
+	if sw.resumed == nil {
+		sw.resumed = make(chan struct{})
+	}
+	resumed := sw.resumed
+	h.swapMu.Unlock()
+
+	log.Infof("swap stream with peer %s dropped, waiting up to %s for it to be reopened: %s",
+		sw.peer.ID, h.swapResumeWindow, readErr)
+
+	ctx, cancel := context.WithTimeout(h.ctx, h.swapResumeWindow)
+	defer cancel()
+
+	if sw.initiator {
+		h.reopenSwapStream(ctx, id, sw.peer)
+	} else {
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+		}
+	}
+
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+
+	if sw.stream != dropped {
+		log.Infof("resumed swap stream with peer %s", sw.peer.ID)
+		return sw.stream
+	}
+
+	// wake up the messages waiting to be sent on the reopened stream
+	if sw.resumed == resumed {
+		close(resumed)
+		sw.resumed = nil
+	}
+
+	log.Warnf("gave up on reopening swap stream with peer %s", sw.peer.ID)
+	return nil
+}
+
+// reopenSwapStream tries to open a new stream with the peer to replace the
+// dropped stream of the swap, backing off between attempts, until it succeeds,
+// the retries run out or ctx is done.
+func (h *Host) reopenSwapStream(ctx context.Context, id types.Hash, who peer.AddrInfo) {
+	backoff := initialResumeBackoff
+	for attempt := uint(1); attempt <= h.swapResumeRetries; attempt++ {
+		stream, peerReceived, err := h.openResumeStream(ctx, id, who)
+		if err == nil {
+			if err = h.replaceSwapStream(id, who.ID, stream, peerReceived); err == nil {
+				return
+			}
+			_ = stream.Close()
+		}
+
+		log.Debugf("failed to reopen swap stream with peer %s (attempt %d/%d): %s",
+			who.ID, attempt, h.swapResumeRetries, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxResumeBackoff {
+			backoff = maxResumeBackoff
+		}
+	}
+}
+
+// openResumeStream opens a resume stream with the peer, and returns it once
+// the peer accepted it to replace the dropped stream of the swap, along with
+// how many swap messages the peer received.
+func (h *Host) openResumeStream(
+	ctx context.Context,
+	id types.Hash,
+	who peer.AddrInfo,
+) (libp2pnetwork.Stream, uint64, error) {
+	if h.h.Connectedness(who.ID) != libp2pnetwork.Connected {
+		if err := h.h.Connect(ctx, who); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	stream, err := h.h.NewStream(ctx, who.ID, protocol.ID(swapResumeID))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open stream with peer: %w", err)
+	}
+
+	received, err := h.swapMessagesReceived(id, who.ID)
+	if err != nil {
+		_ = stream.Close()
+		return nil, 0, err
+	}
+
+	ack, err := h.exchangeResumeSwap(stream, &ResumeSwap{OfferID: id, Received: received})
+	if err != nil {
+		_ = stream.Close()
+		return nil, 0, err
+	}
+
+	if ack.OfferID != id {
+		_ = stream.Close()
+		return nil, 0, fmt.Errorf("peer resumed swap %s instead of %s", ack.OfferID, id)
+	}
+
+	return stream, ack.Received, nil
+}
+
+// handleResumeStream is called when a peer opens a stream to replace the
+// dropped stream of an ongoing swap with us.
+func (h *Host) handleResumeStream(stream libp2pnetwork.Stream) {
+	who := stream.Conn().RemotePeer()
+	if h.isBlocked(who) {
+		log.Debugf("closing swap resume stream from blocked peer %s", who)
+		_ = stream.Close()
+		return
+	}
+
+	req, err := h.exchangeResumeSwap(stream, nil)
+	if err != nil {
+		log.Debugf("failed to read swap resume request from peer %s: %s", who, err)
+		_ = stream.Close()
+		return
+	}
+
+	received, err := h.swapMessagesReceived(req.OfferID, who)
+	if err != nil {
+		log.Warnf("rejecting swap resume request from peer %s: %s", who, err)
+		_ = stream.Close()
+		return
+	}
+
+	// our reply has to come before the messages that we resend
+	reply := &ResumeSwap{OfferID: req.OfferID, Received: received}
+	if err = p2pnet.WriteStreamMessage(stream, reply, who); err != nil {
+		log.Debugf("failed to reply to swap resume request from peer %s: %s", who, err)
+		_ = stream.Close()
+		return
+	}
+
+	if err = h.replaceSwapStream(req.OfferID, who, stream, req.Received); err != nil {
+		log.Warnf("rejecting swap resume request from peer %s: %s", who, err)
+		_ = stream.Close()
+	}
+}
+
+// exchangeResumeSwap writes msg to the stream, if it's set, and reads the
+// ResumeSwap message that the peer sent.
+func (h *Host) exchangeResumeSwap(stream libp2pnetwork.Stream, msg *ResumeSwap) (*ResumeSwap, error) {
+	if msg != nil {
+		if err := p2pnet.WriteStreamMessage(stream, msg, stream.Conn().RemotePeer()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := stream.SetReadDeadline(time.Now().Add(protocolTimeout)); err != nil {
+		return nil, err
+	}
+	defer func() { _ = stream.SetReadDeadline(time.Time{}) }()
+
+	reply, err := h.readPeerMessage(stream, maxResumeSwapSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resume, ok := reply.(*ResumeSwap)
+	if !ok {
+		return nil, fmt.Errorf("peer sent %s instead of ResumeSwap", message.TypeToString(reply.Type()))
+	}
+
+	return resume, nil
+}
+
+// resumableSwap returns the swap whose stream the peer can reopen. The caller
+// must hold swapMu.
+func (h *Host) resumableSwap(id types.Hash, who peer.ID) (*swap, error) {
+	sw, has := h.swaps[id]
+	if !has || sw.closed {
+		return nil, errNoOngoingSwap
+	}
+
+	if sw.peer.ID != who {
+		return nil, errResumeWrongPeer
+	}
+
+	if sw.version < message.MinSwapResumeVersion {
+		return nil, errResumeNotSupported
+	}
+
+	return sw, nil
+}
+
+// swapMessagesReceived returns how many swap messages we received from the
+// peer, for the peer to resend the ones that we're missing.
+func (h *Host) swapMessagesReceived(id types.Hash, who peer.ID) (uint64, error) {
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+
+	sw, err := h.resumableSwap(id, who)
+	if err != nil {
+		return 0, err
+	}
+
+	return sw.received, nil
+}
+
+// replaceSwapStream replaces the stream of the swap with a stream that was
+// reopened with the swap's peer, and resends the messages that the peer didn't
+// receive out of the `peerReceived` that it did. The replaced stream is closed,
+// so that the read loop of the swap moves on to the new stream.
+func (h *Host) replaceSwapStream(
+	id types.Hash,
+	who peer.ID,
+	stream libp2pnetwork.Stream,
+	peerReceived uint64,
+) error {
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+
+	sw, err := h.resumableSwap(id, who)
+	if err != nil {
+		return err
+	}
+
+	missed, err := sw.ackSent(peerReceived)
+	if err != nil {
+		return err
+	}
+
+	_ = sw.stream.Close()
+	sw.stream = stream
+	if sw.resumed != nil {
+		close(sw.resumed)
+		sw.resumed = nil
+	}
+
+	// if resending doesn't make it, the read loop of the swap finds out, and
+	// waits for the stream to be reopened again
+	for _, msg := range missed {
+		if err = p2pnet.WriteStreamMessage(stream, msg, who); err != nil {
+			log.Debugf("failed to resend %s to peer %s: %s", message.TypeToString(msg.Type()), who, err)
+			return nil
+		}
+	}
+
+	if len(missed) > 0 {
+		log.Debugf("resent %d swap messages to peer %s", len(missed), who)
+	}
+
+	return nil
+}
+
+// ackSent drops the sent messages that the peer received, out of the
+// `peerReceived` that it says it did, and returns the ones it didn't.
+func (sw *swap) ackSent(peerReceived uint64) ([]Message, error) {
+	if peerReceived < sw.sentAcked || peerReceived > sw.sentAcked+uint64(len(sw.sent)) {
+		return nil, fmt.Errorf("%w: peer received %d messages, we sent %d and %d were already received",
+			errResumeMessageCount, peerReceived, sw.sentAcked+uint64(len(sw.sent)), sw.sentAcked)
+	}
+
+	sw.sent = sw.sent[peerReceived-sw.sentAcked:]
+	sw.sentAcked = peerReceived
+	return sw.sent, nil
+}
+
+// countReceived records that we received a message on the stream of the swap.
+func (h *Host) countReceived(id types.Hash) {
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+
+	if sw, has := h.swaps[id]; has {
+		sw.received++
+	}
+}
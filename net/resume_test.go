@@ -0,0 +1,126 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+func TestHost_resumeDroppedSwapStream(t *testing.T) {
+	ha := newHost(t, basicTestConfig(t))
+	require.NoError(t, ha.Start())
+	hb := newHost(t, basicTestConfig(t))
+	require.NoError(t, hb.Start())
+
+	err := ha.h.Connect(ha.ctx, hb.h.AddrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.h.AddrInfo(), createSendKeysMessage(t), new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+
+	ha.swapMu.Lock()
+	dropped := ha.swaps[testID].stream
+	ha.swapMu.Unlock()
+
+	// resetting the stream drops it on both sides, without closing it
+	require.NoError(t, dropped.Reset())
+	time.Sleep(time.Millisecond * 1500)
+
+	ha.swapMu.Lock()
+	require.NotNil(t, ha.swaps[testID])
+	require.NotEqual(t, dropped, ha.swaps[testID].stream)
+	ha.swapMu.Unlock()
+
+	hb.swapMu.Lock()
+	require.NotNil(t, hb.swaps[testID])
+	hb.swapMu.Unlock()
+
+	// the swap carries on with the reopened stream
+	require.NoError(t, ha.SendSwapMessage(createSendKeysMessage(t), testID))
+	time.Sleep(time.Millisecond * 500)
+
+	hb.swapMu.Lock()
+	require.Equal(t, uint64(2), hb.swaps[testID].received)
+	hb.swapMu.Unlock()
+
+	ha.swapMu.Lock()
+	require.Equal(t, uint64(1), ha.swaps[testID].sentAcked)
+	require.Len(t, ha.swaps[testID].sent, 1)
+	ha.swapMu.Unlock()
+
+	// streams that we close ourselves aren't reopened
+	ha.CloseProtocolStream(testID)
+	time.Sleep(time.Millisecond * 500)
+
+	ha.swapMu.Lock()
+	require.Nil(t, ha.swaps[testID])
+	ha.swapMu.Unlock()
+}
+
+func TestHost_resumeSwapStream_wrongPeer(t *testing.T) {
+	ha := newHost(t, basicTestConfig(t))
+	require.NoError(t, ha.Start())
+	hb := newHost(t, basicTestConfig(t))
+	require.NoError(t, hb.Start())
+
+	err := ha.h.Connect(ha.ctx, hb.h.AddrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.h.AddrInfo(), createSendKeysMessage(t), new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+
+	err = hb.replaceSwapStream(testID, hb.PeerID(), nil, 0)
+	require.ErrorIs(t, err, errResumeWrongPeer)
+}
+
+func TestHost_resumeSwapStream_oldVersion(t *testing.T) {
+	ha := newHost(t, basicTestConfig(t))
+	require.NoError(t, ha.Start())
+	hb := newHost(t, basicTestConfig(t))
+	hb.swapVersions = []uint16{message.MinSwapResumeVersion - 1}
+	require.NoError(t, hb.Start())
+
+	err := ha.h.Connect(ha.ctx, hb.h.AddrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.h.AddrInfo(), createSendKeysMessage(t), new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+
+	err = hb.replaceSwapStream(testID, ha.PeerID(), nil, 0)
+	require.ErrorIs(t, err, errResumeNotSupported)
+}
+
+func TestSwap_ackSent(t *testing.T) {
+	msgA := &ResumeSwap{OfferID: types.Hash{0x1}}
+	msgB := &ResumeSwap{OfferID: types.Hash{0x2}}
+	msgC := &ResumeSwap{OfferID: types.Hash{0x3}}
+	sw := &swap{sent: []Message{msgA, msgB, msgC}}
+
+	missed, err := sw.ackSent(1)
+	require.NoError(t, err)
+	require.Equal(t, []Message{msgB, msgC}, missed)
+
+	// messages that were resent are kept until the peer confirms them
+	missed, err = sw.ackSent(1)
+	require.NoError(t, err)
+	require.Equal(t, []Message{msgB, msgC}, missed)
+
+	missed, err = sw.ackSent(3)
+	require.NoError(t, err)
+	require.Empty(t, missed)
+	require.Equal(t, uint64(3), sw.sentAcked)
+
+	// the peer can't have received fewer messages than it already confirmed,
+	// or more than we sent
+	_, err = sw.ackSent(2)
+	require.ErrorIs(t, err, errResumeMessageCount)
+	_, err = sw.ackSent(4)
+	require.ErrorIs(t, err, errResumeMessageCount)
+}
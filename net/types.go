@@ -1,39 +1,76 @@
 package net
 
 import (
+	"math/big"
+
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/net/message"
 
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
 type SwapState = common.SwapStateNet //nolint:revive
 
 //nolint:revive
 type (
-	MessageType        = byte
-	Message            = common.Message
-	QueryResponse      = message.QueryResponse
-	SendKeysMessage    = message.SendKeysMessage
-	RelayClaimRequest  = message.RelayClaimRequest
-	RelayClaimResponse = message.RelayClaimResponse
+	MessageType         = byte
+	Message             = common.Message
+	QueryRequest        = message.QueryRequest
+	QueryResponse       = message.QueryResponse
+	SendKeysMessage     = message.SendKeysMessage
+	VersionHandshake    = message.VersionHandshake
+	ResumeSwap          = message.ResumeSwap
+	RelayClaimRequest   = message.RelayClaimRequest
+	RelayClaimResponse  = message.RelayClaimResponse
+	RelayerTermsMessage = message.RelayerTermsMessage
 )
 
 // MakerHandler handles swap initiation messages and offer queries. It is
 // implemented by *xmrmaker.Instance.
 type MakerHandler interface {
-	GetOffers() []*types.Offer
-	HandleInitiateMessage(msg *SendKeysMessage) (SwapState, Message, error)
+	// GetOffersForPeer returns the offers served to the peer. An empty peer
+	// ID only gets the public offers.
+	GetOffersForPeer(who peer.ID) []*types.Offer
+	TakeableRanges(offers []*types.Offer) []*types.TakeableRange
+	HandleInitiateMessage(who peer.ID, msg *SendKeysMessage) (SwapState, Message, error)
 }
 
 // TakerHandler handles relay claim requests. It is implemented by
 // *xmrtaker.xmrtaker.
 type TakerHandler interface {
 	HandleRelayClaimRequest(msg *RelayClaimRequest) (*RelayClaimResponse, error)
+	// RelayerFees returns the minimum fees that we charge for relaying claims,
+	// per asset of the swap, which we advertise to claimers.
+	RelayerFees() map[types.EthAsset]*big.Int
 }
 
 type swap struct {
 	swapState SwapState
 	stream    libp2pnetwork.Stream
+
+	// peer is who the swap is with. If we opened the stream, we're the side
+	// that reopens it when it drops.
+	peer      peer.AddrInfo
+	initiator bool
+
+	// closed is set once we close the stream ourselves, so that it isn't
+	// reopened
+	closed bool
+
+	// resumed is set while the dropped stream is waiting to be reopened, and
+	// is closed once it's been reopened or we gave up on it
+	resumed chan struct{}
+
+	// version is the swap protocol version negotiated with the peer
+	version uint16
+
+	// received counts the swap messages that we received from the peer, and
+	// sent holds the ones we sent that the peer hasn't confirmed receiving,
+	// starting with message number sentAcked, to resend them if the stream
+	// drops
+	received  uint64
+	sent      []Message
+	sentAcked uint64
 }
@@ -0,0 +1,98 @@
+package net
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	p2pnet "github.com/athanorlabs/go-p2p-net"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+
+	"github.com/athanorlabs/atomic-swap/dleq"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const maxVersionHandshakeSize = 512
+
+// exchangeVersions is called by the side that opened a swap stream. It sends
+// the swap protocol versions we support, and returns the highest version that
+// the peer supports as well. An error wrapping errIncompatibleVersion is
+// returned if there is no such version, or the peer doesn't do the handshake.
+func (h *Host) exchangeVersions(stream libp2pnetwork.Stream) (uint16, error) {
+	ours := &VersionHandshake{Versions: h.swapVersions}
+	if err := p2pnet.WriteStreamMessage(stream, ours, stream.Conn().RemotePeer()); err != nil {
+		return 0, err
+	}
+
+	if err := stream.SetReadDeadline(time.Now().Add(protocolTimeout)); err != nil {
+		return 0, err
+	}
+	defer func() { _ = stream.SetReadDeadline(time.Time{}) }()
+
+	msg, err := h.readPeerMessage(stream, maxVersionHandshakeSize)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			// peers that predate the handshake close the stream when they
+			// can't decode it
+			return 0, fmt.Errorf("%w: peer closed the stream without replying to our version handshake",
+				errIncompatibleVersion)
+		}
+		return 0, fmt.Errorf("failed to read version handshake from peer: %w", err)
+	}
+
+	theirs, ok := msg.(*VersionHandshake)
+	if !ok {
+		return 0, fmt.Errorf("%w: peer replied with %s instead of a version handshake",
+			errIncompatibleVersion, message.TypeToString(msg.Type()))
+	}
+
+	return h.agreeVersion(theirs)
+}
+
+// handleVersionHandshake is called by the side that accepted a swap stream,
+// with the first message read from it. It replies with the swap protocol
+// versions we support, even if none are supported by the peer, so that the
+// peer can report why the swap can't go ahead.
+func (h *Host) handleVersionHandshake(stream libp2pnetwork.Stream, msg Message) (uint16, error) {
+	theirs, ok := msg.(*VersionHandshake)
+	if !ok {
+		return 0, fmt.Errorf("%w: peer sent %s before a version handshake",
+			errIncompatibleVersion, message.TypeToString(msg.Type()))
+	}
+
+	ours := &VersionHandshake{Versions: h.swapVersions}
+	if err := p2pnet.WriteStreamMessage(stream, ours, stream.Conn().RemotePeer()); err != nil {
+		return 0, err
+	}
+
+	return h.agreeVersion(theirs)
+}
+
+func (h *Host) agreeVersion(theirs *VersionHandshake) (uint16, error) {
+	version, ok := message.CommonVersion(h.swapVersions, theirs.Versions)
+	if !ok {
+		return 0, fmt.Errorf("%w: we support %v, peer supports %v",
+			errIncompatibleVersion, h.swapVersions, theirs.Versions)
+	}
+
+	return version, nil
+}
+
+// checkMessageVersion returns an error wrapping errVersionFeature if the swap
+// message uses a feature that the negotiated swap protocol version doesn't
+// have, so that we neither send nor accept it.
+func checkMessageVersion(msg Message, version uint16) error {
+	m, ok := msg.(*SendKeysMessage)
+	if !ok {
+		return nil
+	}
+
+	// peers that predate proof schemes assume the original one
+	if m.DLEqProofScheme != dleq.ProofSchemeSecp256k1Ed25519 && version < message.MinProofSchemeVersion {
+		return fmt.Errorf("%w: DLEq proof scheme %s requires version %d, swap uses version %d",
+			errVersionFeature, m.DLEqProofScheme, message.MinProofSchemeVersion, version)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package observer
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/watcher"
+)
+
+// logChSize is the buffer size of the channel that the event filters send the
+// swap factory's logs to.
+const logChSize = 64
+
+var (
+	newTopic      = common.GetTopic(common.NewEventSignature)
+	readyTopic    = common.GetTopic(common.ReadyEventSignature)
+	claimedTopic  = common.GetTopic(common.ClaimedEventSignature)
+	refundedTopic = common.GetTopic(common.RefundedEventSignature)
+)
+
+// stageNames are the ObservedSwap stages of each swap factory event.
+var stageNames = map[ethcommon.Hash]string{
+	newTopic:      "New",
+	readyTopic:    "Ready",
+	claimedTopic:  "Claimed",
+	refundedTopic: "Refunded",
+}
+
+// stageOrder orders the stages of a swap, as the logs of different stages can
+// arrive out of order, since each stage has its own event filter.
+var stageOrder = map[string]int{
+	"New":      1,
+	"Ready":    2,
+	"Claimed":  3,
+	"Refunded": 3,
+}
+
+// WatchChain records the swaps of the swap factory contract at the passed
+// address from the current block on, polling the chain every pollInterval,
+// until the observer's context is cancelled. Zero uses the watcher package's
+// default poll interval.
+func (o *Observer) WatchChain(ec *ethclient.Client, swapFactory ethcommon.Address, pollInterval time.Duration) error {
+	filterer, err := contracts.NewSwapFactoryFilterer(swapFactory, ec)
+	if err != nil {
+		return err
+	}
+
+	head, err := ec.BlockNumber(o.ctx)
+	if err != nil {
+		return err
+	}
+
+	logCh := make(chan ethtypes.Log, logChSize)
+	for topic := range stageNames {
+		filter := watcher.NewEventFilter(o.ctx, ec, swapFactory, new(big.Int).SetUint64(head), topic, logCh)
+		filter.SetPollInterval(pollInterval)
+		if err = filter.Start(); err != nil {
+			return err
+		}
+	}
+
+	go o.recordSwapLogs(o.ctx, filterer, logCh)
+	return nil
+}
+
+// recordSwapLogs records the swap factory logs from logCh until ctx is
+// cancelled.
+func (o *Observer) recordSwapLogs(ctx context.Context, filterer *contracts.SwapFactoryFilterer, logCh <-chan ethtypes.Log) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case l := <-logCh:
+			if err := o.recordSwapLog(filterer, &l); err != nil {
+				log.Warnf("failed to record swap factory log in tx %s: %s", l.TxHash, err)
+			}
+		}
+	}
+}
+
+// recordSwapLog updates the swap of a swap factory log.
+func (o *Observer) recordSwapLog(filterer *contracts.SwapFactoryFilterer, l *ethtypes.Log) error {
+	if len(l.Topics) == 0 {
+		return nil
+	}
+
+	stage, ok := stageNames[l.Topics[0]]
+	if !ok {
+		return nil
+	}
+
+	var (
+		swapID types.Hash
+		newEv  *contracts.SwapFactoryNew
+	)
+	if l.Topics[0] == newTopic {
+		var err error
+		newEv, err = filterer.ParseNew(*l)
+		if err != nil {
+			return err
+		}
+		swapID = newEv.SwapID
+	} else {
+		if len(l.Topics) < 2 {
+			return errLogMissingSwapID
+		}
+		swapID = types.Hash(l.Topics[1])
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	swap := o.swaps[swapID]
+	if swap == nil {
+		swap = &types.ObservedSwap{SwapID: swapID}
+		o.addSwap(swap)
+	}
+
+	if newEv != nil {
+		swap.Asset = types.EthAsset(newEv.Asset)
+		swap.Value = newEv.Value
+		t0 := time.Unix(newEv.Timeout0.Int64(), 0)
+		t1 := time.Unix(newEv.Timeout1.Int64(), 0)
+		swap.Timeout0 = &t0
+		swap.Timeout1 = &t1
+	}
+
+	if stageOrder[stage] <= stageOrder[swap.Stage] {
+		return nil
+	}
+
+	swap.Stage = stage
+	swap.TxHash = l.TxHash
+	swap.BlockNumber = l.BlockNumber
+	return nil
+}
+
+// addSwap adds a newly seen swap, dropping the swap that was seen first if we
+// have too many. The caller must hold the observer's lock.
+func (o *Observer) addSwap(swap *types.ObservedSwap) {
+	if len(o.swapOrder) >= o.maxSwaps {
+		delete(o.swaps, o.swapOrder[0])
+		o.swapOrder = o.swapOrder[1:]
+	}
+
+	o.swaps[swap.SwapID] = swap
+	o.swapOrder = append(o.swapOrder, swap.SwapID)
+}
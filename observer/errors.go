@@ -0,0 +1,7 @@
+package observer
+
+import (
+	"errors"
+)
+
+var errLogMissingSwapID = errors.New("log is missing the swap ID topic")
@@ -0,0 +1,237 @@
+// Package observer watches the network's offers and the swap factory's on-chain
+// swaps, for analytics, without holding funds or taking part in swaps.
+package observer
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	logging "github.com/ipfs/go-log"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	// DefaultPollInterval is how often, by default, the observer discovers
+	// makers and queries them for their offers.
+	DefaultPollInterval = 5 * time.Minute
+
+	// DefaultOfferExpiry is how long, by default, an offer is kept after the
+	// last time it was seen.
+	DefaultOfferExpiry = time.Hour
+
+	// DefaultMaxSwaps is how many on-chain swaps are kept, by default. The
+	// swaps that were seen first are dropped when there are more.
+	DefaultMaxSwaps = 10000
+
+	// discoverTime is how long each discovery of makers searches the DHT.
+	discoverTime = 12 * time.Second
+)
+
+var log = logging.Logger("observer")
+
+// Network is the part of the p2p host that the observer uses.
+type Network interface {
+	Discover(provides string, searchTime time.Duration) ([]peer.ID, error)
+	Query(who peer.ID) (*message.QueryResponse, error)
+}
+
+// Config contains the configuration of an Observer.
+type Config struct {
+	Ctx     context.Context
+	Network Network
+
+	// PollInterval is how often makers are discovered and queried. Zero uses
+	// DefaultPollInterval.
+	PollInterval time.Duration
+
+	// OfferExpiry is how long offers are kept after they were last seen. Zero
+	// uses DefaultOfferExpiry.
+	OfferExpiry time.Duration
+
+	// MaxSwaps is how many on-chain swaps are kept. Zero uses
+	// DefaultMaxSwaps.
+	MaxSwaps uint
+}
+
+type observedOfferKey struct {
+	peerID  peer.ID
+	offerID types.Hash
+}
+
+// Observer records the offers advertised by makers and, if WatchChain is
+// called, the swaps of the swap factory contract.
+type Observer struct {
+	ctx          context.Context
+	net          Network
+	pollInterval time.Duration
+	offerExpiry  time.Duration
+	maxSwaps     int
+	now          func() time.Time // overridden in tests
+
+	mu sync.RWMutex
+	// offers are keyed by maker and offer ID, as a maker could advertise the
+	// ID of another maker's offer
+	offers map[observedOfferKey]*types.ObservedOffer
+	swaps  map[types.Hash]*types.ObservedSwap
+	// swapOrder holds the swap IDs in the order they were first seen, so the
+	// oldest can be dropped
+	swapOrder []types.Hash
+}
+
+// NewObserver returns a new Observer. Start must be called for it to query
+// makers.
+func NewObserver(cfg *Config) *Observer {
+	pollInterval := cfg.PollInterval
+	if pollInterval == 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	offerExpiry := cfg.OfferExpiry
+	if offerExpiry == 0 {
+		offerExpiry = DefaultOfferExpiry
+	}
+
+	maxSwaps := cfg.MaxSwaps
+	if maxSwaps == 0 {
+		maxSwaps = DefaultMaxSwaps
+	}
+
+	return &Observer{
+		ctx:          cfg.Ctx,
+		net:          cfg.Network,
+		pollInterval: pollInterval,
+		offerExpiry:  offerExpiry,
+		maxSwaps:     int(maxSwaps),
+		now:          time.Now,
+		offers:       make(map[observedOfferKey]*types.ObservedOffer),
+		swaps:        make(map[types.Hash]*types.ObservedSwap),
+	}
+}
+
+// Start queries makers for their offers every poll interval, until the
+// observer's context is cancelled.
+func (o *Observer) Start() {
+	go func() {
+		ticker := time.NewTicker(o.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			o.poll()
+
+			select {
+			case <-o.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// poll discovers the makers of both coins, queries each of them for their
+// offers and drops the offers that expired.
+func (o *Observer) poll() {
+	seen := make(map[peer.ID]struct{})
+	for _, provides := range []coins.ProvidesCoin{coins.ProvidesXMR, coins.ProvidesETH} {
+		peerIDs, err := o.net.Discover(string(provides), discoverTime)
+		if err != nil {
+			log.Warnf("failed to discover makers providing %s: %s", provides, err)
+			continue
+		}
+
+		for _, who := range peerIDs {
+			if _, has := seen[who]; has {
+				continue
+			}
+			seen[who] = struct{}{}
+
+			if o.ctx.Err() != nil {
+				return
+			}
+
+			resp, err := o.net.Query(who)
+			if err != nil {
+				log.Debugf("failed to query maker %s: %s", who, err)
+				continue
+			}
+
+			o.recordOffers(who, resp.Offers)
+		}
+	}
+
+	o.expireOffers()
+}
+
+// recordOffers records the offers that a maker replied with.
+func (o *Observer) recordOffers(who peer.ID, offers []*types.Offer) {
+	now := o.now()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, offer := range offers {
+		key := observedOfferKey{peerID: who, offerID: offer.ID}
+		if observed, has := o.offers[key]; has {
+			observed.LastSeen = now
+			continue
+		}
+
+		o.offers[key] = &types.ObservedOffer{
+			PeerID:    who,
+			Offer:     offer,
+			FirstSeen: now,
+			LastSeen:  now,
+		}
+	}
+}
+
+// expireOffers drops the offers that weren't seen within the offer expiry.
+func (o *Observer) expireOffers() {
+	cutoff := o.now().Add(-o.offerExpiry)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for key, observed := range o.offers {
+		if observed.LastSeen.Before(cutoff) {
+			delete(o.offers, key)
+		}
+	}
+}
+
+// Offers returns the observed offers, with those that were seen first first.
+func (o *Observer) Offers() []*types.ObservedOffer {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	offers := make([]*types.ObservedOffer, 0, len(o.offers))
+	for _, observed := range o.offers {
+		c := *observed
+		offers = append(offers, &c)
+	}
+
+	sort.Slice(offers, func(i, j int) bool {
+		return offers[i].FirstSeen.Before(offers[j].FirstSeen)
+	})
+	return offers
+}
+
+// Swaps returns the observed on-chain swaps, with those that were seen first
+// first.
+func (o *Observer) Swaps() []*types.ObservedSwap {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	swaps := make([]*types.ObservedSwap, 0, len(o.swapOrder))
+	for _, id := range o.swapOrder {
+		c := *o.swaps[id]
+		swaps = append(swaps, &c)
+	}
+
+	return swaps
+}
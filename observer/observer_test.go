@@ -0,0 +1,128 @@
+package observer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+type mockNetwork struct {
+	makers map[peer.ID][]*types.Offer
+}
+
+func (n *mockNetwork) Discover(provides string, _ time.Duration) ([]peer.ID, error) {
+	if provides != string(coins.ProvidesXMR) {
+		return nil, nil
+	}
+
+	var ids []peer.ID
+	for id := range n.makers {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (n *mockNetwork) Query(who peer.ID) (*message.QueryResponse, error) {
+	offers, has := n.makers[who]
+	if !has {
+		return nil, errors.New("unreachable")
+	}
+	return &message.QueryResponse{Offers: offers}, nil
+}
+
+func newTestOffer() *types.Offer {
+	return types.NewOffer(
+		coins.ProvidesXMR,
+		coins.StrToDecimal("1"),
+		coins.StrToDecimal("2"),
+		coins.ToExchangeRate(coins.StrToDecimal("0.1")),
+		types.EthAssetETH,
+	)
+}
+
+func TestObserver_poll(t *testing.T) {
+	maker := peer.ID("maker")
+	offer := newTestOffer()
+	network := &mockNetwork{makers: map[peer.ID][]*types.Offer{maker: {offer}}}
+
+	o := NewObserver(&Config{Ctx: context.Background(), Network: network})
+	now := time.Now()
+	o.now = func() time.Time { return now }
+
+	o.poll()
+	offers := o.Offers()
+	require.Len(t, offers, 1)
+	require.Equal(t, maker, offers[0].PeerID)
+	require.Equal(t, offer.ID, offers[0].Offer.ID)
+	require.Equal(t, now, offers[0].FirstSeen)
+
+	// offers that are seen again are kept
+	firstSeen := now
+	now = now.Add(DefaultOfferExpiry)
+	o.poll()
+	offers = o.Offers()
+	require.Len(t, offers, 1)
+	require.Equal(t, firstSeen, offers[0].FirstSeen)
+	require.Equal(t, now, offers[0].LastSeen)
+
+	// offers that are no longer advertised expire
+	network.makers[maker] = nil
+	now = now.Add(DefaultOfferExpiry + time.Second)
+	o.poll()
+	require.Empty(t, o.Offers())
+}
+
+func TestObserver_recordOffers_sameIDFromOtherPeer(t *testing.T) {
+	o := NewObserver(&Config{Ctx: context.Background(), Network: &mockNetwork{}})
+	offer := newTestOffer()
+
+	// another maker advertising the same offer ID doesn't replace the offer
+	o.recordOffers(peer.ID("maker"), []*types.Offer{offer})
+	o.recordOffers(peer.ID("spoofer"), []*types.Offer{offer})
+
+	offers := o.Offers()
+	require.Len(t, offers, 2)
+	peers := []peer.ID{offers[0].PeerID, offers[1].PeerID}
+	require.ElementsMatch(t, []peer.ID{"maker", "spoofer"}, peers)
+}
+
+func TestObserver_recordSwapLog(t *testing.T) {
+	o := NewObserver(&Config{Ctx: context.Background(), MaxSwaps: 2})
+
+	swapLog := func(topic ethcommon.Hash, id types.Hash, block uint64) *ethtypes.Log {
+		return &ethtypes.Log{
+			Topics:      []ethcommon.Hash{topic, ethcommon.Hash(id)},
+			BlockNumber: block,
+		}
+	}
+
+	// logs of later stages can arrive first, and aren't overwritten
+	require.NoError(t, o.recordSwapLog(nil, swapLog(claimedTopic, types.Hash{1}, 12)))
+	require.NoError(t, o.recordSwapLog(nil, swapLog(readyTopic, types.Hash{1}, 11)))
+	swaps := o.Swaps()
+	require.Len(t, swaps, 1)
+	require.Equal(t, "Claimed", swaps[0].Stage)
+	require.Equal(t, uint64(12), swaps[0].BlockNumber)
+
+	// the swaps that were seen first are dropped
+	require.NoError(t, o.recordSwapLog(nil, swapLog(readyTopic, types.Hash{2}, 13)))
+	require.NoError(t, o.recordSwapLog(nil, swapLog(refundedTopic, types.Hash{3}, 14)))
+	swaps = o.Swaps()
+	require.Len(t, swaps, 2)
+	require.Equal(t, types.Hash{2}, swaps[0].SwapID)
+	require.Equal(t, types.Hash{3}, swaps[1].SwapID)
+	require.Equal(t, "Refunded", swaps[1].Stage)
+
+	err := o.recordSwapLog(nil, &ethtypes.Log{Topics: []ethcommon.Hash{readyTopic}})
+	require.ErrorIs(t, err, errLogMissingSwapID)
+}
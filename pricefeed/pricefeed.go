@@ -13,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	logging "github.com/ipfs/go-log"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 )
@@ -142,3 +143,55 @@ func getChainlinkPriceFeed(ctx context.Context, feedAddress string, ec *ethclien
 		UpdatedAt:   updatedAt,
 	}, nil
 }
+
+// ChainlinkOracle is a price oracle that computes the XMR/ETH exchange rate
+// from the Chainlink XMR/USD and ETH/USD price feeds.
+type ChainlinkOracle struct {
+	ec *ethclient.Client
+}
+
+// NewChainlinkOracle returns a new *ChainlinkOracle using the passed ethereum client.
+func NewChainlinkOracle(ec *ethclient.Client) *ChainlinkOracle {
+	return &ChainlinkOracle{ec: ec}
+}
+
+// ExchangeRate returns the current XMR/ETH exchange rate.
+func (o *ChainlinkOracle) ExchangeRate(ctx context.Context) (*coins.ExchangeRate, error) {
+	xmrFeed, err := GetXMRUSDPrice(ctx, o.ec)
+	if err != nil {
+		return nil, err
+	}
+
+	ethFeed, err := GetETHUSDPrice(ctx, o.ec)
+	if err != nil {
+		return nil, err
+	}
+
+	return coins.CalcExchangeRate(xmrFeed.Price, ethFeed.Price)
+}
+
+// Currency returns the fiat currency of the prices returned by FiatPrices.
+func (o *ChainlinkOracle) Currency() string {
+	return "USD"
+}
+
+// FiatPrices returns the current XMR/USD and ETH/USD prices, along with when
+// the older of the two feeds was last updated.
+func (o *ChainlinkOracle) FiatPrices(ctx context.Context) (*apd.Decimal, *apd.Decimal, time.Time, error) {
+	xmrFeed, err := GetXMRUSDPrice(ctx, o.ec)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	ethFeed, err := GetETHUSDPrice(ctx, o.ec)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	updatedAt := xmrFeed.UpdatedAt
+	if ethFeed.UpdatedAt.Before(updatedAt) {
+		updatedAt = ethFeed.UpdatedAt
+	}
+
+	return xmrFeed.Price, ethFeed.Price, updatedAt, nil
+}
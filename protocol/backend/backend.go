@@ -4,30 +4,37 @@ package backend
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	logging "github.com/ipfs/go-log"
 	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/db"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/ethereum/watcher"
 	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/txsender"
 )
 
+var log = logging.Logger("backend")
+
 // NetSender consists of Host methods invoked by the Maker/Taker
 type NetSender interface {
 	SendSwapMessage(common.Message, types.Hash) error
 	CloseProtocolStream(id types.Hash)
-	DiscoverRelayers() ([]peer.ID, error)                                                          // Only used by Maker
-	SubmitClaimToRelayer(peer.ID, *message.RelayClaimRequest) (*message.RelayClaimResponse, error) // Only used by Taker
+	DiscoverRelayers() ([]peer.ID, error)                                                             // Only used by Maker
+	MarkRelayerFailed(peer.ID)                                                                        // Only used by Maker
+	SubmitClaimToRelayer(peer.ID, message.RelayClaimRequestFunc) (*message.RelayClaimResponse, error) // Only used by Taker
 }
 
 // RecoveryDB is implemented by *db.RecoveryDB
@@ -42,6 +49,8 @@ type RecoveryDB interface {
 	GetSwapRelayerInfo(id types.Hash) (*types.OfferExtra, error)
 	PutCounterpartySwapKeys(id types.Hash, sk *mcrypto.PublicKey, vk *mcrypto.PrivateViewKey) error
 	GetCounterpartySwapKeys(id types.Hash) (*mcrypto.PublicKey, *mcrypto.PrivateViewKey, error)
+	PutXMRLockStarted(id types.Hash) error
+	HasXMRLockStarted(id types.Hash) (bool, error)
 	DeleteSwap(id types.Hash) error
 }
 
@@ -59,6 +68,14 @@ type Backend interface {
 
 	// helpers
 	NewSwapFactory(addr ethcommon.Address) (*contracts.SwapFactory, error)
+	EstimateXMRTransferFee(
+		amount *coins.PiconeroAmount,
+		priority types.MoneroTxPriority,
+	) (*coins.PiconeroAmount, error)
+	MoneroStartHeight() (uint64, error)
+	HealthCheck(ctx context.Context) (*types.HealthStatus, error)
+	GetContractSwapState(swapID types.Hash) (*types.ContractSwapState, error)
+	RebroadcastTx(txHash ethcommon.Hash, bumpGas bool) (ethcommon.Hash, error)
 
 	// getters
 	Ctx() context.Context
@@ -67,6 +84,13 @@ type Backend interface {
 	Contract() *contracts.SwapFactory
 	ContractAddr() ethcommon.Address
 	SwapTimeout() time.Duration
+	SweepConfirmations() uint64
+	ETHPollInterval() time.Duration
+	ETHSubscribe() bool
+	ResumeConcurrency() uint
+	PerSwapAccount() bool
+	DustThresholds() types.DustThresholds
+	ExchangeRateBounds() *coins.ExchangeRateBounds
 	XMRDepositAddress(offerID *types.Hash) *mcrypto.Address
 
 	// setters
@@ -90,6 +114,31 @@ type backend struct {
 	// address used by swapd. This sweep destination address can be overridden
 	// on a per-swap basis, by setting an address indexed by the offerID/swapID
 	// in the map below.
+	// how many blocks below the current height to start scanning for a swap's
+	// XMR lock transaction
+	moneroStartHeightRollback uint64
+
+	// how many confirmations to wait for when sweeping claimed XMR out of a
+	// swap wallet
+	sweepConfirmations uint64
+
+	// how often swap event watchers poll the ethereum node for new blocks,
+	// unless they subscribe to them
+	ethPollInterval time.Duration
+	ethSubscribe    bool
+
+	// how many ongoing swaps are resumed at once on startup
+	resumeConcurrency uint
+
+	// whether claimed or reclaimed XMR is swept to a wallet account created
+	// for each swap
+	perSwapAccount bool
+
+	// the minimum values of the offers that we make or take, and the range of
+	// exchange rates of ETH offers
+	dustThresholds     types.DustThresholds
+	exchangeRateBounds *coins.ExchangeRateBounds
+
 	perSwapXMRDepositAddrRWMu sync.RWMutex
 	perSwapXMRDepositAddr     map[types.Hash]*mcrypto.Address
 
@@ -112,6 +161,48 @@ type Config struct {
 	SwapManager        swap.Manager
 	RecoveryDB         RecoveryDB
 	Net                NetSender
+
+	// MoneroStartHeightRollback is how many blocks below the current height a
+	// swap's XMR lock scan starts, to tolerate block reorgs. Nil uses
+	// monero.MinSpendConfirmations, and zero disables the rollback, which is
+	// only safe on private dev chains.
+	MoneroStartHeightRollback *uint64
+
+	// SweepConfirmations is how many confirmations to wait for when sweeping
+	// claimed or reclaimed XMR out of a swap wallet. Zero uses
+	// monero.SweepToSelfConfirmations. Swept outputs aren't spendable until
+	// they have monero.MinSpendConfirmations, so outside of development
+	// environments, other values must be at least that.
+	SweepConfirmations uint64
+
+	// ETHPollInterval is how often the ethereum event watchers of swaps poll
+	// the node for new blocks. Zero uses watcher.DefaultPollInterval.
+	ETHPollInterval time.Duration
+
+	// ETHSubscribe has the ethereum event watchers of swaps check for new
+	// blocks when the node notifies them of one, instead of polling. The
+	// ethereum client must support subscriptions.
+	ETHSubscribe bool
+
+	// ResumeConcurrency is how many ongoing swaps are resumed at once on
+	// startup. Zero uses protocol.DefaultResumeConcurrency.
+	ResumeConcurrency uint
+
+	// PerSwapAccount sweeps the claimed or reclaimed XMR of each swap to an
+	// account of the primary wallet that is created for the swap, instead of
+	// account 0, so funds can be traced per swap. An XMR maker still locks
+	// from account 0, as a new account has no spendable funds. Deposit
+	// addresses set for a swap take precedence.
+	PerSwapAccount bool
+
+	// DustThresholds override the minimum value, in standard units of each
+	// asset, that the MinAmount of offers we make or take must be worth at
+	// their exchange rate.
+	DustThresholds types.DustThresholds
+
+	// ExchangeRateBounds is the range of exchange rates of ETH offers that we
+	// make or take. Nil doesn't bound them.
+	ExchangeRateBounds *coins.ExchangeRateBounds
 }
 
 // NewBackend returns a new Backend
@@ -120,11 +211,33 @@ func NewBackend(cfg *Config) (Backend, error) {
 		return nil, errNilSwapContractOrAddress
 	}
 
+	if cfg.ETHSubscribe && !cfg.EthereumClient.SupportsSubscriptions() {
+		return nil, extethclient.ErrSubscriptionsUnsupported
+	}
+
+	sweepConfirmations := cfg.SweepConfirmations
+	if sweepConfirmations == 0 {
+		sweepConfirmations = monero.SweepToSelfConfirmations
+	} else if sweepConfirmations < monero.MinSpendConfirmations && cfg.Environment != common.Development {
+		return nil, fmt.Errorf("%w: %d is less than %d",
+			errSweepConfirmationsTooLow, sweepConfirmations, monero.MinSpendConfirmations)
+	}
+
 	swapFactory, err := contracts.NewSwapFactory(cfg.SwapFactoryAddress, cfg.EthereumClient.Raw())
 	if err != nil {
 		return nil, err
 	}
 
+	ethPollInterval := cfg.ETHPollInterval
+	if ethPollInterval == 0 {
+		ethPollInterval = watcher.DefaultPollInterval
+	}
+
+	moneroStartHeightRollback := uint64(monero.MinSpendConfirmations)
+	if cfg.MoneroStartHeightRollback != nil {
+		moneroStartHeightRollback = *cfg.MoneroStartHeightRollback
+	}
+
 	return &backend{
 		ctx:                   cfg.Ctx,
 		env:                   cfg.Environment,
@@ -137,6 +250,15 @@ func NewBackend(cfg *Config) (Backend, error) {
 		NetSender:             cfg.Net,
 		perSwapXMRDepositAddr: make(map[types.Hash]*mcrypto.Address),
 		recoveryDB:            cfg.RecoveryDB,
+
+		moneroStartHeightRollback: moneroStartHeightRollback,
+		sweepConfirmations:        sweepConfirmations,
+		ethPollInterval:           ethPollInterval,
+		ethSubscribe:              cfg.ETHSubscribe,
+		resumeConcurrency:         cfg.ResumeConcurrency,
+		perSwapAccount:            cfg.PerSwapAccount,
+		dustThresholds:            cfg.DustThresholds,
+		exchangeRateBounds:        cfg.ExchangeRateBounds,
 	}, nil
 }
 
@@ -156,6 +278,19 @@ func (b *backend) NewTxSender(asset ethcommon.Address, erc20Contract *contracts.
 	return txsender.NewSenderWithPrivateKey(b.ctx, b.ETHClient(), b.contract, erc20Contract), nil
 }
 
+// RebroadcastTx sends the pending transaction with the passed hash again, or a
+// replacement of it with a higher gas price if bumpGas is set, for manually
+// unsticking claims and refunds. It's only supported when swapd holds the
+// ethereum key.
+func (b *backend) RebroadcastTx(txHash ethcommon.Hash, bumpGas bool) (ethcommon.Hash, error) {
+	sender, err := b.NewTxSender(types.EthAssetETH.Address(), nil)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	return sender.RebroadcastTx(txHash, bumpGas)
+}
+
 func (b *backend) RecoveryDB() RecoveryDB {
 	return b.recoveryDB
 }
@@ -184,6 +319,30 @@ func (b *backend) SwapTimeout() time.Duration {
 	return b.swapTimeout
 }
 
+// SweepConfirmations returns how many confirmations to wait for when sweeping
+// claimed XMR out of a swap wallet.
+func (b *backend) SweepConfirmations() uint64 {
+	return b.sweepConfirmations
+}
+
+// ETHPollInterval returns how often the ethereum event watchers of swaps poll
+// the node for new blocks.
+func (b *backend) ETHPollInterval() time.Duration {
+	return b.ethPollInterval
+}
+
+// ETHSubscribe returns whether the ethereum event watchers of swaps subscribe
+// to new blocks, instead of polling for them.
+func (b *backend) ETHSubscribe() bool {
+	return b.ethSubscribe
+}
+
+// ResumeConcurrency returns how many ongoing swaps are resumed at once on
+// startup, where zero means the default.
+func (b *backend) ResumeConcurrency() uint {
+	return b.resumeConcurrency
+}
+
 // SetSwapTimeout sets the duration between the swap being initiated on-chain and the timeout t0,
 // and the duration between t0 and t1.
 func (b *backend) SetSwapTimeout(timeout time.Duration) {
@@ -194,6 +353,34 @@ func (b *backend) NewSwapFactory(addr ethcommon.Address) (*contracts.SwapFactory
 	return contracts.NewSwapFactory(addr, b.ethClient.Raw())
 }
 
+// EstimateXMRTransferFee returns the network fee that transferring the given amount
+// from our primary wallet with the given priority would currently pay, without
+// relaying any transaction. The fee barely depends on the destination, so a
+// transfer to ourselves is used.
+func (b *backend) EstimateXMRTransferFee(
+	amount *coins.PiconeroAmount,
+	priority types.MoneroTxPriority,
+) (*coins.PiconeroAmount, error) {
+	return b.moneroWallet.EstimateTransferFee(b.moneroWallet.PrimaryAddress(), 0, amount, priority)
+}
+
+// PerSwapAccount returns whether claimed or reclaimed XMR is swept to a wallet
+// account created for each swap.
+func (b *backend) PerSwapAccount() bool {
+	return b.perSwapAccount
+}
+
+// DustThresholds returns the minimum values of the offers that we make or take.
+func (b *backend) DustThresholds() types.DustThresholds {
+	return b.dustThresholds
+}
+
+// ExchangeRateBounds returns the range of exchange rates of ETH offers that we
+// make or take, which is nil if they aren't bounded.
+func (b *backend) ExchangeRateBounds() *coins.ExchangeRateBounds {
+	return b.exchangeRateBounds
+}
+
 // XMRDepositAddress returns the per-swap override deposit address, if a
 // per-swap address was set. Otherwise the primary swapd Monero wallet address
 // is returned.
@@ -228,3 +415,21 @@ func (b *backend) ClearXMRDepositAddress(offerID types.Hash) {
 	defer b.perSwapXMRDepositAddrRWMu.Unlock()
 	delete(b.perSwapXMRDepositAddr, offerID)
 }
+
+// MoneroStartHeight returns the height to start scanning from for a new swap's
+// XMR lock transaction. This is the current wallet height, reduced by the
+// configured rollback in case there is a block reorg. The result is stored in
+// the swap's info, so recovering the swap later scans from the same height
+// even if the configured rollback has changed.
+func (b *backend) MoneroStartHeight() (uint64, error) {
+	height, err := b.moneroWallet.GetHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	if height < b.moneroStartHeightRollback {
+		return 0, nil
+	}
+
+	return height - b.moneroStartHeightRollback, nil
+}
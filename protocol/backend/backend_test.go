@@ -5,7 +5,10 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/tests"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -47,3 +50,42 @@ func TestWaitForReceipt(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, tx.Hash(), receipt.TxHash)
 }
+
+func TestMoneroStartHeight(t *testing.T) {
+	mc := monero.CreateWalletClient(t)
+	monero.MineMinXMRBalance(t, mc, coins.NewPiconeroAmount(1))
+
+	b := &backend{
+		moneroWallet:              mc,
+		moneroStartHeightRollback: 5,
+	}
+
+	height, err := mc.GetHeight()
+	require.NoError(t, err)
+
+	startHeight, err := b.MoneroStartHeight()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, startHeight+5, height)
+	require.Less(t, startHeight, height)
+
+	// a zero rollback scans from the current height
+	b.moneroStartHeightRollback = 0
+	startHeight, err = b.MoneroStartHeight()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, startHeight, height)
+
+	// the rollback can't go below the genesis block
+	b.moneroStartHeightRollback = height + 100
+	startHeight, err = b.MoneroStartHeight()
+	require.NoError(t, err)
+	require.Zero(t, startHeight)
+}
+
+func TestNewBackend_sweepConfirmationsTooLow(t *testing.T) {
+	_, err := NewBackend(&Config{
+		Environment:        common.Mainnet,
+		SwapFactoryAddress: ethcommon.Address{0x1},
+		SweepConfirmations: monero.MinSpendConfirmations - 1,
+	})
+	require.ErrorIs(t, err, errSweepConfirmationsTooLow)
+}
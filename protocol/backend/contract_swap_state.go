@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+// GetContractSwapState returns the progress of the swap with the passed ID,
+// read from the swap factory contract that its ETH was locked in. Unlike the
+// swap's status, this is authoritative, so it can be used to reconcile a swap
+// whose state diverged from the chain, eg. after a crash.
+func (b *backend) GetContractSwapState(swapID types.Hash) (*types.ContractSwapState, error) {
+	info, err := b.recoveryDB.GetContractSwapInfo(swapID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contract info of swap %s: %w", swapID, err)
+	}
+
+	contract, err := b.NewSwapFactory(info.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	stage, err := contract.Swaps(b.ethClient.CallOpts(b.ctx), info.SwapID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &types.ContractSwapState{
+		Stage:    types.ContractStage(stage),
+		Timeout0: time.Unix(info.Swap.Timeout0.Int64(), 0),
+		Timeout1: time.Unix(info.Swap.Timeout1.Int64(), 0),
+	}
+
+	if stage != contracts.StageCompleted {
+		return state, nil
+	}
+
+	// the contract only stores the stage, so whether the swap was claimed or
+	// refunded is taken from the event that completed it
+	filterOpts := &bind.FilterOpts{
+		Start:   info.StartNumber.Uint64(),
+		Context: b.ctx,
+	}
+
+	claimed, err := contract.FilterClaimed(filterOpts, [][32]byte{info.SwapID}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter claimed logs: %w", err)
+	}
+	defer claimed.Close()
+	state.Claimed = claimed.Next()
+	if claimed.Error() != nil {
+		return nil, fmt.Errorf("failed to read claimed logs: %w", claimed.Error())
+	}
+
+	state.Refunded = !state.Claimed
+	return state, nil
+}
@@ -6,4 +6,5 @@ import (
 
 var (
 	errNilSwapContractOrAddress = errors.New("must provide swap contract and address")
+	errSweepConfirmationsTooLow = errors.New("sweep confirmations are below the minimum spend confirmations")
 )
@@ -0,0 +1,81 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/monero"
+)
+
+// HealthCheck reports whether our ethereum endpoint and monero wallet are
+// reachable and synced. Unreachable endpoints are reported in the returned
+// status, so an error is only returned if the context is done.
+func (b *backend) HealthCheck(ctx context.Context) (*types.HealthStatus, error) {
+	status := &types.HealthStatus{
+		Ethereum: b.ethereumHealth(ctx),
+		Monero:   b.moneroHealth(),
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	status.Healthy = status.Ethereum.Reachable && !status.Ethereum.Syncing &&
+		status.Monero.Reachable && status.Monero.DaemonSynchronised && status.Monero.WalletRefreshed
+
+	return status, nil
+}
+
+func (b *backend) ethereumHealth(ctx context.Context) *types.EthereumHealth {
+	health := &types.EthereumHealth{
+		ChainID: b.ethClient.ChainID().Uint64(),
+	}
+
+	header, err := b.ethClient.Raw().HeaderByNumber(ctx, nil)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	health.LatestBlock = header.Number.Uint64()
+	health.LatestBlockTime = time.Unix(int64(header.Time), 0)
+
+	// a nil sync progress means the node is not syncing
+	progress, err := b.ethClient.Raw().SyncProgress(ctx)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	health.Reachable = true
+	if progress != nil {
+		health.Syncing = true
+		if progress.HighestBlock > progress.CurrentBlock {
+			health.SyncLag = progress.HighestBlock - progress.CurrentBlock
+		}
+	}
+
+	return health
+}
+
+func (b *backend) moneroHealth() *types.MoneroHealth {
+	health := new(types.MoneroHealth)
+
+	sync, err := monero.GetSyncStatus(b.moneroWallet)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	health.Reachable = true
+	health.DaemonSynchronised = sync.DaemonSynchronised
+	health.DaemonHeight = sync.DaemonHeight
+	health.TargetHeight = sync.TargetHeight
+	health.WalletHeight = sync.WalletHeight
+	health.WalletRefreshed = sync.WalletHeight >= sync.DaemonHeight
+	if sync.TargetHeight > sync.WalletHeight {
+		health.SyncLag = sync.TargetHeight - sync.WalletHeight
+	}
+
+	return health
+}
@@ -128,6 +128,21 @@ func (mr *MockRecoveryDBMockRecorder) GetSwapRelayerInfo(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSwapRelayerInfo", reflect.TypeOf((*MockRecoveryDB)(nil).GetSwapRelayerInfo), arg0)
 }
 
+// HasXMRLockStarted mocks base method.
+func (m *MockRecoveryDB) HasXMRLockStarted(arg0 common.Hash) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasXMRLockStarted", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasXMRLockStarted indicates an expected call of HasXMRLockStarted.
+func (mr *MockRecoveryDBMockRecorder) HasXMRLockStarted(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasXMRLockStarted", reflect.TypeOf((*MockRecoveryDB)(nil).HasXMRLockStarted), arg0)
+}
+
 // PutContractSwapInfo mocks base method.
 func (m *MockRecoveryDB) PutContractSwapInfo(arg0 common.Hash, arg1 *db.EthereumSwapInfo) error {
 	m.ctrl.T.Helper()
@@ -197,3 +212,17 @@ func (mr *MockRecoveryDBMockRecorder) PutSwapRelayerInfo(arg0, arg1 interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutSwapRelayerInfo", reflect.TypeOf((*MockRecoveryDB)(nil).PutSwapRelayerInfo), arg0, arg1)
 }
+
+// PutXMRLockStarted mocks base method.
+func (m *MockRecoveryDB) PutXMRLockStarted(arg0 common.Hash) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutXMRLockStarted", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutXMRLockStarted indicates an expected call of PutXMRLockStarted.
+func (mr *MockRecoveryDBMockRecorder) PutXMRLockStarted(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutXMRLockStarted", reflect.TypeOf((*MockRecoveryDB)(nil).PutXMRLockStarted), arg0)
+}
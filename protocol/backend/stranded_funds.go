@@ -0,0 +1,141 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/monero"
+)
+
+// SweepStrandedSwapFunds looks for XMR left in the shared addresses of past
+// swaps, such as the leftovers of aborted swaps, and sweeps it to the primary
+// address of our wallet. Only swaps whose private spend keys of both parties
+// are in the recovery db can be checked, and swaps that are still in flight are
+// skipped. The XMR found in each address is returned, with the transaction IDs
+// of its sweep, or the error if it couldn't be swept.
+func (b *backend) SweepStrandedSwapFunds(ctx context.Context) ([]*types.StrandedSwapFunds, error) {
+	ids, err := b.swapManager.GetPastIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	found := []*types.StrandedSwapFunds{}
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return found, ctx.Err()
+		}
+
+		// a past swap shouldn't be ongoing, but as its keys are shared with an
+		// in-flight swap if it is, we double-check
+		if _, err = b.swapManager.GetOngoingSwap(id); err == nil {
+			log.Debugf("skipping stranded funds check of in-flight swap %s", id)
+			continue
+		}
+
+		funds, checkErr := b.sweepStrandedSwapFunds(ctx, id)
+		if checkErr != nil {
+			log.Debugf("skipping stranded funds check of swap %s: %s", id, checkErr)
+			continue
+		}
+
+		if funds != nil {
+			found = append(found, funds)
+		}
+	}
+
+	return found, nil
+}
+
+// sweepStrandedSwapFunds checks the shared address of a single past swap. It
+// returns nil if the address is empty, and an error if it can't be checked.
+func (b *backend) sweepStrandedSwapFunds(ctx context.Context, id types.Hash) (*types.StrandedSwapFunds, error) {
+	info, err := b.swapManager.GetPastSwap(id)
+	if err != nil {
+		return nil, err
+	}
+
+	kpAB, err := b.swapClaimKeypair(id)
+	if err != nil {
+		return nil, err
+	}
+
+	address := kpAB.PublicKeyPair().Address(b.env)
+
+	viewConf := b.XMRClient().CreateWalletConf(fmt.Sprintf("swap-wallet-stranded-view-%s", id))
+	viewWalletCli, err := monero.CreateViewOnlyWalletFromKeys(viewConf, kpAB.ViewKey(), address, info.MoneroStartHeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create view-only wallet for %s: %w", address, err)
+	}
+
+	bal, err := viewWalletCli.GetBalance(0)
+	viewWalletCli.CloseAndRemoveWallet()
+	if err != nil {
+		return nil, err
+	}
+
+	if bal.Balance == 0 {
+		return nil, nil
+	}
+
+	funds := &types.StrandedSwapFunds{
+		SwapID:  id,
+		Address: address.String(),
+		Amount:  coins.NewPiconeroAmount(bal.Balance).AsMonero(),
+	}
+
+	depositAddr := b.XMRClient().PrimaryAddress()
+	log.Infof("found %s XMR stranded in address %s of swap %s; sweeping to %s",
+		coins.FmtPiconeroAsXMR(bal.Balance), address, id, depositAddr)
+
+	spendConf := b.XMRClient().CreateWalletConf(fmt.Sprintf("swap-wallet-stranded-%s", id))
+	abWalletCli, err := monero.CreateSpendWalletFromKeys(spendConf, kpAB, info.MoneroStartHeight)
+	if err != nil {
+		funds.Error = err.Error()
+		return funds, nil
+	}
+	defer abWalletCli.CloseAndRemoveWallet()
+
+	transfers, err := abWalletCli.SweepAll(ctx, depositAddr, 0, monero.SweepToSelfConfirmations)
+	if err != nil {
+		funds.Error = err.Error()
+		return funds, nil
+	}
+
+	for _, transfer := range transfers {
+		funds.TxIDs = append(funds.TxIDs, transfer.TxID)
+	}
+
+	return funds, nil
+}
+
+// swapClaimKeypair returns the key pair of the shared address of the swap,
+// from both parties' keys in the recovery db.
+func (b *backend) swapClaimKeypair(id types.Hash) (*mcrypto.PrivateKeyPair, error) {
+	skOurs, err := b.recoveryDB.GetSwapPrivateKey(id)
+	if err != nil {
+		return nil, fmt.Errorf("our swap private key not found: %w", err)
+	}
+
+	skTheirs, err := b.recoveryDB.GetCounterpartySwapPrivateKey(id)
+	if err != nil {
+		return nil, fmt.Errorf("counterparty's swap private key not found: %w", err)
+	}
+
+	_, vkTheirs, err := b.recoveryDB.GetCounterpartySwapKeys(id)
+	if err != nil {
+		return nil, fmt.Errorf("counterparty's swap view key not found: %w", err)
+	}
+
+	vkOurs, err := skOurs.View()
+	if err != nil {
+		return nil, err
+	}
+
+	return mcrypto.NewPrivateKeyPair(
+		mcrypto.SumPrivateSpendKeys(skOurs, skTheirs),
+		mcrypto.SumPrivateViewKeys(vkOurs, vkTheirs),
+	), nil
+}
@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+)
+
+func TestSwapClaimKeypair(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rdb := NewMockRecoveryDB(ctrl)
+	b := &backend{recoveryDB: rdb}
+	id := types.Hash{1, 2, 3}
+
+	kpOurs, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	kpTheirs, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+
+	rdb.EXPECT().GetSwapPrivateKey(id).Return(kpOurs.SpendKey(), nil)
+	rdb.EXPECT().GetCounterpartySwapPrivateKey(id).Return(kpTheirs.SpendKey(), nil)
+	rdb.EXPECT().GetCounterpartySwapKeys(id).Return(kpTheirs.SpendKey().Public(), kpTheirs.ViewKey(), nil)
+
+	kpAB, err := b.swapClaimKeypair(id)
+	require.NoError(t, err)
+
+	expected := mcrypto.SumSpendAndViewKeys(kpOurs.PublicKeyPair(), kpTheirs.PublicKeyPair())
+	require.Equal(t, expected.SpendKey().String(), kpAB.PublicKeyPair().SpendKey().String())
+	require.Equal(t, expected.ViewKey().String(), kpAB.PublicKeyPair().ViewKey().String())
+
+	// without the counterparty's private spend key, the address can't be swept
+	rdb.EXPECT().GetSwapPrivateKey(id).Return(kpOurs.SpendKey(), nil)
+	rdb.EXPECT().GetCounterpartySwapPrivateKey(id).Return(nil, errors.New("not found"))
+	_, err = b.swapClaimKeypair(id)
+	require.ErrorContains(t, err, "counterparty's swap private key not found")
+}
@@ -3,6 +3,7 @@ package protocol
 import (
 	"context"
 	"fmt"
+	"path"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
@@ -31,7 +32,8 @@ func GetClaimKeypair(
 }
 
 // ClaimMonero claims the XMR located in the wallet controlled by the private keypair `kpAB`.
-// If noTransferBack is unset, it sweeps the XMR to `depositAddr`.
+// If noTransferBack is unset, it sweeps the XMR to `depositAddr`, waiting for
+// `sweepConfirmations` confirmations of the sweep.
 func ClaimMonero(
 	ctx context.Context,
 	env common.Environment,
@@ -41,6 +43,7 @@ func ClaimMonero(
 	kpAB *mcrypto.PrivateKeyPair,
 	depositAddr *mcrypto.Address,
 	noTransferBack bool,
+	sweepConfirmations uint64,
 ) error {
 	conf := xmrClient.CreateWalletConf(fmt.Sprintf("swap-wallet-claim-%s", id))
 	abWalletCli, err := monero.CreateSpendWalletFromKeys(conf, kpAB, walletScanHeight)
@@ -59,17 +62,90 @@ func ClaimMonero(
 	log.Infof("monero claimed in account %s; transferring to deposit account %s",
 		address, depositAddr)
 
-	err = depositAddr.ValidateEnv(env)
+	return sweepToDepositAddress(ctx, env, abWalletCli, depositAddr, sweepConfirmations)
+}
+
+// RecoverMoneroFromKeys sweeps the XMR locked in a swap to `depositAddr` using
+// only the swap keys, for when the swap's database records are lost. The claim
+// keypair is reconstructed from both parties' private spend keys (ours, and
+// the counterparty's as stored by PutCounterpartySwapPrivateKey) and the
+// shared view key (v_a + v_b). A view-only wallet is first used to check that
+// the reconstructed address holds funds, so nothing is swept from an empty or
+// mistyped account.
+func RecoverMoneroFromKeys(
+	ctx context.Context,
+	env common.Environment,
+	conf *monero.WalletClientConf,
+	walletScanHeight uint64,
+	ourSpendKey, theirSpendKey *mcrypto.PrivateSpendKey,
+	sharedViewKey *mcrypto.PrivateViewKey,
+	depositAddr *mcrypto.Address,
+) error {
+	if err := depositAddr.ValidateEnv(env); err != nil {
+		return fmt.Errorf("invalid deposit address: %w", err)
+	}
+
+	skAB := mcrypto.SumPrivateSpendKeys(ourSpendKey, theirSpendKey)
+	kpAB := mcrypto.NewPrivateKeyPair(skAB, sharedViewKey)
+	address := kpAB.PublicKeyPair().Address(env)
+
+	viewConf := recoveryWalletConf(conf, fmt.Sprintf("swap-wallet-recover-view-%s", address))
+	viewWalletCli, err := monero.CreateViewOnlyWalletFromKeys(viewConf, sharedViewKey, address, walletScanHeight)
+	if err != nil {
+		return fmt.Errorf("failed to create view-only wallet for %s: %w", address, err)
+	}
+
+	bal, err := viewWalletCli.GetBalance(0)
+	viewWalletCli.CloseAndRemoveWallet()
+	if err != nil {
+		return err
+	}
+	if bal.Balance == 0 {
+		return fmt.Errorf("%w: %s", errNoFundsToRecover, address)
+	}
+
+	log.Infof("found %s XMR in account %s; recovering to %s",
+		coins.FmtPiconeroAsXMR(bal.Balance), address, depositAddr)
+
+	spendConf := recoveryWalletConf(conf, fmt.Sprintf("swap-wallet-recover-%s", address))
+	abWalletCli, err := monero.CreateSpendWalletFromKeys(spendConf, kpAB, walletScanHeight)
+	if err != nil {
+		return err
+	}
+	defer abWalletCli.CloseAndRemoveWallet()
+
+	return sweepToDepositAddress(ctx, env, abWalletCli, depositAddr, monero.SweepToSelfConfirmations)
+}
+
+// recoveryWalletConf returns a copy of conf for a recovery wallet named
+// walletName, in the same directory as conf's wallet and on a fresh port.
+func recoveryWalletConf(conf *monero.WalletClientConf, walletName string) *monero.WalletClientConf {
+	c := *conf
+	c.WalletFilePath = path.Join(path.Dir(conf.WalletFilePath), walletName)
+	c.WalletPort = 0
+	return &c
+}
+
+// sweepToDepositAddress sweeps all XMR in the swap wallet to `depositAddr`,
+// waiting for `numConfirmations` confirmations of the sweep.
+func sweepToDepositAddress(
+	ctx context.Context,
+	env common.Environment,
+	abWalletCli monero.WalletClient,
+	depositAddr *mcrypto.Address,
+	numConfirmations uint64,
+) error {
+	err := depositAddr.ValidateEnv(env)
 	if err != nil {
 		log.Errorf(
 			"failed to transfer XMR out of swap wallet, dest address %s is invalid: %s",
-			address,
+			depositAddr,
 			err,
 		)
 		return err
 	}
 
-	transfers, err := abWalletCli.SweepAll(ctx, depositAddr, 0, monero.SweepToSelfConfirmations)
+	transfers, err := abWalletCli.SweepAll(ctx, depositAddr, 0, numConfirmations)
 	if err != nil {
 		return fmt.Errorf("failed to send funds to deposit account: %w", err)
 	}
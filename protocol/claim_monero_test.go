@@ -50,6 +50,7 @@ func TestClaimMonero_NoTransferBack(t *testing.T) {
 		kp,
 		nil, // deposit address can be nil, as noTransferBack is true
 		true,
+		monero.SweepToSelfConfirmations,
 	)
 	require.NoError(t, err)
 }
@@ -90,6 +91,7 @@ func TestClaimMonero_WithTransferBack(t *testing.T) {
 		kp,
 		depositAddr,
 		false,
+		monero.SweepToSelfConfirmations,
 	)
 	require.NoError(t, err)
 }
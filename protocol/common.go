@@ -21,9 +21,13 @@ type KeysAndProof struct {
 }
 
 // GenerateKeysAndProof generates keys on the secp256k1 and ed25519 curves as well as
-// a DLEq proof between the two.
-func GenerateKeysAndProof() (*KeysAndProof, error) {
-	d := &dleq.DefaultDLEq{}
+// a DLEq proof between the two, using the passed proof scheme.
+func GenerateKeysAndProof(scheme dleq.ProofScheme) (*KeysAndProof, error) {
+	d, err := dleq.New(scheme)
+	if err != nil {
+		return nil, err
+	}
+
 	proof, err := d.Prove()
 	if err != nil {
 		return nil, err
@@ -59,14 +63,19 @@ type VerifyResult struct {
 	Ed25519PublicKey   *mcrypto.PublicKey
 }
 
-// VerifyKeysAndProof verifies the given DLEq proof and asserts that the resulting secp256k1 key corresponds
-// to the given key.
+// VerifyKeysAndProof verifies the given DLEq proof with the given scheme and asserts that the resulting
+// secp256k1 key corresponds to the given key. Unknown schemes are rejected.
 func VerifyKeysAndProof(
+	scheme dleq.ProofScheme,
 	proofData []byte,
 	secp256k1Pub *secp256k1.PublicKey,
 	ed25519Pub *mcrypto.PublicKey,
 ) (*VerifyResult, error) {
-	d := &dleq.DefaultDLEq{}
+	d, err := dleq.New(scheme)
+	if err != nil {
+		return nil, err
+	}
+
 	proof := dleq.NewProofWithoutSecret(proofData)
 	res, err := d.Verify(proof)
 	if err != nil {
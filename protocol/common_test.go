@@ -4,13 +4,16 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/dleq"
 )
 
 func TestKeysAndProof(t *testing.T) {
-	kp, err := GenerateKeysAndProof()
+	kp, err := GenerateKeysAndProof(dleq.DefaultProofScheme)
 	require.NoError(t, err)
 
 	res, err := VerifyKeysAndProof(
+		dleq.DefaultProofScheme,
 		kp.DLEqProof.Proof(),
 		kp.Secp256k1PublicKey,
 		kp.PublicKeyPair.SpendKey(),
@@ -19,3 +22,20 @@ func TestKeysAndProof(t *testing.T) {
 	require.Equal(t, kp.Secp256k1PublicKey.String(), res.Secp256k1PublicKey.String())
 	require.Equal(t, kp.PublicKeyPair.SpendKey().String(), res.Ed25519PublicKey.String())
 }
+
+func TestKeysAndProof_unknownScheme(t *testing.T) {
+	_, err := GenerateKeysAndProof(dleq.ProofScheme(7))
+	require.ErrorContains(t, err, "unknown DLEq proof scheme")
+
+	kp, err := GenerateKeysAndProof(dleq.DefaultProofScheme)
+	require.NoError(t, err)
+	require.Equal(t, dleq.DefaultProofScheme, kp.DLEqProof.Scheme())
+
+	_, err = VerifyKeysAndProof(
+		dleq.ProofScheme(7),
+		kp.DLEqProof.Proof(),
+		kp.Secp256k1PublicKey,
+		kp.PublicKeyPair.SpendKey(),
+	)
+	require.ErrorContains(t, err, "unknown DLEq proof scheme")
+}
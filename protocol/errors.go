@@ -2,6 +2,9 @@ package protocol
 
 import (
 	"errors"
+	"fmt"
+
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
 )
 
 var (
@@ -10,6 +13,9 @@ var (
 
 	errLogMissingParams    = errors.New("log didn't have enough topics")
 	errInvalidEventTopic   = errors.New("log did not have correct event as first topic")
-	errInvalidSecp256k1Key = errors.New("secp256k1 public key resulting from proof verification does not match key sent")
-	errInvalidEd25519Key   = errors.New("ed25519 public key resulting from proof verification does not match key sent")
+	errInvalidSecp256k1Key = fmt.Errorf("%w: secp256k1 public key resulting from proof verification does not match key sent", //nolint:lll
+		swap.ErrCounterpartyKeyMismatch)
+	errInvalidEd25519Key = fmt.Errorf("%w: ed25519 public key resulting from proof verification does not match key sent", //nolint:lll
+		swap.ErrCounterpartyKeyMismatch)
+	errNoFundsToRecover = errors.New("reconstructed swap account holds no XMR")
 )
@@ -0,0 +1,94 @@
+package protocol
+
+import (
+	"context"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/dleq"
+)
+
+// keyPoolRetryInterval is how long the key pool waits before generating keys
+// again, after generating them failed.
+const keyPoolRetryInterval = time.Second
+
+// KeyPool holds keys and DLEq proofs generated ahead of time in the
+// background, so that starting a swap doesn't wait for proof generation. Each
+// generated KeysAndProof is handed out by Get at most once. When the pool's
+// context is cancelled, the keys left in the pool are zeroed.
+type KeyPool struct {
+	keys     chan *KeysAndProof
+	generate func() (*KeysAndProof, error)
+}
+
+// NewKeyPool returns a KeyPool holding up to `size` keys and proofs of the
+// passed scheme, which is refilled in the background until ctx is cancelled.
+func NewKeyPool(ctx context.Context, size uint, scheme dleq.ProofScheme) *KeyPool {
+	p := newKeyPool(size, func() (*KeysAndProof, error) {
+		return GenerateKeysAndProof(scheme)
+	})
+	go p.fill(ctx)
+	return p
+}
+
+func newKeyPool(size uint, generate func() (*KeysAndProof, error)) *KeyPool {
+	return &KeyPool{
+		keys:     make(chan *KeysAndProof, size),
+		generate: generate,
+	}
+}
+
+// Get returns keys and a proof from the pool, or false if the pool is empty
+// or nil, in which case the caller should generate them itself.
+func (p *KeyPool) Get() (*KeysAndProof, bool) {
+	if p == nil {
+		return nil, false
+	}
+
+	select {
+	case kp, ok := <-p.keys:
+		return kp, ok
+	default:
+		return nil, false
+	}
+}
+
+// fill generates keys into the pool whenever it has room, until ctx is
+// cancelled, and then clears the pool.
+func (p *KeyPool) fill(ctx context.Context) {
+	defer p.clear()
+
+	for {
+		kp, err := p.generate()
+		if err != nil {
+			log.Warnf("failed to pre-generate swap keys: %s", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(keyPoolRetryInterval):
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			zeroKeys(kp)
+			return
+		case p.keys <- kp:
+		}
+	}
+}
+
+// clear closes the pool and zeroes the keys that are left in it. Get returns
+// false for a cleared pool.
+func (p *KeyPool) clear() {
+	close(p.keys)
+	for kp := range p.keys {
+		zeroKeys(kp)
+	}
+}
+
+// zeroKeys overwrites the secrets of unused keys with zeros.
+func zeroKeys(kp *KeysAndProof) {
+	kp.DLEqProof.ZeroSecret()
+	kp.PrivateKeyPair.Zero()
+}
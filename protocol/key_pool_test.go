@@ -0,0 +1,66 @@
+package protocol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/dleq"
+)
+
+func TestKeyPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var generated []*KeysAndProof
+	p := newKeyPool(2, func() (*KeysAndProof, error) {
+		kp, err := GenerateKeysAndProof(dleq.DefaultProofScheme)
+		if err == nil {
+			generated = append(generated, kp)
+		}
+		return kp, err
+	})
+	filled := make(chan struct{})
+	go func() {
+		p.fill(ctx)
+		close(filled)
+	}()
+
+	// wait for the pool to fill up
+	require.Eventually(t, func() bool { return len(p.keys) == 2 }, 10*time.Second, 10*time.Millisecond)
+
+	kp, ok := p.Get()
+	require.True(t, ok)
+	require.NotEqual(t, [32]byte{}, kp.DLEqProof.Secret())
+
+	// each keypair is handed out once
+	next, ok := p.Get()
+	require.True(t, ok)
+	require.NotSame(t, kp, next)
+
+	require.Eventually(t, func() bool { return len(p.keys) == 2 }, 10*time.Second, 10*time.Millisecond)
+
+	// cancelling clears the keys left in the pool
+	cancel()
+	<-filled
+	_, ok = p.Get()
+	require.False(t, ok)
+
+	zeroed := 0
+	for _, g := range generated {
+		if g.DLEqProof.Secret() == [32]byte{} {
+			zeroed++
+		}
+	}
+	require.Equal(t, len(generated)-2, zeroed)
+	require.NotEqual(t, [32]byte{}, kp.DLEqProof.Secret())
+	require.NotEqual(t, [32]byte{}, next.DLEqProof.Secret())
+}
+
+func TestKeyPool_nil(t *testing.T) {
+	var p *KeyPool
+	_, ok := p.Get()
+	require.False(t, ok)
+}
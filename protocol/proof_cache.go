@@ -0,0 +1,96 @@
+package protocol
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/crypto/secp256k1"
+	"github.com/athanorlabs/atomic-swap/dleq"
+)
+
+// DefaultProofCacheTTL is how long a verified DLEq proof is remembered for.
+const DefaultProofCacheTTL = 10 * time.Minute
+
+// ProofCache remembers DLEq proofs that VerifyKeysAndProof accepted, so that a
+// counterparty repeatedly taking offers with the same keys and proof doesn't
+// cost a full verification each time. Only successful verifications are
+// cached, keyed by the proof together with both public keys it was checked
+// against, so a cache hit can only accept exactly what a full verification
+// accepted before.
+type ProofCache struct {
+	cache *lru.Cache[[sha256.Size]byte, time.Time]
+	ttl   time.Duration
+	now   func() time.Time // overridden in tests
+}
+
+// NewProofCache returns a ProofCache holding up to `size` verified proofs for
+// `ttl` each.
+func NewProofCache(size int, ttl time.Duration) (*ProofCache, error) {
+	cache, err := lru.New[[sha256.Size]byte, time.Time](size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProofCache{
+		cache: cache,
+		ttl:   ttl,
+		now:   time.Now,
+	}, nil
+}
+
+// VerifyKeysAndProof is VerifyKeysAndProof, skipping the verification if the
+// same proof, scheme and keys were verified within the cache's TTL. It verifies
+// every proof if the cache is nil.
+func (c *ProofCache) VerifyKeysAndProof(
+	scheme dleq.ProofScheme,
+	proofData []byte,
+	secp256k1Pub *secp256k1.PublicKey,
+	ed25519Pub *mcrypto.PublicKey,
+) (*VerifyResult, error) {
+	if c == nil {
+		return VerifyKeysAndProof(scheme, proofData, secp256k1Pub, ed25519Pub)
+	}
+
+	key := proofCacheKey(scheme, proofData, secp256k1Pub, ed25519Pub)
+	if expires, ok := c.cache.Get(key); ok {
+		if c.now().Before(expires) {
+			return &VerifyResult{
+				Secp256k1PublicKey: secp256k1Pub,
+				Ed25519PublicKey:   ed25519Pub,
+			}, nil
+		}
+		c.cache.Remove(key)
+	}
+
+	res, err := VerifyKeysAndProof(scheme, proofData, secp256k1Pub, ed25519Pub)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.Add(key, c.now().Add(c.ttl))
+	return res, nil
+}
+
+// proofCacheKey hashes the scheme, and the proof and keys with their lengths,
+// so that different inputs can't be concatenated into the same key.
+func proofCacheKey(
+	scheme dleq.ProofScheme,
+	proofData []byte,
+	secp256k1Pub *secp256k1.PublicKey,
+	ed25519Pub *mcrypto.PublicKey,
+) [sha256.Size]byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{byte(scheme)})
+	for _, b := range [][]byte{proofData, secp256k1Pub.Bytes(), ed25519Pub.Bytes()} {
+		_ = binary.Write(h, binary.BigEndian, uint64(len(b)))
+		_, _ = h.Write(b)
+	}
+
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
@@ -0,0 +1,59 @@
+package protocol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/dleq"
+)
+
+func TestProofCache(t *testing.T) {
+	kp, err := GenerateKeysAndProof(dleq.DefaultProofScheme)
+	require.NoError(t, err)
+	other, err := GenerateKeysAndProof(dleq.DefaultProofScheme)
+	require.NoError(t, err)
+
+	c, err := NewProofCache(8, time.Minute)
+	require.NoError(t, err)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	scheme := dleq.DefaultProofScheme
+	proof := kp.DLEqProof.Proof()
+	res, err := c.VerifyKeysAndProof(scheme, proof, kp.Secp256k1PublicKey, kp.PublicKeyPair.SpendKey())
+	require.NoError(t, err)
+	require.Equal(t, kp.Secp256k1PublicKey.String(), res.Secp256k1PublicKey.String())
+	require.Equal(t, 1, c.cache.Len())
+
+	// a cache hit still requires the same keys that were verified
+	_, err = c.VerifyKeysAndProof(scheme, proof, kp.Secp256k1PublicKey, other.PublicKeyPair.SpendKey())
+	require.ErrorIs(t, err, errInvalidEd25519Key)
+	_, err = c.VerifyKeysAndProof(scheme, proof, other.Secp256k1PublicKey, kp.PublicKeyPair.SpendKey())
+	require.ErrorIs(t, err, errInvalidSecp256k1Key)
+	require.Equal(t, 1, c.cache.Len())
+
+	_, err = c.VerifyKeysAndProof(scheme, proof, kp.Secp256k1PublicKey, kp.PublicKeyPair.SpendKey())
+	require.NoError(t, err)
+
+	// expired entries are verified again
+	now = now.Add(time.Minute)
+	_, err = c.VerifyKeysAndProof(scheme, proof, kp.Secp256k1PublicKey, kp.PublicKeyPair.SpendKey())
+	require.NoError(t, err)
+	require.Equal(t, 1, c.cache.Len())
+}
+
+func TestProofCache_nil(t *testing.T) {
+	kp, err := GenerateKeysAndProof(dleq.DefaultProofScheme)
+	require.NoError(t, err)
+
+	var c *ProofCache
+	_, err = c.VerifyKeysAndProof(
+		dleq.DefaultProofScheme,
+		kp.DLEqProof.Proof(),
+		kp.Secp256k1PublicKey,
+		kp.PublicKeyPair.SpendKey(),
+	)
+	require.NoError(t, err)
+}
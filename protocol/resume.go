@@ -0,0 +1,109 @@
+package protocol
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// DefaultResumeConcurrency is how many ongoing swaps are resumed at once on
+// startup, by default.
+const DefaultResumeConcurrency = 4
+
+// ResumeFunc resumes a swap found in the db on startup. If the swap carries on
+// with its startup work after ResumeFunc returns, eg. in its event handler, the
+// returned channel is closed once that work is done. A nil channel means that
+// the swap's startup work was done before ResumeFunc returned.
+type ResumeFunc func(*swap.Info) (startupDone <-chan struct{}, err error)
+
+// ResumeOngoingSwaps calls resume with each of the passed swaps, in order of
+// their t1 timeout, with at most `concurrency` swaps doing their startup work
+// at once, so that restarting a node with many ongoing swaps doesn't spike its
+// RPC usage. Swaps without a t1 timeout yet are resumed last. It returns once
+// resume has returned for all of the swaps, so that their offers are taken and
+// their swap states are registered before the node handles any network traffic;
+// the first error is returned. Zero concurrency uses DefaultResumeConcurrency.
+func ResumeOngoingSwaps(
+	ctx context.Context,
+	swaps []*swap.Info,
+	concurrency uint,
+	resume ResumeFunc,
+) error {
+	if concurrency == 0 {
+		concurrency = DefaultResumeConcurrency
+	}
+
+	return resumeSwaps(ctx, orderForResumption(swaps), concurrency, resume)
+}
+
+// orderForResumption returns the swaps sorted by their t1 timeout, so that the
+// swaps that must be refunded or claimed soonest are resumed first. Swaps
+// without a t1 timeout have no deadline to order them by, so they're put last,
+// in their original order.
+func orderForResumption(swaps []*swap.Info) []*swap.Info {
+	sorted := make([]*swap.Info, len(swaps))
+	copy(sorted, swaps)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, tj := sorted[i].Timeout1, sorted[j].Timeout1
+		if ti == nil || tj == nil {
+			return ti != nil && tj == nil
+		}
+		return ti.Before(*tj)
+	})
+
+	return sorted
+}
+
+// resumeSwaps calls resume with each swap, starting them in order with at most
+// `concurrency` swaps doing their startup work at once. A swap holds its slot
+// until its startup work is done, which may be after resume returns. All of the
+// swaps are resumed, even if some fail; the first error is returned.
+func resumeSwaps(ctx context.Context, swaps []*swap.Info, concurrency uint, resume ResumeFunc) error {
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+
+	for _, s := range swaps {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(s *swap.Info) {
+			defer wg.Done()
+
+			startupDone, err := resume(s)
+			if err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				errMu.Unlock()
+			}
+
+			if startupDone == nil {
+				<-sem
+				return
+			}
+
+			go func() {
+				select {
+				case <-startupDone:
+				case <-ctx.Done():
+				}
+				<-sem
+			}()
+		}(s)
+	}
+
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,105 @@
+package protocol
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func TestOrderForResumption(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		ts := now.Add(d)
+		return &ts
+	}
+
+	far := &swap.Info{ID: types.Hash{1}, Timeout1: at(3 * time.Hour)}
+	soon := &swap.Info{ID: types.Hash{2}, Timeout1: at(10 * time.Minute)}
+	noTimeout := &swap.Info{ID: types.Hash{3}}
+	sooner := &swap.Info{ID: types.Hash{4}, Timeout1: at(time.Minute)}
+	farther := &swap.Info{ID: types.Hash{5}, Timeout1: at(5 * time.Hour)}
+
+	sorted := orderForResumption([]*swap.Info{far, soon, noTimeout, sooner, farther})
+	require.Equal(t, []*swap.Info{sooner, soon, far, farther, noTimeout}, sorted)
+}
+
+func TestResumeOngoingSwaps(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	far := time.Now().Add(3 * time.Hour)
+	var swaps []*swap.Info
+	for i := 0; i < 10; i++ {
+		s := &swap.Info{ID: types.Hash{byte(i)}}
+		if i%2 == 0 {
+			s.Timeout1 = &far
+		}
+		swaps = append(swaps, s)
+	}
+
+	var (
+		running, maxRunning atomic.Int32
+		mu                  sync.Mutex
+		resumed             = make(map[types.Hash]bool)
+	)
+
+	err := ResumeOngoingSwaps(ctx, swaps, 2, func(s *swap.Info) (<-chan struct{}, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			prev := maxRunning.Load()
+			if n <= prev || maxRunning.CompareAndSwap(prev, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		resumed[s.ID] = true
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	// all of the swaps are resumed before it returns, so their offers can't
+	// be taken by new swaps
+	require.Len(t, resumed, len(swaps))
+	require.LessOrEqual(t, maxRunning.Load(), int32(2))
+}
+
+func TestResumeOngoingSwaps_holdsSlotUntilStartupDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := &swap.Info{ID: types.Hash{1}}
+	second := &swap.Info{ID: types.Hash{2}}
+	startupDone := make(chan struct{})
+
+	var secondResumed atomic.Bool
+	errCh := make(chan error)
+	go func() {
+		errCh <- ResumeOngoingSwaps(ctx, []*swap.Info{first, second}, 1, func(s *swap.Info) (<-chan struct{}, error) {
+			if s == first {
+				return startupDone, nil
+			}
+			secondResumed.Store(true)
+			return nil, nil
+		})
+	}()
+
+	// the first swap's resume returned, but its startup work isn't done, so it
+	// still holds the only slot
+	time.Sleep(50 * time.Millisecond)
+	require.False(t, secondResumed.Load())
+
+	close(startupDone)
+	require.NoError(t, <-errCh)
+	require.True(t, secondResumed.Load())
+}
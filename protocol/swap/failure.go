@@ -0,0 +1,73 @@
+package swap
+
+import (
+	"errors"
+)
+
+// Errors that categorise why a swap failed. Errors returned from swap state
+// transitions wrap one of them when the cause is known, so callers can check
+// for them with errors.Is.
+var (
+	ErrInsufficientXMRBalance  = errors.New("insufficient XMR balance")
+	ErrInsufficientETHBalance  = errors.New("insufficient ETH balance")
+	ErrETHLockTimeout          = errors.New("counterparty did not lock ETH")
+	ErrXMRLockTimeout          = errors.New("XMR was not locked in time")
+	ErrCounterpartyKeyMismatch = errors.New("counterparty's keys do not match its proof")
+	ErrClaimRelayFailed        = errors.New("failed to claim with a relayer")
+)
+
+// FailureReason is the category of the error that a swap failed with, which is
+// recorded in its Info.
+type FailureReason string
+
+// FailureReason values. FailureUnknown is used for errors that don't wrap one
+// of the errors above.
+const (
+	FailureInsufficientXMRBalance  FailureReason = "InsufficientXMRBalance"
+	FailureInsufficientETHBalance  FailureReason = "InsufficientETHBalance"
+	FailureETHLockTimeout          FailureReason = "ETHLockTimeout"
+	FailureXMRLockTimeout          FailureReason = "XMRLockTimeout"
+	FailureCounterpartyKeyMismatch FailureReason = "CounterpartyKeyMismatch"
+	FailureClaimRelayFailed        FailureReason = "ClaimRelayFailed"
+	FailureUnknown                 FailureReason = "Unknown"
+)
+
+var failureReasons = []struct {
+	err    error
+	reason FailureReason
+}{
+	{ErrInsufficientXMRBalance, FailureInsufficientXMRBalance},
+	{ErrInsufficientETHBalance, FailureInsufficientETHBalance},
+	{ErrETHLockTimeout, FailureETHLockTimeout},
+	{ErrXMRLockTimeout, FailureXMRLockTimeout},
+	{ErrCounterpartyKeyMismatch, FailureCounterpartyKeyMismatch},
+	{ErrClaimRelayFailed, FailureClaimRelayFailed},
+}
+
+// FailureReasonOf returns the category of the error, or the empty string if
+// the error is nil.
+func FailureReasonOf(err error) FailureReason {
+	if err == nil {
+		return ""
+	}
+
+	for _, r := range failureReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+
+	return FailureUnknown
+}
+
+// SetFailure records the error that the swap failed with, along with its
+// category. Only the first error is kept, as later errors are usually caused
+// by it.
+func (i *Info) SetFailure(err error) {
+	if err == nil || i.FailureReason != "" {
+		return
+	}
+
+	i.FailureReason = FailureReasonOf(err)
+	i.FailureError = err.Error()
+}
@@ -0,0 +1,29 @@
+package swap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureReasonOf(t *testing.T) {
+	require.Equal(t, FailureReason(""), FailureReasonOf(nil))
+	require.Equal(t, FailureUnknown, FailureReasonOf(errors.New("oops")))
+
+	err := fmt.Errorf("failed to claim: %w", fmt.Errorf("%w: no relayers found", ErrClaimRelayFailed))
+	require.Equal(t, FailureClaimRelayFailed, FailureReasonOf(err))
+}
+
+func TestInfo_SetFailure(t *testing.T) {
+	info := new(Info)
+	info.SetFailure(nil)
+	require.Empty(t, info.FailureReason)
+
+	err := fmt.Errorf("%w: XMR lock did not confirm before t0", ErrXMRLockTimeout)
+	info.SetFailure(err)
+	info.SetFailure(errors.New("later error")) // the first error is kept
+	require.Equal(t, FailureXMRLockTimeout, info.FailureReason)
+	require.Equal(t, err.Error(), info.FailureError)
+}
@@ -3,6 +3,7 @@
 package swap
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"time"
@@ -23,6 +24,12 @@ type Manager interface {
 	GetOngoingSwap(types.Hash) (Info, error)
 	GetOngoingSwaps() ([]*Info, error)
 	CompleteOngoingSwap(info *Info) error
+	AddStatusListener(l StatusListener)
+
+	// BeginStep and Shutdown let swapd wait for in-flight swap steps to reach a
+	// recoverable state before shutting down.
+	BeginStep(locksFunds bool) (done func(), err error)
+	Shutdown(ctx context.Context) error
 }
 
 // manager implements Manager.
@@ -34,6 +41,13 @@ type manager struct {
 	sync.RWMutex
 	ongoing map[types.Hash]*Info
 	past    map[types.Hash]*Info
+
+	stepsMu sync.Mutex // synchronises access to steps
+	steps   stepTracker
+
+	listenersMu sync.Mutex // synchronises access to listeners and lastStatus
+	listeners   []StatusListener
+	lastStatus  map[types.Hash]Status
 }
 
 var _ Manager = (*manager)(nil)
@@ -49,23 +63,32 @@ func NewManager(db Database) (Manager, error) {
 		return nil, err
 	}
 
+	// the statuses of resumed swaps are seeded, so that their first change is
+	// reported as a change from the status they were stored with
+	lastStatus := make(map[types.Hash]Status)
 	for _, s := range stored {
 		if !s.Status.IsOngoing() {
 			continue
 		}
 
 		ongoing[s.ID] = s
+		lastStatus[s.ID] = s.Status
 	}
 
 	return &manager{
-		db:      db,
-		ongoing: ongoing,
-		past:    make(map[types.Hash]*Info),
+		db:         db,
+		ongoing:    ongoing,
+		past:       make(map[types.Hash]*Info),
+		lastStatus: lastStatus,
 	}, nil
 }
 
 // AddSwap adds the given swap *Info to the Manager.
 func (m *manager) AddSwap(info *Info) error {
+	if info.Status.IsOngoing() && m.isShuttingDown() {
+		return errShuttingDown
+	}
+
 	m.Lock()
 	defer m.Unlock()
 
@@ -76,12 +99,22 @@ func (m *manager) AddSwap(info *Info) error {
 		m.past[info.ID] = info
 	}
 
-	return m.db.PutSwap(info)
+	if err := m.db.PutSwap(info); err != nil {
+		return err
+	}
+
+	m.notifyStatus(info)
+	return nil
 }
 
 // WriteSwapToDB writes the swap to the database.
 func (m *manager) WriteSwapToDB(info *Info) error {
-	return m.db.PutSwap(info)
+	if err := m.db.PutSwap(info); err != nil {
+		return err
+	}
+
+	m.notifyStatus(info)
+	return nil
 }
 
 // GetPastIDs returns all past swap IDs.
@@ -179,7 +212,12 @@ func (m *manager) CompleteOngoingSwap(info *Info) error {
 	delete(m.ongoing, info.ID)
 
 	// re-write to db, as status has changed
-	return m.db.PutSwap(info)
+	if err := m.db.PutSwap(info); err != nil {
+		return err
+	}
+
+	m.notifyStatus(info)
+	return nil
 }
 
 func (m *manager) getSwapFromDB(id types.Hash) (*Info, error) {
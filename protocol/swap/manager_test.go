@@ -1,7 +1,10 @@
 package swap
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/apd/v3"
 
@@ -34,6 +37,7 @@ func TestNewManager(t *testing.T) {
 		types.EthAssetETH,
 		types.ExpectingKeys,
 		100,
+		"",
 		nil,
 	)
 	db.EXPECT().PutSwap(infoA)
@@ -49,6 +53,7 @@ func TestNewManager(t *testing.T) {
 		types.EthAssetETH,
 		types.CompletedSuccess,
 		100,
+		"",
 		nil,
 	)
 	db.EXPECT().PutSwap(infoB)
@@ -82,6 +87,7 @@ func TestManager_AddSwap_Ongoing(t *testing.T) {
 		types.EthAssetETH,
 		types.ExpectingKeys,
 		100,
+		"",
 		nil,
 	)
 
@@ -161,3 +167,163 @@ func TestManager_AddSwap_Past(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 2, len(ids))
 }
+
+func TestManager_Shutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllSwaps()
+	mgr, err := NewManager(db)
+	require.NoError(t, err)
+
+	endClaim, err := mgr.BeginStep(false)
+	require.NoError(t, err)
+
+	shutdownErr := make(chan error)
+	go func() {
+		shutdownErr <- mgr.Shutdown(context.Background())
+	}()
+
+	// wait until shutdown has started, after which new locks are refused
+	require.Eventually(t, func() bool {
+		_, err := mgr.BeginStep(true) //nolint:govet
+		return errors.Is(err, errShuttingDown)
+	}, time.Second, 10*time.Millisecond)
+
+	// claims and refunds can still start and must finish before shutdown returns
+	endRefund, err := mgr.BeginStep(false)
+	require.NoError(t, err)
+
+	info := NewInfo(
+		types.Hash{0x1},
+		coins.ProvidesXMR,
+		apd.New(1, 0),
+		apd.New(10, 0),
+		coins.ToExchangeRate(apd.New(1, -1)),
+		types.EthAssetETH,
+		types.ExpectingKeys,
+		100,
+		"",
+		nil,
+	)
+	require.ErrorIs(t, mgr.AddSwap(info), errShuttingDown)
+
+	endClaim()
+	select {
+	case <-shutdownErr:
+		t.Fatal("shutdown returned with a step in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	endRefund()
+	require.NoError(t, <-shutdownErr)
+}
+
+func TestManager_Shutdown_timeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllSwaps()
+	mgr, err := NewManager(db)
+	require.NoError(t, err)
+
+	endStep, err := mgr.BeginStep(true)
+	require.NoError(t, err)
+	defer endStep()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, mgr.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestManager_StatusListener(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+	db.EXPECT().GetAllSwaps()
+	db.EXPECT().PutSwap(gomock.Any()).AnyTimes()
+
+	mgr, err := NewManager(db)
+	require.NoError(t, err)
+
+	var changes []*StatusChange
+	mgr.AddStatusListener(func(change *StatusChange) {
+		changes = append(changes, change)
+	})
+
+	info := NewInfo(
+		types.Hash{0x1},
+		coins.ProvidesXMR,
+		apd.New(1, 0),
+		apd.New(10, 0),
+		coins.ToExchangeRate(apd.New(1, -1)), // 0.1
+		types.EthAssetETH,
+		types.ExpectingKeys,
+		100,
+		"",
+		nil,
+	)
+	require.NoError(t, mgr.AddSwap(info))
+
+	// writes that don't change the status aren't reported
+	require.NoError(t, mgr.WriteSwapToDB(info))
+
+	info.SetStatus(types.KeysExchanged)
+	require.NoError(t, mgr.WriteSwapToDB(info))
+
+	info.SetStatus(types.CompletedAbort)
+	require.NoError(t, mgr.CompleteOngoingSwap(info))
+
+	require.Equal(t, 3, len(changes))
+	require.Equal(t, types.UnknownStatus, changes[0].Old)
+	require.Equal(t, types.ExpectingKeys, changes[0].New)
+	require.Equal(t, types.ExpectingKeys, changes[1].Old)
+	require.Equal(t, types.KeysExchanged, changes[1].New)
+	require.Equal(t, types.KeysExchanged, changes[2].Old)
+	require.Equal(t, types.CompletedAbort, changes[2].New)
+	require.Equal(t, info.ID, changes[2].ID)
+}
+
+func TestManager_StatusListener_resumedSwap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	info := NewInfo(
+		types.Hash{0x1},
+		coins.ProvidesXMR,
+		apd.New(1, 0),
+		apd.New(10, 0),
+		coins.ToExchangeRate(apd.New(1, -1)), // 0.1
+		types.EthAssetETH,
+		types.XMRLocked,
+		100,
+		"",
+		nil,
+	)
+
+	db := NewMockDatabase(ctrl)
+	db.EXPECT().GetAllSwaps().Return([]*Info{info}, nil)
+	db.EXPECT().PutSwap(gomock.Any()).AnyTimes()
+
+	mgr, err := NewManager(db)
+	require.NoError(t, err)
+
+	var changes []*StatusChange
+	mgr.AddStatusListener(func(change *StatusChange) {
+		changes = append(changes, change)
+	})
+
+	// the swap is resumed with the status it was stored with, which isn't a
+	// change
+	require.NoError(t, mgr.WriteSwapToDB(info))
+	require.Empty(t, changes)
+
+	info.SetStatus(types.CompletedSuccess)
+	require.NoError(t, mgr.CompleteOngoingSwap(info))
+
+	require.Equal(t, 1, len(changes))
+	require.Equal(t, types.XMRLocked, changes[0].Old)
+	require.Equal(t, types.CompletedSuccess, changes[0].New)
+}
@@ -0,0 +1,76 @@
+package swap
+
+import (
+	"context"
+	"errors"
+)
+
+var errShuttingDown = errors.New("swapd is shutting down, not starting new swaps or fund locks")
+
+// stepTracker counts the swap steps that are in flight, so shutdown can wait
+// for them to finish.
+type stepTracker struct {
+	inFlight     int
+	shuttingDown bool
+	idleCh       chan struct{} // closed when shutting down and inFlight drops to 0
+}
+
+// BeginStep marks the start of a swap step that shouldn't be interrupted once
+// it has started, such as sending a transaction and waiting for it to be
+// included. The returned function must be called when the step finishes.
+// After Shutdown is called, steps that lock funds are refused, but steps that
+// claim or refund funds are still allowed, so they can safely complete.
+func (m *manager) BeginStep(locksFunds bool) (func(), error) {
+	m.stepsMu.Lock()
+	defer m.stepsMu.Unlock()
+
+	if m.steps.shuttingDown && locksFunds {
+		return nil, errShuttingDown
+	}
+
+	m.steps.inFlight++
+	return m.endStep, nil
+}
+
+func (m *manager) endStep() {
+	m.stepsMu.Lock()
+	defer m.stepsMu.Unlock()
+
+	m.steps.inFlight--
+	if m.steps.inFlight == 0 && m.steps.idleCh != nil {
+		close(m.steps.idleCh)
+		m.steps.idleCh = nil
+	}
+}
+
+// Shutdown stops new swaps and fund locks from starting, then waits until no
+// swap steps are in flight or the passed context is done. Swaps that haven't
+// completed remain ongoing in the database, so they are resumed or aborted
+// when swapd restarts.
+func (m *manager) Shutdown(ctx context.Context) error {
+	m.stepsMu.Lock()
+	m.steps.shuttingDown = true
+	if m.steps.inFlight == 0 {
+		m.stepsMu.Unlock()
+		return nil
+	}
+
+	if m.steps.idleCh == nil {
+		m.steps.idleCh = make(chan struct{})
+	}
+	idleCh := m.steps.idleCh
+	m.stepsMu.Unlock()
+
+	select {
+	case <-idleCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *manager) isShuttingDown() bool {
+	m.stepsMu.Lock()
+	defer m.stepsMu.Unlock()
+	return m.steps.shuttingDown
+}
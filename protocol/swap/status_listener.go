@@ -0,0 +1,63 @@
+package swap
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// StatusChange describes a swap moving from one status to another. Old is
+// UnknownStatus if the swap is new to the Manager. PeerID is the swap's
+// counterparty.
+type StatusChange struct {
+	ID     types.Hash
+	PeerID peer.ID
+	Old    Status
+	New    Status
+	Time   time.Time
+}
+
+// StatusListener is called with every swap status change that is written to
+// the database. Listeners are called synchronously by the swap's goroutine, so
+// they must not block.
+type StatusListener func(change *StatusChange)
+
+// AddStatusListener registers a listener for swap status changes.
+func (m *manager) AddStatusListener(l StatusListener) {
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+	m.listeners = append(m.listeners, l)
+}
+
+// notifyStatus calls the status listeners if the swap's status differs from
+// the one it had when it was last written.
+func (m *manager) notifyStatus(info *Info) {
+	m.listenersMu.Lock()
+	old, has := m.lastStatus[info.ID]
+	if has && old == info.Status {
+		m.listenersMu.Unlock()
+		return
+	}
+
+	if info.Status.IsOngoing() {
+		m.lastStatus[info.ID] = info.Status
+	} else {
+		delete(m.lastStatus, info.ID)
+	}
+
+	listeners := m.listeners
+	m.listenersMu.Unlock()
+
+	change := &StatusChange{
+		ID:     info.ID,
+		PeerID: info.CounterpartyPeerID,
+		Old:    old,
+		New:    info.Status,
+		Time:   info.LastStatusUpdateTime,
+	}
+	for _, l := range listeners {
+		l(change)
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
@@ -35,9 +37,12 @@ type Info struct {
 	ExchangeRate   *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
 	EthAsset       types.EthAsset      `json:"ethAsset"`
 	Status         Status              `json:"status" validate:"required"`
+	// CounterpartyPeerID is the libp2p peer ID of the node we're swapping with.
+	CounterpartyPeerID peer.ID `json:"counterpartyPeerID,omitempty"`
 	// LastStatusUpdateTime is the time at which the status was last updated.
 	LastStatusUpdateTime time.Time `json:"lastStatusUpdateTime" validate:"required"`
-	// MoneroStartHeight is the Monero block number when the swap begins.
+	// MoneroStartHeight is the Monero block number when the swap begins, less
+	// the backend's reorg rollback. Recovery scans start from this height.
 	MoneroStartHeight uint64 `json:"moneroStartHeight" validate:"required"`
 	// StartTime is the time at which the swap is initiated via
 	// key exchange.
@@ -56,8 +61,20 @@ type Info struct {
 	// (and after Timeout0), the ETH-taker is able to claim, but
 	// after this timeout, the ETH-taker can no longer claim, only
 	// the ETH-maker can refund.
-	Timeout1 *time.Time        `json:"timeout1,omitempty"`
-	statusCh chan types.Status `json:"-"`
+	Timeout1 *time.Time `json:"timeout1,omitempty"`
+	// ApprovalTxHash is the hash of the transaction that approved the swap
+	// contract to transfer the ERC20 tokens we locked, if one was needed.
+	ApprovalTxHash *ethcommon.Hash `json:"approvalTxHash,omitempty"`
+	// MoneroAccountIndex is the account of our primary Monero wallet that the
+	// swap's XMR is swept to, if per-swap accounts are enabled. It's set when
+	// the account is created, so that recovery sweeps to the same account.
+	MoneroAccountIndex *uint64 `json:"moneroAccountIndex,omitempty"`
+	// FailureReason is the category of the error that the swap failed with,
+	// and FailureError is the error's message. They're empty unless the
+	// swap failed.
+	FailureReason FailureReason     `json:"failureReason,omitempty"`
+	FailureError  string            `json:"failureError,omitempty"`
+	statusCh      chan types.Status `json:"-"`
 }
 
 // NewInfo creates a new *Info from the given parameters.
@@ -70,6 +87,7 @@ func NewInfo(
 	ethAsset types.EthAsset,
 	status Status,
 	moneroStartHeight uint64,
+	counterpartyPeerID peer.ID,
 	statusCh chan types.Status,
 ) *Info {
 	info := &Info{
@@ -83,6 +101,7 @@ func NewInfo(
 		Status:               status,
 		LastStatusUpdateTime: time.Now(),
 		MoneroStartHeight:    moneroStartHeight,
+		CounterpartyPeerID:   counterpartyPeerID,
 		statusCh:             statusCh,
 		StartTime:            time.Now(),
 	}
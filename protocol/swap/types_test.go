@@ -6,6 +6,7 @@ import (
 
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/require"
 
 	"github.com/athanorlabs/atomic-swap/coins"
@@ -16,6 +17,8 @@ import (
 func Test_InfoMarshal(t *testing.T) {
 	offerIDStr := "0x0102030405060708091011121314151617181920212223242526272829303132"
 	offerID := ethcommon.HexToHash(offerIDStr)
+	peerID, err := peer.Decode("12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5")
+	require.NoError(t, err)
 	info := NewInfo(
 		offerID,
 		coins.ProvidesXMR,
@@ -25,9 +28,10 @@ func Test_InfoMarshal(t *testing.T) {
 		types.EthAssetETH,
 		types.CompletedSuccess,
 		200,
+		peerID,
 		make(chan types.Status),
 	)
-	err := info.StartTime.UnmarshalJSON([]byte("\"2023-02-20T17:29:43.471020297-05:00\""))
+	err = info.StartTime.UnmarshalJSON([]byte("\"2023-02-20T17:29:43.471020297-05:00\""))
 	require.NoError(t, err)
 	info.LastStatusUpdateTime = info.StartTime
 
@@ -44,6 +48,7 @@ func Test_InfoMarshal(t *testing.T) {
 		"ethAsset": "ETH",
 		"moneroStartHeight": 200,
 		"status": "Success",
+		"counterpartyPeerID": "12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5",
 		"lastStatusUpdateTime": "2023-02-20T17:29:43.471020297-05:00",
 		"startTime": "2023-02-20T17:29:43.471020297-05:00"
 	}`
@@ -0,0 +1,38 @@
+package protocol
+
+import (
+	"fmt"
+
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// SwapAccountAddress returns the address of the account of our primary Monero
+// wallet that is dedicated to the swap, creating the account if the swap doesn't
+// have one yet. The index of a new account is written to the db with the swap's
+// info before returning, so that recovery sweeps to the same account.
+func SwapAccountAddress(b backend.Backend, info *pswap.Info) (*mcrypto.Address, error) {
+	c := b.XMRClient()
+
+	if info.MoneroAccountIndex != nil {
+		resp, err := c.GetAddress(*info.MoneroAccountIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get address of swap account %d: %w", *info.MoneroAccountIndex, err)
+		}
+		return mcrypto.NewAddress(resp.Address, b.Env())
+	}
+
+	idx, addr, err := c.CreateAccount(fmt.Sprintf("swap-%s", info.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create swap account: %w", err)
+	}
+
+	info.MoneroAccountIndex = &idx
+	if err = b.SwapManager().WriteSwapToDB(info); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Created Monero account %d for swap %s, address=%s", idx, info.ID, addr)
+	return addr, nil
+}
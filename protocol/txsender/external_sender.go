@@ -156,10 +156,12 @@ func (s *ExternalSender) SetReady(swap *contracts.SwapFactorySwap) (ethcommon.Ha
 	return s.sendAndReceive(input, s.contractAddr)
 }
 
-// Claim prompts the external sender to sign a claim transaction
+// Claim prompts the external sender to sign a claim transaction. The gas bump
+// config is ignored, as we can't sign replacements of its transactions.
 func (s *ExternalSender) Claim(
 	swap *contracts.SwapFactorySwap,
 	secret [32]byte,
+	_ *GasBumpConfig,
 ) (ethcommon.Hash, *ethtypes.Receipt, error) {
 	input, err := s.abi.Pack("claim", swap, secret)
 	if err != nil {
@@ -0,0 +1,335 @@
+package txsender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	logging "github.com/ipfs/go-log"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/ethereum/block"
+)
+
+const (
+	// DefaultGasBumpPercent is how much the gas price of a replacement
+	// transaction is raised by default.
+	DefaultGasBumpPercent = 25
+
+	// MinGasBumpPercent is the least that the gas price of a replacement
+	// transaction can be raised by, as nodes only accept replacements that
+	// raise it by at least 10%.
+	MinGasBumpPercent = 10
+
+	// DefaultGasBumpInterval is how long a claim or refund sent by a
+	// privateKeySender can be pending by default, before it's replaced with
+	// one paying a higher gas price.
+	DefaultGasBumpInterval = time.Minute * 5
+
+	// maxGasBumps is how many times the gas price of a stuck claim or refund
+	// without both a deadline and a gas cost ceiling is raised before we only
+	// wait for one of the sent transactions to be mined.
+	maxGasBumps = 3
+
+	receiptPollInterval = time.Second * 2
+)
+
+var (
+	log = logging.Logger("txsender")
+
+	errTxNotPending          = errors.New("transaction is not pending")
+	errRebroadcastExternal   = errors.New("transactions signed by an external sender must be rebroadcast by it")
+	errPendingReceiptTimeout = errors.New("timed out waiting for pending transaction to be included")
+	errGasCostCeiling        = errors.New("replacement transaction would go over the gas cost ceiling")
+	errRebroadcastNotOurs    = errors.New("transaction was not sent by us")
+)
+
+// GasBumpConfig configures how the gas price of a stuck transaction is raised
+// until it's included. Zero values use the defaults.
+type GasBumpConfig struct {
+	// Percent is how much the gas price is raised by each time the
+	// transaction is replaced. It must be at least MinGasBumpPercent.
+	Percent uint64
+
+	// Interval is how long the latest transaction can be pending before it's
+	// replaced.
+	Interval time.Duration
+
+	// MaxGasCost is the most, in wei, that a replacement can spend on gas, ie.
+	// its gas limit times its gas price. Gas prices are raised up to the
+	// ceiling, after which the transaction isn't replaced anymore. Nil means
+	// there's no ceiling, in which case the gas price is raised at most
+	// maxGasBumps times.
+	MaxGasCost *big.Int
+
+	// Deadline is when the transaction stops being useful. It isn't replaced
+	// after the deadline. If MaxGasCost is also set, the transaction is
+	// replaced until the deadline, rather than up to maxGasBumps times.
+	Deadline time.Time
+}
+
+func (c *GasBumpConfig) percent() uint64 {
+	if c == nil || c.Percent == 0 {
+		return DefaultGasBumpPercent
+	}
+	return c.Percent
+}
+
+func (c *GasBumpConfig) interval() time.Duration {
+	if c == nil || c.Interval == 0 {
+		return DefaultGasBumpInterval
+	}
+	return c.Interval
+}
+
+// bumpAllowed returns whether a pending transaction that was already replaced
+// `bumps` times can be replaced again. Without a gas cost ceiling, the number
+// of replacements is limited, so that the gas price can't grow unbounded
+// before the deadline.
+func (c *GasBumpConfig) bumpAllowed(bumps int) bool {
+	if c == nil {
+		return bumps < maxGasBumps
+	}
+	if !c.Deadline.IsZero() && !time.Now().Before(c.Deadline) {
+		return false
+	}
+	if c.Deadline.IsZero() || c.MaxGasCost == nil {
+		return bumps < maxGasBumps
+	}
+	return true
+}
+
+// capGasPrice returns the bumped gas price, lowered if needed so that a
+// transaction with the passed gas limit can't spend more than MaxGasCost.
+// errGasCostCeiling is returned if the lowered price doesn't raise the previous
+// price enough for the replacement to be accepted.
+func (c *GasBumpConfig) capGasPrice(prev *big.Int, bumped *big.Int, gas uint64) (*big.Int, error) {
+	if c == nil || c.MaxGasCost == nil || gas == 0 {
+		return bumped, nil
+	}
+
+	ceiling := new(big.Int).Div(c.MaxGasCost, new(big.Int).SetUint64(gas))
+	if bumped.Cmp(ceiling) <= 0 {
+		return bumped, nil
+	}
+
+	if ceiling.Cmp(bumpGasPrice(prev, nil, MinGasBumpPercent)) < 0 {
+		return nil, errGasCostCeiling
+	}
+
+	return ceiling, nil
+}
+
+// RebroadcastTx sends the pending transaction with the passed hash to our
+// ethereum node again, for when it was dropped from the mempool. If bumpGas is
+// set, a replacement of the transaction, with the same nonce and a higher gas
+// price, is sent instead. Only transactions that we sent can be rebroadcast.
+// The hash of the sent transaction is returned.
+func (s *privateKeySender) RebroadcastTx(txHash ethcommon.Hash, bumpGas bool) (ethcommon.Hash, error) {
+	return s.rebroadcastTx(txHash, bumpGas, nil)
+}
+
+// rebroadcastTx is RebroadcastTx, with the gas price of the replacement raised
+// as configured by conf.
+func (s *privateKeySender) rebroadcastTx(
+	txHash ethcommon.Hash,
+	bumpGas bool,
+	conf *GasBumpConfig,
+) (ethcommon.Hash, error) {
+	tx, isPending, err := s.ethClient.Raw().TransactionByHash(s.ctx, txHash)
+	if err != nil {
+		return ethcommon.Hash{}, fmt.Errorf("failed to get transaction %s: %w", txHash, err)
+	}
+
+	if !isPending {
+		return ethcommon.Hash{}, fmt.Errorf("%w: %s was already included", errTxNotPending, txHash)
+	}
+
+	if err = s.checkOwnTx(tx); err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	if bumpGas {
+		tx, err = s.replacementTx(tx, conf)
+		if err != nil {
+			return ethcommon.Hash{}, err
+		}
+	}
+
+	err = s.ethClient.Raw().SendTransaction(s.ctx, tx)
+	if err != nil && !(isAlreadyKnown(err) && !bumpGas) {
+		return ethcommon.Hash{}, fmt.Errorf("failed to send transaction %s: %w", tx.Hash(), err)
+	}
+
+	log.Infof("rebroadcast transaction %s as %s (nonce=%d gas price=%s)",
+		txHash, tx.Hash(), tx.Nonce(), tx.GasFeeCap())
+	return tx.Hash(), nil
+}
+
+// checkOwnTx returns an error if the transaction wasn't sent by us. Replacing a
+// transaction signs its calldata, value and recipient with our key, so only
+// our own transactions are rebroadcast.
+func (s *privateKeySender) checkOwnTx(tx *ethtypes.Transaction) error {
+	from, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(s.ethClient.ChainID()), tx)
+	if err != nil {
+		return fmt.Errorf("failed to get sender of transaction %s: %w", tx.Hash(), err)
+	}
+
+	if from != s.ethClient.Address() {
+		return fmt.Errorf("%w: %s was sent by %s", errRebroadcastNotOurs, tx.Hash(), from)
+	}
+
+	return nil
+}
+
+// isAlreadyKnown returns whether the error is the node rejecting a transaction
+// because it's already in its mempool.
+func isAlreadyKnown(err error) bool {
+	return strings.Contains(err.Error(), "already known")
+}
+
+// replacementTx returns a copy of the passed transaction, signed by us, with a
+// gas price that is raised by the configured percentage, or to the suggested
+// gas price if that's higher, up to the configured gas cost ceiling.
+func (s *privateKeySender) replacementTx(
+	tx *ethtypes.Transaction,
+	conf *GasBumpConfig,
+) (*ethtypes.Transaction, error) {
+	suggested, err := s.ethClient.SuggestGasPrice(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the fee cap of legacy transactions is their gas price
+	percent := conf.percent()
+	feeCap, err := conf.capGasPrice(tx.GasFeeCap(), bumpGasPrice(tx.GasFeeCap(), suggested, percent), tx.Gas())
+	if err != nil {
+		return nil, err
+	}
+
+	var inner ethtypes.TxData
+	switch tx.Type() {
+	case ethtypes.DynamicFeeTxType:
+		tipCap := bumpGasPrice(tx.GasTipCap(), nil, percent)
+		if tipCap.Cmp(feeCap) > 0 {
+			tipCap = feeCap
+		}
+
+		inner = &ethtypes.DynamicFeeTx{
+			ChainID:    s.ethClient.ChainID(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tipCap,
+			GasFeeCap:  feeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	default:
+		inner = &ethtypes.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: feeCap,
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}
+	}
+
+	// sign with the transaction options' signer, like any other transaction
+	// that we send
+	txOpts, err := s.ethClient.TxOpts(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return txOpts.Signer(txOpts.From, ethtypes.NewTx(inner))
+}
+
+// bumpGasPrice returns the gas price raised by `percent`, or `atLeast` if that's
+// higher and not nil.
+func bumpGasPrice(price *big.Int, atLeast *big.Int, percent uint64) *big.Int {
+	bumped := new(big.Int).Mul(price, new(big.Int).SetUint64(100+percent))
+	bumped.Div(bumped, big.NewInt(100))
+	if atLeast != nil && atLeast.Cmp(bumped) > 0 {
+		return new(big.Int).Set(atLeast)
+	}
+	return bumped
+}
+
+// waitForReceipt waits for the passed transaction, or a replacement of it, to
+// be included. Each time the latest transaction is pending for longer than the
+// configured interval, it's replaced with one paying a higher gas price, until
+// the configured deadline if there's also a gas cost ceiling, or otherwise up to
+// maxGasBumps times. The
+// transaction isn't replaced anymore once its gas cost reaches the configured
+// ceiling. The hash and receipt of the included transaction are returned.
+func (s *privateKeySender) waitForReceipt(
+	tx *ethtypes.Transaction,
+	conf *GasBumpConfig,
+) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	sent := []ethcommon.Hash{tx.Hash()}
+	lastSent := time.Now()
+	interval := conf.interval()
+	capped := false
+
+	// the total wait is similar to that of block.WaitForReceipt
+	ctx, cancel := context.WithTimeout(s.ctx, time.Hour)
+	defer cancel()
+
+	for {
+		for _, txHash := range sent {
+			receipt, err := s.ethClient.Raw().TransactionReceipt(ctx, txHash)
+			if err != nil {
+				continue
+			}
+
+			if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+				return ethcommon.Hash{}, nil, fmt.Errorf("transaction failed (gas-lost=%d tx=%s block=%d), %w",
+					receipt.GasUsed, txHash, receipt.BlockNumber, block.ErrorFromBlock(ctx, s.ethClient.Raw(), receipt))
+			}
+
+			log.Infof("transaction %s included in chain, block number=%d, gas used=%d",
+				txHash, receipt.BlockNumber, receipt.GasUsed)
+			return txHash, receipt, nil
+		}
+
+		if !capped && conf.bumpAllowed(len(sent)-1) && time.Since(lastSent) > interval {
+			latest := sent[len(sent)-1]
+			log.Warnf("transaction %s is pending after %s, replacing it with a higher gas price", latest, interval)
+			replacement, err := s.rebroadcastTx(latest, true, conf)
+			switch {
+			case errors.Is(err, errGasCostCeiling):
+				log.Warnf("not replacing transaction %s, as it would spend more than %s ETH on gas",
+					latest, coins.FmtWeiAsETH(conf.MaxGasCost))
+				capped = true
+			case err != nil:
+				// the transaction may have just been included
+				log.Warnf("failed to replace transaction %s: %s", latest, err)
+			default:
+				sent = append(sent, replacement)
+			}
+			lastSent = time.Now()
+		}
+
+		if err := common.SleepWithContext(ctx, receiptPollInterval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && s.ctx.Err() == nil {
+				return ethcommon.Hash{}, nil, fmt.Errorf("%w: %s", errPendingReceiptTimeout, sent[len(sent)-1])
+			}
+			return ethcommon.Hash{}, nil, err
+		}
+	}
+}
+
+// RebroadcastTx is not supported by the external sender, as we can't sign a
+// replacement of its transactions.
+func (s *ExternalSender) RebroadcastTx(_ ethcommon.Hash, _ bool) (ethcommon.Hash, error) {
+	return ethcommon.Hash{}, errRebroadcastExternal
+}
@@ -0,0 +1,91 @@
+package txsender
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+)
+
+func TestBumpGasPrice(t *testing.T) {
+	require.Equal(t, big.NewInt(125), bumpGasPrice(big.NewInt(100), nil, DefaultGasBumpPercent))
+	require.Equal(t, big.NewInt(125), bumpGasPrice(big.NewInt(100), big.NewInt(110), DefaultGasBumpPercent))
+	require.Equal(t, big.NewInt(200), bumpGasPrice(big.NewInt(100), big.NewInt(200), DefaultGasBumpPercent))
+	require.Equal(t, big.NewInt(150), bumpGasPrice(big.NewInt(100), nil, 50))
+}
+
+func TestGasBumpConfig_capGasPrice(t *testing.T) {
+	// no ceiling
+	var conf *GasBumpConfig
+	price, err := conf.capGasPrice(big.NewInt(100), big.NewInt(125), 1000)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(125), price)
+
+	// the bumped price is under the ceiling of 130 per gas
+	conf = &GasBumpConfig{MaxGasCost: big.NewInt(130_000)}
+	price, err = conf.capGasPrice(big.NewInt(100), big.NewInt(125), 1000)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(125), price)
+
+	// the bumped price is lowered to the ceiling
+	price, err = conf.capGasPrice(big.NewInt(100), big.NewInt(150), 1000)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(130), price)
+
+	// the ceiling doesn't leave room for a replacement to be accepted
+	_, err = conf.capGasPrice(big.NewInt(120), big.NewInt(150), 1000)
+	require.ErrorIs(t, err, errGasCostCeiling)
+}
+
+func TestGasBumpConfig_bumpAllowed(t *testing.T) {
+	var conf *GasBumpConfig
+	require.True(t, conf.bumpAllowed(maxGasBumps-1))
+	require.False(t, conf.bumpAllowed(maxGasBumps))
+
+	// without a gas cost ceiling, the bump limit applies even with a deadline
+	conf = &GasBumpConfig{Deadline: time.Now().Add(time.Hour)}
+	require.True(t, conf.bumpAllowed(maxGasBumps-1))
+	require.False(t, conf.bumpAllowed(maxGasBumps))
+
+	// with a deadline and a ceiling, transactions are replaced until the deadline
+	conf.MaxGasCost = big.NewInt(1e15)
+	require.True(t, conf.bumpAllowed(maxGasBumps*10))
+
+	conf.Deadline = time.Now().Add(-time.Second)
+	require.False(t, conf.bumpAllowed(0))
+}
+
+func TestPrivateKeySender_checkOwnTx(t *testing.T) {
+	chainID := big.NewInt(1337)
+	ourKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+
+	ec := extethclient.NewMockEthClient(gomock.NewController(t))
+	ec.EXPECT().ChainID().Return(chainID).AnyTimes()
+	ec.EXPECT().Address().Return(ethcrypto.PubkeyToAddress(ourKey.PublicKey)).AnyTimes()
+
+	s := &privateKeySender{ethClient: ec}
+	tx := ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+		ChainID:   chainID,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       21000,
+	})
+
+	ours, err := extethclient.NewPrivateKeySigner(ourKey, chainID).SignTx(tx)
+	require.NoError(t, err)
+	require.NoError(t, s.checkOwnTx(ours))
+
+	// transactions sent by others aren't rebroadcast, so we don't sign them
+	theirs, err := extethclient.NewPrivateKeySigner(otherKey, chainID).SignTx(tx)
+	require.NoError(t, err)
+	require.ErrorIs(t, s.checkOwnTx(theirs), errRebroadcastNotOurs)
+}
@@ -1,8 +1,8 @@
 // Package txsender provides a common Sender interface for swapd instances. Each Sender
 // implementation is responsible for signing and submitting transactions to the network.
-// privateKeySender is the implementation using an ethereum private key directly managed
-// by swapd. ExternalSender provides an API for interacting with an external entity like
-// Metamask.
+// privateKeySender is the implementation that signs with the ethereum client's Signer,
+// which by default wraps an ethereum private key directly managed by swapd. ExternalSender
+// provides an API for interacting with an external entity like Metamask.
 package txsender
 
 import (
@@ -34,8 +34,13 @@ type Sender interface {
 		amount *big.Int,
 	) (ethcommon.Hash, *ethtypes.Receipt, error)
 	SetReady(swap *contracts.SwapFactorySwap) (ethcommon.Hash, *ethtypes.Receipt, error)
-	Claim(swap *contracts.SwapFactorySwap, secret [32]byte) (ethcommon.Hash, *ethtypes.Receipt, error)
+	Claim(
+		swap *contracts.SwapFactorySwap,
+		secret [32]byte,
+		gasBump *GasBumpConfig,
+	) (ethcommon.Hash, *ethtypes.Receipt, error)
 	Refund(swap *contracts.SwapFactorySwap, secret [32]byte) (ethcommon.Hash, *ethtypes.Receipt, error)
+	RebroadcastTx(txHash ethcommon.Hash, bumpGas bool) (ethcommon.Hash, error)
 }
 
 type privateKeySender struct {
@@ -155,6 +160,7 @@ func (s *privateKeySender) SetReady(swap *contracts.SwapFactorySwap) (ethcommon.
 func (s *privateKeySender) Claim(
 	swap *contracts.SwapFactorySwap,
 	secret [32]byte,
+	gasBump *GasBumpConfig,
 ) (ethcommon.Hash, *ethtypes.Receipt, error) {
 	s.ethClient.Lock()
 	defer s.ethClient.Unlock()
@@ -169,13 +175,15 @@ func (s *privateKeySender) Claim(
 		return ethcommon.Hash{}, nil, err
 	}
 
-	receipt, err := block.WaitForReceipt(s.ctx, s.ethClient.Raw(), tx.Hash())
+	// claims and refunds have deadlines, so they're replaced with a higher gas
+	// price if they get stuck
+	txHash, receipt, err := s.waitForReceipt(tx, gasBump)
 	if err != nil {
 		err = fmt.Errorf("claim failed, %w", err)
 		return ethcommon.Hash{}, nil, err
 	}
 
-	return tx.Hash(), receipt, nil
+	return txHash, receipt, nil
 }
 
 func (s *privateKeySender) Refund(
@@ -195,11 +203,13 @@ func (s *privateKeySender) Refund(
 		return ethcommon.Hash{}, nil, err
 	}
 
-	receipt, err := block.WaitForReceipt(s.ctx, s.ethClient.Raw(), tx.Hash())
+	// claims and refunds have deadlines, so they're replaced with a higher gas
+	// price if they get stuck
+	txHash, receipt, err := s.waitForReceipt(tx, nil)
 	if err != nil {
 		err = fmt.Errorf("refund failed, %w", err)
 		return ethcommon.Hash{}, nil, err
 	}
 
-	return tx.Hash(), receipt, nil
+	return txHash, receipt, nil
 }
@@ -1,38 +1,182 @@
 package xmrmaker
 
 import (
+	"fmt"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 )
 
-// MakeOffer makes a new swap offer.
+// MakeOffer makes a new swap offer, returning the offer being advertised. This
+// is the passed offer, unless duplicate offers are collapsed and we already have
+// an offer with the same terms, in which case that offer is returned instead.
 func (b *Instance) MakeOffer(
 	o *types.Offer,
 	useRelayer bool,
-) (*types.OfferExtra, error) {
+) (*types.Offer, *types.OfferExtra, error) {
+	existing, existingExtra := b.offerManager.FindEquivalentOffer(o, useRelayer)
+	if existing != nil {
+		if b.collapseDuplicateOffers {
+			log.Infof("offer has the same terms as existing offer %s, not adding a duplicate", existing.ID)
+			return existing, existingExtra, nil
+		}
+
+		log.Warnf("new offer %s has the same terms as existing offer %s", o.ID, existing.ID)
+	}
+
+	if err := b.checkNewOffer(o, useRelayer); err != nil {
+		return nil, nil, err
+	}
+
+	extra, err := b.offerManager.AddOffer(o, useRelayer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b.net.Advertise()
+	log.Infof("created new offer: %v", o)
+	return o, extra, nil
+}
+
+// MakePrivateOffer makes a new swap offer that isn't advertised, and that is
+// only served to, and can only be taken by, the passed peers. Private offers
+// aren't collapsed into existing offers with the same terms.
+func (b *Instance) MakePrivateOffer(
+	o *types.Offer,
+	useRelayer bool,
+	allowedPeers []peer.ID,
+) (*types.Offer, *types.OfferExtra, error) {
+	if err := b.checkNewOffer(o, useRelayer); err != nil {
+		return nil, nil, err
+	}
+
+	extra, err := b.offerManager.AddPrivateOffer(o, useRelayer, allowedPeers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Infof("created new private offer for %d peers: %v", len(allowedPeers), o)
+	return o, extra, nil
+}
+
+// ImportOffers adds the offers of an offer book, which is a JSON array of
+// offer definitions, all at once. See offers.Manager.ImportOffers.
+func (b *Instance) ImportOffers(data []byte) ([]types.Hash, error) {
+	ids, err := b.offerManager.ImportOffers(data)
+	if err != nil {
+		return nil, err
+	}
+
+	b.net.Advertise()
+	return ids, nil
+}
+
+// checkNewOffer checks that we can make the offer, and that our balance covers
+// it, if it provides XMR.
+func (b *Instance) checkNewOffer(o *types.Offer, useRelayer bool) error {
+	if err := o.CheckTerms(b.backend.DustThresholds(), b.backend.ExchangeRateBounds()); err != nil {
+		return err
+	}
+
+	if o.Provides == coins.ProvidesETH {
+		// the ETH-providing side of the swap is run by the ETH offer handler,
+		// which checks our balance when the offer is taken
+		if b.ethOffers == nil {
+			return errETHOffersNotSupported
+		}
+		if useRelayer {
+			return errRelayingETHOffer
+		}
+	} else if err := b.checkOfferBalance(o); err != nil {
+		return err
+	}
+
+	if useRelayer && o.EthAsset != types.EthAssetETH {
+		return errRelayingWithNonEthAsset
+	}
+
+	if o.SwapFactory != nil {
+		_, err := contracts.CheckSwapFactoryContractCode(b.backend.Ctx(), b.backend.ETHClient().Raw(), *o.SwapFactory)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %s", errInvalidSwapFactory, o.SwapFactory, err)
+		}
+	}
+
+	return nil
+}
+
+// checkOfferBalance checks that our unlocked monero balance covers the offer's
+// maximum amount, along with the network fee of locking it. The fee is estimated
+// with the configured lock priority, as offers only override it once made.
+func (b *Instance) checkOfferBalance(o *types.Offer) error {
 	// get monero balance
 	balance, err := b.backend.XMRClient().GetBalance(0)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	unlockedBalance := coins.NewPiconeroAmount(balance.UnlockedBalance).AsMonero()
 	if unlockedBalance.Cmp(o.MaxAmount) <= 0 {
-		return nil, errUnlockedBalanceTooLow{o.MaxAmount, unlockedBalance}
+		return errUnlockedBalanceTooLow{o.MaxAmount, unlockedBalance}
 	}
 
-	if useRelayer && o.EthAsset != types.EthAssetETH {
-		return nil, errRelayingWithNonEthAsset
+	if err = b.checkOfferBalanceFraction(o, unlockedBalance); err != nil {
+		return err
 	}
 
-	extra, err := b.offerManager.AddOffer(o, useRelayer)
+	// reserve headroom for the network fee of locking the maximum amount
+	fee, err := b.backend.EstimateXMRTransferFee(coins.MoneroToPiconero(o.MaxAmount), b.moneroLockPriority)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	b.net.Advertise()
-	log.Infof("created new offer: %v", o)
-	return extra, nil
+	required := new(apd.Decimal)
+	_, err = coins.DecimalCtx().Add(required, o.MaxAmount, fee.AsMonero())
+	if err != nil {
+		return err
+	}
+
+	if unlockedBalance.Cmp(required) < 0 {
+		return errUnlockedBalanceTooLowForFee{o.MaxAmount, fee.AsMonero(), unlockedBalance}
+	}
+
+	return nil
+}
+
+// MakeFiatOffer makes a new swap offer whose min and max amounts are pegged to
+// a fiat currency. The advertised offer has concrete XMR amounts and exchange
+// rate computed from the fiat pricing oracle, and is repriced as the oracle's
+// prices change.
+func (b *Instance) MakeFiatOffer(
+	ref *types.FiatReference,
+	ethAsset types.EthAsset,
+	useRelayer bool,
+) (*types.Offer, *types.OfferExtra, error) {
+	o, err := b.offerManager.NewFiatOffer(b.backend.Ctx(), ref, ethAsset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o, extra, err := b.MakeOffer(o, useRelayer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = b.offerManager.SetFiatReference(o.ID, ref); err != nil {
+		return nil, nil, err
+	}
+
+	return o, extra, nil
+}
+
+// SetOfferMoneroLockPriority sets the priority of the XMR lock transfer for
+// swaps on the offer with the passed ID, overriding the configured priority.
+func (b *Instance) SetOfferMoneroLockPriority(id types.Hash, priority types.MoneroTxPriority) error {
+	return b.offerManager.SetMoneroLockPriority(id, priority)
 }
 
 // GetOffers returns all current offers.
@@ -40,10 +184,51 @@ func (b *Instance) GetOffers() []*types.Offer {
 	return b.offerManager.GetOffers()
 }
 
-// ClearOffers clears all offers.
+// GetOffersForPeer returns the current offers that are served to the passed
+// peer, which are the public offers and the private offers it's allowed to
+// see.
+func (b *Instance) GetOffersForPeer(who peer.ID) []*types.Offer {
+	return b.offerManager.GetOffersForPeer(who)
+}
+
+// ClearOffers clears the passed offers, or all offers if none are passed.
+// Offers with in-flight swaps stop being advertised, but their swaps continue
+// to completion and the offers are not re-added if the swaps fail.
 func (b *Instance) ClearOffers(offerIDs []types.Hash) error {
+	var err error
 	if len(offerIDs) == 0 {
-		return b.offerManager.ClearAllOffers()
+		err = b.offerManager.ClearAllOffers()
+	} else {
+		err = b.offerManager.ClearOfferIDs(offerIDs)
 	}
-	return b.offerManager.ClearOfferIDs(offerIDs)
+	if err != nil {
+		return err
+	}
+
+	return b.persistCancelledOffers()
+}
+
+// persistCancelledOffers stores the cancelled flag of the offers of in-flight
+// swaps, so that a swap recovered after a restart doesn't re-add its offer.
+func (b *Instance) persistCancelledOffers() error {
+	b.swapMu.Lock()
+	defer b.swapMu.Unlock()
+
+	for id, s := range b.swapStates {
+		if !b.offerManager.IsCancelled(id) {
+			continue
+		}
+
+		extra := &types.OfferExtra{
+			UseRelayer:         s.offerExtra.UseRelayer,
+			FiatReference:      s.offerExtra.FiatReference,
+			MoneroLockPriority: s.offerExtra.MoneroLockPriority,
+			Cancelled:          true,
+		}
+		if err := b.backend.RecoveryDB().PutSwapRelayerInfo(id, extra); err != nil {
+			return fmt.Errorf("failed to store cancellation of offer %s: %w", id, err)
+		}
+	}
+
+	return nil
 }
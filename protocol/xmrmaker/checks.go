@@ -175,20 +175,28 @@ func (s *swapState) checkContract(txHash ethcommon.Hash) error {
 
 // checkAndSetTimeouts checks that the timeouts set by the counterparty when initiating the swap
 // are not too short or too long.
-// we expect the timeout to be of a certain length (1 hour for mainnet/stagenet), and allow a 3 minute
-// variation between now and the expected time until the first timeout t0, to allow for block confirmations.
+// we expect the timeout to be the offer's swap timeout if it has one, or otherwise of a certain
+// length (1 hour for mainnet/stagenet), and allow a 5% variation (at least a minute) between now
+// and the expected time until the first timeout t0, to allow for block confirmations.
 // the time between t0 and t1 should always be the exact length we expect.
 func (s *swapState) checkAndSetTimeouts(t0, t1 *big.Int) error {
 	s.setTimeouts(t0, t1)
 
-	// we ignore the timeout for development, as unit tests and integration tests
-	// often set different timeouts.
-	if s.Backend.Env() == common.Development {
-		return nil
+	expectedTimeout := s.offer.SwapTimeoutDuration()
+	if expectedTimeout == 0 {
+		// we ignore the default timeout for development, as unit tests and
+		// integration tests often set different timeouts.
+		if s.Backend.Env() == common.Development {
+			return nil
+		}
+		expectedTimeout = common.SwapTimeoutFromEnv(s.Backend.Env())
 	}
 
-	expectedTimeout := common.SwapTimeoutFromEnv(s.Backend.Env())
+	// short offer timeouts still get a minute for the ETH lock to be mined
 	allowableTimeDiff := expectedTimeout / 20
+	if allowableTimeDiff < time.Minute {
+		allowableTimeDiff = time.Minute
+	}
 
 	if s.t1.Sub(s.t0) != expectedTimeout {
 		return errInvalidT1
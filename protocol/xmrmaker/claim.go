@@ -12,20 +12,35 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/net/message"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+	"github.com/athanorlabs/atomic-swap/protocol/txsender"
 	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
+// relayClaimBackoff is how long to wait before resubmitting our claim to the
+// relayers that failed transiently. It doubles with every retry.
+var relayClaimBackoff = 2 * time.Second
+
 // claimFunds redeems XMRMaker's ETH funds by calling Claim() on the contract
 func (s *swapState) claimFunds() (ethcommon.Hash, error) {
+	endStep, err := s.SwapManager().BeginStep(false)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+	defer endStep()
+
 	var (
 		symbol   string
 		decimals uint8
-		err      error
 	)
 	if types.EthAsset(s.contractSwap.Asset) != types.EthAssetETH {
 		_, symbol, decimals, err = s.ETHClient().ERC20Info(s.ctx, s.contractSwap.Asset)
@@ -59,13 +74,19 @@ func (s *swapState) claimFunds() (ethcommon.Hash, error) {
 		// relayer fee was set or we had insufficient funds to claim without a relayer
 		// TODO: Sufficient funds check above should be more specific
 		txHash, err = s.discoverRelayersAndClaim()
-		if err != nil {
+		if errors.Is(err, errClaimDeadlineReached) && weiBalance.Sign() > 0 {
+			var claimed bool
+			txHash, claimed, err = s.findExistingClaim()
+			if err == nil && !claimed {
+				log.Errorf("!!! relayers did not claim swap %s before the claim deadline %s, claiming directly",
+					s.ID(), s.claimDeadline().Format(common.TimeFmtSecs))
+				txHash, err = s.claimDirectly()
+			}
+		} else if err != nil {
 			log.Warnf("failed to claim using relayers: %s", err)
 		}
 	} else {
-		// claim and wait for tx to be included
-		sc := s.getSecret()
-		txHash, _, err = s.sender.Claim(s.contractSwap, sc)
+		txHash, err = s.claimDirectly()
 	}
 	if err != nil {
 		return ethcommon.Hash{}, err
@@ -94,19 +115,76 @@ func (s *swapState) claimFunds() (ethcommon.Hash, error) {
 	return txHash, nil
 }
 
-// discoverRelayersAndClaim discovers available relayers on the network,
+// claimDirectly sends our claim transaction ourselves and waits for it to be
+// included. While it's stuck, its gas price is raised until shortly before t1.
+func (s *swapState) claimDirectly() (ethcommon.Hash, error) {
+	gasBump := new(txsender.GasBumpConfig)
+	if s.claimGasBump != nil {
+		*gasBump = *s.claimGasBump
+	}
+
+	// a claim that's still pending in the last block before t1 won't be
+	// included in time, so there's no point in replacing it after that
+	gasBump.Deadline = s.t1.Add(-expectedETHBlockTime(s.Backend.Env()))
+
+	sc := s.getSecret()
+	txHash, receipt, err := s.sender.Claim(s.contractSwap, sc, gasBump)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	if err = checkClaimReceipt(receipt, s.contractAddr, s.contractSwapID, sc); err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	return txHash, nil
+}
+
+// claimDeadline returns the time after which we stop waiting on relayers to
+// claim for us, leaving enough time before t1 to get a claim included.
+func (s *swapState) claimDeadline() time.Time {
+	return s.t1.Add(-s.claimDeadlineMargin)
+}
+
+// expectedETHBlockTime returns the expected time between blocks of the
+// ethereum network used by env.
+func expectedETHBlockTime(env common.Environment) time.Duration {
+	if env == common.Development {
+		return time.Second
+	}
+	return 12 * time.Second
+}
+
+// discoverRelayersAndClaim discovers available relayers on the network, and
+// submits our claim to them until one succeeds. Relayers that fail transiently
+// are retried, with exponential backoff, up to relayClaimRetries more times.
+// Relayers that fail permanently, eg. by rejecting our request, are not retried.
+// Once the claim deadline is reached, no more relayers are tried and
+// errClaimDeadlineReached is returned. If the swap is refunded while we're
+// claiming, errRefundedDuringClaim is returned, and the refund is handled once
+// the event handler is free.
 func (s *swapState) discoverRelayersAndClaim() (ethcommon.Hash, error) {
+	deadline := s.claimDeadline()
+	if time.Now().After(deadline) {
+		return ethcommon.Hash{}, errClaimDeadlineReached
+	}
+
+	ctx, cancel := s.newClaimContext(deadline)
+	defer cancel()
+
 	relayers, err := s.Backend.DiscoverRelayers()
 	if err != nil {
 		return ethcommon.Hash{}, err
 	}
 
+	relayers = s.orderRelayers(relayers)
+
 	if len(relayers) == 0 {
-		return ethcommon.Hash{}, errors.New("no relayers found to submit claim to")
+		return ethcommon.Hash{}, fmt.Errorf("%w: no relayers found to submit claim to", pswap.ErrClaimRelayFailed)
 	}
 	log.Debugf("Found %d relayers to submit claim to", len(relayers))
 
-	forwarderAddress, err := s.Contract().TrustedForwarder(&bind.CallOpts{Context: s.ctx})
+	forwarderAddress, err := s.contract.TrustedForwarder(&bind.CallOpts{Context: s.ctx})
 	if err != nil {
 		return ethcommon.Hash{}, err
 	}
@@ -115,51 +193,349 @@ func (s *swapState) discoverRelayersAndClaim() (ethcommon.Hash, error) {
 
 	req, err := relayer.CreateRelayClaimRequest(
 		s.ctx,
-		s.ETHClient().PrivateKey(),
+		s.ETHClient().Signer(),
 		s.ETHClient().Raw(),
 		s.contractAddr,
 		forwarderAddress,
 		s.contractSwap,
 		&secret,
+		relayer.FeeWei,
+		s.claimGas,
 	)
 	if err != nil {
 		return ethcommon.Hash{}, err
 	}
 
-	for _, relayer := range relayers {
-		log.Debugf("submitting claim to relayer with peer ID %s", relayer)
-		resp, err := s.Backend.SubmitClaimToRelayer(relayer, req)
+	createRequest := s.relayClaimRequestFunc(req, forwarderAddress, &secret)
+
+	// set once a claim was submitted, after which the swap may already have
+	// been claimed by a relayer that we stopped waiting for
+	var submitted bool
+
+	backoff := relayClaimBackoff
+	for attempt := uint(0); ; attempt++ {
+		var retryable []peer.ID
+
+		for _, relayerID := range relayers {
+			if err = s.checkClaimContext(ctx); err != nil {
+				return ethcommon.Hash{}, err
+			}
+
+			if submitted {
+				txHash, claimed, err := s.findExistingClaim() //nolint:govet
+				if err != nil || claimed {
+					return txHash, err
+				}
+			}
+
+			txHash, err := s.claimWithRelayer(ctx, relayerID, createRequest) //nolint:govet
+			if err == nil {
+				return txHash, nil
+			}
+			submitted = true
+
+			if ctxErr := s.checkClaimContext(ctx); ctxErr != nil {
+				return ethcommon.Hash{}, ctxErr
+			}
+
+			// try other relayers first on our next claims
+			s.Backend.MarkRelayerFailed(relayerID)
+
+			if isPermanentRelayFailure(err) {
+				log.Warnf("relayer %s failed to claim, not retrying it: %s", relayerID, err)
+				continue
+			}
+
+			log.Warnf("relayer %s failed to claim: %s", relayerID, err)
+			retryable = append(retryable, relayerID)
+		}
+
+		if len(retryable) == 0 || attempt >= s.relayClaimRetries {
+			break
+		}
+
+		log.Infof("retrying claim with %d relayers in %s", len(retryable), backoff)
+		if err = common.SleepWithContext(ctx, backoff); err != nil {
+			return ethcommon.Hash{}, s.checkClaimContext(ctx)
+		}
+
+		relayers = retryable
+		backoff *= 2
+	}
+
+	if submitted {
+		txHash, claimed, err := s.findExistingClaim()
+		if err != nil || claimed {
+			return txHash, err
+		}
+	}
+
+	return ethcommon.Hash{}, fmt.Errorf("%w: failed to submit transaction to any relayer", pswap.ErrClaimRelayFailed)
+}
+
+// findExistingClaim checks on-chain whether our swap was already claimed, eg.
+// by a relayer whose transaction we stopped waiting for, so that we don't
+// submit a second claim that would revert. If it was, the hash of the claim
+// transaction is returned. If the swap was completed without our claim, ie.
+// it was refunded, errSwapCompletedWithoutClaim is returned. Failing to read
+// the swap's stage is only logged, as submitting another claim is still safe.
+func (s *swapState) findExistingClaim() (ethcommon.Hash, bool, error) {
+	stage, err := s.contract.Swaps(s.ETHClient().CallOpts(s.ctx), s.contractSwapID)
+	if err != nil {
+		log.Warnf("failed to check if swap %s was already claimed: %s", s.ID(), err)
+		return ethcommon.Hash{}, false, nil
+	}
+
+	if stage != contracts.StageCompleted {
+		return ethcommon.Hash{}, false, nil
+	}
+
+	logs, err := s.ETHClient().Raw().FilterLogs(s.ctx, ethereum.FilterQuery{
+		FromBlock: s.ethStartNumber,
+		Addresses: []ethcommon.Address{s.contractAddr},
+		Topics:    [][]ethcommon.Hash{{claimedTopic}, {s.contractSwapID}},
+	})
+	if err != nil {
+		return ethcommon.Hash{}, false, fmt.Errorf("failed to filter claim logs of completed swap: %w", err)
+	}
+
+	for i := range logs {
+		if checkClaimedLog(&logs[i], s.contractAddr, s.contractSwapID, s.getSecret()) == nil {
+			log.Infof("swap %s was already claimed by a relayer, tx=%s", s.ID(), logs[i].TxHash)
+			return logs[i].TxHash, true, nil
+		}
+	}
+
+	return ethcommon.Hash{}, false, errSwapCompletedWithoutClaim
+}
+
+// newClaimContext returns a context for claiming with relayers, which is done
+// when the swap's context is, at the claim deadline, or when the swap is
+// refunded.
+func (s *swapState) newClaimContext(deadline time.Time) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithDeadline(s.ctx, deadline)
+	go func() {
+		select {
+		case <-s.refundedCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// checkClaimContext returns an error if the claim must stop: errRefundedDuringClaim
+// if the counterparty's refund was seen on-chain, the swap's context error if
+// the swap was cancelled, or errClaimDeadlineReached if the claim deadline of
+// claimCtx has passed. It returns nil if the claim can continue.
+func (s *swapState) checkClaimContext(claimCtx context.Context) error {
+	select {
+	case <-s.refundedCh:
+		return errRefundedDuringClaim
+	default:
+	}
+	return checkClaimContext(s.ctx, claimCtx)
+}
+
+// checkClaimContext returns the swap's context error if it was cancelled, or
+// errClaimDeadlineReached if only the claim deadline of claimCtx has passed.
+func checkClaimContext(swapCtx, claimCtx context.Context) error {
+	if err := swapCtx.Err(); err != nil {
+		return err
+	}
+	if claimCtx.Err() != nil {
+		return errClaimDeadlineReached
+	}
+	return nil
+}
+
+// relayClaimRequestFunc returns the RelayClaimRequestFunc of our claim. Claims
+// to relayers on the current relay protocol are signed for the fee that the
+// relayer advertised, and req, which was signed for relayer.FeeWei, is used for
+// relayers that advertise that fee. Relayers on the legacy relay protocol relay
+// claims with a fixed gas limit, so their request is signed with that limit
+// instead. Requests are created once the first relayer that needs them is tried.
+func (s *swapState) relayClaimRequestFunc(
+	req *message.RelayClaimRequest,
+	forwarderAddress ethcommon.Address,
+	secret *[32]byte,
+) message.RelayClaimRequestFunc {
+	requests := map[string]*message.RelayClaimRequest{ // fee -> request
+		req.Fee.String(): req,
+	}
+	var legacyReq *message.RelayClaimRequest
+
+	return func(terms *message.RelayerTerms) (*message.RelayClaimRequest, error) {
+		var err error
+		if terms.Legacy {
+			if legacyReq == nil {
+				legacyReq, err = relayer.CreateLegacyRelayClaimRequest(
+					s.ctx,
+					s.ETHClient().Signer(),
+					s.ETHClient().Raw(),
+					s.contractAddr,
+					forwarderAddress,
+					s.contractSwap,
+					secret,
+					relayer.FeeWei,
+				)
+			}
+			return legacyReq, err
+		}
+
+		fee, err := s.checkRelayerFee(terms.Fees)
 		if err != nil {
-			log.Warnf("failed to submit tx to relayer: %s", err)
-			continue
+			return nil, errPermanentRelayFailure{err}
 		}
 
-		err = waitForClaimReceipt(
+		if feeReq, has := requests[fee.String()]; has {
+			return feeReq, nil
+		}
+
+		feeReq, err := relayer.CreateRelayClaimRequest(
 			s.ctx,
+			s.ETHClient().Signer(),
 			s.ETHClient().Raw(),
-			resp.TxHash,
 			s.contractAddr,
-			s.contractSwapID,
-			s.getSecret(),
+			forwarderAddress,
+			s.contractSwap,
+			secret,
+			fee,
+			s.claimGas,
 		)
 		if err != nil {
-			log.Warnf("failed to get receipt of relayer's tx: %s", err)
-			continue
+			return nil, err
 		}
 
-		return resp.TxHash, nil
+		requests[fee.String()] = feeReq
+		return feeReq, nil
+	}
+}
+
+// checkRelayerFee returns the fee that a relayer advertised for claims of our
+// swap's asset, if we accept it. ETH fees can't be more than relayer.FeeWei,
+// which our offers' exchange rates account for, and no fee can take the whole
+// swap value.
+func (s *swapState) checkRelayerFee(fees map[types.EthAsset]*big.Int) (*big.Int, error) {
+	asset := types.EthAsset(s.contractSwap.Asset)
+
+	fee, has := fees[asset]
+	if !has || fee == nil || fee.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %s", errRelayerFeeMissing, asset)
+	}
+
+	if asset == types.EthAssetETH && fee.Cmp(relayer.FeeWei) > 0 {
+		return nil, fmt.Errorf("%w: %s ETH is more than %s ETH",
+			errRelayerFeeTooHigh, coins.FmtWeiAsETH(fee), coins.FmtWeiAsETH(relayer.FeeWei))
+	}
+
+	if fee.Cmp(s.contractSwap.Value) >= 0 {
+		return nil, fmt.Errorf("%w: %s is not less than the swap value of %s",
+			errRelayerFeeTooHigh, fee, s.contractSwap.Value)
 	}
 
-	return ethcommon.Hash{}, errors.New("failed to submit transaction to any relayer")
+	return fee, nil
 }
 
+// claimWithRelayer submits our claim to a single relayer and waits for the
+// relayed transaction to be included and validated, recording the result in the
+// relayer's stats. The relayer isn't blamed if we stopped waiting on it because
+// ctx was done.
+func (s *swapState) claimWithRelayer(
+	ctx context.Context,
+	relayerID peer.ID,
+	createRequest message.RelayClaimRequestFunc,
+) (ethcommon.Hash, error) {
+	txHash, err := s.submitClaimToRelayer(ctx, relayerID, createRequest)
+	if err == nil || ctx.Err() == nil {
+		s.recordRelayerResult(relayerID, err)
+	}
+	return txHash, err
+}
+
+func (s *swapState) submitClaimToRelayer(
+	ctx context.Context,
+	relayerID peer.ID,
+	createRequest message.RelayClaimRequestFunc,
+) (ethcommon.Hash, error) {
+	log.Debugf("submitting claim to relayer with peer ID %s", relayerID)
+
+	// the relayer's terms decide which request is sent, so we keep it to know
+	// the fee that the claim was signed to pay
+	var req *message.RelayClaimRequest
+	keepRequest := func(terms *message.RelayerTerms) (*message.RelayClaimRequest, error) {
+		var err error
+		req, err = createRequest(terms)
+		return req, err
+	}
+
+	resp, err := s.Backend.SubmitClaimToRelayer(relayerID, keepRequest)
+	if err != nil {
+		return ethcommon.Hash{}, fmt.Errorf("failed to submit tx to relayer: %w", err)
+	}
+
+	receipt, err := waitForClaimReceipt(
+		ctx,
+		s.ETHClient().Raw(),
+		resp.TxHash,
+		s.contractAddr,
+		s.contractSwapID,
+		s.getSecret(),
+		s.claimReceiptRetries,
+	)
+	if err != nil {
+		return ethcommon.Hash{}, fmt.Errorf("failed to get receipt of relayer's tx: %w", err)
+	}
+
+	// requests to legacy relayers don't carry the fee, as they're always
+	// signed for relayer.FeeWei
+	signedFee := relayer.FeeWei
+	if req != nil && req.Fee != nil {
+		signedFee = req.Fee
+	}
+
+	if err = checkRelayClaimDetails(resp, receipt, signedFee); err != nil {
+		log.Warnf("relayer %s reported inaccurate claim details: %s", relayerID, err)
+	}
+
+	return resp.TxHash, nil
+}
+
+// errPermanentRelayFailure wraps relayed claim failures that retrying with the
+// same relayer won't fix.
+type errPermanentRelayFailure struct {
+	err error
+}
+
+func (e errPermanentRelayFailure) Error() string {
+	return e.err.Error()
+}
+
+func (e errPermanentRelayFailure) Unwrap() error {
+	return e.err
+}
+
+// isPermanentRelayFailure returns whether the relayer rejected our claim
+// request as invalid or reverting, or its claim transaction was included but
+// didn't claim our swap. Anything else, like timeouts, busy relayers and the
+// relayer's RPC or transaction pool errors, is transient.
+func isPermanentRelayFailure(err error) bool {
+	return errors.Is(err, net.ErrRelayerRejectedClaim) || errors.As(err, new(errPermanentRelayFailure))
+}
+
+// waitForClaimReceipt waits for the relayed claim transaction to be included in
+// a block and validates its receipt. Transient errors fetching the receipt of an
+// included transaction are retried up to receiptRetries times, so that a flaky
+// RPC read doesn't cause us to abandon a relayer's successful claim. The
+// validated receipt is returned.
 func waitForClaimReceipt(
 	ctx context.Context,
 	ec *ethclient.Client,
 	txHash ethcommon.Hash,
 	contractAddr ethcommon.Address,
 	contractSwapID, secret [32]byte,
-) error {
+	receiptRetries uint,
+) (*ethtypes.Receipt, error) {
 	const (
 		checkInterval = time.Second // time between transaction polls
 		maxWait       = time.Minute // max wait for the tx to be included in a block
@@ -175,7 +551,7 @@ func waitForClaimReceipt(
 		// into the node we're using
 		err := common.SleepWithContext(ctx, checkInterval)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		_, isPending, err := ec.TransactionByHash(ctx, txHash)
@@ -186,12 +562,12 @@ func waitForClaimReceipt(
 				continue
 			}
 
-			return err
+			return nil, err
 		}
 
 		if time.Since(start) > maxWait {
 			// the tx is taking too long, return an error so we try with another relayer
-			return errRelayedTransactionTimeout
+			return nil, errRelayedTransactionTimeout
 		}
 
 		if !isPending {
@@ -199,32 +575,128 @@ func waitForClaimReceipt(
 		}
 	}
 
-	receipt, err := ec.TransactionReceipt(ctx, txHash)
+	receipt, err := fetchClaimReceipt(ctx, ec, txHash, receiptRetries, checkInterval)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
 		err = fmt.Errorf("relayer's claim transaction failed (gas-lost=%d tx=%s block=%d), %w",
 			receipt.GasUsed, txHash, receipt.BlockNumber, block.ErrorFromBlock(ctx, ec, receipt))
-		return err
+		return nil, errPermanentRelayFailure{err}
 	}
 
 	if len(receipt.Logs) == 0 {
-		return fmt.Errorf("relayer's claim transaction had no logs (tx=%s block=%d)",
-			txHash, receipt.BlockNumber)
+		return nil, errPermanentRelayFailure{fmt.Errorf("relayer's claim transaction had no logs (tx=%s block=%d)",
+			txHash, receipt.BlockNumber)}
 	}
 
 	if err = checkClaimedLog(receipt.Logs[0], contractAddr, contractSwapID, secret); err != nil {
-		return fmt.Errorf("relayer's claim had logs error (tx=%s block=%d): %w",
-			txHash, receipt.BlockNumber, err)
+		return nil, errPermanentRelayFailure{fmt.Errorf("relayer's claim had logs error (tx=%s block=%d): %w",
+			txHash, receipt.BlockNumber, err)}
 	}
 
 	log.Infof("relayer's claim tx=%s in block=%d validated, gas used: %d",
 		receipt.TxHash, receipt.BlockNumber, receipt.GasUsed)
+	return receipt, nil
+}
+
+// checkRelayClaimDetails checks the optional claim details reported by the
+// relayer against the validated receipt of its claim transaction, and the fee
+// that our claim request was signed to pay, and logs the true cost of the
+// claim. Responses without details, or with details of a version we don't
+// know, are accepted as is.
+func checkRelayClaimDetails(resp *message.RelayClaimResponse, receipt *ethtypes.Receipt, signedFee *big.Int) error {
+	if resp.DetailsVersion != message.RelayClaimDetailsVersion {
+		return nil
+	}
+
+	if resp.FeeWei == nil {
+		return errors.New("reported claim details are missing the fee")
+	}
+
+	if resp.FeeWei.BigInt().Cmp(signedFee) != 0 {
+		return fmt.Errorf("reported fee %s ETH is not the agreed %s ETH",
+			resp.FeeWei.AsEtherString(), coins.FmtWeiAsETH(signedFee))
+	}
+
+	if resp.GasUsed != receipt.GasUsed {
+		return fmt.Errorf("reported gas used %d does not match the receipt's %d", resp.GasUsed, receipt.GasUsed)
+	}
+
+	if resp.BlockNumber != receipt.BlockNumber.Uint64() {
+		return fmt.Errorf("reported block %d does not match the receipt's block %d",
+			resp.BlockNumber, receipt.BlockNumber)
+	}
+
+	log.Infof("relayed claim tx=%s cost us a fee of %s ETH, relayer paid for %d gas",
+		receipt.TxHash, resp.FeeWei.AsEtherString(), resp.GasUsed)
 	return nil
 }
 
+// receiptFetcher is the subset of *ethclient.Client used by fetchClaimReceipt.
+type receiptFetcher interface {
+	TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error)
+}
+
+// fetchClaimReceipt fetches the receipt of an included transaction. A NotFound
+// error means the receipt is genuinely missing (eg. the transaction was
+// reorged out), so errClaimReceiptNotFound is returned immediately. Any other
+// error is treated as transient and retried up to `retries` times.
+func fetchClaimReceipt(
+	ctx context.Context,
+	ec receiptFetcher,
+	txHash ethcommon.Hash,
+	retries uint,
+	retryInterval time.Duration,
+) (*ethtypes.Receipt, error) {
+	for attempt := uint(0); ; attempt++ {
+		receipt, err := ec.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			return receipt, nil
+		}
+
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("%w (tx=%s)", errClaimReceiptNotFound, txHash)
+		}
+
+		if ctx.Err() != nil || attempt >= retries {
+			return nil, fmt.Errorf("failed to fetch receipt of tx=%s after %d attempts: %w",
+				txHash, attempt+1, err)
+		}
+
+		log.Debugf("failed to fetch receipt of tx=%s, retrying: %s", txHash, err)
+		if err = common.SleepWithContext(ctx, retryInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// checkClaimReceipt validates the Claimed log of a claim transaction that we sent
+// ourselves. Unlike relayed claims, self-claims can be for ERC20 tokens, in which
+// case the token's logs are also present in the receipt, so we search for the
+// Claimed log instead of assuming it is the first one.
+func checkClaimReceipt(
+	receipt *ethtypes.Receipt,
+	contractAddr ethcommon.Address,
+	contractSwapID, secret [32]byte,
+) error {
+	for _, log := range receipt.Logs {
+		if log.Address != contractAddr || len(log.Topics) == 0 || log.Topics[0] != claimedTopic {
+			continue
+		}
+
+		if err := checkClaimedLog(log, contractAddr, contractSwapID, secret); err != nil {
+			return fmt.Errorf("claim had logs error (tx=%s block=%d): %w",
+				receipt.TxHash, receipt.BlockNumber, err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w (tx=%s block=%d)", errClaimedLogNotFound, receipt.TxHash, receipt.BlockNumber)
+}
+
 func checkClaimedLog(log *ethtypes.Log, contractAddr ethcommon.Address, contractSwapID, secret [32]byte) error {
 	if log.Address != contractAddr {
 		return errClaimedLogInvalidContractAddr
@@ -3,20 +3,30 @@ package xmrmaker
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
+	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 
 	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
 
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/dleq"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/net/message"
 	"github.com/athanorlabs/atomic-swap/relayer"
 	"github.com/athanorlabs/atomic-swap/tests"
 )
@@ -162,20 +172,24 @@ func testSwapStateClaimRelayer(t *testing.T, sk *ecdsa.PrivateKey, asset types.E
 	// now let's try to claim
 	req, err := relayer.CreateRelayClaimRequest(
 		ctx,
-		sk,
+		ec.Signer(),
 		ec.Raw(),
 		contractAddr,
 		forwarderAddress,
 		swap,
 		&secret,
+		relayer.FeeWei,
+		nil,
 	)
 	require.NoError(t, err)
+	require.NotZero(t, req.Gas)
 
-	resp, err := relayer.ValidateAndSendTransaction(ctx, req, ec, contractAddr)
+	resp, err := relayer.ValidateAndSendTransaction(ctx, req, ec, contractAddr, nil, true, nil, nil, nil)
 	require.NoError(t, err)
 
 	receipt, err = block.WaitForReceipt(ctx, ec.Raw(), resp.TxHash)
 	require.NoError(t, err)
+	require.NoError(t, checkRelayClaimDetails(resp, receipt, relayer.FeeWei))
 	t.Logf("gas cost to call Claim via relayer: %d", receipt.GasUsed)
 
 	if asset != types.EthAssetETH {
@@ -189,3 +203,216 @@ func testSwapStateClaimRelayer(t *testing.T, sk *ecdsa.PrivateKey, asset types.E
 	require.NoError(t, err)
 	require.Equal(t, contracts.StageCompleted, stage)
 }
+
+func TestCheckClaimReceipt(t *testing.T) {
+	contractAddr := ethcommon.Address{0x1}
+	swapID := [32]byte{0x2}
+	secret := [32]byte{0x3}
+
+	claimedLog := &ethtypes.Log{
+		Address: contractAddr,
+		Topics:  []ethcommon.Hash{claimedTopic, swapID, secret},
+	}
+
+	// an ERC20 claim has the token's Transfer log before the Claimed log
+	transferLog := &ethtypes.Log{
+		Address: ethcommon.Address{0x4},
+		Topics:  []ethcommon.Hash{{0x5}, {0x6}, {0x7}},
+	}
+
+	receipt := &ethtypes.Receipt{
+		Logs:        []*ethtypes.Log{transferLog, claimedLog},
+		BlockNumber: big.NewInt(1),
+	}
+	err := checkClaimReceipt(receipt, contractAddr, swapID, secret)
+	require.NoError(t, err)
+
+	err = checkClaimReceipt(receipt, contractAddr, swapID, [32]byte{0x8})
+	require.ErrorIs(t, err, errClaimedLogWrongSecret)
+
+	err = checkClaimReceipt(receipt, contractAddr, [32]byte{0x8}, secret)
+	require.ErrorIs(t, err, errClaimedLogWrongSwapID)
+
+	// Claimed log from a different contract is ignored
+	err = checkClaimReceipt(receipt, ethcommon.Address{0x9}, swapID, secret)
+	require.ErrorIs(t, err, errClaimedLogNotFound)
+
+	receipt.Logs = []*ethtypes.Log{transferLog}
+	err = checkClaimReceipt(receipt, contractAddr, swapID, secret)
+	require.ErrorIs(t, err, errClaimedLogNotFound)
+}
+
+type flakyReceiptFetcher struct {
+	errs  []error // errors to return before returning the receipt
+	calls int
+}
+
+func (f *flakyReceiptFetcher) TransactionReceipt(_ context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	return &ethtypes.Receipt{TxHash: txHash}, nil
+}
+
+func TestFetchClaimReceipt(t *testing.T) {
+	ctx := context.Background()
+	txHash := ethcommon.Hash{0x1}
+	errTransient := errors.New("connection reset")
+
+	// transient errors are retried
+	ec := &flakyReceiptFetcher{errs: []error{errTransient, errTransient}}
+	receipt, err := fetchClaimReceipt(ctx, ec, txHash, 2, 0)
+	require.NoError(t, err)
+	require.Equal(t, txHash, receipt.TxHash)
+	require.Equal(t, 3, ec.calls)
+
+	// retries are bounded
+	ec = &flakyReceiptFetcher{errs: []error{errTransient, errTransient, errTransient}}
+	_, err = fetchClaimReceipt(ctx, ec, txHash, 2, 0)
+	require.ErrorIs(t, err, errTransient)
+	require.Equal(t, 3, ec.calls)
+
+	// a missing receipt is not retried
+	ec = &flakyReceiptFetcher{errs: []error{ethereum.NotFound}}
+	_, err = fetchClaimReceipt(ctx, ec, txHash, 2, 0)
+	require.ErrorIs(t, err, errClaimReceiptNotFound)
+	require.Equal(t, 1, ec.calls)
+}
+
+func TestCheckRelayClaimDetails(t *testing.T) {
+	receipt := &ethtypes.Receipt{
+		TxHash:      ethcommon.Hash{0x1},
+		GasUsed:     80_000,
+		BlockNumber: big.NewInt(100),
+	}
+
+	// responses without details are accepted
+	resp := &message.RelayClaimResponse{TxHash: receipt.TxHash}
+	require.NoError(t, checkRelayClaimDetails(resp, receipt, relayer.FeeWei))
+
+	resp.DetailsVersion = message.RelayClaimDetailsVersion
+	resp.FeeWei = coins.NewWeiAmount(relayer.FeeWei)
+	resp.GasUsed = receipt.GasUsed
+	resp.BlockNumber = receipt.BlockNumber.Uint64()
+	require.NoError(t, checkRelayClaimDetails(resp, receipt, relayer.FeeWei))
+
+	resp.BlockNumber++
+	require.ErrorContains(t, checkRelayClaimDetails(resp, receipt, relayer.FeeWei), "reported block")
+
+	resp.BlockNumber--
+	resp.GasUsed++
+	require.ErrorContains(t, checkRelayClaimDetails(resp, receipt, relayer.FeeWei), "reported gas used")
+
+	resp.GasUsed--
+	resp.FeeWei = coins.NewWeiAmount(new(big.Int).Add(relayer.FeeWei, big.NewInt(1)))
+	require.ErrorContains(t, checkRelayClaimDetails(resp, receipt, relayer.FeeWei), "not the agreed")
+
+	// the fee is checked against the one that the claim was signed to pay,
+	// which relayers with their own fees advertise
+	signedFee := new(big.Int).Div(relayer.FeeWei, big.NewInt(2))
+	require.ErrorContains(t, checkRelayClaimDetails(resp, receipt, signedFee), "not the agreed")
+	resp.FeeWei = coins.NewWeiAmount(signedFee)
+	require.NoError(t, checkRelayClaimDetails(resp, receipt, signedFee))
+
+	resp.FeeWei = nil
+	require.Error(t, checkRelayClaimDetails(resp, receipt, relayer.FeeWei))
+
+	// details of an unknown version are ignored
+	resp.DetailsVersion = message.RelayClaimDetailsVersion + 1
+	require.NoError(t, checkRelayClaimDetails(resp, receipt, relayer.FeeWei))
+}
+
+func TestIsPermanentRelayFailure(t *testing.T) {
+	rejected := fmt.Errorf("failed to submit tx to relayer: %w",
+		fmt.Errorf("%w: invalid signature", net.ErrRelayerRejectedClaim))
+	require.True(t, isPermanentRelayFailure(rejected))
+
+	reverted := fmt.Errorf("failed to get receipt of relayer's tx: %w",
+		errPermanentRelayFailure{errors.New("relayer's claim transaction failed")})
+	require.True(t, isPermanentRelayFailure(reverted))
+
+	require.False(t, isPermanentRelayFailure(errRelayedTransactionTimeout))
+	require.False(t, isPermanentRelayFailure(errors.New("relayer is unavailable: relayer is busy, try again later")))
+}
+
+func TestSwapState_checkRelayerFee(t *testing.T) {
+	s := &swapState{
+		contractSwap: &contracts.SwapFactorySwap{
+			Asset: ethcommon.Address(types.EthAssetETH),
+			Value: big.NewInt(1e18),
+		},
+	}
+
+	// relayers can charge less than the default fee
+	lowFee := big.NewInt(1e15)
+	fee, err := s.checkRelayerFee(map[types.EthAsset]*big.Int{types.EthAssetETH: lowFee})
+	require.NoError(t, err)
+	require.Equal(t, lowFee, fee)
+
+	highFee := new(big.Int).Add(relayer.FeeWei, big.NewInt(1))
+	_, err = s.checkRelayerFee(map[types.EthAsset]*big.Int{types.EthAssetETH: highFee})
+	require.ErrorIs(t, err, errRelayerFeeTooHigh)
+
+	_, err = s.checkRelayerFee(map[types.EthAsset]*big.Int{})
+	require.ErrorIs(t, err, errRelayerFeeMissing)
+
+	// the fee must be less than the swap value
+	s.contractSwap.Value = relayer.FeeWei
+	_, err = s.checkRelayerFee(map[types.EthAsset]*big.Int{types.EthAssetETH: relayer.FeeWei})
+	require.ErrorIs(t, err, errRelayerFeeTooHigh)
+}
+
+func TestSwapState_discoverRelayersAndClaim_pastDeadline(t *testing.T) {
+	s := &swapState{
+		ctx: context.Background(),
+		t1:  time.Now().Add(time.Minute),
+	}
+	s.claimDeadlineMargin = 2 * time.Minute
+
+	// the deadline is checked before relayers are discovered with the nil backend
+	_, err := s.discoverRelayersAndClaim()
+	require.ErrorIs(t, err, errClaimDeadlineReached)
+}
+
+func TestCheckClaimContext(t *testing.T) {
+	swapCtx, cancelSwap := context.WithCancel(context.Background())
+	claimCtx, cancelClaim := context.WithCancel(swapCtx)
+	require.NoError(t, checkClaimContext(swapCtx, claimCtx))
+
+	cancelClaim()
+	require.ErrorIs(t, checkClaimContext(swapCtx, claimCtx), errClaimDeadlineReached)
+
+	cancelSwap()
+	require.ErrorIs(t, checkClaimContext(swapCtx, claimCtx), context.Canceled)
+}
+
+func TestSwapState_checkClaimContext_refunded(t *testing.T) {
+	s := &swapState{
+		ctx:        context.Background(),
+		refundedCh: make(chan struct{}),
+	}
+
+	ctx, cancel := s.newClaimContext(time.Now().Add(time.Hour))
+	defer cancel()
+	require.NoError(t, s.checkClaimContext(ctx))
+
+	// a refund seen by the watcher while we wait between relayer retries
+	// interrupts the wait, instead of only being handled after the claim
+	sleepErr := make(chan error)
+	go func() {
+		sleepErr <- common.SleepWithContext(ctx, time.Hour)
+	}()
+	close(s.refundedCh)
+
+	select {
+	case err := <-sleepErr:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("claim context was not cancelled by the refund")
+	}
+
+	require.ErrorIs(t, s.checkClaimContext(ctx), errRefundedDuringClaim)
+}
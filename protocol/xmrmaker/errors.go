@@ -5,11 +5,16 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
 )
 
 var (
 	// various instance and swap errors
 	errUnexpectedMessageType         = errors.New("unexpected message type")
+	errPerSwapWalletAndAccount       = errors.New("per-swap wallets and per-swap accounts can't both be enabled")
+	errInvalidOfferBalanceFraction   = errors.New("max offer balance fraction must be between 0 and 1")
 	errMissingKeys                   = errors.New("did not receive XMRTaker's public spend or view key")
 	errMissingAddress                = errors.New("got empty contract address")
 	errNilSwapState                  = errors.New("swap state is nil")
@@ -27,26 +32,59 @@ var (
 	errClaimedLogWrongEvent          = errors.New("log did not have the Claimed event as its first topic")
 	errClaimedLogWrongSwapID         = errors.New("log did not have the correct swap ID as its second topic")
 	errClaimedLogWrongSecret         = errors.New("log did not have the correct secret as its third topic")
+	errClaimedLogNotFound            = errors.New("claim transaction did not emit a Claimed log")
+	errClaimReceiptNotFound          = errors.New("receipt of included claim transaction not found")
+	errClaimDeadlineReached          = errors.New("relayers did not claim before the claim deadline")
+	errSwapCompletedWithoutClaim     = errors.New("swap was completed on-chain without our claim")
+	errRefundedDuringClaim           = errors.New("swap was refunded while claiming with relayers")
 	errRelayingWithNonEthAsset       = errors.New("relayers with ERC20 token swaps are not currently supported")
+	errRelayerFeeMissing             = errors.New("relayer did not advertise a fee for the swap's asset")
+	errRelayerFeeTooHigh             = errors.New("relayer's advertised fee is too high")
+	errKeyGenerationFailed           = errors.New("failed to generate swap keys")
+	errETHOffersNotSupported         = errors.New("offers that provide ETH are not supported by this node")
+	errNotETHOffer                   = errors.New("offer does not provide ETH")
+	errRelayingETHOffer              = errors.New("relayers are not supported with offers that provide ETH")
+	errInvalidSwapFactory            = errors.New("offer's swap factory is not a valid SwapFactory contract")
+	errWrongSwapFactory              = errors.New("ETH was locked in a different swap factory than the offer's")
 
 	// protocol initiation errors
 	errProtocolAlreadyInProgress = errors.New("protocol already in progress")
 	errOfferIDNotSet             = errors.New("offer ID was not set")
-	errInvalidStageForRecovery   = errors.New("cannot create ongoing swap state if stage is not XMRLocked")
+	errOfferNotVisible           = errors.New("offer with given ID does not exist")
+	errInvalidStageForRecovery   = errors.New("cannot create ongoing swap state if stage is not KeysExchanged or XMRLocked")
+	errContractNotPending        = errors.New("swap is no longer pending in the contract")
+	errResumeTooCloseToT0        = errors.New("cannot resume swap, too close to t0 to safely lock XMR")
+	errXMRLockNotReceived        = errors.New("cannot resume swap, XMR lock was started but never received")
+	errXMRLockTooLate            = errors.New("too close to t0 to lock XMR, consider a larger swap timeout or smaller lock margin") //nolint:lll
+	errXMRLockTimeout            = fmt.Errorf("%w: XMR lock did not confirm before t0", pswap.ErrXMRLockTimeout)
+	errXMRLockInsufficientFunds  = fmt.Errorf("%w: unlocked balance no longer covers the XMR lock", pswap.ErrInsufficientXMRBalance) //nolint:lll
+	errConnectivityCheckFailed   = errors.New("rejecting take, swap dependency is unavailable")
 )
 
 type errBalanceTooLow struct {
 	unlockedBalance *apd.Decimal
+	reservedAmount  *apd.Decimal // may be nil
 	providedAmount  *apd.Decimal
 }
 
 func (e errBalanceTooLow) Error() string {
+	if e.reservedAmount != nil && !e.reservedAmount.IsZero() {
+		return fmt.Sprintf("balance of %s XMR, with %s XMR reserved by other swaps, is below provided %s XMR",
+			e.unlockedBalance.String(),
+			e.reservedAmount.String(),
+			e.providedAmount.String(),
+		)
+	}
 	return fmt.Sprintf("balance of %s XMR is below provided %s XMR",
 		e.unlockedBalance.String(),
 		e.providedAmount.String(),
 	)
 }
 
+func (e errBalanceTooLow) Unwrap() error {
+	return pswap.ErrInsufficientXMRBalance
+}
+
 type errAmountProvidedTooLow struct {
 	providedAmount *apd.Decimal
 	minAmount      *apd.Decimal
@@ -71,6 +109,14 @@ func (e errAmountProvidedTooHigh) Error() string {
 	)
 }
 
+type errMakerAtCapacity struct {
+	maxSwaps uint
+}
+
+func (e errMakerAtCapacity) Error() string {
+	return fmt.Sprintf("maker at capacity, already running the maximum of %d concurrent swaps", e.maxSwaps)
+}
+
 type errUnlockedBalanceTooLow struct {
 	maxOfferAmount  *apd.Decimal
 	unlockedBalance *apd.Decimal
@@ -82,3 +128,58 @@ func (e errUnlockedBalanceTooLow) Error() string {
 		e.maxOfferAmount.String(),
 	)
 }
+
+func (e errUnlockedBalanceTooLow) Unwrap() error {
+	return pswap.ErrInsufficientXMRBalance
+}
+
+type errOfferTooLargeForBalance struct {
+	maxOfferAmount *apd.Decimal
+	allowedAmount  *apd.Decimal
+}
+
+func (e errOfferTooLargeForBalance) Error() string {
+	return fmt.Sprintf("maximum offer amount of %s XMR is over the allowed maximum of %s XMR for our balance",
+		e.maxOfferAmount.String(),
+		e.allowedAmount.String(),
+	)
+}
+
+func (e errOfferTooLargeForBalance) Unwrap() error {
+	return pswap.ErrInsufficientXMRBalance
+}
+
+type errUnlockedBalanceTooLowForFee struct {
+	maxOfferAmount  *apd.Decimal
+	fee             *apd.Decimal
+	unlockedBalance *apd.Decimal
+}
+
+func (e errUnlockedBalanceTooLowForFee) Error() string {
+	return fmt.Sprintf("balance %s XMR is too low for maximum offer amount of %s XMR plus estimated fee of %s XMR",
+		e.unlockedBalance.String(),
+		e.maxOfferAmount.String(),
+		e.fee.String(),
+	)
+}
+
+func (e errUnlockedBalanceTooLowForFee) Unwrap() error {
+	return pswap.ErrInsufficientXMRBalance
+}
+
+type errETHBalanceTooLowForClaim struct {
+	balance  *coins.WeiAmount
+	required *coins.WeiAmount
+}
+
+func (e errETHBalanceTooLowForClaim) Error() string {
+	return fmt.Sprintf("ETH balance of %s ETH is below the estimated %s ETH needed to claim the swap,"+
+		" fund the account or use relayers for the offer",
+		e.balance.AsEtherString(),
+		e.required.AsEtherString(),
+	)
+}
+
+func (e errETHBalanceTooLowForClaim) Unwrap() error {
+	return pswap.ErrInsufficientETHBalance
+}
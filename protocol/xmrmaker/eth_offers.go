@@ -0,0 +1,159 @@
+package xmrmaker
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/net/message"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+
+	"github.com/fatih/color"
+)
+
+// ETHOfferHandler runs the ETH-providing side of swaps on our offers that
+// provide ETH. It is implemented by *xmrtaker.Instance.
+type ETHOfferHandler interface {
+	HandleTakeETHOffer(
+		takerPeerID peer.ID,
+		offer *types.Offer,
+		msg *message.SendKeysMessage,
+		onDone func(types.Status),
+	) (common.SwapState, common.Message, error)
+}
+
+// handleTakeETHOffer hands a take of one of our offers that provide ETH to the
+// ETH offer handler. The offer is unavailable while the swap runs, and removed
+// if the swap succeeds.
+func (inst *Instance) handleTakeETHOffer(
+	takerPeerID peer.ID,
+	offer *types.Offer,
+	msg *message.SendKeysMessage,
+) (net.SwapState, common.Message, error) {
+	if inst.ethOffers == nil {
+		return nil, nil, errETHOffersNotSupported
+	}
+
+	if _, _, err := inst.offerManager.TakeOffer(offer.ID); err != nil {
+		return nil, nil, err
+	}
+
+	onDone := func(status types.Status) {
+		var err error
+		if status == types.CompletedSuccess {
+			err = inst.offerManager.DeleteOffer(offer.ID)
+		} else {
+			err = inst.offerManager.ReleaseOffer(offer, false)
+		}
+		if err != nil {
+			log.Warnf("failed to update offer %s after swap exited: %s", offer.ID, err)
+		}
+	}
+
+	s, resp, err := inst.ethOffers.HandleTakeETHOffer(takerPeerID, offer, msg, onDone)
+	if err != nil {
+		if relErr := inst.offerManager.ReleaseOffer(offer, false); relErr != nil {
+			log.Warnf("failed to re-add offer %s: %s", offer.ID, relErr)
+		}
+		return nil, nil, err
+	}
+
+	return s, resp, nil
+}
+
+// InitiateProtocol starts a swap on the given maker's offer that provides ETH,
+// where we provide providesAmount of XMR. Our keys are sent to the maker when
+// the swap is initiated on the network, and once the maker's ETH is locked, the
+// swap continues like one on our own offers.
+func (inst *Instance) InitiateProtocol(
+	makerPeerID peer.ID,
+	providesAmount *apd.Decimal,
+	offer *types.Offer,
+) (common.SwapState, error) {
+	if offer.Provides != coins.ProvidesETH {
+		return nil, errNotETHOffer
+	}
+
+	ethAmount, err := offer.ExchangeRate.ToETH(providesAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredAmount, err := pcommon.GetEthereumAssetAmount(
+		inst.backend.Ctx(),
+		inst.backend.ETHClient(),
+		ethAmount,
+		offer.EthAsset,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	inst.swapMu.Lock()
+	defer inst.swapMu.Unlock()
+
+	if inst.swapStates[offer.ID] != nil {
+		return nil, errProtocolAlreadyInProgress
+	}
+
+	providesPiconero := coins.MoneroToPiconero(providesAmount)
+	if err = inst.checkUnlockedBalance(providesPiconero); err != nil {
+		return nil, err
+	}
+
+	s, err := newSwapStateFromStart(
+		inst.backend,
+		makerPeerID,
+		offer,
+		new(types.OfferExtra),
+		nil, // the offer isn't ours
+		providesPiconero,
+		desiredAmount,
+		inst.swapOptions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.initiator = true
+	inst.reservations.reserve(offer.ID, providesPiconero)
+
+	go func() {
+		<-s.done
+		inst.reservations.release(offer.ID)
+		inst.swapMu.Lock()
+		defer inst.swapMu.Unlock()
+		delete(inst.swapStates, offer.ID)
+	}()
+
+	log.Info(color.New(color.Bold).Sprintf("**initiated swap on ETH offer ID=%s**", s.info.ID))
+	log.Info(color.New(color.Bold).Sprint("DO NOT EXIT THIS PROCESS OR FUNDS MAY BE LOST!"))
+	inst.swapStates[offer.ID] = s
+	return s, nil
+}
+
+// handleCounterpartyKeys handles the keys that the maker of an offer providing
+// ETH replies to our keys with, when we initiated the swap. We then send our
+// keys again, which tells the maker to lock its ETH.
+func (s *swapState) handleCounterpartyKeys(msg *message.SendKeysMessage) error {
+	if !s.initiator || s.xmrtakerPublicSpendKey != nil {
+		return errUnexpectedMessageType
+	}
+
+	if msg.ProvidedAmount == nil || msg.ProvidedAmount.Cmp(s.info.ExpectedAmount) < 0 {
+		return fmt.Errorf("provided amount is not the same as expected: got %v, expected %s",
+			msg.ProvidedAmount,
+			s.info.ExpectedAmount.Text('f'),
+		)
+	}
+
+	if err := s.handleSendKeysMessage(msg); err != nil {
+		return err
+	}
+
+	return s.SendSwapMessage(s.SendKeysMessage(), s.ID())
+}
@@ -1,11 +1,13 @@
 package xmrmaker
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
 )
 
 // EventType represents an event that occurs which moves the swap
@@ -29,7 +31,11 @@ const (
 
 	// EventETHRefundedType is triggered when the taker refunds the
 	// contract-locked ETH back to themselves. It causes use to try to refund
-	// our XMR. After this event, the only possible event is EventExitType.
+	// our XMR. After this event, the only possible event is EventExitType. It
+	// is also the next expected event if our XMR lock didn't confirm before its
+	// deadline, in which case the only other possible event is
+	// EventContractReadyType, if the taker saw the lock and set the contract
+	// to ready.
 	EventETHRefundedType
 
 	// EventExitType is triggered by the protocol "exiting", which may happen
@@ -80,11 +86,12 @@ func (t EventType) getStatus() types.Status {
 	switch t {
 	case EventETHLockedType:
 		return types.KeysExchanged
-	case EventContractReadyType:
+	case EventContractReadyType, EventETHRefundedType:
 		return types.XMRLocked
 	default:
-		// the only possible nextExpectedEvents are EventETHLockedType
-		// and EventContractReadyType, so this case shouldn't be hit.
+		// the only possible nextExpectedEvents are EventETHLockedType,
+		// EventContractReadyType and EventETHRefundedType, so this case
+		// shouldn't be hit.
 		return types.UnknownStatus
 	}
 }
@@ -95,7 +102,9 @@ type Event interface {
 }
 
 // EventETHLocked is the first expected event. It represents ETH being locked
-// on-chain.
+// on-chain. The message is nil when resuming a swap from the recovery db, in
+// which case the contract info was already stored when the message was first
+// received.
 type EventETHLocked struct {
 	message *message.NotifyETHLocked
 	errCh   chan error
@@ -153,6 +162,8 @@ func newEventETHRefunded(sk *mcrypto.PrivateSpendKey) *EventETHRefunded {
 // for example if the remote peer closes their connection with us before sending all
 // required messages, or we decide to cancel the swap.
 type EventExit struct {
+	// cause is the error that the swap is exiting because of, if any
+	cause error
 	errCh chan error
 }
 
@@ -161,8 +172,9 @@ func (*EventExit) Type() EventType {
 	return EventExitType
 }
 
-func newEventExit() *EventExit {
+func newEventExit(cause error) *EventExit {
 	return &EventExit{
+		cause: cause,
 		errCh: make(chan error),
 	}
 }
@@ -190,12 +202,33 @@ func (s *swapState) handleEvent(event Event) {
 			return
 		}
 
-		err := s.handleNotifyETHLocked(e.message)
+		var err error
+		if e.message == nil {
+			err = s.handleResumeETHLocked()
+		} else {
+			err = s.handleNotifyETHLocked(e.message)
+		}
 		if err != nil {
-			e.errCh <- fmt.Errorf("failed to handle EventETHLocked: %w", err)
-			if !s.fundsLocked {
-				return
+			if errors.Is(err, pswap.ErrInsufficientXMRBalance) && !s.fundsLocked {
+				// None of our XMR was sent, so we abort now instead of when the
+				// swap exits. The returned error closes the swap stream, which
+				// tells the counterparty to refund.
+				log.Warnf("aborting swap %s, not enough XMR to lock: %s", s.ID(), err)
+				s.info.SetFailure(err)
+				s.clearNextExpectedEvent(types.CompletedAbort)
+			}
+
+			if s.fundsLocked {
+				// Our XMR was sent, but didn't confirm before its deadline, so
+				// we wait for the counterparty to refund, which lets us reclaim
+				// it.
+				if err2 := s.setNextExpectedEvent(EventETHRefundedType); err2 != nil {
+					log.Warnf("failed to set next expected event to EventETHRefundedType: %s", err2)
+				}
 			}
+
+			e.errCh <- fmt.Errorf("failed to handle EventETHLocked: %w", err)
+			return
 		}
 
 		err = s.setNextExpectedEvent(EventContractReadyType)
@@ -207,7 +240,10 @@ func (s *swapState) handleEvent(event Event) {
 		log.Infof("EventContractReady")
 		defer close(e.errCh)
 
-		if s.nextExpectedEvent != EventContractReadyType {
+		// while waiting for a refund after our XMR lock missed its deadline,
+		// the counterparty only sets the contract to ready if it saw the lock
+		// confirm, so we can still claim
+		if s.nextExpectedEvent != EventContractReadyType && s.nextExpectedEvent != EventETHRefundedType {
 			e.errCh <- fmt.Errorf("nextExpectedEvent was %s, not %s", s.nextExpectedEvent, e.Type())
 			return
 		}
@@ -218,7 +254,7 @@ func (s *swapState) handleEvent(event Event) {
 			return
 		}
 
-		err = s.exit()
+		err = s.exit(nil)
 		if err != nil {
 			log.Warnf("failed to exit swap: %s", err)
 		}
@@ -232,7 +268,7 @@ func (s *swapState) handleEvent(event Event) {
 			return
 		}
 
-		err = s.exit()
+		err = s.exit(nil)
 		if err != nil {
 			log.Warnf("failed to exit swap: %s", err)
 		}
@@ -241,7 +277,7 @@ func (s *swapState) handleEvent(event Event) {
 		log.Infof("EventExit")
 		defer close(e.errCh)
 
-		err := s.exit()
+		err := s.exit(e.cause)
 		if err != nil {
 			e.errCh <- fmt.Errorf("failed to handle EventExit: %w", err)
 		}
@@ -261,7 +297,7 @@ func (s *swapState) handleEventContractReady() error {
 		log.Warnf("failed to claim funds from contract, attempting to safely exit: %s", err)
 
 		// TODO: retry claim, depending on error (#162)
-		if err2 := s.exit(); err2 != nil {
+		if err2 := s.exit(err); err2 != nil {
 			return fmt.Errorf("failed to exit after failing to claim: %w", err2)
 		}
 
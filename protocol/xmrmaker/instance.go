@@ -2,18 +2,25 @@ package xmrmaker
 
 import (
 	"fmt"
+	"path"
 	"sync"
+	"time"
 
 	"github.com/MarinX/monerorpc/wallet"
+	"github.com/cockroachdb/apd/v3"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/dleq"
+	"github.com/athanorlabs/atomic-swap/monero"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
+	"github.com/athanorlabs/atomic-swap/protocol/txsender"
 	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker/offers"
+	"github.com/athanorlabs/atomic-swap/relayer"
 
 	logging "github.com/ipfs/go-log"
 )
@@ -35,8 +42,31 @@ type Instance struct {
 
 	net Host
 
+	// skipConnectivityCheck disables verifying that the ethereum node and monero
+	// wallet are healthy before accepting a take
+	skipConnectivityCheck bool
+
+	// collapseDuplicateOffers has MakeOffer return an existing offer with the
+	// same terms instead of adding a new one
+	collapseDuplicateOffers bool
+
+	// maxConcurrentSwaps is the maximum number of swaps that can run at once,
+	// takes are rejected when it's reached (zero is unlimited)
+	maxConcurrentSwaps uint
+
+	// maxOfferBalanceFraction is the largest fraction of our unlocked balance
+	// that an offer's max amount can be (nil is unlimited)
+	maxOfferBalanceFraction *apd.Decimal
+
+	// options passed to each swap
+	swapOptions
+
 	offerManager *offers.Manager
 
+	// runs the ETH-providing side of swaps on our offers that provide ETH,
+	// which aren't supported if it's nil
+	ethOffers ETHOfferHandler
+
 	swapMu     sync.Mutex // synchronises access to swapStates
 	swapStates map[types.Hash]*swapState
 }
@@ -49,27 +79,250 @@ type Config struct {
 	WalletFile, WalletPassword string
 	ExternalSender             bool
 	Network                    Host
+	OraclePricing              *offers.OraclePricingConfig // optional, pegs offer rates to a price oracle
+	FiatPricing                *offers.FiatPricingConfig   // optional, enables offers pegged to a fiat currency
+	SkipConnectivityCheck      bool
+	XMRLockMargin              time.Duration // optional, how long before t0 the XMR lock must confirm by
+	PerSwapWallet              bool          // lock and reclaim XMR using a wallet named by the swap ID
+
+	// ReclaimOnKeyPersistFailure continues reclaiming XMR after a refund even if
+	// the counterparty's swap key can't be stored in the db. By default, the
+	// reclaim is aborted.
+	ReclaimOnKeyPersistFailure bool
+
+	// CollapseDuplicateOffers returns an existing offer with the same terms
+	// from MakeOffer, instead of adding an offer that only differs by its nonce.
+	// By default, a warning is logged and the new offer is added.
+	CollapseDuplicateOffers bool
+
+	// ClaimReceiptRetries is how many times to retry fetching the receipt of a
+	// relayed claim after a transient RPC error. Zero uses the default.
+	ClaimReceiptRetries uint
+
+	// RelayClaimRetries is how many more times to submit a relayed claim to
+	// the relayers that failed transiently, with exponential backoff. Zero
+	// uses the default.
+	RelayClaimRetries uint
+
+	// ClaimDeadlineMargin is how long before t1 to stop waiting on relayers to
+	// claim, and claim directly instead if we have ETH for gas. Zero uses
+	// DefaultClaimDeadlineBlocks of the network's expected block time.
+	ClaimDeadlineMargin time.Duration
+
+	// KeyGenRetries is how many times to retry generating our swap keys and
+	// DLEq proof when taking an offer. Zero uses the default.
+	KeyGenRetries uint
+
+	// MaxConcurrentSwaps is the maximum number of swaps, including resumed
+	// ones, that can run at once. New takes are rejected while it's reached.
+	// Zero is unlimited.
+	MaxConcurrentSwaps uint
+
+	// MoneroLockPriority is the priority of the XMR lock transfer, which
+	// offers can override. The default lets the wallet pick the priority.
+	MoneroLockPriority types.MoneroTxPriority
+
+	// ETHOffers runs the ETH-providing side of swaps on our offers that
+	// provide ETH. If it's nil, such offers can't be made.
+	ETHOffers ETHOfferHandler
+
+	// ETHLockConfirmations is how many confirmations, counting its block, the
+	// counterparty's ETH lock must have before we lock XMR. The lock is checked
+	// against the ContractSwap again once it has them, and resumed swaps wait
+	// for them as well. Zero only waits for the lock to be mined.
+	ETHLockConfirmations uint64
+
+	// RelayerStats records how the relayers we submit claims to perform, so
+	// the most reliable are tried first. If it's nil, nothing is recorded.
+	RelayerStats RelayerStats
+
+	// MinRelayerSuccessRate is the fraction of claims, between 0 and 1, that a
+	// relayer with a few recorded claims must have gotten mined for us to keep
+	// submitting claims to it. Zero never skips relayers.
+	MinRelayerSuccessRate float64
+
+	// ClaimGas sets how the gas limit of claims submitted to relayers is
+	// estimated. Nil uses the relayer package defaults.
+	ClaimGas *relayer.ClaimGasConfig
+
+	// ClaimGasBump sets how the gas price of claims that we send ourselves is
+	// raised while they're stuck. Its deadline is set for each swap, from t1.
+	// Nil uses the txsender package defaults.
+	ClaimGasBump *txsender.GasBumpConfig
+
+	// XMRReservationWindow is how long the XMR of an accepted take is held
+	// back from our unlocked balance while waiting to lock it, so concurrent
+	// takes can't be accepted against the same funds. Zero uses the default.
+	XMRReservationWindow time.Duration
+
+	// ProofCacheSize is how many verified DLEq proofs of counterparties are
+	// remembered, so that repeated takes with the same keys and proof aren't
+	// verified again. Zero verifies every proof.
+	ProofCacheSize int
+
+	// MaxOfferBalanceFraction is the largest fraction, between 0 and 1, of our
+	// unlocked XMR balance that a new offer's max amount can be, so that we
+	// don't advertise more than we can back. Zero doesn't limit offers beyond
+	// our balance.
+	MaxOfferBalanceFraction float64
+
+	// OfferRevalidationInterval is how often our offers are checked against
+	// MaxOfferBalanceFraction, logging a warning for offers that our balance
+	// no longer backs. Zero disables the checks.
+	OfferRevalidationInterval time.Duration
+
+	// KeyPoolSize is how many swap keys and DLEq proofs are generated ahead
+	// of time in the background, so that taking our offers doesn't wait for
+	// proof generation. Zero generates them when each swap starts.
+	KeyPoolSize uint
 }
 
+const (
+	// DefaultClaimReceiptRetries is the default number of times to retry
+	// fetching the receipt of a relayed claim.
+	DefaultClaimReceiptRetries = 5
+
+	// DefaultRelayClaimRetries is the default number of times to retry
+	// submitting a relayed claim to the relayers that failed transiently.
+	DefaultRelayClaimRetries = 3
+
+	// DefaultClaimDeadlineBlocks is the default claim deadline margin before
+	// t1, in expected ethereum block times. It leaves time for a direct claim
+	// to be included after relayers fail to claim.
+	DefaultClaimDeadlineBlocks = 20
+
+	// DefaultKeyGenRetries is the default number of times to retry generating
+	// our swap keys and DLEq proof.
+	DefaultKeyGenRetries = 2
+
+	// DefaultETHLockConfirmations is the recommended number of confirmations
+	// of the counterparty's ETH lock on public networks. More confirmations
+	// make it less likely that a reorg reverts the lock after we've locked XMR,
+	// but leave less of the swap's timeout for locking XMR.
+	DefaultETHLockConfirmations = 12
+
+	// DefaultXMRReservationWindow is the default time that the XMR of an
+	// accepted take is held back for. It covers the taker locking their ETH
+	// and it being confirmed, after which our XMR is locked.
+	DefaultXMRReservationWindow = 10 * time.Minute
+)
+
 // NewInstance returns a new *xmrmaker.Instance.
 // It accepts an endpoint to a monero-wallet-rpc instance where account 0 contains XMRMaker's XMR.
 func NewInstance(cfg *Config) (*Instance, error) {
+	if cfg.PerSwapWallet && cfg.Backend.PerSwapAccount() {
+		return nil, errPerSwapWalletAndAccount
+	}
+
 	om, err := offers.NewManager(cfg.DataDir, cfg.Database)
 	if err != nil {
 		return nil, err
 	}
 
+	if cfg.OraclePricing != nil {
+		err = om.StartOraclePricing(cfg.Backend.Ctx(), cfg.OraclePricing)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.FiatPricing != nil {
+		err = om.StartFiatPricing(cfg.Backend.Ctx(), cfg.FiatPricing)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if om.NumOffers() > 0 {
 		// this is blocking if the network service hasn't started yet
 		go cfg.Network.Advertise()
 	}
 
+	claimReceiptRetries := cfg.ClaimReceiptRetries
+	if claimReceiptRetries == 0 {
+		claimReceiptRetries = DefaultClaimReceiptRetries
+	}
+
+	relayClaimRetries := cfg.RelayClaimRetries
+	if relayClaimRetries == 0 {
+		relayClaimRetries = DefaultRelayClaimRetries
+	}
+
+	claimDeadlineMargin := cfg.ClaimDeadlineMargin
+	if claimDeadlineMargin == 0 {
+		claimDeadlineMargin = DefaultClaimDeadlineBlocks * expectedETHBlockTime(cfg.Backend.Env())
+	}
+
+	keyGenRetries := cfg.KeyGenRetries
+	if keyGenRetries == 0 {
+		keyGenRetries = DefaultKeyGenRetries
+	}
+
+	reservationWindow := cfg.XMRReservationWindow
+	if reservationWindow == 0 {
+		reservationWindow = DefaultXMRReservationWindow
+	}
+
+	var maxOfferBalanceFraction *apd.Decimal
+	if cfg.MaxOfferBalanceFraction != 0 {
+		if cfg.MaxOfferBalanceFraction < 0 || cfg.MaxOfferBalanceFraction > 1 {
+			return nil, errInvalidOfferBalanceFraction
+		}
+		maxOfferBalanceFraction, err = new(apd.Decimal).SetFloat64(cfg.MaxOfferBalanceFraction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var keyPool *pcommon.KeyPool
+	if cfg.KeyPoolSize > 0 {
+		keyPool = pcommon.NewKeyPool(cfg.Backend.Ctx(), cfg.KeyPoolSize, dleq.DefaultProofScheme)
+	}
+
+	var proofCache *pcommon.ProofCache
+	if cfg.ProofCacheSize > 0 {
+		proofCache, err = pcommon.NewProofCache(cfg.ProofCacheSize, pcommon.DefaultProofCacheTTL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	inst := &Instance{
 		backend:      cfg.Backend,
 		dataDir:      cfg.DataDir,
 		offerManager: om,
 		swapStates:   make(map[types.Hash]*swapState),
 		net:          cfg.Network,
+		ethOffers:    cfg.ETHOffers,
+
+		skipConnectivityCheck:   cfg.SkipConnectivityCheck,
+		collapseDuplicateOffers: cfg.CollapseDuplicateOffers,
+		maxConcurrentSwaps:      cfg.MaxConcurrentSwaps,
+		maxOfferBalanceFraction: maxOfferBalanceFraction,
+		swapOptions: swapOptions{
+			xmrLockMargin:              cfg.XMRLockMargin,
+			perSwapWallet:              cfg.PerSwapWallet,
+			reclaimOnKeyPersistFailure: cfg.ReclaimOnKeyPersistFailure,
+			keyBackupDir:               path.Join(cfg.DataDir, "key-backups"),
+			claimReceiptRetries:        claimReceiptRetries,
+			relayClaimRetries:          relayClaimRetries,
+			claimDeadlineMargin:        claimDeadlineMargin,
+			keyGenRetries:              keyGenRetries,
+			keyPool:                    keyPool,
+			moneroLockPriority:         cfg.MoneroLockPriority,
+			ethLockConfirmations:       cfg.ETHLockConfirmations,
+			relayerStats:               cfg.RelayerStats,
+			minRelayerSuccessRate:      cfg.MinRelayerSuccessRate,
+			claimGas:                   cfg.ClaimGas,
+			claimGasBump:               cfg.ClaimGasBump,
+			reservations:               newXMRReservations(reservationWindow),
+			proofCache:                 proofCache,
+		},
+	}
+	om.SetOfferCheck(inst.checkNewOffer)
+
+	if maxOfferBalanceFraction != nil && cfg.OfferRevalidationInterval > 0 {
+		go inst.revalidateOffers(cfg.Backend.Ctx(), cfg.OfferRevalidationInterval)
 	}
 
 	err = inst.checkForOngoingSwaps()
@@ -86,32 +339,74 @@ func (inst *Instance) checkForOngoingSwaps() error {
 		return err
 	}
 
+	var ours []*swap.Info
 	for _, s := range swaps {
-		if s.Provides != coins.ProvidesXMR {
-			continue
+		if s.Provides == coins.ProvidesXMR {
+			ours = append(ours, s)
 		}
+	}
+
+	return pcommon.ResumeOngoingSwaps(
+		inst.backend.Ctx(),
+		ours,
+		inst.backend.ResumeConcurrency(),
+		inst.resumeOngoingSwap,
+	)
+}
 
-		if s.Status == types.KeysExchanged || s.Status == types.ExpectingKeys {
-			log.Infof("found ongoing swap %s in DB, aborting since no funds were locked", s.ID)
+// resumeOngoingSwap resumes an ongoing swap found in the db on startup, or
+// aborts it if no funds were locked. Only failing to abort it is returned as
+// an error; failing to resume it is logged. The returned channel is that of
+// createOngoingSwap.
+func (inst *Instance) resumeOngoingSwap(s *swap.Info) (<-chan struct{}, error) {
+	if s.Status == types.KeysExchanged && inst.hasContractSwapInfo(s.ID) {
+		// the counterparty locked their ETH before we exited, so we can
+		// resume the swap from where we left off
+		resumed, err := inst.createOngoingSwap(s)
+		if err == nil {
+			return resumed, nil
+		}
 
-			// for these two cases, no funds have been locked, so we can safely
-			// abort the swap.
-			err = inst.abortOngoingSwap(s)
-			if err != nil {
-				return fmt.Errorf("failed to abort ongoing swap: %w", err)
-			}
+		log.Errorf("%s", err)
 
-			continue
+		// only abort if we're sure that no XMR was sent
+		lockStarted, err := inst.backend.RecoveryDB().HasXMRLockStarted(s.ID)
+		if err != nil || lockStarted {
+			return nil, nil
 		}
 
-		err = inst.createOngoingSwap(s)
-		if err != nil {
-			log.Errorf("%s", err)
-			continue
+		if err = inst.abortOngoingSwap(s); err != nil {
+			return nil, fmt.Errorf("failed to abort ongoing swap: %w", err)
 		}
+
+		return nil, nil
 	}
 
-	return nil
+	if s.Status == types.KeysExchanged || s.Status == types.ExpectingKeys {
+		log.Infof("found ongoing swap %s in DB, aborting since no funds were locked", s.ID)
+
+		// for these two cases, no funds have been locked, so we can safely
+		// abort the swap.
+		if err := inst.abortOngoingSwap(s); err != nil {
+			return nil, fmt.Errorf("failed to abort ongoing swap: %w", err)
+		}
+
+		return nil, nil
+	}
+
+	resumed, err := inst.createOngoingSwap(s)
+	if err != nil {
+		log.Errorf("%s", err)
+	}
+
+	return resumed, nil
+}
+
+// hasContractSwapInfo returns whether the counterparty's ETH lock was recorded
+// for the given swap.
+func (inst *Instance) hasContractSwapInfo(id types.Hash) bool {
+	_, err := inst.backend.RecoveryDB().GetContractSwapInfo(id)
+	return err == nil
 }
 
 func (inst *Instance) abortOngoingSwap(s *swap.Info) error {
@@ -125,34 +420,37 @@ func (inst *Instance) abortOngoingSwap(s *swap.Info) error {
 	return inst.backend.RecoveryDB().DeleteSwap(s.ID)
 }
 
-func (inst *Instance) createOngoingSwap(s *swap.Info) error {
+// createOngoingSwap recreates the swap state of an ongoing swap found in the db
+// on startup. If the swap resumes handling the counterparty's ETH lock, the
+// returned channel is closed once it's done; otherwise it's nil.
+func (inst *Instance) createOngoingSwap(s *swap.Info) (<-chan struct{}, error) {
 	log.Infof("found ongoing swap %s in DB, restarting swap", s.ID)
 
 	// check if we have shared secret key in db; if so, recover XMR from that
 	// otherwise, create new swap state from recovery info
 	skA, err := inst.backend.RecoveryDB().GetCounterpartySwapPrivateKey(s.ID)
 	if err == nil {
-		return inst.completeSwap(s, skA)
+		return nil, inst.completeSwap(s, skA)
 	}
 
 	offer, _, err := inst.offerManager.GetOffer(s.ID)
 	if err != nil {
-		return fmt.Errorf("failed to get offer for ongoing swap, id %s: %s", s.ID, err)
+		return nil, fmt.Errorf("failed to get offer for ongoing swap, id %s: %s", s.ID, err)
 	}
 
 	ethSwapInfo, err := inst.backend.RecoveryDB().GetContractSwapInfo(s.ID)
 	if err != nil {
-		return fmt.Errorf("failed to get contract info for ongoing swap from db with swap id %s: %w", s.ID, err)
+		return nil, fmt.Errorf("failed to get contract info for ongoing swap from db with swap id %s: %w", s.ID, err)
 	}
 
 	sk, err := inst.backend.RecoveryDB().GetSwapPrivateKey(s.ID)
 	if err != nil {
-		return fmt.Errorf("failed to get private key for ongoing swap from db with swap id %s: %w", s.ID, err)
+		return nil, fmt.Errorf("failed to get private key for ongoing swap from db with swap id %s: %w", s.ID, err)
 	}
 
 	kp, err := sk.AsPrivateKeyPair()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	relayerInfo, err := inst.backend.RecoveryDB().GetSwapRelayerInfo(s.ID)
@@ -162,6 +460,16 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		relayerInfo = &types.OfferExtra{}
 	}
 
+	// the offer is loaded from the db on startup, but is taken by this swap
+	if _, _, err = inst.offerManager.TakeOffer(s.ID); err != nil {
+		return nil, err
+	}
+	if relayerInfo.Cancelled {
+		if err = inst.offerManager.ClearOfferIDs([]types.Hash{s.ID}); err != nil {
+			return nil, err
+		}
+	}
+
 	ss, err := newSwapStateFromOngoing(
 		inst.backend,
 		offer,
@@ -170,9 +478,13 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		ethSwapInfo,
 		s,
 		kp,
+		inst.swapOptions,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create new swap state for ongoing swap, id %s: %w", s.ID, err)
+		if relErr := inst.offerManager.ReleaseOffer(offer, relayerInfo.UseRelayer); relErr != nil {
+			log.Warnf("failed to re-add offer %s: %s", s.ID, relErr)
+		}
+		return nil, fmt.Errorf("failed to create new swap state for ongoing swap, id %s: %w", s.ID, err)
 	}
 
 	inst.swapMu.Lock()
@@ -186,7 +498,7 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		delete(inst.swapStates, offer.ID)
 	}()
 
-	return nil
+	return ss.resumed, nil
 }
 
 // completeSwap is called in the case where we find an ongoing swap in the db on startup,
@@ -197,7 +509,9 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 //
 // Note: this will use the current value of `noTransferBack` (verses whatever value was
 // set when the swap was started). It will also only only recover to the primary wallet
-// address, not whatever address was used when the swap was started.
+// address, not whatever address was used when the swap was started, unless per-swap
+// wallets are enabled, in which case it recovers to the swap's wallet, or the swap
+// has its own account, in which case it recovers to that account.
 func (inst *Instance) completeSwap(s *swap.Info, skA *mcrypto.PrivateSpendKey) error {
 	// fetch our swap private spend key
 	skB, err := inst.backend.RecoveryDB().GetSwapPrivateKey(s.ID)
@@ -223,6 +537,23 @@ func (inst *Instance) completeSwap(s *swap.Info, skA *mcrypto.PrivateSpendKey) e
 		vkA, vkB,
 	)
 
+	depositAddr := inst.backend.XMRClient().PrimaryAddress()
+	if inst.perSwapWallet {
+		conf := inst.backend.XMRClient().WalletConf(swapWalletName(s.ID))
+		swapWallet, err := monero.NewWalletClient(conf) //nolint:govet
+		if err != nil {
+			return fmt.Errorf("failed to open swap wallet %s: %w", conf.WalletFilePath, err)
+		}
+		depositAddr = swapWallet.PrimaryAddress()
+		swapWallet.Close()
+	}
+	if inst.backend.PerSwapAccount() || s.MoneroAccountIndex != nil {
+		depositAddr, err = pcommon.SwapAccountAddress(inst.backend, s)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = pcommon.ClaimMonero(
 		inst.backend.Ctx(),
 		inst.backend.Env(),
@@ -230,8 +561,9 @@ func (inst *Instance) completeSwap(s *swap.Info, skA *mcrypto.PrivateSpendKey) e
 		inst.backend.XMRClient(),
 		s.MoneroStartHeight,
 		kpAB,
-		inst.backend.XMRClient().PrimaryAddress(),
-		false, // always sweep back to our primary address
+		depositAddr,
+		false, // always sweep back to our primary address or swap wallet
+		inst.backend.SweepConfirmations(),
 	)
 	if err != nil {
 		return err
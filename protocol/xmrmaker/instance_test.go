@@ -58,7 +58,12 @@ func (n *mockNet) DiscoverRelayers() ([]peer.ID, error) {
 	return nil, nil
 }
 
-func (n *mockNet) SubmitClaimToRelayer(_ peer.ID, _ *message.RelayClaimRequest) (*message.RelayClaimResponse, error) {
+func (n *mockNet) MarkRelayerFailed(_ peer.ID) {}
+
+func (n *mockNet) SubmitClaimToRelayer(
+	_ peer.ID,
+	_ message.RelayClaimRequestFunc,
+) (*message.RelayClaimResponse, error) {
 	return new(message.RelayClaimResponse), nil
 }
 
@@ -99,6 +104,7 @@ func newBackendAndNet(t *testing.T) (backend.Backend, *mockNet) {
 	rdb.EXPECT().PutCounterpartySwapPrivateKey(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	rdb.EXPECT().PutSwapRelayerInfo(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	rdb.EXPECT().PutCounterpartySwapKeys(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	rdb.EXPECT().PutXMRLockStarted(gomock.Any()).Return(nil).AnyTimes()
 	rdb.EXPECT().DeleteSwap(gomock.Any()).Return(nil).AnyTimes()
 
 	extendedEC, err := extethclient.NewEthClient(context.Background(), env, common.DefaultEthEndpoint, pk)
@@ -195,8 +201,9 @@ func TestInstance_createOngoingSwap(t *testing.T) {
 	)
 	offerDB.EXPECT().GetOffer(s.ID).Return(offer, nil)
 
-	err = inst.createOngoingSwap(s)
+	resumed, err := inst.createOngoingSwap(s)
 	require.NoError(t, err)
+	require.Nil(t, resumed) // the XMR was locked, so there's no lock to resume
 
 	inst.swapMu.Lock()
 	defer inst.swapMu.Unlock()
@@ -0,0 +1,61 @@
+package xmrmaker
+
+import (
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// GetOfferLiquidity returns our offers, including those taken by in-flight
+// swaps, with the XMR reserved by the swap on each, along with our unlocked
+// XMR balance and how much of it is reserved and available. The available
+// balance is what new takes are checked against.
+func (inst *Instance) GetOfferLiquidity() (*types.MakerLiquidity, error) {
+	balance, err := inst.backend.XMRClient().GetBalance(0)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved, err := inst.reservations.total()
+	if err != nil {
+		return nil, err
+	}
+
+	unlocked := coins.NewPiconeroAmount(balance.UnlockedBalance).AsMonero()
+	available := new(apd.Decimal)
+	if _, err = coins.DecimalCtx().Sub(available, unlocked, reserved.AsMonero()); err != nil {
+		return nil, err
+	}
+	if available.Negative {
+		available.SetInt64(0)
+	}
+
+	liquidity := &types.MakerLiquidity{
+		UnlockedBalance:  unlocked,
+		ReservedBalance:  reserved.AsMonero(),
+		AvailableBalance: available,
+	}
+
+	for _, o := range inst.offerManager.GetOffers() {
+		liquidity.Offers = append(liquidity.Offers, inst.offerLiquidity(o, false))
+	}
+	for _, o := range inst.offerManager.GetTakenOffers() {
+		liquidity.Offers = append(liquidity.Offers, inst.offerLiquidity(o, true))
+	}
+
+	return liquidity, nil
+}
+
+func (inst *Instance) offerLiquidity(o *types.Offer, taken bool) *types.OfferLiquidity {
+	return &types.OfferLiquidity{
+		OfferID:        o.ID,
+		Provides:       o.Provides,
+		MinAmount:      o.MinAmount,
+		MaxAmount:      o.MaxAmount,
+		ExchangeRate:   o.ExchangeRate,
+		EthAsset:       o.EthAsset,
+		Taken:          taken,
+		ReservedAmount: inst.reservations.reserved(o.ID).AsMonero(),
+	}
+}
@@ -10,10 +10,13 @@ import (
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/db"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/block"
+	"github.com/athanorlabs/atomic-swap/metrics"
+	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
-	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 )
 
 // HandleProtocolMessage is called by the network to handle an incoming message.
@@ -29,11 +32,17 @@ func (s *swapState) HandleProtocolMessage(msg common.Message) error {
 	}
 
 	switch msg := msg.(type) {
+	case *message.SendKeysMessage:
+		if err := s.handleCounterpartyKeys(msg); err != nil {
+			s.info.SetFailure(err)
+			return err
+		}
 	case *message.NotifyETHLocked:
 		event := newEventETHLocked(msg)
 		s.eventCh <- event
 		err := <-event.errCh
 		if err != nil {
+			s.info.SetFailure(err)
 			return err
 		}
 
@@ -51,6 +60,7 @@ func (s *swapState) HandleProtocolMessage(msg common.Message) error {
 func (s *swapState) clearNextExpectedEvent(status types.Status) {
 	s.nextExpectedEvent = EventNoneType
 	s.info.SetStatus(status)
+	metrics.SwapExited(s.info.Provides, status, s.info.StartTime)
 	if s.offerExtra.StatusCh != nil {
 		s.offerExtra.StatusCh <- status
 	}
@@ -111,6 +121,10 @@ func (s *swapState) handleNotifyETHLocked(msg *message.NotifyETHLocked) error {
 	}
 
 	contractAddr := msg.Address
+	if s.offer.SwapFactory != nil && *s.offer.SwapFactory != contractAddr {
+		return fmt.Errorf("%w: offer requires %s, got %s", errWrongSwapFactory, s.offer.SwapFactory, contractAddr)
+	}
+
 	// note: this function verifies the forwarder code as well, even if we aren't using a relayer,
 	// in which case it's not relevant to us and we don't need to verify it.
 	// doesn't hurt though I suppose.
@@ -145,6 +159,84 @@ func (s *swapState) handleNotifyETHLocked(msg *message.NotifyETHLocked) error {
 		return err
 	}
 
+	// a reorg could revert the ETH lock after we've locked XMR, so we wait for
+	// it to be buried deep enough first
+	_, err = block.WaitForConfirmations(s.ctx, s.ETHClient().Raw(), msg.TxHash, s.ethLockConfirmations)
+	if err != nil {
+		return fmt.Errorf("failed waiting for ETH lock confirmations: %w", err)
+	}
+
+	// the transaction may have been mined again in another block while we
+	// waited, so what we checked in its first block is checked again
+	if s.ethLockConfirmations > 1 {
+		if err = s.checkContract(msg.TxHash); err != nil {
+			return fmt.Errorf("ETH lock changed while waiting for confirmations: %w", err)
+		}
+	}
+
+	if err = s.checkContractPending(); err != nil {
+		return err
+	}
+
+	err = s.lockFunds(coins.MoneroToPiconero(s.info.ProvidedAmount))
+	if err != nil {
+		return fmt.Errorf("failed to lock funds: %w", err)
+	}
+
+	go s.runT0ExpirationHandler()
+	return nil
+}
+
+// handleResumeETHLocked is called instead of handleNotifyETHLocked when resuming a
+// swap where the counterparty's ETH was locked, but we exited before locking XMR.
+// The contract info and timeouts were already validated and stored when we first
+// received NotifyETHLocked, so we only re-check that it's still safe to lock.
+func (s *swapState) handleResumeETHLocked() error {
+	lockStarted, err := s.RecoveryDB().HasXMRLockStarted(s.ID())
+	if err != nil {
+		return err
+	}
+
+	if lockStarted {
+		// We may have exited between sending the transfer and it confirming, so
+		// we never lock again, as that could double our XMR outlay.
+		var locked bool
+		locked, err = s.xmrLockReceived()
+		if err != nil {
+			// without knowing, we continue as if it was sent, as the
+			// counterparty will refund if it wasn't
+			log.Warnf("failed to check XMR lock of swap %s, assuming it was sent: %s", s.ID(), err)
+			locked = true
+		}
+
+		if !locked {
+			return errXMRLockNotReceived
+		}
+
+		log.Infof("XMR lock for swap %s was sent before exiting, not locking again", s.ID())
+		s.fundsLocked = true
+		go s.runT0ExpirationHandler()
+		return nil
+	}
+
+	// we may have exited while waiting for the ETH lock's confirmations, and
+	// only know the block that it was first seen in
+	err = block.WaitForDepth(s.ctx, s.ETHClient().Raw(), s.ethStartNumber, s.ethLockConfirmations)
+	if err != nil {
+		return fmt.Errorf("failed waiting for ETH lock confirmations: %w", err)
+	}
+
+	if err = s.checkContractPending(); err != nil {
+		return err
+	}
+
+	// Locking takes a while to confirm and the counterparty needs time to see it
+	// before t0, so we only continue with at least half the swap timeout left.
+	minTimeLeft := s.t1.Sub(s.t0) / 2
+	if time.Until(s.t0) < minTimeLeft {
+		return errResumeTooCloseToT0
+	}
+
 	err = s.lockFunds(coins.MoneroToPiconero(s.info.ProvidedAmount))
 	if err != nil {
 		return fmt.Errorf("failed to lock funds: %w", err)
@@ -154,6 +246,45 @@ func (s *swapState) handleNotifyETHLocked(msg *message.NotifyETHLocked) error {
 	return nil
 }
 
+// xmrLockReceived returns whether any XMR was received at the swap's lock
+// address, which is checked with a view-only wallet of the address.
+func (s *swapState) xmrLockReceived() (bool, error) {
+	xmrtakerPublicKeys := mcrypto.NewPublicKeyPair(s.xmrtakerPublicSpendKey, s.xmrtakerPrivateViewKey.Public())
+	lockAddr := mcrypto.SumSpendAndViewKeys(xmrtakerPublicKeys, s.pubkeys).Address(s.Env())
+	vk := mcrypto.SumPrivateViewKeys(s.xmrtakerPrivateViewKey, s.privkeys.ViewKey())
+
+	conf := s.XMRClient().CreateWalletConf("xmrmaker-swap-wallet-verify-lock")
+	viewCli, err := monero.CreateViewOnlyWalletFromKeys(conf, vk, lockAddr, s.moneroStartHeight)
+	if err != nil {
+		return false, fmt.Errorf("failed to generate view-only wallet to verify locked XMR: %w", err)
+	}
+	defer viewCli.CloseAndRemoveWallet()
+
+	balance, err := viewCli.GetBalance(0)
+	if err != nil {
+		return false, err
+	}
+
+	return balance.Balance > 0, nil
+}
+
+// checkContractPending checks that the swap's ETH is locked in the contract,
+// and that it can still be claimed once set to ready. As the contract's swap ID
+// is the hash of the whole ContractSwap, this also checks that the locked swap
+// is the one we expect.
+func (s *swapState) checkContractPending() error {
+	stage, err := s.contract.Swaps(s.ETHClient().CallOpts(s.ctx), s.contractSwapID)
+	if err != nil {
+		return err
+	}
+
+	if stage != contracts.StagePending {
+		return fmt.Errorf("%w: stage is %s", errContractNotPending, contracts.StageToString(stage))
+	}
+
+	return nil
+}
+
 func (s *swapState) runT0ExpirationHandler() {
 	log.Debugf("time until t0 (%s): %vs",
 		s.t0.Format(common.TimeFmtSecs),
@@ -205,7 +336,12 @@ func (s *swapState) handleSendKeysMessage(msg *message.SendKeysMessage) error {
 	}
 
 	// verify counterparty's DLEq proof and ensure the resulting secp256k1 key is correct
-	verifyResult, err := pcommon.VerifyKeysAndProof(msg.DLEqProof, msg.Secp256k1PublicKey, msg.PublicSpendKey)
+	verifyResult, err := s.proofCache.VerifyKeysAndProof(
+		msg.DLEqProofScheme,
+		msg.DLEqProof,
+		msg.Secp256k1PublicKey,
+		msg.PublicSpendKey,
+	)
 	if err != nil {
 		return err
 	}
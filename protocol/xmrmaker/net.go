@@ -1,13 +1,17 @@
 package xmrmaker
 
 import (
+	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/net"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
@@ -15,6 +19,17 @@ import (
 	"github.com/fatih/color"
 )
 
+// Conservative gas amounts of claiming a swap ourselves. The claim can't be
+// simulated when a swap is taken, as the swap doesn't exist on-chain yet.
+const (
+	claimETHGas   = 50_000
+	claimERC20Gas = 70_000
+
+	// claimGasPriceHeadroom multiplies the current gas price, as the claim is
+	// sent later in the swap, when the gas price may be higher.
+	claimGasPriceHeadroom = 2
+)
+
 // EthereumAssetAmount represents an amount of an Ethereum asset (ie. ether or an ERC20)
 type EthereumAssetAmount interface {
 	BigInt() *big.Int
@@ -27,6 +42,7 @@ func (inst *Instance) Provides() coins.ProvidesCoin {
 }
 
 func (inst *Instance) initiate(
+	takerPeerID peer.ID,
 	offer *types.Offer,
 	offerExtra *types.OfferExtra,
 	providesAmount *coins.PiconeroAmount,
@@ -36,41 +52,42 @@ func (inst *Instance) initiate(
 		return nil, errProtocolAlreadyInProgress
 	}
 
-	balance, err := inst.backend.XMRClient().GetBalance(0)
-	if err != nil {
+	if err := inst.checkUnlockedBalance(providesAmount); err != nil {
 		return nil, err
 	}
 
-	// check that the user's monero balance is sufficient for their max swap amount (strictly
-	// greater check, since they need to cover chain fees).
-	unlockedBal := coins.NewPiconeroAmount(balance.UnlockedBalance)
-	if unlockedBal.Decimal().Cmp(providesAmount.Decimal()) <= 0 {
-		return nil, errBalanceTooLow{
-			unlockedBalance: unlockedBal.AsMonero(),
-			providedAmount:  providesAmount.AsMonero(),
-		}
-	}
-
 	// checks passed, delete the offer from memory for now
-	_, _, err = inst.offerManager.TakeOffer(offer.ID)
+	_, _, err := inst.offerManager.TakeOffer(offer.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	s, err := newSwapStateFromStart(
 		inst.backend,
+		takerPeerID,
 		offer,
 		offerExtra,
 		inst.offerManager,
 		providesAmount,
 		desiredAmount,
+		inst.swapOptions,
 	)
 	if err != nil {
+		// put the offer back, so it isn't left taken by a swap that never
+		// started (this is a no-op if exiting the swap already re-added it)
+		if relErr := inst.offerManager.ReleaseOffer(offer, offerExtra.UseRelayer); relErr != nil {
+			log.Warnf("failed to re-add offer %s: %s", offer.ID, relErr)
+		}
 		return nil, err
 	}
 
+	// hold back the XMR we're going to lock until it's locked, so that other
+	// takes can't be accepted against it
+	inst.reservations.reserve(offer.ID, providesAmount)
+
 	go func() {
 		<-s.done
+		inst.reservations.release(offer.ID)
 		inst.swapMu.Lock()
 		defer inst.swapMu.Unlock()
 		delete(inst.swapStates, offer.ID)
@@ -92,8 +109,84 @@ func (inst *Instance) initiate(
 	return s, nil
 }
 
+// checkUnlockedBalance checks that our monero balance, less the XMR reserved by
+// other swaps that haven't locked yet, is sufficient to provide the passed
+// amount (strictly greater check, since we need to cover chain fees).
+func (inst *Instance) checkUnlockedBalance(providesAmount *coins.PiconeroAmount) error {
+	balance, err := inst.backend.XMRClient().GetBalance(0)
+	if err != nil {
+		return err
+	}
+
+	reserved, err := inst.reservations.total()
+	if err != nil {
+		return err
+	}
+
+	unlockedBal := coins.NewPiconeroAmount(balance.UnlockedBalance)
+	available := new(apd.Decimal)
+	if _, err = coins.DecimalCtx().Sub(available, unlockedBal.Decimal(), reserved.Decimal()); err != nil {
+		return err
+	}
+
+	if available.Cmp(providesAmount.Decimal()) <= 0 {
+		return errBalanceTooLow{
+			unlockedBalance: unlockedBal.AsMonero(),
+			reservedAmount:  reserved.AsMonero(),
+			providedAmount:  providesAmount.AsMonero(),
+		}
+	}
+
+	return nil
+}
+
+// checkClaimBalance checks that our ETH balance covers the gas of claiming a
+// swap of the passed asset at the current gas price, with some headroom for the
+// price rising by the time we claim. Swaps claimed with relayers don't need any
+// ETH, so they always pass.
+func checkClaimBalance(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	asset types.EthAsset,
+	useRelayer bool,
+) error {
+	if useRelayer {
+		return nil
+	}
+
+	gasPrice, err := ec.SuggestGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+
+	gas := uint64(claimETHGas)
+	if asset != types.EthAssetETH {
+		gas = claimERC20Gas
+	}
+
+	required := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))
+	required.Mul(required, big.NewInt(claimGasPriceHeadroom))
+
+	balance, err := ec.Balance(ctx)
+	if err != nil {
+		return err
+	}
+
+	if balance.Cmp(required) < 0 {
+		return errETHBalanceTooLowForClaim{
+			balance:  coins.NewWeiAmount(balance),
+			required: coins.NewWeiAmount(required),
+		}
+	}
+
+	return nil
+}
+
 // HandleInitiateMessage is called when we receive a network message from a peer that they wish to initiate a swap.
-func (inst *Instance) HandleInitiateMessage(msg *message.SendKeysMessage) (net.SwapState, common.Message, error) {
+func (inst *Instance) HandleInitiateMessage(
+	takerPeerID peer.ID,
+	msg *message.SendKeysMessage,
+) (net.SwapState, common.Message, error) {
 	inst.swapMu.Lock()
 	defer inst.swapMu.Unlock()
 
@@ -108,14 +201,29 @@ func (inst *Instance) HandleInitiateMessage(msg *message.SendKeysMessage) (net.S
 		return nil, nil, errOfferIDNotSet
 	}
 
-	// TODO: If this is not ETH, we need quick/easy access to the number
-	//       of token decimal places. Should it be in the OfferExtra struct?
-	err := coins.ValidatePositive("providedAmount", coins.NumEtherDecimals, msg.ProvidedAmount)
+	// swaps are removed from swapStates once they complete or abort, so it
+	// holds exactly the swaps that are still running
+	if inst.maxConcurrentSwaps > 0 && uint(len(inst.swapStates)) >= inst.maxConcurrentSwaps {
+		return nil, nil, errMakerAtCapacity{inst.maxConcurrentSwaps}
+	}
+
+	offer, offerExtra, err := inst.offerManager.GetOffer(msg.OfferID)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	offer, offerExtra, err := inst.offerManager.GetOffer(msg.OfferID)
+	// private offers are reported as missing to peers that can't see them
+	if !offerExtra.VisibleTo(takerPeerID) {
+		return nil, nil, errOfferNotVisible
+	}
+
+	if offer.Provides == coins.ProvidesETH {
+		return inst.handleTakeETHOffer(takerPeerID, offer, msg)
+	}
+
+	// TODO: If this is not ETH, we need quick/easy access to the number
+	//       of token decimal places. Should it be in the OfferExtra struct?
+	err = coins.ValidatePositive("providedAmount", coins.NumEtherDecimals, msg.ProvidedAmount)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -133,6 +241,21 @@ func (inst *Instance) HandleInitiateMessage(msg *message.SendKeysMessage) (net.S
 		return nil, nil, errAmountProvidedTooHigh{providedAmount, offer.MaxAmount}
 	}
 
+	// Completing the swap depends on both the ethereum node and the monero wallet,
+	// so reject the take now instead of failing after our XMR is locked.
+	if !inst.skipConnectivityCheck {
+		if err = inst.checkConnectivity(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// without relayers, we pay the gas of our claim, so make sure we can
+	// before our XMR is locked
+	err = checkClaimBalance(inst.backend.Ctx(), inst.backend.ETHClient(), offer.EthAsset, offerExtra.UseRelayer)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	providedPiconero := coins.MoneroToPiconero(providedAmount)
 
 	// check decimals if ERC20
@@ -147,7 +270,7 @@ func (inst *Instance) HandleInitiateMessage(msg *message.SendKeysMessage) (net.S
 		return nil, nil, err
 	}
 
-	state, err := inst.initiate(offer, offerExtra, providedPiconero, expectedAmount)
+	state, err := inst.initiate(takerPeerID, offer, offerExtra, providedPiconero, expectedAmount)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -159,3 +282,27 @@ func (inst *Instance) HandleInitiateMessage(msg *message.SendKeysMessage) (net.S
 	resp := state.SendKeysMessage()
 	return state, resp, nil
 }
+
+// checkConnectivity returns an error if our ethereum endpoint or monero wallet
+// is unreachable or not synced, as reported by the backend's health check.
+func (inst *Instance) checkConnectivity() error {
+	status, err := inst.backend.HealthCheck(inst.backend.Ctx())
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status.Healthy:
+		return nil
+	case !status.Ethereum.Reachable:
+		return fmt.Errorf("%w: ethereum endpoint is unreachable: %s", errConnectivityCheckFailed, status.Ethereum.Error)
+	case status.Ethereum.Syncing:
+		return fmt.Errorf("%w: ethereum node is syncing, %d blocks behind",
+			errConnectivityCheckFailed, status.Ethereum.SyncLag)
+	case !status.Monero.Reachable:
+		return fmt.Errorf("%w: monero wallet is unreachable: %s", errConnectivityCheckFailed, status.Monero.Error)
+	default:
+		return fmt.Errorf("%w: monero wallet is not synced, %d blocks behind",
+			errConnectivityCheckFailed, status.Monero.SyncLag)
+	}
+}
@@ -1,13 +1,19 @@
 package xmrmaker
 
 import (
+	"context"
+	"math/big"
 	"testing"
 
+	"github.com/golang/mock/gomock"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/require"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/net/message"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
 )
 
 func TestXMRMaker_HandleInitiateMessage(t *testing.T) {
@@ -21,7 +27,7 @@ func TestXMRMaker_HandleInitiateMessage(t *testing.T) {
 
 	b.net.(*MockP2pHost).EXPECT().Advertise()
 
-	_, err := b.MakeOffer(offer, false)
+	_, _, err := b.MakeOffer(offer, false)
 	require.NoError(t, err)
 
 	msg, _ := newTestXMRTakerSendKeysMessage(t)
@@ -29,8 +35,103 @@ func TestXMRMaker_HandleInitiateMessage(t *testing.T) {
 	msg.ProvidedAmount, err = offer.ExchangeRate.ToETH(offer.MinAmount)
 	require.NoError(t, err)
 
-	_, resp, err := b.HandleInitiateMessage(msg)
+	takerPeerID := peer.ID("taker")
+	_, resp, err := b.HandleInitiateMessage(takerPeerID, msg)
 	require.NoError(t, err)
 	require.Equal(t, message.SendKeysType, resp.Type())
 	require.NotNil(t, b.swapStates[offer.ID])
+	require.Equal(t, takerPeerID, b.swapStates[offer.ID].info.CounterpartyPeerID)
+}
+
+func TestXMRMaker_HandleInitiateMessage_atCapacity(t *testing.T) {
+	b, _ := newTestInstanceAndDB(t)
+	b.maxConcurrentSwaps = 1
+	b.swapStates[types.Hash{0x1}] = &swapState{}
+
+	msg, _ := newTestXMRTakerSendKeysMessage(t)
+	msg.OfferID = types.Hash{0x2}
+	msg.ProvidedAmount = coins.StrToDecimal("0.0001")
+
+	_, _, err := b.HandleInitiateMessage(peer.ID("taker"), msg)
+	require.ErrorIs(t, err, errMakerAtCapacity{1})
+
+	// a completed swap frees up capacity
+	delete(b.swapStates, types.Hash{0x1})
+	_, _, err = b.HandleInitiateMessage(peer.ID("taker"), msg)
+	require.NotErrorIs(t, err, errMakerAtCapacity{1})
+}
+
+func TestCheckClaimBalance(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	ec := extethclient.NewMockEthClient(ctrl)
+
+	gasPrice := big.NewInt(10_000_000_000) // 10 gwei
+	required := new(big.Int).Mul(gasPrice, big.NewInt(claimETHGas*claimGasPriceHeadroom))
+	ec.EXPECT().SuggestGasPrice(ctx).Return(gasPrice, nil).Times(2)
+
+	ec.EXPECT().Balance(ctx).Return(new(big.Int).Sub(required, big.NewInt(1)), nil)
+	err := checkClaimBalance(ctx, ec, types.EthAssetETH, false)
+	require.ErrorIs(t, err, pswap.ErrInsufficientETHBalance)
+
+	ec.EXPECT().Balance(ctx).Return(required, nil)
+	err = checkClaimBalance(ctx, ec, types.EthAssetETH, false)
+	require.NoError(t, err)
+
+	// swaps claimed with relayers don't need ETH
+	err = checkClaimBalance(ctx, ec, types.EthAssetETH, true)
+	require.NoError(t, err)
+}
+
+func TestXMRMaker_MakeOffer_collapseDuplicates(t *testing.T) {
+	b, db := newTestInstanceAndDB(t)
+	b.collapseDuplicateOffers = true
+
+	min := coins.StrToDecimal("0.001")
+	max := coins.StrToDecimal("0.002")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	offer := types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
+	db.EXPECT().PutOffer(offer)
+	b.net.(*MockP2pHost).EXPECT().Advertise()
+
+	made, _, err := b.MakeOffer(offer, false)
+	require.NoError(t, err)
+	require.Equal(t, offer.ID, made.ID)
+
+	// same terms, different nonce
+	duplicate := types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
+	require.NotEqual(t, offer.ID, duplicate.ID)
+	made, _, err = b.MakeOffer(duplicate, false)
+	require.NoError(t, err)
+	require.Equal(t, offer.ID, made.ID)
+	require.Equal(t, 1, b.offerManager.NumOffers())
+}
+
+func TestXMRMaker_MakeOffer_providesETH(t *testing.T) {
+	b, _ := newTestInstanceAndDB(t)
+
+	min := coins.StrToDecimal("0.001")
+	max := coins.StrToDecimal("0.002")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	offer := types.NewOffer(coins.ProvidesETH, min, max, rate, types.EthAssetETH)
+
+	// the test instance has no ETH offer handler
+	_, _, err := b.MakeOffer(offer, false)
+	require.ErrorIs(t, err, errETHOffersNotSupported)
+	require.Equal(t, 0, b.offerManager.NumOffers())
+}
+
+func TestXMRMaker_MakeOffer_invalidSwapFactory(t *testing.T) {
+	b, _ := newTestInstanceAndDB(t)
+
+	min := coins.StrToDecimal("0.001")
+	max := coins.StrToDecimal("0.002")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	offer := types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
+
+	// an account without code is not a SwapFactory
+	offer.SetSwapFactory(b.backend.ETHClient().Address())
+	_, _, err := b.MakeOffer(offer, false)
+	require.ErrorIs(t, err, errInvalidSwapFactory)
+	require.Equal(t, 0, b.offerManager.NumOffers())
 }
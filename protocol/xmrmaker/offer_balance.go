@@ -0,0 +1,81 @@
+package xmrmaker
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// maxOfferAmount returns the largest max amount that an offer can have with
+// the passed unlocked balance, or nil if offers aren't limited to a fraction
+// of our balance.
+func (b *Instance) maxOfferAmount(unlockedBalance *apd.Decimal) (*apd.Decimal, error) {
+	if b.maxOfferBalanceFraction == nil {
+		return nil, nil
+	}
+
+	allowed := new(apd.Decimal)
+	if _, err := coins.DecimalCtx().Mul(allowed, unlockedBalance, b.maxOfferBalanceFraction); err != nil {
+		return nil, err
+	}
+
+	_, _ = allowed.Reduce(allowed)
+	return allowed, nil
+}
+
+// checkOfferBalanceFraction checks that the offer's max amount is within the
+// configured fraction of our unlocked balance.
+func (b *Instance) checkOfferBalanceFraction(o *types.Offer, unlockedBalance *apd.Decimal) error {
+	allowed, err := b.maxOfferAmount(unlockedBalance)
+	if err != nil || allowed == nil {
+		return err
+	}
+
+	if o.MaxAmount.Cmp(allowed) > 0 {
+		return errOfferTooLargeForBalance{o.MaxAmount, allowed}
+	}
+
+	return nil
+}
+
+// revalidateOffers checks our offers against our unlocked balance every
+// interval, until ctx is cancelled.
+func (b *Instance) revalidateOffers(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.warnOnUnbackedOffers()
+		}
+	}
+}
+
+// warnOnUnbackedOffers logs a warning for each XMR offer whose max amount is
+// over the configured fraction of our current unlocked balance. The offers are
+// kept, as our unlocked balance drops for a while whenever we lock XMR.
+func (b *Instance) warnOnUnbackedOffers() {
+	balance, err := b.backend.XMRClient().GetBalance(0)
+	if err != nil {
+		log.Warnf("failed to get balance to revalidate offers: %s", err)
+		return
+	}
+
+	unlockedBalance := coins.NewPiconeroAmount(balance.UnlockedBalance).AsMonero()
+	for _, o := range b.offerManager.GetOffers() {
+		if o.Provides != coins.ProvidesXMR {
+			continue
+		}
+
+		if err = b.checkOfferBalanceFraction(o, unlockedBalance); err != nil {
+			log.Warnf("offer %s is no longer backed by our balance: %s", o.ID, err)
+		}
+	}
+}
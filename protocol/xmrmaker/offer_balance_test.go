@@ -0,0 +1,36 @@
+package xmrmaker
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func TestInstance_checkOfferBalanceFraction(t *testing.T) {
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	offer := types.NewOffer(
+		coins.ProvidesXMR,
+		coins.StrToDecimal("1"),
+		coins.StrToDecimal("4"),
+		rate,
+		types.EthAssetETH,
+	)
+	balance := coins.StrToDecimal("10")
+
+	// offers aren't limited without a fraction
+	inst := new(Instance)
+	require.NoError(t, inst.checkOfferBalanceFraction(offer, balance))
+
+	inst.maxOfferBalanceFraction = apd.New(5, -1)
+	require.NoError(t, inst.checkOfferBalanceFraction(offer, balance))
+
+	inst.maxOfferBalanceFraction = apd.New(25, -2)
+	err := inst.checkOfferBalanceFraction(offer, balance)
+	require.ErrorIs(t, err, pswap.ErrInsufficientXMRBalance)
+	require.EqualError(t, err, "maximum offer amount of 4 XMR is over the allowed maximum of 2.5 XMR for our balance")
+}
@@ -11,4 +11,8 @@ type Database interface {
 	GetOffer(id types.Hash) (*types.Offer, error)
 	GetAllOffers() ([]*types.Offer, error)
 	ClearAllOffers() error
+	PutOfferVisibility(id types.Hash, v *types.OfferVisibility) error
+	GetOfferVisibility(id types.Hash) (*types.OfferVisibility, error)
+	PutOfferMoneroLockPriority(id types.Hash, priority types.MoneroTxPriority) error
+	GetOfferMoneroLockPriority(id types.Hash) (types.MoneroTxPriority, error)
 }
@@ -0,0 +1,291 @@
+package offers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// defaultMaxFiatPriceAge is how old oracle prices can be before they are
+// considered stale, when the caller does not set FiatPricingConfig.MaxPriceAge.
+const defaultMaxFiatPriceAge = time.Hour * 24
+
+var (
+	errNilFiatPriceOracle      = errors.New("fiat price oracle must be set")
+	errFiatPricingNotStarted   = errors.New("fiat pricing is not enabled")
+	errFiatPriceNotPositive    = errors.New("oracle fiat prices must be positive")
+	errInvalidFiatReference    = errors.New("fiat reference min and max amounts must be positive, with min <= max")
+	errFiatCurrencyMismatch    = errors.New("fiat reference currency does not match the oracle's currency")
+	errFiatOfferAmountTooSmall = errors.New("fiat amount converts to less than one piconero")
+)
+
+type errStaleFiatPrices struct {
+	updatedAt time.Time
+	maxAge    time.Duration
+}
+
+func (e errStaleFiatPrices) Error() string {
+	return fmt.Sprintf("oracle prices last updated at %s are older than %s",
+		e.updatedAt.Format(time.RFC3339),
+		e.maxAge,
+	)
+}
+
+// FiatPriceOracle provides the prices of XMR and ETH in the fiat currency
+// returned by Currency. updatedAt is when the older of the two prices was last
+// updated.
+type FiatPriceOracle interface {
+	Currency() string
+	FiatPrices(ctx context.Context) (xmrPrice *apd.Decimal, ethPrice *apd.Decimal, updatedAt time.Time, err error)
+}
+
+// FiatPricingConfig configures the pricing mode where an offer's min and max
+// amounts are pegged to a fiat currency instead of XMR. Offers are still
+// advertised with concrete XMR amounts and exchange rate, which are computed
+// from the oracle's prices when an offer is published and on every interval
+// afterwards.
+//
+// If the oracle's prices are older than MaxPriceAge, they are considered
+// stale. New fiat offers can't be published with stale prices, and existing
+// fiat offers keep the terms from their last successful repricing until the
+// oracle recovers.
+type FiatPricingConfig struct {
+	Oracle FiatPriceOracle
+	// Spread is the fractional adjustment applied to the oracle exchange rate,
+	// in the same way as OraclePricingConfig.Spread.
+	Spread      *apd.Decimal
+	MaxPriceAge time.Duration
+	Interval    time.Duration
+}
+
+func (c *FiatPricingConfig) validate() error {
+	if c.Oracle == nil {
+		return errNilFiatPriceOracle
+	}
+	return validateSpread(c.Spread)
+}
+
+func (c *FiatPricingConfig) maxPriceAge() time.Duration {
+	if c.MaxPriceAge == 0 {
+		return defaultMaxFiatPriceAge
+	}
+	return c.MaxPriceAge
+}
+
+// fiatQuote holds the XMR fiat price and exchange rate used to compute the
+// concrete terms of fiat offers.
+type fiatQuote struct {
+	xmrPrice *apd.Decimal
+	rate     *coins.ExchangeRate
+}
+
+// quote returns a quote from fresh oracle prices, or an errStaleFiatPrices
+// error if the oracle's prices are older than the configured maximum age.
+func (c *FiatPricingConfig) quote(ctx context.Context) (*fiatQuote, error) {
+	xmrPrice, ethPrice, updatedAt, err := c.Oracle.FiatPrices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oracle fiat prices: %w", err)
+	}
+
+	if time.Since(updatedAt) > c.maxPriceAge() {
+		return nil, errStaleFiatPrices{updatedAt, c.maxPriceAge()}
+	}
+
+	if xmrPrice.Sign() <= 0 || ethPrice.Sign() <= 0 {
+		return nil, errFiatPriceNotPositive
+	}
+
+	oracleRate, err := coins.CalcExchangeRate(xmrPrice, ethPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := applySpread(oracleRate, c.Spread)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fiatQuote{xmrPrice: xmrPrice, rate: rate}, nil
+}
+
+// xmrAmount converts the passed fiat amount to XMR, rounded to piconeros.
+func (q *fiatQuote) xmrAmount(fiatAmount *apd.Decimal) (*apd.Decimal, error) {
+	amount := new(apd.Decimal)
+	ctx := coins.DecimalCtx()
+	if _, err := ctx.Quo(amount, fiatAmount, q.xmrPrice); err != nil {
+		return nil, err
+	}
+	if _, err := ctx.Quantize(amount, amount, -coins.NumMoneroDecimals); err != nil {
+		return nil, err
+	}
+	_, _ = amount.Reduce(amount)
+
+	if amount.Sign() <= 0 {
+		return nil, errFiatOfferAmountTooSmall
+	}
+
+	return amount, nil
+}
+
+// xmrAmounts returns the concrete XMR min and max amounts of the passed fiat
+// reference.
+func (q *fiatQuote) xmrAmounts(ref *types.FiatReference) (*apd.Decimal, *apd.Decimal, error) {
+	minAmount, err := q.xmrAmount(ref.MinAmount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maxAmount, err := q.xmrAmount(ref.MaxAmount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return minAmount, maxAmount, nil
+}
+
+// offer returns a new offer with concrete terms for the passed fiat reference.
+func (q *fiatQuote) offer(ref *types.FiatReference, ethAsset types.EthAsset) (*types.Offer, error) {
+	minAmount, maxAmount, err := q.xmrAmounts(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewOffer(
+		coins.ProvidesXMR,
+		minAmount,
+		maxAmount,
+		coins.ToExchangeRate(new(apd.Decimal).Set(q.rate.Decimal())),
+		ethAsset,
+	), nil
+}
+
+func validateFiatReference(ref *types.FiatReference, currency string) error {
+	if ref == nil || ref.MinAmount == nil || ref.MaxAmount == nil {
+		return errInvalidFiatReference
+	}
+	if ref.MinAmount.Sign() <= 0 || ref.MinAmount.Cmp(ref.MaxAmount) > 0 {
+		return errInvalidFiatReference
+	}
+	if ref.Currency != currency {
+		return errFiatCurrencyMismatch
+	}
+	return nil
+}
+
+// StartFiatPricing enables offers pegged to a fiat currency. Pegged offers are
+// repriced immediately and then on every interval until the passed context is
+// cancelled. Offers whose concrete terms are unchanged are left as is.
+func (m *Manager) StartFiatPricing(ctx context.Context, conf *FiatPricingConfig) error {
+	if err := conf.validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.fiatPricing = conf
+	m.mu.Unlock()
+
+	go repriceEvery(ctx, conf.Interval, func() error {
+		return m.RepriceFiatOffers(ctx)
+	})
+
+	return nil
+}
+
+// NewFiatOffer returns a new offer whose concrete XMR amounts and exchange rate
+// are computed from the current oracle prices for the passed fiat reference.
+// The offer is not added to the manager; once it is, SetFiatReference must be
+// called so the offer is repriced as the oracle prices change.
+func (m *Manager) NewFiatOffer(
+	ctx context.Context,
+	ref *types.FiatReference,
+	ethAsset types.EthAsset,
+) (*types.Offer, error) {
+	m.mu.RLock()
+	conf := m.fiatPricing
+	m.mu.RUnlock()
+
+	if conf == nil {
+		return nil, errFiatPricingNotStarted
+	}
+
+	if err := validateFiatReference(ref, conf.Oracle.Currency()); err != nil {
+		return nil, err
+	}
+
+	q, err := conf.quote(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.offer(ref, ethAsset)
+}
+
+// SetFiatReference pegs the managed offer with the passed ID to the passed
+// fiat reference. The reference is only kept in memory, so offers loaded from
+// the database on startup are no longer pegged and keep their last terms.
+func (m *Manager) SetFiatReference(id types.Hash, ref *types.FiatReference) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	o, has := m.offers[id]
+	if !has {
+		return errOfferDoesNotExist
+	}
+
+	o.extra.FiatReference = ref
+	return nil
+}
+
+// RepriceFiatOffers replaces every offer pegged to a fiat currency whose
+// concrete terms differ from those computed from the current oracle prices.
+// Like RepriceOffers, each replacement has a new ID. If the oracle's prices
+// are stale, no offers are changed and an error is returned.
+func (m *Manager) RepriceFiatOffers(ctx context.Context) error {
+	m.mu.RLock()
+	conf := m.fiatPricing
+	m.mu.RUnlock()
+
+	if conf == nil {
+		return errFiatPricingNotStarted
+	}
+
+	q, err := conf.quote(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, o := range m.offers {
+		ref := o.extra.FiatReference
+		if ref == nil {
+			continue
+		}
+
+		minAmount, maxAmount, err := q.xmrAmounts(ref)
+		if err != nil {
+			return err
+		}
+
+		newOffer := repricedOffer(o.offer, minAmount, maxAmount, q.rate)
+		if sameTerms(o.offer, newOffer) {
+			continue
+		}
+
+		if err = m.replaceOffer(id, newOffer); err != nil {
+			return err
+		}
+
+		log.Infof("repriced fiat offer %s to %s-%s XMR at %s (new offer ID %s)",
+			id, newOffer.MinAmount, newOffer.MaxAmount, newOffer.ExchangeRate, newOffer.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package offers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+type mockFiatOracle struct {
+	xmrPrice  *apd.Decimal
+	ethPrice  *apd.Decimal
+	updatedAt time.Time
+}
+
+func (o *mockFiatOracle) Currency() string {
+	return "USD"
+}
+
+func (o *mockFiatOracle) FiatPrices(_ context.Context) (*apd.Decimal, *apd.Decimal, time.Time, error) {
+	return o.xmrPrice, o.ethPrice, o.updatedAt, nil
+}
+
+func newTestFiatManager(t *testing.T, oracle *mockFiatOracle) (*Manager, *MockDatabase) {
+	ctrl := gomock.NewController(t)
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllOffers()
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	// set the config directly instead of calling StartFiatPricing, so no
+	// background repricing races with the test
+	mgr.fiatPricing = &FiatPricingConfig{Oracle: oracle}
+
+	return mgr, db
+}
+
+func Test_Manager_NewFiatOffer(t *testing.T) {
+	oracle := &mockFiatOracle{
+		xmrPrice:  apd.New(150, 0),
+		ethPrice:  apd.New(1500, 0),
+		updatedAt: time.Now(),
+	}
+	mgr, _ := newTestFiatManager(t, oracle)
+
+	ref := &types.FiatReference{
+		Currency:  "USD",
+		MinAmount: apd.New(15, 0),
+		MaxAmount: apd.New(300, 0),
+	}
+	offer, err := mgr.NewFiatOffer(context.Background(), ref, types.EthAssetETH)
+	require.NoError(t, err)
+	require.Equal(t, "0.1", offer.MinAmount.String())
+	require.Equal(t, "2", offer.MaxAmount.String())
+	require.Equal(t, "0.1", offer.ExchangeRate.String())
+
+	ref.Currency = "EUR"
+	_, err = mgr.NewFiatOffer(context.Background(), ref, types.EthAssetETH)
+	require.ErrorIs(t, err, errFiatCurrencyMismatch)
+
+	ref.Currency = "USD"
+	oracle.updatedAt = time.Now().Add(-2 * defaultMaxFiatPriceAge)
+	_, err = mgr.NewFiatOffer(context.Background(), ref, types.EthAssetETH)
+	require.ErrorAs(t, err, new(errStaleFiatPrices))
+}
+
+func Test_Manager_StartFiatPricing_noOracle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllOffers()
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	err = mgr.StartFiatPricing(context.Background(), &FiatPricingConfig{})
+	require.ErrorIs(t, err, errNilFiatPriceOracle)
+}
+
+func Test_Manager_NewFiatOffer_notStarted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllOffers()
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	_, err = mgr.NewFiatOffer(context.Background(), &types.FiatReference{}, types.EthAssetETH)
+	require.ErrorIs(t, err, errFiatPricingNotStarted)
+}
+
+func Test_Manager_RepriceFiatOffers(t *testing.T) {
+	oracle := &mockFiatOracle{
+		xmrPrice:  apd.New(150, 0),
+		ethPrice:  apd.New(1500, 0),
+		updatedAt: time.Now(),
+	}
+	mgr, db := newTestFiatManager(t, oracle)
+
+	ref := &types.FiatReference{
+		Currency:  "USD",
+		MinAmount: apd.New(15, 0),
+		MaxAmount: apd.New(300, 0),
+	}
+	fiatOffer, err := mgr.NewFiatOffer(context.Background(), ref, types.EthAssetETH)
+	require.NoError(t, err)
+	db.EXPECT().PutOffer(fiatOffer)
+	_, err = mgr.AddOffer(fiatOffer, false)
+	require.NoError(t, err)
+	require.NoError(t, mgr.SetFiatReference(fiatOffer.ID, ref))
+
+	xmrOffer := types.NewOffer(
+		coins.ProvidesXMR,
+		apd.New(1, 0),
+		apd.New(2, 0),
+		coins.ToExchangeRate(apd.New(5, -2)),
+		types.EthAssetETH,
+	)
+	db.EXPECT().PutOffer(xmrOffer)
+	_, err = mgr.AddOffer(xmrOffer, false)
+	require.NoError(t, err)
+
+	// unchanged prices leave the fiat offer as is
+	require.NoError(t, mgr.RepriceFiatOffers(context.Background()))
+	require.Equal(t, 2, mgr.NumOffers())
+	_, _, err = mgr.GetOffer(fiatOffer.ID)
+	require.NoError(t, err)
+
+	// stale prices leave the fiat offer at its last terms
+	oracle.xmrPrice = apd.New(300, 0)
+	oracle.updatedAt = time.Now().Add(-2 * defaultMaxFiatPriceAge)
+	err = mgr.RepriceFiatOffers(context.Background())
+	require.ErrorAs(t, err, new(errStaleFiatPrices))
+	_, _, err = mgr.GetOffer(fiatOffer.ID)
+	require.NoError(t, err)
+
+	// fresh prices reprice only the fiat offer
+	oracle.updatedAt = time.Now()
+	db.EXPECT().PutOffer(gomock.Any())
+	db.EXPECT().DeleteOffer(fiatOffer.ID)
+	require.NoError(t, mgr.RepriceFiatOffers(context.Background()))
+
+	_, _, err = mgr.GetOffer(xmrOffer.ID)
+	require.NoError(t, err)
+	_, _, err = mgr.GetOffer(fiatOffer.ID)
+	require.ErrorIs(t, err, errOfferDoesNotExist)
+
+	for _, o := range mgr.GetOffers() {
+		if o.ID == xmrOffer.ID {
+			continue
+		}
+		require.Equal(t, "0.05", o.MinAmount.String())
+		require.Equal(t, "1", o.MaxAmount.String())
+		require.Equal(t, "0.2", o.ExchangeRate.String())
+
+		_, extra, err := mgr.GetOffer(o.ID) //nolint:govet
+		require.NoError(t, err)
+		require.Equal(t, ref, extra.FiatReference)
+	}
+
+	// oracle-rate repricing doesn't touch the fiat offer
+	db.EXPECT().PutOffer(gomock.Any())
+	db.EXPECT().DeleteOffer(xmrOffer.ID)
+	require.NoError(t, mgr.RepriceOffers(coins.ToExchangeRate(apd.New(6, -2))))
+}
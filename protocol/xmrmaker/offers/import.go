@@ -0,0 +1,192 @@
+package offers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+var errNoOffersToImport = errors.New("no offers to import")
+
+// OfferSpec is the definition of an offer in an imported offer book. Its
+// fields match those of the net_makeOffer RPC request.
+type OfferSpec struct {
+	MinAmount          *apd.Decimal           `json:"minAmount" validate:"required"`
+	MaxAmount          *apd.Decimal           `json:"maxAmount" validate:"required"`
+	ExchangeRate       *coins.ExchangeRate    `json:"exchangeRate" validate:"required"`
+	EthAsset           types.EthAsset         `json:"ethAsset,omitempty"`
+	Provides           coins.ProvidesCoin     `json:"provides,omitempty"` // defaults to XMR
+	UseRelayer         bool                   `json:"useRelayer,omitempty"`
+	MoneroLockPriority types.MoneroTxPriority `json:"moneroLockPriority,omitempty"`
+	SwapFactory        *ethcommon.Address     `json:"swapFactory,omitempty"`
+	SwapTimeout        uint64                 `json:"swapTimeout,omitempty"` // in seconds
+}
+
+// ImportError is the error of a single offer of an imported offer book.
+type ImportError struct {
+	Index int // index of the offer in the imported array
+	Err   error
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("offer %d: %s", e.Index, e.Err)
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ImportErrors is returned by ImportOffers when any of the imported offers
+// are invalid, in which case none of them are added.
+type ImportErrors []*ImportError
+
+func (e ImportErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d invalid offers, none were imported: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// OfferCheck is called by ImportOffers with each imported offer, to check
+// what the manager can't, like whether our balance covers the offer.
+type OfferCheck func(offer *types.Offer, useRelayer bool) error
+
+// SetOfferCheck sets the check of imported offers.
+func (m *Manager) SetOfferCheck(check OfferCheck) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.offerCheck = check
+}
+
+// ImportOffers adds the offers of an offer book, which is a JSON array of
+// OfferSpec objects, returning the IDs of the offers in the same order.
+// Either all of the offers are added, or none are. If any offers are invalid,
+// the returned error is an ImportErrors with the error of each of them.
+// Offers with the same terms as a current offer aren't added again, and the ID
+// of the current offer is returned instead, so the same book can be imported
+// every time swapd starts.
+func (m *Manager) ImportOffers(data []byte) ([]types.Hash, error) {
+	var rawSpecs []json.RawMessage
+	if err := json.Unmarshal(data, &rawSpecs); err != nil {
+		return nil, fmt.Errorf("failed to parse offer book: %w", err)
+	}
+
+	if len(rawSpecs) == 0 {
+		return nil, errNoOffersToImport
+	}
+
+	// offers are checked without holding the lock, as checks can be slow
+	m.mu.RLock()
+	check := m.offerCheck
+	m.mu.RUnlock()
+
+	var importErrs ImportErrors
+	offers := make([]*offerWithExtra, len(rawSpecs))
+	for i, raw := range rawSpecs {
+		o, err := parseOfferSpec(raw, check)
+		if err != nil {
+			importErrs = append(importErrs, &ImportError{Index: i, Err: err})
+			continue
+		}
+		offers[i] = o
+	}
+
+	if len(importErrs) > 0 {
+		return nil, importErrs
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]types.Hash, len(offers))
+	var added []types.Hash
+	for i, o := range offers {
+		if existing := m.findEquivalentOffer(o.offer, o.extra.UseRelayer); existing != nil {
+			log.Infof("imported offer %d has the same terms as existing offer %s, not adding it", i, existing.offer.ID)
+			ids[i] = existing.offer.ID
+			continue
+		}
+
+		if o.extra.MoneroLockPriority != types.MoneroPriorityDefault {
+			err := m.db.PutOfferMoneroLockPriority(o.offer.ID, o.extra.MoneroLockPriority)
+			if err != nil {
+				m.removeOffers(added)
+				return nil, fmt.Errorf("failed to store imported offer %d: %w", i, err)
+			}
+		}
+
+		if err := m.db.PutOffer(o.offer); err != nil {
+			m.removeOffers(added)
+			return nil, fmt.Errorf("failed to store imported offer %d: %w", i, err)
+		}
+
+		m.offers[o.offer.ID] = o
+		added = append(added, o.offer.ID)
+		ids[i] = o.offer.ID
+	}
+
+	log.Infof("imported %d offers", len(added))
+	return ids, nil
+}
+
+// parseOfferSpec returns the offer defined by the passed spec, after
+// validating it and passing it to the check, if there is one.
+func parseOfferSpec(raw json.RawMessage, check OfferCheck) (*offerWithExtra, error) {
+	spec := new(OfferSpec)
+	if err := vjson.UnmarshalStruct(raw, spec); err != nil {
+		return nil, err
+	}
+
+	provides := spec.Provides
+	if provides == "" {
+		provides = coins.ProvidesXMR
+	}
+
+	offer := types.NewOffer(provides, spec.MinAmount, spec.MaxAmount, spec.ExchangeRate, spec.EthAsset)
+	if spec.SwapFactory != nil {
+		offer.SetSwapFactory(*spec.SwapFactory)
+	}
+	if spec.SwapTimeout != 0 {
+		offer.SetSwapTimeout(time.Duration(spec.SwapTimeout) * time.Second)
+	}
+
+	if err := offer.Validate(); err != nil {
+		return nil, err
+	}
+
+	if check != nil {
+		if err := check(offer, spec.UseRelayer); err != nil {
+			return nil, err
+		}
+	}
+
+	return &offerWithExtra{
+		offer: offer,
+		extra: &types.OfferExtra{
+			StatusCh:           make(chan types.Status, statusChSize),
+			UseRelayer:         spec.UseRelayer,
+			MoneroLockPriority: spec.MoneroLockPriority,
+		},
+	}, nil
+}
+
+// removeOffers removes offers that were just added, when importing fails
+// part way. The caller must hold the manager's lock.
+func (m *Manager) removeOffers(ids []types.Hash) {
+	for _, id := range ids {
+		delete(m.offers, id)
+		if err := m.db.DeleteOffer(id); err != nil {
+			log.Warnf("failed to remove imported offer %s from db: %s", id, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package offers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+const testOfferBook = `[
+	{"minAmount": "1", "maxAmount": "10", "exchangeRate": "0.1"},
+	{"minAmount": "0.5", "maxAmount": "2", "exchangeRate": "0.09", "swapTimeout": 3600, "useRelayer": true}
+]`
+
+func TestManager_ImportOffers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+	db.EXPECT().GetAllOffers()
+
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	var checked int
+	mgr.SetOfferCheck(func(_ *types.Offer, _ bool) error {
+		checked++
+		return nil
+	})
+
+	db.EXPECT().PutOffer(gomock.Any()).Times(2)
+	ids, err := mgr.ImportOffers([]byte(testOfferBook))
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	require.Equal(t, 2, checked)
+	require.Equal(t, 2, mgr.NumOffers())
+
+	offer, extra, err := mgr.GetOffer(ids[1])
+	require.NoError(t, err)
+	require.Equal(t, uint64(3600), offer.SwapTimeout)
+	require.True(t, extra.UseRelayer)
+
+	// importing the same book again doesn't add duplicate offers
+	reimportedIDs, err := mgr.ImportOffers([]byte(testOfferBook))
+	require.NoError(t, err)
+	require.Equal(t, ids, reimportedIDs)
+	require.Equal(t, 2, mgr.NumOffers())
+}
+
+func TestManager_ImportOffers_invalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+	db.EXPECT().GetAllOffers()
+
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	errNoFunds := errors.New("balance too low")
+	mgr.SetOfferCheck(func(o *types.Offer, _ bool) error {
+		if o.MaxAmount.Text('f') == "100" {
+			return errNoFunds
+		}
+		return nil
+	})
+
+	book := `[
+		{"minAmount": "1", "maxAmount": "10", "exchangeRate": "0.1"},
+		{"minAmount": "10", "maxAmount": "1", "exchangeRate": "0.1"},
+		{"minAmount": "1", "maxAmount": "100", "exchangeRate": "0.1"},
+		{"minAmount": "1", "exchangeRate": "0.1"}
+	]`
+
+	// no offers are added if any are invalid, so PutOffer isn't expected
+	_, err = mgr.ImportOffers([]byte(book))
+	var importErrs ImportErrors
+	require.ErrorAs(t, err, &importErrs)
+	require.Len(t, importErrs, 3)
+	require.Equal(t, 1, importErrs[0].Index)
+	require.Equal(t, 2, importErrs[1].Index)
+	require.ErrorIs(t, importErrs[1], errNoFunds)
+	require.Equal(t, 3, importErrs[2].Index)
+	require.Equal(t, 0, mgr.NumOffers())
+
+	_, err = mgr.ImportOffers([]byte(`[]`))
+	require.ErrorIs(t, err, errNoOffersToImport)
+
+	_, err = mgr.ImportOffers([]byte(`{}`))
+	require.Error(t, err)
+}
+
+func TestManager_ImportOffers_dbFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+	db.EXPECT().GetAllOffers()
+
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	// the first offer is removed again when storing the second one fails
+	var firstID types.Hash
+	gomock.InOrder(
+		db.EXPECT().PutOffer(gomock.Any()).Do(func(o *types.Offer) { firstID = o.ID }),
+		db.EXPECT().PutOffer(gomock.Any()).Return(errors.New("disk full")),
+		db.EXPECT().DeleteOffer(gomock.Any()).Do(func(id types.Hash) { require.Equal(t, firstID, id) }),
+	)
+
+	_, err = mgr.ImportOffers([]byte(testOfferBook))
+	require.ErrorContains(t, err, "disk full")
+	require.Equal(t, 0, mgr.NumOffers())
+}
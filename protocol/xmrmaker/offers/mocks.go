@@ -94,6 +94,36 @@ func (mr *MockDatabaseMockRecorder) GetOffer(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOffer", reflect.TypeOf((*MockDatabase)(nil).GetOffer), arg0)
 }
 
+// GetOfferMoneroLockPriority mocks base method.
+func (m *MockDatabase) GetOfferMoneroLockPriority(arg0 common.Hash) (types.MoneroTxPriority, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOfferMoneroLockPriority", arg0)
+	ret0, _ := ret[0].(types.MoneroTxPriority)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOfferMoneroLockPriority indicates an expected call of GetOfferMoneroLockPriority.
+func (mr *MockDatabaseMockRecorder) GetOfferMoneroLockPriority(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOfferMoneroLockPriority", reflect.TypeOf((*MockDatabase)(nil).GetOfferMoneroLockPriority), arg0)
+}
+
+// GetOfferVisibility mocks base method.
+func (m *MockDatabase) GetOfferVisibility(arg0 common.Hash) (*types.OfferVisibility, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOfferVisibility", arg0)
+	ret0, _ := ret[0].(*types.OfferVisibility)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOfferVisibility indicates an expected call of GetOfferVisibility.
+func (mr *MockDatabaseMockRecorder) GetOfferVisibility(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOfferVisibility", reflect.TypeOf((*MockDatabase)(nil).GetOfferVisibility), arg0)
+}
+
 // PutOffer mocks base method.
 func (m *MockDatabase) PutOffer(arg0 *types.Offer) error {
 	m.ctrl.T.Helper()
@@ -107,3 +137,31 @@ func (mr *MockDatabaseMockRecorder) PutOffer(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutOffer", reflect.TypeOf((*MockDatabase)(nil).PutOffer), arg0)
 }
+
+// PutOfferMoneroLockPriority mocks base method.
+func (m *MockDatabase) PutOfferMoneroLockPriority(arg0 common.Hash, arg1 types.MoneroTxPriority) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutOfferMoneroLockPriority", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutOfferMoneroLockPriority indicates an expected call of PutOfferMoneroLockPriority.
+func (mr *MockDatabaseMockRecorder) PutOfferMoneroLockPriority(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutOfferMoneroLockPriority", reflect.TypeOf((*MockDatabase)(nil).PutOfferMoneroLockPriority), arg0, arg1)
+}
+
+// PutOfferVisibility mocks base method.
+func (m *MockDatabase) PutOfferVisibility(arg0 common.Hash, arg1 *types.OfferVisibility) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutOfferVisibility", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutOfferVisibility indicates an expected call of PutOfferVisibility.
+func (mr *MockDatabaseMockRecorder) PutOfferVisibility(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutOfferVisibility", reflect.TypeOf((*MockDatabase)(nil).PutOfferVisibility), arg0, arg1)
+}
@@ -3,9 +3,12 @@ package offers
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/ChainSafe/chaindb"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/common/types"
 
@@ -18,6 +21,7 @@ var (
 	log = logging.Logger("offers")
 
 	errOfferDoesNotExist = errors.New("offer with given ID does not exist")
+	errOfferIDCollision  = errors.New("a different offer with the same ID already exists")
 )
 
 // Manager synchronises access to the offers map.
@@ -26,6 +30,17 @@ type Manager struct {
 	offers  map[types.Hash]*offerWithExtra
 	dataDir string
 	db      Database
+
+	// taken holds the offers of in-flight swaps. They are not advertised, but
+	// stay in the database until their swap completes.
+	taken map[types.Hash]*offerWithExtra
+
+	// fiatPricing is set by StartFiatPricing and used to price new offers
+	// pegged to a fiat currency
+	fiatPricing *FiatPricingConfig
+
+	// offerCheck is set by SetOfferCheck and used to check imported offers
+	offerCheck OfferCheck
 }
 
 type offerWithExtra struct {
@@ -46,8 +61,22 @@ func NewManager(dataDir string, db Database) (*Manager, error) {
 	offers := make(map[types.Hash]*offerWithExtra)
 
 	for _, offer := range savedOffers {
+		var visibility *types.OfferVisibility
+		visibility, err = db.GetOfferVisibility(offer.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		var lockPriority types.MoneroTxPriority
+		lockPriority, err = db.GetOfferMoneroLockPriority(offer.ID)
+		if err != nil {
+			return nil, err
+		}
+
 		extra := &types.OfferExtra{
-			StatusCh: make(chan types.Status, statusChSize),
+			StatusCh:           make(chan types.Status, statusChSize),
+			MoneroLockPriority: lockPriority,
+			OfferVisibility:    *visibility,
 		}
 
 		offers[offer.ID] = &offerWithExtra{
@@ -61,6 +90,7 @@ func NewManager(dataDir string, db Database) (*Manager, error) {
 	return &Manager{
 		offers:  offers,
 		dataDir: dataDir,
+		taken:   make(map[types.Hash]*offerWithExtra),
 		db:      db,
 	}, nil
 }
@@ -79,28 +109,73 @@ func (m *Manager) GetOffer(id types.Hash) (*types.Offer, *types.OfferExtra, erro
 	return offer.offer, offer.extra, nil
 }
 
-// AddOffer adds a new offer to the manager and returns its OffersExtra data
+// AddOffer adds a new offer to the manager and returns its OffersExtra data.
+// Adding an offer that was already added returns its existing OfferExtra, but
+// adding a different offer with the ID of an existing one is an error.
 func (m *Manager) AddOffer(
 	offer *types.Offer,
 	useRelayer bool,
 ) (*types.OfferExtra, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.addOffer(offer, useRelayer, nil)
+}
+
+// AddPrivateOffer is the same as AddOffer, but the offer is only served to,
+// and can only be taken by, the passed peers.
+func (m *Manager) AddPrivateOffer(
+	offer *types.Offer,
+	useRelayer bool,
+	allowedPeers []peer.ID,
+) (*types.OfferExtra, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.addOffer(offer, useRelayer, &types.OfferVisibility{
+		Private:      true,
+		AllowedPeers: allowedPeers,
+	})
+}
 
+// addOffer is the same as AddOffer, but the caller must hold the manager's
+// lock. A nil visibility makes the offer public.
+func (m *Manager) addOffer(
+	offer *types.Offer,
+	useRelayer bool,
+	visibility *types.OfferVisibility,
+) (*types.OfferExtra, error) {
 	id := offer.ID
-	oe, has := m.offers[id]
-	if has {
+	if oe, has := m.offers[id]; has {
+		if !sameOffer(oe.offer, offer) {
+			return nil, fmt.Errorf("%w: %s", errOfferIDCollision, id)
+		}
 		return oe.extra, nil
 	}
 
+	if oe, has := m.taken[id]; has && !sameOffer(oe.offer, offer) {
+		return nil, fmt.Errorf("%w: %s", errOfferIDCollision, id)
+	}
+
+	m.warnOnNonceReuse(offer)
+
+	// the visibility is stored first, so that a private offer is never
+	// loaded as a public one
+	if visibility != nil {
+		if err := m.db.PutOfferVisibility(id, visibility); err != nil {
+			return nil, err
+		}
+	} else {
+		visibility = new(types.OfferVisibility)
+	}
+
 	err := m.db.PutOffer(offer)
 	if err != nil {
 		return nil, err
 	}
 
 	extra := &types.OfferExtra{
-		StatusCh:   make(chan types.Status, statusChSize),
-		UseRelayer: useRelayer,
+		StatusCh:        make(chan types.Status, statusChSize),
+		UseRelayer:      useRelayer,
+		OfferVisibility: *visibility,
 	}
 
 	m.offers[id] = &offerWithExtra{
@@ -111,9 +186,83 @@ func (m *Manager) AddOffer(
 	return extra, nil
 }
 
+// sameOffer returns whether the offers have the same content, which is all of
+// the fields that their IDs are hashed from.
+func sameOffer(a, b *types.Offer) bool {
+	return a.HashPreimage() == b.HashPreimage()
+}
+
+// warnOnNonceReuse logs a warning if a current offer has the same nonce as the
+// passed one. NewOffer picks random nonces, so reuse hints at a buggy client.
+// The caller must hold the manager's lock.
+func (m *Manager) warnOnNonceReuse(offer *types.Offer) {
+	for _, offers := range []map[types.Hash]*offerWithExtra{m.offers, m.taken} {
+		for id, oe := range offers {
+			if id != offer.ID && oe.offer.Nonce == offer.Nonce {
+				log.Warnf("offer %s reuses the nonce of offer %s", offer.ID, id)
+			}
+		}
+	}
+}
+
+// FindEquivalentOffer returns a current offer, and its OfferExtra, with the same
+// terms as the passed offer but a different ID. Offers are equivalent if they
+// provide the same coin for the same ETH asset with the same min, max and
+// exchange rate, and agree on using a relayer. Private offers are never
+// equivalent to other offers. Nil for both values is returned if there is no
+// equivalent offer.
+func (m *Manager) FindEquivalentOffer(
+	offer *types.Offer,
+	useRelayer bool,
+) (*types.Offer, *types.OfferExtra) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	o := m.findEquivalentOffer(offer, useRelayer)
+	if o == nil {
+		return nil, nil
+	}
+	return o.offer, o.extra
+}
+
+// findEquivalentOffer is the same as FindEquivalentOffer, but the caller must
+// hold the manager's lock.
+func (m *Manager) findEquivalentOffer(offer *types.Offer, useRelayer bool) *offerWithExtra {
+	for id, o := range m.offers {
+		if id == offer.ID || o.extra.UseRelayer != useRelayer || o.extra.Private {
+			continue
+		}
+
+		if sameTerms(o.offer, offer) {
+			return o
+		}
+	}
+
+	return nil
+}
+
+// sameTerms returns whether the two offers only differ by their ID and nonce.
+func sameTerms(a, b *types.Offer) bool {
+	return a.Provides == b.Provides &&
+		a.EthAsset == b.EthAsset &&
+		a.MinAmount.Cmp(b.MinAmount) == 0 &&
+		a.MaxAmount.Cmp(b.MaxAmount) == 0 &&
+		a.ExchangeRate.Decimal().Cmp(b.ExchangeRate.Decimal()) == 0 &&
+		sameSwapFactory(a.SwapFactory, b.SwapFactory) &&
+		a.SwapTimeout == b.SwapTimeout
+}
+
+func sameSwapFactory(a, b *ethcommon.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 // TakeOffer returns any offer with the matching id and removes the offer from the cache,
 // but leaves it in the database (unlike the Clear/DeleteOffer methods.)
 // Nil for both values is returned when the passed offer id is not currently managed.
+// The offer is held as taken until ReleaseOffer or DeleteOffer is called.
 func (m *Manager) TakeOffer(id types.Hash) (*types.Offer, *types.OfferExtra, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -124,9 +273,97 @@ func (m *Manager) TakeOffer(id types.Hash) (*types.Offer, *types.OfferExtra, err
 	}
 
 	delete(m.offers, id)
+	m.taken[id] = offer
 	return offer.offer, offer.extra, nil
 }
 
+// ReleaseOffer is called when the swap of an offer doesn't complete
+// successfully. The offer is re-added, unless it was cancelled while taken, in
+// which case it is deleted.
+func (m *Manager) ReleaseOffer(offer *types.Offer, useRelayer bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := offer.ID
+	taken, has := m.taken[id]
+	delete(m.taken, id)
+
+	if has && taken.extra.Cancelled {
+		log.Infof("deleting offer %s, which was cancelled during its swap", id)
+		err := m.db.DeleteOffer(id)
+		if err != nil && !errors.Is(chaindb.ErrKeyNotFound, err) {
+			return err
+		}
+		return nil
+	}
+
+	if has {
+		// keep the offer's fiat reference, lock priority and visibility, but
+		// not the status channel used by the swap
+		if err := m.db.PutOffer(offer); err != nil {
+			return err
+		}
+		m.offers[id] = &offerWithExtra{
+			offer: offer,
+			extra: &types.OfferExtra{
+				StatusCh:           make(chan types.Status, statusChSize),
+				UseRelayer:         useRelayer,
+				FiatReference:      taken.extra.FiatReference,
+				MoneroLockPriority: taken.extra.MoneroLockPriority,
+				OfferVisibility:    taken.extra.OfferVisibility,
+			},
+		}
+	} else if _, err := m.addOffer(offer, useRelayer, nil); err != nil {
+		return err
+	}
+
+	log.Debugf("re-added offer %s", id)
+	return nil
+}
+
+// SetMoneroLockPriority sets the priority of the XMR lock transfer for swaps on
+// the managed offer with the passed ID. The default priority uses the maker's
+// configured priority.
+func (m *Manager) SetMoneroLockPriority(id types.Hash, priority types.MoneroTxPriority) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	o, has := m.offers[id]
+	if !has {
+		return errOfferDoesNotExist
+	}
+
+	if err := m.db.PutOfferMoneroLockPriority(id, priority); err != nil {
+		return err
+	}
+
+	o.extra.MoneroLockPriority = priority
+	return nil
+}
+
+// IsCancelled returns whether the offer is taken by an in-flight swap and was
+// cancelled since.
+func (m *Manager) IsCancelled(id types.Hash) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	offer, has := m.taken[id]
+	return has && offer.extra.Cancelled
+}
+
+// cancelTaken marks the offer as cancelled if it's taken, returning whether it
+// was. Cancelled offers stay in the database, so their swaps can be recovered,
+// until they are released. The caller must hold the manager's lock.
+func (m *Manager) cancelTaken(id types.Hash) bool {
+	offer, has := m.taken[id]
+	if !has {
+		return false
+	}
+
+	offer.extra.Cancelled = true
+	return true
+}
+
 // GetOffers returns all current offers. The returned slice is in random order and will not
 // be the same from one invocation to the next.
 func (m *Manager) GetOffers() []*types.Offer {
@@ -140,7 +377,37 @@ func (m *Manager) GetOffers() []*types.Offer {
 	return offers
 }
 
-// ClearAllOffers clears all offers.
+// GetOffersForPeer returns the current offers that are served to the passed
+// peer, which are the public offers and the private offers it's allowed to
+// see, in random order.
+func (m *Manager) GetOffersForPeer(who peer.ID) []*types.Offer {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	offers := make([]*types.Offer, 0, len(m.offers))
+	for _, o := range m.offers {
+		if o.extra.VisibleTo(who) {
+			offers = append(offers, o.offer)
+		}
+	}
+	return offers
+}
+
+// GetTakenOffers returns the offers that are taken by in-flight swaps, in
+// random order.
+func (m *Manager) GetTakenOffers() []*types.Offer {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	offers := make([]*types.Offer, 0, len(m.taken))
+	for _, o := range m.taken {
+		offers = append(offers, o.offer)
+	}
+	return offers
+}
+
+// ClearAllOffers clears all offers. Offers taken by in-flight swaps are
+// cancelled instead, so that their swaps can still complete.
 func (m *Manager) ClearAllOffers() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -150,14 +417,28 @@ func (m *Manager) ClearAllOffers() error {
 	}
 
 	m.offers = make(map[types.Hash]*offerWithExtra)
+
+	for id, offer := range m.taken {
+		m.cancelTaken(id)
+		if err = m.db.PutOffer(offer.offer); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// ClearOfferIDs clears the passed in offer IDs if they exist.
+// ClearOfferIDs clears the passed in offer IDs if they exist. Offers taken by
+// in-flight swaps are cancelled instead, so that their swaps can still
+// complete.
 func (m *Manager) ClearOfferIDs(ids []types.Hash) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	for _, id := range ids {
+		if m.cancelTaken(id) {
+			continue
+		}
+
 		delete(m.offers, id)
 		err := m.db.DeleteOffer(id)
 		if err != nil && !errors.Is(chaindb.ErrKeyNotFound, err) {
@@ -173,6 +454,7 @@ func (m *Manager) DeleteOffer(id types.Hash) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	delete(m.offers, id)
+	delete(m.taken, id)
 	err := m.db.DeleteOffer(id)
 	if err != nil && !errors.Is(chaindb.ErrKeyNotFound, err) {
 		return err
@@ -6,6 +6,7 @@ import (
 	"github.com/ChainSafe/chaindb"
 	"github.com/cockroachdb/apd/v3"
 	"github.com/golang/mock/gomock"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/require"
 
 	"github.com/athanorlabs/atomic-swap/coins"
@@ -63,11 +64,51 @@ func Test_Manager(t *testing.T) {
 	offers = mgr.GetOffers()
 	require.Len(t, offers, numAdd-numTake-2)
 
+	// taken offers are kept in the db for their in-flight swaps
+	db.EXPECT().PutOffer(gomock.Any()).Times(numTake)
 	mgr.ClearAllOffers()
 	offers = mgr.GetOffers()
 	require.Len(t, offers, 0)
 }
 
+func Test_Manager_CancelTakenOffer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+	db.EXPECT().GetAllOffers()
+
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	one := coins.StrToDecimal("1")
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	db.EXPECT().PutOffer(offer)
+	_, err = mgr.AddOffer(offer, false)
+	require.NoError(t, err)
+
+	// a failed swap on a taken offer re-adds it
+	_, _, err = mgr.TakeOffer(offer.ID)
+	require.NoError(t, err)
+	require.Len(t, mgr.GetOffers(), 0)
+	db.EXPECT().PutOffer(offer)
+	require.NoError(t, mgr.ReleaseOffer(offer, false))
+	require.Len(t, mgr.GetOffers(), 1)
+
+	// cancelling a taken offer keeps it in the db until its swap is done, but
+	// it's deleted instead of being re-added
+	_, extra, err := mgr.TakeOffer(offer.ID)
+	require.NoError(t, err)
+	require.NoError(t, mgr.ClearOfferIDs([]types.Hash{offer.ID}))
+	require.True(t, extra.Cancelled)
+	require.True(t, mgr.IsCancelled(offer.ID))
+	require.Len(t, mgr.GetOffers(), 0)
+
+	db.EXPECT().DeleteOffer(offer.ID)
+	require.NoError(t, mgr.ReleaseOffer(offer, false))
+	require.False(t, mgr.IsCancelled(offer.ID))
+	require.Len(t, mgr.GetOffers(), 0)
+}
+
 func Test_Manager_NoErrorDeletingOfferNotOnDisk(t *testing.T) {
 	dataDir := t.TempDir()
 	testDB, err := db.NewDatabase(&chaindb.Config{DataDir: dataDir})
@@ -118,3 +159,177 @@ func Test_Manager_NoErrorDeletingOfferNotOnDisk(t *testing.T) {
 	err = mgr.DeleteOffer(offer.ID)
 	require.NoError(t, err)
 }
+
+func Test_Manager_FindEquivalentOffer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+	db.EXPECT().GetAllOffers()
+	db.EXPECT().PutOffer(gomock.Any()).AnyTimes()
+
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	min := coins.StrToDecimal("1")
+	max := coins.StrToDecimal("2")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	offer := types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
+	_, err = mgr.AddOffer(offer, false)
+	require.NoError(t, err)
+
+	// an offer is not equivalent to itself
+	existing, _ := mgr.FindEquivalentOffer(offer, false)
+	require.Nil(t, existing)
+
+	// same terms with a different nonce, using equal decimals with a different exponent
+	duplicate := types.NewOffer(coins.ProvidesXMR, coins.StrToDecimal("1.0"), max, rate, types.EthAssetETH)
+	existing, extra := mgr.FindEquivalentOffer(duplicate, false)
+	require.NotNil(t, existing)
+	require.NotNil(t, extra)
+	require.Equal(t, offer.ID, existing.ID)
+
+	// relayer usage differs
+	existing, _ = mgr.FindEquivalentOffer(duplicate, true)
+	require.Nil(t, existing)
+
+	// exchange rate differs
+	otherRate := coins.ToExchangeRate(coins.StrToDecimal("0.2"))
+	other := types.NewOffer(coins.ProvidesXMR, min, max, otherRate, types.EthAssetETH)
+	existing, _ = mgr.FindEquivalentOffer(other, false)
+	require.Nil(t, existing)
+}
+
+func Test_Manager_AddOffer_DuplicateID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllOffers()
+
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	one := coins.StrToDecimal("1")
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	db.EXPECT().PutOffer(offer)
+	extra, err := mgr.AddOffer(offer, false)
+	require.NoError(t, err)
+
+	// adding the same offer again is a no-op
+	again := *offer
+	sameExtra, err := mgr.AddOffer(&again, false)
+	require.NoError(t, err)
+	require.Same(t, extra, sameExtra)
+
+	// a different offer that has the same ID is rejected
+	collision := *offer
+	collision.MaxAmount = coins.StrToDecimal("2")
+	_, err = mgr.AddOffer(&collision, false)
+	require.ErrorIs(t, err, errOfferIDCollision)
+
+	// including when the existing offer was taken
+	_, _, err = mgr.TakeOffer(offer.ID)
+	require.NoError(t, err)
+	_, err = mgr.AddOffer(&collision, false)
+	require.ErrorIs(t, err, errOfferIDCollision)
+
+	// reusing a nonce is only logged, as the offer's ID still differs
+	two := coins.StrToDecimal("2")
+	reused := types.NewOffer(coins.ProvidesXMR, two, two, coins.ToExchangeRate(two), types.EthAssetETH)
+	reused.Nonce = offer.Nonce
+	reused.ID = reused.Hash()
+	db.EXPECT().PutOffer(reused)
+	_, err = mgr.AddOffer(reused, false)
+	require.NoError(t, err)
+}
+
+func Test_Manager_PrivateOffers(t *testing.T) {
+	sdb, err := db.NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sdb.Close()) }()
+
+	mgr, err := NewManager(t.TempDir(), sdb)
+	require.NoError(t, err)
+
+	one := coins.StrToDecimal("1")
+	oneRate := coins.ToExchangeRate(one)
+	public := types.NewOffer(coins.ProvidesXMR, one, one, oneRate, types.EthAssetETH)
+	_, err = mgr.AddOffer(public, false)
+	require.NoError(t, err)
+
+	allowed, err := peer.Decode("12D3KooWAYn1T8Lu122Pav4zAogjpeU61usLTNZpLRNh9gCqY6X2")
+	require.NoError(t, err)
+	other, err := peer.Decode("12D3KooWGBw6ScWiL6k3pKNT2LR9o6MVh5CtYj1X8E1rdKueYLjv")
+	require.NoError(t, err)
+
+	private := types.NewOffer(coins.ProvidesXMR, one, one, oneRate, types.EthAssetETH)
+	extra, err := mgr.AddPrivateOffer(private, false, []peer.ID{allowed})
+	require.NoError(t, err)
+	require.True(t, extra.Private)
+
+	// private offers are never equivalent to public ones
+	existing, _ := mgr.FindEquivalentOffer(public, false)
+	require.Nil(t, existing)
+
+	requireOfferIDs := func(expected []types.Hash, offers []*types.Offer) {
+		ids := make([]types.Hash, len(offers))
+		for i, o := range offers {
+			ids[i] = o.ID
+		}
+		require.ElementsMatch(t, expected, ids)
+	}
+
+	requireOfferIDs([]types.Hash{public.ID, private.ID}, mgr.GetOffers())
+	requireOfferIDs([]types.Hash{public.ID, private.ID}, mgr.GetOffersForPeer(allowed))
+	requireOfferIDs([]types.Hash{public.ID}, mgr.GetOffersForPeer(other))
+	requireOfferIDs([]types.Hash{public.ID}, mgr.GetOffersForPeer(""))
+
+	// the visibility is loaded with the offer
+	mgr, err = NewManager(t.TempDir(), sdb)
+	require.NoError(t, err)
+	_, extra, err = mgr.GetOffer(private.ID)
+	require.NoError(t, err)
+	require.True(t, extra.Private)
+	require.Equal(t, []peer.ID{allowed}, extra.AllowedPeers)
+	requireOfferIDs([]types.Hash{public.ID}, mgr.GetOffersForPeer(other))
+
+	// and deleted with it
+	require.NoError(t, mgr.DeleteOffer(private.ID))
+	visibility, err := sdb.GetOfferVisibility(private.ID)
+	require.NoError(t, err)
+	require.False(t, visibility.Private)
+}
+
+func Test_Manager_MoneroLockPriority(t *testing.T) {
+	sdb, err := db.NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sdb.Close()) }()
+
+	mgr, err := NewManager(t.TempDir(), sdb)
+	require.NoError(t, err)
+
+	one := coins.StrToDecimal("1")
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	_, err = mgr.AddOffer(offer, false)
+	require.NoError(t, err)
+	require.NoError(t, mgr.SetMoneroLockPriority(offer.ID, types.MoneroPriorityHigh))
+
+	// the priority is loaded with the offer
+	mgr, err = NewManager(t.TempDir(), sdb)
+	require.NoError(t, err)
+	_, extra, err := mgr.GetOffer(offer.ID)
+	require.NoError(t, err)
+	require.Equal(t, types.MoneroPriorityHigh, extra.MoneroLockPriority)
+
+	// and deleted with it
+	require.NoError(t, mgr.DeleteOffer(offer.ID))
+	priority, err := sdb.GetOfferMoneroLockPriority(offer.ID)
+	require.NoError(t, err)
+	require.Equal(t, types.MoneroPriorityDefault, priority)
+}
@@ -0,0 +1,229 @@
+package offers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// defaultRepriceInterval is how often offers are repriced when the caller does
+// not set OraclePricingConfig.Interval.
+const defaultRepriceInterval = time.Minute * 5
+
+var (
+	errNilPriceOracle        = errors.New("price oracle must be set")
+	errSpreadTooSmall        = errors.New("spread must be greater than -1")
+	errOracleRateNotPositive = errors.New("oracle exchange rate must be positive")
+)
+
+// PriceOracle provides a reference XMR/ETH exchange rate. Users can supply
+// their own implementation to peg offers to any price source.
+type PriceOracle interface {
+	ExchangeRate(ctx context.Context) (*coins.ExchangeRate, error)
+}
+
+// OraclePricingConfig configures the pricing mode where offer exchange rates
+// track a PriceOracle instead of being fixed at creation time.
+type OraclePricingConfig struct {
+	Oracle PriceOracle
+	// Spread is the fractional adjustment applied to the oracle rate, so an
+	// offer's rate is oracleRate * (1 + Spread). A spread of 0.01 prices
+	// offers 1% above the oracle and -0.01 prices them 1% below.
+	Spread   *apd.Decimal
+	Interval time.Duration
+}
+
+func (c *OraclePricingConfig) validate() error {
+	if c.Oracle == nil {
+		return errNilPriceOracle
+	}
+	return validateSpread(c.Spread)
+}
+
+func validateSpread(spread *apd.Decimal) error {
+	if spread != nil && spread.Cmp(apd.New(-1, 0)) <= 0 {
+		return errSpreadTooSmall
+	}
+	return nil
+}
+
+// applySpread returns oracleRate * (1 + spread), rounded to the maximum number
+// of decimal places that an exchange rate supports.
+func applySpread(oracleRate *coins.ExchangeRate, spread *apd.Decimal) (*coins.ExchangeRate, error) {
+	if oracleRate.Decimal().Sign() <= 0 {
+		return nil, errOracleRateNotPositive
+	}
+
+	multiplier := apd.New(1, 0)
+	if spread != nil {
+		if _, err := coins.DecimalCtx().Add(multiplier, multiplier, spread); err != nil {
+			return nil, err
+		}
+	}
+
+	rate := new(apd.Decimal)
+	ctx := coins.DecimalCtx()
+	if _, err := ctx.Mul(rate, oracleRate.Decimal(), multiplier); err != nil {
+		return nil, err
+	}
+	if _, err := ctx.Quantize(rate, rate, -coins.MaxExchangeRateDecimals); err != nil {
+		return nil, err
+	}
+	_, _ = rate.Reduce(rate)
+
+	if rate.Sign() <= 0 {
+		return nil, errOracleRateNotPositive
+	}
+
+	return coins.ToExchangeRate(rate), nil
+}
+
+// StartOraclePricing reprices all managed offers immediately and then on every
+// interval until the passed context is cancelled. Offers whose exchange rate
+// is unchanged are left as is.
+func (m *Manager) StartOraclePricing(ctx context.Context, conf *OraclePricingConfig) error {
+	if err := conf.validate(); err != nil {
+		return err
+	}
+
+	go repriceEvery(ctx, conf.Interval, func() error {
+		return m.reprice(ctx, conf)
+	})
+
+	return nil
+}
+
+// repriceEvery calls reprice immediately and then on every interval, or
+// defaultRepriceInterval if it's zero, until ctx is cancelled. Offers keep
+// their last terms when repricing fails.
+func repriceEvery(ctx context.Context, interval time.Duration, reprice func() error) {
+	if interval == 0 {
+		interval = defaultRepriceInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := reprice(); err != nil {
+			log.Warnf("failed to reprice offers, keeping their last terms: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) reprice(ctx context.Context, conf *OraclePricingConfig) error {
+	oracleRate, err := conf.Oracle.ExchangeRate(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get oracle exchange rate: %w", err)
+	}
+
+	rate, err := applySpread(oracleRate, conf.Spread)
+	if err != nil {
+		return err
+	}
+
+	return m.RepriceOffers(rate)
+}
+
+// RepriceOffers replaces every managed offer whose exchange rate differs from
+// the passed rate with an otherwise identical offer at the new rate. Since the
+// rate is part of the offer's hash, each replacement has a new ID. The old
+// offer is removed and the new one added while holding the manager's lock, so
+// callers never observe both or neither. Offers pegged to a fiat currency are
+// skipped, as their rate is set when their fiat terms are repriced.
+func (m *Manager) RepriceOffers(rate *coins.ExchangeRate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, o := range m.offers {
+		if o.extra.FiatReference != nil {
+			continue
+		}
+
+		if o.offer.ExchangeRate.Decimal().Cmp(rate.Decimal()) == 0 {
+			continue
+		}
+
+		newOffer := repricedOffer(o.offer, o.offer.MinAmount, o.offer.MaxAmount, rate)
+		if err := m.replaceOffer(id, newOffer); err != nil {
+			return err
+		}
+
+		log.Infof("repriced offer %s to %s (new offer ID %s)", id, rate.Decimal(), newOffer.ID)
+	}
+
+	return nil
+}
+
+// repricedOffer returns a new offer with the passed amounts and exchange rate,
+// and otherwise the same terms as the old offer, including the SwapFactory and
+// SwapTimeout that it was made with.
+func repricedOffer(
+	old *types.Offer,
+	minAmount *apd.Decimal,
+	maxAmount *apd.Decimal,
+	rate *coins.ExchangeRate,
+) *types.Offer {
+	newOffer := types.NewOffer(
+		old.Provides,
+		new(apd.Decimal).Set(minAmount),
+		new(apd.Decimal).Set(maxAmount),
+		coins.ToExchangeRate(new(apd.Decimal).Set(rate.Decimal())),
+		old.EthAsset,
+	)
+
+	newOffer.SwapFactory = old.SwapFactory
+	newOffer.SwapTimeout = old.SwapTimeout
+	newOffer.ID = newOffer.Hash()
+	return newOffer
+}
+
+// replaceOffer swaps the offer with the passed ID for newOffer, keeping the old
+// offer's OfferExtra, including the settings stored under the offer ID in the
+// database. The caller must hold the manager's lock.
+func (m *Manager) replaceOffer(id types.Hash, newOffer *types.Offer) error {
+	o := m.offers[id]
+
+	if o.extra.Private {
+		if err := m.db.PutOfferVisibility(newOffer.ID, &o.extra.OfferVisibility); err != nil {
+			return err
+		}
+	}
+
+	if o.extra.MoneroLockPriority != types.MoneroPriorityDefault {
+		err := m.db.PutOfferMoneroLockPriority(newOffer.ID, o.extra.MoneroLockPriority)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := m.db.PutOffer(newOffer); err != nil {
+		return err
+	}
+
+	if err := m.db.DeleteOffer(id); err != nil {
+		// roll back so the db matches the in-memory offers
+		_ = m.db.DeleteOffer(newOffer.ID)
+		return err
+	}
+
+	delete(m.offers, id)
+	m.offers[newOffer.ID] = &offerWithExtra{
+		offer: newOffer,
+		extra: o.extra,
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package offers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+type mockOracle struct {
+	rate *coins.ExchangeRate
+}
+
+func (o *mockOracle) ExchangeRate(_ context.Context) (*coins.ExchangeRate, error) {
+	return o.rate, nil
+}
+
+func Test_applySpread(t *testing.T) {
+	oracleRate := coins.ToExchangeRate(apd.New(5, -2)) // 0.05
+
+	rate, err := applySpread(oracleRate, apd.New(1, -2)) // +1%
+	require.NoError(t, err)
+	require.Equal(t, "0.0505", rate.Decimal().String())
+
+	rate, err = applySpread(oracleRate, apd.New(-2, -2)) // -2%
+	require.NoError(t, err)
+	require.Equal(t, "0.049", rate.Decimal().String())
+
+	rate, err = applySpread(oracleRate, nil)
+	require.NoError(t, err)
+	require.Equal(t, "0.05", rate.Decimal().String())
+
+	_, err = applySpread(coins.ToExchangeRate(apd.New(0, 0)), nil)
+	require.ErrorIs(t, err, errOracleRateNotPositive)
+}
+
+func Test_Manager_RepriceOffers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllOffers()
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	offer := types.NewOffer(
+		coins.ProvidesXMR,
+		apd.New(1, 0),
+		apd.New(2, 0),
+		coins.ToExchangeRate(apd.New(5, -2)),
+		types.EthAssetETH,
+	)
+	offer.SetSwapFactory(ethcommon.Address{0x1})
+	offer.SetSwapTimeout(time.Hour)
+	db.EXPECT().PutOffer(offer)
+	extra, err := mgr.AddOffer(offer, true)
+	require.NoError(t, err)
+
+	// repricing with the same rate is a no-op
+	err = mgr.RepriceOffers(coins.ToExchangeRate(apd.New(5, -2)))
+	require.NoError(t, err)
+	require.Equal(t, offer.ID, mgr.GetOffers()[0].ID)
+
+	db.EXPECT().PutOffer(gomock.Any())
+	db.EXPECT().DeleteOffer(offer.ID)
+	err = mgr.RepriceOffers(coins.ToExchangeRate(apd.New(6, -2)))
+	require.NoError(t, err)
+
+	offers := mgr.GetOffers()
+	require.Len(t, offers, 1)
+	require.NotEqual(t, offer.ID, offers[0].ID)
+	require.Equal(t, "0.06", offers[0].ExchangeRate.Decimal().String())
+	require.Equal(t, offer.MinAmount.String(), offers[0].MinAmount.String())
+	require.Equal(t, offer.MaxAmount.String(), offers[0].MaxAmount.String())
+	require.Equal(t, offer.SwapFactory, offers[0].SwapFactory)
+	require.Equal(t, offer.SwapTimeout, offers[0].SwapTimeout)
+	require.Equal(t, offers[0].Hash(), offers[0].ID)
+
+	_, newExtra, err := mgr.GetOffer(offers[0].ID)
+	require.NoError(t, err)
+	require.True(t, extra == newExtra)
+
+	_, _, err = mgr.GetOffer(offer.ID)
+	require.ErrorIs(t, err, errOfferDoesNotExist)
+}
+
+func Test_Manager_RepriceOffers_keepsLockPriority(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllOffers()
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	offer := types.NewOffer(
+		coins.ProvidesXMR,
+		apd.New(1, 0),
+		apd.New(2, 0),
+		coins.ToExchangeRate(apd.New(5, -2)),
+		types.EthAssetETH,
+	)
+	db.EXPECT().PutOffer(offer)
+	_, err = mgr.AddOffer(offer, true)
+	require.NoError(t, err)
+
+	db.EXPECT().PutOfferMoneroLockPriority(offer.ID, types.MoneroPriorityHigh)
+	err = mgr.SetMoneroLockPriority(offer.ID, types.MoneroPriorityHigh)
+	require.NoError(t, err)
+
+	var newID types.Hash
+	db.EXPECT().PutOfferMoneroLockPriority(gomock.Any(), types.MoneroPriorityHigh).
+		Do(func(id types.Hash, _ types.MoneroTxPriority) { newID = id })
+	db.EXPECT().PutOffer(gomock.Any())
+	db.EXPECT().DeleteOffer(offer.ID)
+	err = mgr.RepriceOffers(coins.ToExchangeRate(apd.New(6, -2)))
+	require.NoError(t, err)
+
+	offers := mgr.GetOffers()
+	require.Len(t, offers, 1)
+	require.Equal(t, offers[0].ID, newID)
+
+	_, extra, err := mgr.GetOffer(newID)
+	require.NoError(t, err)
+	require.Equal(t, types.MoneroPriorityHigh, extra.MoneroLockPriority)
+}
+
+func Test_Manager_StartOraclePricing_noOracle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllOffers()
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	err = mgr.StartOraclePricing(context.Background(), &OraclePricingConfig{})
+	require.ErrorIs(t, err, errNilPriceOracle)
+}
+
+func Test_Manager_reprice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllOffers()
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	offer := types.NewOffer(
+		coins.ProvidesXMR,
+		apd.New(1, 0),
+		apd.New(2, 0),
+		coins.ToExchangeRate(apd.New(5, -2)),
+		types.EthAssetETH,
+	)
+	db.EXPECT().PutOffer(offer)
+	_, err = mgr.AddOffer(offer, false)
+	require.NoError(t, err)
+
+	conf := &OraclePricingConfig{
+		Oracle: &mockOracle{rate: coins.ToExchangeRate(apd.New(1, -1))},
+		Spread: apd.New(5, -2),
+	}
+	db.EXPECT().PutOffer(gomock.Any())
+	db.EXPECT().DeleteOffer(offer.ID)
+	err = mgr.reprice(context.Background(), conf)
+	require.NoError(t, err)
+	require.Equal(t, "0.105", mgr.GetOffers()[0].ExchangeRate.Decimal().String())
+}
@@ -0,0 +1,96 @@
+package xmrmaker
+
+import (
+	"sort"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/metrics"
+)
+
+// minRelayerAttempts is how many claims must have been submitted to a relayer
+// before its success rate is compared to the minimum success rate.
+const minRelayerAttempts = 5
+
+// RelayerStats is implemented by *db.RelayerStats
+type RelayerStats interface {
+	RecordSuccess(id peer.ID) error
+	RecordFailure(id peer.ID) error
+	Get(id peer.ID) (*types.RelayerClaimStats, error)
+	GetAll() ([]*types.RelayerClaimStats, error)
+}
+
+// GetRelayerClaimStats returns how the relayers we submitted claims to
+// performed, with the most reliable relayers first.
+func (inst *Instance) GetRelayerClaimStats() ([]*types.RelayerClaimStats, error) {
+	if inst.relayerStats == nil {
+		return []*types.RelayerClaimStats{}, nil
+	}
+
+	all, err := inst.relayerStats.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].SuccessRate() > all[j].SuccessRate()
+	})
+
+	return all, nil
+}
+
+// orderRelayers returns the relayers with the most reliable first, leaving out
+// those whose success rate is below the minimum. Relayers without a history
+// keep their order among relayers with a perfect one.
+func (s *swapState) orderRelayers(relayers []peer.ID) []peer.ID {
+	if s.relayerStats == nil {
+		return relayers
+	}
+
+	rates := make(map[peer.ID]float64, len(relayers))
+	ordered := make([]peer.ID, 0, len(relayers))
+	for _, id := range relayers {
+		stats, err := s.relayerStats.Get(id)
+		if err != nil {
+			log.Warnf("failed to get stats of relayer %s: %s", id, err)
+			stats = &types.RelayerClaimStats{PeerID: id}
+		}
+
+		if stats.Attempts() >= minRelayerAttempts && stats.SuccessRate() < s.minRelayerSuccessRate {
+			log.Debugf("skipping relayer %s with success rate %.2f", id, stats.SuccessRate())
+			continue
+		}
+
+		rates[id] = stats.SuccessRate()
+		ordered = append(ordered, id)
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rates[ordered[i]] > rates[ordered[j]]
+	})
+
+	return ordered
+}
+
+// recordRelayerResult counts a claim submitted to the relayer as mined, if err
+// is nil, or failed. Claims interrupted by the swap exiting aren't counted.
+func (s *swapState) recordRelayerResult(id peer.ID, err error) {
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	metrics.RelayerClaimSubmitted(err == nil)
+	if s.relayerStats == nil {
+		return
+	}
+
+	if err == nil {
+		err = s.relayerStats.RecordSuccess(id)
+	} else {
+		err = s.relayerStats.RecordFailure(id)
+	}
+	if err != nil {
+		log.Warnf("failed to record claim result of relayer %s: %s", id, err)
+	}
+}
@@ -0,0 +1,63 @@
+package xmrmaker
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+type mockRelayerStats map[peer.ID]*types.RelayerClaimStats
+
+func (m mockRelayerStats) RecordSuccess(id peer.ID) error {
+	stats, _ := m.Get(id)
+	stats.Succeeded++
+	m[id] = stats
+	return nil
+}
+
+func (m mockRelayerStats) RecordFailure(id peer.ID) error {
+	stats, _ := m.Get(id)
+	stats.Failed++
+	m[id] = stats
+	return nil
+}
+
+func (m mockRelayerStats) Get(id peer.ID) (*types.RelayerClaimStats, error) {
+	if stats, has := m[id]; has {
+		return stats, nil
+	}
+	return &types.RelayerClaimStats{PeerID: id}, nil
+}
+
+func (m mockRelayerStats) GetAll() ([]*types.RelayerClaimStats, error) {
+	all := make([]*types.RelayerClaimStats, 0, len(m))
+	for _, stats := range m {
+		all = append(all, stats)
+	}
+	return all, nil
+}
+
+func TestSwapState_orderRelayers(t *testing.T) {
+	reliable := peer.ID("reliable")
+	unknown := peer.ID("unknown")
+	flaky := peer.ID("flaky")
+	unreliable := peer.ID("unreliable")
+
+	stats := mockRelayerStats{
+		reliable:   {PeerID: reliable, Succeeded: 10},
+		flaky:      {PeerID: flaky, Succeeded: 3, Failed: 2},
+		unreliable: {PeerID: unreliable, Succeeded: 1, Failed: 9},
+	}
+
+	s := &swapState{swapOptions: swapOptions{relayerStats: stats, minRelayerSuccessRate: 0.5}}
+	ordered := s.orderRelayers([]peer.ID{unreliable, flaky, unknown, reliable})
+	require.Equal(t, []peer.ID{unknown, reliable, flaky}, ordered)
+
+	// without stats, relayers are tried in the order they were discovered
+	s = &swapState{}
+	ordered = s.orderRelayers([]peer.ID{unreliable, flaky})
+	require.Equal(t, []peer.ID{unreliable, flaky}, ordered)
+}
@@ -0,0 +1,109 @@
+package xmrmaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// xmrReservations holds back the XMR that accepted takes are going to lock from
+// our unlocked balance. Our balance only drops once a swap's XMR is locked,
+// which is after the taker locks their ETH, so without reservations two takes
+// arriving in that time could both be accepted against the same funds.
+//
+// A reservation is released when the swap's XMR is locked or the swap exits,
+// or else when the reservation window passes. All methods are no-ops on a nil
+// *xmrReservations.
+type xmrReservations struct {
+	mu      sync.Mutex
+	window  time.Duration
+	now     func() time.Time // overridden in tests
+	byOffer map[types.Hash]*xmrReservation
+}
+
+type xmrReservation struct {
+	amount  *coins.PiconeroAmount
+	expires time.Time
+}
+
+func newXMRReservations(window time.Duration) *xmrReservations {
+	return &xmrReservations{
+		window:  window,
+		now:     time.Now,
+		byOffer: make(map[types.Hash]*xmrReservation),
+	}
+}
+
+// reserve holds back `amount` for the swap on the offer with the given ID,
+// replacing any existing reservation for the offer.
+func (r *xmrReservations) reserve(offerID types.Hash, amount *coins.PiconeroAmount) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byOffer[offerID] = &xmrReservation{
+		amount:  amount,
+		expires: r.now().Add(r.window),
+	}
+}
+
+// release removes the reservation for the offer with the given ID, if any.
+func (r *xmrReservations) release(offerID types.Hash) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byOffer, offerID)
+}
+
+// reserved returns the unexpired reservation for the offer with the given ID,
+// or zero if there is none.
+func (r *xmrReservations) reserved(offerID types.Hash) *coins.PiconeroAmount {
+	if r == nil {
+		return new(coins.PiconeroAmount)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, has := r.byOffer[offerID]
+	if !has || !r.now().Before(res.expires) {
+		return new(coins.PiconeroAmount)
+	}
+
+	return res.amount
+}
+
+// total returns the sum of the unexpired reservations, removing expired ones.
+func (r *xmrReservations) total() (*coins.PiconeroAmount, error) {
+	sum := new(apd.Decimal)
+	if r == nil {
+		return (*coins.PiconeroAmount)(sum), nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for id, res := range r.byOffer {
+		if !now.Before(res.expires) {
+			log.Debugf("reservation of %s XMR for offer %s expired", res.amount.AsMoneroString(), id)
+			delete(r.byOffer, id)
+			continue
+		}
+
+		if _, err := coins.DecimalCtx().Add(sum, sum, res.amount.Decimal()); err != nil {
+			return nil, err
+		}
+	}
+
+	return (*coins.PiconeroAmount)(sum), nil
+}
@@ -0,0 +1,56 @@
+package xmrmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestXMRReservations(t *testing.T) {
+	now := time.Now()
+	r := newXMRReservations(time.Minute)
+	r.now = func() time.Time { return now }
+
+	r.reserve(types.Hash{0x1}, coins.NewPiconeroAmount(100))
+	r.reserve(types.Hash{0x2}, coins.NewPiconeroAmount(20))
+	total, err := r.total()
+	require.NoError(t, err)
+	require.Zero(t, total.CmpU64(120))
+
+	// reserving again for the same offer replaces the reservation
+	r.reserve(types.Hash{0x1}, coins.NewPiconeroAmount(50))
+	total, err = r.total()
+	require.NoError(t, err)
+	require.Zero(t, total.CmpU64(70))
+
+	require.Zero(t, r.reserved(types.Hash{0x2}).CmpU64(20))
+	require.Zero(t, r.reserved(types.Hash{0x3}).CmpU64(0))
+
+	r.release(types.Hash{0x2})
+	require.Zero(t, r.reserved(types.Hash{0x2}).CmpU64(0))
+	total, err = r.total()
+	require.NoError(t, err)
+	require.Zero(t, total.CmpU64(50))
+
+	// expired reservations no longer count
+	now = now.Add(time.Minute)
+	require.Zero(t, r.reserved(types.Hash{0x1}).CmpU64(0))
+	total, err = r.total()
+	require.NoError(t, err)
+	require.Zero(t, total.CmpU64(0))
+	require.Empty(t, r.byOffer)
+}
+
+func TestXMRReservations_nil(t *testing.T) {
+	var r *xmrReservations
+	r.reserve(types.Hash{0x1}, coins.NewPiconeroAmount(100))
+	r.release(types.Hash{0x1})
+	require.Zero(t, r.reserved(types.Hash{0x1}).CmpU64(0))
+	total, err := r.total()
+	require.NoError(t, err)
+	require.Zero(t, total.CmpU64(0))
+}
@@ -4,14 +4,18 @@ package xmrmaker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"os"
+	"path"
 	"time"
 
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/fatih/color"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
@@ -29,6 +33,7 @@ import (
 	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/txsender"
 	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker/offers"
+	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
 var (
@@ -37,6 +42,75 @@ var (
 	refundedTopic = common.GetTopic(common.RefundedEventSignature)
 )
 
+// swapOptions are the instance-wide settings that each swap is created with.
+type swapOptions struct {
+	// how long before t0 the XMR lock must be confirmed by
+	xmrLockMargin time.Duration
+
+	// if set, XMR is locked from and reclaimed to a dedicated wallet for the
+	// swap instead of our primary wallet
+	perSwapWallet bool
+
+	// if set, reclaiming XMR continues when the counterparty's swap key can't
+	// be written to the db, with the key written to a file in keyBackupDir
+	reclaimOnKeyPersistFailure bool
+	keyBackupDir               string
+
+	// how many times to retry fetching the receipt of a relayed claim after a
+	// transient RPC error
+	claimReceiptRetries uint
+
+	// how many more times to submit our claim to the relayers that failed
+	// transiently, when claiming with a relayer
+	relayClaimRetries uint
+
+	// how long before t1 we stop waiting on relayers to claim for us, and
+	// claim directly if we can pay for gas
+	claimDeadlineMargin time.Duration
+
+	// how many times to retry generating our swap keys and DLEq proof
+	keyGenRetries uint
+
+	// keys and DLEq proofs generated ahead of time, if enabled
+	keyPool *pcommon.KeyPool
+
+	// the priority of the XMR lock transfer, unless the offer overrides it
+	moneroLockPriority types.MoneroTxPriority
+
+	// how many confirmations the counterparty's ETH lock must have before we
+	// lock XMR, after which contract events are also checked for reorgs
+	ethLockConfirmations uint64
+
+	// where the results of claims submitted to relayers are recorded, and the
+	// success rate below which relayers are skipped; nil if not recorded
+	relayerStats          RelayerStats
+	minRelayerSuccessRate float64
+
+	// how the gas limit of claims submitted to relayers is estimated
+	claimGas *relayer.ClaimGasConfig
+
+	// how the gas price of claims that we send ourselves is raised while
+	// they're stuck
+	claimGasBump *txsender.GasBumpConfig
+
+	// the XMR held back from our balance for accepted takes, which a swap
+	// releases once its XMR is locked; nil if takes don't reserve XMR
+	reservations *xmrReservations
+
+	// verified DLEq proofs of counterparties, so repeated takes with the same
+	// keys aren't verified again; nil if every proof is verified
+	proofCache *pcommon.ProofCache
+}
+
+// lockPriority returns the priority of the XMR lock transfer for swaps on an
+// offer with the passed extra, which may override the configured priority.
+func (o *swapOptions) lockPriority(extra *types.OfferExtra) types.MoneroTxPriority {
+	if extra != nil && extra.MoneroLockPriority != types.MoneroPriorityDefault {
+		return extra.MoneroLockPriority
+	}
+	return o.moneroLockPriority
+}
+
 type swapState struct {
 	backend.Backend
 	sender txsender.Sender
@@ -47,7 +121,11 @@ type swapState struct {
 	info         *pswap.Info
 	offer        *types.Offer
 	offerExtra   *types.OfferExtra
-	offerManager *offers.Manager
+	offerManager *offers.Manager // nil if the offer isn't ours
+
+	// set if we initiated the swap on the counterparty's offer that provides
+	// ETH, in which case they reply to our keys with theirs
+	initiator bool
 
 	// our keys for this session
 	dleqProof    *dleq.Proof
@@ -62,6 +140,9 @@ type swapState struct {
 	contractSwap   *contracts.SwapFactorySwap
 	t0, t1         time.Time
 
+	// block that the swap started at, from which contract events are searched
+	ethStartNumber *big.Int
+
 	// XMRTaker's keys for this session
 	xmrtakerPublicSpendKey     *mcrypto.PublicKey
 	xmrtakerPrivateViewKey     *mcrypto.PrivateViewKey
@@ -72,6 +153,12 @@ type swapState struct {
 	nextExpectedEvent EventType
 	// set to true once funds are locked
 	fundsLocked bool
+	// options set by the creating xmrmaker instance
+	swapOptions
+
+	// the dedicated wallet for this swap, set once opened, if
+	// swapOptions.perSwapWallet is set
+	swapWalletCli monero.WalletClient
 
 	readyWatcher *watcher.EventFilter
 
@@ -84,20 +171,31 @@ type swapState struct {
 	logReadyCh chan ethtypes.Log
 	// channel for `Refunded` logs seen on-chain
 	logRefundedCh chan ethtypes.Log
+	// closed by the contract event watcher when our swap's `Refunded` log is
+	// seen, so that claiming with relayers stops without waiting for the
+	// event handler
+	refundedCh chan struct{}
+	// channel for `Ready` and `Refunded` logs reverted by a reorg
+	logRevertedCh chan ethtypes.Log
 	// signals the t0 expiration handler to return
 	readyCh chan struct{}
 	// signals to the creator xmrmaker instance that it can delete this swap
 	done chan struct{}
+	// closed once a swap recovered after the counterparty's ETH lock has
+	// handled it, which ends the swap's startup work; nil for other swaps
+	resumed chan struct{}
 }
 
 // newSwapStateFromStart returns a new *swapState for a fresh swap.
 func newSwapStateFromStart(
 	b backend.Backend,
+	takerPeerID peer.ID,
 	offer *types.Offer,
 	offerExtra *types.OfferExtra,
 	om *offers.Manager,
 	providesAmount *coins.PiconeroAmount,
 	desiredAmount EthereumAssetAmount,
+	opts swapOptions,
 ) (*swapState, error) {
 	// at this point, we've received the counterparty's keys,
 	// and will send our own after this function returns.
@@ -113,14 +211,10 @@ func newSwapStateFromStart(
 		}
 	}
 
-	moneroStartHeight, err := b.XMRClient().GetHeight()
+	moneroStartHeight, err := b.MoneroStartHeight()
 	if err != nil {
 		return nil, err
 	}
-	// reduce the scan height a little in case there is a block reorg
-	if moneroStartHeight >= monero.MinSpendConfirmations {
-		moneroStartHeight -= monero.MinSpendConfirmations
-	}
 
 	ethHeader, err := b.ETHClient().Raw().HeaderByNumber(b.Ctx(), nil)
 	if err != nil {
@@ -136,6 +230,7 @@ func newSwapStateFromStart(
 		offer.EthAsset,
 		stage,
 		moneroStartHeight,
+		takerPeerID,
 		offerExtra.StatusCh,
 	)
 
@@ -151,13 +246,20 @@ func newSwapStateFromStart(
 		ethHeader.Number,
 		moneroStartHeight,
 		info,
+		opts,
 	)
 	if err != nil {
+		abortNewSwap(b, info)
 		return nil, err
 	}
 
 	err = s.generateAndSetKeys()
 	if err != nil {
+		// nothing was sent or locked yet, so exiting aborts the swap, re-adds
+		// the offer and stops the swap's goroutines
+		if exitErr := s.exitWithCause(err); exitErr != nil {
+			log.Warnf("failed to exit swap %s after key generation failure: %s", s.ID(), exitErr)
+		}
 		return nil, err
 	}
 
@@ -165,6 +267,19 @@ func newSwapStateFromStart(
 	return s, nil
 }
 
+// abortNewSwap marks a swap that failed before its swap state was created as
+// aborted and removes any of its recovery info.
+func abortNewSwap(b backend.Backend, info *pswap.Info) {
+	info.Status = types.CompletedAbort
+	if err := b.SwapManager().CompleteOngoingSwap(info); err != nil {
+		log.Warnf("failed to mark swap %s as aborted: %s", info.ID, err)
+	}
+
+	if err := b.RecoveryDB().DeleteSwap(info.ID); err != nil {
+		log.Warnf("failed to delete temporary swap info %s from db: %s", info.ID, err)
+	}
+}
+
 // newSwapStateFromOngoing returns a new *swapState given information about a swap
 // that's ongoing, but not yet completed.
 func newSwapStateFromOngoing(
@@ -175,16 +290,17 @@ func newSwapStateFromOngoing(
 	ethSwapInfo *db.EthereumSwapInfo,
 	info *pswap.Info,
 	sk *mcrypto.PrivateKeyPair,
+	opts swapOptions,
 ) (*swapState, error) {
-	// TODO: do we want to support the case where the ETH has been locked,
-	// but we haven't locked yet?
-	if info.Status != types.XMRLocked {
+	// KeysExchanged swaps are only recovered if the counterparty's ETH was
+	// locked, as otherwise there's no contract info in the db to recover from.
+	if info.Status != types.XMRLocked && info.Status != types.KeysExchanged {
 		return nil, errInvalidStageForRecovery
 	}
 
 	log.Debugf("restarting swap from eth block number %s", ethSwapInfo.StartNumber)
 	s, err := newSwapState(
-		b, offer, offerExtra, om, ethSwapInfo.StartNumber, info.MoneroStartHeight, info,
+		b, offer, offerExtra, om, ethSwapInfo.StartNumber, info.MoneroStartHeight, info, opts,
 	)
 	if err != nil {
 		return nil, err
@@ -200,9 +316,42 @@ func newSwapStateFromOngoing(
 	s.pubkeys = sk.PublicKeyPair()
 	s.contractSwapID = ethSwapInfo.SwapID
 	s.contractSwap = ethSwapInfo.Swap
+
+	if info.Status == types.KeysExchanged {
+		// the counterparty's keys are needed to derive the address to lock XMR in
+		s.xmrtakerPublicSpendKey, s.xmrtakerPrivateViewKey, err = b.RecoveryDB().GetCounterpartySwapKeys(info.ID)
+		if err != nil {
+			s.cancel()
+			return nil, fmt.Errorf("failed to get counterparty public keypair: %w", err)
+		}
+
+		s.resumed = make(chan struct{})
+		go func() {
+			defer close(s.resumed)
+			s.resumeETHLocked()
+		}()
+	}
+
 	return s, nil
 }
 
+// resumeETHLocked triggers the EventETHLocked handling of a swap recovered from
+// the db, where the counterparty had locked their ETH but our XMR was not yet
+// locked. If the swap can't be safely continued, it's aborted.
+func (s *swapState) resumeETHLocked() {
+	event := newEventETHLocked(nil)
+	s.eventCh <- event
+	err := <-event.errCh
+	if err == nil {
+		return
+	}
+
+	log.Errorf("failed to resume swap %s: %s", s.ID(), err)
+	if err = s.exitWithCause(err); err != nil {
+		log.Errorf("failed to exit swap %s: %s", s.ID(), err)
+	}
+}
+
 func newSwapState(
 	b backend.Backend,
 	offer *types.Offer,
@@ -211,6 +360,7 @@ func newSwapState(
 	ethStartNumber *big.Int,
 	moneroStartNumber uint64,
 	info *pswap.Info,
+	opts swapOptions,
 ) (*swapState, error) {
 	var sender txsender.Sender
 	if offer.EthAsset != types.EthAssetETH {
@@ -235,14 +385,20 @@ func newSwapState(
 	const logChSize = 16 // arbitrary, we just don't want the watcher to block on writing
 	logReadyCh := make(chan ethtypes.Log, logChSize)
 	logRefundedCh := make(chan ethtypes.Log, logChSize)
+	logRevertedCh := make(chan ethtypes.Log, logChSize)
 
 	// Create per swap context that is canceled when the swap completes
 	ctx, cancel := context.WithCancel(b.Ctx())
 
+	contractAddr := b.ContractAddr()
+	if offer.SwapFactory != nil {
+		contractAddr = *offer.SwapFactory
+	}
+
 	readyWatcher := watcher.NewEventFilter(
 		ctx,
 		b.ETHClient().Raw(),
-		b.ContractAddr(),
+		contractAddr,
 		ethStartNumber,
 		readyTopic,
 		logReadyCh,
@@ -251,12 +407,22 @@ func newSwapState(
 	refundedWatcher := watcher.NewEventFilter(
 		ctx,
 		b.ETHClient().Raw(),
-		b.ContractAddr(),
+		contractAddr,
 		ethStartNumber,
 		refundedTopic,
 		logRefundedCh,
 	)
 
+	readyWatcher.SetPollInterval(b.ETHPollInterval())
+	refundedWatcher.SetPollInterval(b.ETHPollInterval())
+	readyWatcher.SetSubscribeNewHeads(b.ETHSubscribe())
+	refundedWatcher.SetSubscribeNewHeads(b.ETHSubscribe())
+
+	if opts.ethLockConfirmations > 1 {
+		readyWatcher.SetReorgDetection(opts.ethLockConfirmations, logRevertedCh)
+		refundedWatcher.SetReorgDetection(opts.ethLockConfirmations, logRevertedCh)
+	}
+
 	err := readyWatcher.Start()
 	if err != nil {
 		cancel()
@@ -270,15 +436,15 @@ func newSwapState(
 	}
 
 	// note: if this is recovering an ongoing swap, this will only
-	// be invoked if our status is XMRLocked; ie. we've locked XMR,
-	// but not yet claimed or refunded.
+	// be invoked if our status is KeysExchanged with the counterparty's
+	// ETH locked, or XMRLocked; ie. we've locked XMR, but not yet claimed
+	// or refunded.
 	//
 	// dleqProof and secp256k1Pub are never set, as they are only used
-	// in the swap steps before XMR is locked.
+	// in the swap steps before the counterparty locks ETH.
 	//
-	// similarly, xmrtakerPublicKeys and xmrtakerSecp256K1PublicKey are
-	// also never set, as they're only used to check the contract
-	// before we lock XMR.
+	// similarly, xmrtakerSecp256K1PublicKey is also never set, as it's
+	// only used to check the contract when the ETH is first locked.
 	s := &swapState{
 		ctx:               ctx,
 		cancel:            cancel,
@@ -288,9 +454,13 @@ func newSwapState(
 		offerExtra:        offerExtra,
 		offerManager:      om,
 		moneroStartHeight: moneroStartNumber,
+		ethStartNumber:    ethStartNumber,
 		nextExpectedEvent: nextExpectedEventFromStatus(info.Status),
+		swapOptions:       opts,
 		logReadyCh:        logReadyCh,
 		logRefundedCh:     logRefundedCh,
+		refundedCh:        make(chan struct{}),
+		logRevertedCh:     logRevertedCh,
 		eventCh:           make(chan Event, 1),
 		readyCh:           make(chan struct{}),
 		info:              info,
@@ -310,6 +480,7 @@ func (s *swapState) SendKeysMessage() common.Message {
 		PublicSpendKey:     s.pubkeys.SpendKey(),
 		PrivateViewKey:     s.privkeys.ViewKey(),
 		DLEqProof:          s.dleqProof.Proof(),
+		DLEqProofScheme:    s.dleqProof.Scheme(),
 		Secp256k1PublicKey: s.secp256k1Pub,
 		EthAddress:         s.ETHClient().Address(),
 	}
@@ -329,15 +500,23 @@ func (s *swapState) ID() types.Hash {
 // It exists the swap by refunding if necessary. If no locking has been done, it simply aborts the swap.
 // If the swap already completed successfully, this function does not do anything regarding the protocol.
 func (s *swapState) Exit() error {
-	event := newEventExit()
+	return s.exitWithCause(nil)
+}
+
+// exitWithCause is the same as Exit, but records the passed error as the
+// reason the swap failed, unless an earlier error was already recorded.
+func (s *swapState) exitWithCause(cause error) error {
+	event := newEventExit(cause)
 	s.eventCh <- event
 	return <-event.errCh
 }
 
-// exit is the same as Exit, but assumes the calling code block already holds the swapState lock.
-func (s *swapState) exit() error {
+// exit is the same as exitWithCause, but assumes the calling code block already holds the swapState lock.
+func (s *swapState) exit(cause error) error {
 	log.Debugf("attempting to exit swap: nextExpectedEvent=%v", s.nextExpectedEvent)
+	s.info.SetFailure(cause)
 
+	defer s.closeSwapWallet()
 	defer func() {
 		err := s.SwapManager().CompleteOngoingSwap(s.info)
 		if err != nil {
@@ -347,15 +526,15 @@ func (s *swapState) exit() error {
 
 		log.Infof("exit status %s", s.info.Status)
 
-		if s.info.Status != types.CompletedSuccess && s.offer.IsSet() {
-			// re-add offer, as it wasn't taken successfully
-			_, err = s.offerManager.AddOffer(s.offer, s.offerExtra.UseRelayer)
+		// the offer manager is nil if the offer was the counterparty's
+		if s.offerManager != nil && s.info.Status != types.CompletedSuccess && s.offer.IsSet() {
+			// re-add offer, as it wasn't taken successfully, unless it was
+			// cancelled during the swap
+			err = s.offerManager.ReleaseOffer(s.offer, s.offerExtra.UseRelayer)
 			if err != nil {
 				log.Warnf("failed to re-add offer %s: %s", s.offer.ID, err)
 			}
-
-			log.Debugf("re-added offer %s", s.offer.ID)
-		} else if s.info.Status == types.CompletedSuccess {
+		} else if s.offerManager != nil && s.info.Status == types.CompletedSuccess {
 			err = s.offerManager.DeleteOffer(s.offer.ID)
 			if err != nil {
 				log.Warnf("failed to delete offer %s from db: %s", s.offer.ID, err)
@@ -387,10 +566,12 @@ func (s *swapState) exit() error {
 	switch s.nextExpectedEvent {
 	case EventETHLockedType:
 		// we were waiting for the contract to be deployed, but haven't
-		// locked out funds yet, so we're fine.
+		// locked out funds yet, so we're fine. Without an error that we're
+		// exiting because of, the counterparty never locked its ETH.
+		s.info.SetFailure(pswap.ErrETHLockTimeout)
 		s.clearNextExpectedEvent(types.CompletedAbort)
 		return nil
-	case EventContractReadyType:
+	case EventContractReadyType, EventETHRefundedType:
 		// this case takes control of the event channel.
 		// the next event will either be EventContractReady or EventETHRefunded.
 
@@ -425,8 +606,14 @@ func (s *swapState) exit() error {
 }
 
 func (s *swapState) reclaimMonero(skA *mcrypto.PrivateSpendKey) error {
+	endStep, err := s.SwapManager().BeginStep(false)
+	if err != nil {
+		return err
+	}
+	defer endStep()
+
 	// write counterparty swap privkey to disk in case something goes wrong
-	err := s.Backend.RecoveryDB().PutCounterpartySwapPrivateKey(s.ID(), skA)
+	err = s.persistCounterpartySwapPrivateKey(skA)
 	if err != nil {
 		return err
 	}
@@ -443,6 +630,19 @@ func (s *swapState) reclaimMonero(skA *mcrypto.PrivateSpendKey) error {
 		s.xmrtakerPrivateViewKey, s.privkeys.ViewKey(),
 	)
 
+	swapWallet, err := s.swapWallet()
+	if err != nil {
+		return err
+	}
+
+	depositAddr := swapWallet.PrimaryAddress()
+	if s.PerSwapAccount() {
+		depositAddr, err = pcommon.SwapAccountAddress(s.Backend, s.info)
+		if err != nil {
+			return err
+		}
+	}
+
 	return pcommon.ClaimMonero(
 		s.ctx,
 		s.Env(),
@@ -450,9 +650,179 @@ func (s *swapState) reclaimMonero(skA *mcrypto.PrivateSpendKey) error {
 		s.XMRClient(),
 		s.moneroStartHeight,
 		kpAB,
-		s.XMRClient().PrimaryAddress(),
-		false, // always sweep back to our primary address
+		depositAddr,
+		false, // always sweep back to the wallet we locked from
+		s.SweepConfirmations(),
+	)
+}
+
+// persistCounterpartySwapPrivateKey writes the counterparty's swap private key to
+// the db. If that fails and reclaimOnKeyPersistFailure is set, the write is
+// retried, and if it still fails, the key is written to a backup file instead.
+// An error is only returned if the caller should not continue with the reclaim.
+func (s *swapState) persistCounterpartySwapPrivateKey(skA *mcrypto.PrivateSpendKey) error {
+	const (
+		maxRetries = 3
+		retryDelay = time.Second
+	)
+
+	err := s.Backend.RecoveryDB().PutCounterpartySwapPrivateKey(s.ID(), skA)
+	if err == nil {
+		return nil
+	}
+
+	if !s.reclaimOnKeyPersistFailure {
+		return err
+	}
+
+	log.Errorf("!!! failed to store counterparty swap key for swap %s, reclaiming XMR anyway: %s", s.ID(), err)
+
+	for i := 0; i < maxRetries; i++ {
+		if err = common.SleepWithContext(s.ctx, retryDelay); err != nil {
+			break
+		}
+
+		err = s.Backend.RecoveryDB().PutCounterpartySwapPrivateKey(s.ID(), skA)
+		if err == nil {
+			log.Infof("stored counterparty swap key for swap %s after %d retries", s.ID(), i+1)
+			return nil
+		}
+
+		log.Warnf("retry %d storing counterparty swap key for swap %s failed: %s", i+1, s.ID(), err)
+	}
+
+	backupPath, err := writeKeyBackup(s.keyBackupDir, s.ID(), skA)
+	if err != nil {
+		log.Errorf("!!! failed to write counterparty swap key backup for swap %s: %s", s.ID(), err)
+		return nil
+	}
+
+	log.Errorf("!!! counterparty swap key for swap %s was written to %s", s.ID(), backupPath)
+	return nil
+}
+
+// writeKeyBackup writes the counterparty's swap private key for the swap to a
+// file in dir, returning the file's path.
+func writeKeyBackup(dir string, id types.Hash, skA *mcrypto.PrivateSpendKey) (string, error) {
+	if err := common.MakeDir(dir); err != nil {
+		return "", err
+	}
+
+	backupPath := path.Join(dir, fmt.Sprintf("counterparty-swap-key-%s", id))
+	if err := os.WriteFile(backupPath, []byte(skA.Hex()), 0600); err != nil {
+		return "", err
+	}
+
+	return backupPath, nil
+}
+
+// swapWallet returns the wallet that XMR is locked from and reclaimed to. This
+// is our primary wallet, unless per-swap wallets are enabled, in which case it's
+// a wallet named by the swap ID that is opened (or created) on first use.
+func (s *swapState) swapWallet() (monero.WalletClient, error) {
+	if !s.perSwapWallet {
+		return s.XMRClient(), nil
+	}
+
+	if s.swapWalletCli != nil {
+		return s.swapWalletCli, nil
+	}
+
+	conf := s.XMRClient().WalletConf(swapWalletName(s.ID()))
+	c, err := monero.NewWalletClient(conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open swap wallet %s: %w", conf.WalletFilePath, err)
+	}
+
+	s.swapWalletCli = c
+	return c, nil
+}
+
+// fundSwapWallet transfers the XMR to lock, plus the fee of the lock, from our
+// primary wallet to the swap's dedicated wallet, which is created empty, and
+// waits until it's spendable. A swap wallet whose balance already covers the
+// lock, eg. as it was funded before a restart, isn't funded again. If our
+// primary wallet can't cover the funding, the error wraps
+// errXMRLockInsufficientFunds, as nothing was sent and the swap can be aborted.
+func (s *swapState) fundSwapWallet(
+	ctx context.Context,
+	swapWallet monero.WalletClient,
+	lockAddr *mcrypto.Address,
+	amount *coins.PiconeroAmount,
+	priority types.MoneroTxPriority,
+) error {
+	// the lock from the swap wallet spends the single output that we fund it
+	// with, so its fee is at most that of the lock from our primary wallet
+	fee, err := s.XMRClient().EstimateTransferFee(lockAddr, 0, amount, priority)
+	if errors.Is(err, monero.ErrInsufficientFunds) {
+		return fmt.Errorf("%w: %s", errXMRLockInsufficientFunds, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	lockAmount, err := amount.Uint64()
+	if err != nil {
+		return err
+	}
+
+	lockFee, err := fee.Uint64()
+	if err != nil {
+		return err
+	}
+
+	balance, err := swapWallet.GetBalance(0)
+	if err != nil {
+		return err
+	}
+
+	if balance.Balance >= lockAmount+lockFee {
+		return nil
+	}
+
+	fundAmount := coins.NewPiconeroAmount(lockAmount + lockFee - balance.Balance)
+	log.Infof("Funding swap wallet %s with %s XMR", swapWallet.PrimaryAddress(), fundAmount.AsMoneroString())
+	_, err = s.XMRClient().Transfer(
+		ctx,
+		swapWallet.PrimaryAddress(),
+		0,
+		fundAmount,
+		monero.MinSpendConfirmations,
+		priority,
 	)
+	if errors.Is(err, monero.ErrInsufficientFunds) {
+		// the wallet didn't send anything, so the swap can be aborted
+		return fmt.Errorf("%w: %s", errXMRLockInsufficientFunds, err)
+	}
+	return err
+}
+
+// closeSwapWallet closes the per-swap wallet if it was opened. The wallet files
+// are only removed if the wallet is empty, as its keys aren't stored elsewhere.
+func (s *swapState) closeSwapWallet() {
+	if s.swapWalletCli == nil {
+		return
+	}
+
+	c := s.swapWalletCli
+	s.swapWalletCli = nil
+
+	balance, err := c.GetBalance(0)
+	if err != nil || balance.Balance != 0 {
+		if err == nil {
+			log.Infof("Swap wallet %s holds %s XMR, keeping it", c.PrimaryAddress(),
+				coins.FmtPiconeroAsXMR(balance.Balance))
+		}
+		c.Close()
+		return
+	}
+
+	c.CloseAndRemoveWallet()
+}
+
+// swapWalletName returns the name of the dedicated wallet file for a swap.
+func swapWalletName(id types.Hash) string {
+	return fmt.Sprintf("swap-wallet-%s", id)
 }
 
 // generateKeys generates XMRMaker's spend and view keys (s_b, v_b)
@@ -463,9 +833,13 @@ func (s *swapState) generateAndSetKeys() error {
 		panic("generateAndSetKeys should only be called once")
 	}
 
-	keysAndProof, err := generateKeys()
-	if err != nil {
-		return err
+	keysAndProof, ok := s.keyPool.Get()
+	if !ok {
+		var err error
+		keysAndProof, err = generateKeysWithRetries(s.keyGenRetries)
+		if err != nil {
+			return err
+		}
 	}
 
 	s.dleqProof = keysAndProof.DLEqProof
@@ -476,8 +850,26 @@ func (s *swapState) generateAndSetKeys() error {
 	return s.Backend.RecoveryDB().PutSwapPrivateKey(s.ID(), s.privkeys.SpendKey())
 }
 
-func generateKeys() (*pcommon.KeysAndProof, error) {
-	return pcommon.GenerateKeysAndProof()
+// generateKeys is a variable so tests can simulate key generation failures.
+var generateKeys = func() (*pcommon.KeysAndProof, error) {
+	return pcommon.GenerateKeysAndProof(dleq.DefaultProofScheme)
+}
+
+// generateKeysWithRetries generates our swap keys and DLEq proof, retrying up
+// to the passed number of times if generation fails.
+func generateKeysWithRetries(retries uint) (*pcommon.KeysAndProof, error) {
+	var err error
+	for i := uint(0); i <= retries; i++ {
+		var keysAndProof *pcommon.KeysAndProof
+		keysAndProof, err = generateKeys()
+		if err == nil {
+			return keysAndProof, nil
+		}
+
+		log.Warnf("failed to generate swap keys (attempt %d of %d): %s", i+1, retries+1, err)
+	}
+
+	return nil, fmt.Errorf("%w: %s", errKeyGenerationFailed, err)
 }
 
 // getSecret secrets returns the current secret scalar used to unlock funds from the contract.
@@ -522,11 +914,40 @@ func (s *swapState) setContract(address ethcommon.Address) error {
 // (S_a + S_b), viewable with (V_a + V_b)
 // It accepts the amount to lock as the input
 func (s *swapState) lockFunds(amount *coins.PiconeroAmount) error {
+	endStep, err := s.SwapManager().BeginStep(true)
+	if err != nil {
+		return err
+	}
+	defer endStep()
+
 	xmrtakerPublicKeys := mcrypto.NewPublicKeyPair(s.xmrtakerPublicSpendKey, s.xmrtakerPrivateViewKey.Public())
 	swapDestAddr := mcrypto.SumSpendAndViewKeys(xmrtakerPublicKeys, s.pubkeys).Address(s.Env())
 	log.Infof("going to lock XMR funds, amount=%s XMR", amount.AsMoneroString())
 
-	balance, err := s.XMRClient().GetBalance(0)
+	swapWallet, err := s.swapWallet()
+	if err != nil {
+		return err
+	}
+
+	// the lock needs to be confirmed before t0, otherwise the counterparty may
+	// refund before seeing it
+	deadline := s.t0.Add(-s.xmrLockMargin)
+	if time.Until(deadline) <= 0 {
+		return fmt.Errorf("%w: deadline was %s", errXMRLockTooLate, deadline.Format(common.TimeFmtSecs))
+	}
+
+	lockCtx, lockCtxCancel := context.WithDeadline(s.ctx, deadline)
+	defer lockCtxCancel()
+
+	priority := s.lockPriority(s.offerExtra)
+
+	if s.perSwapWallet {
+		if err = s.fundSwapWallet(lockCtx, swapWallet, swapDestAddr, amount, priority); err != nil {
+			return fmt.Errorf("failed to fund swap wallet %s: %w", swapWallet.PrimaryAddress(), err)
+		}
+	}
+
+	balance, err := swapWallet.GetBalance(0)
 	if err != nil {
 		return err
 	}
@@ -534,14 +955,59 @@ func (s *swapState) lockFunds(amount *coins.PiconeroAmount) error {
 	log.Debug("total XMR balance: ", coins.FmtPiconeroAsXMR(balance.Balance))
 	log.Info("unlocked XMR balance: ", coins.FmtPiconeroAsXMR(balance.UnlockedBalance))
 
+	// the balance may have been spent or reserved by other swaps since we
+	// accepted this one
+	if amount.CmpU64(balance.UnlockedBalance) > 0 {
+		return fmt.Errorf("%w: unlocked balance is %s XMR", errXMRLockInsufficientFunds,
+			coins.FmtPiconeroAsXMR(balance.UnlockedBalance))
+	}
+
+	// if we exit after this point, the XMR may have been sent, so recovery must
+	// not attempt to lock it again
+	if err = s.RecoveryDB().PutXMRLockStarted(s.ID()); err != nil {
+		return err
+	}
+
 	log.Infof("Starting lock of %s XMR in address %s", amount.AsMoneroString(), swapDestAddr)
-	transfer, err := s.XMRClient().Transfer(s.ctx, swapDestAddr, 0, amount, monero.MinSpendConfirmations)
+	transfer, err := swapWallet.Transfer(lockCtx, swapDestAddr, 0, amount, monero.MinSpendConfirmations, priority)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && s.ctx.Err() == nil {
+			// The transfer was sent, but didn't confirm in time. We treat the
+			// funds as locked so that we wait for the counterparty to refund,
+			// which lets us reclaim the XMR, instead of claiming at t0.
+			s.fundsLocked = true
+			return fmt.Errorf("%w: deadline was %s, waiting for counterparty to refund",
+				errXMRLockTimeout, deadline.Format(common.TimeFmtSecs))
+		}
+		if errors.Is(err, monero.ErrRPCTimeout) {
+			// monero-wallet-rpc didn't answer in time, so we can't tell whether
+			// the transfer went out. As above, we wait for the counterparty to
+			// refund instead of risking locking again or claiming at t0.
+			s.fundsLocked = true
+			return fmt.Errorf("%w: XMR lock may have been sent, waiting for counterparty to refund", err)
+		}
+		if errors.Is(err, monero.ErrInsufficientFunds) {
+			// the wallet didn't send anything, so the swap can be aborted
+			return fmt.Errorf("%w: %s", errXMRLockInsufficientFunds, err)
+		}
 		return err
 	}
 
 	log.Infof("Successfully locked XMR funds: txID=%s address=%s block=%d",
 		transfer.TxID, swapDestAddr, transfer.Height)
 	s.fundsLocked = true
+	s.reservations.release(s.offer.ID)
+
+	// the taker verifies the lock independently, so failing to notify them
+	// only delays the swap until their next check
+	notify := &message.NotifyXMRLocked{
+		TxID:    transfer.TxID,
+		Address: swapDestAddr,
+		Height:  transfer.Height,
+	}
+	if err = s.SendSwapMessage(notify, s.ID()); err != nil {
+		log.Warnf("failed to notify counterparty of XMR lock: %s", err)
+	}
+
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/db"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/tests"
@@ -51,6 +52,7 @@ func TestSwapStateOngoing_ClaimFunds(t *testing.T) {
 		ethSwapInfo,
 		swapState.info,
 		swapState.privkeys,
+		swapState.swapOptions,
 	)
 	require.NoError(t, err)
 
@@ -128,6 +130,7 @@ func TestSwapStateOngoing_Refund(t *testing.T) {
 		ethSwapInfo,
 		s.info,
 		s.privkeys,
+		s.swapOptions,
 	)
 	require.NoError(t, err)
 
@@ -139,3 +142,113 @@ func TestSwapStateOngoing_Refund(t *testing.T) {
 
 	require.Equal(t, types.CompletedRefund, ss.info.Status)
 }
+
+func TestSwapStateOngoing_ResumeETHLocked_NotPending(t *testing.T) {
+	inst, s, offerDB := newTestSwapStateAndDB(t)
+	offerDB.EXPECT().PutOffer(s.offer)
+
+	xmrtakerKeysAndProof, err := generateKeys()
+	require.NoError(t, err)
+
+	startNum, err := s.ETHClient().Raw().BlockNumber(s.Backend.Ctx())
+	require.NoError(t, err)
+	s.cancel()
+
+	// the swap was never created on-chain, so the contract stage is invalid
+	now := time.Now()
+	ethSwapInfo := &db.EthereumSwapInfo{
+		StartNumber: big.NewInt(int64(startNum)),
+		SwapID:      types.Hash{1},
+		Swap: &contracts.SwapFactorySwap{
+			Timeout0: big.NewInt(now.Add(time.Hour).Unix()),
+			Timeout1: big.NewInt(now.Add(2 * time.Hour).Unix()),
+		},
+		ContractAddress: s.Backend.ContractAddr(),
+	}
+
+	s.info.Status = types.KeysExchanged
+	rdb := inst.backend.RecoveryDB().(*backend.MockRecoveryDB)
+	rdb.EXPECT().GetCounterpartySwapKeys(s.ID()).Return(
+		xmrtakerKeysAndProof.PublicKeyPair.SpendKey(),
+		xmrtakerKeysAndProof.PrivateKeyPair.ViewKey(),
+		nil,
+	)
+	rdb.EXPECT().HasXMRLockStarted(s.ID()).Return(false, nil)
+
+	ss, err := newSwapStateFromOngoing(
+		s.Backend,
+		s.offer,
+		s.offerExtra,
+		s.offerManager,
+		ethSwapInfo,
+		s.info,
+		s.privkeys,
+		s.swapOptions,
+	)
+	require.NoError(t, err)
+
+	select {
+	case <-ss.done:
+	case <-time.After(time.Second * 10):
+		t.Fatal("test timed out")
+	}
+
+	require.Equal(t, types.CompletedAbort, ss.info.Status)
+	require.False(t, ss.fundsLocked)
+}
+
+func TestSwapStateOngoing_ResumeETHLocked_LockNotReceived(t *testing.T) {
+	inst, s, offerDB := newTestSwapStateAndDB(t)
+	offerDB.EXPECT().PutOffer(s.offer)
+
+	xmrtakerKeysAndProof, err := generateKeys()
+	require.NoError(t, err)
+
+	startNum, err := s.ETHClient().Raw().BlockNumber(s.Backend.Ctx())
+	require.NoError(t, err)
+	s.cancel()
+
+	now := time.Now()
+	ethSwapInfo := &db.EthereumSwapInfo{
+		StartNumber: big.NewInt(int64(startNum)),
+		SwapID:      types.Hash{1},
+		Swap: &contracts.SwapFactorySwap{
+			Timeout0: big.NewInt(now.Add(time.Hour).Unix()),
+			Timeout1: big.NewInt(now.Add(2 * time.Hour).Unix()),
+		},
+		ContractAddress: s.Backend.ContractAddr(),
+	}
+
+	// we exited after starting the XMR lock, but nothing reached the swap's
+	// lock address, so the swap is aborted instead of treating it as locked
+	s.info.Status = types.KeysExchanged
+	rdb := inst.backend.RecoveryDB().(*backend.MockRecoveryDB)
+	rdb.EXPECT().GetCounterpartySwapKeys(s.ID()).Return(
+		xmrtakerKeysAndProof.PublicKeyPair.SpendKey(),
+		xmrtakerKeysAndProof.PrivateKeyPair.ViewKey(),
+		nil,
+	)
+	rdb.EXPECT().HasXMRLockStarted(s.ID()).Return(true, nil)
+
+	ss, err := newSwapStateFromOngoing(
+		s.Backend,
+		s.offer,
+		s.offerExtra,
+		s.offerManager,
+		ethSwapInfo,
+		s.info,
+		s.privkeys,
+		s.swapOptions,
+	)
+	require.NoError(t, err)
+
+	select {
+	case <-ss.done:
+	case <-time.After(time.Minute):
+		t.Fatal("test timed out")
+	}
+
+	require.Equal(t, types.CompletedAbort, ss.info.Status)
+	require.False(t, ss.fundsLocked)
+	require.Contains(t, ss.info.FailureError, errXMRLockNotReceived.Error())
+}
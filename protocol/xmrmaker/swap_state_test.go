@@ -2,7 +2,10 @@ package xmrmaker
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
+	"os"
+	"path"
 	"testing"
 	"time"
 
@@ -11,10 +14,14 @@ import (
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/dleq"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker/offers"
 	"github.com/athanorlabs/atomic-swap/tests"
 
@@ -35,11 +42,13 @@ func newTestSwapStateAndDB(t *testing.T) (*Instance, *swapState, *offers.MockDat
 
 	swapState, err := newSwapStateFromStart(
 		xmrmaker.backend,
+		"",
 		types.NewOffer("", new(apd.Decimal), new(apd.Decimal), new(coins.ExchangeRate), types.EthAssetETH),
 		&types.OfferExtra{},
 		xmrmaker.offerManager,
 		coins.MoneroToPiconero(coins.StrToDecimal("0.05")),
 		desiredAmount,
+		xmrmaker.swapOptions,
 	)
 	require.NoError(t, err)
 	return xmrmaker, swapState, db
@@ -51,7 +60,7 @@ func newTestSwapState(t *testing.T) (*Instance, *swapState) {
 }
 
 func newTestXMRTakerSendKeysMessage(t *testing.T) (*message.SendKeysMessage, *pcommon.KeysAndProof) {
-	keysAndProof, err := pcommon.GenerateKeysAndProof()
+	keysAndProof, err := pcommon.GenerateKeysAndProof(dleq.DefaultProofScheme)
 	require.NoError(t, err)
 
 	msg := &message.SendKeysMessage{
@@ -109,6 +118,7 @@ func newSwap(
 	}
 
 	ss.setTimeouts(t0, t1)
+	require.NoError(t, ss.setContract(ss.ContractAddr()))
 	return tx.Hash()
 }
 
@@ -119,6 +129,59 @@ func TestNewSwapState_generateAndSetKeys(t *testing.T) {
 	require.NotNil(t, swapState.dleqProof)
 }
 
+func TestNewSwapStateFromStart_keyGenRetry(t *testing.T) {
+	inst, offerDB := newTestInstanceAndDB(t)
+
+	origGenerateKeys := generateKeys
+	failures := 0
+	generateKeys = func() (*pcommon.KeysAndProof, error) {
+		failures++
+		if failures <= int(inst.keyGenRetries) {
+			return nil, errors.New("transient failure")
+		}
+		return pcommon.GenerateKeysAndProof(dleq.DefaultProofScheme)
+	}
+	t.Cleanup(func() { generateKeys = origGenerateKeys })
+
+	one := apd.New(1, 0)
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	offerDB.EXPECT().PutOffer(offer).Return(nil)
+	offerExtra, err := inst.offerManager.AddOffer(offer, false)
+	require.NoError(t, err)
+
+	ss, err := inst.initiate("", offer, offerExtra, coins.MoneroToPiconero(one), desiredAmount)
+	require.NoError(t, err)
+	require.NotNil(t, ss.privkeys)
+}
+
+func TestNewSwapStateFromStart_keyGenFailure(t *testing.T) {
+	inst, offerDB := newTestInstanceAndDB(t)
+
+	origGenerateKeys := generateKeys
+	generateKeys = func() (*pcommon.KeysAndProof, error) {
+		return nil, errors.New("permanent failure")
+	}
+	t.Cleanup(func() { generateKeys = origGenerateKeys })
+
+	one := apd.New(1, 0)
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	offerDB.EXPECT().PutOffer(offer).Return(nil).Times(2)
+	offerExtra, err := inst.offerManager.AddOffer(offer, false)
+	require.NoError(t, err)
+
+	_, err = inst.initiate("", offer, offerExtra, coins.MoneroToPiconero(one), desiredAmount)
+	require.ErrorIs(t, err, errKeyGenerationFailed)
+
+	// the swap was aborted and the offer can be taken again
+	info, err := inst.backend.SwapManager().GetPastSwap(offer.ID)
+	require.NoError(t, err)
+	require.Equal(t, types.CompletedAbort, info.Status)
+
+	_, _, err = inst.offerManager.GetOffer(offer.ID)
+	require.NoError(t, err)
+	require.Nil(t, inst.swapStates[offer.ID])
+}
+
 func TestSwapState_ClaimFunds(t *testing.T) {
 	_, swapState := newTestSwapState(t)
 
@@ -138,6 +201,32 @@ func TestSwapState_ClaimFunds(t *testing.T) {
 	require.True(t, swapState.info.Status.IsOngoing())
 }
 
+func TestSwapState_findExistingClaim(t *testing.T) {
+	_, swapState := newTestSwapState(t)
+
+	claimKey := swapState.secp256k1Pub.Keccak256()
+	newSwap(t, swapState, claimKey,
+		[32]byte{}, big.NewInt(33), defaultTimeoutDuration)
+
+	txOpts, err := swapState.ETHClient().TxOpts(swapState.ctx)
+	require.NoError(t, err)
+	tx, err := swapState.Contract().SetReady(txOpts, *swapState.contractSwap)
+	require.NoError(t, err)
+	tests.MineTransaction(t, swapState.ETHClient().Raw(), tx)
+
+	_, claimed, err := swapState.findExistingClaim()
+	require.NoError(t, err)
+	require.False(t, claimed)
+
+	txHash, err := swapState.claimFunds()
+	require.NoError(t, err)
+
+	existingTxHash, claimed, err := swapState.findExistingClaim()
+	require.NoError(t, err)
+	require.True(t, claimed)
+	require.Equal(t, txHash, existingTxHash)
+}
+
 func TestSwapState_handleSendKeysMessage(t *testing.T) {
 	_, s := newTestSwapState(t)
 
@@ -352,14 +441,62 @@ func TestSwapState_Exit_Aborted(t *testing.T) {
 	require.Equal(t, types.CompletedAbort, s.info.Status)
 }
 
-func TestSwapState_Exit_Aborted_1(t *testing.T) {
+func TestSwapState_Exit_Aborted_withCause(t *testing.T) {
 	_, s, db := newTestSwapStateAndDB(t)
 	db.EXPECT().PutOffer(s.offer)
 
-	s.nextExpectedEvent = EventETHRefundedType
-	err := s.Exit()
-	require.True(t, errors.Is(err, errUnexpectedMessageType))
+	cause := fmt.Errorf("%w: balance too low", pswap.ErrInsufficientXMRBalance)
+	s.nextExpectedEvent = EventETHLockedType
+	err := s.exitWithCause(cause)
+	require.NoError(t, err)
 	require.Equal(t, types.CompletedAbort, s.info.Status)
+	require.Equal(t, pswap.FailureInsufficientXMRBalance, s.info.FailureReason)
+	require.Equal(t, cause.Error(), s.info.FailureError)
+}
+
+// test that if our XMR lock missed its deadline, exiting waits for XMRTaker to
+// refund, so that XMRMaker can reclaim his monero
+func TestSwapState_Exit_Reclaim_LockTimedOut(t *testing.T) {
+	_, s, db := newTestSwapStateAndDB(t)
+	db.EXPECT().PutOffer(s.offer)
+
+	xmrtakerKeysAndProof, err := generateKeys()
+	require.NoError(t, err)
+	err = s.setXMRTakerKeys(
+		xmrtakerKeysAndProof.PublicKeyPair.SpendKey(),
+		xmrtakerKeysAndProof.PrivateKeyPair.ViewKey(),
+		xmrtakerKeysAndProof.Secp256k1PublicKey,
+	)
+	require.NoError(t, err)
+
+	refundKey := xmrtakerKeysAndProof.Secp256k1PublicKey.Keccak256()
+	newSwap(t, s, [32]byte{}, refundKey, desiredAmount.BigInt(), defaultTimeoutDuration)
+
+	err = s.lockFunds(coins.MoneroToPiconero(s.info.ProvidedAmount))
+	require.NoError(t, err)
+
+	// the state that handling EventETHLocked leaves us in when the lock
+	// doesn't confirm before its deadline
+	s.nextExpectedEvent = EventETHRefundedType
+	require.Equal(t, types.XMRLocked, s.nextExpectedEvent.getStatus())
+
+	exitErrCh := make(chan error)
+	go func() {
+		exitErrCh <- s.Exit()
+	}()
+
+	secret := xmrtakerKeysAndProof.DLEqProof.Secret()
+	var sc [32]byte
+	copy(sc[:], secret[:])
+
+	txOpts, err := s.ETHClient().TxOpts(s.ctx)
+	require.NoError(t, err)
+	tx, err := s.Contract().Refund(txOpts, *s.contractSwap, sc)
+	require.NoError(t, err)
+	tests.MineTransaction(t, s.ETHClient().Raw(), tx)
+
+	require.NoError(t, <-exitErrCh)
+	require.Equal(t, types.CompletedRefund, s.info.Status)
 }
 
 func TestSwapState_Exit_Success(t *testing.T) {
@@ -389,7 +526,7 @@ func TestSwapState_Exit_Refunded(t *testing.T) {
 	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
 	s.offer = types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
 	db.EXPECT().PutOffer(s.offer)
-	_, err := b.MakeOffer(s.offer, false)
+	_, _, err := b.MakeOffer(s.offer, false)
 	require.NoError(t, err)
 
 	s.info.SetStatus(types.CompletedRefund)
@@ -402,3 +539,137 @@ func TestSwapState_Exit_Refunded(t *testing.T) {
 	require.NotNil(t, o)
 	require.NotNil(t, oe)
 }
+
+func TestSwapState_lockFunds_TooLate(t *testing.T) {
+	_, s := newTestSwapState(t)
+
+	xmrtakerKeysAndProof, err := generateKeys()
+	require.NoError(t, err)
+	err = s.setXMRTakerKeys(
+		xmrtakerKeysAndProof.PublicKeyPair.SpendKey(),
+		xmrtakerKeysAndProof.PrivateKeyPair.ViewKey(),
+		xmrtakerKeysAndProof.Secp256k1PublicKey,
+	)
+	require.NoError(t, err)
+
+	refundKey := xmrtakerKeysAndProof.Secp256k1PublicKey.Keccak256()
+	newSwap(t, s, [32]byte{}, refundKey, desiredAmount.BigInt(), defaultTimeoutDuration)
+
+	// the lock can't confirm before t0 with a margin longer than the timeout
+	s.xmrLockMargin = defaultTimeoutDuration * 2
+	err = s.lockFunds(coins.MoneroToPiconero(s.info.ProvidedAmount))
+	require.ErrorIs(t, err, errXMRLockTooLate)
+	require.False(t, s.fundsLocked)
+}
+
+func TestSwapState_lockFunds_perSwapWallet(t *testing.T) {
+	_, s, db := newTestSwapStateAndDB(t)
+	db.EXPECT().PutOffer(s.offer)
+	s.perSwapWallet = true
+
+	xmrtakerKeysAndProof, err := generateKeys()
+	require.NoError(t, err)
+	err = s.setXMRTakerKeys(
+		xmrtakerKeysAndProof.PublicKeyPair.SpendKey(),
+		xmrtakerKeysAndProof.PrivateKeyPair.ViewKey(),
+		xmrtakerKeysAndProof.Secp256k1PublicKey,
+	)
+	require.NoError(t, err)
+
+	refundKey := xmrtakerKeysAndProof.Secp256k1PublicKey.Keccak256()
+	newSwap(t, s, [32]byte{}, refundKey, desiredAmount.BigInt(), 10*time.Minute)
+
+	// the swap wallet is created empty, so it's funded from the primary
+	// wallet before the lock
+	err = s.lockFunds(coins.MoneroToPiconero(s.info.ProvidedAmount))
+	require.NoError(t, err)
+	require.True(t, s.fundsLocked)
+
+	swapWallet, err := s.swapWallet()
+	require.NoError(t, err)
+	require.NotEqual(t, s.XMRClient().PrimaryAddress(), swapWallet.PrimaryAddress())
+	s.closeSwapWallet()
+}
+
+func TestSwapState_lockFunds_perSwapWallet_InsufficientBalance(t *testing.T) {
+	_, s, db := newTestSwapStateAndDB(t)
+	db.EXPECT().PutOffer(s.offer)
+	s.perSwapWallet = true
+
+	xmrtakerKeysAndProof, err := generateKeys()
+	require.NoError(t, err)
+	err = s.setXMRTakerKeys(
+		xmrtakerKeysAndProof.PublicKeyPair.SpendKey(),
+		xmrtakerKeysAndProof.PrivateKeyPair.ViewKey(),
+		xmrtakerKeysAndProof.Secp256k1PublicKey,
+	)
+	require.NoError(t, err)
+
+	refundKey := xmrtakerKeysAndProof.Secp256k1PublicKey.Keccak256()
+	newSwap(t, s, [32]byte{}, refundKey, desiredAmount.BigInt(), 10*time.Minute)
+
+	// our primary wallet can't fund the swap wallet, so nothing is sent and
+	// the swap can be aborted
+	balance, err := s.XMRClient().GetBalance(0)
+	require.NoError(t, err)
+	err = s.lockFunds(coins.NewPiconeroAmount(balance.UnlockedBalance + 1))
+	require.ErrorIs(t, err, errXMRLockInsufficientFunds)
+	require.ErrorIs(t, err, pswap.ErrInsufficientXMRBalance)
+	require.False(t, s.fundsLocked)
+	s.closeSwapWallet()
+}
+
+func TestSwapState_lockFunds_InsufficientBalance(t *testing.T) {
+	inst, s, db := newTestSwapStateAndDB(t)
+	db.EXPECT().PutOffer(s.offer)
+	rdb := inst.backend.RecoveryDB().(*backend.MockRecoveryDB)
+	rdb.EXPECT().HasXMRLockStarted(s.ID()).Return(false, nil)
+
+	xmrtakerKeysAndProof, err := generateKeys()
+	require.NoError(t, err)
+	err = s.setXMRTakerKeys(
+		xmrtakerKeysAndProof.PublicKeyPair.SpendKey(),
+		xmrtakerKeysAndProof.PrivateKeyPair.ViewKey(),
+		xmrtakerKeysAndProof.Secp256k1PublicKey,
+	)
+	require.NoError(t, err)
+
+	refundKey := xmrtakerKeysAndProof.Secp256k1PublicKey.Keccak256()
+	newSwap(t, s, [32]byte{}, refundKey, desiredAmount.BigInt(), defaultTimeoutDuration)
+
+	// simulate our unlocked balance dropping below the swap amount after the
+	// swap was accepted, like when other swaps lock XMR first
+	balance, err := s.XMRClient().GetBalance(0)
+	require.NoError(t, err)
+	s.info.ProvidedAmount = coins.NewPiconeroAmount(balance.UnlockedBalance + 1).AsMonero()
+
+	event := newEventETHLocked(nil)
+	s.eventCh <- event
+	err = <-event.errCh
+	require.ErrorIs(t, err, errXMRLockInsufficientFunds)
+	require.False(t, s.fundsLocked)
+	require.Equal(t, types.CompletedAbort, s.info.Status)
+	require.Equal(t, pswap.FailureInsufficientXMRBalance, s.info.FailureReason)
+
+	// the network exits the swap when the stream closes
+	require.NoError(t, s.Exit())
+	require.Equal(t, types.CompletedAbort, s.info.Status)
+}
+
+func TestWriteKeyBackup(t *testing.T) {
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+
+	id := types.Hash{1}
+	dir := path.Join(t.TempDir(), "key-backups")
+	backupPath, err := writeKeyBackup(dir, id, kp.SpendKey())
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(backupPath)
+	require.NoError(t, err)
+	require.Equal(t, kp.SpendKey().Hex(), string(data))
+
+	info, err := os.Stat(backupPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
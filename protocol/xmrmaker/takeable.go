@@ -0,0 +1,180 @@
+package xmrmaker
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// maxTakeableFeeEstimates is how many lock fee estimates are made for one
+// query of takeable ranges, as each estimate is a wallet RPC call. The ranges of
+// the remaining offers use the highest estimate made, as the network fee of a
+// lock barely depends on its amount, and the highest priority's fee covers the
+// others.
+const maxTakeableFeeEstimates = 4
+
+// TakeableRanges returns the range of XMR amounts of each passed offer that we
+// would accept right now. This composes the checks made when an offer is taken:
+// our swap capacity, and whether our unlocked balance, less the XMR reserved by
+// other takes, covers the amount plus the network fee of locking it. Nil is
+// returned if our balance can't be read, in which case takers fall back to the
+// offers' static ranges.
+func (inst *Instance) TakeableRanges(offers []*types.Offer) []*types.TakeableRange {
+	ranges := make([]*types.TakeableRange, 0, len(offers))
+
+	inst.swapMu.Lock()
+	numSwaps := uint(len(inst.swapStates))
+	inst.swapMu.Unlock()
+
+	if inst.maxConcurrentSwaps > 0 && numSwaps >= inst.maxConcurrentSwaps {
+		reason := errMakerAtCapacity{inst.maxConcurrentSwaps}.Error()
+		for _, o := range offers {
+			ranges = append(ranges, &types.TakeableRange{OfferID: o.ID, Reason: reason})
+		}
+		return ranges
+	}
+
+	balance, err := inst.backend.XMRClient().GetBalance(0)
+	if err != nil {
+		log.Warnf("failed to get balance for takeable offer ranges: %s", err)
+		return nil
+	}
+
+	// XMR reserved for takes that haven't locked yet isn't available either
+	reserved, err := inst.reservations.total()
+	if err != nil {
+		log.Warnf("failed to get reserved XMR for takeable offer ranges: %s", err)
+		return nil
+	}
+
+	unlockedBalance := coins.NewPiconeroAmount(balance.UnlockedBalance).AsMonero()
+	if _, err = coins.DecimalCtx().Sub(unlockedBalance, unlockedBalance, reserved.AsMonero()); err != nil {
+		log.Warnf("failed to subtract reserved XMR for takeable offer ranges: %s", err)
+		return nil
+	}
+
+	fees := &lockFeeEstimator{
+		estimate: inst.backend.EstimateXMRTransferFee,
+		fees:     make(map[string]*coins.PiconeroAmount),
+	}
+
+	for _, o := range offers {
+		var extra *types.OfferExtra
+		if _, e, err := inst.offerManager.GetOffer(o.ID); err == nil {
+			extra = e
+		}
+
+		r, err := takeableRange(o, unlockedBalance, fees, inst.lockPriority(extra))
+		if err != nil {
+			log.Warnf("failed to compute takeable range of offer %s: %s", o.ID, err)
+			return nil
+		}
+		ranges = append(ranges, r)
+	}
+
+	return ranges
+}
+
+// lockFeeEstimator estimates the network fees of locking XMR amounts for one
+// query of takeable ranges, making at most maxTakeableFeeEstimates estimates.
+type lockFeeEstimator struct {
+	estimate func(amount *coins.PiconeroAmount, priority types.MoneroTxPriority) (*coins.PiconeroAmount, error)
+	fees     map[string]*coins.PiconeroAmount // amount and priority -> fee
+	highest  *coins.PiconeroAmount
+}
+
+// fee returns the network fee of locking the XMR amount with the priority.
+func (e *lockFeeEstimator) fee(amount *apd.Decimal, priority types.MoneroTxPriority) (*coins.PiconeroAmount, error) {
+	key := amount.Text('f') + "/" + priority.String()
+	if fee, has := e.fees[key]; has {
+		return fee, nil
+	}
+
+	if len(e.fees) >= maxTakeableFeeEstimates {
+		return e.highest, nil
+	}
+
+	fee, err := e.estimate(coins.MoneroToPiconero(amount), priority)
+	if err != nil {
+		return nil, err
+	}
+
+	e.fees[key] = fee
+	if e.highest == nil || fee.Cmp(e.highest) > 0 {
+		e.highest = fee
+	}
+
+	return fee, nil
+}
+
+// takeableRange caps the offer's max amount to what our unlocked balance can
+// lock, after the network fee. The balance must be strictly greater than the
+// amount, so one piconero is held back as well. The fee is estimated with the
+// offer's lock priority, for the largest amount that the balance could lock,
+// and again for the capped amount if the fee lowered it.
+func takeableRange(
+	o *types.Offer,
+	unlockedBalance *apd.Decimal,
+	fees *lockFeeEstimator,
+	priority types.MoneroTxPriority,
+) (*types.TakeableRange, error) {
+	tooLow := &types.TakeableRange{
+		OfferID: o.ID,
+		Reason: fmt.Sprintf("unlocked balance %s XMR is too low for offer minimum of %s XMR",
+			unlockedBalance.Text('f'), o.MinAmount.Text('f')),
+	}
+
+	maxAmount := o.MaxAmount
+	if unlockedBalance.Cmp(maxAmount) < 0 {
+		maxAmount = unlockedBalance
+	}
+
+	// we can't lock the minimum even without a fee
+	if maxAmount.Cmp(o.MinAmount) < 0 || maxAmount.Sign() <= 0 {
+		return tooLow, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		fee, err := fees.fee(maxAmount, priority)
+		if err != nil {
+			return nil, err
+		}
+
+		available, err := availableAfterFee(unlockedBalance, fee)
+		if err != nil {
+			return nil, err
+		}
+
+		if available.Cmp(o.MinAmount) < 0 {
+			return tooLow, nil
+		}
+
+		if available.Cmp(maxAmount) >= 0 {
+			break
+		}
+		maxAmount = available
+	}
+
+	return &types.TakeableRange{
+		OfferID:   o.ID,
+		MinAmount: o.MinAmount,
+		MaxAmount: maxAmount,
+	}, nil
+}
+
+// availableAfterFee returns how much of the unlocked balance can be locked
+// after paying the fee, holding back one piconero.
+func availableAfterFee(unlockedBalance *apd.Decimal, fee *coins.PiconeroAmount) (*apd.Decimal, error) {
+	available := new(apd.Decimal)
+	ctx := coins.DecimalCtx()
+	if _, err := ctx.Sub(available, unlockedBalance, fee.AsMonero()); err != nil {
+		return nil, err
+	}
+	if _, err := ctx.Sub(available, available, apd.New(1, -coins.NumMoneroDecimals)); err != nil {
+		return nil, err
+	}
+	return available, nil
+}
@@ -0,0 +1,89 @@
+package xmrmaker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestInstance_TakeableRanges(t *testing.T) {
+	b, _ := newTestInstanceAndDB(t)
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+
+	balance, err := b.backend.XMRClient().GetBalance(0)
+	require.NoError(t, err)
+	unlocked := coins.NewPiconeroAmount(balance.UnlockedBalance).AsMonero()
+
+	small := types.NewOffer(coins.ProvidesXMR, coins.StrToDecimal("0.001"), coins.StrToDecimal("0.002"), rate,
+		types.EthAssetETH)
+	large := types.NewOffer(coins.ProvidesXMR, coins.StrToDecimal("0.001"), coins.StrToDecimal("1000000"), rate,
+		types.EthAssetETH)
+	tooLarge := types.NewOffer(coins.ProvidesXMR, coins.StrToDecimal("999999"), coins.StrToDecimal("1000000"), rate,
+		types.EthAssetETH)
+
+	ranges := b.TakeableRanges([]*types.Offer{small, large, tooLarge})
+	require.Len(t, ranges, 3)
+
+	// the small offer is fully takeable
+	require.Equal(t, small.ID, ranges[0].OfferID)
+	require.Equal(t, small.MinAmount, ranges[0].MinAmount)
+	require.Equal(t, small.MaxAmount, ranges[0].MaxAmount)
+
+	// the large offer is capped below our unlocked balance
+	require.Equal(t, large.ID, ranges[1].OfferID)
+	require.Equal(t, large.MinAmount, ranges[1].MinAmount)
+	require.Negative(t, ranges[1].MaxAmount.Cmp(unlocked))
+
+	// we can't cover the minimum of the last offer
+	require.Nil(t, ranges[2].MaxAmount)
+	require.Contains(t, ranges[2].Reason, "too low")
+
+	// nothing is takeable at capacity
+	b.maxConcurrentSwaps = 1
+	b.swapStates[types.Hash{0x1}] = &swapState{}
+	ranges = b.TakeableRanges([]*types.Offer{small})
+	require.Equal(t, errMakerAtCapacity{1}.Error(), ranges[0].Reason)
+}
+
+func TestTakeableRange_feeAtBound(t *testing.T) {
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	unlocked := coins.StrToDecimal("10")
+
+	var estimatedAt []string
+	fees := &lockFeeEstimator{
+		estimate: func(amount *coins.PiconeroAmount, priority types.MoneroTxPriority) (*coins.PiconeroAmount, error) {
+			require.Equal(t, types.MoneroPriorityElevated, priority)
+			estimatedAt = append(estimatedAt, amount.AsMoneroString())
+			return coins.MoneroToPiconero(coins.StrToDecimal("0.01")), nil
+		},
+		fees: make(map[string]*coins.PiconeroAmount),
+	}
+
+	// the fee is estimated for what our balance can lock, not the offer's max
+	o := types.NewOffer(coins.ProvidesXMR, coins.StrToDecimal("1"), coins.StrToDecimal("1000"), rate,
+		types.EthAssetETH)
+	r, err := takeableRange(o, unlocked, fees, types.MoneroPriorityElevated)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10", "9.989999999999"}, estimatedAt)
+	require.Equal(t, "9.989999999999", r.MaxAmount.Text('f'))
+
+	// estimates are cached, and capped per query
+	for i := 0; i < 2*maxTakeableFeeEstimates; i++ {
+		maxAmount := coins.StrToDecimal(fmt.Sprintf("%d", i+2))
+		o = types.NewOffer(coins.ProvidesXMR, coins.StrToDecimal("1"), maxAmount, rate, types.EthAssetETH)
+		_, err = takeableRange(o, unlocked, fees, types.MoneroPriorityElevated)
+		require.NoError(t, err)
+	}
+	require.Len(t, estimatedAt, maxTakeableFeeEstimates)
+
+	// offers whose minimum is over our balance aren't estimated
+	o = types.NewOffer(coins.ProvidesXMR, coins.StrToDecimal("11"), coins.StrToDecimal("20"), rate,
+		types.EthAssetETH)
+	r, err = takeableRange(o, unlocked, fees, types.MoneroPriorityElevated)
+	require.NoError(t, err)
+	require.Contains(t, r.Reason, "too low")
+}
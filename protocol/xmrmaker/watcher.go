@@ -28,6 +28,10 @@ func (s *swapState) runContractEventWatcher() {
 
 			// there won't be any more events after this
 			return
+		case l := <-s.logRevertedCh:
+			// we act on events as soon as they're seen, so all we can do is
+			// make the reorg visible to the operator
+			log.Warnf("contract event of swap %s in tx %s was reverted by a chain reorg", s.ID(), l.TxHash)
 		}
 	}
 }
@@ -69,6 +73,10 @@ func (s *swapState) handleRefundLogs(ethlog *ethtypes.Log) error {
 		return err
 	}
 
+	// the event handler may be busy claiming with relayers, which can't
+	// succeed anymore, so we stop that first
+	close(s.refundedCh)
+
 	// swap was refunded, send EventRefunded
 	log.Infof("sending EventETHRefunded in s.eventCh")
 	event := newEventETHRefunded(sk)
@@ -35,7 +35,7 @@ func (s *swapState) tryClaim() error {
 
 func (s *swapState) filterForClaim() (*mcrypto.PrivateSpendKey, error) {
 	logs, err := s.ETHClient().Raw().FilterLogs(s.ctx, eth.FilterQuery{
-		Addresses: []ethcommon.Address{s.ContractAddr()},
+		Addresses: []ethcommon.Address{s.contractAddr},
 		Topics:    [][]ethcommon.Hash{{claimedTopic}},
 	})
 	if err != nil {
@@ -81,8 +81,14 @@ func (s *swapState) claimMonero(skB *mcrypto.PrivateSpendKey) (*mcrypto.Address,
 		return nil, errSwapCompleted
 	}
 
+	endStep, err := s.SwapManager().BeginStep(false)
+	if err != nil {
+		return nil, err
+	}
+	defer endStep()
+
 	// write counterparty swap privkey to disk in case something goes wrong
-	err := s.Backend.RecoveryDB().PutCounterpartySwapPrivateKey(s.ID(), skB)
+	err = s.Backend.RecoveryDB().PutCounterpartySwapPrivateKey(s.ID(), skB)
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +97,12 @@ func (s *swapState) claimMonero(skB *mcrypto.PrivateSpendKey) (*mcrypto.Address,
 	depositAddr := s.XMRDepositAddress(&id)
 	if s.noTransferBack {
 		depositAddr = nil
+	} else if s.PerSwapAccount() && depositAddr.Equal(s.XMRClient().PrimaryAddress()) {
+		// the swap has no deposit address of its own
+		depositAddr, err = pcommon.SwapAccountAddress(s.Backend, s.info)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	kpAB := pcommon.GetClaimKeypair(
@@ -107,6 +119,7 @@ func (s *swapState) claimMonero(skB *mcrypto.PrivateSpendKey) (*mcrypto.Address,
 		kpAB,
 		depositAddr,
 		s.noTransferBack,
+		s.SweepConfirmations(),
 	)
 	if err != nil {
 		return nil, err
@@ -3,6 +3,8 @@ package xmrtaker
 import (
 	"errors"
 	"fmt"
+
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
 )
 
 var (
@@ -18,13 +20,17 @@ var (
 	errCannotRefund            = errors.New("swap is not at a stage where it can refund")
 	errRefundInvalid           = errors.New("cannot refund, swap does not exist")
 	errRefundSwapCompleted     = fmt.Errorf("cannot refund, %w", errSwapCompleted)
+	errRefundTooEarly          = errors.New("cannot refund yet, the swap is ready for the counterparty to claim")
 	errCounterpartyKeysNotSet  = errors.New("counterparty's keys aren't set")
 	errSwapInstantiationNoLogs = errors.New("expected 1 log, got 0")
 	errSwapCompleted           = errors.New("swap is already completed")
 
 	// initiation errors
 	errProtocolAlreadyInProgress = errors.New("protocol already in progress")
-	errBalanceTooLow             = errors.New("eth balance lower than amount to be provided")
+	errBalanceTooLow             = fmt.Errorf("%w: eth balance lower than amount to be provided", pswap.ErrInsufficientETHBalance) //nolint:lll
+	errNotETHOffer               = errors.New("offer does not provide ETH")
+	errAmountNotInOfferRange     = errors.New("amount provided by taker is not within the offer's range")
+	errInvalidSwapFactory        = errors.New("offer's swap factory is not a valid SwapFactory contract")
 	errInvalidStageForRecovery   = errors.New("cannot create ongoing swap state if stage is not ETHLocked or ContractReady") //nolint:lll
 )
 
@@ -0,0 +1,76 @@
+package xmrtaker
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net/message"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+)
+
+// HandleTakeETHOffer starts the ETH-providing side of a swap on one of our
+// offers that provide ETH, which a taker providing XMR has initiated with msg.
+// It returns the swap and our keys to reply with. The taker sends its keys
+// again once it has ours, and we lock our ETH when they arrive, so from then on
+// the swap runs like one that we initiated. onDone is called with the swap's
+// final status once it exits.
+func (inst *Instance) HandleTakeETHOffer(
+	takerPeerID peer.ID,
+	offer *types.Offer,
+	msg *message.SendKeysMessage,
+	onDone func(types.Status),
+) (common.SwapState, common.Message, error) {
+	if offer.Provides != coins.ProvidesETH {
+		return nil, nil, errNotETHOffer
+	}
+
+	// the taker provides XMR
+	err := coins.ValidatePositive("providedAmount", coins.NumMoneroDecimals, msg.ProvidedAmount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if msg.ProvidedAmount.Cmp(offer.MinAmount) < 0 || msg.ProvidedAmount.Cmp(offer.MaxAmount) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s XMR is not within %s-%s XMR", errAmountNotInOfferRange,
+			msg.ProvidedAmount.Text('f'), offer.MinAmount.Text('f'), offer.MaxAmount.Text('f'))
+	}
+
+	ethAmount, err := offer.ExchangeRate.ToETH(msg.ProvidedAmount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	providedAmount, err := pcommon.GetEthereumAssetAmount(
+		inst.backend.Ctx(),
+		inst.backend.ETHClient(),
+		ethAmount,
+		offer.EthAsset,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contractAddr, err := inst.swapFactoryAddr(offer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := inst.initiate(takerPeerID, providedAmount, coins.MoneroToPiconero(msg.ProvidedAmount),
+		offer.ExchangeRate, offer.EthAsset, offer.ID, contractAddr, offer.SwapTimeoutDuration())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	go func() {
+		<-s.done
+		onDone(s.info.Status)
+	}()
+
+	resp := s.SendKeysMessage().(*message.SendKeysMessage)
+	resp.ProvidedAmount = ethAmount
+	return s, resp, nil
+}
@@ -2,6 +2,7 @@ package xmrtaker
 
 import (
 	"fmt"
+	"math/big"
 	"sync"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -23,6 +24,11 @@ var (
 	log = logging.Logger("xmrtaker")
 )
 
+// DefaultXMRLockTolerance is the default number of piconeros that a maker's XMR
+// lock may fall short of the expected amount, which covers rounding in amount
+// conversions.
+const DefaultXMRLockTolerance = 10
+
 // Instance implements the functionality that will be used by a user who owns ETH
 // and wishes to swap for XMR.
 type Instance struct {
@@ -31,6 +37,25 @@ type Instance struct {
 
 	noTransferBack bool // leave XMR in per-swap generated wallet
 
+	// piconeros that a maker's XMR lock may fall short of the expected amount
+	xmrLockTolerance uint64
+
+	// forwarders that relayed claims may use, any bytecode-verified forwarder
+	// is accepted if empty
+	relayerForwarders []ethcommon.Address
+
+	// report the fee, gas used and block of relayed claims to the maker
+	relayerIncludeClaimDetails bool
+
+	// fees charged for relaying claims, per asset
+	relayerFees *relayer.FeeTable
+
+	// forwards relayer fees to the payout address, if set
+	relayerPayout *relayer.FeePayout
+
+	// the gas limit ceiling of relayed claims
+	relayerClaimGas *relayer.ClaimGasConfig
+
 	// non-nil if a swap is currently happening, nil otherwise
 	// map of offer IDs -> ongoing swaps
 	swapStates map[types.Hash]*swapState
@@ -43,16 +68,65 @@ type Config struct {
 	DataDir        string
 	NoTransferBack bool
 	ExternalSender bool
+
+	// RelayerForwarders restricts relayed claims to swap factories that trust
+	// one of these forwarders. If empty, any verified forwarder is accepted.
+	RelayerForwarders []ethcommon.Address
+
+	// RelayerIncludeClaimDetails has relay claim responses report the fee
+	// charged, and the gas used and block number of the claim transaction.
+	RelayerIncludeClaimDetails bool
+
+	// RelayerFees are the fees charged for relaying claims, per asset. Nil
+	// uses relayer.DefaultFeeTable.
+	RelayerFees *relayer.FeeTable
+
+	// RelayerPayoutAddress, if set, is where the fees of relayed claims are
+	// forwarded to, so they don't accumulate with the key that signs claims.
+	RelayerPayoutAddress *ethcommon.Address
+
+	// RelayerPayoutMin is the least ETH, in wei, forwarded to the payout
+	// address in one transfer. Nil uses relayer.DefaultPayoutMinWei.
+	RelayerPayoutMin *big.Int
+
+	// RelayerClaimGas sets the highest gas limit of claims that we relay. Nil
+	// uses the relayer package defaults.
+	RelayerClaimGas *relayer.ClaimGasConfig
+
+	// XMRLockTolerance is how many piconeros the maker's XMR lock may fall
+	// short of the expected amount, to allow for rounding in amount
+	// conversions. Nil uses DefaultXMRLockTolerance.
+	XMRLockTolerance *uint64
 }
 
 // NewInstance returns a new instance of XMRTaker.
 // It accepts an endpoint to a monero-wallet-rpc instance where XMRTaker will generate
 // the account in which the XMR will be deposited.
 func NewInstance(cfg *Config) (*Instance, error) {
+	xmrLockTolerance := uint64(DefaultXMRLockTolerance)
+	if cfg.XMRLockTolerance != nil {
+		xmrLockTolerance = *cfg.XMRLockTolerance
+	}
+
 	inst := &Instance{
-		backend:    cfg.Backend,
-		dataDir:    cfg.DataDir,
-		swapStates: make(map[types.Hash]*swapState),
+		backend:          cfg.Backend,
+		dataDir:          cfg.DataDir,
+		swapStates:       make(map[types.Hash]*swapState),
+		xmrLockTolerance: xmrLockTolerance,
+
+		relayerForwarders:          cfg.RelayerForwarders,
+		relayerIncludeClaimDetails: cfg.RelayerIncludeClaimDetails,
+		relayerFees:                cfg.RelayerFees,
+		relayerClaimGas:            cfg.RelayerClaimGas,
+	}
+
+	if cfg.RelayerPayoutAddress != nil {
+		inst.relayerPayout = relayer.NewFeePayout(
+			cfg.Backend.Ctx(),
+			cfg.Backend.ETHClient(),
+			*cfg.RelayerPayoutAddress,
+			cfg.RelayerPayoutMin,
+		)
 	}
 
 	err := inst.checkForOngoingSwaps()
@@ -69,29 +143,40 @@ func (inst *Instance) checkForOngoingSwaps() error {
 		return err
 	}
 
+	var ours []*swap.Info
 	for _, s := range swaps {
-		if s.Provides != coins.ProvidesETH {
-			continue
+		if s.Provides == coins.ProvidesETH {
+			ours = append(ours, s)
 		}
+	}
 
-		if s.Status == types.KeysExchanged || s.Status == types.ExpectingKeys {
-			// set status to aborted, delete info from recovery db
-			log.Infof("found ongoing swap %s in DB, aborting since no funds were locked", s.ID)
-			err = inst.abortOngoingSwap(s)
-			if err != nil {
-				log.Warnf("failed to abort ongoing swap %s: %s", s.ID, err)
-			}
-			continue
-		}
+	return pcommon.ResumeOngoingSwaps(
+		inst.backend.Ctx(),
+		ours,
+		inst.backend.ResumeConcurrency(),
+		inst.resumeOngoingSwap,
+	)
+}
 
-		err = inst.createOngoingSwap(s)
-		if err != nil {
-			log.Errorf("%s", err)
-			continue
+// resumeOngoingSwap resumes an ongoing swap found in the db on startup, or
+// aborts it if no funds were locked. Errors are logged, not returned. Once our
+// swap states are created, they only wait for the counterparty and the chains,
+// so there's no startup work left, and the returned channel is always nil.
+func (inst *Instance) resumeOngoingSwap(s *swap.Info) (<-chan struct{}, error) {
+	if s.Status == types.KeysExchanged || s.Status == types.ExpectingKeys {
+		// set status to aborted, delete info from recovery db
+		log.Infof("found ongoing swap %s in DB, aborting since no funds were locked", s.ID)
+		if err := inst.abortOngoingSwap(s); err != nil {
+			log.Warnf("failed to abort ongoing swap %s: %s", s.ID, err)
 		}
+		return nil, nil
 	}
 
-	return nil
+	if err := inst.createOngoingSwap(s); err != nil {
+		log.Errorf("%s", err)
+	}
+
+	return nil, nil
 }
 
 func (inst *Instance) abortOngoingSwap(s *swap.Info) error {
@@ -136,6 +221,7 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		inst.backend,
 		s,
 		inst.noTransferBack,
+		inst.xmrLockTolerance,
 		ethSwapInfo,
 		kp,
 	)
@@ -163,7 +249,8 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 //
 // Note: this will use the current value of `noTransferBack` (verses whatever value
 // was set when the swap was started). It will also only only recover to the primary
-// wallet address, not whatever address was used when the swap was started.
+// wallet address, not whatever address was used when the swap was started, unless
+// the swap has its own account, in which case it recovers to that account.
 func (inst *Instance) completeSwap(s *swap.Info, skB *mcrypto.PrivateSpendKey) error {
 	// fetch our swap private spend key
 	skA, err := inst.backend.RecoveryDB().GetSwapPrivateKey(s.ID)
@@ -188,6 +275,14 @@ func (inst *Instance) completeSwap(s *swap.Info, skB *mcrypto.PrivateSpendKey) e
 		vkA, vkB,
 	)
 
+	depositAddr := inst.backend.XMRClient().PrimaryAddress()
+	if !inst.noTransferBack && (inst.backend.PerSwapAccount() || s.MoneroAccountIndex != nil) {
+		depositAddr, err = pcommon.SwapAccountAddress(inst.backend, s)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = pcommon.ClaimMonero(
 		inst.backend.Ctx(),
 		inst.backend.Env(),
@@ -195,8 +290,9 @@ func (inst *Instance) completeSwap(s *swap.Info, skB *mcrypto.PrivateSpendKey) e
 		inst.backend.XMRClient(),
 		s.MoneroStartHeight,
 		kpAB,
-		inst.backend.XMRClient().PrimaryAddress(),
+		depositAddr,
 		inst.noTransferBack,
+		inst.backend.SweepConfirmations(),
 	)
 	if err != nil {
 		return err
@@ -251,6 +347,15 @@ func (inst *Instance) ExternalSender(offerID types.Hash) (*txsender.ExternalSend
 	return es, nil
 }
 
+// RelayerFees returns the minimum fees that we charge for relaying claims, per
+// asset of the swap.
+func (inst *Instance) RelayerFees() map[types.EthAsset]*big.Int {
+	if inst.relayerFees == nil {
+		return relayer.DefaultFeeTable().Fees()
+	}
+	return inst.relayerFees.Fees()
+}
+
 // HandleRelayClaimRequest validates and sends the transaction for a relay claim request
 func (inst *Instance) HandleRelayClaimRequest(request *message.RelayClaimRequest) (*message.RelayClaimResponse, error) {
 	return relayer.ValidateAndSendTransaction(
@@ -258,5 +363,10 @@ func (inst *Instance) HandleRelayClaimRequest(request *message.RelayClaimRequest
 		request,
 		inst.backend.ETHClient(),
 		inst.backend.ContractAddr(),
+		inst.relayerForwarders,
+		inst.relayerIncludeClaimDetails,
+		inst.relayerFees,
+		inst.relayerPayout,
+		inst.relayerClaimGas,
 	)
 }
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/metrics"
 	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/fatih/color"
@@ -26,8 +29,11 @@ func (s *swapState) HandleProtocolMessage(msg common.Message) error {
 		s.eventCh <- event
 		err := <-event.errCh
 		if err != nil {
+			s.info.SetFailure(err)
 			return err
 		}
+	case *message.NotifyXMRLocked:
+		s.handleNotifyXMRLocked(msg)
 	default:
 		return errUnexpectedMessageType
 	}
@@ -35,9 +41,44 @@ func (s *swapState) HandleProtocolMessage(msg common.Message) error {
 	return nil
 }
 
+// handleNotifyXMRLocked wakes up checkForXMRLock, so that the lock is verified
+// right away instead of at the next polling interval. The message is only a
+// hint, so a notification that doesn't match the expected lock is ignored.
+func (s *swapState) handleNotifyXMRLocked(msg *message.NotifyXMRLocked) {
+	if s.xmrmakerPublicSpendKey == nil || s.xmrmakerPrivateViewKey == nil {
+		log.Warnf("ignoring XMR lock notification received before XMRMaker's keys")
+		return
+	}
+
+	lockedAddr, _ := s.expectedXMRLockAccount()
+	if !lockedAddr.Equal(msg.Address) {
+		log.Warnf("ignoring XMR lock notification for unexpected address %s, expected %s",
+			msg.Address, lockedAddr)
+		return
+	}
+
+	// our view-only wallet doesn't see transfers below its scan height, so this
+	// swap refunds before t0 unless XMRMaker's height is wrong
+	if msg.Height != 0 && msg.Height < s.walletScanHeight {
+		log.Warnf("XMR lock notification has height %d, below our scan height %d, so the lock can't be "+
+			"verified and the swap will refund; restart with a larger --monero-start-height-rollback "+
+			"to scan from further back in later swaps",
+			msg.Height, s.walletScanHeight)
+	}
+
+	log.Infof("XMRMaker reported locking XMR: txID=%s height=%d, checking lock", msg.TxID, msg.Height)
+
+	// non-blocking, as a check is already pending if the channel is full
+	select {
+	case s.xmrLockNotifiedCh <- struct{}{}:
+	default:
+	}
+}
+
 func (s *swapState) clearNextExpectedEvent(status types.Status) {
 	s.nextExpectedEvent = EventNoneType
 	s.info.SetStatus(status)
+	metrics.SwapExited(s.info.Provides, status, s.info.StartTime)
 	if s.statusCh != nil {
 		s.statusCh <- status
 	}
@@ -96,7 +137,12 @@ func (s *swapState) handleSendKeysMessage(msg *message.SendKeysMessage) (common.
 	vk := msg.PrivateViewKey
 
 	// verify counterparty's DLEq proof and ensure the resulting secp256k1 key is correct
-	verificationRes, err := pcommon.VerifyKeysAndProof(msg.DLEqProof, msg.Secp256k1PublicKey, msg.PublicSpendKey)
+	verificationRes, err := pcommon.VerifyKeysAndProof(
+		msg.DLEqProofScheme,
+		msg.DLEqProof,
+		msg.Secp256k1PublicKey,
+		msg.PublicSpendKey,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +182,7 @@ func (s *swapState) handleSendKeysMessage(msg *message.SendKeysMessage) (common.
 	go s.checkForXMRLock()
 
 	out := &message.NotifyETHLocked{
-		Address:        s.ContractAddr(),
+		Address:        s.contractAddr,
 		TxHash:         txHash,
 		ContractSwapID: s.contractSwapID,
 		ContractSwap:   s.contractSwap,
@@ -168,31 +214,44 @@ func (s *swapState) checkForXMRLock() {
 	log.Debugf("generated view-only wallet to check funds: %s", abViewCli.WalletName())
 
 	timer := time.NewTicker(checkForXMRLockInterval)
+	defer timer.Stop()
+
 	for {
 		select {
 		case <-s.ctx.Done():
 			return
 		case <-timer.C:
-			balance, err := abViewCli.GetBalance(0)
-			if err != nil {
-				log.Errorf("failed to get balance: %s", err)
-				continue
-			}
+		case <-s.xmrLockNotifiedCh:
+		}
 
-			log.Debugf("checking locked wallet, address=%s balance=%d blocks-to-unlock=%d",
-				lockedAddr, balance.Balance, balance.BlocksToUnlock)
+		balance, err := abViewCli.GetBalance(0)
+		if err != nil {
+			log.Errorf("failed to get balance: %s", err)
+			continue
+		}
 
-			if s.expectedPiconeroAmount().CmpU64(balance.UnlockedBalance) <= 0 {
-				event := newEventXMRLocked()
-				s.eventCh <- event
-				err := <-event.errCh
-				if err != nil {
-					log.Errorf("eventXMRLocked errored: %s", err)
-				}
+		log.Debugf("checking locked wallet, address=%s balance=%d blocks-to-unlock=%d",
+			lockedAddr, balance.Balance, balance.BlocksToUnlock)
 
-				return
+		if !s.isXMRLockSufficient(balance.UnlockedBalance) {
+			if balance.UnlockedBalance != 0 && balance.UnlockedBalance == balance.Balance {
+				log.Warnf("locked XMR %s is short of the expected %s XMR by more than the tolerated %d piconeros",
+					coins.FmtPiconeroAsXMR(balance.UnlockedBalance),
+					s.expectedPiconeroAmount().AsMoneroString(),
+					s.xmrLockTolerance,
+				)
 			}
+			continue
 		}
+
+		event := newEventXMRLocked()
+		s.eventCh <- event
+		err = <-event.errCh
+		if err != nil {
+			log.Errorf("eventXMRLocked errored: %s", err)
+		}
+
+		return
 	}
 }
 
@@ -221,6 +280,7 @@ func (s *swapState) runT0ExpirationHandler() {
 		return
 	case <-giveUpAndRefundTimer.C:
 		log.Infof("approaching T0, attempting to refund ETH")
+		s.info.SetFailure(pswap.ErrXMRLockTimeout)
 		event := newEventShouldRefund()
 		s.eventCh <- event
 		err := <-event.errCh
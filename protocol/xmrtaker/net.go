@@ -1,9 +1,13 @@
 package xmrtaker
 
 import (
+	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
@@ -25,9 +29,19 @@ func (inst *Instance) Provides() coins.ProvidesCoin {
 	return coins.ProvidesETH
 }
 
-// InitiateProtocol is called when an RPC call is made from the user to initiate a swap.
-// The input units are ether that we will provide.
-func (inst *Instance) InitiateProtocol(providesAmount *apd.Decimal, offer *types.Offer) (common.SwapState, error) {
+// InitiateProtocol is called when an RPC call is made from the user to initiate a swap
+// with the maker of the offer. The input units are ether that we will provide.
+func (inst *Instance) InitiateProtocol(
+	makerPeerID peer.ID,
+	providesAmount *apd.Decimal,
+	offer *types.Offer,
+) (common.SwapState, error) {
+	// offers queried from the maker aren't checked against our configured
+	// terms when decoded, so they're checked before we take one
+	if err := offer.CheckTerms(inst.backend.DustThresholds(), inst.backend.ExchangeRateBounds()); err != nil {
+		return nil, err
+	}
+
 	expectedAmount, err := offer.ExchangeRate.ToXMR(providesAmount)
 	if err != nil {
 		return nil, err
@@ -42,8 +56,13 @@ func (inst *Instance) InitiateProtocol(providesAmount *apd.Decimal, offer *types
 		return nil, err
 	}
 
-	state, err := inst.initiate(providedAmount, coins.MoneroToPiconero(expectedAmount),
-		offer.ExchangeRate, offer.EthAsset, offer.ID)
+	contractAddr, err := inst.swapFactoryAddr(offer)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := inst.initiate(makerPeerID, providedAmount, coins.MoneroToPiconero(expectedAmount),
+		offer.ExchangeRate, offer.EthAsset, offer.ID, contractAddr, offer.SwapTimeoutDuration())
 	if err != nil {
 		return nil, err
 	}
@@ -51,8 +70,28 @@ func (inst *Instance) InitiateProtocol(providesAmount *apd.Decimal, offer *types
 	return state, nil
 }
 
-func (inst *Instance) initiate(providesAmount EthereumAssetAmount, expectedAmount *coins.PiconeroAmount,
-	exchangeRate *coins.ExchangeRate, ethAsset types.EthAsset, offerID types.Hash) (*swapState, error) {
+// swapFactoryAddr returns the SwapFactory contract that a swap on the offer
+// uses: the offer's own, once its bytecode is verified, or our configured one.
+func (inst *Instance) swapFactoryAddr(offer *types.Offer) (ethcommon.Address, error) {
+	if offer.SwapFactory == nil || *offer.SwapFactory == inst.backend.ContractAddr() {
+		return inst.backend.ContractAddr(), nil
+	}
+
+	_, err := contracts.CheckSwapFactoryContractCode(
+		inst.backend.Ctx(),
+		inst.backend.ETHClient().Raw(),
+		*offer.SwapFactory,
+	)
+	if err != nil {
+		return ethcommon.Address{}, fmt.Errorf("%w: %s: %s", errInvalidSwapFactory, offer.SwapFactory, err)
+	}
+
+	return *offer.SwapFactory, nil
+}
+
+func (inst *Instance) initiate(makerPeerID peer.ID, providesAmount EthereumAssetAmount,
+	expectedAmount *coins.PiconeroAmount, exchangeRate *coins.ExchangeRate, ethAsset types.EthAsset,
+	offerID types.Hash, contractAddr ethcommon.Address, swapTimeout time.Duration) (*swapState, error) {
 	inst.swapMu.Lock()
 	defer inst.swapMu.Unlock()
 
@@ -89,16 +128,20 @@ func (inst *Instance) initiate(providesAmount EthereumAssetAmount, expectedAmoun
 
 	s, err := newSwapStateFromStart(
 		inst.backend,
+		makerPeerID,
 		offerID,
 		inst.noTransferBack,
+		inst.xmrLockTolerance,
 		providesAmount,
 		expectedAmount,
 		exchangeRate,
 		ethAsset,
+		contractAddr,
 	)
 	if err != nil {
 		return nil, err
 	}
+	s.swapTimeout = swapTimeout
 
 	go func() {
 		<-s.done
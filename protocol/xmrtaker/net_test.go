@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/require"
 
 	"github.com/athanorlabs/atomic-swap/coins"
@@ -29,7 +30,9 @@ func TestXMRTaker_InitiateProtocol(t *testing.T) {
 	one := apd.New(1, 0)
 	offer := types.NewOffer(coins.ProvidesETH, zero, zero, coins.ToExchangeRate(one), types.EthAssetETH)
 	providesAmount := apd.New(333, -2) // 3.33
-	s, err := a.InitiateProtocol(providesAmount, offer)
+	makerPeerID := peer.ID("maker")
+	s, err := a.InitiateProtocol(makerPeerID, providesAmount, offer)
 	require.NoError(t, err)
 	require.Equal(t, a.swapStates[offer.ID], s)
+	require.Equal(t, makerPeerID, a.swapStates[offer.ID].info.CounterpartyPeerID)
 }
@@ -22,7 +22,6 @@ import (
 	"github.com/athanorlabs/atomic-swap/dleq"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/watcher"
-	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
@@ -32,6 +31,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/fatih/color"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
 const revertSwapCompleted = "swap is already completed"
@@ -50,6 +50,10 @@ type swapState struct {
 	cancel         context.CancelFunc
 	noTransferBack bool
 
+	// how many piconeros the XMR lock may fall short of the expected amount,
+	// to allow for rounding differences
+	xmrLockTolerance uint64
+
 	info           *pswap.Info
 	statusCh       chan types.Status
 	providedAmount EthereumAssetAmount
@@ -70,10 +74,16 @@ type swapState struct {
 	walletScanHeight uint64
 
 	// swap contract and timeouts in it; set once contract is deployed
+	contract       *contracts.SwapFactory
+	contractAddr   ethcommon.Address
 	contractSwapID [32]byte
 	contractSwap   *contracts.SwapFactorySwap
 	t0, t1         time.Time
 
+	// timeout duration passed to the contract when locking ETH, set if the
+	// offer has its own; otherwise the backend's SwapTimeout is used
+	swapTimeout time.Duration
+
 	// tracks the state of the swap
 	nextExpectedEvent EventType
 	// set to true once funds are locked
@@ -88,6 +98,8 @@ type swapState struct {
 	logClaimedCh chan ethtypes.Log
 	// signals the t0 expiration handler to return
 	xmrLockedCh chan struct{}
+	// signals checkForXMRLock that XMRMaker reported locking XMR
+	xmrLockNotifiedCh chan struct{}
 	// signals the t1 expiration handler to return
 	claimedCh chan struct{}
 	// signals to the creator xmrmaker instance that it can delete this swap
@@ -96,26 +108,24 @@ type swapState struct {
 
 func newSwapStateFromStart(
 	b backend.Backend,
+	makerPeerID peer.ID,
 	offerID types.Hash,
 	noTransferBack bool,
+	xmrLockTolerance uint64,
 	providedAmount EthereumAssetAmount,
 	expectedAmount *coins.PiconeroAmount,
 	exchangeRate *coins.ExchangeRate,
 	ethAsset types.EthAsset,
+	contractAddr ethcommon.Address,
 ) (*swapState, error) {
 	stage := types.ExpectingKeys
 	statusCh := make(chan types.Status, 16)
 
-	moneroStartNumber, err := b.XMRClient().GetHeight()
+	moneroStartNumber, err := b.MoneroStartHeight()
 	if err != nil {
 		return nil, err
 	}
 
-	// reduce the scan height a little in case there is a block reorg
-	if moneroStartNumber >= monero.MinSpendConfirmations {
-		moneroStartNumber -= monero.MinSpendConfirmations
-	}
-
 	ethHeader, err := b.ETHClient().Raw().HeaderByNumber(b.Ctx(), nil)
 	if err != nil {
 		return nil, err
@@ -130,6 +140,7 @@ func newSwapStateFromStart(
 		ethAsset,
 		stage,
 		moneroStartNumber,
+		makerPeerID,
 		statusCh,
 	)
 	if err = b.SwapManager().AddSwap(info); err != nil {
@@ -138,7 +149,9 @@ func newSwapStateFromStart(
 
 	s, err := newSwapState(
 		b,
+		contractAddr,
 		noTransferBack,
+		xmrLockTolerance,
 		info,
 		ethHeader.Number,
 		moneroStartNumber,
@@ -155,6 +168,7 @@ func newSwapStateFromOngoing(
 	b backend.Backend,
 	info *pswap.Info,
 	noTransferBack bool,
+	xmrLockTolerance uint64,
 	ethSwapInfo *db.EthereumSwapInfo,
 	sk *mcrypto.PrivateKeyPair,
 ) (*swapState, error) {
@@ -167,9 +181,20 @@ func newSwapStateFromOngoing(
 		return nil, fmt.Errorf("failed to get xmrmaker swap keys from db: %w", err)
 	}
 
+	// the swap may use an offer's own SwapFactory rather than the one loaded
+	// at start-up, which is fine as long as it's a valid SwapFactory
+	if b.ContractAddr() != ethSwapInfo.ContractAddress {
+		_, err = contracts.CheckSwapFactoryContractCode(b.Ctx(), b.ETHClient().Raw(), ethSwapInfo.ContractAddress)
+		if err != nil {
+			return nil, errContractAddrMismatch(ethSwapInfo.ContractAddress.String())
+		}
+	}
+
 	s, err := newSwapState(
 		b,
+		ethSwapInfo.ContractAddress,
 		noTransferBack,
+		xmrLockTolerance,
 		info,
 		ethSwapInfo.StartNumber,
 		info.MoneroStartHeight,
@@ -178,10 +203,6 @@ func newSwapStateFromOngoing(
 		return nil, err
 	}
 
-	if b.ContractAddr() != ethSwapInfo.ContractAddress {
-		return nil, errContractAddrMismatch(ethSwapInfo.ContractAddress.String())
-	}
-
 	s.setTimeouts(ethSwapInfo.Swap.Timeout0, ethSwapInfo.Swap.Timeout1)
 	s.privkeys = sk
 	s.pubkeys = sk.PublicKeyPair()
@@ -198,7 +219,9 @@ func newSwapStateFromOngoing(
 
 func newSwapState(
 	b backend.Backend,
+	contractAddr ethcommon.Address,
 	noTransferBack bool,
+	xmrLockTolerance uint64,
 	info *pswap.Info,
 	ethStartNumber *big.Int,
 	moneroStartNumber uint64,
@@ -231,6 +254,17 @@ func newSwapState(
 		}
 	}
 
+	contract := b.Contract()
+	if contractAddr != b.ContractAddr() {
+		var err error
+		contract, err = b.NewSwapFactory(contractAddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sender.SetContractAddress(contractAddr)
+	sender.SetContract(contract)
+
 	// set up ethereum event watchers
 	const logChSize = 16
 	logClaimedCh := make(chan ethtypes.Log, logChSize)
@@ -240,11 +274,13 @@ func newSwapState(
 	claimedWatcher := watcher.NewEventFilter(
 		ctx,
 		b.ETHClient().Raw(),
-		b.ContractAddr(),
+		contractAddr,
 		ethStartNumber,
 		claimedTopic,
 		logClaimedCh,
 	)
+	claimedWatcher.SetPollInterval(b.ETHPollInterval())
+	claimedWatcher.SetSubscribeNewHeads(b.ETHSubscribe())
 
 	err := claimedWatcher.Start()
 	if err != nil {
@@ -266,12 +302,16 @@ func newSwapState(
 		cancel:            cancel,
 		Backend:           b,
 		sender:            sender,
+		contract:          contract,
+		contractAddr:      contractAddr,
 		noTransferBack:    noTransferBack,
+		xmrLockTolerance:  xmrLockTolerance,
 		walletScanHeight:  moneroStartNumber,
 		nextExpectedEvent: nextExpectedEventFromStatus(info.Status),
 		eventCh:           make(chan Event),
 		logClaimedCh:      logClaimedCh,
 		xmrLockedCh:       make(chan struct{}),
+		xmrLockNotifiedCh: make(chan struct{}, 1),
 		claimedCh:         make(chan struct{}),
 		done:              make(chan struct{}),
 		info:              info,
@@ -315,6 +355,7 @@ func (s *swapState) SendKeysMessage() common.Message {
 		PublicSpendKey:     s.pubkeys.SpendKey(),
 		PrivateViewKey:     s.privkeys.ViewKey(),
 		DLEqProof:          s.dleqProof.Proof(),
+		DLEqProofScheme:    s.dleqProof.Scheme(),
 		Secp256k1PublicKey: s.secp256k1Pub,
 	}
 }
@@ -328,6 +369,17 @@ func (s *swapState) expectedPiconeroAmount() *coins.PiconeroAmount {
 	return coins.MoneroToPiconero(s.info.ExpectedAmount)
 }
 
+// isXMRLockSufficient returns whether the unlocked balance of the swap's XMR
+// lock covers the expected amount, less the tolerated shortfall.
+func (s *swapState) isXMRLockSufficient(unlockedBalance uint64) bool {
+	tolerated := unlockedBalance + s.xmrLockTolerance
+	if tolerated < unlockedBalance {
+		return true // overflowed, so it's more than any expected amount
+	}
+
+	return s.expectedPiconeroAmount().CmpU64(tolerated) <= 0
+}
+
 // ID returns the ID of the swap
 func (s *swapState) ID() types.Hash {
 	return s.info.ID
@@ -417,9 +469,15 @@ func (s *swapState) exit() error {
 
 // doRefund is called by the RPC function swap_refund.
 // If it's possible to refund the ongoing swap, it does that, then notifies the counterparty.
+// Unlike tryRefund, it never waits: if the contract doesn't permit a refund yet,
+// or the swap was already claimed, it returns an error without sending anything.
 func (s *swapState) doRefund() (ethcommon.Hash, error) {
 	switch s.nextExpectedEvent {
 	case EventXMRLockedType, EventETHClaimedType:
+		if err := s.checkRefundable(); err != nil {
+			return ethcommon.Hash{}, err
+		}
+
 		event := newEventShouldRefund()
 		s.eventCh <- event
 		err := <-event.errCh
@@ -427,15 +485,49 @@ func (s *swapState) doRefund() (ethcommon.Hash, error) {
 			return ethcommon.Hash{}, err
 		}
 
-		txHash := <-event.txHashCh
+		// the handler closes txHashCh before errCh, without sending a tx hash
+		// if the swap completed first
+		txHash, ok := <-event.txHashCh
+		if !ok {
+			return ethcommon.Hash{}, errRefundSwapCompleted
+		}
+
 		return txHash, nil
 	default:
 		return ethcommon.Hash{}, errCannotRefund
 	}
 }
 
+// checkRefundable returns an error if the contract won't currently accept our
+// refund: either the swap was already claimed, or it was set ready and t1
+// hasn't passed.
+func (s *swapState) checkRefundable() error {
+	stage, err := s.contract.Swaps(s.ETHClient().CallOpts(s.ctx), s.contractSwapID)
+	if err != nil {
+		return err
+	}
+
+	switch stage {
+	case contracts.StageInvalid:
+		return fmt.Errorf("%w: contract swap ID: %s", errRefundInvalid, s.contractSwapID)
+	case contracts.StageCompleted:
+		return errRefundSwapCompleted
+	}
+
+	ts, err := s.ETHClient().LatestBlockTimestamp(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	if ts.After(s.t1) || (stage == contracts.StagePending && ts.Before(s.t0)) {
+		return nil
+	}
+
+	return fmt.Errorf("%w, refund is possible after %s", errRefundTooEarly, s.t1)
+}
+
 func (s *swapState) tryRefund() (ethcommon.Hash, error) {
-	stage, err := s.Contract().Swaps(s.ETHClient().CallOpts(s.ctx), s.contractSwapID)
+	stage, err := s.contract.Swaps(s.ETHClient().CallOpts(s.ctx), s.contractSwapID)
 	if err != nil {
 		return ethcommon.Hash{}, err
 	}
@@ -564,25 +656,47 @@ func (s *swapState) setXMRMakerKeys(
 	return s.Backend.RecoveryDB().PutCounterpartySwapKeys(s.info.ID, sk, vk)
 }
 
+// approveToken approves the swap contract to transfer the ERC20 tokens that we
+// are about to lock, unless it is already approved for at least that amount.
+// The hash of the approval transaction, if one was sent, is recorded in the
+// swap info.
 func (s *swapState) approveToken() error {
-	token, err := contracts.NewIERC20(s.info.EthAsset.Address(), s.ETHClient().Raw())
-	if err != nil {
-		return fmt.Errorf("failed to instantiate IERC20: %w", err)
-	}
+	token := s.info.EthAsset.Address()
+	amount := s.providedAmount.BigInt()
 
-	balance, err := token.BalanceOf(s.ETHClient().CallOpts(s.ctx), s.ETHClient().Address())
-	if err != nil {
-		return fmt.Errorf("failed to get balance for token: %w", err)
+	var txHash *ethcommon.Hash
+	if s.ETHClient().HasPrivateKey() {
+		var err error
+		txHash, err = s.ETHClient().EnsureERC20Approval(s.ctx, token, s.contractAddr, amount)
+		if err != nil {
+			return fmt.Errorf("failed to approve token: %w", err)
+		}
+	} else {
+		// the external sender signs the approval, so we check the allowance
+		// ourselves to avoid prompting for a redundant approval
+		allowance, err := s.ETHClient().ERC20Allowance(s.ctx, token, s.contractAddr)
+		if err != nil {
+			return fmt.Errorf("failed to get token allowance: %w", err)
+		}
+
+		if allowance.Cmp(amount) < 0 {
+			log.Info("approving token for use by the swap contract...")
+			hash, _, err := s.sender.Approve(s.contractAddr, amount) //nolint:govet
+			if err != nil {
+				return fmt.Errorf("failed to approve token: %w", err)
+			}
+			txHash = &hash
+		}
 	}
 
-	log.Info("approving token for use by the swap contract...")
-	_, _, err = s.sender.Approve(s.ContractAddr(), balance)
-	if err != nil {
-		return fmt.Errorf("failed to approve token: %w", err)
+	if txHash == nil {
+		log.Info("swap contract is already approved to transfer the token")
+		return nil
 	}
 
-	log.Info("approved token for use by the swap contract")
-	return nil
+	log.Infof("approved token for use by the swap contract, tx=%s", txHash)
+	s.info.ApprovalTxHash = txHash
+	return s.Backend.SwapManager().WriteSwapToDB(s.info)
 }
 
 // lockAsset calls the Swap contract function new_swap and locks `amount` ether in it.
@@ -591,8 +705,14 @@ func (s *swapState) lockAsset() (ethcommon.Hash, error) {
 		panic(errCounterpartyKeysNotSet)
 	}
 
+	endStep, err := s.SwapManager().BeginStep(true)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+	defer endStep()
+
 	if s.info.EthAsset != types.EthAssetETH {
-		err := s.approveToken()
+		err = s.approveToken()
 		if err != nil {
 			return ethcommon.Hash{}, err
 		}
@@ -603,12 +723,17 @@ func (s *swapState) lockAsset() (ethcommon.Hash, error) {
 
 	log.Debugf("locking ETH in contract")
 
+	timeout := s.SwapTimeout()
+	if s.swapTimeout != 0 {
+		timeout = s.swapTimeout
+	}
+
 	nonce := generateNonce()
 	txHash, receipt, err := s.sender.NewSwap(
 		cmtXMRMaker,
 		cmtXMRTaker,
 		s.xmrmakerAddress,
-		big.NewInt(int64(s.SwapTimeout().Seconds())),
+		big.NewInt(int64(timeout.Seconds())),
 		nonce,
 		s.info.EthAsset,
 		s.providedAmount.BigInt(),
@@ -664,10 +789,10 @@ func (s *swapState) lockAsset() (ethcommon.Hash, error) {
 		StartNumber:     receipt.BlockNumber,
 		SwapID:          s.contractSwapID,
 		Swap:            s.contractSwap,
-		ContractAddress: s.Backend.ContractAddr(),
+		ContractAddress: s.contractAddr,
 	}
 
-	if err := s.Backend.RecoveryDB().PutContractSwapInfo(s.ID(), ethInfo); err != nil {
+	if err = s.Backend.RecoveryDB().PutContractSwapInfo(s.ID(), ethInfo); err != nil {
 		return ethcommon.Hash{}, err
 	}
 
@@ -678,7 +803,13 @@ func (s *swapState) lockAsset() (ethcommon.Hash, error) {
 // call Claim(). Ready() should only be called once XMRTaker sees XMRMaker lock his XMR.
 // If time t_0 has passed, there is no point of calling Ready().
 func (s *swapState) ready() error {
-	stage, err := s.Contract().Swaps(s.ETHClient().CallOpts(s.ctx), s.contractSwapID)
+	endStep, err := s.SwapManager().BeginStep(false)
+	if err != nil {
+		return err
+	}
+	defer endStep()
+
+	stage, err := s.contract.Swaps(s.ETHClient().CallOpts(s.ctx), s.contractSwapID)
 	if err != nil {
 		return err
 	}
@@ -703,6 +834,12 @@ func (s *swapState) ready() error {
 // and returns to her the ether in the contract.
 // If time t_1 passes and Claim() has not been called, XMRTaker should call Refund().
 func (s *swapState) refund() (ethcommon.Hash, error) {
+	endStep, err := s.SwapManager().BeginStep(false)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+	defer endStep()
+
 	sc := s.getSecret()
 
 	log.Infof("attempting to call Refund()...")
@@ -718,7 +855,7 @@ func (s *swapState) refund() (ethcommon.Hash, error) {
 // generateKeys generates XMRTaker's monero spend and view keys (S_b, V_b), a secp256k1 public key,
 // and a DLEq proof proving that the two keys correspond.
 func generateKeys() (*pcommon.KeysAndProof, error) {
-	return pcommon.GenerateKeysAndProof()
+	return pcommon.GenerateKeysAndProof(dleq.DefaultProofScheme)
 }
 
 func generateNonce() *big.Int {
@@ -10,6 +10,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/db"
+	"github.com/athanorlabs/atomic-swap/dleq"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
 )
@@ -24,7 +25,7 @@ func setupSwapStateUntilETHLocked(t *testing.T) (*swapState, uint64) {
 	startNum, err := s.ETHClient().Raw().BlockNumber(s.Backend.Ctx())
 	require.NoError(t, err)
 
-	makerKeys, err := pcommon.GenerateKeysAndProof()
+	makerKeys, err := pcommon.GenerateKeysAndProof(dleq.DefaultProofScheme)
 	require.NoError(t, err)
 	s.setXMRMakerKeys(
 		makerKeys.PublicKeyPair.SpendKey(),
@@ -65,6 +66,7 @@ func TestSwapStateOngoing_handleEvent_EventXMRLocked(t *testing.T) {
 		s.Backend,
 		s.info,
 		s.noTransferBack,
+		s.xmrLockTolerance,
 		ethInfo,
 		s.privkeys,
 	)
@@ -92,6 +94,7 @@ func TestSwapStateOngoing_handleEvent_EventETHClaimed(t *testing.T) {
 		s.Backend,
 		s.info,
 		s.noTransferBack,
+		s.xmrLockTolerance,
 		ethInfo,
 		s.privkeys,
 	)
@@ -20,6 +20,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/dleq"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/monero"
@@ -55,7 +56,12 @@ func (n *mockNet) DiscoverRelayers() ([]peer.ID, error) {
 	return nil, nil
 }
 
-func (n *mockNet) SubmitClaimToRelayer(_ peer.ID, _ *message.RelayClaimRequest) (*message.RelayClaimResponse, error) {
+func (n *mockNet) MarkRelayerFailed(_ peer.ID) {}
+
+func (n *mockNet) SubmitClaimToRelayer(
+	_ peer.ID,
+	_ message.RelayClaimRequestFunc,
+) (*message.RelayClaimResponse, error) {
 	return new(message.RelayClaimResponse), nil
 }
 
@@ -124,8 +130,8 @@ func newTestSwapStateAndNet(t *testing.T) (*swapState, *mockNet) {
 	providedAmt := coins.EtherToWei(coins.StrToDecimal("1"))
 	expectedAmt := coins.MoneroToPiconero(coins.StrToDecimal("1"))
 	exchangeRate := coins.ToExchangeRate(coins.StrToDecimal("1.0")) // 100%
-	swapState, err := newSwapStateFromStart(b, types.Hash{}, true,
-		providedAmt, expectedAmt, exchangeRate, types.EthAssetETH)
+	swapState, err := newSwapStateFromStart(b, "", types.Hash{}, true, DefaultXMRLockTolerance,
+		providedAmt, expectedAmt, exchangeRate, types.EthAssetETH, b.ContractAddr())
 	require.NoError(t, err)
 	return swapState, net
 }
@@ -155,14 +161,14 @@ func newTestSwapStateWithERC20(t *testing.T, initialBalance *big.Int) (*swapStat
 
 	exchangeRate := coins.ToExchangeRate(apd.New(1, 0)) // 100%
 	zeroPiconeros := coins.NewPiconeroAmount(0)
-	swapState, err := newSwapStateFromStart(b, types.Hash{}, false,
-		coins.IntToWei(1), zeroPiconeros, exchangeRate, types.EthAsset(addr))
+	swapState, err := newSwapStateFromStart(b, "", types.Hash{}, false, DefaultXMRLockTolerance,
+		coins.IntToWei(1), zeroPiconeros, exchangeRate, types.EthAsset(addr), b.ContractAddr())
 	require.NoError(t, err)
 	return swapState, contract
 }
 
 func newTestXMRMakerSendKeysMessage(t *testing.T) (*message.SendKeysMessage, *pcommon.KeysAndProof) {
-	keysAndProof, err := pcommon.GenerateKeysAndProof()
+	keysAndProof, err := pcommon.GenerateKeysAndProof(dleq.DefaultProofScheme)
 	require.NoError(t, err)
 
 	msg := &message.SendKeysMessage{
@@ -245,8 +251,28 @@ func lockXMRFunds(
 	amount *coins.PiconeroAmount,
 ) {
 	monero.MineMinXMRBalance(t, wc, amount)
-	_, err := wc.Transfer(ctx, destAddr, 0, amount, monero.MinSpendConfirmations)
+	_, err := wc.Transfer(ctx, destAddr, 0, amount, monero.MinSpendConfirmations, types.MoneroPriorityDefault)
+	require.NoError(t, err)
+}
+
+func TestSwapState_isXMRLockSufficient(t *testing.T) {
+	s := &swapState{
+		info:             &pswap.Info{ExpectedAmount: coins.StrToDecimal("1")},
+		xmrLockTolerance: DefaultXMRLockTolerance,
+	}
+
+	expected, err := s.expectedPiconeroAmount().Uint64()
 	require.NoError(t, err)
+
+	require.True(t, s.isXMRLockSufficient(expected+1))
+	require.True(t, s.isXMRLockSufficient(expected))
+	require.True(t, s.isXMRLockSufficient(expected-DefaultXMRLockTolerance))
+	require.False(t, s.isXMRLockSufficient(expected-DefaultXMRLockTolerance-1))
+
+	// without a tolerance, the lock must cover the expected amount
+	s.xmrLockTolerance = 0
+	require.True(t, s.isXMRLockSufficient(expected))
+	require.False(t, s.isXMRLockSufficient(expected-1))
 }
 
 func TestSwapState_NotifyXMRLock(t *testing.T) {
@@ -278,6 +304,38 @@ func TestSwapState_NotifyXMRLock(t *testing.T) {
 	require.Equal(t, EventETHClaimedType, s.nextExpectedEvent)
 }
 
+func TestSwapState_HandleProtocolMessage_NotifyXMRLocked(t *testing.T) {
+	s := newTestSwapState(t)
+	defer s.cancel()
+
+	xmrmakerKeysAndProof, err := generateKeys()
+	require.NoError(t, err)
+
+	err = s.setXMRMakerKeys(
+		xmrmakerKeysAndProof.PublicKeyPair.SpendKey(),
+		xmrmakerKeysAndProof.PrivateKeyPair.ViewKey(),
+		xmrmakerKeysAndProof.Secp256k1PublicKey,
+	)
+	require.NoError(t, err)
+
+	// a notification for some other address is ignored
+	msg := &message.NotifyXMRLocked{
+		TxID:    "txid",
+		Address: xmrmakerKeysAndProof.PublicKeyPair.Address(common.Development),
+		Height:  s.walletScanHeight,
+	}
+	require.NoError(t, s.HandleProtocolMessage(msg))
+	require.Len(t, s.xmrLockNotifiedCh, 0)
+
+	msg.Address, _ = s.expectedXMRLockAccount()
+	require.NoError(t, s.HandleProtocolMessage(msg))
+	require.Len(t, s.xmrLockNotifiedCh, 1)
+
+	// repeated notifications don't block
+	require.NoError(t, s.HandleProtocolMessage(msg))
+	require.Len(t, s.xmrLockNotifiedCh, 1)
+}
+
 // test the case where the monero is locked, but XMRMaker never claims.
 // XMRTaker should call refund after the timeout t1.
 func TestSwapState_NotifyXMRLock_Refund(t *testing.T) {
@@ -325,6 +383,40 @@ func TestSwapState_NotifyXMRLock_Refund(t *testing.T) {
 	require.Equal(t, uint64(0), balance.Uint64())
 }
 
+// once the contract is set ready, a manual refund fails fast until t1 passes.
+func TestSwapState_doRefund_tooEarly(t *testing.T) {
+	s := newTestSwapState(t)
+	defer s.cancel()
+	s.nextExpectedEvent = EventXMRLockedType
+
+	xmrmakerKeysAndProof, err := generateKeys()
+	require.NoError(t, err)
+
+	err = s.setXMRMakerKeys(
+		xmrmakerKeysAndProof.PublicKeyPair.SpendKey(),
+		xmrmakerKeysAndProof.PrivateKeyPair.ViewKey(),
+		xmrmakerKeysAndProof.Secp256k1PublicKey,
+	)
+	require.NoError(t, err)
+
+	_, err = s.lockAsset()
+	require.NoError(t, err)
+
+	kp := mcrypto.SumSpendAndViewKeys(xmrmakerKeysAndProof.PublicKeyPair, s.pubkeys)
+	xmrAddr := kp.Address(common.Development)
+
+	lockXMRFunds(t, s.ctx, s.XMRClient(), xmrAddr, s.expectedPiconeroAmount())
+	event := newEventXMRLocked()
+	s.eventCh <- event
+	err = <-event.errCh
+	require.NoError(t, err)
+	require.Equal(t, EventETHClaimedType, s.nextExpectedEvent)
+
+	_, err = s.doRefund()
+	require.ErrorIs(t, err, errRefundTooEarly)
+	require.True(t, s.info.Status.IsOngoing())
+}
+
 func TestExit_afterSendKeysMessage(t *testing.T) {
 	s := newTestSwapState(t)
 	defer s.cancel()
@@ -422,5 +514,12 @@ func TestSwapState_ApproveToken(t *testing.T) {
 	require.NoError(t, err)
 	allowance, err := contract.Allowance(&bind.CallOpts{}, s.ETHClient().Address(), s.ContractAddr())
 	require.NoError(t, err)
-	require.Equal(t, initialBalance, allowance)
+	require.Equal(t, s.providedAmount.BigInt(), allowance)
+	require.NotNil(t, s.info.ApprovalTxHash)
+
+	// the existing allowance is sufficient, so no approval is sent
+	s.info.ApprovalTxHash = nil
+	err = s.approveToken()
+	require.NoError(t, err)
+	require.Nil(t, s.info.ApprovalTxHash)
 }
@@ -0,0 +1,148 @@
+package xmrtaker
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/cockroachdb/apd/v3"
+	ethereum "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+)
+
+// Conservative gas amounts for the swap contract calls made by the taker. They
+// are used when a call can't be simulated: setReady and refund need a swap that
+// exists on-chain, and newSwap with an ERC20 asset needs the token approval.
+const (
+	newSwapETHGas   = 60_000
+	newSwapERC20Gas = 90_000
+	approveGas      = 50_000
+	setReadyGas     = 40_000
+	refundGas       = 50_000
+)
+
+// EstimateTakeCost returns the total ETH, in wei, that taking the passed offer
+// with providesAmount could cost. This is the locked value (if the asset is
+// ETH), plus the gas for the token approval (if the asset is an ERC20 token),
+// newSwap, setReady and a potential refund, at the current gas price. When the
+// asset is an ERC20 token, the locked token amount is not included.
+func (inst *Instance) EstimateTakeCost(offer *types.Offer, providesAmount *apd.Decimal) (*coins.WeiAmount, error) {
+	ctx := inst.backend.Ctx()
+	ec := inst.backend.ETHClient()
+
+	providedAmount, err := pcommon.GetEthereumAssetAmount(ctx, ec, providesAmount, offer.EthAsset)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice, err := ec.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	contractAddr := inst.backend.ContractAddr()
+	if offer.SwapFactory != nil {
+		contractAddr = *offer.SwapFactory
+	}
+
+	gas := uint64(setReadyGas + refundGas)
+	value := new(big.Int)
+
+	if offer.EthAsset == types.EthAssetETH {
+		value.Set(providedAmount.BigInt())
+		gas += estimateNewSwapGas(ctx, ec, contractAddr, offer.EthAsset, value, newSwapETHGas)
+	} else {
+		gas += estimateApproveGas(ctx, ec, offer.EthAsset, contractAddr, providedAmount.BigInt())
+		gas += estimateNewSwapGas(ctx, ec, contractAddr, offer.EthAsset,
+			providedAmount.BigInt(), newSwapERC20Gas)
+	}
+
+	cost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))
+	cost.Add(cost, value)
+
+	return coins.NewWeiAmount(cost), nil
+}
+
+// estimateNewSwapGas simulates a newSwap call locking value of the passed
+// asset, returning fallbackGas if the simulation fails.
+func estimateNewSwapGas(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	contractAddr ethcommon.Address,
+	asset types.EthAsset,
+	value *big.Int,
+	fallbackGas uint64,
+) uint64 {
+	// the commitments and claimer only need to be non-zero for the simulation
+	var placeholder [32]byte
+	placeholder[0] = 1
+
+	data, err := contracts.SwapFactoryParsedABI.Pack(
+		"newSwap",
+		placeholder,
+		placeholder,
+		ec.Address(),
+		big.NewInt(3600),
+		ethcommon.Address(asset),
+		value,
+		generateNonce(),
+	)
+	if err != nil {
+		return fallbackGas
+	}
+
+	msg := ethereum.CallMsg{
+		From: ec.Address(),
+		To:   &contractAddr,
+		Data: data,
+	}
+	if asset == types.EthAssetETH {
+		msg.Value = value
+	}
+
+	gas, err := ec.Raw().EstimateGas(ctx, msg)
+	if err != nil {
+		log.Debugf("failed to estimate newSwap gas, using %d: %s", fallbackGas, err)
+		return fallbackGas
+	}
+
+	return gas
+}
+
+// estimateApproveGas simulates approving the swap contract to transfer amount
+// of the token, returning approveGas if the simulation fails.
+func estimateApproveGas(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	token types.EthAsset,
+	contractAddr ethcommon.Address,
+	amount *big.Int,
+) uint64 {
+	erc20ABI, err := contracts.IERC20MetaData.GetAbi()
+	if err != nil {
+		return approveGas
+	}
+
+	data, err := erc20ABI.Pack("approve", contractAddr, amount)
+	if err != nil {
+		return approveGas
+	}
+
+	tokenAddr := token.Address()
+	gas, err := ec.Raw().EstimateGas(ctx, ethereum.CallMsg{
+		From: ec.Address(),
+		To:   &tokenAddr,
+		Data: data,
+	})
+	if err != nil {
+		log.Debugf("failed to estimate token approval gas, using %d: %s", approveGas, err)
+		return approveGas
+	}
+
+	return gas
+}
@@ -0,0 +1,38 @@
+package xmrtaker
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestInstance_EstimateTakeCost(t *testing.T) {
+	inst := newTestInstance(t)
+
+	offer := types.NewOffer(
+		coins.ProvidesXMR,
+		apd.New(1, 0),
+		apd.New(2, 0),
+		coins.ToExchangeRate(apd.New(1, -1)),
+		types.EthAssetETH,
+	)
+
+	providesAmount := apd.New(1, -1) // 0.1 ETH
+	cost, err := inst.EstimateTakeCost(offer, providesAmount)
+	require.NoError(t, err)
+
+	// the cost is the locked value plus a non-zero amount of gas
+	lockValue := coins.EtherToWei(providesAmount).BigInt()
+	require.Equal(t, 1, cost.BigInt().Cmp(lockValue))
+
+	gasPrice, err := inst.backend.ETHClient().SuggestGasPrice(inst.backend.Ctx())
+	require.NoError(t, err)
+	maxGas := new(big.Int).SetUint64(newSwapETHGas*2 + setReadyGas + refundGas)
+	maxCost := new(big.Int).Add(lockValue, new(big.Int).Mul(gasPrice, maxGas))
+	require.Equal(t, -1, cost.BigInt().Cmp(maxCost))
+}
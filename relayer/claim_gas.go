@@ -0,0 +1,99 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+const (
+	// DefaultClaimGasBufferPercent is how much is added to the estimated gas
+	// of relayed claims by default, as the estimate is made before the claim
+	// is relayed.
+	DefaultClaimGasBufferPercent = 20
+
+	// DefaultMaxClaimGas is the default gas limit ceiling of relayed claims,
+	// both for claims that we sign and for claims that we relay.
+	DefaultMaxClaimGas = 300_000
+)
+
+// ClaimGasConfig configures the gas limits of relayed claims. Zero values use
+// the defaults.
+type ClaimGasConfig struct {
+	// BufferPercent is added to the estimated gas of claims that we sign.
+	BufferPercent uint64
+
+	// MaxGas is the highest gas limit that we sign claims with, or relay
+	// claims with.
+	MaxGas uint64
+}
+
+func (c *ClaimGasConfig) bufferPercent() uint64 {
+	if c == nil || c.BufferPercent == 0 {
+		return DefaultClaimGasBufferPercent
+	}
+	return c.BufferPercent
+}
+
+func (c *ClaimGasConfig) maxGas() uint64 {
+	if c == nil || c.MaxGas == 0 {
+		return DefaultMaxClaimGas
+	}
+	return c.MaxGas
+}
+
+// estimateClaimGas returns the gas limit to sign the forwarded claim with,
+// which is the estimated gas of the claim, made by the forwarder, plus the
+// configured buffer. If the gas can't be estimated, relayedClaimGas is
+// returned, so that the claim can still be relayed. An error is returned if
+// the buffered estimate is over the configured ceiling.
+func estimateClaimGas(
+	ctx context.Context,
+	ec *ethclient.Client,
+	swapFactoryAddress ethcommon.Address,
+	forwarderAddress ethcommon.Address,
+	swap *contracts.SwapFactorySwap,
+	secret *[32]byte,
+	feeWei *big.Int,
+	conf *ClaimGasConfig,
+) (uint64, error) {
+	calldata, err := getClaimRelayerTxCalldata(feeWei, swap, secret)
+	if err != nil {
+		return 0, err
+	}
+
+	// the forwarder appends the address of the claimer to the calldata, which
+	// the swap factory takes as the sender of calls from its trusted forwarder
+	estimate, err := ec.EstimateGas(ctx, ethereum.CallMsg{
+		From: forwarderAddress,
+		To:   &swapFactoryAddress,
+		Data: append(calldata, swap.Claimer.Bytes()...),
+	})
+	if err != nil {
+		log.Warnf("failed to estimate gas of relayed claim, using %d: %s", relayedClaimGas, err)
+		return relayedClaimGas, nil
+	}
+
+	gas := estimate + estimate*conf.bufferPercent()/100
+	if gas > conf.maxGas() {
+		return 0, fmt.Errorf("%w: estimated %d is over %d", errClaimGasTooHigh, gas, conf.maxGas())
+	}
+
+	log.Debugf("estimated gas of relayed claim is %d, signing it with %d", estimate, gas)
+	return gas, nil
+}
+
+// claimRequestGas returns the gas limit that the claim request was signed with.
+func claimRequestGas(req *message.RelayClaimRequest) uint64 {
+	if req.Gas == 0 {
+		return relayedClaimGas
+	}
+	return req.Gas
+}
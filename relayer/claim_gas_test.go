@@ -0,0 +1,25 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+func TestClaimGasConfig_defaults(t *testing.T) {
+	var conf *ClaimGasConfig
+	require.Equal(t, uint64(DefaultClaimGasBufferPercent), conf.bufferPercent())
+	require.Equal(t, uint64(DefaultMaxClaimGas), conf.maxGas())
+
+	conf = &ClaimGasConfig{BufferPercent: 50, MaxGas: 100_000}
+	require.Equal(t, uint64(50), conf.bufferPercent())
+	require.Equal(t, uint64(100_000), conf.maxGas())
+}
+
+func TestClaimRequestGas(t *testing.T) {
+	// requests from makers that don't estimate gas were signed with the fixed limit
+	require.Equal(t, uint64(relayedClaimGas), claimRequestGas(&message.RelayClaimRequest{}))
+	require.Equal(t, uint64(90_000), claimRequestGas(&message.RelayClaimRequest{Gas: 90_000}))
+}
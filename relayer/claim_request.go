@@ -3,7 +3,6 @@ package relayer
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"math/big"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -11,39 +10,105 @@ import (
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
 const (
+	// relayedClaimGas is the gas limit of forwarded claims when it can't be
+	// estimated, and of claims from claimers that don't send the limit they
+	// signed.
 	relayedClaimGas = 70000
 )
 
-// FeeWei and FeeEth are the fixed 0.009 ETH fee for using a swap relayer to claim.
+// FeeWei and FeeEth are the default 0.009 ETH fee for using a swap relayer to
+// claim, which relayers on the legacy relay protocol always charge.
 var (
 	FeeWei = big.NewInt(9e15)
 	FeeEth = coins.NewWeiAmount(FeeWei).AsEther()
 )
 
 // CreateRelayClaimRequest fills and returns a RelayClaimRequest ready for
-// submission to a relayer.
+// submission to a relayer, with the forward request signed by the claimer's
+// Signer for the passed relayer fee. The gas limit of the claim is estimated,
+// as set by gasConf, which uses the defaults if nil.
 func CreateRelayClaimRequest(
 	ctx context.Context,
-	claimerEthKey *ecdsa.PrivateKey,
+	signer extethclient.Signer,
 	ec *ethclient.Client,
 	swapFactoryAddress ethcommon.Address,
 	forwarderAddress ethcommon.Address,
 	swap *contracts.SwapFactorySwap,
 	secret *[32]byte,
+	feeWei *big.Int,
+	gasConf *ClaimGasConfig,
+) (*message.RelayClaimRequest, error) {
+
+	gas, err := estimateClaimGas(ctx, ec, swapFactoryAddress, forwarderAddress, swap, secret, feeWei, gasConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return createRelayClaimRequest(ctx, signer, ec, swapFactoryAddress, forwarderAddress, swap, secret, feeWei, gas)
+}
+
+// CreateLegacyRelayClaimRequest is CreateRelayClaimRequest for relayers on the
+// legacy relay protocol, which relay every claim with the fixed relayedClaimGas
+// limit, for their own fee. The request is signed with that limit, and doesn't
+// set its Gas or Fee.
+func CreateLegacyRelayClaimRequest(
+	ctx context.Context,
+	signer extethclient.Signer,
+	ec *ethclient.Client,
+	swapFactoryAddress ethcommon.Address,
+	forwarderAddress ethcommon.Address,
+	swap *contracts.SwapFactorySwap,
+	secret *[32]byte,
+	feeWei *big.Int,
+) (*message.RelayClaimRequest, error) {
+
+	req, err := createRelayClaimRequest(
+		ctx,
+		signer,
+		ec,
+		swapFactoryAddress,
+		forwarderAddress,
+		swap,
+		secret,
+		feeWei,
+		relayedClaimGas,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Gas = 0
+	req.Fee = nil
+	return req, nil
+}
+
+func createRelayClaimRequest(
+	ctx context.Context,
+	signer extethclient.Signer,
+	ec *ethclient.Client,
+	swapFactoryAddress ethcommon.Address,
+	forwarderAddress ethcommon.Address,
+	swap *contracts.SwapFactorySwap,
+	secret *[32]byte,
+	feeWei *big.Int,
+	gas uint64,
 ) (*message.RelayClaimRequest, error) {
 
 	signature, err := createForwarderSignature(
 		ctx,
-		claimerEthKey,
+		signer,
 		ec,
 		swapFactoryAddress,
 		forwarderAddress,
 		swap,
 		secret,
+		feeWei,
+		gas,
 	)
 	if err != nil {
 		return nil, err
@@ -54,5 +119,7 @@ func CreateRelayClaimRequest(
 		Swap:               swap,
 		Secret:             secret[:],
 		Signature:          signature,
+		Gas:                gas,
+		Fee:                feeWei,
 	}, nil
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/tests"
 )
 
@@ -48,18 +49,19 @@ func TestCreateRelayClaimRequest(t *testing.T) {
 	ctx := context.Background()
 	ethKey := tests.GetMakerTestKey(t)
 	claimer := crypto.PubkeyToAddress(*ethKey.Public().(*ecdsa.PublicKey))
-	ec, _ := tests.NewEthClient(t)
+	ec, chainID := tests.NewEthClient(t)
+	signer := extethclient.NewPrivateKeySigner(ethKey, chainID)
 	secret := [32]byte{0x1}
 	swapFactoryAddr, forwarderAddr := deployContracts(t, ec, ethKey)
 
 	// success path
 	swap := createTestSwap(claimer)
-	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapFactoryAddr, forwarderAddr, swap, &secret)
+	req, err := CreateRelayClaimRequest(ctx, signer, ec, swapFactoryAddr, forwarderAddr, swap, &secret, FeeWei, nil)
 	require.NoError(t, err)
 	require.NotNil(t, req)
 
 	// change the ethkey to not match the claimer address to trigger the error path
-	ethKey = tests.GetTakerTestKey(t)
-	_, err = CreateRelayClaimRequest(ctx, ethKey, ec, swapFactoryAddr, forwarderAddr, swap, &secret)
+	signer = extethclient.NewPrivateKeySigner(tests.GetTakerTestKey(t), chainID)
+	_, err = CreateRelayClaimRequest(ctx, signer, ec, swapFactoryAddr, forwarderAddr, swap, &secret, FeeWei, nil)
 	require.ErrorContains(t, err, "signing key does not match claimer")
 }
@@ -0,0 +1,43 @@
+package relayer
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+var (
+	errForwarderNotAccepted   = errors.New("swap factory's trusted forwarder is not accepted by this relayer")
+	errForwarderNonceMismatch = errors.New("failed to verify signature after refreshing forwarder nonce")
+	errAssetNotRelayed        = errors.New("relaying is not supported for asset")
+	errClaimGasTooHigh        = errors.New("gas limit of relayed claim is too high")
+	errRelayerFeeTooLow       = errors.New("relayer fee of claim is less than ours")
+	errPayoutBelowGas         = errors.New("relayer fees don't cover the gas of forwarding them")
+)
+
+// invalidClaimError wraps the errors of claim requests that are invalid, or
+// whose claim reverts, so that they match message.ErrInvalidRelayClaim.
+type invalidClaimError struct {
+	err error
+}
+
+func (e invalidClaimError) Error() string {
+	return e.err.Error()
+}
+
+func (e invalidClaimError) Unwrap() error {
+	return e.err
+}
+
+func (e invalidClaimError) Is(target error) bool {
+	return target == message.ErrInvalidRelayClaim
+}
+
+// isRevert returns whether err is from a call, gas estimate or transaction
+// that reverted, as opposed to eg. an RPC error.
+func isRevert(err error) bool {
+	return errors.Is(err, vm.ErrExecutionReverted) || strings.Contains(err.Error(), vm.ErrExecutionReverted.Error())
+}
@@ -0,0 +1,89 @@
+package relayer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// FeeTable holds the minimum fee that a relayer charges for relaying the claim
+// of a swap, per asset of the swap, in the asset's smallest unit. Claims of
+// swaps for assets that aren't in the table are rejected. Fees can be replaced
+// while the relayer is running.
+type FeeTable struct {
+	mu   sync.RWMutex
+	fees map[types.EthAsset]*big.Int
+}
+
+// NewFeeTable returns a fee table with the passed fees.
+func NewFeeTable(fees map[types.EthAsset]*big.Int) *FeeTable {
+	return &FeeTable{fees: fees}
+}
+
+// DefaultFeeTable returns a fee table that only relays claims of ETH swaps, for
+// FeeWei.
+func DefaultFeeTable() *FeeTable {
+	return NewFeeTable(map[types.EthAsset]*big.Int{
+		types.EthAssetETH: FeeWei,
+	})
+}
+
+// Fee returns the minimum fee for relaying claims of swaps for the asset.
+func (t *FeeTable) Fee(asset types.EthAsset) (*big.Int, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	fee, has := t.fees[asset]
+	if !has {
+		return nil, fmt.Errorf("%w: %s", errAssetNotRelayed, asset)
+	}
+
+	return fee, nil
+}
+
+// Fees returns a copy of the fees of the table.
+func (t *FeeTable) Fees() map[types.EthAsset]*big.Int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	fees := make(map[types.EthAsset]*big.Int, len(t.fees))
+	for asset, fee := range t.fees {
+		fees[asset] = new(big.Int).Set(fee)
+	}
+
+	return fees
+}
+
+// SetFees replaces all fees of the table.
+func (t *FeeTable) SetFees(fees map[types.EthAsset]*big.Int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fees = fees
+}
+
+// LoadFees reads relayer fees from a JSON file holding an object that maps each
+// relayed asset, "ETH" or a token address, to its fee in the asset's smallest
+// unit. For example: {"ETH": 9000000000000000}
+func LoadFees(path string) (map[types.EthAsset]*big.Int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fees := make(map[types.EthAsset]*big.Int)
+	if err = json.Unmarshal(data, &fees); err != nil {
+		return nil, fmt.Errorf("failed to parse relayer fees in %s: %w", path, err)
+	}
+
+	for asset, fee := range fees {
+		if fee == nil || fee.Sign() < 0 {
+			return nil, fmt.Errorf("relayer fee of %s in %s must be non-negative", asset, path)
+		}
+	}
+
+	return fees, nil
+}
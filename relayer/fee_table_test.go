@@ -0,0 +1,54 @@
+package relayer
+
+import (
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestFeeTable(t *testing.T) {
+	table := DefaultFeeTable()
+
+	fee, err := table.Fee(types.EthAssetETH)
+	require.NoError(t, err)
+	require.Equal(t, FeeWei, fee)
+
+	token := types.EthAsset(ethcommon.HexToAddress("0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8"))
+	_, err = table.Fee(token)
+	require.ErrorIs(t, err, errAssetNotRelayed)
+
+	table.SetFees(map[types.EthAsset]*big.Int{token: big.NewInt(100)})
+	fee, err = table.Fee(token)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), fee)
+	_, err = table.Fee(types.EthAssetETH)
+	require.ErrorIs(t, err, errAssetNotRelayed)
+}
+
+func TestLoadFees(t *testing.T) {
+	feesFile := path.Join(t.TempDir(), "fees.json")
+	data := `{"ETH": 5000000000000000, "0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8": 100}`
+	require.NoError(t, os.WriteFile(feesFile, []byte(data), 0600))
+
+	fees, err := LoadFees(feesFile)
+	require.NoError(t, err)
+	token := types.EthAsset(ethcommon.HexToAddress("0xa1E32d14AC4B6d8c1791CAe8E9baD46a1E15B7a8"))
+	require.Equal(t, map[types.EthAsset]*big.Int{
+		types.EthAssetETH: big.NewInt(5e15),
+		token:             big.NewInt(100),
+	}, fees)
+
+	require.NoError(t, os.WriteFile(feesFile, []byte(`{"ETH": -1}`), 0600))
+	_, err = LoadFees(feesFile)
+	require.ErrorContains(t, err, "must be non-negative")
+
+	require.NoError(t, os.WriteFile(feesFile, []byte(`{"BTC": 1}`), 0600))
+	_, err = LoadFees(feesFile)
+	require.ErrorContains(t, err, `invalid asset value "BTC"`)
+}
@@ -2,7 +2,6 @@ package relayer
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 
@@ -14,19 +13,22 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 )
 
 func createForwarderSignature(
 	ctx context.Context,
-	claimerEthKey *ecdsa.PrivateKey,
+	signer extethclient.Signer,
 	ec *ethclient.Client,
 	swapFactoryAddress ethcommon.Address,
 	forwarderAddress ethcommon.Address,
 	swap *contracts.SwapFactorySwap,
 	secret *[32]byte,
+	feeWei *big.Int,
+	gas uint64,
 ) ([]byte, error) {
 
-	if swap.Claimer != ethcrypto.PubkeyToAddress(claimerEthKey.PublicKey) {
+	if swap.Claimer != signer.Address() {
 		return nil, fmt.Errorf("signing key does not match claimer %s", swap.Claimer)
 	}
 
@@ -42,9 +44,11 @@ func createForwarderSignature(
 
 	forwarderReq, err := createForwarderRequest(
 		nonce,
+		feeWei,
 		swapFactoryAddress,
 		swap,
 		secret,
+		gas,
 	)
 	if err != nil {
 		return nil, err
@@ -55,23 +59,29 @@ func createForwarderSignature(
 		return nil, fmt.Errorf("failed to get forward request digest: %w", err)
 	}
 
-	signature, err := rcommon.NewKeyFromPrivateKey(claimerEthKey).Sign(digest)
+	signature, err := signer.SignHash(digest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign forward request digest: %w", err)
 	}
 
+	// the forwarder only accepts signatures with V being 27 or 28
+	signature[ethcrypto.RecoveryIDOffset] += 27
+
 	return signature, nil
 }
 
-// createForwarderRequest creates the forwarder request, which we sign the digest of.
+// createForwarderRequest creates the forwarder request, which we sign the digest
+// of, with the passed gas limit for the forwarded claim.
 func createForwarderRequest(
 	nonce *big.Int,
+	feeWei *big.Int,
 	swapFactoryAddress ethcommon.Address,
 	swap *contracts.SwapFactorySwap,
 	secret *[32]byte,
+	gas uint64,
 ) (*gsnforwarder.IForwarderForwardRequest, error) {
 
-	calldata, err := getClaimRelayerTxCalldata(FeeWei, swap, secret)
+	calldata, err := getClaimRelayerTxCalldata(feeWei, swap, secret)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +90,7 @@ func createForwarderRequest(
 		From:           swap.Claimer,
 		To:             swapFactoryAddress,
 		Value:          big.NewInt(0),
-		Gas:            big.NewInt(relayedClaimGas),
+		Gas:            new(big.Int).SetUint64(gas),
 		Nonce:          nonce,
 		Data:           calldata,
 		ValidUntilTime: big.NewInt(0),
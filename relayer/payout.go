@@ -0,0 +1,230 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/block"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+)
+
+// DefaultPayoutMinWei is the least ETH, by default, that FeePayout forwards in
+// one transfer.
+var DefaultPayoutMinWei = big.NewInt(5e16) // 0.05 ETH
+
+// FeePayout forwards the fees of relayed claims to a payout address. The swap
+// factory pays relayer fees to the transaction's origin, which is our signing
+// key, and has no way to name a different recipient, so fees are forwarded
+// after the claims to keep them out of the hot wallet.
+//
+// Only what the claims earned is forwarded, so that relaying doesn't drain the
+// signing key: the ETH fee of each claim less the gas we paid for the claim,
+// and less the gas of the transfer that forwards it. Net ETH fees accumulate
+// until they reach a minimum, so that few transfers are paid for. Token fees
+// are forwarded whole, as the gas of claims is paid in ETH.
+//
+// Fees are forwarded in the background, so that relay responses don't wait on
+// them. Fees of claims that are included while a transfer is in flight are
+// summed and forwarded together, and fees that failed to be forwarded are
+// retried with the fees of the next claim.
+type FeePayout struct {
+	ctx    context.Context
+	ec     extethclient.EthClient
+	addr   ethcommon.Address
+	minETH *big.Int
+
+	mu      sync.Mutex
+	pending map[ethcommon.Address]*big.Int // asset -> fees to forward
+	wake    chan struct{}
+}
+
+// NewFeePayout returns a FeePayout that forwards fees to payoutAddr until ctx
+// is done. ETH is forwarded once at least minETH wei of net fees are pending,
+// where nil uses DefaultPayoutMinWei.
+func NewFeePayout(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	payoutAddr ethcommon.Address,
+	minETH *big.Int,
+) *FeePayout {
+	if minETH == nil {
+		minETH = DefaultPayoutMinWei
+	}
+
+	p := &FeePayout{
+		ctx:     ctx,
+		ec:      ec,
+		addr:    payoutAddr,
+		minETH:  minETH,
+		pending: make(map[ethcommon.Address]*big.Int),
+		wake:    make(chan struct{}, 1),
+	}
+	go p.run()
+	return p
+}
+
+// add queues the fee of a relayed claim, whose gas cost us claimGasCost wei,
+// to be forwarded.
+func (p *FeePayout) add(asset ethcommon.Address, fee *big.Int, claimGasCost *big.Int) {
+	if p.addr == p.ec.Address() {
+		return
+	}
+
+	netFee := new(big.Int).Set(fee)
+	if types.EthAsset(asset) == types.EthAssetETH {
+		netFee.Sub(netFee, claimGasCost)
+	}
+
+	if netFee.Sign() <= 0 {
+		log.Debugf("relayer fee of %s didn't cover the claim's gas of %s wei, nothing to forward", fee, claimGasCost)
+		return
+	}
+
+	p.mu.Lock()
+	if pending, has := p.pending[asset]; has {
+		pending.Add(pending, netFee)
+	} else {
+		p.pending[asset] = netFee
+	}
+	p.mu.Unlock()
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// takeReady returns the queued fees that are ready to be forwarded, and
+// removes them from the queue. ETH fees stay queued until they reach the
+// minimum.
+func (p *FeePayout) takeReady() map[ethcommon.Address]*big.Int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ready := make(map[ethcommon.Address]*big.Int)
+	for asset, fee := range p.pending {
+		if types.EthAsset(asset) == types.EthAssetETH && fee.Cmp(p.minETH) < 0 {
+			continue
+		}
+		ready[asset] = fee
+		delete(p.pending, asset)
+	}
+
+	return ready
+}
+
+func (p *FeePayout) run() {
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-p.wake:
+		}
+
+		for asset, fee := range p.takeReady() {
+			txHash, sent, err := forwardFee(p.ctx, p.ec, asset, fee, p.addr)
+			if err != nil {
+				// the fee stays with our signing key until the fee of the
+				// next claim is forwarded
+				log.Warnf("failed to forward relayer fees of %s to payout address %s: %s", fee, p.addr, err)
+				p.mu.Lock()
+				if pending, has := p.pending[asset]; has {
+					pending.Add(pending, fee)
+				} else {
+					p.pending[asset] = fee
+				}
+				p.mu.Unlock()
+				continue
+			}
+
+			log.Infof("forwarded relayer fees of %s to payout address %s: tx=%s", sent, p.addr, txHash)
+		}
+	}
+}
+
+// forwardFee sends relayer fees of the asset to the payout address, and returns
+// the amount sent, which for ETH is less the gas of the transfer. The client's
+// lock is only held until the transfer is sent, so that claims can be relayed
+// while we wait for it to be included.
+func forwardFee(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	asset ethcommon.Address,
+	fee *big.Int,
+	payoutAddr ethcommon.Address,
+) (ethcommon.Hash, *big.Int, error) {
+	tx, sent, err := sendFee(ctx, ec, asset, fee, payoutAddr)
+	if err != nil {
+		return ethcommon.Hash{}, nil, err
+	}
+
+	if _, err = block.WaitForReceipt(ctx, ec.Raw(), tx.Hash()); err != nil {
+		return ethcommon.Hash{}, nil, err
+	}
+
+	return tx.Hash(), sent, nil
+}
+
+func sendFee(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	asset ethcommon.Address,
+	fee *big.Int,
+	payoutAddr ethcommon.Address,
+) (*ethtypes.Transaction, *big.Int, error) {
+	ec.Lock()
+	defer ec.Unlock()
+
+	txOpts, err := ec.TxOpts(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tx *ethtypes.Transaction
+	if types.EthAsset(asset) == types.EthAssetETH {
+		// the transfer's gas is paid out of the fees, with the gas price fixed
+		// so that its cost is known up front
+		if txOpts.GasPrice == nil {
+			if txOpts.GasPrice, err = ec.SuggestGasPrice(ctx); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		transferGasCost := new(big.Int).Mul(txOpts.GasPrice, big.NewInt(int64(params.TxGas)))
+		txOpts.Value = new(big.Int).Sub(fee, transferGasCost)
+		if txOpts.Value.Sign() <= 0 {
+			return nil, nil, fmt.Errorf("%w: fees of %s, transfer gas of %s", errPayoutBelowGas, fee, transferGasCost)
+		}
+
+		tx, err = bind.NewBoundContract(payoutAddr, abi.ABI{}, nil, ec.Raw(), nil).Transfer(txOpts)
+		return tx, txOpts.Value, err
+	}
+
+	token, err := contracts.NewIERC20(asset, ec.Raw())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err = token.Transfer(txOpts, payoutAddr, fee)
+	return tx, fee, err
+}
+
+// claimGasCost returns how much the gas of the included claim cost us.
+func claimGasCost(tx *ethtypes.Transaction, receipt *ethtypes.Receipt) *big.Int {
+	gasPrice := receipt.EffectiveGasPrice
+	if gasPrice == nil {
+		gasPrice = tx.GasPrice()
+	}
+
+	return new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+}
@@ -0,0 +1,129 @@
+package relayer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/tests"
+)
+
+func TestForwardFee_ETH(t *testing.T) {
+	ctx := context.Background()
+	ec, err := extethclient.NewEthClient(ctx, common.Development, common.DefaultEthEndpoint, tests.GetMakerTestKey(t))
+	require.NoError(t, err)
+
+	payoutAddr := ethcommon.Address{0x9, 0x9, 0x1}
+	before, err := ec.Raw().BalanceAt(ctx, payoutAddr, nil)
+	require.NoError(t, err)
+
+	// the gas of the transfer is paid out of the fee
+	_, sent, err := forwardFee(ctx, ec, ethcommon.Address{}, FeeWei, payoutAddr)
+	require.NoError(t, err)
+	require.Negative(t, sent.Cmp(FeeWei))
+
+	after, err := ec.Raw().BalanceAt(ctx, payoutAddr, nil)
+	require.NoError(t, err)
+	require.Equal(t, sent, new(big.Int).Sub(after, before))
+
+	// fees that don't cover the gas aren't forwarded
+	_, _, err = forwardFee(ctx, ec, ethcommon.Address{}, big.NewInt(1), payoutAddr)
+	require.ErrorIs(t, err, errPayoutBelowGas)
+}
+
+func TestForwardFee_ERC20(t *testing.T) {
+	ctx := context.Background()
+	key := tests.GetMakerTestKey(t)
+	ec, err := extethclient.NewEthClient(ctx, common.Development, common.DefaultEthEndpoint, key)
+	require.NoError(t, err)
+
+	txOpts, err := bind.NewKeyedTransactorWithChainID(key, ec.ChainID())
+	require.NoError(t, err)
+	tokenAddr, tx, token, err := contracts.DeployERC20Mock(
+		txOpts,
+		ec.Raw(),
+		"Mock",
+		"MOCK",
+		ec.Address(),
+		big.NewInt(1000),
+	)
+	require.NoError(t, err)
+	_, err = bind.WaitDeployed(ctx, ec.Raw(), tx)
+	require.NoError(t, err)
+
+	payoutAddr := ethcommon.Address{0x9, 0x9, 0x2}
+	_, sent, err := forwardFee(ctx, ec, tokenAddr, big.NewInt(30), payoutAddr)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), sent.Int64())
+
+	balance, err := token.BalanceOf(nil, payoutAddr)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), balance.Int64())
+}
+
+func TestFeePayout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ec, err := extethclient.NewEthClient(ctx, common.Development, common.DefaultEthEndpoint, tests.GetMakerTestKey(t))
+	require.NoError(t, err)
+
+	payoutAddr := ethcommon.Address{0x9, 0x9, 0x3}
+	before, err := ec.Raw().BalanceAt(ctx, payoutAddr, nil)
+	require.NoError(t, err)
+
+	// net fees are held until they reach the minimum, then forwarded together
+	claimGas := big.NewInt(1e15)
+	p := NewFeePayout(ctx, ec, payoutAddr, big.NewInt(15e15))
+	p.add(ethcommon.Address{}, FeeWei, claimGas)
+	require.Empty(t, p.takeReady())
+	p.add(ethcommon.Address{}, FeeWei, claimGas)
+
+	// the payout address gets the fees less the gas of the claims and of the
+	// transfer
+	netFees := new(big.Int).Mul(new(big.Int).Sub(FeeWei, claimGas), big.NewInt(2))
+	require.Eventually(t, func() bool {
+		after, err := ec.Raw().BalanceAt(ctx, payoutAddr, nil) //nolint:govet
+		if err != nil || after.Cmp(before) <= 0 {
+			return false
+		}
+		received := new(big.Int).Sub(after, before)
+		return received.Cmp(netFees) < 0
+	}, 30*time.Second, 100*time.Millisecond)
+}
+
+func TestFeePayout_add(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ec := extethclient.NewMockEthClient(ctrl)
+	ec.EXPECT().Address().Return(ethcommon.Address{0x1}).AnyTimes()
+
+	p := &FeePayout{
+		ec:      ec,
+		addr:    ethcommon.Address{0x2},
+		minETH:  big.NewInt(100),
+		pending: make(map[ethcommon.Address]*big.Int),
+		wake:    make(chan struct{}, 1),
+	}
+	tokenAddr := ethcommon.Address{0x3}
+
+	// fees that don't cover their claim's gas leave nothing to forward
+	p.add(ethcommon.Address{}, big.NewInt(50), big.NewInt(60))
+	require.Empty(t, p.pending)
+
+	// ETH fees are held until their net reaches the minimum, token fees aren't
+	p.add(ethcommon.Address{}, big.NewInt(80), big.NewInt(20))
+	p.add(tokenAddr, big.NewInt(5), big.NewInt(20))
+	require.Equal(t, map[ethcommon.Address]*big.Int{tokenAddr: big.NewInt(5)}, p.takeReady())
+
+	p.add(ethcommon.Address{}, big.NewInt(80), big.NewInt(20))
+	require.Equal(t, map[ethcommon.Address]*big.Int{{}: big.NewInt(120)}, p.takeReady())
+	require.Empty(t, p.pending)
+}
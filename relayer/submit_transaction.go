@@ -2,36 +2,60 @@ package relayer
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	logging "github.com/ipfs/go-log"
 
-	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
+var log = logging.Logger("relayer")
+
 // ValidateAndSendTransaction sends the relayed transaction to the network if it validates successfully.
+// If acceptedForwarders is not empty, requests are only relayed if the swap factory's trusted
+// forwarder is one of them. If includeDetails is set, the response also reports the fee charged
+// and the gas used and block number of the included transaction. The fee for the swap's asset is
+// taken from fees, or from DefaultFeeTable if fees is nil. If payout is set, the fee is queued
+// to be forwarded by it once the claim is included. Claims whose gas limit is over the ceiling
+// of gasConf, which uses the defaults if nil, are rejected.
+//
+// Errors of requests that are invalid, or whose claim reverts, match
+// message.ErrInvalidRelayClaim. Any other error is transient.
 func ValidateAndSendTransaction(
 	ctx context.Context,
 	req *message.RelayClaimRequest,
 	ec extethclient.EthClient,
 	ourSFContractAddr ethcommon.Address,
+	acceptedForwarders []ethcommon.Address,
+	includeDetails bool,
+	fees *FeeTable,
+	payout *FeePayout,
+	gasConf *ClaimGasConfig,
 ) (*message.RelayClaimResponse, error) {
-
-	err := validateClaimRequest(ctx, req, ec.Raw(), ourSFContractAddr)
-	if err != nil {
-		return nil, err
+	if fees == nil {
+		fees = DefaultFeeTable()
 	}
 
-	reqSwapFactory, err := contracts.NewSwapFactory(req.SwapFactoryAddress, ec.Raw())
-	if err != nil {
-		return nil, err
+	if gas := claimRequestGas(req); gas > gasConf.maxGas() {
+		return nil, invalidClaimError{fmt.Errorf("%w: %d is over %d", errClaimGasTooHigh, gas, gasConf.maxGas())}
 	}
 
-	reqForwarderAddr, err := reqSwapFactory.TrustedForwarder(&bind.CallOpts{Context: ctx})
+	// Submit the same forwarder request that the signature was verified
+	// against, so we don't re-read a nonce that may have changed since.
+	reqForwarderAddr, forwarderReq, fee, err := validateClaimRequest(
+		ctx,
+		req,
+		ec.Raw(),
+		ourSFContractAddr,
+		acceptedForwarders,
+		fees,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -41,19 +65,6 @@ func ValidateAndSendTransaction(
 		return nil, err
 	}
 
-	nonce, err := reqForwarder.GetNonce(&bind.CallOpts{Context: ctx}, req.Swap.Claimer)
-	if err != nil {
-		return nil, err
-	}
-
-	// The size of request.Secret was vetted when it was deserialized
-	secret := (*[32]byte)(req.Secret)
-
-	forwarderReq, err := createForwarderRequest(nonce, req.SwapFactoryAddress, req.Swap, secret)
-	if err != nil {
-		return nil, err
-	}
-
 	// Lock the wallet's nonce until we get a receipt
 	ec.Lock()
 	defer ec.Unlock()
@@ -72,13 +83,40 @@ func ValidateAndSendTransaction(
 		req.Signature,
 	)
 	if err != nil {
+		// the claim reverted when its gas was estimated
+		if isRevert(err) {
+			return nil, invalidClaimError{err}
+		}
 		return nil, err
 	}
 
-	_, err = block.WaitForReceipt(ctx, ec.Raw(), tx.Hash())
+	receipt, err := block.WaitForReceipt(ctx, ec.Raw(), tx.Hash())
 	if err != nil {
+		if claimFailed(ctx, ec, tx.Hash()) {
+			return nil, invalidClaimError{err}
+		}
 		return nil, err
 	}
 
-	return &message.RelayClaimResponse{TxHash: tx.Hash()}, nil
+	if payout != nil {
+		payout.add(req.Swap.Asset, fee, claimGasCost(tx, receipt))
+	}
+
+	resp := &message.RelayClaimResponse{TxHash: tx.Hash()}
+	if includeDetails {
+		resp.DetailsVersion = message.RelayClaimDetailsVersion
+		resp.FeeWei = coins.NewWeiAmount(fee)
+		resp.GasUsed = receipt.GasUsed
+		resp.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+
+	return resp, nil
+}
+
+// claimFailed returns whether our claim transaction was included and failed, in
+// which case relaying it again would fail too, as opposed to it not being
+// included in time.
+func claimFailed(ctx context.Context, ec extethclient.EthClient, txHash ethcommon.Hash) bool {
+	receipt, err := ec.Raw().TransactionReceipt(ctx, txHash)
+	return err == nil && receipt.Status != ethtypes.ReceiptStatusSuccessful
 }
@@ -3,6 +3,7 @@ package relayer
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -15,23 +16,78 @@ import (
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
+// validateClaimRequest validates the claim request, returning the address of the
+// forwarder that the request's swap factory trusts, the forwarder request that
+// the signature was verified against and the relayer fee that was signed for,
+// which is at least the fee of the swap's asset in fees. If acceptedForwarders
+// is not empty, the forwarder must be one of them.
 func validateClaimRequest(
 	ctx context.Context,
 	request *message.RelayClaimRequest,
 	ec *ethclient.Client,
 	ourSFContractAddr ethcommon.Address,
-) error {
-	err := validateClaimValues(ctx, request, ec, ourSFContractAddr)
+	acceptedForwarders []ethcommon.Address,
+	fees *FeeTable,
+) (ethcommon.Address, *gsnforwarder.IForwarderForwardRequest, *big.Int, error) {
+	fee, err := validateClaimValues(ctx, request, ec, ourSFContractAddr, fees)
 	if err != nil {
-		return err
+		return ethcommon.Address{}, nil, nil, err
 	}
 
-	return validateClaimSignature(ctx, ec, request)
+	forwarderAddr, err := getAcceptedForwarder(ctx, ec, request.SwapFactoryAddress, acceptedForwarders)
+	if err != nil {
+		return ethcommon.Address{}, nil, nil, err
+	}
+
+	forwarderReq, err := validateClaimSignature(ctx, ec, request, forwarderAddr, fee)
+	if err != nil {
+		return ethcommon.Address{}, nil, nil, err
+	}
+
+	return forwarderAddr, forwarderReq, fee, nil
 }
 
-// validateClaimValues validates the non-signature aspects of the claim request:
+// getAcceptedForwarder returns the trusted forwarder of the swap factory at
+// swapFactoryAddr. If acceptedForwarders is not empty, an error is returned when
+// the forwarder isn't one of them. This lets a relayer keep validating swaps
+// created against older forwarders, as long as it still accepts them.
+func getAcceptedForwarder(
+	ctx context.Context,
+	ec *ethclient.Client,
+	swapFactoryAddr ethcommon.Address,
+	acceptedForwarders []ethcommon.Address,
+) (ethcommon.Address, error) {
+	swapFactory, err := contracts.NewSwapFactory(swapFactoryAddr, ec)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	forwarderAddr, err := swapFactory.TrustedForwarder(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		if isRevert(err) {
+			return ethcommon.Address{}, invalidClaimError{err}
+		}
+		return ethcommon.Address{}, err
+	}
+
+	if len(acceptedForwarders) == 0 {
+		return forwarderAddr, nil
+	}
+
+	for _, addr := range acceptedForwarders {
+		if addr == forwarderAddr {
+			return forwarderAddr, nil
+		}
+	}
+
+	return ethcommon.Address{}, invalidClaimError{fmt.Errorf("%w: %s", errForwarderNotAccepted, forwarderAddr)}
+}
+
+// validateClaimValues validates the non-signature aspects of the claim request,
+// returning the relayer fee that the claim was signed for:
 //  1. the claim request's swap factory and forwarder contract bytecode matches ours
-//  2. the swap is for ETH and not an ERC20 token
+//  2. the swap's asset has a fee in the fee table, which the request's fee is at
+//     least, or is taken as if the request has none
 //  3. the swap value is strictly greater than the relayer fee
 //  4. TODO: Validate that the swap exists and is in a claimable state?
 func validateClaimValues(
@@ -39,73 +95,130 @@ func validateClaimValues(
 	req *message.RelayClaimRequest,
 	ec *ethclient.Client,
 	ourSwapFactoryAddr ethcommon.Address,
-) error {
+	fees *FeeTable,
+) (*big.Int, error) {
 	// Validate the deployed SwapFactory contract, if it is not at the same address
 	// as our own. The CheckSwapFactoryContractCode method validates both the
 	// SwapFactory bytecode and the Forwarder bytecode.
 	if req.SwapFactoryAddress != ourSwapFactoryAddr {
 		_, err := contracts.CheckSwapFactoryContractCode(ctx, ec, req.SwapFactoryAddress)
 		if err != nil {
-			return err
+			if contracts.IsInvalidContractCode(err) {
+				return nil, invalidClaimError{err}
+			}
+			return nil, err
 		}
 	}
 
 	asset := types.EthAsset(req.Swap.Asset)
-	if asset != types.EthAssetETH {
-		return fmt.Errorf("relaying for ETH Asset %s is not supported", asset)
+	minFee, err := fees.Fee(asset)
+	if err != nil {
+		return nil, invalidClaimError{err}
+	}
+
+	// claimers that don't send the fee that they signed for signed for ours
+	fee := req.Fee
+	if fee == nil {
+		fee = minFee
+	}
+
+	if fee.Cmp(minFee) < 0 {
+		return nil, invalidClaimError{fmt.Errorf("%w: %s is less than %s", errRelayerFeeTooLow, fee, minFee)}
 	}
 
 	// The relayer fee must be strictly less than the swap value
-	if FeeWei.Cmp(req.Swap.Value) >= 0 {
-		return fmt.Errorf("swap value of %s ETH is too low to support %s ETH relayer fee",
-			coins.FmtWeiAsETH(req.Swap.Value), coins.FmtWeiAsETH(FeeWei))
+	if fee.Cmp(req.Swap.Value) >= 0 {
+		if asset == types.EthAssetETH {
+			return nil, invalidClaimError{fmt.Errorf("swap value of %s ETH is too low to support %s ETH relayer fee",
+				coins.FmtWeiAsETH(req.Swap.Value), coins.FmtWeiAsETH(fee))}
+		}
+		return nil, invalidClaimError{fmt.Errorf("swap value of %s is too low to support %s relayer fee of %s",
+			req.Swap.Value, fee, asset)}
 	}
 
-	return nil
+	return fee, nil
 }
 
-// validateClaimSignature validates the claim signature. It is assumed that the
-// request fields have already been validated.
+// validateClaimSignature validates the claim signature against the forwarder
+// trusted by the request's swap factory, returning the forwarder request that
+// the signature is valid for. The claimer must have signed a claim that pays
+// feeWei to the relayer. It is assumed that the request fields have already been
+// validated.
 func validateClaimSignature(
 	ctx context.Context,
 	ec *ethclient.Client,
 	req *message.RelayClaimRequest,
-) error {
+	forwarderAddr ethcommon.Address,
+	feeWei *big.Int,
+) (*gsnforwarder.IForwarderForwardRequest, error) {
 	callOpts := &bind.CallOpts{
 		Context: ctx,
 		From:    ethcommon.Address{0xFF}, // can be any value but zero, which will validate all signatures
 	}
 
-	swapFactory, err := contracts.NewSwapFactory(req.SwapFactoryAddress, ec)
+	forwarder, domainSeparator, err := getForwarderAndDomainSeparator(ctx, ec, forwarderAddr)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	forwarderAddr, err := swapFactory.TrustedForwarder(&bind.CallOpts{Context: ctx})
+	nonce, err := forwarder.GetNonce(callOpts, req.Swap.Claimer)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	forwarder, domainSeparator, err := getForwarderAndDomainSeparator(ctx, ec, forwarderAddr)
-	if err != nil {
-		return err
+	forwarderRequest, err := verifyClaimSignature(callOpts, forwarder, domainSeparator, req, nonce, feeWei)
+	if err == nil {
+		return forwarderRequest, nil
 	}
 
-	nonce, err := forwarder.GetNonce(callOpts, req.Swap.Claimer)
-	if err != nil {
-		return err
+	// The claimer may have signed against a different nonce than the one we
+	// read, if our node was behind theirs or a prior transaction from the
+	// claimer bumped the nonce in between. Re-read the nonce and, if it changed,
+	// retry once before failing the claim.
+	refreshedNonce, nonceErr := forwarder.GetNonce(callOpts, req.Swap.Claimer)
+	if nonceErr != nil || refreshedNonce.Cmp(nonce) == 0 {
+		err = fmt.Errorf("failed to verify signature: %w", err)
+		if isRevert(err) {
+			return nil, invalidClaimError{err}
+		}
+		return nil, err
+	}
+
+	forwarderRequest, verifyErr := verifyClaimSignature(callOpts, forwarder, domainSeparator, req, refreshedNonce, feeWei)
+	if verifyErr != nil {
+		err = fmt.Errorf("%w (nonce %s, then %s): %s", errForwarderNonceMismatch, nonce, refreshedNonce, verifyErr)
+		if isRevert(verifyErr) {
+			return nil, invalidClaimError{err}
+		}
+		return nil, err
 	}
 
+	return forwarderRequest, nil
+}
+
+// verifyClaimSignature verifies the request's signature using the passed
+// forwarder nonce and relayer fee, returning the forwarder request that was
+// verified.
+func verifyClaimSignature(
+	callOpts *bind.CallOpts,
+	forwarder *gsnforwarder.Forwarder,
+	domainSeparator *[32]byte,
+	req *message.RelayClaimRequest,
+	nonce *big.Int,
+	feeWei *big.Int,
+) (*gsnforwarder.IForwarderForwardRequest, error) {
 	secret := (*[32]byte)(req.Secret)
 
 	forwarderRequest, err := createForwarderRequest(
 		nonce,
+		feeWei,
 		req.SwapFactoryAddress,
 		req.Swap,
 		secret,
+		claimRequestGas(req),
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = forwarder.Verify(
@@ -117,8 +230,8 @@ func validateClaimSignature(
 		req.Signature,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to verify signature: %w", err)
+		return nil, err
 	}
 
-	return nil
+	return forwarderRequest, nil
 }
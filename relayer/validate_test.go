@@ -3,7 +3,6 @@ package relayer
 import (
 	"context"
 	"crypto/ecdsa"
-	"fmt"
 	"math/big"
 	"testing"
 
@@ -11,7 +10,9 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 
+	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	"github.com/athanorlabs/atomic-swap/tests"
 )
@@ -66,7 +67,7 @@ func TestValidateRelayerFee(t *testing.T) {
 			Secret:             secret[:],
 		}
 
-		err := validateClaimValues(ctx, request, ec, forwarderAddr)
+		_, err := validateClaimValues(ctx, request, ec, forwarderAddr, DefaultFeeTable())
 		if tc.expectErr != "" {
 			require.ErrorContains(t, err, tc.expectErr, tc.description)
 		} else {
@@ -79,43 +80,88 @@ func Test_validateSignature(t *testing.T) {
 	ctx := context.Background()
 	ethKey := tests.GetMakerTestKey(t)
 	claimer := crypto.PubkeyToAddress(*ethKey.Public().(*ecdsa.PublicKey))
-	ec, _ := tests.NewEthClient(t)
+	ec, chainID := tests.NewEthClient(t)
+	signer := extethclient.NewPrivateKeySigner(ethKey, chainID)
 	secret := [32]byte{0x1}
 	swapFactoryAddr, forwarderAddr := deployContracts(t, ec, ethKey)
 
 	swap := createTestSwap(claimer)
-	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapFactoryAddr, forwarderAddr, swap, &secret)
+	req, err := CreateRelayClaimRequest(ctx, signer, ec, swapFactoryAddr, forwarderAddr, swap, &secret, FeeWei, nil)
 	require.NoError(t, err)
 
 	// success path
-	err = validateClaimSignature(ctx, ec, req)
+	forwarderReq, err := validateClaimSignature(ctx, ec, req, forwarderAddr, FeeWei)
 	require.NoError(t, err)
+	require.Equal(t, claimer, forwarderReq.From)
+	require.Zero(t, forwarderReq.Nonce.Sign())
 
 	// failure path (tamper with an arbitrary byte of the signature)
 	req.Signature[10]++
-	err = validateClaimSignature(ctx, ec, req)
+	_, err = validateClaimSignature(ctx, ec, req, forwarderAddr, FeeWei)
 	require.ErrorContains(t, err, "failed to verify signature")
+	require.NotErrorIs(t, err, errForwarderNonceMismatch)
+
+	// legacy requests are signed with the fixed gas limit, without setting it
+	req, err = CreateLegacyRelayClaimRequest(ctx, signer, ec, swapFactoryAddr, forwarderAddr, swap, &secret, FeeWei)
+	require.NoError(t, err)
+	require.Zero(t, req.Gas)
+	forwarderReq, err = validateClaimSignature(ctx, ec, req, forwarderAddr, FeeWei)
+	require.NoError(t, err)
+	require.Equal(t, uint64(relayedClaimGas), forwarderReq.Gas.Uint64())
 }
 
 func Test_validateClaimRequest(t *testing.T) {
 	ctx := context.Background()
 	ethKey := tests.GetMakerTestKey(t)
 	claimer := crypto.PubkeyToAddress(*ethKey.Public().(*ecdsa.PublicKey))
-	ec, _ := tests.NewEthClient(t)
+	ec, chainID := tests.NewEthClient(t)
+	signer := extethclient.NewPrivateKeySigner(ethKey, chainID)
 	secret := [32]byte{0x1}
 	swapFactoryAddr, forwarderAddr := deployContracts(t, ec, ethKey)
 
 	swap := createTestSwap(claimer)
-	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapFactoryAddr, forwarderAddr, swap, &secret)
+	req, err := CreateRelayClaimRequest(ctx, signer, ec, swapFactoryAddr, forwarderAddr, swap, &secret, FeeWei, nil)
 	require.NoError(t, err)
 
 	// success path
-	err = validateClaimRequest(ctx, req, ec, swapFactoryAddr)
+	fees := DefaultFeeTable()
+	reqForwarderAddr, _, fee, err := validateClaimRequest(ctx, req, ec, swapFactoryAddr, nil, fees)
 	require.NoError(t, err)
+	require.Equal(t, forwarderAddr, reqForwarderAddr)
+	require.Equal(t, FeeWei, fee)
+
+	// the forwarder is one of the accepted forwarders
+	accepted := []ethcommon.Address{{0x1}, forwarderAddr}
+	_, _, _, err = validateClaimRequest(ctx, req, ec, swapFactoryAddr, accepted, fees)
+	require.NoError(t, err)
+
+	// the forwarder is not one of the accepted forwarders
+	_, _, _, err = validateClaimRequest(ctx, req, ec, swapFactoryAddr, []ethcommon.Address{{0x1}}, fees)
+	require.ErrorIs(t, err, errForwarderNotAccepted)
+	require.ErrorIs(t, err, message.ErrInvalidRelayClaim)
+
+	// the claimer signed a claim paying more than our minimum fee
+	fees.SetFees(map[types.EthAsset]*big.Int{types.EthAssetETH: big.NewInt(1e15)})
+	_, _, fee, err = validateClaimRequest(ctx, req, ec, swapFactoryAddr, nil, fees)
+	require.NoError(t, err)
+	require.Equal(t, FeeWei, fee)
+
+	// the claimer signed a claim paying less than our minimum fee
+	fees.SetFees(map[types.EthAsset]*big.Int{types.EthAssetETH: big.NewInt(1e16)})
+	_, _, _, err = validateClaimRequest(ctx, req, ec, swapFactoryAddr, nil, fees)
+	require.ErrorIs(t, err, errRelayerFeeTooLow)
+	require.ErrorIs(t, err, message.ErrInvalidRelayClaim)
+
+	// the claimer signed a claim paying a different fee than the request's
+	fees.SetFees(map[types.EthAsset]*big.Int{types.EthAssetETH: big.NewInt(1e15)})
+	req.Fee = big.NewInt(2e15)
+	_, _, _, err = validateClaimRequest(ctx, req, ec, swapFactoryAddr, nil, fees)
+	require.ErrorContains(t, err, "failed to verify signature")
+	require.ErrorIs(t, err, message.ErrInvalidRelayClaim)
 
-	// test failure path by passing a non-eth asset
+	// test failure path by passing an asset without a fee
 	asset := ethcommon.Address{0x1}
 	req.Swap.Asset = asset
-	err = validateClaimRequest(ctx, req, ec, forwarderAddr)
-	require.ErrorContains(t, err, fmt.Sprintf("relaying for ETH Asset %s is not supported", asset))
+	_, _, _, err = validateClaimRequest(ctx, req, ec, forwarderAddr, nil, fees)
+	require.ErrorIs(t, err, errAssetNotRelayed)
 }
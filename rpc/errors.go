@@ -6,7 +6,13 @@ import (
 
 var (
 	// net_ errors
-	errNoOfferWithID = errors.New("peer does not have offer with given ID")
+	errNoOfferWithID     = errors.New("peer does not have offer with given ID")
+	errOfferNotTakeable  = errors.New("offer can't be taken right now")
+	errAmountNotTakeable = errors.New("amount is outside the offer's currently takeable range")
+	errPeersNotPrivate   = errors.New("allowed peers can only be set on private offers")
+
+	// personal_ errors
+	errBackupPathNotAbsolute = errors.New("backup path must be absolute")
 
 	// swap_ errors
 	errCannotRefund = errors.New("cannot refund if not the ETH provider")
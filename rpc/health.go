@@ -0,0 +1,34 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// healthHandler serves the health status of our ethereum endpoint and monero
+// wallet on a plain HTTP endpoint, for monitoring tools. The response code is
+// 200 if both are reachable and synced, and 503 otherwise.
+type healthHandler struct {
+	pb ProtocolBackend
+}
+
+func newHealthHandler(pb ProtocolBackend) *healthHandler {
+	return &healthHandler{pb: pb}
+}
+
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status, err := h.pb.HealthCheck(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err = json.NewEncoder(w).Encode(status); err != nil {
+		log.Warnf("failed to write health status: %s", err)
+	}
+}
@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestHealthHandler(t *testing.T) {
+	pb := newMockProtocolBackend()
+	handler := newHealthHandler(pb)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	status := new(types.HealthStatus)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(status))
+	require.True(t, status.Healthy)
+
+	pb.health = &types.HealthStatus{
+		Ethereum: &types.EthereumHealth{Reachable: true, Syncing: true, SyncLag: 100},
+		Monero:   &types.MoneroHealth{Reachable: true, DaemonSynchronised: true, WalletRefreshed: true},
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	status = new(types.HealthStatus)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(status))
+	require.False(t, status.Healthy)
+	require.Equal(t, uint64(100), status.Ethereum.SyncLag)
+}
@@ -1,6 +1,7 @@
 package rpc
 
 import (
+	"context"
 	"time"
 
 	"github.com/MarinX/monerorpc/wallet"
@@ -15,6 +16,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	swapnet "github.com/athanorlabs/atomic-swap/net"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/txsender"
@@ -51,14 +53,69 @@ func (*mockNet) Discover(_ string, _ time.Duration) ([]peer.ID, error) {
 	return nil, nil
 }
 
+func (m *mockNet) QueryFiltered(who peer.ID, filter *types.OfferFilter) (*message.QueryResponse, error) {
+	resp, err := m.Query(who)
+	if err != nil || filter == nil {
+		return resp, err
+	}
+
+	resp.Offers, resp.TotalOffers = filter.Apply(resp.Offers)
+	return resp, nil
+}
+
 func (*mockNet) Query(_ peer.ID) (*message.QueryResponse, error) {
-	return &message.QueryResponse{Offers: []*types.Offer{{ID: testSwapID}}}, nil
+	return &message.QueryResponse{
+		Offers: []*types.Offer{{
+			ID:           testSwapID,
+			MinAmount:    apd.New(5, -1),
+			MaxAmount:    apd.New(2, 0),
+			ExchangeRate: coins.ToExchangeRate(apd.New(1, 0)),
+		}},
+		TakeableRanges: []*types.TakeableRange{{
+			OfferID:   testSwapID,
+			MinAmount: apd.New(5, -1),
+			MaxAmount: apd.New(15, -1),
+		}},
+	}, nil
+}
+
+func (m *mockNet) FetchAggregatedOffers(
+	_ context.Context,
+	filter *types.OfferFilter,
+	_ *types.RelayerFeePolicy,
+) ([]*types.OfferWithPeer, error) {
+	resp, err := m.QueryFiltered("12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	book := make([]*types.OfferWithPeer, len(resp.Offers))
+	for i, o := range resp.Offers {
+		book[i] = &types.OfferWithPeer{PeerID: "12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5", Offer: o}
+	}
+	return book, nil
 }
 
 func (*mockNet) Initiate(_ peer.AddrInfo, _ common.Message, _ common.SwapStateNet) error {
 	return nil
 }
 
+func (*mockNet) RelayQueueStats() swapnet.RelayQueueStats {
+	return swapnet.RelayQueueStats{}
+}
+
+func (*mockNet) DecodeFailures() map[peer.ID]uint {
+	return nil
+}
+
+func (*mockNet) BlockPeer(_ peer.ID) error {
+	return nil
+}
+
+func (*mockNet) UnblockPeer(_ peer.ID) error {
+	return nil
+}
+
 func (*mockNet) CloseProtocolStream(_ types.Hash) {
 	panic("not implemented")
 }
@@ -95,6 +152,7 @@ func (*mockSwapManager) GetOngoingSwap(id types.Hash) (swap.Info, error) {
 		types.EthAssetETH,
 		types.CompletedSuccess,
 		1,
+		"",
 		statusCh,
 	), nil
 }
@@ -107,6 +165,18 @@ func (*mockSwapManager) CompleteOngoingSwap(_ *swap.Info) error {
 	panic("not implemented")
 }
 
+func (*mockSwapManager) AddStatusListener(_ swap.StatusListener) {
+	panic("not implemented")
+}
+
+func (*mockSwapManager) BeginStep(_ bool) (func(), error) {
+	panic("not implemented")
+}
+
+func (*mockSwapManager) Shutdown(_ context.Context) error {
+	panic("not implemented")
+}
+
 type mockXMRTaker struct{}
 
 func (*mockXMRTaker) Provides() coins.ProvidesCoin {
@@ -117,10 +187,14 @@ func (*mockXMRTaker) GetOngoingSwapState(_ types.Hash) common.SwapState {
 	return new(mockSwapState)
 }
 
-func (*mockXMRTaker) InitiateProtocol(_ *apd.Decimal, _ *types.Offer) (common.SwapState, error) {
+func (*mockXMRTaker) InitiateProtocol(_ peer.ID, _ *apd.Decimal, _ *types.Offer) (common.SwapState, error) {
 	return new(mockSwapState), nil
 }
 
+func (*mockXMRTaker) EstimateTakeCost(_ *types.Offer, _ *apd.Decimal) (*coins.WeiAmount, error) {
+	panic("not implemented")
+}
+
 func (*mockXMRTaker) Refund(_ types.Hash) (ethcommon.Hash, error) {
 	panic("not implemented")
 }
@@ -143,18 +217,38 @@ func (m *mockXMRMaker) GetOngoingSwapState(_ types.Hash) common.SwapState {
 	panic("not implemented")
 }
 
-func (*mockXMRMaker) MakeOffer(_ *types.Offer, _ bool) (*types.OfferExtra, error) {
+func (*mockXMRMaker) InitiateProtocol(_ peer.ID, _ *apd.Decimal, _ *types.Offer) (common.SwapState, error) {
+	return new(mockSwapState), nil
+}
+
+func (*mockXMRMaker) MakeOffer(offer *types.Offer, _ bool) (*types.Offer, *types.OfferExtra, error) {
 	offerExtra := &types.OfferExtra{
 		StatusCh: make(chan types.Status, 1),
 	}
 	offerExtra.StatusCh <- types.CompletedSuccess
-	return offerExtra, nil
+	return offer, offerExtra, nil
+}
+
+func (*mockXMRMaker) MakePrivateOffer(_ *types.Offer, _ bool, _ []peer.ID) (*types.Offer, *types.OfferExtra, error) {
+	panic("not implemented")
+}
+
+func (*mockXMRMaker) ImportOffers(_ []byte) ([]types.Hash, error) {
+	panic("not implemented")
+}
+
+func (*mockXMRMaker) SetOfferMoneroLockPriority(_ types.Hash, _ types.MoneroTxPriority) error {
+	return nil
 }
 
 func (*mockXMRMaker) GetOffers() []*types.Offer {
 	panic("not implemented")
 }
 
+func (*mockXMRMaker) GetOfferLiquidity() (*types.MakerLiquidity, error) {
+	panic("not implemented")
+}
+
 func (*mockXMRMaker) ClearOffers(_ []types.Hash) error {
 	panic("not implemented")
 }
@@ -163,6 +257,10 @@ func (*mockXMRMaker) GetMoneroBalance() (*mcrypto.Address, *wallet.GetBalanceRes
 	panic("not implemented")
 }
 
+func (*mockXMRMaker) GetRelayerClaimStats() ([]*types.RelayerClaimStats, error) {
+	return []*types.RelayerClaimStats{{PeerID: "relayer", Succeeded: 3, Failed: 1}}, nil
+}
+
 type mockSwapState struct{}
 
 func (*mockSwapState) HandleProtocolMessage(_ common.Message) error {
@@ -182,7 +280,8 @@ func (*mockSwapState) ID() types.Hash {
 }
 
 type mockProtocolBackend struct {
-	sm *mockSwapManager
+	sm     *mockSwapManager
+	health *types.HealthStatus
 }
 
 func newMockProtocolBackend() *mockProtocolBackend {
@@ -218,3 +317,30 @@ func (*mockProtocolBackend) ClearXMRDepositAddress(types.Hash) {
 func (*mockProtocolBackend) ETHClient() extethclient.EthClient {
 	panic("not implemented")
 }
+
+func (*mockProtocolBackend) GetContractSwapState(_ types.Hash) (*types.ContractSwapState, error) {
+	return &types.ContractSwapState{
+		Stage:    types.ContractStagePending,
+		Timeout0: time.Unix(1700000000, 0),
+		Timeout1: time.Unix(1700003600, 0),
+	}, nil
+}
+
+func (*mockProtocolBackend) RebroadcastTx(_ ethcommon.Hash, _ bool) (ethcommon.Hash, error) {
+	panic("not implemented")
+}
+
+func (*mockProtocolBackend) SweepStrandedSwapFunds(_ context.Context) ([]*types.StrandedSwapFunds, error) {
+	panic("not implemented")
+}
+
+func (b *mockProtocolBackend) HealthCheck(_ context.Context) (*types.HealthStatus, error) {
+	if b.health == nil {
+		return &types.HealthStatus{
+			Healthy:  true,
+			Ethereum: &types.EthereumHealth{Reachable: true},
+			Monero:   &types.MoneroHealth{Reachable: true, DaemonSynchronised: true, WalletRefreshed: true},
+		}, nil
+	}
+	return b.health, nil
+}
@@ -1,8 +1,10 @@
 package rpc
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/cockroachdb/apd/v3"
@@ -13,7 +15,9 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	swapnet "github.com/athanorlabs/atomic-swap/net"
 	"github.com/athanorlabs/atomic-swap/net/message"
+	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
 const defaultSearchTime = time.Second * 12
@@ -25,8 +29,18 @@ type Net interface {
 	Addresses() []ma.Multiaddr
 	Discover(provides string, searchTime time.Duration) ([]peer.ID, error)
 	Query(who peer.ID) (*message.QueryResponse, error)
+	QueryFiltered(who peer.ID, filter *types.OfferFilter) (*message.QueryResponse, error)
+	FetchAggregatedOffers(
+		ctx context.Context,
+		filter *types.OfferFilter,
+		relayerFee *types.RelayerFeePolicy,
+	) ([]*types.OfferWithPeer, error)
 	Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s common.SwapStateNet) error
 	CloseProtocolStream(types.Hash)
+	RelayQueueStats() swapnet.RelayQueueStats
+	BlockPeer(who peer.ID) error
+	UnblockPeer(who peer.ID) error
+	DecodeFailures() map[peer.ID]uint
 }
 
 // NetService is the RPC service prefixed by net_.
@@ -67,9 +81,63 @@ func (s *NetService) Peers(_ *http.Request, _ *interface{}, resp *rpctypes.Peers
 	return nil
 }
 
+// BlockPeer adds a peer to the blocklist. Swaps and relay requests from blocked
+// peers are rejected.
+func (s *NetService) BlockPeer(_ *http.Request, req *rpctypes.BlockPeerRequest, _ *interface{}) error {
+	return s.net.BlockPeer(req.PeerID)
+}
+
+// UnblockPeer removes a peer from the blocklist.
+func (s *NetService) UnblockPeer(_ *http.Request, req *rpctypes.UnblockPeerRequest, _ *interface{}) error {
+	return s.net.UnblockPeer(req.PeerID)
+}
+
+// RelayerStats returns the load on this node's relay claim worker pool.
+func (s *NetService) RelayerStats(_ *http.Request, _ *interface{}, resp *rpctypes.RelayerStatsResponse) error {
+	stats := s.net.RelayQueueStats()
+	resp.Active = stats.Active
+	resp.Queued = stats.Queued
+	resp.Rejected = stats.Rejected
+	return nil
+}
+
+// RelayerClaimStats returns how the relayers that we submitted claims to
+// performed, with the most reliable relayers first.
+func (s *NetService) RelayerClaimStats(
+	_ *http.Request,
+	_ *interface{},
+	resp *rpctypes.RelayerClaimStatsResponse,
+) error {
+	relayers, err := s.xmrmaker.GetRelayerClaimStats()
+	if err != nil {
+		return err
+	}
+
+	resp.Relayers = relayers
+	return nil
+}
+
+// PeerStats returns the number of messages from each peer that failed to decode,
+// with the peers that sent the most malformed messages first.
+func (s *NetService) PeerStats(_ *http.Request, _ *interface{}, resp *rpctypes.PeerStatsResponse) error {
+	failures := s.net.DecodeFailures()
+	resp.DecodeFailures = make([]*rpctypes.PeerDecodeFailures, 0, len(failures))
+	for id, count := range failures {
+		resp.DecodeFailures = append(resp.DecodeFailures, &rpctypes.PeerDecodeFailures{
+			PeerID: id,
+			Count:  count,
+		})
+	}
+
+	sort.Slice(resp.DecodeFailures, func(i, j int) bool {
+		return resp.DecodeFailures[i].Count > resp.DecodeFailures[j].Count
+	})
+	return nil
+}
+
 // QueryAll discovers peers who provide a certain coin and queries all of them for their current offers.
 func (s *NetService) QueryAll(_ *http.Request, req *rpctypes.QueryAllRequest, resp *rpctypes.QueryAllResponse) error {
-	peerIDs, err := s.discover(req)
+	peerIDs, err := s.discover(&req.DiscoverRequest)
 	if err != nil {
 		return err
 	}
@@ -79,14 +147,34 @@ func (s *NetService) QueryAll(_ *http.Request, req *rpctypes.QueryAllRequest, re
 		resp.PeersWithOffers[i] = &rpctypes.PeerWithOffers{
 			PeerID: p,
 		}
-		msg, err := s.net.Query(p)
+		msg, err := s.net.QueryFiltered(p, req.Filter)
 		if err != nil {
 			log.Debugf("Failed to query peer ID %s", p)
 			continue
 		}
 		resp.PeersWithOffers[i].Offers = msg.Offers
+		resp.PeersWithOffers[i].TakeableRanges = msg.TakeableRanges
+		resp.PeersWithOffers[i].TotalOffers = msg.TotalOffers
+	}
+
+	return nil
+}
+
+// OfferBook queries all of our connected peers for their offers, and returns
+// them merged into one orderbook, with the best effective exchange rates after
+// the relayer fee first. The fee defaults to the standard relayer fee.
+func (s *NetService) OfferBook(r *http.Request, req *rpctypes.OfferBookRequest, resp *rpctypes.OfferBookResponse) error {
+	relayerFee := req.RelayerFee
+	if relayerFee == nil {
+		relayerFee = &types.RelayerFeePolicy{Fixed: relayer.FeeEth}
 	}
 
+	offers, err := s.net.FetchAggregatedOffers(r.Context(), req.Filter, relayerFee)
+	if err != nil {
+		return err
+	}
+
+	resp.Offers = offers
 	return nil
 }
 
@@ -126,12 +214,14 @@ func (s *NetService) Discover(_ *http.Request, req *rpctypes.DiscoverRequest, re
 func (s *NetService) QueryPeer(_ *http.Request, req *rpctypes.QueryPeerRequest,
 	resp *rpctypes.QueryPeerResponse) error {
 
-	msg, err := s.net.Query(req.PeerID)
+	msg, err := s.net.QueryFiltered(req.PeerID, req.Filter)
 	if err != nil {
 		return err
 	}
 
 	resp.Offers = msg.Offers
+	resp.TakeableRanges = msg.TakeableRanges
+	resp.TotalOffers = msg.TotalOffers
 	return nil
 }
 
@@ -153,23 +243,22 @@ func (s *NetService) takeOffer(who peer.ID, offerID types.Hash, providesAmount *
 	<-chan types.Status,
 	error,
 ) {
-	queryResp, err := s.net.Query(who)
+	offer, takeable, err := s.queryOffer(who, offerID)
 	if err != nil {
 		return nil, err
 	}
 
-	var offer *types.Offer
-	for _, maybeOffer := range queryResp.Offers {
-		if offerID == maybeOffer.ID {
-			offer = maybeOffer
-			break
-		}
-	}
-	if offer == nil {
-		return nil, errNoOfferWithID
+	if err = checkTakeable(offer, takeable, providesAmount); err != nil {
+		return nil, err
 	}
 
-	swapState, err := s.xmrtaker.InitiateProtocol(providesAmount, offer)
+	// on offers that provide ETH, we provide XMR
+	var swapState common.SwapState
+	if offer.Provides == coins.ProvidesETH {
+		swapState, err = s.xmrmaker.InitiateProtocol(who, providesAmount, offer)
+	} else {
+		swapState, err = s.xmrtaker.InitiateProtocol(who, providesAmount, offer)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate protocol: %w", err)
 	}
@@ -193,6 +282,98 @@ func (s *NetService) takeOffer(who peer.ID, offerID types.Hash, providesAmount *
 	return info.StatusCh(), nil
 }
 
+// queryOffer returns the offer with the passed ID advertised by the given peer,
+// and the range of the offer that the peer currently accepts. If the peer
+// doesn't report takeable ranges, the offer's static range is returned.
+func (s *NetService) queryOffer(who peer.ID, offerID types.Hash) (*types.Offer, *types.TakeableRange, error) {
+	queryResp, err := s.net.Query(who)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var offer *types.Offer
+	for _, o := range queryResp.Offers {
+		if offerID == o.ID {
+			offer = o
+			break
+		}
+	}
+
+	if offer == nil {
+		return nil, nil, errNoOfferWithID
+	}
+
+	for _, r := range queryResp.TakeableRanges {
+		if r.OfferID == offerID {
+			return offer, r, nil
+		}
+	}
+
+	return offer, &types.TakeableRange{
+		OfferID:   offerID,
+		MinAmount: offer.MinAmount,
+		MaxAmount: offer.MaxAmount,
+	}, nil
+}
+
+// checkTakeable returns an error if the maker would reject taking the offer
+// with providesAmount, given its currently takeable range. providesAmount is of
+// XMR if the offer provides ETH, and of the ETH asset otherwise.
+func checkTakeable(offer *types.Offer, takeable *types.TakeableRange, providesAmount *apd.Decimal) error {
+	if takeable.Reason != "" {
+		return fmt.Errorf("%w: %s", errOfferNotTakeable, takeable.Reason)
+	}
+
+	xmrAmount := providesAmount
+	if offer.Provides != coins.ProvidesETH {
+		var err error
+		xmrAmount, err = offer.ExchangeRate.ToXMR(providesAmount)
+		if err != nil {
+			return err
+		}
+	}
+
+	if xmrAmount.Cmp(takeable.MinAmount) < 0 || xmrAmount.Cmp(takeable.MaxAmount) > 0 {
+		return fmt.Errorf("%w: %s XMR is not within %s-%s XMR", errAmountNotTakeable,
+			xmrAmount.Text('f'), takeable.MinAmount.Text('f'), takeable.MaxAmount.Text('f'))
+	}
+
+	return nil
+}
+
+// TakeableRange returns the range of XMR amounts of the given peer's offer that
+// the peer would currently accept, accounting for its live balance and swap
+// capacity.
+func (s *NetService) TakeableRange(
+	_ *http.Request,
+	req *rpctypes.TakeableRangeRequest,
+	resp *rpctypes.TakeableRangeResponse,
+) error {
+	_, takeable, err := s.queryOffer(req.PeerID, req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	*resp = *takeable
+	return nil
+}
+
+// EstimateTakeCost returns the total ETH that taking the given peer's offer
+// could cost, without initiating a swap.
+func (s *NetService) EstimateTakeCost(
+	_ *http.Request,
+	req *rpctypes.EstimateTakeCostRequest,
+	resp *rpctypes.EstimateTakeCostResponse,
+) error {
+	offer, _, err := s.queryOffer(req.PeerID, req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	resp.WeiCost, err = s.xmrtaker.EstimateTakeCost(offer, req.ProvidesAmount)
+	return err
+}
+
 // TakeOfferSyncResponse ...
 type TakeOfferSyncResponse struct {
 	Status types.Status `json:"status" validate:"required"`
@@ -245,20 +426,63 @@ func (s *NetService) MakeOffer(
 	return nil
 }
 
+// ImportOffers adds all the offers of an offer book at once. If any of the
+// offers are invalid, none are added.
+func (s *NetService) ImportOffers(
+	_ *http.Request,
+	req *rpctypes.ImportOffersRequest,
+	resp *rpctypes.ImportOffersResponse,
+) error {
+	ids, err := s.xmrmaker.ImportOffers(req.Offers)
+	if err != nil {
+		return err
+	}
+
+	resp.PeerID = s.net.PeerID()
+	resp.OfferIDs = ids
+	return nil
+}
+
 func (s *NetService) makeOffer(req *rpctypes.MakeOfferRequest) (*rpctypes.MakeOfferResponse, *types.OfferExtra, error) {
+	provides := req.Provides
+	if provides == "" {
+		provides = coins.ProvidesXMR
+	}
+
 	offer := types.NewOffer(
-		coins.ProvidesXMR,
+		provides,
 		req.MinAmount,
 		req.MaxAmount,
 		req.ExchangeRate,
 		req.EthAsset,
 	)
+	if req.SwapFactory != nil {
+		offer.SetSwapFactory(*req.SwapFactory)
+	}
+	if req.SwapTimeout != 0 {
+		offer.SetSwapTimeout(time.Duration(req.SwapTimeout) * time.Second)
+	}
 
-	offerExtra, err := s.xmrmaker.MakeOffer(offer, req.UseRelayer)
+	var offerExtra *types.OfferExtra
+	var err error
+	if req.Private {
+		offer, offerExtra, err = s.xmrmaker.MakePrivateOffer(offer, req.UseRelayer, req.AllowedPeers)
+	} else if len(req.AllowedPeers) > 0 {
+		err = errPeersNotPrivate
+	} else {
+		offer, offerExtra, err = s.xmrmaker.MakeOffer(offer, req.UseRelayer)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if req.MoneroLockPriority != types.MoneroPriorityDefault {
+		err = s.xmrmaker.SetOfferMoneroLockPriority(offer.ID, req.MoneroLockPriority)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	return &rpctypes.MakeOfferResponse{
 		PeerID:  s.net.PeerID(),
 		OfferID: offer.ID,
@@ -1,6 +1,8 @@
 package rpc
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/cockroachdb/apd/v3"
@@ -51,6 +53,36 @@ func TestNet_TakeOffer(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestNet_TakeOffer_notTakeable(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
+
+	// within the offer's static range, but above the maker's takeable max
+	req := &rpctypes.TakeOfferRequest{
+		PeerID:         "12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5",
+		OfferID:        testSwapID,
+		ProvidesAmount: apd.New(18, -1),
+	}
+
+	err := ns.TakeOffer(nil, req, nil)
+	require.ErrorIs(t, err, errAmountNotTakeable)
+}
+
+func TestNet_TakeableRange(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
+
+	req := &rpctypes.TakeableRangeRequest{
+		PeerID:  "12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5",
+		OfferID: testSwapID,
+	}
+
+	resp := new(rpctypes.TakeableRangeResponse)
+	err := ns.TakeableRange(nil, req, resp)
+	require.NoError(t, err)
+	require.Equal(t, "0.5", resp.MinAmount.String())
+	require.Equal(t, "1.5", resp.MaxAmount.String())
+	require.Empty(t, resp.Reason)
+}
+
 func TestNet_TakeOfferSync(t *testing.T) {
 	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
 
@@ -65,3 +97,23 @@ func TestNet_TakeOfferSync(t *testing.T) {
 	err := ns.TakeOfferSync(nil, req, resp)
 	require.NoError(t, err)
 }
+
+func TestNet_RelayerClaimStats(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), new(mockXMRMaker), new(mockSwapManager))
+
+	resp := new(rpctypes.RelayerClaimStatsResponse)
+	err := ns.RelayerClaimStats(nil, nil, resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Relayers, 1)
+	require.Equal(t, 0.75, resp.Relayers[0].SuccessRate())
+}
+
+func TestNet_OfferBook(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
+
+	resp := new(rpctypes.OfferBookResponse)
+	err := ns.OfferBook(httptest.NewRequest(http.MethodPost, "/", nil), new(rpctypes.OfferBookRequest), resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Offers, 1)
+	require.Equal(t, testSwapID, resp.Offers[0].Offer.ID)
+}
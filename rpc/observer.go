@@ -0,0 +1,38 @@
+package rpc
+
+import (
+	"net/http"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// Observer contains the functions of an observer node required by the rpc
+// service.
+type Observer interface {
+	Offers() []*types.ObservedOffer
+	Swaps() []*types.ObservedSwap
+}
+
+// ObserverService is the RPC service prefixed by observer_, which is the only
+// service of observer nodes.
+type ObserverService struct {
+	observer Observer
+}
+
+// NewObserverService ...
+func NewObserverService(observer Observer) *ObserverService {
+	return &ObserverService{observer: observer}
+}
+
+// Offers returns the offers that makers were seen advertising.
+func (s *ObserverService) Offers(_ *http.Request, _ *interface{}, resp *rpctypes.ObservedOffersResponse) error {
+	resp.Offers = s.observer.Offers()
+	return nil
+}
+
+// Swaps returns the swaps that were seen in the swap factory contract.
+func (s *ObserverService) Swaps(_ *http.Request, _ *interface{}, resp *rpctypes.ObservedSwapsResponse) error {
+	resp.Swaps = s.observer.Swaps()
+	return nil
+}
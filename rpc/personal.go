@@ -3,10 +3,12 @@ package rpc
 import (
 	"context"
 	"net/http"
+	"path/filepath"
 	"time"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
 )
 
 // PersonalService handles private keys and wallets.
@@ -14,14 +16,16 @@ type PersonalService struct {
 	ctx      context.Context
 	xmrmaker XMRMaker
 	pb       ProtocolBackend
+	db       Database
 }
 
 // NewPersonalService ...
-func NewPersonalService(ctx context.Context, xmrmaker XMRMaker, pb ProtocolBackend) *PersonalService {
+func NewPersonalService(ctx context.Context, xmrmaker XMRMaker, pb ProtocolBackend, db Database) *PersonalService {
 	return &PersonalService{
 		ctx:      ctx,
 		xmrmaker: xmrmaker,
 		pb:       pb,
+		db:       db,
 	}
 }
 
@@ -82,3 +86,56 @@ func (s *PersonalService) Balances(_ *http.Request, _ *interface{}, resp *rpctyp
 	}
 	return nil
 }
+
+// HealthResponse ...
+type HealthResponse = types.HealthStatus
+
+// Health returns whether our ethereum endpoint and monero wallet are reachable
+// and synced, with how far behind each is.
+func (s *PersonalService) Health(_ *http.Request, _ *interface{}, resp *HealthResponse) error {
+	status, err := s.pb.HealthCheck(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	*resp = *status
+	return nil
+}
+
+// SweepStrandedFundsResponse ...
+type SweepStrandedFundsResponse struct {
+	Found []*types.StrandedSwapFunds `json:"found" validate:"dive,required"`
+}
+
+// SweepStrandedFunds sweeps XMR left in the shared addresses of past swaps to
+// our primary Monero address, and reports what was found and swept. Swaps that
+// are still in flight are skipped.
+func (s *PersonalService) SweepStrandedFunds(
+	_ *http.Request,
+	_ *interface{},
+	resp *SweepStrandedFundsResponse,
+) error {
+	found, err := s.pb.SweepStrandedSwapFunds(s.ctx)
+	if err != nil {
+		return err
+	}
+
+	resp.Found = found
+	return nil
+}
+
+// BackupDatabaseRequest ...
+type BackupDatabaseRequest struct {
+	Path string `json:"path" validate:"required"`
+}
+
+// BackupDatabase writes a consistent snapshot of swapd's database to the passed
+// path, which must be absolute, on the host running swapd. The snapshot can be
+// loaded with swapd's --restore-db flag.
+func (s *PersonalService) BackupDatabase(_ *http.Request, req *BackupDatabaseRequest, _ *interface{}) error {
+	if !filepath.IsAbs(req.Path) {
+		return errBackupPathNotAbsolute
+	}
+
+	return s.db.Backup(req.Path)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/rpc/v2"
 	logging "github.com/ipfs/go-log"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
@@ -45,6 +46,7 @@ type Config struct {
 	XMRTaker        XMRTaker
 	XMRMaker        XMRMaker
 	ProtocolBackend ProtocolBackend
+	Database        Database
 }
 
 // NewServer ...
@@ -57,7 +59,7 @@ func NewServer(cfg *Config) (*Server, error) {
 		return nil, err
 	}
 
-	err := rpcServer.RegisterService(NewPersonalService(cfg.Ctx, cfg.XMRMaker, cfg.ProtocolBackend), "personal")
+	err := rpcServer.RegisterService(NewPersonalService(cfg.Ctx, cfg.XMRMaker, cfg.ProtocolBackend, cfg.Database), "personal")
 	if err != nil {
 		return nil, err
 	}
@@ -76,15 +78,45 @@ func NewServer(cfg *Config) (*Server, error) {
 
 	wsServer := newWsServer(cfg.Ctx, cfg.ProtocolBackend.SwapManager(), ns, cfg.ProtocolBackend, cfg.XMRTaker)
 
-	lc := net.ListenConfig{}
-	ln, err := lc.Listen(cfg.Ctx, "tcp", cfg.Address)
-	if err != nil {
+	r := mux.NewRouter()
+	r.Handle("/", rpcServer)
+	r.Handle("/ws", wsServer)
+	r.Handle("/health", newHealthHandler(cfg.ProtocolBackend)).Methods("GET", "HEAD")
+
+	return newRouterServer(cfg.Ctx, cfg.Address, r)
+}
+
+// ObserverConfig is the configuration of the RPC server of observer nodes.
+type ObserverConfig struct {
+	Ctx      context.Context
+	Address  string // "IP:port"
+	Observer Observer
+}
+
+// NewObserverServer returns the RPC server of an observer node, which only
+// serves the observer_ methods, as observers have no wallets or swaps.
+func NewObserverServer(cfg *ObserverConfig) (*Server, error) {
+	rpcServer := rpc.NewServer()
+	rpcServer.RegisterCodec(NewCodec(), "application/json")
+
+	if err := rpcServer.RegisterService(NewObserverService(cfg.Observer), "observer"); err != nil {
 		return nil, err
 	}
 
 	r := mux.NewRouter()
 	r.Handle("/", rpcServer)
-	r.Handle("/ws", wsServer)
+
+	return newRouterServer(cfg.Ctx, cfg.Address, r)
+}
+
+// newRouterServer returns a Server listening on the address, that serves the
+// router's routes.
+func newRouterServer(ctx context.Context, address string, r *mux.Router) (*Server, error) {
+	lc := net.ListenConfig{}
+	ln, err := lc.Listen(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
 
 	headersOk := handlers.AllowedHeaders([]string{"content-type", "username", "password"})
 	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
@@ -94,12 +126,12 @@ func NewServer(cfg *Config) (*Server, error) {
 		ReadHeaderTimeout: time.Second,
 		Handler:           handlers.CORS(headersOk, methodsOk, originsOk)(r),
 		BaseContext: func(listener net.Listener) context.Context {
-			return cfg.Ctx
+			return ctx
 		},
 	}
 
 	return &Server{
-		ctx:        cfg.Ctx,
+		ctx:        ctx,
 		listener:   ln,
 		httpServer: server,
 	}, nil
@@ -168,12 +200,22 @@ type ProtocolBackend interface {
 	SetXMRDepositAddress(*mcrypto.Address, types.Hash)
 	ClearXMRDepositAddress(types.Hash)
 	ETHClient() extethclient.EthClient
+	HealthCheck(ctx context.Context) (*types.HealthStatus, error)
+	GetContractSwapState(swapID types.Hash) (*types.ContractSwapState, error)
+	RebroadcastTx(txHash ethcommon.Hash, bumpGas bool) (ethcommon.Hash, error)
+	SweepStrandedSwapFunds(ctx context.Context) ([]*types.StrandedSwapFunds, error)
+}
+
+// Database represents the parts of db.Database used by the rpc service.
+type Database interface {
+	Backup(destPath string) error
 }
 
 // XMRTaker ...
 type XMRTaker interface {
 	Protocol
-	InitiateProtocol(providesAmount *apd.Decimal, offer *types.Offer) (common.SwapState, error)
+	InitiateProtocol(makerPeerID peer.ID, providesAmount *apd.Decimal, offer *types.Offer) (common.SwapState, error)
+	EstimateTakeCost(offer *types.Offer, providesAmount *apd.Decimal) (*coins.WeiAmount, error)
 	Refund(types.Hash) (ethcommon.Hash, error)
 	ExternalSender(offerID types.Hash) (*txsender.ExternalSender, error)
 }
@@ -181,10 +223,20 @@ type XMRTaker interface {
 // XMRMaker ...
 type XMRMaker interface {
 	Protocol
-	MakeOffer(offer *types.Offer, useRelayer bool) (*types.OfferExtra, error)
+	InitiateProtocol(makerPeerID peer.ID, providesAmount *apd.Decimal, offer *types.Offer) (common.SwapState, error)
+	MakeOffer(offer *types.Offer, useRelayer bool) (*types.Offer, *types.OfferExtra, error)
+	MakePrivateOffer(
+		offer *types.Offer,
+		useRelayer bool,
+		allowedPeers []peer.ID,
+	) (*types.Offer, *types.OfferExtra, error)
+	ImportOffers(data []byte) ([]types.Hash, error)
+	SetOfferMoneroLockPriority(id types.Hash, priority types.MoneroTxPriority) error
 	GetOffers() []*types.Offer
+	GetOfferLiquidity() (*types.MakerLiquidity, error)
 	ClearOffers([]types.Hash) error
 	GetMoneroBalance() (*mcrypto.Address, *wallet.GetBalanceResponse, error)
+	GetRelayerClaimStats() ([]*types.RelayerClaimStats, error)
 }
 
 // SwapManager ...
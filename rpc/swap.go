@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
@@ -48,14 +49,17 @@ func NewSwapService(
 
 // PastSwap represents a past swap returned by swap_getPast.
 type PastSwap struct {
-	ID             types.Hash          `json:"id" validate:"required"`
-	Provided       coins.ProvidesCoin  `json:"provided" validate:"required"`
-	ProvidedAmount *apd.Decimal        `json:"providedAmount" validate:"required"`
-	ExpectedAmount *apd.Decimal        `json:"expectedAmount" validate:"required"`
-	ExchangeRate   *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
-	Status         types.Status        `json:"status" validate:"required"`
-	StartTime      time.Time           `json:"startTime" validate:"required"`
-	EndTime        *time.Time          `json:"endTime"`
+	ID                 types.Hash          `json:"id" validate:"required"`
+	Provided           coins.ProvidesCoin  `json:"provided" validate:"required"`
+	ProvidedAmount     *apd.Decimal        `json:"providedAmount" validate:"required"`
+	ExpectedAmount     *apd.Decimal        `json:"expectedAmount" validate:"required"`
+	ExchangeRate       *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
+	Status             types.Status        `json:"status" validate:"required"`
+	CounterpartyPeerID peer.ID             `json:"counterpartyPeerID,omitempty"`
+	StartTime          time.Time           `json:"startTime" validate:"required"`
+	EndTime            *time.Time          `json:"endTime"`
+	FailureReason      swap.FailureReason  `json:"failureReason,omitempty"`
+	FailureError       string              `json:"failureError,omitempty"`
 }
 
 // GetPastRequest ...
@@ -100,14 +104,17 @@ func (s *SwapService) GetPast(_ *http.Request, req *GetPastRequest, resp *GetPas
 	resp.Swaps = make([]*PastSwap, len(swaps))
 	for i, info := range swaps {
 		resp.Swaps[i] = &PastSwap{
-			ID:             info.ID,
-			Provided:       info.Provides,
-			ProvidedAmount: info.ProvidedAmount,
-			ExpectedAmount: info.ExpectedAmount,
-			ExchangeRate:   info.ExchangeRate,
-			Status:         info.Status,
-			StartTime:      info.StartTime,
-			EndTime:        info.EndTime,
+			ID:                 info.ID,
+			Provided:           info.Provides,
+			ProvidedAmount:     info.ProvidedAmount,
+			ExpectedAmount:     info.ExpectedAmount,
+			ExchangeRate:       info.ExchangeRate,
+			Status:             info.Status,
+			CounterpartyPeerID: info.CounterpartyPeerID,
+			StartTime:          info.StartTime,
+			EndTime:            info.EndTime,
+			FailureReason:      info.FailureReason,
+			FailureError:       info.FailureError,
 		}
 	}
 
@@ -126,10 +133,12 @@ type OngoingSwap struct {
 	ExpectedAmount            *apd.Decimal        `json:"expectedAmount" validate:"required"`
 	ExchangeRate              *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
 	Status                    types.Status        `json:"status" validate:"required"`
+	CounterpartyPeerID        peer.ID             `json:"counterpartyPeerID,omitempty"`
 	LastStatusUpdateTime      time.Time           `json:"lastStatusUpdateTime" validate:"required"`
 	StartTime                 time.Time           `json:"startTime" validate:"required"`
 	Timeout0                  *time.Time          `json:"timeout0"`
 	Timeout1                  *time.Time          `json:"timeout1"`
+	ApprovalTxHash            *ethcommon.Hash     `json:"approvalTxHash,omitempty"`
 	EstimatedTimeToCompletion time.Duration       `json:"estimatedTimeToCompletion" validate:"required"`
 }
 
@@ -175,10 +184,12 @@ func (s *SwapService) GetOngoing(_ *http.Request, req *GetOngoingRequest, resp *
 		swap.ExpectedAmount = info.ExpectedAmount
 		swap.ExchangeRate = info.ExchangeRate
 		swap.Status = info.Status
+		swap.CounterpartyPeerID = info.CounterpartyPeerID
 		swap.LastStatusUpdateTime = info.LastStatusUpdateTime
 		swap.StartTime = info.StartTime
 		swap.Timeout0 = info.Timeout0
 		swap.Timeout1 = info.Timeout1
+		swap.ApprovalTxHash = info.ApprovalTxHash
 		swap.EstimatedTimeToCompletion, err = estimatedTimeToCompletion(env, info.Status, info.LastStatusUpdateTime)
 		if err != nil {
 			return fmt.Errorf("failed to estimate time to completion for swap %s: %w", info.ID, err)
@@ -225,6 +236,30 @@ func (s *SwapService) Refund(_ *http.Request, req *RefundRequest, resp *RefundRe
 	return nil
 }
 
+// RebroadcastTxRequest ...
+type RebroadcastTxRequest struct {
+	TxHash  ethcommon.Hash `json:"txHash" validate:"required"`
+	BumpGas bool           `json:"bumpGas"`
+}
+
+// RebroadcastTxResponse ...
+type RebroadcastTxResponse struct {
+	TxHash ethcommon.Hash `json:"txHash" validate:"required"`
+}
+
+// RebroadcastTx sends a pending transaction, such as a stuck claim or refund,
+// to our ethereum node again. If BumpGas is set, a replacement with the same
+// nonce and a higher gas price is sent instead, and its hash is returned.
+func (s *SwapService) RebroadcastTx(_ *http.Request, req *RebroadcastTxRequest, resp *RebroadcastTxResponse) error {
+	txHash, err := s.backend.RebroadcastTx(req.TxHash, req.BumpGas)
+	if err != nil {
+		return fmt.Errorf("failed to rebroadcast transaction: %w", err)
+	}
+
+	resp.TxHash = txHash
+	return nil
+}
+
 // GetStatusRequest ...
 type GetStatusRequest struct {
 	ID types.Hash `json:"id" validate:"required"`
@@ -250,6 +285,30 @@ func (s *SwapService) GetStatus(_ *http.Request, req *GetStatusRequest, resp *Ge
 	return nil
 }
 
+// GetContractStageRequest ...
+type GetContractStageRequest struct {
+	OfferID types.Hash `json:"offerID" validate:"required"`
+}
+
+// GetContractStageResponse ...
+type GetContractStageResponse = types.ContractSwapState
+
+// GetContractStage returns the progress of a swap as read from the swap
+// contract, which is authoritative when it differs from the swap's status.
+func (s *SwapService) GetContractStage(
+	_ *http.Request,
+	req *GetContractStageRequest,
+	resp *GetContractStageResponse,
+) error {
+	state, err := s.backend.GetContractSwapState(req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	*resp = *state
+	return nil
+}
+
 // GetOffersResponse ...
 type GetOffersResponse struct {
 	PeerID peer.ID        `json:"peerID" validate:"required"`
@@ -263,6 +322,26 @@ func (s *SwapService) GetOffers(_ *http.Request, _ *interface{}, resp *GetOffers
 	return nil
 }
 
+// GetOfferLiquidityResponse ...
+type GetOfferLiquidityResponse struct {
+	PeerID peer.ID `json:"peerID" validate:"required"`
+	*types.MakerLiquidity
+}
+
+// GetOfferLiquidity returns our offers, including those taken by in-flight
+// swaps, with the XMR reserved by each swap, along with how much of our
+// unlocked XMR balance is reserved and how much is available to new takes.
+func (s *SwapService) GetOfferLiquidity(_ *http.Request, _ *interface{}, resp *GetOfferLiquidityResponse) error {
+	liquidity, err := s.xmrmaker.GetOfferLiquidity()
+	if err != nil {
+		return err
+	}
+
+	resp.PeerID = s.net.PeerID()
+	resp.MakerLiquidity = liquidity
+	return nil
+}
+
 // ClearOffersRequest ...
 type ClearOffersRequest struct {
 	OfferIDs []types.Hash `json:"offerIDs" validate:"dive,required"`
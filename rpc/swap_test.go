@@ -0,0 +1,24 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestSwap_GetContractStage(t *testing.T) {
+	pb := newMockProtocolBackend()
+	ss := NewSwapService(context.Background(), pb.sm, new(mockXMRTaker), new(mockXMRMaker), new(mockNet), pb)
+
+	req := &GetContractStageRequest{OfferID: testSwapID}
+	resp := new(GetContractStageResponse)
+	require.NoError(t, ss.GetContractStage(nil, req, resp))
+	require.Equal(t, types.ContractStagePending, resp.Stage)
+	require.Equal(t, int64(1700000000), resp.Timeout0.Unix())
+	require.Equal(t, int64(1700003600), resp.Timeout1.Unix())
+	require.False(t, resp.Claimed)
+	require.False(t, resp.Refunded)
+}
@@ -0,0 +1,34 @@
+package rpcclient
+
+import (
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// BlockPeer calls net_blockPeer to add a peer to the swapd instance's blocklist.
+func (c *Client) BlockPeer(who peer.ID) error {
+	const (
+		method = "net_blockPeer"
+	)
+
+	req := &rpctypes.BlockPeerRequest{
+		PeerID: who,
+	}
+
+	return c.Post(method, req, nil)
+}
+
+// UnblockPeer calls net_unblockPeer to remove a peer from the swapd instance's
+// blocklist.
+func (c *Client) UnblockPeer(who peer.ID) error {
+	const (
+		method = "net_unblockPeer"
+	)
+
+	req := &rpctypes.UnblockPeerRequest{
+		PeerID: who,
+	}
+
+	return c.Post(method, req, nil)
+}
@@ -33,8 +33,10 @@ func (c *Client) QueryAll(provides coins.ProvidesCoin, searchTime uint64) ([]*rp
 	)
 
 	req := &rpctypes.QueryAllRequest{
-		Provides:   string(provides),
-		SearchTime: searchTime,
+		DiscoverRequest: rpctypes.DiscoverRequest{
+			Provides:   string(provides),
+			SearchTime: searchTime,
+		},
 	}
 	res := &rpctypes.QueryAllResponse{}
 
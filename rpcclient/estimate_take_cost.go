@@ -0,0 +1,35 @@
+package rpcclient
+
+import (
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// EstimateTakeCost calls net_estimateTakeCost to get the total ETH that taking
+// the given peer's offer could cost.
+func (c *Client) EstimateTakeCost(
+	peerID peer.ID,
+	offerID types.Hash,
+	providesAmount *apd.Decimal,
+) (*coins.WeiAmount, error) {
+	const (
+		method = "net_estimateTakeCost"
+	)
+
+	req := &rpctypes.EstimateTakeCostRequest{
+		PeerID:         peerID,
+		OfferID:        offerID,
+		ProvidesAmount: providesAmount,
+	}
+	res := &rpctypes.EstimateTakeCostResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res.WeiCost, nil
+}
@@ -0,0 +1,20 @@
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+// GetOfferLiquidity calls swap_getOfferLiquidity.
+func (c *Client) GetOfferLiquidity() (*rpc.GetOfferLiquidityResponse, error) {
+	const (
+		method = "swap_getOfferLiquidity"
+	)
+
+	resp := &rpc.GetOfferLiquidityResponse{}
+
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
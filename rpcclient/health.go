@@ -0,0 +1,20 @@
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+// Health calls personal_health to get whether swapd's ethereum endpoint and
+// monero wallet are reachable and synced.
+func (c *Client) Health() (*rpc.HealthResponse, error) {
+	const (
+		method = "personal_health"
+	)
+
+	res := &rpc.HealthResponse{}
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,23 @@
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// ImportOffers calls net_importOffers.
+func (c *Client) ImportOffers(offers []byte) (*rpctypes.ImportOffersResponse, error) {
+	const (
+		method = "net_importOffers"
+	)
+
+	req := &rpctypes.ImportOffersRequest{
+		Offers: offers,
+	}
+	res := &rpctypes.ImportOffersResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,31 @@
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// OfferBook calls net_offerBook to get the offers of a swapd instance's
+// connected peers, merged into one orderbook with the best effective exchange
+// rates after the relayer fee first. The filter is optional, as is the relayer
+// fee, which defaults to the standard relayer fee.
+func (c *Client) OfferBook(
+	filter *types.OfferFilter,
+	relayerFee *types.RelayerFeePolicy,
+) (*rpctypes.OfferBookResponse, error) {
+	const (
+		method = "net_offerBook"
+	)
+
+	req := &rpctypes.OfferBookRequest{
+		Filter:     filter,
+		RelayerFee: relayerFee,
+	}
+	res := &rpctypes.OfferBookResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,21 @@
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// PeerStats calls net_peerStats to get the number of messages from each peer
+// that failed to decode on a swapd instance.
+func (c *Client) PeerStats() (*rpctypes.PeerStatsResponse, error) {
+	const (
+		method = "net_peerStats"
+	)
+
+	res := &rpctypes.PeerStatsResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -49,3 +49,30 @@ func (c *Client) Balances() (*rpctypes.BalancesResponse, error) {
 
 	return balances, nil
 }
+
+// SweepStrandedFunds calls personal_sweepStrandedFunds.
+func (c *Client) SweepStrandedFunds() (*rpc.SweepStrandedFundsResponse, error) {
+	const (
+		method = "personal_sweepStrandedFunds"
+	)
+
+	resp := &rpc.SweepStrandedFundsResponse{}
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// BackupDatabase calls personal_backupDatabase.
+func (c *Client) BackupDatabase(path string) error {
+	const (
+		method = "personal_backupDatabase"
+	)
+
+	req := &rpc.BackupDatabaseRequest{
+		Path: path,
+	}
+
+	return c.Post(method, req, nil)
+}
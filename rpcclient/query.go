@@ -4,16 +4,24 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
 )
 
 // Query calls net_query.
 func (c *Client) Query(who peer.ID) (*rpctypes.QueryPeerResponse, error) {
+	return c.QueryFiltered(who, nil)
+}
+
+// QueryFiltered calls net_query with a filter selecting a page of the peer's
+// offers.
+func (c *Client) QueryFiltered(who peer.ID, filter *types.OfferFilter) (*rpctypes.QueryPeerResponse, error) {
 	const (
 		method = "net_queryPeer"
 	)
 
 	req := &rpctypes.QueryPeerRequest{
 		PeerID: who,
+		Filter: filter,
 	}
 	res := &rpctypes.QueryPeerResponse{}
 
@@ -0,0 +1,21 @@
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// RelayerClaimStats calls net_relayerClaimStats to get how the relayers that a
+// swapd instance submitted claims to performed.
+func (c *Client) RelayerClaimStats() (*rpctypes.RelayerClaimStatsResponse, error) {
+	const (
+		method = "net_relayerClaimStats"
+	)
+
+	res := &rpctypes.RelayerClaimStatsResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
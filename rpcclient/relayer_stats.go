@@ -0,0 +1,21 @@
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+)
+
+// RelayerStats calls net_relayerStats to get the load on a swapd instance's
+// relay claim worker pool.
+func (c *Client) RelayerStats() (*rpctypes.RelayerStatsResponse, error) {
+	const (
+		method = "net_relayerStats"
+	)
+
+	res := &rpctypes.RelayerStatsResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -3,6 +3,8 @@ package rpcclient
 import (
 	"fmt"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/rpc"
 )
@@ -44,6 +46,24 @@ func (c *Client) GetPastSwap(id *types.Hash) (*rpc.GetPastResponse, error) {
 	return res, nil
 }
 
+// GetContractStage calls swap_getContractStage
+func (c *Client) GetContractStage(id types.Hash) (*rpc.GetContractStageResponse, error) {
+	const (
+		method = "swap_getContractStage"
+	)
+
+	req := &rpc.GetContractStageRequest{
+		OfferID: id,
+	}
+	res := &rpc.GetContractStageResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 // Refund calls swap_refund
 func (c *Client) Refund(id types.Hash) (*rpc.RefundResponse, error) {
 	const (
@@ -62,6 +82,25 @@ func (c *Client) Refund(id types.Hash) (*rpc.RefundResponse, error) {
 	return res, nil
 }
 
+// RebroadcastTx calls swap_rebroadcastTx
+func (c *Client) RebroadcastTx(txHash ethcommon.Hash, bumpGas bool) (*rpc.RebroadcastTxResponse, error) {
+	const (
+		method = "swap_rebroadcastTx"
+	)
+
+	req := &rpc.RebroadcastTxRequest{
+		TxHash:  txHash,
+		BumpGas: bumpGas,
+	}
+	res := &rpc.RebroadcastTxResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 // GetStatus calls swap_getStatus
 func (c *Client) GetStatus(id types.Hash) (*rpc.GetStatusResponse, error) {
 	const (
@@ -0,0 +1,28 @@
+package rpcclient
+
+import (
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// TakeableRange calls net_takeableRange to get the range of XMR amounts of a
+// peer's offer that the peer would currently accept.
+func (c *Client) TakeableRange(peerID peer.ID, offerID types.Hash) (*rpctypes.TakeableRangeResponse, error) {
+	const (
+		method = "net_takeableRange"
+	)
+
+	req := &rpctypes.TakeableRangeRequest{
+		PeerID:  peerID,
+		OfferID: offerID,
+	}
+	res := &rpctypes.TakeableRangeResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
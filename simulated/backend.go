@@ -0,0 +1,307 @@
+// Package simulated provides a simulated ethereum chain with the SwapFactory
+// contract deployed and a simulated monero chain, along with mock wallet and
+// ethereum clients backed by them. Blocks are only produced when the caller
+// advances the chains, so swaps can be driven through their happy and refund
+// paths deterministically, without ethereum or monero nodes.
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/crypto/secp256k1"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+// gasLimit is the block gas limit of the simulated ethereum chain.
+const gasLimit = 30_000_000
+
+var (
+	errNoAccounts = errors.New("at least one account is needed to deploy the swap contract")
+	errTxReverted = errors.New("transaction reverted")
+	errNoSwapLog  = errors.New("newSwap transaction has no New log")
+)
+
+// InitialBalance is the balance, in wei, that each account passed to
+// NewBackend starts with.
+var InitialBalance = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+
+// Backend is a simulated ethereum chain, on which the SwapFactory contract is
+// deployed, and a simulated monero chain. The ethereum chain only mines a block
+// when a transaction is sent through one of its swap methods, or when it's
+// advanced. The monero chain only has a height, which is advanced explicitly.
+type Backend struct {
+	eth             *backends.SimulatedBackend
+	chainID         *big.Int
+	swapFactoryAddr ethcommon.Address
+	swapFactory     *contracts.SwapFactory
+
+	mu           sync.Mutex
+	nonce        int64 // nonce of the next swap created by NewSwap
+	moneroHeight uint64
+	moneroFunds  []*moneroFunds
+}
+
+// NewBackend returns a simulated backend on which each account of the passed
+// keys holds InitialBalance. The SwapFactory contract is deployed by the first
+// account, without a trusted forwarder.
+func NewBackend(keys ...*ecdsa.PrivateKey) (*Backend, error) {
+	if len(keys) == 0 {
+		return nil, errNoAccounts
+	}
+
+	alloc := make(core.GenesisAlloc, len(keys))
+	for _, key := range keys {
+		alloc[common.EthereumPrivateKeyToAddress(key)] = core.GenesisAccount{Balance: InitialBalance}
+	}
+
+	eth := backends.NewSimulatedBackend(alloc, gasLimit)
+	b := &Backend{
+		eth:          eth,
+		chainID:      eth.Blockchain().Config().ChainID,
+		moneroHeight: 1,
+	}
+
+	txOpts, err := b.TxOpts(keys[0])
+	if err != nil {
+		_ = eth.Close()
+		return nil, err
+	}
+
+	addr, tx, swapFactory, err := contracts.DeploySwapFactory(txOpts, eth, ethcommon.Address{})
+	if err != nil {
+		_ = eth.Close()
+		return nil, fmt.Errorf("failed to deploy swap contract: %w", err)
+	}
+
+	if _, err = b.mine(tx); err != nil {
+		_ = eth.Close()
+		return nil, fmt.Errorf("failed to deploy swap contract: %w", err)
+	}
+
+	b.swapFactoryAddr = addr
+	b.swapFactory = swapFactory
+	return b, nil
+}
+
+// Close stops the simulated ethereum chain.
+func (b *Backend) Close() error {
+	return b.eth.Close()
+}
+
+// ETH returns the simulated ethereum chain. It can be used to bind other
+// contracts, or to read logs and receipts.
+func (b *Backend) ETH() *backends.SimulatedBackend {
+	return b.eth
+}
+
+// ChainID returns the chain ID of the simulated ethereum chain.
+func (b *Backend) ChainID() *big.Int {
+	return new(big.Int).Set(b.chainID)
+}
+
+// SwapFactoryAddress returns the address of the deployed SwapFactory contract.
+func (b *Backend) SwapFactoryAddress() ethcommon.Address {
+	return b.swapFactoryAddr
+}
+
+// SwapFactory returns the binding of the deployed SwapFactory contract.
+func (b *Backend) SwapFactory() *contracts.SwapFactory {
+	return b.swapFactory
+}
+
+// TxOpts returns the options to send transactions signed by key on the
+// simulated ethereum chain.
+func (b *Backend) TxOpts(key *ecdsa.PrivateKey) (*bind.TransactOpts, error) {
+	return bind.NewKeyedTransactorWithChainID(key, b.chainID)
+}
+
+// AdvanceBlocks mines count blocks on the simulated ethereum chain, including
+// any transactions that were sent to it directly.
+func (b *Backend) AdvanceBlocks(count int) {
+	for i := 0; i < count; i++ {
+		b.eth.Commit()
+	}
+}
+
+// AdvanceTime mines a block whose timestamp is d after that of the latest
+// block, eg. to pass a swap's t0 or t1. There must be no pending transactions.
+func (b *Backend) AdvanceTime(d time.Duration) error {
+	if err := b.eth.AdjustTime(d); err != nil {
+		return err
+	}
+
+	b.eth.Commit()
+	return nil
+}
+
+// LatestBlockTimestamp returns the timestamp of the latest block of the
+// simulated ethereum chain, which is what the contract compares the swap
+// timeouts against.
+func (b *Backend) LatestBlockTimestamp() time.Time {
+	return time.Unix(int64(b.eth.Blockchain().CurrentHeader().Time), 0)
+}
+
+// NewSwapSecret returns a random secret, and the commitment to it that the
+// contract takes as a swap's claim or refund key.
+func NewSwapSecret() (secret [32]byte, commitment [32]byte, err error) {
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		return secret, commitment, err
+	}
+
+	key.D.FillBytes(secret[:])
+	commitment = secp256k1.NewPublicKeyFromBigInt(key.X, key.Y).Keccak256()
+	return secret, commitment, nil
+}
+
+// NewSwap locks value wei of ETH in a new swap owned by owner, as the taker
+// does. The swap can be claimed by claimer with the secret of claimCommitment
+// and refunded by the owner with the secret of refundCommitment. It returns the
+// swap, with its timeouts, and its ID in the contract.
+func (b *Backend) NewSwap(
+	owner *ecdsa.PrivateKey,
+	claimer ethcommon.Address,
+	claimCommitment [32]byte,
+	refundCommitment [32]byte,
+	timeout time.Duration,
+	value *big.Int,
+) (*contracts.SwapFactorySwap, types.Hash, error) {
+	b.mu.Lock()
+	nonce := big.NewInt(b.nonce)
+	b.nonce++
+	b.mu.Unlock()
+
+	txOpts, err := b.TxOpts(owner)
+	if err != nil {
+		return nil, types.Hash{}, err
+	}
+	txOpts.Value = value
+
+	asset := ethcommon.Address(types.EthAssetETH)
+	tx, err := b.swapFactory.NewSwap(txOpts, claimCommitment, refundCommitment, claimer,
+		big.NewInt(int64(timeout.Seconds())), asset, value, nonce)
+	if err != nil {
+		return nil, types.Hash{}, err
+	}
+
+	receipt, err := b.mine(tx)
+	if err != nil {
+		return nil, types.Hash{}, err
+	}
+
+	if len(receipt.Logs) == 0 {
+		return nil, types.Hash{}, errNoSwapLog
+	}
+
+	swapID, err := contracts.GetIDFromLog(receipt.Logs[0])
+	if err != nil {
+		return nil, types.Hash{}, err
+	}
+
+	t0, t1, err := contracts.GetTimeoutsFromLog(receipt.Logs[0])
+	if err != nil {
+		return nil, types.Hash{}, err
+	}
+
+	swap := &contracts.SwapFactorySwap{
+		Owner:        common.EthereumPrivateKeyToAddress(owner),
+		Claimer:      claimer,
+		PubKeyClaim:  claimCommitment,
+		PubKeyRefund: refundCommitment,
+		Timeout0:     t0,
+		Timeout1:     t1,
+		Asset:        asset,
+		Value:        value,
+		Nonce:        nonce,
+	}
+
+	return swap, swapID, nil
+}
+
+// SetReady sets the swap to ready, as the taker does once the XMR is locked.
+func (b *Backend) SetReady(owner *ecdsa.PrivateKey, swap *contracts.SwapFactorySwap) error {
+	txOpts, err := b.TxOpts(owner)
+	if err != nil {
+		return err
+	}
+
+	tx, err := b.swapFactory.SetReady(txOpts, *swap)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.mine(tx)
+	return err
+}
+
+// Claim claims the swap's ETH with the secret of its claim commitment, as the
+// maker does, which emits the Claimed event.
+func (b *Backend) Claim(claimer *ecdsa.PrivateKey, swap *contracts.SwapFactorySwap, secret [32]byte) error {
+	txOpts, err := b.TxOpts(claimer)
+	if err != nil {
+		return err
+	}
+
+	tx, err := b.swapFactory.Claim(txOpts, *swap, secret)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.mine(tx)
+	return err
+}
+
+// Refund refunds the swap's ETH with the secret of its refund commitment, as
+// the taker does, which emits the Refunded event.
+func (b *Backend) Refund(owner *ecdsa.PrivateKey, swap *contracts.SwapFactorySwap, secret [32]byte) error {
+	txOpts, err := b.TxOpts(owner)
+	if err != nil {
+		return err
+	}
+
+	tx, err := b.swapFactory.Refund(txOpts, *swap, secret)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.mine(tx)
+	return err
+}
+
+// Stage returns the stage of the swap with the passed ID in the contract.
+func (b *Backend) Stage(swapID types.Hash) (byte, error) {
+	return b.swapFactory.Swaps(&bind.CallOpts{Context: context.Background()}, swapID)
+}
+
+// mine mines a block with the sent transaction, and returns its receipt. An
+// error is returned if the transaction reverted.
+func (b *Backend) mine(tx *ethtypes.Transaction) (*ethtypes.Receipt, error) {
+	b.eth.Commit()
+
+	receipt, err := b.eth.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("%w: %s", errTxReverted, tx.Hash())
+	}
+
+	return receipt, nil
+}
@@ -0,0 +1,136 @@
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/monero"
+)
+
+const testTimeout = time.Hour
+
+func newTestBackend(t *testing.T) (b *Backend, owner *ecdsa.PrivateKey, claimer *ecdsa.PrivateKey) {
+	owner, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	claimer, err = ethcrypto.GenerateKey()
+	require.NoError(t, err)
+
+	b, err = NewBackend(owner, claimer)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, b.Close())
+	})
+
+	return b, owner, claimer
+}
+
+func newTestSwap(
+	t *testing.T,
+	b *Backend,
+	owner *ecdsa.PrivateKey,
+	claimer *ecdsa.PrivateKey,
+) (swap *contracts.SwapFactorySwap, swapID types.Hash, claimSecret [32]byte, refundSecret [32]byte) {
+	claimSecret, claimCommitment, err := NewSwapSecret()
+	require.NoError(t, err)
+	refundSecret, refundCommitment, err := NewSwapSecret()
+	require.NoError(t, err)
+
+	value := big.NewInt(1e18)
+	swap, swapID, err = b.NewSwap(owner, common.EthereumPrivateKeyToAddress(claimer),
+		claimCommitment, refundCommitment, testTimeout, value)
+	require.NoError(t, err)
+
+	stage, err := b.Stage(swapID)
+	require.NoError(t, err)
+	require.Equal(t, contracts.StagePending, stage)
+
+	return swap, swapID, claimSecret, refundSecret
+}
+
+func TestBackend_Claim(t *testing.T) {
+	b, owner, claimer := newTestBackend(t)
+	swap, swapID, claimSecret, _ := newTestSwap(t, b, owner, claimer)
+
+	require.NoError(t, b.SetReady(owner, swap))
+	require.NoError(t, b.Claim(claimer, swap, claimSecret))
+
+	stage, err := b.Stage(swapID)
+	require.NoError(t, err)
+	require.Equal(t, contracts.StageCompleted, stage)
+
+	// the claimer paid for gas, but got the swap's value
+	balance, err := b.ETH().BalanceAt(context.Background(), common.EthereumPrivateKeyToAddress(claimer), nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, balance.Cmp(InitialBalance))
+}
+
+func TestBackend_Refund(t *testing.T) {
+	b, owner, claimer := newTestBackend(t)
+	swap, swapID, _, refundSecret := newTestSwap(t, b, owner, claimer)
+
+	require.NoError(t, b.SetReady(owner, swap))
+
+	// once ready, the swap can't be refunded until t1
+	require.ErrorContains(t, b.Refund(owner, swap, refundSecret), "counterparty's turn")
+
+	require.NoError(t, b.AdvanceTime(2*testTimeout))
+	require.False(t, b.LatestBlockTimestamp().Before(time.Unix(swap.Timeout1.Int64(), 0)))
+	require.NoError(t, b.Refund(owner, swap, refundSecret))
+
+	stage, err := b.Stage(swapID)
+	require.NoError(t, err)
+	require.Equal(t, contracts.StageCompleted, stage)
+}
+
+func TestBackend_EthClient(t *testing.T) {
+	b, owner, _ := newTestBackend(t)
+	ctx := context.Background()
+	ec := b.NewEthClient(gomock.NewController(t), owner)
+
+	ts := b.LatestBlockTimestamp().Add(time.Minute)
+	done := make(chan error)
+	go func() {
+		done <- ec.WaitForTimestamp(ctx, ts)
+	}()
+
+	require.NoError(t, b.AdvanceTime(time.Minute))
+	require.NoError(t, <-done)
+
+	balance, err := ec.Balance(ctx)
+	require.NoError(t, err)
+	require.Equal(t, -1, balance.Cmp(InitialBalance)) // paid for the contract deployment
+}
+
+func TestBackend_MoneroWalletClient(t *testing.T) {
+	b, _, _ := newTestBackend(t)
+	wc := b.NewMoneroWalletClient(gomock.NewController(t))
+
+	b.ReceiveMonero(1e12)
+	b.AdvanceMoneroBlocks(monero.MinSpendConfirmations - 1)
+
+	height, err := wc.GetHeight()
+	require.NoError(t, err)
+	require.Equal(t, uint64(monero.MinSpendConfirmations), height)
+
+	balance, err := wc.GetBalance(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1e12), balance.Balance)
+	require.Zero(t, balance.UnlockedBalance)
+	require.Equal(t, uint64(1), balance.BlocksToUnlock)
+
+	b.AdvanceMoneroBlocks(1)
+	balance, err = wc.GetBalance(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1e12), balance.UnlockedBalance)
+	require.Zero(t, balance.BlocksToUnlock)
+}
@@ -0,0 +1,163 @@
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"time"
+
+	"github.com/MarinX/monerorpc/wallet"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/monero"
+)
+
+// pollInterval is how often the mock ethereum client checks the simulated
+// chain while waiting for a receipt or a timestamp.
+const pollInterval = 10 * time.Millisecond
+
+// moneroFunds are piconero received by the simulated monero wallet at a height.
+type moneroFunds struct {
+	amount uint64
+	height uint64
+}
+
+// MoneroHeight returns the height of the simulated monero chain.
+func (b *Backend) MoneroHeight() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.moneroHeight
+}
+
+// AdvanceMoneroBlocks mines count blocks on the simulated monero chain.
+func (b *Backend) AdvanceMoneroBlocks(count uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.moneroHeight += count
+}
+
+// ReceiveMonero adds piconero to the simulated monero wallet at the current
+// height. Like real transfers, they only unlock after
+// monero.MinSpendConfirmations blocks.
+func (b *Backend) ReceiveMonero(piconero uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.moneroFunds = append(b.moneroFunds, &moneroFunds{amount: piconero, height: b.moneroHeight})
+}
+
+func (b *Backend) moneroBalance() *wallet.GetBalanceResponse {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	resp := new(wallet.GetBalanceResponse)
+	for _, funds := range b.moneroFunds {
+		resp.Balance += funds.amount
+
+		unlockHeight := funds.height + monero.MinSpendConfirmations
+		if b.moneroHeight >= unlockHeight {
+			resp.UnlockedBalance += funds.amount
+			continue
+		}
+
+		blocksToUnlock := unlockHeight - b.moneroHeight
+		if blocksToUnlock > resp.BlocksToUnlock {
+			resp.BlocksToUnlock = blocksToUnlock
+		}
+	}
+
+	return resp
+}
+
+// NewMoneroWalletClient returns a mock monero wallet client whose height and
+// balance are those of the simulated monero chain. Other methods can be
+// expected on it as usual.
+func (b *Backend) NewMoneroWalletClient(ctrl *gomock.Controller) *monero.MockWalletClient {
+	mwc := monero.NewMockWalletClient(ctrl)
+	mwc.EXPECT().GetHeight().DoAndReturn(func() (uint64, error) {
+		return b.MoneroHeight(), nil
+	}).AnyTimes()
+	mwc.EXPECT().GetBalance(gomock.Any()).DoAndReturn(func(_ uint64) (*wallet.GetBalanceResponse, error) {
+		return b.moneroBalance(), nil
+	}).AnyTimes()
+	return mwc
+}
+
+// NewEthClient returns a mock ethereum client for the account of key, whose
+// balance, options, receipts and block timestamps come from the simulated
+// ethereum chain. Raw returns nil, as the simulated chain has no RPC endpoint,
+// so code that uses it directly still needs a real node.
+func (b *Backend) NewEthClient(ctrl *gomock.Controller, key *ecdsa.PrivateKey) *extethclient.MockEthClient {
+	addr := common.EthereumPrivateKeyToAddress(key)
+
+	ec := extethclient.NewMockEthClient(ctrl)
+	ec.EXPECT().Address().Return(addr).AnyTimes()
+	ec.EXPECT().PrivateKey().Return(key).AnyTimes()
+	ec.EXPECT().HasPrivateKey().Return(true).AnyTimes()
+	ec.EXPECT().SupportsSubscriptions().Return(false).AnyTimes()
+	ec.EXPECT().ChainID().Return(b.ChainID()).AnyTimes()
+	ec.EXPECT().Lock().AnyTimes()
+	ec.EXPECT().Unlock().AnyTimes()
+	ec.EXPECT().Raw().Return(nil).AnyTimes()
+	ec.EXPECT().Balance(gomock.Any()).DoAndReturn(func(ctx context.Context) (*big.Int, error) {
+		return b.eth.BalanceAt(ctx, addr, nil)
+	}).AnyTimes()
+	ec.EXPECT().CallOpts(gomock.Any()).DoAndReturn(func(ctx context.Context) *bind.CallOpts {
+		return &bind.CallOpts{From: addr, Context: ctx}
+	}).AnyTimes()
+	ec.EXPECT().TxOpts(gomock.Any()).DoAndReturn(func(ctx context.Context) (*bind.TransactOpts, error) {
+		txOpts, err := b.TxOpts(key)
+		if err != nil {
+			return nil, err
+		}
+		txOpts.Context = ctx
+		return txOpts, nil
+	}).AnyTimes()
+	ec.EXPECT().LatestBlockTimestamp(gomock.Any()).DoAndReturn(func(_ context.Context) (time.Time, error) {
+		return b.LatestBlockTimestamp(), nil
+	}).AnyTimes()
+	ec.EXPECT().WaitForReceipt(gomock.Any(), gomock.Any()).DoAndReturn(b.waitForReceipt).AnyTimes()
+	ec.EXPECT().WaitForTimestamp(gomock.Any(), gomock.Any()).DoAndReturn(b.waitForTimestamp).AnyTimes()
+	return ec
+}
+
+// waitForReceipt waits until the transaction is mined on the simulated chain,
+// which only happens when the caller advances it.
+func (b *Backend) waitForReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := b.eth.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			return receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForTimestamp waits until the simulated chain has a block at or after ts,
+// which only happens when the caller advances its time.
+func (b *Backend) waitForTimestamp(ctx context.Context, ts time.Time) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for b.LatestBlockTimestamp().Before(ts) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,294 @@
+// Package webhook POSTs swap status changes to a URL configured by the user, so
+// that external systems don't need to poll swapd's RPC server for them.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	logging "github.com/ipfs/go-log"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+const (
+	// DefaultMaxRetries is how many times a failed delivery is retried, by
+	// default, before it's written to the dead-letter log.
+	DefaultMaxRetries = 5
+
+	// DefaultRetryInterval is how long we wait, by default, before the first
+	// retry of a failed delivery. The wait doubles with each retry.
+	DefaultRetryInterval = 5 * time.Second
+
+	// DefaultRequestTimeout is the default timeout of each POST request.
+	DefaultRequestTimeout = 10 * time.Second
+
+	// SignatureHeader holds the hex-encoded HMAC-SHA256 of the request body,
+	// prefixed with "sha256=", if a secret is configured.
+	SignatureHeader = "X-Swapd-Signature"
+
+	// queueSize is how many status changes can wait to be delivered before
+	// new ones are dead-lettered, so that swaps are never blocked by us.
+	queueSize = 256
+
+	// maxRetryInterval caps the doubling of the retry interval.
+	maxRetryInterval = 5 * time.Minute
+)
+
+var (
+	log = logging.Logger("webhook")
+
+	errInvalidURL  = errors.New("webhook URL must be an absolute http or https URL")
+	errQueueFull   = errors.New("webhook queue is full")
+	errBadResponse = errors.New("webhook returned a non-2xx status")
+)
+
+// Config contains the configuration of a Dispatcher.
+type Config struct {
+	Ctx context.Context
+	URL string
+
+	// Secret, if set, is used to sign each request body with HMAC-SHA256.
+	Secret []byte
+
+	// MaxRetries is how many times a failed delivery is retried. Zero uses
+	// DefaultMaxRetries.
+	MaxRetries uint
+
+	// RetryInterval is the wait before the first retry, which doubles with
+	// each retry. Zero uses DefaultRetryInterval.
+	RetryInterval time.Duration
+
+	// RequestTimeout is the timeout of each request. Zero uses
+	// DefaultRequestTimeout.
+	RequestTimeout time.Duration
+
+	// DeadLetterFile, if set, is a file that payloads which couldn't be
+	// delivered are appended to, one JSON object per line. They're always
+	// logged as well.
+	DeadLetterFile string
+}
+
+// Payload is the JSON body that is POSTed for each status change.
+type Payload struct {
+	SwapID types.Hash `json:"swapID"`
+	// OldStatus is omitted when the swap is new.
+	OldStatus *types.Status `json:"oldStatus,omitempty"`
+	NewStatus types.Status  `json:"newStatus"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// Dispatcher delivers swap status changes to a webhook. Deliveries are made
+// in order by a single goroutine, so the receiver sees a swap's status changes
+// in the order they happened. The cost is that while a webhook is unreachable,
+// each queued change holds up the ones behind it until its retries run out,
+// which is about 3.5 minutes with the default retries and timeouts. Changes
+// that arrive while the queue is full are dead-lettered.
+type Dispatcher struct {
+	ctx            context.Context
+	url            string
+	secret         []byte
+	maxRetries     uint
+	retryInterval  time.Duration
+	client         *http.Client
+	deadLetterFile string
+	deadLetterMu   sync.Mutex
+	queue          chan *Payload
+}
+
+// NewDispatcher returns a new Dispatcher. Start must be called for it to
+// deliver status changes.
+func NewDispatcher(cfg *Config) (*Dispatcher, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", errInvalidURL, cfg.URL)
+	}
+
+	maxRetries := cfg.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+
+	retryInterval := cfg.RetryInterval
+	if retryInterval == 0 {
+		retryInterval = DefaultRetryInterval
+	}
+
+	requestTimeout := cfg.RequestTimeout
+	if requestTimeout == 0 {
+		requestTimeout = DefaultRequestTimeout
+	}
+
+	return &Dispatcher{
+		ctx:            cfg.Ctx,
+		url:            cfg.URL,
+		secret:         cfg.Secret,
+		maxRetries:     maxRetries,
+		retryInterval:  retryInterval,
+		client:         &http.Client{Timeout: requestTimeout},
+		deadLetterFile: cfg.DeadLetterFile,
+		queue:          make(chan *Payload, queueSize),
+	}, nil
+}
+
+// OnStatusChange is a swap.StatusListener that queues the status change for
+// delivery. It never blocks; if the queue is full, the change is dead-lettered.
+func (d *Dispatcher) OnStatusChange(change *swap.StatusChange) {
+	p := &Payload{
+		SwapID:    change.ID,
+		NewStatus: change.New,
+		Timestamp: change.Time,
+	}
+	if change.Old != types.UnknownStatus {
+		old := change.Old
+		p.OldStatus = &old
+	}
+
+	select {
+	case d.queue <- p:
+	default:
+		d.deadLetter(p, errQueueFull)
+	}
+}
+
+// Start delivers queued status changes until the dispatcher's context is
+// cancelled.
+func (d *Dispatcher) Start() {
+	log.Infof("sending swap status changes to webhook %s, retrying failed deliveries for up to %s",
+		d.url, d.maxDeliveryTime())
+	go func() {
+		for {
+			select {
+			case <-d.ctx.Done():
+				return
+			case p := <-d.queue:
+				if err := d.deliver(p); err != nil {
+					d.deadLetter(p, err)
+				}
+			}
+		}
+	}()
+}
+
+// maxDeliveryTime returns the longest that delivering a payload can take, when
+// every attempt times out, which is how long the queue stalls on each payload
+// while the webhook is unreachable.
+func (d *Dispatcher) maxDeliveryTime() time.Duration {
+	total := time.Duration(d.maxRetries+1) * d.client.Timeout
+	wait := d.retryInterval
+	for i := uint(0); i < d.maxRetries; i++ {
+		total += wait
+		wait *= 2
+		if wait > maxRetryInterval {
+			wait = maxRetryInterval
+		}
+	}
+	return total
+}
+
+// deliver POSTs the payload, retrying with a doubling interval on failure.
+func (d *Dispatcher) deliver(p *Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	wait := d.retryInterval
+	for attempt := uint(0); ; attempt++ {
+		err = d.post(body)
+		if err == nil {
+			return nil
+		}
+
+		if attempt == d.maxRetries {
+			return fmt.Errorf("failed after %d attempts: %w", attempt+1, err)
+		}
+
+		log.Warnf("failed to deliver status %s of swap %s to webhook, retrying in %s: %s",
+			p.NewStatus, p.SwapID, wait, err)
+		if err = common.SleepWithContext(d.ctx, wait); err != nil {
+			return err
+		}
+
+		wait *= 2
+		if wait > maxRetryInterval {
+			wait = maxRetryInterval
+		}
+	}
+}
+
+func (d *Dispatcher) post(body []byte) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(d.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(d.secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	// drain the body, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", errBadResponse, resp.Status)
+	}
+
+	return nil
+}
+
+// deadLetter records a payload that couldn't be delivered, so that it can be
+// replayed by hand.
+func (d *Dispatcher) deadLetter(p *Payload, cause error) {
+	line, err := json.Marshal(p)
+	if err != nil {
+		log.Errorf("failed to encode undeliverable webhook payload: %s", err)
+		return
+	}
+
+	log.Errorf("dropping webhook payload %s: %s", line, cause)
+	if d.deadLetterFile == "" {
+		return
+	}
+
+	d.deadLetterMu.Lock()
+	defer d.deadLetterMu.Unlock()
+
+	f, err := os.OpenFile(d.deadLetterFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Errorf("failed to open webhook dead-letter file: %s", err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		log.Errorf("failed to write to webhook dead-letter file: %s", err)
+	}
+}
+
+// Sign returns the value of the SignatureHeader for the passed request body.
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func TestDispatcher_deliver(t *testing.T) {
+	secret := []byte("secret")
+	var failures atomic.Int32
+	failures.Store(2)
+	received := make(chan *Payload, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, Sign(secret, body), r.Header.Get(SignatureHeader))
+
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		p := new(Payload)
+		require.NoError(t, json.Unmarshal(body, p))
+		received <- p
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d, err := NewDispatcher(&Config{
+		Ctx:           ctx,
+		URL:           srv.URL,
+		Secret:        secret,
+		MaxRetries:    2,
+		RetryInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+	d.Start()
+
+	now := time.Now().Round(time.Second)
+	d.OnStatusChange(&swap.StatusChange{
+		ID:   types.Hash{1},
+		Old:  types.ETHLocked,
+		New:  types.XMRLocked,
+		Time: now,
+	})
+
+	select {
+	case p := <-received:
+		require.Equal(t, types.Hash{1}, p.SwapID)
+		require.NotNil(t, p.OldStatus)
+		require.Equal(t, types.ETHLocked, *p.OldStatus)
+		require.Equal(t, types.XMRLocked, p.NewStatus)
+		require.True(t, now.Equal(p.Timestamp))
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+}
+
+func TestDispatcher_deadLetter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deadLetterFile := path.Join(t.TempDir(), "dead-letters.jsonl")
+	d, err := NewDispatcher(&Config{
+		Ctx:            ctx,
+		URL:            srv.URL,
+		MaxRetries:     1,
+		RetryInterval:  time.Millisecond,
+		DeadLetterFile: deadLetterFile,
+	})
+	require.NoError(t, err)
+
+	p := &Payload{SwapID: types.Hash{2}, NewStatus: types.ExpectingKeys, Timestamp: time.Now()}
+	err = d.deliver(p)
+	require.ErrorIs(t, err, errBadResponse)
+
+	d.deadLetter(p, err)
+	data, err := os.ReadFile(deadLetterFile)
+	require.NoError(t, err)
+
+	var stored Payload
+	require.NoError(t, json.Unmarshal(data, &stored))
+	require.Equal(t, p.SwapID, stored.SwapID)
+	require.Nil(t, stored.OldStatus)
+}
+
+func TestNewDispatcher_invalidURL(t *testing.T) {
+	for _, u := range []string{"", "localhost:8080", "ftp://example.com", "http://"} {
+		_, err := NewDispatcher(&Config{Ctx: context.Background(), URL: u})
+		require.ErrorIs(t, err, errInvalidURL, u)
+	}
+}
+
+func TestDispatcher_maxDeliveryTime(t *testing.T) {
+	d, err := NewDispatcher(&Config{Ctx: context.Background(), URL: "http://localhost:8080"})
+	require.NoError(t, err)
+
+	// 6 attempts that time out after 10s, with 5s, 10s, 20s, 40s and 80s
+	// between them
+	require.Equal(t, 215*time.Second, d.maxDeliveryTime())
+
+	// the interval stops doubling at maxRetryInterval
+	d.maxRetries = 10
+	require.Equal(t, 110*time.Second+315*time.Second+4*maxRetryInterval, d.maxDeliveryTime())
+}